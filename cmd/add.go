@@ -10,18 +10,32 @@ import (
 	"vault.module/internal/colors"
 	"vault.module/internal/config"
 	"vault.module/internal/errors"
+	"vault.module/internal/keys"
 	"vault.module/internal/security"
 	"vault.module/internal/vault"
+	"vault.module/internal/webhook"
 )
 
+var addPathScheme string
+
 var addCmd = &cobra.Command{
 	Use:   "add <PREFIX>",
 	Short: "Adds a new wallet to the active vault.",
 	Long: `Adds a new wallet to the active vault.
 
+The wallet is created from a mnemonic, a private key, raw BIP-39 entropy (hex,
+16 to 32 bytes) or a BIP-32 seed (hex, 16 to 64 bytes). The mnemonic of raw
+entropy is reconstructed, so the wallet is like one added from its mnemonic. A
+seed has no mnemonic: the wallet keeps the seed, which 'get <PREFIX> seed'
+shows for backups. 'list' shows which wallets came from entropy or a seed.
+
+--path-scheme sets how an HD wallet derives its next addresses; see
+'path-scheme' for the schemes.
+
 Examples:
   vault.module add A1
   vault.module add mywallet
+  vault.module add ledger1 --path-scheme ledger-live
 `,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -50,8 +64,11 @@ Examples:
 			}
 
 			prefix := args[0]
-			if err := actions.ValidatePrefix(prefix); err != nil {
-				return errors.NewInvalidPrefixError(prefix, err.Error())
+			if err := validatePrefix(prefix); err != nil {
+				return err
+			}
+			if _, err := keys.SchemeDerivationPath(activeVault.Type, addPathScheme); err != nil {
+				return errors.NewInvalidInputError(addPathScheme, err.Error())
 			}
 
 			v, err := vault.LoadVault(activeVault)
@@ -71,26 +88,47 @@ Examples:
 			}
 
 			// The prompt is now generic and doesn't mention specific chains.
-			choice, err := askForInput("Choose source: 1. Mnemonic (HD-wallet), 2. Private Key (single address)")
+			choice, err := askForInput("Choose source: 1. Mnemonic (HD-wallet), 2. Private Key (single address), 3. Raw entropy (hex), 4. BIP-32 seed (hex)")
 			if err != nil {
 				return err
 			}
 			if strings.TrimSpace(choice) == "" {
-				return errors.NewInvalidInputError(choice, "source choice cannot be empty. Please choose 1 for mnemonic or 2 for private key")
+				return errors.NewInvalidInputError(choice, "source choice cannot be empty. Please choose 1 for mnemonic, 2 for private key, 3 for entropy or 4 for seed")
 			}
 
 			var newWallet vault.Wallet
 			var finalAddress string
 			switch choice {
-			case "1":
-				mnemonic, mnemonicErr := askForSecretInputWithCleanup("Enter your mnemonic phrase")
-				if mnemonicErr != nil {
-					return mnemonicErr
+			case "1", "3", "4":
+				switch choice {
+				case "1":
+					mnemonic, mnemonicErr := askForSecretInputWithCleanup("Enter your mnemonic phrase")
+					if mnemonicErr != nil {
+						return mnemonicErr
+					}
+					if strings.TrimSpace(mnemonic) == "" {
+						return errors.NewInvalidMnemonicError("mnemonic phrase cannot be empty")
+					}
+					newWallet, finalAddress, err = actions.CreateWalletFromMnemonic(mnemonic, activeVault.Type)
+				case "3":
+					entropy, entropyErr := askForSecretInputWithCleanup("Enter the entropy (hex)")
+					if entropyErr != nil {
+						return entropyErr
+					}
+					newWallet, finalAddress, err = actions.CreateWalletFromEntropy(entropy, activeVault.Type)
+				case "4":
+					seed, seedErr := askForSecretInputWithCleanup("Enter the BIP-32 seed (hex)")
+					if seedErr != nil {
+						return seedErr
+					}
+					newWallet, finalAddress, err = actions.CreateWalletFromSeed(seed, activeVault.Type)
 				}
-				if strings.TrimSpace(mnemonic) == "" {
-					return errors.NewInvalidMnemonicError("mnemonic phrase cannot be empty")
+				if err == nil && addPathScheme != "" && addPathScheme != vault.PathSchemeBIP44 {
+					newWallet, err = keys.ApplyPathScheme(newWallet, activeVault.Type, addPathScheme)
+					if err == nil {
+						finalAddress = newWallet.Addresses[0].Address
+					}
 				}
-				newWallet, finalAddress, err = actions.CreateWalletFromMnemonic(mnemonic, activeVault.Type)
 			case "2":
 				pkStr, pkErr := askForSecretInputWithCleanup("Enter your private key")
 				if pkErr != nil {
@@ -101,23 +139,34 @@ Examples:
 				}
 				newWallet, finalAddress, err = actions.CreateWalletFromPrivateKey(pkStr, activeVault.Type)
 			default:
-				return errors.NewInvalidInputError(choice, fmt.Sprintf("invalid source choice: '%s'. Please choose 1 for mnemonic or 2 for private key", choice))
+				return errors.NewInvalidInputError(choice, fmt.Sprintf("invalid source choice: '%s'. Please choose 1 for mnemonic, 2 for private key, 3 for entropy or 4 for seed", choice))
 			}
 
 			if err != nil {
 				return errors.NewWalletInvalidError(prefix, err.Error())
 			}
+			if !confirmWeakSecret("add", prefix, keys.AnalyzeWallet(newWallet)) {
+				newWallet.Clear()
+				fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+				return nil
+			}
 
 			v[prefix] = newWallet
 			if err := vault.SaveVault(activeVault, v); err != nil {
 				return errors.NewVaultSaveError(activeVault.KeyFile, err)
 			}
+			notifyVaultMutation(webhook.EventWalletAdded, prefix, "")
 
 			fmt.Println(colors.SafeColor(
 				fmt.Sprintf("Wallet '%s' added successfully to vault '%s'.", prefix, config.Cfg.ActiveVault),
 				colors.Success,
 			))
 			fmt.Printf("   Address: %s\n", colors.SafeColor(finalAddress, colors.Cyan))
+			if newWallet.Source == vault.SourceEntropy {
+				fmt.Println(colors.SafeColor("💡 The mnemonic was reconstructed from the entropy: 'get "+prefix+" mnemonic' shows it for backups.", colors.Info))
+			} else if newWallet.Source == vault.SourceSeed {
+				fmt.Println(colors.SafeColor("💡 A seed has no mnemonic: back up the seed itself ('get "+prefix+" seed').", colors.Info))
+			}
 			return nil
 		})
 	},
@@ -125,4 +174,5 @@ Examples:
 
 func init() {
 	// Registration moved to root.go
+	addCmd.Flags().StringVar(&addPathScheme, "path-scheme", "", "Path scheme of an HD wallet: bip44 (default), ledger-live or ledger-legacy.")
 }
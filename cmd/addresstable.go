@@ -0,0 +1,139 @@
+// File: cmd/addresstable.go
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"vault.module/internal/colors"
+	"vault.module/internal/errors"
+	"vault.module/internal/vault"
+)
+
+// addressRow is one address of a wallet, as shown by get and list
+type addressRow struct {
+	Prefix  string
+	Account int
+	Index   int
+	Address string
+	Path    string
+}
+
+// newAddressRow returns the row of an address of the wallet at prefix
+func newAddressRow(prefix string, addr vault.Address) addressRow {
+	return addressRow{Prefix: prefix, Account: addr.Account, Index: addr.Index, Address: addr.Address, Path: addr.Path}
+}
+
+// value returns the row's value in column
+func (r addressRow) value(column string) interface{} {
+	switch column {
+	case "prefix":
+		return r.Prefix
+	case "account":
+		return r.Account
+	case "index":
+		return r.Index
+	case "address":
+		return r.Address
+	case "path":
+		return r.Path
+	}
+	return ""
+}
+
+// printAddressTable writes rows as aligned columns with a header
+func printAddressTable(w io.Writer, rows []addressRow, columns []string) error {
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = len(column)
+		for _, row := range rows {
+			if n := len(fmt.Sprint(row.value(column))); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	cells := make([]string, len(columns))
+	for i, column := range columns {
+		cells[i] = fmt.Sprintf("%-*s", widths[i], strings.ToUpper(column))
+	}
+	fmt.Fprintln(w, colors.SafeColor(strings.TrimRight(strings.Join(cells, "  "), " "), colors.Bold))
+	for _, row := range rows {
+		for i, column := range columns {
+			switch v := row.value(column).(type) {
+			case int:
+				cells[i] = fmt.Sprintf("%*d", widths[i], v)
+			default:
+				cells[i] = fmt.Sprintf("%-*s", widths[i], v)
+			}
+		}
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+	return nil
+}
+
+// printAddressCSV writes rows as CSV with a header, for spreadsheets and scripts
+func printAddressCSV(w io.Writer, rows []addressRow, columns []string) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(columns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = fmt.Sprint(row.value(column))
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// printAddressJSON writes rows as a JSON array of objects with the given columns
+func printAddressJSON(w io.Writer, rows []addressRow, columns []string) error {
+	objects := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		object := make(map[string]interface{}, len(columns))
+		for _, column := range columns {
+			object[column] = row.value(column)
+		}
+		objects[i] = object
+	}
+	data, err := json.MarshalIndent(objects, "", "  ")
+	if err != nil {
+		return errors.New(errors.ErrCodeInternal, "failed to generate JSON").WithContext("marshal_error", err.Error())
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
+// parseIndexList parses indices such as "0-9" or "0,2,5-7", in the order given
+func parseIndexList(spec string, max int) ([]int, error) {
+	var indices []int
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, errors.NewInvalidInputError(spec, fmt.Sprintf("'%s' is not an index or a range like 0-9", part))
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(to); err != nil {
+				return nil, errors.NewInvalidInputError(spec, fmt.Sprintf("'%s' is not an index or a range like 0-9", part))
+			}
+		}
+		if first < 0 || last < first || last > max {
+			return nil, errors.NewInvalidInputError(spec, fmt.Sprintf("'%s' must be ascending indices between 0 and %d", part, max))
+		}
+		for i := first; i <= last; i++ {
+			indices = append(indices, i)
+		}
+	}
+	return indices, nil
+}
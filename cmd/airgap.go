@@ -0,0 +1,367 @@
+// File: cmd/airgap.go
+package cmd
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+	"vault.module/internal/airgap"
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/errors"
+	"vault.module/internal/nfc"
+	"vault.module/internal/security"
+
+	"github.com/spf13/cobra"
+)
+
+// maxAirgapPayload bounds payloads; larger ones are impractical to move as QR codes
+const maxAirgapPayload = 1 << 20
+
+var airgapIn string
+var airgapOut string
+var airgapType string
+var airgapFragmentLen int
+var airgapFPS int
+var airgapText bool
+var airgapTextParts int
+var airgapNFC bool
+var airgapReader string
+var airgapVerifyCode bool
+
+var airgapCmd = &cobra.Command{
+	Use:   "airgap",
+	Short: "Moves data to and from an offline machine as animated QR codes.",
+	Long: `Moves data to and from an offline machine as animated QR codes.
+
+Payloads (e.g. an unsigned transaction in, a signature out) are encoded as
+Uniform Resources (UR) with fountain codes: the QR animation loops through an
+endless sequence of parts and the receiver can decode from any sufficiently
+large subset of them, in any order.
+
+The crypto-psbt, crypto-account and eth-sign-request formats used by Keystone,
+SeedSigner and compatible wallets are supported: 'airgap sign' answers an
+eth-sign-request with an eth-signature, and 'airgap account' exports a wallet's
+public key for watch-only use.
+
+With --verify-code, each side shows a four-word code derived from the SHA-256
+of the payload (its first four bytes as bytewords). A companion app or second
+machine computes the same code independently; if the codes differ, the payload
+was substituted in transit and must not be signed or used.
+
+With --nfc, the payload is exchanged with the companion device over NFC through
+a PC/SC reader instead (requires opensc-tool), which suits larger payloads.`,
+}
+
+var airgapSendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Displays a payload as an animated QR sequence in the terminal.",
+	Long: `Displays a payload as an animated QR sequence in the terminal.
+
+The payload is read from --in or stdin. Scan the animation with a camera-equipped
+companion device; press Ctrl+C when it reports completion. With --text the UR
+parts are printed one per line instead, for use with other tools.
+
+Examples:
+  vault.module airgap send --in unsigned-tx.bin
+  vault.module airgap send --in psbt.bin --type crypto-psbt --fragment-len 200 --fps 6
+  vault.module airgap send --in signature.bin --text
+  vault.module airgap send --in psbt.bin --nfc
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			payload, err := readAirgapPayload()
+			if err != nil {
+				return err
+			}
+
+			digest := sha256.Sum256(payload)
+			if airgapNFC {
+				return sendAirgapNFC(payload, hex.EncodeToString(digest[:]))
+			}
+			code := verificationCode(payload)
+
+			message := payload
+			if airgap.IsByteStringType(airgapType) {
+				message = airgap.EncodeCBORBytes(payload)
+			}
+
+			audit.Logger.Info("Air-gap payload sent",
+				slog.String("command", "airgap send"),
+				slog.String("type", airgapType),
+				slog.Int("bytes", len(payload)),
+				slog.String("sha256", hex.EncodeToString(digest[:])),
+			)
+			return emitUR(airgapType, message, hex.EncodeToString(digest[:]), code)
+		})
+	},
+}
+
+var airgapReceiveCmd = &cobra.Command{
+	Use:   "receive",
+	Short: "Reassembles a payload from scanned UR parts.",
+	Long: `Reassembles a payload from scanned UR parts.
+
+UR strings are read from stdin, one per line, as produced by a keyboard-emulating
+QR scanner, a companion app, or 'airgap send --text'. Parts may arrive in any
+order and duplicates are ignored. The payload is written to --out (0600) or stdout.
+
+Examples:
+  vault.module airgap receive --out signature.bin
+  vault.module airgap send --in tx.bin --text | vault.module airgap receive --out copy.bin
+  vault.module airgap receive --nfc --out signature.bin
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if airgapNFC {
+				return receiveAirgapNFC()
+			}
+
+			urType, message, err := readURFromStdin()
+			if err != nil {
+				return err
+			}
+			payload := message
+			if airgap.IsByteStringType(urType) {
+				if payload, err = airgap.DecodeCBORBytes(message); err != nil {
+					return errors.NewInvalidInputError("UR payload", err.Error())
+				}
+			}
+
+			return writeAirgapPayload(payload, urType)
+		})
+	},
+}
+
+// emitUR displays message as UR parts: as text with --text, otherwise as a QR
+// animation that loops until interrupted. A non-empty code is shown alongside.
+func emitUR(urType string, message []byte, digest, code string) error {
+	if airgapFPS < 1 || airgapFPS > 30 {
+		return errors.NewInvalidInputError(fmt.Sprintf("%d", airgapFPS), "--fps must be between 1 and 30")
+	}
+	single, next, seqLen, err := airgap.EncodeUR(urType, message, airgapFragmentLen)
+	if err != nil {
+		return errors.NewInvalidInputError(urType, err.Error())
+	}
+
+	if airgapText {
+		if code != "" {
+			fmt.Fprintln(os.Stderr, colors.SafeColor("Verification code: "+code, colors.Info))
+		}
+		if single != "" {
+			fmt.Println(single)
+			return nil
+		}
+		count := airgapTextParts
+		if count <= 0 {
+			count = seqLen
+		}
+		for i := 0; i < count; i++ {
+			fmt.Println(next())
+		}
+		return nil
+	}
+
+	if single != "" {
+		return showQRFrame(single, "single part", digest, code)
+	}
+
+	ctx := security.GetManager().Context()
+	ticker := time.NewTicker(time.Second / time.Duration(airgapFPS))
+	defer ticker.Stop()
+	for frame := 1; ; frame++ {
+		status := fmt.Sprintf("part %d, %d fragments", frame, seqLen)
+		if err := showQRFrame(next(), status, digest, code); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// readURFromStdin reads UR strings from stdin, one per line, until the message is
+// complete and returns its UR type and CBOR body
+func readURFromStdin() (string, []byte, error) {
+	decoder := airgap.NewURDecoder()
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	if !programmaticMode {
+		fmt.Fprintln(os.Stderr, colors.SafeColor("Scan the QR parts; each one is read as a line of input.", colors.Info))
+	}
+	for !decoder.IsComplete() && scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := decoder.Receive(line); err != nil {
+			fmt.Fprintln(os.Stderr, colors.SafeColor("Ignored part: "+err.Error(), colors.Warning))
+			continue
+		}
+		if !programmaticMode {
+			have, total := decoder.Progress()
+			fmt.Fprintf(os.Stderr, "\rReceived %d/%d fragments", have, total)
+		}
+	}
+	if !programmaticMode {
+		fmt.Fprintln(os.Stderr)
+	}
+	if !decoder.IsComplete() {
+		return "", nil, errors.NewInvalidInputError("stdin", "input ended before the payload was complete")
+	}
+
+	urType, message, err := decoder.Result()
+	if err != nil {
+		return "", nil, errors.NewInvalidInputError("UR parts", err.Error())
+	}
+	return urType, message, nil
+}
+
+// writeAirgapPayload writes a received payload to --out or stdout and audits it
+func writeAirgapPayload(payload []byte, transport string) error {
+	if airgapOut == "" {
+		if _, err := os.Stdout.Write(payload); err != nil {
+			return errors.FromOSError(err, "stdout")
+		}
+	} else if err := os.WriteFile(airgapOut, payload, 0600); err != nil {
+		return errors.FromOSError(err, airgapOut)
+	}
+
+	digest := sha256.Sum256(payload)
+	audit.Logger.Info("Air-gap payload received",
+		slog.String("command", "airgap receive"),
+		slog.String("type", transport),
+		slog.Int("bytes", len(payload)),
+		slog.String("sha256", hex.EncodeToString(digest[:])),
+	)
+	if airgapOut != "" && !programmaticMode {
+		fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("Payload (%d bytes, sha256 %s) written to %s", len(payload), hex.EncodeToString(digest[:]), airgapOut), colors.Success))
+	}
+	if code := verificationCode(payload); code != "" {
+		fmt.Fprintln(os.Stderr, colors.SafeColor("Verification code: "+code, colors.Info))
+	}
+	return nil
+}
+
+// sendAirgapNFC writes the raw payload to the companion device over NFC
+func sendAirgapNFC(payload []byte, digest string) error {
+	if !nfc.Available() {
+		return errors.NewDependencyError(nfc.Tool, "Please install OpenSC (https://github.com/OpenSC/OpenSC) and a PC/SC daemon for NFC transport")
+	}
+	if !programmaticMode {
+		fmt.Fprintln(os.Stderr, colors.SafeColor("Hold the companion device on the NFC reader...", colors.Info))
+	}
+	if err := nfc.Send(airgapReader, payload); err != nil {
+		return errors.New(errors.ErrCodeSystem, "NFC transfer failed").WithContext("error", err.Error())
+	}
+	audit.Logger.Info("Air-gap payload sent",
+		slog.String("command", "airgap send"),
+		slog.String("type", "nfc"),
+		slog.Int("bytes", len(payload)),
+		slog.String("sha256", digest),
+	)
+	if !programmaticMode {
+		fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("Sent %d bytes (sha256 %s) over NFC.", len(payload), digest), colors.Success))
+	}
+	if code := verificationCode(payload); code != "" {
+		fmt.Fprintln(os.Stderr, colors.SafeColor("Verification code: "+code, colors.Info))
+	}
+	return nil
+}
+
+// receiveAirgapNFC reads the payload offered by the companion device over NFC
+func receiveAirgapNFC() error {
+	if !nfc.Available() {
+		return errors.NewDependencyError(nfc.Tool, "Please install OpenSC (https://github.com/OpenSC/OpenSC) and a PC/SC daemon for NFC transport")
+	}
+	if !programmaticMode {
+		fmt.Fprintln(os.Stderr, colors.SafeColor("Hold the companion device on the NFC reader...", colors.Info))
+	}
+	payload, err := nfc.Receive(airgapReader)
+	if err != nil {
+		return errors.New(errors.ErrCodeSystem, "NFC transfer failed").WithContext("error", err.Error())
+	}
+	return writeAirgapPayload(payload, "nfc")
+}
+
+// verificationCode returns the payload's verification code when --verify-code is set
+func verificationCode(payload []byte) string {
+	if !airgapVerifyCode {
+		return ""
+	}
+	return airgap.VerificationCode(payload)
+}
+
+// readAirgapPayload reads the payload from --in or stdin
+func readAirgapPayload() ([]byte, error) {
+	var r io.Reader = os.Stdin
+	name := "stdin"
+	if airgapIn != "" {
+		f, err := os.Open(airgapIn)
+		if err != nil {
+			return nil, errors.FromOSError(err, airgapIn)
+		}
+		defer f.Close()
+		r, name = f, airgapIn
+	}
+	payload, err := io.ReadAll(io.LimitReader(r, maxAirgapPayload+1))
+	if err != nil {
+		return nil, errors.NewFileSystemError("read", name, err)
+	}
+	if len(payload) == 0 {
+		return nil, errors.NewInvalidInputError(name, "payload is empty")
+	}
+	if len(payload) > maxAirgapPayload {
+		return nil, errors.NewInvalidInputError(name, fmt.Sprintf("payload exceeds %d bytes", maxAirgapPayload))
+	}
+	return payload, nil
+}
+
+// showQRFrame clears the terminal and renders one UR part as a QR code. Upper case
+// lets the QR encoder use the denser alphanumeric mode.
+func showQRFrame(part, status, digest, code string) error {
+	q, err := qrcode.New(strings.ToUpper(part), qrcode.Low)
+	if err != nil {
+		return errors.New(errors.ErrCodeInternal, "failed to render QR code").WithContext("error", err.Error())
+	}
+	fmt.Print("\033[H\033[2J")
+	fmt.Print(q.ToSmallString(false))
+	fmt.Printf("%s  sha256 %s\n", status, digest[:16])
+	if code != "" {
+		fmt.Printf("Verification code: %s\n", code)
+	}
+	fmt.Println("Press Ctrl+C when the receiver has completed.")
+	return nil
+}
+
+// addURDisplayFlags registers the flags controlling emitUR on cmd
+func addURDisplayFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&airgapFragmentLen, "fragment-len", 120, "Maximum bytes per QR frame; smaller frames scan more reliably.")
+	cmd.Flags().IntVar(&airgapFPS, "fps", 4, "Frames per second of the animation.")
+	cmd.Flags().BoolVar(&airgapText, "text", false, "Print UR parts as text instead of displaying QR codes.")
+	cmd.Flags().IntVar(&airgapTextParts, "parts", 0, "Number of parts printed with --text (default: one per fragment).")
+}
+
+func init() {
+	airgapSendCmd.Flags().StringVar(&airgapIn, "in", "", "File containing the payload (default: stdin).")
+	airgapSendCmd.Flags().StringVar(&airgapType, "type", airgap.TypeBytes, "UR type; 'bytes' and 'crypto-psbt' payloads are wrapped as CBOR, others must already be CBOR.")
+	addURDisplayFlags(airgapSendCmd)
+
+	airgapCmd.PersistentFlags().BoolVar(&airgapNFC, "nfc", false, "Exchange the payload over NFC through a PC/SC reader instead of QR codes.")
+	airgapCmd.PersistentFlags().BoolVar(&airgapVerifyCode, "verify-code", false, "Show a short code derived from the payload hash to compare with the companion device.")
+	airgapCmd.PersistentFlags().StringVar(&airgapReader, "reader", "", "PC/SC reader number or name for --nfc (default: first reader).")
+
+	airgapReceiveCmd.Flags().StringVar(&airgapOut, "out", "", "File to write the payload to (default: stdout).")
+}
@@ -0,0 +1,725 @@
+// File: cmd/airgapsign.go
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"vault.module/internal/addressbook"
+	"vault.module/internal/airgap"
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/display"
+	"vault.module/internal/errors"
+	"vault.module/internal/keys"
+	"vault.module/internal/noncelog"
+	"vault.module/internal/signqueue"
+	"vault.module/internal/simulate"
+	"vault.module/internal/vault"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+var airgapSignKey string
+var airgapSignYes bool
+var airgapAccountHDKey bool
+var airgapSignNoSimulate bool
+var airgapSignChainID int64
+var airgapSignAllowUnprotected bool
+var airgapSignAllowNonceReuse bool
+
+var airgapSignCmd = &cobra.Command{
+	Use:   "sign",
+	Short: "Signs an eth-sign-request scanned from a watch-only wallet.",
+	Long: `Signs an eth-sign-request scanned from a watch-only wallet.
+
+Acts as an air-gapped signer in the Keystone QR protocol: the request's UR parts
+are read from stdin, one per line, the signing address is looked up in the active
+vault by its address or derivation path, and the resulting eth-signature is shown
+as an animated QR code for the watch-only wallet to scan.
+
+Legacy and typed (EIP-2718) transactions, personal messages and EIP-712 typed
+data are supported. The request is summarized and must be confirmed unless --yes
+is given; a transaction's recipient is shown with its label from the address
+book ('labels import'), and its value. With --verify-code, the summary
+includes the verification code of the request as received, to compare with the
+code 'airgap send --verify-code' or the companion app showed for the request as
+sent.
+
+Before a transaction is confirmed, it is previewed: the summary lists the
+balance changes of the signing address and the token approvals it grants, with
+unlimited allowances and approvals of whole NFT collections highlighted. With an
+endpoint for the request's chain in "simulation_rpc" of config.json, such as
+{"1": "http://127.0.0.1:8545"}, the transaction is run against the latest
+block: debug_traceCall shows every token movement, and a node without it
+(eth_call) shows whether the transaction reverts. Otherwise, and with
+--no-simulate, transfers and approvals called directly on a token are decoded
+from the calldata, offline. Leave "simulation_rpc" unset on an air-gapped
+machine.
+
+A request that grants a token approval (approve, increaseAllowance,
+setApprovalForAll or permit calldata, or an EIP-2612, DAI or Permit2 permit as
+typed data) is flagged as high risk with its spender, token, amount and expiry,
+and must be confirmed a second time.
+
+A signature must not be valid on another chain than the request's: the chain
+ID in a transaction, or in the domain of typed data, must be the request's, and
+the request's must be --chain-id when it is given. A legacy transaction without
+an EIP-155 chain ID is valid on every chain and is refused unless
+--allow-unprotected is given.
+
+The nonce of every signed transaction is recorded per address and chain in
+signed-nonces.json. A transaction with a nonce already signed for another one
+replaces or double-spends it, as only one of them can be mined: it is flagged
+and must be confirmed a second time, and with --yes it is refused unless
+--allow-nonce-reuse is given.
+
+With "signing_queue" enabled in config.json, programmatic requests
+(VAULT_MODULE_PROGRAMMATIC=1) are not signed directly: they are queued, their
+ID is printed, and they are signed once a human approves them with 'approvals
+review'. The client authenticates with its token of 'approvals token' in
+VAULT_MODULE_CLIENT_TOKEN. "signing_policies" lets a client's low-risk
+requests, by data type and chain ID, be signed without review.
+
+Examples:
+  vault.module airgap sign
+  vault.module airgap sign --key A1 --text
+  vault.module airgap sign --verify-code
+  vault.module airgap sign --chain-id 1
+  VAULT_MODULE_PROGRAMMATIC=1 VAULT_MODULE_CLIENT_TOKEN=... vault.module airgap sign --yes --text
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			if activeVault.Type != constants.VaultTypeEVM {
+				return errors.NewInvalidInputError(activeVault.Type, "air-gapped signing is only available for EVM vaults")
+			}
+			if programmaticMode && !airgapSignYes {
+				return errors.NewInvalidInputError("--yes", "programmatic signing requires --yes")
+			}
+
+			urType, message, err := readURFromStdin()
+			if err != nil {
+				return err
+			}
+			if urType != airgap.TypeEthSignRequest {
+				return errors.NewInvalidInputError(urType, "expected an "+airgap.TypeEthSignRequest+" UR")
+			}
+			req, err := airgap.DecodeEthSignRequest(message)
+			if err != nil {
+				return errors.NewInvalidInputError("eth-sign-request", err.Error())
+			}
+			kind, err := ethSignKind(req.DataType)
+			if err != nil {
+				return err
+			}
+			if _, err := checkReplayProtection(req, kind, airgapSignChainID, airgapSignAllowUnprotected); err != nil {
+				return err
+			}
+
+			// Programmatic requests wait for a human unless a policy of the client approves them
+			if programmaticMode && config.Cfg.SigningQueue {
+				client, err := authenticatedClient("queued signing")
+				if err != nil {
+					return err
+				}
+				dataType := airgap.EthDataTypeNames[req.DataType]
+				if !config.AutoApproves(client, dataType, req.ChainID) {
+					entry, err := signqueue.Enqueue(client, config.Cfg.ActiveVault, airgapSignKey, message)
+					if err != nil {
+						return err
+					}
+					audit.Logger.Info("Signing request queued for approval",
+						slog.String("command", "airgap sign"),
+						slog.String("vault", config.Cfg.ActiveVault),
+						slog.String("client", client),
+						slog.String("request_id", entry.ID),
+						slog.String("data_type", dataType),
+						slog.Int64("chain_id", req.ChainID),
+					)
+					// The client polls 'approvals result <ID>' for the signature
+					fmt.Println(entry.ID)
+					return nil
+				}
+				audit.Logger.Info("Signing request auto-approved by policy",
+					slog.String("command", "airgap sign"),
+					slog.String("vault", config.Cfg.ActiveVault),
+					slog.String("client", client),
+					slog.String("data_type", dataType),
+					slog.Int64("chain_id", req.ChainID),
+				)
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			response, err := signEthRequest("airgap sign", v, req, message, airgapSignKey, !airgapSignYes)
+			if err != nil || response == nil {
+				return err
+			}
+			sigDigest := sha256.Sum256(response)
+			return emitUR(airgap.TypeEthSignature, response, hex.EncodeToString(sigDigest[:]), verificationCode(response))
+		})
+	},
+}
+
+// signEthRequest signs an eth-sign-request with the matching address of v and returns
+// the encoded eth-signature. With confirm, the summary must be confirmed first; a
+// declined request yields a nil response.
+func signEthRequest(command string, v vault.Vault, req *airgap.EthSignRequest, message []byte, key string, confirm bool) ([]byte, error) {
+	kind, err := ethSignKind(req.DataType)
+	if err != nil {
+		return nil, err
+	}
+	signChainID, err := checkReplayProtection(req, kind, airgapSignChainID, airgapSignAllowUnprotected)
+	if err != nil {
+		return nil, err
+	}
+	prefix, addr, err := findSigningAddress(v, req, key)
+	if err != nil {
+		return nil, err
+	}
+	if wallet := v[prefix]; wallet.Sealed() {
+		if err := openWalletEnvelope(command, prefix, &wallet); err != nil {
+			return nil, err
+		}
+		v[prefix] = wallet
+		if prefix, addr, err = findSigningAddress(v, req, key); err != nil {
+			return nil, err
+		}
+	}
+	if addr.PrivateKey == nil || addr.PrivateKey.IsEmpty() {
+		return nil, missingKeyError(prefix, addr.Index, v[prefix])
+	}
+	if err := checkWalletNotFrozen(command, prefix, v[prefix]); err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256(req.SignData)
+	// Only a request a human confirms is worth the round trip to the node
+	printSignRequest(req, prefix, addr, hex.EncodeToString(digest[:]), verificationCode(message), confirm && !airgapSignNoSimulate)
+	grants := simulate.Grants(req.SignData, kind)
+	printGrantWarnings(os.Stderr, grants, req.ChainID)
+
+	// A second transaction with a nonce already signed replaces or
+	// double-spends the first: only one of them can be mined
+	nonce, isTransaction := transactionNonce(req.SignData, kind)
+	var reused *noncelog.Entry
+	if isTransaction {
+		if reused, err = noncelog.Conflict(addr.Address, signChainID, nonce, hex.EncodeToString(digest[:])); err != nil {
+			return nil, err
+		}
+	}
+	if reused != nil {
+		printNonceReuse(os.Stderr, reused)
+		if !confirm && !airgapSignAllowNonceReuse {
+			return nil, errors.NewInvalidInputError(strconv.FormatUint(nonce, 10), "another transaction was already signed with this nonce; sign a replacement with --allow-nonce-reuse only if that is intended")
+		}
+	}
+	if confirm && !askForConfirmation("Sign this request?") {
+		fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+		return nil, nil
+	}
+	if confirm && reused != nil && !askForConfirmation(fmt.Sprintf("Sign a second transaction with nonce %d? Only one of them can be mined.", nonce)) {
+		fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+		return nil, nil
+	}
+	// Approvals drain wallets long after they are signed: ask a second time
+	if confirm && len(grants) > 0 && !askForConfirmation(fmt.Sprintf("This request lets %d spender(s) move your tokens. Sign it anyway?", len(grants))) {
+		fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+		return nil, nil
+	}
+
+	if err := checkSecretRateLimit(prefix); err != nil {
+		return nil, err
+	}
+	if isTransaction && reused == nil {
+		// Another signer may have taken the nonce since it was checked
+		if reused, err = noncelog.Claim(addr.Address, signChainID, nonce, hex.EncodeToString(digest[:]), command); err != nil {
+			return nil, err
+		}
+		if reused != nil {
+			printNonceReuse(os.Stderr, reused)
+			return nil, errors.NewInvalidInputError(strconv.FormatUint(nonce, 10), "another transaction was signed with this nonce while this one was reviewed; sign it again to decide")
+		}
+	}
+	signature, err := keys.SignEVMPayload(addr.PrivateKey.String(), req.SignData, kind, signChainID)
+	if err != nil {
+		return nil, errors.NewInvalidInputError("eth-sign-request", err.Error())
+	}
+	response, err := airgap.EncodeEthSignature(req.RequestID, signature, "vault.module")
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeInternal, "failed to encode signature").WithContext("error", err.Error())
+	}
+
+	audit.Logger.Warn("Air-gap signing request signed",
+		slog.String("command", command),
+		slog.String("vault", config.Cfg.ActiveVault),
+		slog.String("prefix", prefix),
+		slog.String("address", addr.Address),
+		slog.Int64("chain_id", req.ChainID),
+		slog.Int("data_type", req.DataType),
+		slog.String("origin", req.Origin),
+		slog.String("sha256", hex.EncodeToString(digest[:])),
+		slog.Int("approval_grants", len(grants)),
+		slog.Bool("nonce_reused", reused != nil),
+	)
+	if isTransaction {
+		if err := noncelog.Record(addr.Address, signChainID, nonce, hex.EncodeToString(digest[:]), command); err != nil {
+			fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("Warning: the nonce of this transaction was not recorded: %v", err), colors.Warning))
+		}
+	}
+	return response, nil
+}
+
+// transactionNonce returns the nonce of a transaction payload, and false for
+// other payloads
+func transactionNonce(payload []byte, kind keys.EVMPayloadKind) (uint64, bool) {
+	if kind != keys.EVMLegacyTransaction && kind != keys.EVMTypedTransaction {
+		return 0, false
+	}
+	tx, err := keys.DecodeEVMTransaction(payload, kind)
+	if err != nil {
+		return 0, false
+	}
+	return tx.Nonce, true
+}
+
+// printNonceReuse warns that a transaction with the same nonce was signed before
+func printNonceReuse(out io.Writer, earlier *noncelog.Entry) {
+	fmt.Fprintln(out, colors.SafeColor(fmt.Sprintf("  NONCE REUSE: another transaction of this address was signed with nonce %d on chain %d", earlier.Nonce, earlier.ChainID), colors.Error))
+	fmt.Fprintf(out, "    Signed:   %s by '%s'\n", display.Time(earlier.SignedAt), earlier.Command)
+	fmt.Fprintf(out, "    SHA-256:  %s\n", earlier.SHA256)
+	fmt.Fprintln(out, colors.SafeColor("  Signing this one replaces it if it is still pending, and fails if it was mined. Check the nonce on the sending device.", colors.Warning))
+}
+
+// checkReplayProtection checks that a signature cannot be replayed on another
+// chain than the request's: a transaction or typed data must name the chain of
+// the request, which must be onlyChainID (--chain-id) when it is not 0. A
+// legacy transaction without an EIP-155 chain ID is refused unless
+// allowUnprotected (--allow-unprotected). It returns the chain ID to sign
+// with, 0 for an unprotected transaction.
+func checkReplayProtection(req *airgap.EthSignRequest, kind keys.EVMPayloadKind, onlyChainID int64, allowUnprotected bool) (int64, error) {
+	chainID := strconv.FormatInt(req.ChainID, 10)
+	if onlyChainID != 0 && req.ChainID != onlyChainID && kind != keys.EVMPersonalMessage {
+		return 0, errors.NewInvalidInputError(chainID, fmt.Sprintf("the request is for chain %d, not chain %d of --chain-id", req.ChainID, onlyChainID))
+	}
+	switch kind {
+	case keys.EVMLegacyTransaction, keys.EVMTypedTransaction:
+		tx, err := keys.DecodeEVMTransaction(req.SignData, kind)
+		if err != nil {
+			return 0, errors.NewInvalidInputError("eth-sign-request", err.Error())
+		}
+		if tx.ChainID == nil {
+			if !allowUnprotected {
+				return 0, errors.NewInvalidInputError("eth-sign-request", "the legacy transaction has no EIP-155 chain ID, so it could be replayed on every chain; sign it with --allow-unprotected only if that is intended")
+			}
+			return 0, nil
+		}
+		if !tx.ChainID.IsInt64() || tx.ChainID.Int64() != req.ChainID {
+			return 0, errors.NewInvalidInputError(chainID, fmt.Sprintf("the transaction is for chain %s but the request for chain %d", tx.ChainID, req.ChainID))
+		}
+	case keys.EVMTypedData:
+		domainChainID, err := keys.EVMTypedDataChainID(req.SignData)
+		if err != nil {
+			return 0, errors.NewInvalidInputError("eth-sign-request", err.Error())
+		}
+		if domainChainID != nil && (!domainChainID.IsInt64() || domainChainID.Int64() != req.ChainID) {
+			return 0, errors.NewInvalidInputError(chainID, fmt.Sprintf("the typed data is for chain %s but the request for chain %d", domainChainID, req.ChainID))
+		}
+	}
+	return req.ChainID, nil
+}
+
+var airgapAccountCmd = &cobra.Command{
+	Use:   "account <PREFIX>",
+	Short: "Exports an HD wallet's account key to a watch-only wallet.",
+	Long: `Exports an HD wallet's account key to a watch-only wallet.
+
+Shows the extended public key at m/44'/60'/0' of the wallet's mnemonic as a
+crypto-account UR (or crypto-hdkey with --hdkey), the format MetaMask, Rabby and
+other wallets scan to pair with a Keystone-compatible signer. Only public data
+is exported; 'airgap sign' then signs the requests they produce.
+
+Examples:
+  vault.module airgap account A1
+  vault.module airgap account A1 --hdkey --text
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			if activeVault.Type != constants.VaultTypeEVM {
+				return errors.NewInvalidInputError(activeVault.Type, "account export is only available for EVM vaults")
+			}
+			prefix := args[0]
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			wallet, exists := v[prefix]
+			if !exists {
+				return errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
+			}
+			if !wallet.IsHD() {
+				return errors.NewWalletInvalidError(prefix, "account export requires an HD wallet (created from a mnemonic or seed)")
+			}
+			if wallet.DerivationPath != "" && wallet.DerivationPath != keys.EVMDerivationPath {
+				return errors.NewWalletInvalidError(prefix, fmt.Sprintf("derivation path %s is not below %s", wallet.DerivationPath, keys.EVMAccountPath))
+			}
+
+			xpub, err := keys.EVMAccountKey(wallet)
+			if err != nil {
+				return errors.NewWalletInvalidError(prefix, err.Error())
+			}
+			hdkey := &airgap.HDKey{
+				PublicKey:         xpub.PublicKey,
+				ChainCode:         xpub.ChainCode,
+				Path:              xpub.Path,
+				MasterFingerprint: xpub.MasterFingerprint,
+				ParentFingerprint: xpub.ParentFingerprint,
+				Children:          "0/*",
+				Name:              prefix,
+				Note:              "account.standard",
+			}
+
+			urType := airgap.TypeCryptoAccount
+			var message []byte
+			if airgapAccountHDKey {
+				urType = airgap.TypeCryptoHDKey
+				message, err = airgap.EncodeCryptoHDKey(hdkey)
+			} else {
+				message, err = airgap.EncodeCryptoAccount(xpub.MasterFingerprint, []*airgap.HDKey{hdkey})
+			}
+			if err != nil {
+				return errors.New(errors.ErrCodeInternal, "failed to encode account").WithContext("error", err.Error())
+			}
+
+			audit.Logger.Info("Air-gap account exported",
+				slog.String("command", "airgap account"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("prefix", prefix),
+				slog.String("type", urType),
+				slog.String("fingerprint", fmt.Sprintf("%08x", xpub.MasterFingerprint)),
+			)
+
+			digest := sha256.Sum256(message)
+			return emitUR(urType, message, hex.EncodeToString(digest[:]), verificationCode(message))
+		})
+	},
+}
+
+// ethSignKind maps an eth-sign-request data type to the payload kind to sign
+func ethSignKind(dataType int) (keys.EVMPayloadKind, error) {
+	switch dataType {
+	case airgap.EthDataTransaction:
+		return keys.EVMLegacyTransaction, nil
+	case airgap.EthDataTypedTransaction:
+		return keys.EVMTypedTransaction, nil
+	case airgap.EthDataPersonalMessage:
+		return keys.EVMPersonalMessage, nil
+	case airgap.EthDataTypedData:
+		return keys.EVMTypedData, nil
+	default:
+		return 0, errors.NewInvalidInputError(fmt.Sprintf("%d", dataType), "unsupported eth-sign-request data type")
+	}
+}
+
+// findSigningAddress finds the address a request is for, by its address or, when the
+// request has none, by its derivation path. A key (--key) restricts the search to one wallet.
+func findSigningAddress(v vault.Vault, req *airgap.EthSignRequest, key string) (string, *vault.Address, error) {
+	var matchPrefix string
+	var match *vault.Address
+	for prefix, wallet := range v {
+		if key != "" && prefix != key {
+			continue
+		}
+		for i := range wallet.Addresses {
+			addr := &wallet.Addresses[i]
+			var ok bool
+			if req.Address != nil {
+				ok = strings.EqualFold(strings.TrimPrefix(addr.Address, "0x"), hex.EncodeToString(req.Address))
+			} else {
+				ok = req.Path != "" && addr.Path == req.Path
+			}
+			if !ok {
+				continue
+			}
+			if match != nil {
+				return "", nil, errors.NewInvalidInputError(req.Path, "the request matches addresses in several wallets; select one with --key")
+			}
+			matchPrefix, match = prefix, addr
+		}
+	}
+	if match == nil {
+		target := req.Path
+		if req.Address != nil {
+			target = "0x" + hex.EncodeToString(req.Address)
+		}
+		return "", nil, errors.NewInvalidInputError(target, "no address in the active vault matches the request")
+	}
+	return matchPrefix, match, nil
+}
+
+// printSignRequest summarizes a request for confirmation. Personal messages are
+// shown as text when they are printable; transactions are previewed, online
+// when online is set.
+func printSignRequest(req *airgap.EthSignRequest, prefix string, addr *vault.Address, digest, code string, online bool) {
+	out := os.Stderr
+	fmt.Fprintln(out, colors.SafeColor("Signing request", colors.Info))
+	fmt.Fprintf(out, "  Wallet:    %s (index %d)\n", prefix, addr.Index)
+	fmt.Fprintf(out, "  Address:   %s\n", addr.Address)
+	fmt.Fprintf(out, "  Chain ID:  %d\n", req.ChainID)
+	if req.Origin != "" {
+		fmt.Fprintf(out, "  Origin:    %s\n", req.Origin)
+	}
+	switch req.DataType {
+	case airgap.EthDataTransaction:
+		fmt.Fprintln(out, "  Type:      legacy transaction")
+		printTransactionPreview(out, req, keys.EVMLegacyTransaction, addr.Address, online)
+	case airgap.EthDataTypedTransaction:
+		fmt.Fprintln(out, "  Type:      typed transaction")
+		printTransactionPreview(out, req, keys.EVMTypedTransaction, addr.Address, online)
+	case airgap.EthDataTypedData:
+		fmt.Fprintln(out, "  Type:      EIP-712 typed data")
+		fmt.Fprintf(out, "  Data:      %s\n", string(req.SignData))
+	case airgap.EthDataPersonalMessage:
+		fmt.Fprintln(out, "  Type:      personal message")
+		if utf8.Valid(req.SignData) && !strings.ContainsFunc(string(req.SignData), isControlRune) {
+			fmt.Fprintf(out, "  Message:   %s\n", string(req.SignData))
+		} else {
+			fmt.Fprintf(out, "  Message:   0x%s\n", hex.EncodeToString(req.SignData))
+		}
+	}
+	fmt.Fprintf(out, "  SHA-256:   %s\n", digest)
+	if code != "" {
+		fmt.Fprintf(out, "  Code:      %s\n", code)
+		fmt.Fprintln(out, colors.SafeColor("Compare the code with the one shown by the sending device before signing.", colors.Warning))
+	}
+}
+
+// printTransactionPreview prints the recipient of a transaction, with its label
+// from the address book, its value and what it would do to the signer's
+// balances and allowances. When online and "simulation_rpc" has an endpoint
+// for the chain, the transaction is run against the chain's latest state;
+// otherwise its calldata is decoded offline.
+func printTransactionPreview(out io.Writer, req *airgap.EthSignRequest, kind keys.EVMPayloadKind, from string, online bool) {
+	tx, err := keys.DecodeEVMTransaction(req.SignData, kind)
+	if err != nil {
+		fmt.Fprintln(out, colors.SafeColor(fmt.Sprintf("  Warning: the transaction could not be decoded (%v); check it on the sending device.", err), colors.Warning))
+		return
+	}
+	if tx.To == nil {
+		fmt.Fprintln(out, "  To:        contract creation")
+	} else if label := addressbook.Label(tx.To.Hex()); label != "" {
+		fmt.Fprintf(out, "  To:        %s (%s)\n", colors.SafeColor(label, colors.Bold), tx.To.Hex())
+	} else {
+		fmt.Fprintf(out, "  To:        %s\n", tx.To.Hex())
+	}
+	fmt.Fprintf(out, "  Value:     %s %s\n", simulate.FormatAmount(tx.Value, 18), simulate.NativeSymbol(req.ChainID))
+	if tx.ChainID == nil {
+		fmt.Fprintln(out, colors.SafeColor("  Replay:    UNPROTECTED: without an EIP-155 chain ID, the signed transaction is valid on every chain", colors.Error))
+	}
+
+	sender := common.HexToAddress(from)
+	var preview *simulate.Preview
+	if rpcURL := config.Cfg.SimulationRPC[strconv.FormatInt(req.ChainID, 10)]; online && rpcURL != "" {
+		if preview, err = simulate.Run(rpcURL, sender, tx); err != nil {
+			fmt.Fprintln(out, colors.SafeColor(fmt.Sprintf("  Warning: the simulation failed (%v); the preview is decoded from the calldata only.", err), colors.Warning))
+		}
+	}
+	if preview == nil {
+		preview = simulate.Offline(sender, tx)
+	}
+	printTransactionEffects(out, preview, req.ChainID)
+}
+
+// printTransactionEffects prints a transaction preview: its outcome, the
+// signer's balance changes and the approvals it grants
+func printTransactionEffects(out io.Writer, preview *simulate.Preview, chainID int64) {
+	switch preview.Mode {
+	case simulate.ModeTrace:
+		fmt.Fprintln(out, "  Preview:   simulated on the latest block (debug_traceCall)")
+	case simulate.ModeCall:
+		fmt.Fprintln(out, "  Preview:   simulated on the latest block (eth_call)")
+	default:
+		fmt.Fprintln(out, "  Preview:   decoded offline from the calldata, not simulated")
+	}
+	if preview.Reverted {
+		reason := preview.RevertReason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		fmt.Fprintln(out, colors.SafeColor("  Outcome:   REVERTS: "+addressbook.Clean(reason), colors.Error))
+		fmt.Fprintln(out, "  Balance:   no change besides the gas fee, as nothing else happens")
+		return
+	} else if preview.Mode != simulate.ModeOffline {
+		outcome := "succeeds"
+		if preview.GasUsed > 0 {
+			outcome += fmt.Sprintf(", using %d gas", preview.GasUsed)
+		}
+		fmt.Fprintf(out, "  Outcome:   %s\n", outcome)
+	}
+
+	heading := "  Balance:   "
+	if len(preview.Changes) == 0 {
+		if preview.Mode == simulate.ModeTrace || len(preview.Notes) == 0 {
+			fmt.Fprintln(out, heading+"no change besides the gas fee")
+		} else {
+			fmt.Fprintln(out, heading+"no change decoded")
+		}
+	}
+	for _, change := range preview.Changes {
+		amount := simulate.FormatAmount(change.Amount, change.Asset.Decimals)
+		if change.Amount.Sign() > 0 {
+			amount = "+" + amount
+		}
+		line := amount + " " + assetName(change.Asset, chainID)
+		if change.Asset.NFT {
+			line = amount + " NFT(s) of " + assetName(change.Asset, chainID)
+		} else if change.Asset.Decimals < 0 {
+			line = amount + " base units of " + assetName(change.Asset, chainID)
+		}
+		fmt.Fprintln(out, heading+line)
+		heading = "             "
+	}
+
+	heading = "  Approves:  "
+	for _, approval := range preview.Approvals {
+		spender := addressbook.Describe(approval.Spender)
+		token := assetName(approval.Asset, chainID)
+		var line string
+		switch {
+		case approval.Revoked && approval.All:
+			line = fmt.Sprintf("revokes %s as operator of every %s token", spender, token)
+		case approval.Revoked:
+			line = fmt.Sprintf("revokes the %s allowance of %s", token, spender)
+		case approval.All:
+			line = fmt.Sprintf("%s to move EVERY %s token you own", spender, token)
+		case approval.Asset.NFT:
+			line = fmt.Sprintf("%s to move %s token #%s", spender, token, approval.Amount)
+		case approval.Unlimited():
+			line = fmt.Sprintf("%s to spend UNLIMITED %s", spender, token)
+		case approval.Asset.Decimals < 0:
+			line = fmt.Sprintf("%s to spend %s base units of %s", spender, approval.Amount, token)
+		default:
+			line = fmt.Sprintf("%s to spend %s %s", spender, simulate.FormatAmount(approval.Amount, approval.Asset.Decimals), token)
+		}
+		color := colors.Warning
+		if approval.Revoked {
+			color = colors.Info
+		}
+		fmt.Fprintln(out, heading+colors.SafeColor(line, color))
+		heading = "             "
+	}
+	for _, note := range preview.Notes {
+		fmt.Fprintf(out, "  Note:      %s\n", note)
+	}
+}
+
+// printGrantWarnings warns of each allowance a request grants, with its
+// spender, token and amount
+func printGrantWarnings(out io.Writer, grants []simulate.Grant, chainID int64) {
+	for _, grant := range grants {
+		what := "an on-chain " + grant.Method + " call"
+		if grant.Signature {
+			what = "an off-chain " + grant.Method + " signature, which anyone holding it can submit"
+		}
+		fmt.Fprintln(out, colors.SafeColor("  HIGH RISK: this request grants a token approval ("+what+")", colors.Error))
+		fmt.Fprintf(out, "    Spender:  %s\n", colors.SafeColor(addressbook.Describe(grant.Spender), colors.Bold))
+		token := "not named by the request"
+		if grant.Token != nil {
+			token = assetName(simulate.Asset{Token: grant.Token}, chainID)
+		}
+		fmt.Fprintf(out, "    Token:    %s\n", token)
+		switch {
+		case grant.All && grant.Method == "setApprovalForAll":
+			fmt.Fprintln(out, colors.SafeColor("    Amount:   EVERY token of the collection, now and in the future", colors.Error))
+		case grant.Unlimited():
+			fmt.Fprintln(out, colors.SafeColor("    Amount:   UNLIMITED: the spender can take the whole balance, now and in the future", colors.Error))
+		default:
+			fmt.Fprintf(out, "    Amount:   %s (base units)\n", grant.Amount)
+		}
+		if grant.Deadline != nil && grant.Deadline.Sign() > 0 {
+			if grant.Deadline.IsInt64() && grant.Deadline.Int64() < 253402300800 {
+				fmt.Fprintf(out, "    Expires:  %s\n", time.Unix(grant.Deadline.Int64(), 0).UTC().Format(time.RFC3339))
+			} else {
+				fmt.Fprintln(out, "    Expires:  never")
+			}
+		}
+	}
+	if len(grants) > 0 {
+		fmt.Fprintln(out, colors.SafeColor("  Only approve spenders you trust; a malicious or compromised spender can move these tokens without asking again.", colors.Warning))
+	}
+}
+
+// assetName names an asset by its symbol or address book label, and its
+// contract address
+func assetName(asset simulate.Asset, chainID int64) string {
+	if asset.Token == nil {
+		return simulate.NativeSymbol(chainID)
+	}
+	name := asset.Symbol
+	if name == "" {
+		name = addressbook.Label(asset.Token.Hex())
+	}
+	if name == "" {
+		return asset.Token.Hex()
+	}
+	return fmt.Sprintf("%s (%s)", name, asset.Token.Hex())
+}
+
+func isControlRune(r rune) bool {
+	return r < 0x20 && r != '\n' && r != '\t' || r == 0x7F
+}
+
+func init() {
+	airgapSignCmd.Flags().StringVar(&airgapSignKey, "key", "", "Only sign with addresses of this wallet.")
+	airgapSignCmd.Flags().BoolVar(&airgapSignYes, "yes", false, "Sign without asking for confirmation.")
+	airgapSignCmd.Flags().Int64Var(&airgapSignChainID, "chain-id", 0, "Only sign requests for this chain (default: any chain).")
+	airgapSignCmd.Flags().BoolVar(&airgapSignAllowUnprotected, "allow-unprotected", false, "Sign legacy transactions without an EIP-155 chain ID, which are valid on every chain.")
+	airgapSignCmd.Flags().BoolVar(&airgapSignAllowNonceReuse, "allow-nonce-reuse", false, "With --yes, sign a transaction whose nonce was already signed for another one.")
+	airgapSignCmd.Flags().BoolVar(&airgapSignNoSimulate, "no-simulate", false, "Do not run transactions against simulation_rpc; decode them offline only.")
+	addURDisplayFlags(airgapSignCmd)
+
+	airgapAccountCmd.Flags().BoolVar(&airgapAccountHDKey, "hdkey", false, "Export a bare crypto-hdkey instead of a crypto-account.")
+	addURDisplayFlags(airgapAccountCmd)
+}
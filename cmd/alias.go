@@ -0,0 +1,232 @@
+// File: cmd/alias.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+
+	"github.com/spf13/cobra"
+)
+
+// aliasPlaceholder in an alias expansion is replaced by the next argument given to the alias
+const aliasPlaceholder = "{}"
+
+var aliasNameRegex = regexp.MustCompile(`^[a-z][a-z0-9-]{0,31}$`)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manages user-defined command aliases.",
+	Long: `Manages user-defined command aliases.
+
+An alias expands to a vault.module command line before it runs. Each {} in
+the expansion takes the next argument given to the alias; the remaining
+arguments and flags are appended. Aliases cannot shadow built-in commands or
+refer to other aliases, and the expansion is split on whitespace (no quoting).
+
+Aliases are stored under "aliases" in config.json.
+
+Examples:
+  vault.module alias set pk = get {} privatekey
+  vault.module pk A1 --index 2          # runs: get A1 privatekey --index 2
+  vault.module alias list
+  vault.module alias remove pk
+`,
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the configured aliases.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if len(config.Cfg.Aliases) == 0 {
+				fmt.Println(colors.SafeColor("No aliases configured. Add one with 'vault.module alias set'.", colors.Info))
+				return nil
+			}
+			names := make([]string, 0, len(config.Cfg.Aliases))
+			for name := range config.Cfg.Aliases {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Printf("%s = %s\n", colors.SafeColor(name, colors.White), config.Cfg.Aliases[name])
+			}
+			return nil
+		})
+	},
+}
+
+var aliasSetCmd = &cobra.Command{
+	Use:                "set <NAME> = <COMMAND...>",
+	Short:              "Defines or replaces an alias.",
+	DisableFlagParsing: true, // flags belong to the expansion
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+				return cmd.Help()
+			}
+			if len(args) >= 2 && args[1] == "=" {
+				args = append(args[:1:1], args[2:]...)
+			}
+			if len(args) < 2 {
+				return errors.NewInvalidInputError(strings.Join(args, " "), "usage: alias set <NAME> = <COMMAND...>")
+			}
+			name := strings.ToLower(args[0])
+			expansion := strings.Join(args[1:], " ")
+			if !aliasNameRegex.MatchString(name) {
+				return errors.NewInvalidInputError(name, "alias names start with a letter and contain only lowercase letters, digits and hyphens (max 32)")
+			}
+			if isBuiltinCommand(name) {
+				return errors.NewInvalidInputError(name, "alias cannot shadow a built-in command")
+			}
+			if target := strings.Fields(expansion)[0]; !isBuiltinCommand(target) {
+				return errors.NewInvalidInputError(target, "alias must expand to a built-in command")
+			}
+
+			if config.Cfg.Aliases == nil {
+				config.Cfg.Aliases = make(map[string]string)
+			}
+			config.Cfg.Aliases[name] = expansion
+			if err := config.SaveConfig(); err != nil {
+				return errors.NewConfigSaveError("config.json", err)
+			}
+
+			audit.Logger.Info("Alias saved", slog.String("alias", name), slog.String("expansion", expansion))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Alias '%s' = %s", name, expansion), colors.Success))
+			return nil
+		})
+	},
+}
+
+var aliasRemoveCmd = &cobra.Command{
+	Use:   "remove <NAME>",
+	Short: "Removes an alias.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			name := strings.ToLower(args[0])
+			if _, ok := config.Cfg.Aliases[name]; !ok {
+				return errors.NewInvalidInputError(name, "no such alias")
+			}
+			delete(config.Cfg.Aliases, name)
+			if err := config.SaveConfig(); err != nil {
+				return errors.NewConfigSaveError("config.json", err)
+			}
+
+			audit.Logger.Info("Alias removed", slog.String("alias", name))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Alias '%s' removed.", name), colors.Success))
+			return nil
+		})
+	},
+}
+
+// isBuiltinCommand reports whether name is a top-level command of vault.module
+func isBuiltinCommand(name string) bool {
+	if name == "help" {
+		return true
+	}
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// aliasPosition returns the index in args of the command word when it could
+// be an alias, i.e. is not a built-in command, or -1
+func aliasPosition(args []string) int {
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--vault" {
+			i++ // skip the flag's value
+			continue
+		}
+		if !strings.HasPrefix(args[i], "-") {
+			if isBuiltinCommand(args[i]) {
+				return -1
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// expandAlias rewrites a command line whose command is a configured alias. It
+// reports false when the command line does not use an alias.
+func expandAlias(args []string) ([]string, bool, error) {
+	pos := aliasPosition(args)
+	if pos < 0 {
+		return nil, false, nil
+	}
+	expansion, ok := config.Cfg.Aliases[strings.ToLower(args[pos])]
+	if !ok {
+		return nil, false, nil
+	}
+
+	rest := args[pos+1:]
+	expanded := append([]string{}, args[:pos]...)
+	for _, token := range strings.Fields(expansion) {
+		for strings.Contains(token, aliasPlaceholder) {
+			if len(rest) == 0 {
+				return nil, false, errors.NewInvalidInputError(args[pos], fmt.Sprintf("alias '%s' expects %d argument(s): %s", args[pos], strings.Count(expansion, aliasPlaceholder), expansion))
+			}
+			token = strings.Replace(token, aliasPlaceholder, rest[0], 1)
+			rest = rest[1:]
+		}
+		expanded = append(expanded, token)
+	}
+	return append(expanded, rest...), true, nil
+}
+
+// applyVaultDefaults sets the flags of cmd configured in the active vault's defaults,
+// leaving flags given on the command line untouched
+func applyVaultDefaults(cmd *cobra.Command) error {
+	details, ok := config.Cfg.Vaults[config.Cfg.ActiveVault]
+	if !ok || details.Defaults == nil {
+		return nil
+	}
+	d := details.Defaults
+	values := map[string]string{}
+	if d.Index != 0 {
+		values["index"] = strconv.Itoa(d.Index)
+	}
+	if d.ClipboardTimeout != 0 {
+		values["clipboard-timeout"] = strconv.Itoa(d.ClipboardTimeout)
+	}
+	switch d.Output {
+	case "", "text":
+	case "json":
+		// Explicit output modes that exclude --json win over the default
+		explicit := false
+		for _, name := range []string{"ansible", "copy", "out-fd", "out-fifo"} {
+			if f := cmd.Flags().Lookup(name); f != nil && f.Changed {
+				explicit = true
+			}
+		}
+		if !explicit {
+			values["json"] = "true"
+		}
+	default:
+		return errors.NewConfigValidationError("vaults."+config.Cfg.ActiveVault+".defaults.output", d.Output, "output must be \"text\" or \"json\"")
+	}
+
+	for name, value := range values {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return errors.NewConfigValidationError("vaults."+config.Cfg.ActiveVault+".defaults", value, err.Error())
+		}
+	}
+	return nil
+}
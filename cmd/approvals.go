@@ -0,0 +1,329 @@
+// File: cmd/approvals.go
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"vault.module/internal/airgap"
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/display"
+	"vault.module/internal/errors"
+	"vault.module/internal/signqueue"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var approvalsListAll bool
+var approvalsRejectReason string
+var approvalsTokenRevoke bool
+
+// clientTokenEnv carries the token a programmatic client authenticates with
+const clientTokenEnv = "VAULT_MODULE_CLIENT_TOKEN"
+
+var approvalsCmd = &cobra.Command{
+	Use:   "approvals",
+	Short: "Reviews signing requests queued by programmatic clients.",
+	Long: `Reviews signing requests queued by programmatic clients.
+
+With "signing_queue" enabled, 'airgap sign' in programmatic mode queues its
+requests in signing-queue.json instead of signing them. A human reviews each
+request here; the client fetches the outcome with 'approvals result'.
+
+Auto-approval policies are configured per client in config.json:
+
+  "signing_policies": [
+    {"client": "ci-bot", "data_types": ["personal-message"], "chain_ids": [11155111]}
+  ]
+
+A client is identified by the token 'approvals token' issues to it, passed in
+VAULT_MODULE_CLIENT_TOKEN (or as the bearer token of 'web3signer'), never by a
+name it claims. config.json holds only the token's SHA-256.
+
+Examples:
+  vault.module approvals list
+  vault.module approvals review 3f9c2a1b7d4e8f60
+  vault.module approvals reject 3f9c2a1b7d4e8f60 --reason "unknown contract"
+  vault.module approvals result 3f9c2a1b7d4e8f60 --text
+  vault.module approvals token ci-bot
+`,
+}
+
+var approvalsTokenCmd = &cobra.Command{
+	Use:   "token <CLIENT>",
+	Short: "Issues the token a programmatic client authenticates with.",
+	Long: `Issues the token a programmatic client authenticates with.
+
+The token is printed once; config.json keeps only its SHA-256. A new token
+replaces the client's previous one, and --revoke removes it: the client's
+signing policies approve nothing until it has a token.
+
+Examples:
+  vault.module approvals token ci-bot
+  vault.module approvals token ci-bot --revoke
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("approvals token")
+			}
+			client := strings.TrimSpace(args[0])
+			if client == "" {
+				return errors.NewInvalidInputError(args[0], "client name must not be empty")
+			}
+
+			tokens := make([]config.ClientToken, 0, len(config.Cfg.ClientTokens)+1)
+			revoked := false
+			for _, t := range config.Cfg.ClientTokens {
+				if t.Client == client {
+					revoked = true
+					continue
+				}
+				tokens = append(tokens, t)
+			}
+
+			var token string
+			if !approvalsTokenRevoke {
+				bytes := make([]byte, 32)
+				if _, err := rand.Read(bytes); err != nil {
+					return errors.New(errors.ErrCodeInternal, "failed to generate client token").WithContext("error", err.Error())
+				}
+				token = hex.EncodeToString(bytes)
+				tokens = append(tokens, config.ClientToken{
+					Client:    client,
+					TokenHash: config.HashClientToken(token),
+					CreatedAt: time.Now().UTC().Format(time.RFC3339),
+				})
+			} else if !revoked {
+				return errors.NewInvalidInputError(client, "the client has no token")
+			}
+			config.Cfg.ClientTokens = tokens
+			if err := config.SaveConfig(); err != nil {
+				return errors.NewConfigSaveError("config.json", err)
+			}
+
+			if approvalsTokenRevoke {
+				audit.Logger.Warn("Client token revoked", slog.String("client", client))
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Token of client '%s' revoked.", client), colors.Success))
+				return nil
+			}
+			audit.Logger.Warn("Client token issued", slog.String("client", client), slog.Bool("replaced", revoked))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Token of client '%s' (shown only now; set it as %s):", client, clientTokenEnv), colors.Success))
+			fmt.Println(token)
+			return nil
+		})
+	},
+}
+
+// authenticatedClient returns the client whose token is in VAULT_MODULE_CLIENT_TOKEN
+func authenticatedClient(what string) (string, error) {
+	token := os.Getenv(clientTokenEnv)
+	if token == "" {
+		return "", errors.NewInvalidInputError(clientTokenEnv, fmt.Sprintf("%s requires the client's token of 'approvals token' in %s", what, clientTokenEnv))
+	}
+	client, ok := config.AuthenticateClient(token)
+	if !ok {
+		audit.Logger.Warn("Unknown client token", slog.String("operation", what))
+		return "", errors.NewAuthFailedError(fmt.Sprintf("the token in %s was not issued by 'approvals token' or was revoked", clientTokenEnv))
+	}
+	return client, nil
+}
+
+var approvalsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists pending signing requests.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			entries, err := signqueue.List()
+			if err != nil {
+				return err
+			}
+			shown := 0
+			for _, e := range entries {
+				if e.Status != signqueue.StatusPending && !approvalsListAll {
+					continue
+				}
+				summary := "unreadable request"
+				if message, err := hex.DecodeString(e.Request); err == nil {
+					if req, err := airgap.DecodeEthSignRequest(message); err == nil {
+						summary = fmt.Sprintf("%s on chain %d", airgap.EthDataTypeNames[req.DataType], req.ChainID)
+					}
+				}
+				status := colors.SafeColor(e.Status, colors.Warning)
+				switch e.Status {
+				case signqueue.StatusApproved:
+					status = colors.SafeColor(e.Status, colors.Success)
+				case signqueue.StatusRejected:
+					status = colors.SafeColor(e.Status, colors.Error)
+				}
+				fmt.Printf("%s  %-16s  %-10s %s (vault %s, %s)\n", e.ID, display.Time(e.CreatedAt), e.Client, summary, e.Vault, status)
+				shown++
+			}
+			if shown == 0 {
+				fmt.Println(colors.SafeColor("No pending signing requests.", colors.Info))
+			}
+			return nil
+		})
+	},
+}
+
+var approvalsReviewCmd = &cobra.Command{
+	Use:   "review <ID>",
+	Short: "Shows a queued signing request and signs it if approved.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("approvals review")
+			}
+			entry, req, message, err := pendingSignRequest(args[0])
+			if err != nil {
+				return err
+			}
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			fmt.Fprintf(os.Stderr, "Queued by client %s at %s\n", colors.SafeColor(entry.Client, colors.Bold), display.Time(entry.CreatedAt))
+			response, err := signEthRequest("approvals review", v, req, message, entry.Key, true)
+			if err != nil || response == nil {
+				return err
+			}
+			if _, err := signqueue.Decide(entry.ID, signqueue.StatusApproved, config.Cfg.Operator, "", response); err != nil {
+				// Another operator decided the request during the review
+				fmt.Fprintln(os.Stderr, colors.SafeColor("The signature made here was discarded; the client never receives it.", colors.Warning))
+				return err
+			}
+
+			audit.Logger.Warn("Queued signing request approved",
+				slog.String("command", "approvals review"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("client", entry.Client),
+				slog.String("request_id", entry.ID),
+			)
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Request %s approved and signed. The client can fetch the signature now.", entry.ID), colors.Success))
+			return nil
+		})
+	},
+}
+
+var approvalsRejectCmd = &cobra.Command{
+	Use:   "reject <ID>",
+	Short: "Rejects a queued signing request.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("approvals reject")
+			}
+			entry, _, _, err := pendingSignRequest(args[0])
+			if err != nil {
+				return err
+			}
+			if _, err := signqueue.Decide(entry.ID, signqueue.StatusRejected, config.Cfg.Operator, approvalsRejectReason, nil); err != nil {
+				return err
+			}
+
+			audit.Logger.Warn("Queued signing request rejected",
+				slog.String("command", "approvals reject"),
+				slog.String("vault", entry.Vault),
+				slog.String("client", entry.Client),
+				slog.String("request_id", entry.ID),
+				slog.String("reason", approvalsRejectReason),
+			)
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Request %s rejected.", entry.ID), colors.Success))
+			return nil
+		})
+	},
+}
+
+var approvalsResultCmd = &cobra.Command{
+	Use:   "result <ID>",
+	Short: "Shows the signature of an approved request.",
+	Long: `Shows the signature of an approved request.
+
+Used by programmatic clients to poll a queued request: the eth-signature UR is
+printed once the request is approved. A pending request fails with
+APPROVAL_PENDING and a rejected one with AUTH_FAILED.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			entry, err := signqueue.Get(args[0])
+			if err != nil {
+				return err
+			}
+			switch entry.Status {
+			case signqueue.StatusPending:
+				return errors.Newf(errors.ErrCodeApprovalPending, "signing request %s is waiting for approval", entry.ID)
+			case signqueue.StatusRejected:
+				err := errors.NewAuthFailedError(fmt.Sprintf("signing request %s was rejected", entry.ID))
+				if entry.Reason != "" {
+					err = err.WithDetails(entry.Reason)
+				}
+				return err
+			}
+			response, err := hex.DecodeString(entry.Response)
+			if err != nil {
+				return errors.NewFormatInvalidError(signqueue.StateFile, "stored signature is not valid hex")
+			}
+			digest := sha256.Sum256(response)
+			return emitUR(airgap.TypeEthSignature, response, hex.EncodeToString(digest[:]), verificationCode(response))
+		})
+	},
+}
+
+// pendingSignRequest loads a pending queue entry of the active vault and decodes its request
+func pendingSignRequest(id string) (*signqueue.Entry, *airgap.EthSignRequest, []byte, error) {
+	entry, err := signqueue.Get(id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if entry.Status != signqueue.StatusPending {
+		return nil, nil, nil, errors.NewInvalidInputError(id, fmt.Sprintf("signing request is already %s", entry.Status))
+	}
+	if entry.Vault != config.Cfg.ActiveVault {
+		return nil, nil, nil, errors.NewInvalidInputError(id, fmt.Sprintf("request was queued for vault '%s'; switch to it with 'vault.module vaults use %s'", entry.Vault, entry.Vault))
+	}
+	message, err := hex.DecodeString(entry.Request)
+	if err != nil {
+		return nil, nil, nil, errors.NewFormatInvalidError(signqueue.StateFile, "stored request is not valid hex")
+	}
+	req, err := airgap.DecodeEthSignRequest(message)
+	if err != nil {
+		return nil, nil, nil, errors.NewInvalidInputError("eth-sign-request", err.Error())
+	}
+	return entry, req, message, nil
+}
+
+func init() {
+	approvalsListCmd.Flags().BoolVar(&approvalsListAll, "all", false, "Include approved and rejected requests.")
+	approvalsRejectCmd.Flags().StringVar(&approvalsRejectReason, "reason", "", "Reason returned to the client.")
+	approvalsTokenCmd.Flags().BoolVar(&approvalsTokenRevoke, "revoke", false, "Remove the client's token instead of issuing one.")
+	addURDisplayFlags(approvalsResultCmd)
+}
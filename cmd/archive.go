@@ -0,0 +1,369 @@
+// File: cmd/archive.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+// archiveVaultSuffix names the default archive vault after the active vault
+const archiveVaultSuffix = "-archive"
+
+var archiveTo string
+var archiveRecipients string
+var archiveYes bool
+var unarchiveYes bool
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive <PREFIX...>",
+	Short: "Moves rarely used wallets to an archive vault.",
+	Long: `Moves rarely used wallets to an archive vault.
+
+The wallets are moved, with their secrets, to a separate encrypted vault, by
+default '<active vault>-archive' next to the active vault's file. The active
+vault keeps a stub of each: a watch-only entry with the public addresses,
+tags and notes, and where the wallet went. The active vault stays small, fast
+to decrypt and within memory_budget_mb, and 'list' shows the stubs as archived.
+
+The archive vault is created on first use with the active vault's encryption,
+and added to config.json. --recipients encrypts it to another age recipients
+file, e.g. a YubiKey kept in a safe; it only applies when the archive vault
+is created.
+
+Commands that need the secrets of an archived wallet refuse and point to
+'unarchive', which moves the wallet back. The archive vault is saved before
+the active vault: if the second save fails, the wallet is in both vaults
+rather than in none.
+
+Examples:
+  vault.module archive A1 A2
+  vault.module archive OLD1 --to cold --recipients safe-yubikey.txt
+  vault.module unarchive A1
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("archive")
+			}
+
+			archiveName := archiveTo
+			if archiveName == "" {
+				archiveName = config.Cfg.ActiveVault + archiveVaultSuffix
+			}
+			if archiveName == config.Cfg.ActiveVault {
+				return errors.NewInvalidInputError(archiveName, "wallets cannot be archived into the active vault")
+			}
+			archiveDetails, registered := config.Cfg.Vaults[archiveName]
+			if registered {
+				if archiveRecipients != "" {
+					return errors.NewInvalidInputError("--recipients", fmt.Sprintf("vault '%s' exists: --recipients only applies when the archive vault is created", archiveName))
+				}
+				if archiveDetails.Type != activeVault.Type {
+					return errors.NewInvalidInputError(archiveName, fmt.Sprintf("the archive vault holds %s wallets, the active vault %s wallets", archiveDetails.Type, activeVault.Type))
+				}
+			} else {
+				archiveDetails = derivedVaultDetails(activeVault, filepath.Join(filepath.Dir(activeVault.KeyFile), archiveName), activeVault.RecipientsFile)
+				if archiveRecipients != "" {
+					if _, err := os.Stat(archiveRecipients); err != nil {
+						return errors.NewFileSystemError("access", archiveRecipients, err).WithDetails("recipients file not found")
+					}
+					archiveDetails.RecipientsFile = archiveRecipients
+				}
+				if _, err := os.Stat(archiveDetails.KeyFile); err == nil {
+					return errors.NewInvalidInputError(archiveDetails.KeyFile, "a file by the archive vault's name exists; register it with 'vaults add' or pick another name with --to")
+				}
+			}
+			if sameFile(archiveDetails.KeyFile, activeVault.KeyFile) {
+				return errors.NewInvalidInputError(archiveName, "the archive vault is the active vault's file")
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			prefixes := uniqueStrings(args)
+			sort.Strings(prefixes)
+			for _, prefix := range prefixes {
+				wallet, exists := v[prefix]
+				if !exists {
+					return errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
+				}
+				if err := checkWalletNotFrozen("archive", prefix, wallet); err != nil {
+					return err
+				}
+			}
+
+			archived := make(vault.Vault)
+			if registered {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Opening archive vault '%s'.", archiveName), colors.Info))
+				if archived, err = vault.LoadVault(archiveDetails); err != nil {
+					return errors.NewVaultLoadError(archiveDetails.KeyFile, err)
+				}
+			} else {
+				// A new archive vault is saved with the active vault's second factor passphrase
+				vault.ShareSecondFactorPassphrase(activeVault.KeyFile, archiveDetails.KeyFile)
+			}
+			defer func() {
+				for _, wallet := range archived {
+					wallet.Clear()
+				}
+			}()
+			for _, prefix := range prefixes {
+				if _, exists := archived[prefix]; exists {
+					return errors.NewWalletExistsError(prefix).WithDetails(fmt.Sprintf("archive vault '%s' already holds a wallet '%s'", archiveName, prefix))
+				}
+			}
+
+			if !archiveYes {
+				prompt := fmt.Sprintf("Move %s from '%s' to archive vault '%s' (%s)?", strings.Join(prefixes, ", "), config.Cfg.ActiveVault, archiveName, archiveDetails.KeyFile)
+				if !askForConfirmation(colors.SafeColor(prompt, colors.Warning)) {
+					fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+					return nil
+				}
+			}
+
+			for _, prefix := range prefixes {
+				archived[prefix] = v[prefix]
+			}
+			if err := vault.SaveVault(archiveDetails, archived); err != nil {
+				return errors.NewVaultSaveError(archiveDetails.KeyFile, err)
+			}
+			if !registered {
+				if config.Cfg.Vaults == nil {
+					config.Cfg.Vaults = make(map[string]config.VaultDetails)
+				}
+				config.Cfg.Vaults[archiveName] = archiveDetails
+				if err := config.SaveConfig(); err != nil {
+					return errors.NewConfigSaveError("config.json", err)
+				}
+			}
+
+			since := time.Now().UTC().Format(time.RFC3339)
+			for _, prefix := range prefixes {
+				v[prefix] = vault.ArchiveStub(v[prefix], vault.ArchiveRef{
+					Vault:   archiveName,
+					KeyFile: archiveDetails.KeyFile,
+					Prefix:  prefix,
+					Since:   since,
+				})
+			}
+			if err := vault.SaveVault(activeVault, v); err != nil {
+				fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("The wallets are in archive vault '%s' but still in '%s' too.", archiveName, config.Cfg.ActiveVault), colors.Warning))
+				return errors.NewVaultSaveError(activeVault.KeyFile, err)
+			}
+
+			audit.Logger.Warn("Wallets archived",
+				slog.String("command", "archive"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("archive_vault", archiveName),
+				slog.String("prefixes", strings.Join(prefixes, ",")),
+				slog.String("recipients_file", filepath.Base(archiveDetails.RecipientsFile)),
+				slog.Bool("created", !registered),
+			)
+			if !registered {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Archive vault '%s' created at '%s', encrypted to the recipients in '%s'.", archiveName, archiveDetails.KeyFile, archiveDetails.RecipientsFile), colors.Info))
+			}
+			fmt.Println(colors.SafeColor(fmt.Sprintf("%d wallets moved to archive vault '%s': %s.", len(prefixes), archiveName, strings.Join(prefixes, ", ")), colors.Success))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("💡 '%s' keeps watch-only stubs; 'unarchive <PREFIX>' brings a wallet back.", config.Cfg.ActiveVault), colors.Info))
+			return nil
+		})
+	},
+}
+
+var unarchiveCmd = &cobra.Command{
+	Use:   "unarchive <PREFIX...>",
+	Short: "Moves archived wallets back into the active vault.",
+	Long: `Moves archived wallets back into the active vault.
+
+Each stub left by 'archive' is replaced by the wallet from its archive vault,
+with the tags and notes of the stub, which may have been edited since. The
+active vault is saved before the wallet is removed from the archive vault: if
+the second save fails, the wallet is in both vaults rather than in none.
+
+Examples:
+  vault.module unarchive A1
+  vault.module unarchive A1 A2 --yes
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("unarchive")
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			// The stubs, by the archive vault holding their wallets
+			prefixes := uniqueStrings(args)
+			sort.Strings(prefixes)
+			byArchive := map[string][]string{}
+			details := map[string]config.VaultDetails{}
+			for _, prefix := range prefixes {
+				stub, exists := v[prefix]
+				if !exists {
+					return errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
+				}
+				if !stub.Archived() {
+					return errors.NewInvalidInputError(prefix, "the wallet is not archived")
+				}
+				name, d, err := archiveVaultDetails(*stub.Archive)
+				if err != nil {
+					return err
+				}
+				byArchive[name] = append(byArchive[name], prefix)
+				details[name] = d
+			}
+			names := make([]string, 0, len(byArchive))
+			for name := range byArchive {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			if !unarchiveYes {
+				prompt := fmt.Sprintf("Move %s back from %s into '%s'?", strings.Join(prefixes, ", "), quotedList(names), config.Cfg.ActiveVault)
+				if !askForConfirmation(colors.SafeColor(prompt, colors.Warning)) {
+					fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+					return nil
+				}
+			}
+
+			archives := map[string]vault.Vault{}
+			restored := map[string][]string{} // Prefixes in each archive vault
+			defer func() {
+				for _, archived := range archives {
+					for _, wallet := range archived {
+						wallet.Clear()
+					}
+				}
+			}()
+			for _, name := range names {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Opening archive vault '%s'.", name), colors.Info))
+				archived, err := vault.LoadVault(details[name])
+				if err != nil {
+					return errors.NewVaultLoadError(details[name].KeyFile, err)
+				}
+				archives[name] = archived
+				for _, prefix := range byArchive[name] {
+					stub := v[prefix]
+					wallet, exists := archived[stub.Archive.Prefix]
+					if !exists {
+						return errors.NewWalletNotFoundError(stub.Archive.Prefix, name)
+					}
+					wallet.Tags = stub.Tags
+					wallet.Notes = stub.Notes
+					v[prefix] = wallet
+					restored[name] = append(restored[name], stub.Archive.Prefix)
+				}
+			}
+
+			if err := vault.SaveVault(activeVault, v); err != nil {
+				return errors.NewVaultSaveError(activeVault.KeyFile, err)
+			}
+			for _, name := range names {
+				archived := archives[name]
+				for _, prefix := range restored[name] {
+					delete(archived, prefix)
+				}
+				if err := vault.SaveVault(details[name], archived); err != nil {
+					fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("%s are back in '%s' but still in archive vault '%s' too.", strings.Join(byArchive[name], ", "), config.Cfg.ActiveVault, name), colors.Warning))
+					return errors.NewVaultSaveError(details[name].KeyFile, err)
+				}
+			}
+
+			audit.Logger.Warn("Wallets unarchived",
+				slog.String("command", "unarchive"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("archive_vaults", strings.Join(names, ",")),
+				slog.String("prefixes", strings.Join(prefixes, ",")),
+			)
+			fmt.Println(colors.SafeColor(fmt.Sprintf("%d wallets moved back into '%s': %s.", len(prefixes), config.Cfg.ActiveVault, strings.Join(prefixes, ", ")), colors.Success))
+			return nil
+		})
+	},
+}
+
+// archiveVaultDetails finds the archive vault of a stub in config.json, by
+// name or, if it was renamed, by file
+func archiveVaultDetails(ref vault.ArchiveRef) (string, config.VaultDetails, error) {
+	if details, ok := config.Cfg.Vaults[ref.Vault]; ok && sameFile(details.KeyFile, ref.KeyFile) {
+		return ref.Vault, details, nil
+	}
+	for name, details := range config.Cfg.Vaults {
+		if sameFile(details.KeyFile, ref.KeyFile) {
+			return name, details, nil
+		}
+	}
+	return "", config.VaultDetails{}, errors.NewVaultNotFoundError(ref.Vault).
+		WithDetails(fmt.Sprintf("the wallet was archived to '%s'; register that file again with 'vaults add'", ref.KeyFile))
+}
+
+// uniqueStrings drops repeated values, keeping the first of each
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+// quotedList quotes names and joins them
+func quotedList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "'" + name + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func init() {
+	archiveCmd.Flags().StringVar(&archiveTo, "to", "", "Archive vault to move the wallets to (default '<active vault>-archive').")
+	archiveCmd.Flags().StringVar(&archiveRecipients, "recipients", "", "Age recipients file to encrypt a new archive vault to (default: the active vault's).")
+	archiveCmd.Flags().BoolVarP(&archiveYes, "yes", "y", false, "Move the wallets without confirmation.")
+	unarchiveCmd.Flags().BoolVarP(&unarchiveYes, "yes", "y", false, "Move the wallets back without confirmation.")
+}
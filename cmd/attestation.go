@@ -0,0 +1,244 @@
+// File: cmd/attestation.go
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/display"
+	"vault.module/internal/errors"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var vaultsTrustVaultID string
+var vaultsTrustRemove bool
+
+var vaultsAttestationCmd = &cobra.Command{
+	Use:   "attestation [NAME]",
+	Short: "Shows the ID and export signing key of a vault.",
+	Long: `Shows the ID and export signing key of a vault.
+
+Every export is signed with an Ed25519 key held inside the encrypted vault and
+names the vault by a UUID; both are created with the first export, or by this
+command. Vaults of this installation are trusted here as soon as they sign.
+Run the printed 'vaults trust' command on the installations that should accept
+this vault's exports with 'import --verify-signature'.
+
+Examples:
+  vault.module vaults attestation
+  vault.module vaults attestation cold
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			name, details, err := vaultFromArgs(args)
+			if err != nil {
+				return err
+			}
+			v, err := vault.LoadVault(details)
+			if err != nil {
+				return errors.NewVaultLoadError(details.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			vaultID, publicKey, err := vault.AttestationIdentity(details, v)
+			if err != nil {
+				return err
+			}
+			if err := trustExporter(name, vaultID, publicKey); err != nil {
+				return err
+			}
+
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Vault '%s'", name), colors.Bold))
+			fmt.Printf("  ID:          %s\n", vaultID)
+			fmt.Printf("  Signing key: %s\n", publicKey)
+			fmt.Println("Trust its exports on another installation with:")
+			fmt.Printf("  vault.module vaults trust %s %s --vault-id %s\n", name, publicKey, vaultID)
+			return nil
+		})
+	},
+}
+
+var vaultsTrustCmd = &cobra.Command{
+	Use:   "trust [NAME PUBLIC_KEY]",
+	Short: "Lists or adds the vaults whose signed exports are accepted.",
+	Long: `Lists or adds the vaults whose signed exports are accepted.
+
+Without arguments the trusted vaults are listed. With a name and the signing
+key printed by 'vaults attestation' on the exporting installation, the vault
+is trusted; --remove NAME withdraws the trust. 'import --verify-signature'
+only accepts exports signed by a trusted vault.
+
+Examples:
+  vault.module vaults trust
+  vault.module vaults trust office-main 3q2+7w... --vault-id 5f0c...
+  vault.module vaults trust office-main --remove
+`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			switch {
+			case vaultsTrustRemove:
+				if len(args) != 1 {
+					return errors.NewInvalidInputError("NAME", "--remove takes the name of a trusted vault")
+				}
+				return untrustExporter(args[0])
+			case len(args) == 0:
+				if len(config.Cfg.TrustedExporters) == 0 {
+					fmt.Println(colors.SafeColor("No vaults are trusted.", colors.Info))
+					return nil
+				}
+				for _, t := range config.Cfg.TrustedExporters {
+					fmt.Printf("- %s %s\n", colors.SafeColor(t.Name, colors.Bold), t.VaultID)
+					fmt.Println(colors.SafeColor("  "+t.PublicKey, colors.Dim))
+				}
+				return nil
+			case len(args) == 1:
+				return errors.NewInvalidInputError("PUBLIC_KEY", "give the signing key printed by 'vaults attestation'")
+			}
+
+			name, publicKey := args[0], args[1]
+			if key, err := base64.StdEncoding.DecodeString(publicKey); err != nil || len(key) != 32 {
+				return errors.NewInvalidInputError(publicKey, "not a base64 Ed25519 public key")
+			}
+			for _, t := range config.Cfg.TrustedExporters {
+				if t.Name == name && t.PublicKey != publicKey {
+					return errors.NewInvalidInputError(name, "a different key is trusted under this name; remove it first")
+				}
+			}
+			if err := trustExporter(name, vaultsTrustVaultID, publicKey); err != nil {
+				return err
+			}
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Exports signed by '%s' are trusted.", name), colors.Success))
+			return nil
+		})
+	},
+}
+
+// trustExporter adds a vault to the trusted exporters, unless its key is trusted already
+func trustExporter(name, vaultID, publicKey string) error {
+	if _, found := config.FindTrustedExporter(publicKey); found {
+		return nil
+	}
+	config.Cfg.TrustedExporters = append(config.Cfg.TrustedExporters, config.TrustedExporter{
+		Name:      name,
+		VaultID:   vaultID,
+		PublicKey: publicKey,
+	})
+	if err := config.SaveConfig(); err != nil {
+		return errors.NewConfigSaveError("config.json", err)
+	}
+	audit.Logger.Info("Exporting vault trusted",
+		slog.String("name", name),
+		slog.String("vault_id", vaultID),
+		slog.String("public_key", publicKey))
+	return nil
+}
+
+// untrustExporter removes a vault from the trusted exporters
+func untrustExporter(name string) error {
+	kept := config.Cfg.TrustedExporters[:0]
+	for _, t := range config.Cfg.TrustedExporters {
+		if t.Name != name {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == len(config.Cfg.TrustedExporters) {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("No vault named '%s' is trusted.", name), colors.Info))
+		return nil
+	}
+	config.Cfg.TrustedExporters = kept
+	if err := config.SaveConfig(); err != nil {
+		return errors.NewConfigSaveError("config.json", err)
+	}
+	audit.Logger.Warn("Exporting vault no longer trusted", slog.String("name", name))
+	fmt.Println(colors.SafeColor(fmt.Sprintf("Exports signed by '%s' are no longer trusted.", name), colors.Success))
+	return nil
+}
+
+// signExport writes the attestation of an export file next to it. The vault
+// signing it is trusted by this installation.
+func signExport(name string, details config.VaultDetails, v vault.Vault, data []byte, outputFile string) error {
+	attestation, err := vault.AttestExport(details, v, data, outputFile)
+	if err != nil {
+		return err
+	}
+	if err := trustExporter(name, attestation.VaultID, attestation.PublicKey); err != nil {
+		return err
+	}
+	attestationData, err := json.MarshalIndent(attestation, "", "  ")
+	if err != nil {
+		return errors.New(errors.ErrCodeInternal, "failed to serialize attestation").WithContext("marshal_error", err.Error())
+	}
+	attestationFile := outputFile + vault.AttestationSuffix
+	if err := os.WriteFile(attestationFile, append(attestationData, '\n'), 0600); err != nil {
+		return errors.NewFileSystemError("write", attestationFile, err)
+	}
+	audit.Logger.Info("Export signed",
+		slog.String("vault", name),
+		slog.String("vault_id", attestation.VaultID),
+		slog.String("file", filepath.Base(outputFile)),
+		slog.String("sha256", attestation.SHA256))
+	fmt.Printf("Signed by vault %s; attestation written to '%s'.\n", attestation.VaultID, attestationFile)
+	return nil
+}
+
+// verifyImportSignature checks the attestation next to an import file: it must
+// sign the file's contents and come from a trusted vault.
+func verifyImportSignature(format, filePath string, content []byte) error {
+	attestationFile := filePath + vault.AttestationSuffix
+	attestationData, err := os.ReadFile(attestationFile)
+	if os.IsNotExist(err) {
+		return errors.NewImportFailedError(format, fmt.Sprintf("%s has no attestation (%s)", filepath.Base(filePath), filepath.Base(attestationFile)), err)
+	}
+	if err != nil {
+		return errors.NewFileSystemError("read", attestationFile, err)
+	}
+	var attestation vault.Attestation
+	if err := json.Unmarshal(attestationData, &attestation); err != nil {
+		return errors.NewImportFailedError(format, "the attestation is not valid JSON", err)
+	}
+	if err := vault.VerifyAttestation(attestation, content); err != nil {
+		audit.Logger.Error("Import signature verification failed",
+			slog.String("file", filepath.Base(filePath)),
+			slog.String("vault_id", attestation.VaultID),
+			slog.String("error", err.Error()))
+		return errors.NewImportFailedError(format, "signature verification failed", err)
+	}
+	trusted, found := config.FindTrustedExporter(attestation.PublicKey)
+	if !found {
+		audit.Logger.Error("Import signed by an untrusted vault",
+			slog.String("file", filepath.Base(filePath)),
+			slog.String("vault_id", attestation.VaultID),
+			slog.String("public_key", attestation.PublicKey))
+		return errors.NewImportFailedError(format, fmt.Sprintf("the file is signed by vault %s, which is not trusted; trust it with 'vaults trust' if it is yours", attestation.VaultID), nil)
+	}
+
+	exported := display.TimeString(attestation.CreatedAt)
+	audit.Logger.Info("Import signature verified",
+		slog.String("file", filepath.Base(filePath)),
+		slog.String("trusted_as", trusted.Name),
+		slog.String("vault_id", attestation.VaultID),
+		slog.String("tool", attestation.Tool))
+	fmt.Println(colors.SafeColor(fmt.Sprintf("Signature verified: exported %s from vault '%s' (%s) by vault.module %s.",
+		exported, trusted.Name, attestation.VaultID, attestation.Tool), colors.Success))
+	return nil
+}
+
+func init() {
+	vaultsTrustCmd.Flags().StringVar(&vaultsTrustVaultID, "vault-id", "", "ID of the trusted vault, as printed by 'vaults attestation'")
+	vaultsTrustCmd.Flags().BoolVar(&vaultsTrustRemove, "remove", false, "Withdraw the trust in the named vault")
+}
@@ -0,0 +1,184 @@
+// File: cmd/auditexport.go
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/errors"
+
+	"github.com/spf13/cobra"
+)
+
+var auditExportFrom string
+var auditExportTo string
+var auditExportFormat string
+var auditExportOut string
+var auditExportLog string
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Works with the audit log.",
+}
+
+var auditExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports audit events as CSV or Parquet for reviewers.",
+	Long: `Exports audit events as CSV or Parquet for reviewers.
+
+Every event becomes one row with the same columns:
+
+  time        UTC, RFC 3339 in CSV, a millisecond timestamp in Parquet
+  seq         the record's sequence number (empty for records before it existed)
+  level       INFO, WARN or ERROR
+  message     what happened
+  command     the vault.module command that logged it
+  vault       the vault it concerned (vault or vault_name attribute)
+  prefix      the wallet it concerned
+  field       the data accessed (address, privateKey, mnemonic, ...)
+  operator    the operator in organization mode
+  error       the error, for failures
+  attributes  all other attributes as a JSON object
+
+Empty cells are empty strings in CSV and nulls in Parquet. Rows are in log
+order, which the sequence numbers keep even when the clock was changed.
+--from is inclusive and --to exclusive; both take a date (2026-01-31,
+midnight UTC) or an RFC 3339 time.
+
+Examples:
+  vault.module audit export --from 2026-01-01 --to 2026-04-01 --out q1.csv
+  vault.module audit export --format parquet --out audit.parquet
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			from, err := parseAuditTime("--from", auditExportFrom)
+			if err != nil {
+				return err
+			}
+			to, err := parseAuditTime("--to", auditExportTo)
+			if err != nil {
+				return err
+			}
+			if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+				return errors.NewInvalidInputError(auditExportTo, "--to must be after --from")
+			}
+
+			format := strings.ToLower(auditExportFormat)
+			write := audit.WriteCSV
+			switch format {
+			case "csv":
+			case "parquet":
+				write = audit.WriteParquet
+				if auditExportOut == "" && term.IsTerminal(int(os.Stdout.Fd())) {
+					return errors.NewInvalidInputError("--out", "Parquet is binary; write it to a file with --out")
+				}
+			default:
+				return errors.NewInvalidInputError(auditExportFormat, "--format must be csv or parquet")
+			}
+
+			events, skipped, err := audit.ReadEvents(auditExportLog, from, to)
+			if err != nil {
+				return errors.NewFileSystemError("read", auditExportLog, err)
+			}
+
+			var out io.Writer = os.Stdout
+			if auditExportOut != "" {
+				f, err := os.OpenFile(auditExportOut, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+				if err != nil {
+					return errors.NewFileSystemError("create", auditExportOut, err)
+				}
+				defer f.Close()
+				out = f
+			}
+			if err := write(out, events); err != nil {
+				return errors.NewExportFailedError(format, "failed to write audit events", err)
+			}
+
+			audit.Logger.Info("Audit log exported", slog.String("format", format), slog.Int("count", len(events)), slog.String("from", auditExportFrom), slog.String("to", auditExportTo))
+			if auditExportOut != "" {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("%d audit event(s) exported to '%s'.", len(events), auditExportOut), colors.Success))
+			}
+			if skipped > 0 {
+				fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("Warning: %d line(s) of %s are not audit records and were skipped.", skipped, auditExportLog), colors.Warning))
+			}
+			return nil
+		})
+	},
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Checks the sequence numbers and hash chain of the audit log.",
+	Long: `Checks the sequence numbers and hash chain of the audit log.
+
+Every audit record carries a sequence number (seq) one above the record
+before it and the SHA-256 of that record's line (prev). verify reads the log
+in order and reports removed, inserted, reordered or edited records. Records
+written before sequence numbers existed are counted but not checked.
+
+Timestamps are UTC and only reported: a record timestamped before the one
+preceding it means the clock was changed, which does not break the chain.
+
+Examples:
+  vault.module audit verify
+  vault.module audit verify --log /backups/audit.log
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			report, err := audit.VerifyChain(auditExportLog)
+			if err != nil {
+				return errors.NewFileSystemError("read", auditExportLog, err)
+			}
+
+			fmt.Printf("Records:   %d\n", report.Records)
+			if report.Unchained > 0 {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Unchained: %d (written before sequence numbers)", report.Unchained), colors.Dim))
+			}
+			if report.ClockJumps > 0 {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Clock set back %d time(s); the sequence keeps the order.", report.ClockJumps), colors.Warning))
+			}
+			if len(report.Problems) > 0 {
+				for _, problem := range report.Problems {
+					fmt.Println(colors.SafeColor("  "+problem, colors.Error))
+				}
+				audit.Logger.Warn("Audit chain broken", slog.String("log", auditExportLog), slog.Int("problems", len(report.Problems)))
+				return errors.New(errors.ErrCodeVaultTampered, fmt.Sprintf("audit log '%s' chain is broken in %d place(s)", auditExportLog, len(report.Problems)))
+			}
+			fmt.Println(colors.SafeColor("Audit chain intact.", colors.Success))
+			return nil
+		})
+	},
+}
+
+// parseAuditTime reads a date or RFC 3339 time; empty leaves the range open
+func parseAuditTime(flag, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.NewInvalidInputError(value, flag+" must be a date (YYYY-MM-DD) or an RFC 3339 time")
+	}
+	return t, nil
+}
+
+func init() {
+	auditExportCmd.Flags().StringVar(&auditExportFrom, "from", "", "First day or time to include (inclusive).")
+	auditExportCmd.Flags().StringVar(&auditExportTo, "to", "", "Day or time to stop at (exclusive).")
+	auditExportCmd.Flags().StringVar(&auditExportFormat, "format", "csv", "Output format: csv or parquet.")
+	auditExportCmd.Flags().StringVar(&auditExportOut, "out", "", "File to write (default: stdout).")
+	auditExportCmd.Flags().StringVar(&auditExportLog, "log", "audit.log", "Audit log to read.")
+	auditVerifyCmd.Flags().StringVar(&auditExportLog, "log", "audit.log", "Audit log to check.")
+}
@@ -0,0 +1,121 @@
+// File: cmd/auditrotate.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+
+	"github.com/spf13/cobra"
+)
+
+var auditRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Archives the audit log now.",
+	Long: `Archives the audit log now.
+
+audit.log is compressed to audit-<UTC time>.log.gz next to it and starts over.
+Its first record continues the sequence numbers and hash chain of the archive,
+so 'audit verify --log <archive>' and 'audit verify' together check the whole
+history; 'audit export --log <archive>' reads archives too.
+
+Every command rotates the log on its own when it outgrows the "audit_rotation"
+section of config.json:
+
+  "audit_rotation": {
+    "max_size_mb": 10,              rotate when audit.log is larger
+    "max_age_days": 30,             rotate when its first record is older
+    "keep": 12,                     archives to keep (default: all)
+    "encrypt_vault": "trading"      encrypt archives to this vault's recipients
+  }
+
+With encrypt_vault, archives are audit-<UTC time>.log.gz.age, encrypted with
+age to the vault's recipients file and operators: historical access logs are
+not plaintext on disk, and reading one takes the vault's key:
+
+  age -d -i <identity> audit-20260101T000000Z.log.gz.age | gunzip
+
+Examples:
+  vault.module audit rotate
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			policy, err := auditRotationPolicy()
+			if err != nil {
+				return err
+			}
+			archive, err := audit.Rotate(policy)
+			if err != nil {
+				return errors.Wrap(errors.ErrCodeSystem, "failed to rotate the audit log", err)
+			}
+			if archive == "" {
+				fmt.Println(colors.SafeColor("The audit log is empty; nothing to rotate.", colors.Info))
+				return nil
+			}
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Audit log archived to '%s'.", archive), colors.Success))
+			return nil
+		})
+	},
+}
+
+// auditRotationPolicy reads the audit_rotation section of config.json. The
+// archives of an encrypt_vault are encrypted to its recipients file and its
+// operators, like the vault itself.
+func auditRotationPolicy() (audit.RotationPolicy, error) {
+	var policy audit.RotationPolicy
+	r := config.Cfg.AuditRotation
+	if r == nil {
+		return policy, nil
+	}
+	if r.MaxSizeMB < 0 || r.MaxAgeDays < 0 || r.Keep < 0 {
+		return policy, errors.NewConfigValidationError("audit_rotation", "", "max_size_mb, max_age_days and keep must not be negative")
+	}
+	policy.MaxSize = int64(r.MaxSizeMB) * 1024 * 1024
+	policy.MaxAge = time.Duration(r.MaxAgeDays) * 24 * time.Hour
+	policy.Keep = r.Keep
+
+	if r.EncryptVault != "" {
+		details, ok := config.Cfg.Vaults[r.EncryptVault]
+		if !ok {
+			return policy, errors.NewConfigValidationError("audit_rotation.encrypt_vault", r.EncryptVault, fmt.Sprintf("vault '%s' is not defined in config.json", r.EncryptVault))
+		}
+		policy.RecipientsFile = details.RecipientsFile
+		for _, name := range details.Operators {
+			op, ok := config.FindOperator(name)
+			if !ok {
+				return policy, errors.NewConfigValidationError("operators", name, fmt.Sprintf("vault grants access to operator '%s', who is not defined in config.json", name))
+			}
+			policy.Recipients = append(policy.Recipients, op.Recipient)
+		}
+		if !policy.Encrypted() {
+			return policy, errors.NewConfigValidationError("audit_rotation.encrypt_vault", r.EncryptVault, fmt.Sprintf("vault '%s' has no recipients file or operators to encrypt to", r.EncryptVault))
+		}
+	}
+	return policy, nil
+}
+
+// rotateAuditLog rotates the audit log when it is due. A failed rotation is
+// reported but does not stop the command: the log keeps growing until the
+// next attempt.
+func rotateAuditLog() {
+	policy, err := auditRotationPolicy()
+	if err == nil {
+		var archive string
+		archive, err = audit.RotateIfDue(policy)
+		if archive != "" {
+			fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("Audit log archived to '%s'.", filepath.Base(archive)), colors.Dim))
+		}
+	}
+	if err != nil {
+		audit.Logger.Error("Audit log rotation failed", slog.String("error", err.Error()))
+		fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("Warning: audit log rotation failed: %v", err), colors.Warning))
+	}
+}
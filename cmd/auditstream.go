@@ -0,0 +1,109 @@
+// File: cmd/auditstream.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"vault.module/internal/audit"
+	"vault.module/internal/auditstream"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+
+	"github.com/spf13/cobra"
+)
+
+var auditStreamListen string
+var auditStreamTLSCert string
+var auditStreamTLSKey string
+
+var auditStreamCmd = &cobra.Command{
+	Use:   "audit-stream",
+	Short: "Streams audit events to subscribers over gRPC.",
+	Long: `Streams audit events to subscribers over gRPC.
+
+Runs a gRPC server whose AuditStream.Subscribe RPC pushes every record appended
+to audit.log, by this or any other vault.module process, in real time. SIEM agents
+can subscribe instead of tailing the log file. The service definition is in
+internal/auditstream/audit.proto.
+
+Subscribers authenticate with the programmatic token ('token generate') sent as
+"authorization: Bearer <token>" metadata. Non-loopback addresses require TLS.
+
+Examples:
+  vault.module audit-stream
+  vault.module audit-stream --listen 0.0.0.0:50051 --tls-cert server.crt --tls-key server.key
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if config.Cfg.AuthToken == "" {
+				return errors.NewConfigMissingError("authtoken").
+					WithDetails("generate a token with 'vault.module token generate' for subscribers to authenticate")
+			}
+
+			host, _, err := net.SplitHostPort(auditStreamListen)
+			if err != nil {
+				return errors.NewInvalidInputError(auditStreamListen, "--listen must be host:port")
+			}
+			useTLS := auditStreamTLSCert != "" || auditStreamTLSKey != ""
+			if useTLS && (auditStreamTLSCert == "" || auditStreamTLSKey == "") {
+				return errors.NewInvalidInputError("--tls-cert/--tls-key", "both a certificate and a key are required for TLS")
+			}
+			if ip := net.ParseIP(host); !useTLS && (ip == nil || !ip.IsLoopback()) {
+				return errors.NewInvalidInputError(auditStreamListen, "non-loopback addresses require --tls-cert and --tls-key")
+			}
+
+			var opts []grpc.ServerOption
+			if useTLS {
+				creds, err := credentials.NewServerTLSFromFile(auditStreamTLSCert, auditStreamTLSKey)
+				if err != nil {
+					return errors.New(errors.ErrCodeConfigValidation, "failed to load TLS certificate").WithContext("error", err.Error())
+				}
+				opts = append(opts, grpc.Creds(creds))
+			}
+
+			listener, err := net.Listen("tcp", auditStreamListen)
+			if err != nil {
+				return errors.New(errors.ErrCodeSystem, "failed to listen").WithContext("address", auditStreamListen).WithContext("error", err.Error())
+			}
+
+			server := auditstream.NewServer("audit.log", config.Cfg.AuthToken)
+			grpcServer := grpc.NewServer(opts...)
+			server.Register(grpcServer)
+
+			ctx := security.GetManager().Context()
+			go func() {
+				if err := server.Follow(ctx); err != nil {
+					audit.Logger.Error("Audit stream stopped following the log", slog.String("error", err.Error()))
+				}
+			}()
+			go func() {
+				<-ctx.Done()
+				grpcServer.Stop()
+			}()
+
+			audit.Logger.Info("Audit stream started", slog.String("command", "audit-stream"), slog.String("addr", listener.Addr().String()), slog.Bool("tls", useTLS))
+			if !programmaticMode {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Audit stream listening on %s (service %s).", listener.Addr(), auditstream.ServiceName), colors.Success))
+				fmt.Println(colors.SafeColor("Press Ctrl+C to stop.", colors.Info))
+			}
+
+			if err := grpcServer.Serve(listener); err != nil {
+				return errors.New(errors.ErrCodeSystem, "audit stream server failed").WithContext("error", err.Error())
+			}
+			return nil
+		})
+	},
+}
+
+func init() {
+	auditStreamCmd.Flags().StringVar(&auditStreamListen, "listen", "127.0.0.1:50051", "Address to listen on.")
+	auditStreamCmd.Flags().StringVar(&auditStreamTLSCert, "tls-cert", "", "TLS certificate file (required for non-loopback addresses).")
+	auditStreamCmd.Flags().StringVar(&auditStreamTLSKey, "tls-key", "", "TLS private key file.")
+}
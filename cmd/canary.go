@@ -0,0 +1,227 @@
+// File: cmd/canary.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"vault.module/internal/actions"
+	"vault.module/internal/audit"
+	"vault.module/internal/canary"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/errors"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var canaryCmd = &cobra.Command{
+	Use:   "canary",
+	Short: "Manages decoy wallets that reveal a vault compromise.",
+	Long: `Manages canary wallets.
+
+A canary is a decoy wallet stored in a vault like any other wallet. It is never
+used, so any on-chain activity on its address means someone has extracted keys
+from the vault. Canaries are recorded in config.json, not in the vault itself.
+`,
+}
+
+var canaryCreateCmd = &cobra.Command{
+	Use:   "create <PREFIX>",
+	Short: "Creates a decoy wallet in the active vault.",
+	Long: `Creates a decoy wallet in the active vault and starts monitoring its address.
+
+Pick a prefix that looks like a real wallet; the decoy is indistinguishable
+from other wallets when listing the vault.
+
+Examples:
+  vault.module canary create Treasury2
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("canary create")
+			}
+
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+
+			prefix := args[0]
+			if err := validatePrefix(prefix); err != nil {
+				return err
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			if _, exists := v[prefix]; exists {
+				return errors.NewWalletExistsError(prefix)
+			}
+
+			newWallet, address, err := actions.CreateRandomWallet(activeVault.Type)
+			if err != nil {
+				return errors.NewWalletInvalidError(prefix, err.Error())
+			}
+
+			v[prefix] = newWallet
+			if err := vault.SaveVault(activeVault, v); err != nil {
+				return errors.NewVaultSaveError(activeVault.KeyFile, err)
+			}
+
+			config.Cfg.Canaries = append(config.Cfg.Canaries, config.Canary{
+				Vault:     config.Cfg.ActiveVault,
+				Prefix:    prefix,
+				Address:   address,
+				Type:      activeVault.Type,
+				CreatedAt: time.Now().UTC().Format(time.RFC3339),
+			})
+			if err := config.SaveConfig(); err != nil {
+				return errors.NewConfigSaveError("config.json", err)
+			}
+
+			audit.Logger.Info("Canary wallet created",
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("prefix", prefix),
+				slog.String("address", address))
+
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Canary wallet '%s' created in vault '%s'.", prefix, config.Cfg.ActiveVault), colors.Success))
+			fmt.Printf("   Address: %s\n", colors.SafeColor(address, colors.Cyan))
+			fmt.Println(colors.SafeColor("Run 'vault.module canary check' periodically (e.g. from cron) to monitor it.", colors.Info))
+			return nil
+		})
+	},
+}
+
+var canaryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists monitored canary wallets.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if len(config.Cfg.Canaries) == 0 {
+				fmt.Println(colors.SafeColor("No canary wallets configured.", colors.Info))
+				return nil
+			}
+			fmt.Println(colors.SafeColor("Canary wallets:", colors.Bold))
+			for _, c := range config.Cfg.Canaries {
+				fmt.Printf("  - %s/%s %s (created %s)\n", c.Vault, colors.SafeColor(c.Prefix, colors.Cyan), c.Address, c.CreatedAt)
+			}
+			return nil
+		})
+	},
+}
+
+var canaryCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Checks canary addresses for on-chain activity.",
+	Long: `Checks every canary address for on-chain activity.
+
+EVM canaries are queried through canary_evm_rpc (JSON-RPC) and Cosmos canaries
+through canary_cosmos_rest (REST/LCD). Any transaction or balance raises a
+critical audit event, is POSTed to canary_webhook if configured, and makes
+the command fail with CANARY_TRIGGERED.
+
+Examples:
+  vault.module canary check
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if len(config.Cfg.Canaries) == 0 {
+				fmt.Println(colors.SafeColor("No canary wallets configured.", colors.Info))
+				return nil
+			}
+
+			triggered := 0
+			for _, c := range config.Cfg.Canaries {
+				var activity canary.Activity
+				var err error
+				switch c.Type {
+				case constants.VaultTypeEVM:
+					if config.Cfg.CanaryEVMRPC == "" {
+						fmt.Printf("  %s %s/%s: canary_evm_rpc is not configured\n", colors.SafeColor("?", colors.Warning), c.Vault, c.Prefix)
+						continue
+					}
+					activity, err = canary.CheckEVM(config.Cfg.CanaryEVMRPC, c.Address)
+				case constants.VaultTypeCosmos:
+					if config.Cfg.CanaryCosmosREST == "" {
+						fmt.Printf("  %s %s/%s: canary_cosmos_rest is not configured\n", colors.SafeColor("?", colors.Warning), c.Vault, c.Prefix)
+						continue
+					}
+					activity, err = canary.CheckCosmos(config.Cfg.CanaryCosmosREST, c.Address)
+				default:
+					continue
+				}
+				if err != nil {
+					audit.Logger.Warn("Canary check failed",
+						slog.String("vault", c.Vault),
+						slog.String("prefix", c.Prefix),
+						slog.String("error", err.Error()))
+					fmt.Printf("  %s %s/%s: %v\n", colors.SafeColor("?", colors.Warning), c.Vault, c.Prefix, err)
+					continue
+				}
+
+				if !activity.Triggered {
+					fmt.Printf("  %s %s/%s: no activity\n", colors.SafeColor("✓", colors.Success), c.Vault, c.Prefix)
+					continue
+				}
+
+				triggered++
+				reportCanaryActivity(c, activity)
+			}
+
+			if triggered > 0 {
+				return errors.Newf(errors.ErrCodeCanaryTriggered, "%d canary wallet(s) show on-chain activity", triggered).
+					WithDetails("the affected vaults must be considered compromised; move funds and rotate keys").
+					WithSeverity(errors.SeverityCritical)
+			}
+			return nil
+		})
+	},
+}
+
+// reportCanaryActivity records a triggered canary in the audit log and notifies the webhook
+func reportCanaryActivity(c config.Canary, activity canary.Activity) {
+	audit.Logger.Error("CRITICAL: canary wallet activity detected, vault may be compromised",
+		slog.String("severity", "CRITICAL"),
+		slog.String("vault", c.Vault),
+		slog.String("prefix", c.Prefix),
+		slog.String("address", c.Address),
+		slog.Uint64("nonce", activity.Nonce),
+		slog.String("balance", activity.Balance))
+
+	fmt.Printf("  %s %s/%s: ACTIVITY DETECTED (nonce %d, balance %s)\n",
+		colors.SafeColor("✗", colors.Error), c.Vault, c.Prefix, activity.Nonce, activity.Balance)
+
+	if config.Cfg.CanaryWebhook == "" {
+		return
+	}
+	err := canary.SendAlert(config.Cfg.CanaryWebhook, canary.Alert{
+		Vault:     c.Vault,
+		Prefix:    c.Prefix,
+		Address:   c.Address,
+		Nonce:     activity.Nonce,
+		Balance:   activity.Balance,
+		Message:   "canary wallet activity detected, vault may be compromised",
+		Timestamp: time.Now().UTC(),
+	})
+	if err != nil {
+		audit.Logger.Error("Failed to deliver canary alert", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,500 @@
+// File: cmd/ceremony.go
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"vault.module/internal/actions"
+	"vault.module/internal/addressbook"
+	"vault.module/internal/audit"
+	"vault.module/internal/ceremony"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/errors"
+	"vault.module/internal/keys"
+	"vault.module/internal/security"
+	"vault.module/internal/vault"
+	"vault.module/internal/webhook"
+
+	"github.com/spf13/cobra"
+)
+
+// ceremonyCheckedWords is how many words of each backup are typed back
+const ceremonyCheckedWords = 3
+
+// ceremonyMaxBackups bounds the backup copies of a ceremony
+const ceremonyMaxBackups = 10
+
+var ceremonyOperators []string
+var ceremonyWords int
+var ceremonyBackups int
+var ceremonyTranscript string
+var ceremonyNotes string
+
+var ceremonyCmd = &cobra.Command{
+	Use:   "ceremony",
+	Short: "Runs key ceremonies with several operators and verifies their transcripts.",
+	Long: `Runs key ceremonies with several operators and verifies their transcripts.
+
+A key ceremony creates an HD wallet in front of the operators of organization
+mode ('operators') and records every step in a transcript for institutional
+records. 'ceremony run' guides the operators through:
+
+  1. entropy       each operator rolls a six-sided die and types the results,
+                   unseen by the others; every contribution is mixed with the
+                   system's generator, so one honest operator suffices
+  2. backup        the mnemonic is shown once, on the alternate screen, and
+                   written down on each backup copy; each copy is then checked
+                   by typing back words at random positions
+  3. verification  each operator confirms the first address by typing their
+                   name
+  4. registration  the wallet is stored in the active vault, which is
+                   re-encrypted to the recipients of every operator
+
+The transcript names the operators, the backup custodians and locations, the
+first address and the SHA-256 commitment of each contribution, never the rolls
+or the mnemonic. Each step holds the hash of the one before it, and the whole
+transcript is signed with the vault's attestation key ('vaults attestation')
+in a .sig file next to it. 'ceremony verify' checks both.
+
+Examples:
+  vault.module ceremony run treasury --operators alice,bob,carol --backups 3
+  vault.module ceremony verify ceremony-treasury-1f3a9c0b.json
+`,
+}
+
+var ceremonyRunCmd = &cobra.Command{
+	Use:   "run <PREFIX>",
+	Short: "Creates an HD wallet in a key ceremony and writes its signed transcript.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("ceremony run")
+			}
+			prefix := args[0]
+			if err := validatePrefix(prefix); err != nil {
+				return err
+			}
+			if ceremonyWords != 12 && ceremonyWords != 24 {
+				return errors.NewInvalidInputError(strconv.Itoa(ceremonyWords), "--words must be 12 or 24")
+			}
+			if ceremonyBackups < 1 || ceremonyBackups > ceremonyMaxBackups {
+				return errors.NewInvalidInputError(strconv.Itoa(ceremonyBackups), fmt.Sprintf("--backups must be between 1 and %d", ceremonyMaxBackups))
+			}
+			operators, err := ceremonyOperatorList(ceremonyOperators)
+			if err != nil {
+				return err
+			}
+			if err := refuseSecretEcho("ceremony run"); err != nil {
+				return err
+			}
+			if err := confirmNoScreenCapture("ceremony run", false); err != nil {
+				return err
+			}
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+
+			names := make([]string, len(operators))
+			for i, op := range operators {
+				names[i] = op.Name
+			}
+			transcript, err := ceremony.New(constants.Version, config.Cfg.ActiveVault, prefix, names)
+			if err != nil {
+				return errors.Wrap(errors.ErrCodeSystem, "failed to generate ceremony ID", err)
+			}
+			out := ceremonyTranscript
+			if out == "" {
+				out = fmt.Sprintf("ceremony-%s-%s.json", prefix, transcript.CeremonyID[:8])
+			}
+			if _, err := os.Stat(out); err == nil {
+				return errors.NewInvalidInputError(out, "file already exists")
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+			if _, exists := v[prefix]; exists {
+				return errors.NewWalletExistsError(prefix)
+			}
+
+			facilitator := ""
+			if op, ok := config.CurrentOperator(); ok {
+				facilitator = op.Name
+			}
+			transcript.Record(ceremony.StepOpened, facilitator, map[string]string{
+				"words":   strconv.Itoa(ceremonyWords),
+				"backups": strconv.Itoa(ceremonyBackups),
+			})
+			audit.Logger.Warn("Key ceremony opened",
+				slog.String("command", "ceremony run"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("prefix", prefix),
+				slog.String("ceremony_id", transcript.CeremonyID),
+				slog.String("operators", strings.Join(names, ",")),
+			)
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Key ceremony %s for wallet '%s' in vault '%s'", transcript.CeremonyID, prefix, config.Cfg.ActiveVault), colors.Bold))
+			fmt.Printf("  Operators: %s\n", strings.Join(names, ", "))
+			fmt.Printf("  Backups:   %d\n", ceremonyBackups)
+
+			mnemonic, address, ok, err := ceremonyGenerate(transcript, operators)
+			if err != nil || !ok {
+				return err
+			}
+			defer mnemonic.Clear()
+			if ok, err := ceremonyBackup(transcript, prefix, mnemonic); err != nil || !ok {
+				return err
+			}
+			for _, op := range operators {
+				if ok, err := ceremonyVerify(transcript, op.Name, address); err != nil || !ok {
+					return err
+				}
+			}
+
+			// Registration: the wallet is stored and the vault encrypted to every operator
+			wallet, _, err := actions.CreateWalletFromMnemonic(mnemonic.String(), activeVault.Type)
+			if err != nil {
+				return errors.NewWalletInvalidError(prefix, err.Error())
+			}
+			wallet.Notes = ceremonyNotes
+			if wallet.Notes == "" {
+				wallet.Notes = "Key ceremony " + transcript.CeremonyID
+			}
+			v[prefix] = wallet
+			updated := activeVault
+			updated.Operators = append([]string(nil), activeVault.Operators...)
+			for _, op := range operators {
+				if !updated.HasOperator(op.Name) {
+					updated.Operators = append(updated.Operators, op.Name)
+				}
+			}
+			if err := saveVaultOperators(updated, v); err != nil {
+				return err
+			}
+			notifyVaultMutation(webhook.EventWalletAdded, prefix, "HD wallet from key ceremony "+transcript.CeremonyID)
+			transcript.Record(ceremony.StepStored, facilitator, map[string]string{
+				"vault":         config.Cfg.ActiveVault,
+				"prefix":        prefix,
+				"first_address": address,
+			})
+			for _, op := range operators {
+				access := "granted"
+				if activeVault.HasOperator(op.Name) {
+					access = "already granted"
+				}
+				transcript.Record(ceremony.StepRecipient, op.Name, map[string]string{
+					"recipient": op.Recipient,
+					"access":    access,
+				})
+			}
+			transcript.Record(ceremony.StepClosed, facilitator, nil)
+
+			data, err := json.MarshalIndent(transcript, "", "  ")
+			if err != nil {
+				return errors.New(errors.ErrCodeInternal, "failed to generate JSON").WithContext("marshal_error", err.Error())
+			}
+			data = append(data, '\n')
+			if err := os.WriteFile(out, data, 0600); err != nil {
+				return errors.FromOSError(err, out)
+			}
+			if err := signExport(config.Cfg.ActiveVault, config.Cfg.Vaults[config.Cfg.ActiveVault], v, data, out); err != nil {
+				return err
+			}
+
+			digest := sha256.Sum256(data)
+			audit.Logger.Warn("Key ceremony completed",
+				slog.String("command", "ceremony run"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("prefix", prefix),
+				slog.String("ceremony_id", transcript.CeremonyID),
+				slog.String("operators", strings.Join(names, ",")),
+				slog.String("address", address),
+				slog.String("transcript", out),
+				slog.String("transcript_sha256", hex.EncodeToString(digest[:])),
+			)
+			fmt.Println(colors.SafeColor(fmt.Sprintf("HD wallet '%s' stored in vault '%s'. First address: %s", prefix, config.Cfg.ActiveVault, address), colors.Success))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Transcript written to '%s'. Check it anywhere with 'vault.module ceremony verify %s'.", out, out), colors.Success))
+			return nil
+		})
+	},
+}
+
+var ceremonyVerifyCmd = &cobra.Command{
+	Use:   "verify <TRANSCRIPT>",
+	Short: "Verifies the hash chain and signature of a ceremony transcript.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			file := args[0]
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return errors.FromOSError(err, file)
+			}
+			var transcript ceremony.Transcript
+			if err := json.Unmarshal(data, &transcript); err != nil {
+				return errors.NewFormatInvalidError(file, "not a ceremony transcript: "+err.Error())
+			}
+			if err := transcript.Verify(); err != nil {
+				return errors.New(errors.ErrCodeAuthFailed, "ceremony transcript is invalid").WithDetails(err.Error())
+			}
+
+			attestationFile := file + vault.AttestationSuffix
+			attestationData, err := os.ReadFile(attestationFile)
+			if err != nil {
+				return errors.FromOSError(err, attestationFile)
+			}
+			var attestation vault.Attestation
+			if err := json.Unmarshal(attestationData, &attestation); err != nil {
+				return errors.NewFormatInvalidError(attestationFile, "not an attestation: "+err.Error())
+			}
+			if err := vault.VerifyAttestation(attestation, data); err != nil {
+				return errors.New(errors.ErrCodeAuthFailed, "ceremony transcript signature is invalid").WithDetails(err.Error())
+			}
+
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Key ceremony %s: wallet '%s' in vault '%s'", transcript.CeremonyID, transcript.Prefix, transcript.Vault), colors.Bold))
+			for _, step := range transcript.Steps {
+				line := fmt.Sprintf("  %2d. %s  %-12s  %-10s", step.Number, step.At.Format(time.RFC3339), step.Name, step.Operator)
+				keys := make([]string, 0, len(step.Details))
+				for k := range step.Details {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					line += fmt.Sprintf("  %s=%s", k, addressbook.Clean(step.Details[k]))
+				}
+				fmt.Println(strings.TrimRight(line, " "))
+			}
+			if trusted, found := config.FindTrustedExporter(attestation.PublicKey); found {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Valid transcript, signed by vault '%s' (%s).", trusted.Name, attestation.VaultID), colors.Success))
+				return nil
+			}
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Valid transcript, signed by vault %s, which this installation does not trust.", attestation.VaultID), colors.Warning))
+			fmt.Println(colors.SafeColor("Compare its signing key with 'vaults attestation' on the installation that ran the ceremony.", colors.Warning))
+			return nil
+		})
+	},
+}
+
+// ceremonyOperatorList resolves the configured operators of a ceremony. A
+// ceremony needs at least two distinct operators.
+func ceremonyOperatorList(names []string) ([]config.Operator, error) {
+	var operators []config.Operator
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if seen[name] {
+			return nil, errors.NewInvalidInputError(name, "operator listed twice in --operators")
+		}
+		seen[name] = true
+		op, ok := config.FindOperator(name)
+		if !ok {
+			return nil, errors.NewInvalidInputError(name, "no such operator; add it with 'operators add'")
+		}
+		operators = append(operators, op)
+	}
+	if len(operators) < 2 {
+		return nil, errors.NewInvalidInputError("--operators", "a key ceremony needs at least two operators")
+	}
+	return operators, nil
+}
+
+// ceremonyGenerate collects the dice rolls of every operator and generates the
+// mnemonic from them and the system's generator. It reports false when the
+// operators cancel.
+func ceremonyGenerate(transcript *ceremony.Transcript, operators []config.Operator) (*security.SecureString, string, bool, error) {
+	bits := ceremonyWords / 3 * 32
+	needed := security.DiceRollsFor(bits)
+	var contributions [][]byte
+	defer func() {
+		for _, rolls := range contributions {
+			security.SecureZero(rolls)
+		}
+	}()
+
+	for _, op := range operators {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Entropy from operator '%s'", op.Name), colors.Bold))
+		fmt.Println(colors.SafeColor(fmt.Sprintf("'%s' alone rolls a six-sided die %d times and types the results (1-6). The input is hidden.", op.Name, needed), colors.Info))
+		rolls, err := askForRolls("Rolls", needed, parseDieRoll)
+		if err != nil {
+			return nil, "", false, err
+		}
+		contributions = append(contributions, rolls)
+		warnings := physicalEntropyBias("dice", rolls)
+		for _, w := range warnings {
+			fmt.Println(colors.SafeColor("WARNING: "+w, colors.Warning))
+		}
+		if len(warnings) > 0 && !askForConfirmation(colors.SafeColor("These rolls do not look random. Continue anyway?", colors.Warning)) {
+			fmt.Println(colors.SafeColor("Ceremony cancelled; nothing was stored.", colors.Info))
+			return nil, "", false, nil
+		}
+		security.AddEntropyProvider(security.DiceProvider{Rolls: rolls})
+		details := map[string]string{
+			"source":     "dice",
+			"rolls":      strconv.Itoa(len(rolls)),
+			"commitment": transcript.Commitment(rolls),
+		}
+		if len(warnings) > 0 {
+			details["warnings"] = strings.Join(warnings, "; ")
+		}
+		transcript.Record(ceremony.StepEntropy, op.Name, details)
+	}
+
+	phrase, err := actions.GenerateMnemonic(ceremonyWords)
+	if err != nil {
+		return nil, "", false, err
+	}
+	mnemonic := security.NewSecureString(phrase)
+	if !confirmWeakSecret("ceremony run", transcript.Prefix, keys.AnalyzeMnemonic(mnemonic.String())) {
+		mnemonic.Clear()
+		fmt.Println(colors.SafeColor("Ceremony cancelled; nothing was stored.", colors.Info))
+		return nil, "", false, nil
+	}
+	activeVault, err := config.GetActiveVault()
+	if err != nil {
+		mnemonic.Clear()
+		return nil, "", false, err
+	}
+	wallet, address, err := actions.CreateWalletFromMnemonic(mnemonic.String(), activeVault.Type)
+	if err != nil {
+		mnemonic.Clear()
+		return nil, "", false, errors.NewWalletInvalidError(transcript.Prefix, err.Error())
+	}
+	wallet.Clear()
+
+	sources := append([]string{"system"}, config.Cfg.EntropySources...)
+	transcript.Record(ceremony.StepGenerated, "", map[string]string{
+		"words":         strconv.Itoa(ceremonyWords),
+		"sources":       strings.Join(append(sources, fmt.Sprintf("dice of %d operators", len(operators))), ", "),
+		"first_address": address,
+	})
+	return mnemonic, address, true, nil
+}
+
+// ceremonyBackup shows the mnemonic for the backups to be written, then checks
+// every backup copy by asking for words at random positions. It reports false
+// when the operators cancel.
+func ceremonyBackup(transcript *ceremony.Transcript, prefix string, mnemonic *security.SecureString) (bool, error) {
+	fmt.Println(colors.SafeColor("Backup", colors.Bold))
+	fmt.Println(colors.SafeColor("The mnemonic is shown next. Write it on every backup copy, then close the screen.", colors.Info))
+	if !askForConfirmation("Show the mnemonic?") {
+		fmt.Println(colors.SafeColor("Ceremony cancelled; nothing was stored.", colors.Info))
+		return false, nil
+	}
+	if err := revealOnScreen("ceremony run", prefix, "Mnemonic", mnemonic.String()); err != nil {
+		return false, err
+	}
+	audit.Logger.Warn("Key ceremony mnemonic shown",
+		slog.String("command", "ceremony run"),
+		slog.String("prefix", prefix),
+		slog.String("ceremony_id", transcript.CeremonyID),
+	)
+
+	words := strings.Fields(mnemonic.String())
+	for copy := 1; copy <= ceremonyBackups; copy++ {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Backup %d of %d", copy, ceremonyBackups), colors.Bold))
+		custodian, err := askForInput("Custodian")
+		if err != nil {
+			return false, err
+		}
+		location, err := askForInput("Location")
+		if err != nil {
+			return false, err
+		}
+		positions, err := ceremonyWordPositions(len(words))
+		if err != nil {
+			return false, errors.Wrap(errors.ErrCodeSystem, "failed to choose words to check", err)
+		}
+		checked := make([]string, len(positions))
+		for i, position := range positions {
+			typed, err := askForSecretInputWithCleanup(fmt.Sprintf("Word #%d on backup %d", position+1, copy))
+			if err != nil {
+				return false, err
+			}
+			if strings.ToLower(strings.TrimSpace(typed)) != words[position] {
+				audit.Logger.Warn("Key ceremony backup check failed",
+					slog.String("command", "ceremony run"),
+					slog.String("ceremony_id", transcript.CeremonyID),
+					slog.Int("backup", copy),
+				)
+				return false, errors.NewInvalidInputError(fmt.Sprintf("backup %d", copy), fmt.Sprintf("word #%d does not match; the ceremony is stopped and nothing was stored, destroy the backups written so far", position+1))
+			}
+			checked[i] = strconv.Itoa(position + 1)
+		}
+		transcript.Record(ceremony.StepBackup, "", map[string]string{
+			"copy":          strconv.Itoa(copy),
+			"custodian":     addressbook.Clean(custodian),
+			"location":      addressbook.Clean(location),
+			"checked_words": strings.Join(checked, ", "),
+		})
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Backup %d checked.", copy), colors.Success))
+	}
+	return true, nil
+}
+
+// ceremonyWordPositions picks distinct word positions to check, in order
+func ceremonyWordPositions(words int) ([]int, error) {
+	picked := make(map[int]bool)
+	for len(picked) < ceremonyCheckedWords {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(words)))
+		if err != nil {
+			return nil, err
+		}
+		picked[int(n.Int64())] = true
+	}
+	positions := make([]int, 0, len(picked))
+	for position := range picked {
+		positions = append(positions, position)
+	}
+	sort.Ints(positions)
+	return positions, nil
+}
+
+// ceremonyVerify asks an operator to confirm the wallet by typing their name.
+// It reports false when the operator declines.
+func ceremonyVerify(transcript *ceremony.Transcript, operator, address string) (bool, error) {
+	fmt.Println(colors.SafeColor(fmt.Sprintf("Verification by operator '%s'", operator), colors.Bold))
+	fmt.Printf("  First address: %s\n", address)
+	answer, err := askForInput(fmt.Sprintf("'%s', type your name to confirm the address and the backups", operator))
+	if err != nil {
+		return false, err
+	}
+	if answer != operator {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Operator '%s' did not confirm. Ceremony cancelled; nothing was stored, destroy the backups.", operator), colors.Warning))
+		return false, nil
+	}
+	transcript.Record(ceremony.StepVerification, operator, map[string]string{
+		"first_address": address,
+	})
+	return true, nil
+}
+
+func init() {
+	ceremonyRunCmd.Flags().StringSliceVar(&ceremonyOperators, "operators", nil, "Operators taking part, comma-separated (at least two)")
+	ceremonyRunCmd.Flags().IntVar(&ceremonyWords, "words", 24, "Number of words: 12 or 24")
+	ceremonyRunCmd.Flags().IntVar(&ceremonyBackups, "backups", 2, "Number of backup copies of the mnemonic")
+	ceremonyRunCmd.Flags().StringVar(&ceremonyTranscript, "transcript", "", "Transcript file to write (default: ceremony-<PREFIX>-<ID>.json)")
+	ceremonyRunCmd.Flags().StringVar(&ceremonyNotes, "notes", "", "Notes for the stored wallet (default: the ceremony ID)")
+}
@@ -0,0 +1,178 @@
+// File: cmd/checklist.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/display"
+	"vault.module/internal/errors"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var checklistDone []string
+var checklistUndo []string
+var checklistAdd []string
+var checklistRemove []string
+var checklistReset bool
+
+var checklistCmd = &cobra.Command{
+	Use:   "checklist <PREFIX>",
+	Short: "Tracks the cold-storage checklist of a wallet.",
+	Long: `Tracks the cold-storage checklist of a wallet.
+
+Every wallet can carry a checklist of the steps that make it safe for cold
+storage. The state is saved in the vault with the wallet, and 'list' shows it
+as a completion badge. Without flags the checklist is displayed; the first
+change creates it from "checklist_items" in config.json, or from the default
+steps: backup made, backup verified, test transaction done, passphrase stored.
+
+Items are referred to by their ID, shown in brackets.
+
+Examples:
+  vault.module checklist A1
+  vault.module checklist A1 --done backup-made --done backup-verified
+  vault.module checklist A1 --undo backup-verified
+  vault.module checklist A1 --add "Seed plate engraved"
+  vault.module checklist A1 --remove passphrase-stored
+  vault.module checklist A1 --reset
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			prefix := args[0]
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			wallet, exists := v[prefix]
+			if !exists {
+				return errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
+			}
+
+			changed := checklistReset || len(checklistDone)+len(checklistUndo)+len(checklistAdd)+len(checklistRemove) > 0
+			if wallet.Checklist == nil || checklistReset {
+				wallet.Checklist = vault.NewChecklist(checklistTitles())
+			}
+			if changed {
+				if err := applyChecklistChanges(&wallet); err != nil {
+					return err
+				}
+				v[prefix] = wallet
+				if err := vault.SaveVault(activeVault, v); err != nil {
+					return errors.NewVaultSaveError(activeVault.KeyFile, err)
+				}
+				done, total := wallet.ChecklistProgress()
+				audit.Logger.Info("Wallet checklist updated",
+					slog.String("command", "checklist"),
+					slog.String("vault", config.Cfg.ActiveVault),
+					slog.String("prefix", prefix),
+					slog.Int("done", done),
+					slog.Int("total", total),
+				)
+			}
+
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Checklist for '%s':%s", prefix, checklistBadge(wallet)), colors.Bold))
+			for _, item := range wallet.Checklist {
+				if item.Done {
+					doneAt := ""
+					if item.DoneAt != nil {
+						doneAt = " " + colors.SafeColor(display.Time(*item.DoneAt), colors.Dim)
+					}
+					fmt.Printf("  %s %s [%s]%s\n", colors.SafeColor("[x]", colors.Success), item.Title, item.ID, doneAt)
+				} else {
+					fmt.Printf("  %s %s [%s]\n", colors.SafeColor("[ ]", colors.Warning), item.Title, item.ID)
+				}
+			}
+			return nil
+		})
+	},
+}
+
+// checklistTitles returns the steps of a new checklist
+func checklistTitles() []string {
+	if len(config.Cfg.ChecklistItems) > 0 {
+		return config.Cfg.ChecklistItems
+	}
+	return vault.DefaultChecklistItems
+}
+
+// applyChecklistChanges applies --add, --remove, --done and --undo to the wallet's checklist
+func applyChecklistChanges(wallet *vault.Wallet) error {
+	find := func(id string) int {
+		for i, item := range wallet.Checklist {
+			if item.ID == id {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for _, title := range checklistAdd {
+		title = strings.TrimSpace(title)
+		id := vault.ChecklistID(title)
+		if id == "" {
+			return errors.NewInvalidInputError(title, "checklist item title must contain letters or digits")
+		}
+		if find(id) >= 0 {
+			return errors.NewInvalidInputError(title, fmt.Sprintf("checklist already has item '%s'", id))
+		}
+		wallet.Checklist = append(wallet.Checklist, vault.ChecklistItem{ID: id, Title: title})
+	}
+	for _, id := range checklistRemove {
+		i := find(id)
+		if i < 0 {
+			return errors.NewInvalidInputError(id, "no such checklist item")
+		}
+		wallet.Checklist = append(wallet.Checklist[:i], wallet.Checklist[i+1:]...)
+	}
+	now := time.Now().UTC()
+	for _, id := range checklistDone {
+		i := find(id)
+		if i < 0 {
+			return errors.NewInvalidInputError(id, "no such checklist item")
+		}
+		if !wallet.Checklist[i].Done {
+			wallet.Checklist[i].Done = true
+			wallet.Checklist[i].DoneAt = &now
+		}
+	}
+	for _, id := range checklistUndo {
+		i := find(id)
+		if i < 0 {
+			return errors.NewInvalidInputError(id, "no such checklist item")
+		}
+		wallet.Checklist[i].Done = false
+		wallet.Checklist[i].DoneAt = nil
+	}
+	return nil
+}
+
+func init() {
+	checklistCmd.Flags().StringArrayVar(&checklistDone, "done", nil, "Mark an item as done (repeatable).")
+	checklistCmd.Flags().StringArrayVar(&checklistUndo, "undo", nil, "Mark an item as not done (repeatable).")
+	checklistCmd.Flags().StringArrayVar(&checklistAdd, "add", nil, "Add an item with this title (repeatable).")
+	checklistCmd.Flags().StringArrayVar(&checklistRemove, "remove", nil, "Remove an item (repeatable).")
+	checklistCmd.Flags().BoolVar(&checklistReset, "reset", false, "Start over from the configured checklist.")
+}
@@ -4,11 +4,14 @@ package cmd
 import (
 	"bufio"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"vault.module/internal/actions"
+	"vault.module/internal/audit"
 	"vault.module/internal/colors"
 	"vault.module/internal/config"
 	"vault.module/internal/errors"
@@ -18,22 +21,38 @@ import (
 )
 
 var cloneYesFlag bool
+var clonePrefixesFlag []string
+var cloneTo string
+var cloneRecipients string
 
 var cloneCmd = &cobra.Command{
-	Use:   "clone <VAULT_NAME> <PREFIXES...>",
+	Use:   "clone [VAULT_NAME] [PREFIXES...]",
 	Short: "Creates a new, isolated vault from the active vault.",
 	Long: `Creates a new, isolated vault from the active vault.
 
 This command creates a new vault containing only the specified wallets.
-The new vault will be encrypted with the same method as the source vault.
+The new vault will be encrypted with the same method and second factor as the
+source vault. Frozen and archived wallets cannot be cloned.
 The vault file will be saved in the same directory as the source vault.
 The new vault will be automatically added to config.json.
 
+The wallets can also be given as --prefixes A1,B2, with VAULT_NAME optional.
+--to writes the vault file to another path and --recipients encrypts it to
+another age recipients file, e.g. another operator's YubiKey. Without
+VAULT_NAME the file is only written, not added to config.json: it is meant to
+be handed to whoever holds the recipients' keys.
+
 Examples:
   vault.module clone newvault A1 A2
   vault.module clone backup wallet1 wallet2
+  vault.module clone --prefixes A1,B2 --to alice.key --recipients alice.txt
 `,
-	Args: cobra.MinimumNArgs(2),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(clonePrefixesFlag) > 0 {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.MinimumNArgs(2)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return errors.WrapCommand(func() error {
 			activeVault, err := config.GetActiveVault()
@@ -45,12 +64,20 @@ Examples:
 				return errors.NewProgrammaticModeError("clone")
 			}
 			
-			clonedVaultName := args[0]
-			clonePrefixes := args[1:]
+			var clonedVaultName string
+			var clonePrefixes []string
+			if len(args) > 0 {
+				clonedVaultName = args[0]
+				clonePrefixes = args[1:]
+			}
+			clonePrefixes = append(clonePrefixes, clonePrefixesFlag...)
 
 			if len(clonePrefixes) == 0 {
 				return errors.NewInvalidInputError("", "at least one prefix must be specified")
 			}
+			if clonedVaultName == "" && cloneTo == "" {
+				return errors.NewInvalidInputError("", "give a VAULT_NAME or a file with --to")
+			}
 
 			// Check if vault name already exists
 			if _, exists := config.Cfg.Vaults[clonedVaultName]; exists {
@@ -58,8 +85,22 @@ Examples:
 			}
 
 			// Generate output file path in the same directory as source vault
-			sourceDir := filepath.Dir(activeVault.KeyFile)
-			outputFile := filepath.Join(sourceDir, clonedVaultName)
+			outputFile := cloneTo
+			if outputFile == "" {
+				sourceDir := filepath.Dir(activeVault.KeyFile)
+				outputFile = filepath.Join(sourceDir, clonedVaultName)
+			}
+			if sameFile(outputFile, activeVault.KeyFile) {
+				return errors.NewInvalidInputError(outputFile, "the clone cannot replace the active vault's file")
+			}
+
+			recipientsFile := activeVault.RecipientsFile
+			if cloneRecipients != "" {
+				if _, err := os.Stat(cloneRecipients); err != nil {
+					return errors.NewFileSystemError("access", cloneRecipients, err).WithDetails("recipients file not found")
+				}
+				recipientsFile = cloneRecipients
+			}
 
 			if _, err := os.Stat(outputFile); err == nil && !cloneYesFlag {
 				fmt.Printf("File '%s' already exists. Overwrite? [y/N]: ", outputFile)
@@ -89,24 +130,50 @@ Examples:
 				}
 			}()
 
+			for _, prefix := range clonePrefixes {
+				if wallet, exists := v[prefix]; exists {
+					if err := checkWalletNotFrozen("clone", prefix, wallet); err != nil {
+						return err
+					}
+				}
+			}
+
 			clonedVault, err := actions.CloneVault(v, clonePrefixes)
 			if err != nil {
 				return err
 			}
 
-			// Create a temporary VaultDetails for the new file, inheriting the active vault's properties.
-			clonedVaultDetails := config.VaultDetails{
-				KeyFile:        outputFile,
-				RecipientsFile: activeVault.RecipientsFile,
-				Encryption:     activeVault.Encryption,
-				Type:           activeVault.Type,
-			}
+			// The new file inherits the active vault's encryption, second factor and backend settings.
+			clonedVaultDetails := derivedVaultDetails(activeVault, outputFile, recipientsFile)
+			vault.ShareSecondFactorPassphrase(activeVault.KeyFile, clonedVaultDetails.KeyFile)
 
 			// Save the cloned vault to file
 			if err := vault.SaveVault(clonedVaultDetails, clonedVault); err != nil {
 				return errors.NewVaultSaveError(outputFile, err)
 			}
 
+			prefixes := make([]string, 0, len(clonedVault))
+			for prefix := range clonedVault {
+				prefixes = append(prefixes, prefix)
+			}
+			sort.Strings(prefixes)
+			audit.Logger.Warn("Wallets cloned to a new vault",
+				slog.String("command", "clone"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("prefixes", strings.Join(prefixes, ",")),
+				slog.String("destination_file", filepath.Base(outputFile)),
+				slog.String("recipients_file", filepath.Base(recipientsFile)),
+				slog.Bool("registered", clonedVaultName != ""))
+
+			if clonedVaultName == "" {
+				fmt.Println(colors.SafeColor(
+					fmt.Sprintf("%d wallets from '%s' written to '%s', encrypted to the recipients in '%s'.", len(clonedVault), config.Cfg.ActiveVault, outputFile, recipientsFile),
+					colors.Success,
+				))
+				fmt.Printf("   Wallets: %s\n", strings.Join(prefixes, ", "))
+				return nil
+			}
+
 			// Add the cloned vault to config.json
 			if config.Cfg.Vaults == nil {
 				config.Cfg.Vaults = make(map[string]config.VaultDetails)
@@ -130,6 +197,33 @@ Examples:
 	},
 }
 
+// derivedVaultDetails returns the details of a new vault file made from the
+// source vault: the same encryption, second factor, backend and naming settings,
+// without what belongs to the source file alone (its integrity signature,
+// inheritance package and public twin).
+func derivedVaultDetails(source config.VaultDetails, keyFile, recipientsFile string) config.VaultDetails {
+	details := source
+	details.KeyFile = keyFile
+	details.RecipientsFile = recipientsFile
+	details.IntegritySignature = ""
+	details.Inheritance = nil
+	details.PublicTwin = ""
+	return details
+}
+
+// sameFile reports whether two paths name the same file, whether or not it exists
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
+
 func init() {
 	cloneCmd.Flags().BoolVar(&cloneYesFlag, "yes", false, "Overwrite without confirmation prompt")
+	cloneCmd.Flags().StringSliceVar(&clonePrefixesFlag, "prefixes", nil, "Wallets to clone, comma-separated")
+	cloneCmd.Flags().StringVar(&cloneTo, "to", "", "File to write the new vault to (default: VAULT_NAME next to the active vault)")
+	cloneCmd.Flags().StringVar(&cloneRecipients, "recipients", "", "age recipients file to encrypt the new vault to (default: the active vault's)")
 }
@@ -0,0 +1,293 @@
+// File: cmd/consensus.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/errors"
+	"vault.module/internal/keys"
+	"vault.module/internal/ratelimit"
+	"vault.module/internal/security"
+	"vault.module/internal/vault"
+	"vault.module/internal/webhook"
+
+	"github.com/spf13/cobra"
+)
+
+// maxPrivValidatorKeySize bounds a priv_validator_key.json; real ones are
+// about 350 bytes
+const maxPrivValidatorKeySize = 64 * 1024
+
+var consensusNotes string
+var consensusTo string
+
+var consensusCmd = &cobra.Command{
+	Use:   "consensus",
+	Short: "Manages validator consensus keys (priv_validator_key.json).",
+	Long: `Manages validator consensus keys (priv_validator_key.json).
+
+A consensus wallet holds the Tendermint/CometBFT ed25519 key a validator
+signs blocks with. Its address is the validator address, as in the key file;
+the vault keeps the key's seed as the address's private key.
+
+A consensus key must be used by exactly one signer at a time: two nodes
+signing with the same key double-sign, and the validator is slashed and
+tombstoned for good. Retrieving a consensus key, with 'consensus export' or
+'get <NAME> privatekey', therefore:
+
+  - shows a double-signing warning and asks for the wallet's name to be typed
+  - is refused in programmatic mode
+  - is limited to consensus_key_rate_limit retrievals per hour and wallet
+    (default 2), even when no other retrieval limits are configured
+  - is refused for frozen wallets and recorded in the access history
+
+Consensus wallets are for cosmos vaults. They cannot sign transactions or
+ownership proofs.
+
+Examples:
+  vault.module consensus import val_1 ~/.gaia/config/priv_validator_key.json
+  vault.module consensus export val_1 --to /mnt/new-node/priv_validator_key.json
+`,
+}
+
+var consensusImportCmd = &cobra.Command{
+	Use:   "import <NAME> <KEY_FILE>",
+	Short: "Imports a priv_validator_key.json into the active vault.",
+	Long: `Imports a priv_validator_key.json into the active vault.
+
+The key file is checked: its address and public key must match its private
+key. The file is left in place; once the key is safe in the vault, remove it
+from machines that should no longer sign with it.
+`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("consensus import")
+			}
+			name, keyFile := args[0], args[1]
+			if err := validatePrefix(name); err != nil {
+				return err
+			}
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			if activeVault.Type != constants.VaultTypeCosmos {
+				return errors.NewInvalidInputError(activeVault.Type, "consensus keys can only be stored in cosmos vaults")
+			}
+
+			info, err := os.Stat(keyFile)
+			if err != nil {
+				return errors.NewFileSystemError("access", keyFile, err)
+			}
+			if info.Size() > maxPrivValidatorKeySize {
+				return errors.NewInvalidInputError(keyFile, "file too large for a priv_validator_key.json")
+			}
+			content, err := os.ReadFile(keyFile)
+			if err != nil {
+				return errors.NewFileSystemError("read", keyFile, err)
+			}
+			wallet, err := keys.ParsePrivValidatorKey(content)
+			security.SecureZero(content)
+			if err != nil {
+				return errors.NewFormatInvalidError("priv_validator_key.json", err.Error())
+			}
+			wallet.Notes = consensusNotes
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				wallet.Clear()
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, w := range v {
+					w.Clear()
+				}
+			}()
+			if _, exists := v[name]; exists {
+				wallet.Clear()
+				return errors.NewWalletExistsError(name)
+			}
+			address := wallet.Addresses[0].Address
+			for prefix, existing := range v {
+				if existing.Kind == vault.KindConsensus && len(existing.Addresses) > 0 && existing.Addresses[0].Address == address {
+					wallet.Clear()
+					return errors.NewInvalidInputError(keyFile, fmt.Sprintf("the consensus key of validator address %s is already stored as '%s'", address, prefix))
+				}
+			}
+			v[name] = wallet
+
+			warnKnownCompromised("consensus import", name, wallet)
+			if !confirmWeakSecret("consensus import", name, keys.AnalyzeWallet(wallet)) {
+				fmt.Println(colors.SafeColor("Cancelled. Nothing was imported.", colors.Info))
+				return nil
+			}
+
+			if err := vault.SaveVault(activeVault, v); err != nil {
+				return errors.NewVaultSaveError(activeVault.KeyFile, err)
+			}
+			notifyVaultMutation(webhook.EventWalletAdded, name, "consensus key")
+			audit.Logger.Info("Consensus key imported",
+				slog.String("command", "consensus import"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("prefix", name),
+				slog.String("address", address),
+				slog.String("source_file", filepath.Base(keyFile)))
+
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Consensus key '%s' (validator address %s) added to vault '%s'.", name, address, config.Cfg.ActiveVault), colors.Success))
+			if pubKey, err := keys.ConsensusPubKey(wallet.Addresses[0].PrivateKey.String()); err == nil {
+				fmt.Printf("Validator public key: {\"@type\":\"/cosmos.crypto.ed25519.PubKey\",\"key\":\"%s\"}\n", pubKey)
+			}
+			fmt.Println(colors.SafeColor(fmt.Sprintf("💡 '%s' was not removed. Keep the key on one signing node only.", keyFile), colors.Info))
+			return nil
+		})
+	},
+}
+
+var consensusExportCmd = &cobra.Command{
+	Use:   "export <NAME> --to KEY_FILE",
+	Short: "Writes a consensus key to a priv_validator_key.json.",
+	Long: `Writes a consensus key to a priv_validator_key.json.
+
+The file is written with mode 0600 and is never overwritten: move an existing
+key file aside first, after making sure its node has stopped signing.
+
+Before starting the node, make sure it will not sign at heights the key has
+already signed: carry over priv_validator_state.json from the previous signer,
+or let the previous signer stop for good first.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("consensus export")
+			}
+			if consensusTo == "" {
+				return errors.NewInvalidInputError("", "--to is required")
+			}
+			if _, err := os.Stat(consensusTo); err == nil {
+				return errors.NewInvalidInputError(consensusTo, "the file exists; a consensus key file is never overwritten")
+			}
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			name := args[0]
+			wallet, exists := v[name]
+			if !exists {
+				return errors.NewWalletNotFoundError(name, config.Cfg.ActiveVault)
+			}
+			if wallet.Kind != vault.KindConsensus {
+				return errors.NewWalletInvalidError(name, "wallet is not a consensus key")
+			}
+			if err := openWalletEnvelope("consensus export", name, &wallet); err != nil {
+				return err
+			}
+			v[name] = wallet
+			addr := wallet.AddressAt(0, 0)
+			if addr == nil || addr.PrivateKey == nil || addr.PrivateKey.IsEmpty() {
+				return errors.NewWalletInvalidError(name, "the consensus key is missing")
+			}
+			if err := checkWalletNotFrozen("consensus export", name, wallet); err != nil {
+				return err
+			}
+			if err := checkWalletSecretRateLimit("consensus export", name, wallet); err != nil {
+				return err
+			}
+
+			data, err := keys.MarshalPrivValidatorKey(addr.PrivateKey.String())
+			if err != nil {
+				return errors.NewWalletInvalidError(name, err.Error())
+			}
+			defer security.SecureZero(data)
+			file, err := os.OpenFile(consensusTo, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+			if err != nil {
+				return errors.NewFileSystemError("create", consensusTo, err)
+			}
+			if _, err := file.Write(data); err != nil {
+				file.Close()
+				os.Remove(consensusTo)
+				return errors.NewFileSystemError("write", consensusTo, err)
+			}
+			if err := file.Close(); err != nil {
+				return errors.NewFileSystemError("close", consensusTo, err)
+			}
+
+			recordWalletAccess(activeVault, v, name, "consensusKey")
+			audit.Logger.Warn("Consensus key exported",
+				slog.String("severity", "HIGH"),
+				slog.String("command", "consensus export"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("prefix", name),
+				slog.String("address", addr.Address),
+				slog.String("destination_file", filepath.Base(consensusTo)))
+
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Consensus key '%s' written to '%s'.", name, consensusTo), colors.Success))
+			fmt.Println(colors.SafeColor("💡 Start the node only after every other signer with this key has stopped.", colors.Info))
+			return nil
+		})
+	},
+}
+
+// checkWalletSecretRateLimit applies the retrieval limits to a secret of
+// wallet. Retrieving a consensus key also takes a typed confirmation after a
+// double-signing warning, is refused in programmatic mode and is limited by
+// consensus_key_rate_limit.
+func checkWalletSecretRateLimit(command, prefix string, wallet vault.Wallet) error {
+	if wallet.Kind != vault.KindConsensus {
+		return checkSecretRateLimit(prefix)
+	}
+	if programmaticMode {
+		audit.Logger.Warn("Consensus key retrieval refused in programmatic mode", slog.String("command", command), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix))
+		return errors.New(errors.ErrCodePermission, "consensus keys are not released in programmatic mode").
+			WithDetails("retrieve it interactively with 'consensus export'")
+	}
+
+	fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("WARNING: '%s' is a validator consensus key.", prefix), colors.Warning))
+	fmt.Fprintln(os.Stderr, colors.SafeColor("Two nodes signing with it double-sign: the validator is slashed and tombstoned for good.", colors.Warning))
+	fmt.Fprintln(os.Stderr, colors.SafeColor("Stop the current signer before another one starts with this key.", colors.Warning))
+	answer, err := askForInput(fmt.Sprintf("Type '%s' to retrieve the key", prefix))
+	if err != nil || answer != prefix {
+		audit.Logger.Warn("Consensus key retrieval not confirmed", slog.String("command", command), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix))
+		return errors.New(errors.ErrCodePermission, "retrieval of the consensus key was not confirmed")
+	}
+
+	global, walletLimit := config.SecretRateLimits()
+	if limit := config.ConsensusKeyRateLimit(); walletLimit <= 0 || walletLimit > limit {
+		walletLimit = limit
+	}
+	return ratelimit.Allow(config.Cfg.ActiveVault, prefix, ratelimit.Limits{
+		Global: global,
+		Wallet: walletLimit,
+	})
+}
+
+func init() {
+	consensusImportCmd.Flags().StringVar(&consensusNotes, "notes", "", "Notes for the consensus wallet")
+	consensusExportCmd.Flags().StringVar(&consensusTo, "to", "", "priv_validator_key.json to write (must not exist)")
+}
@@ -0,0 +1,174 @@
+// File: cmd/convert.go
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"vault.module/internal/actions"
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/convert"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+
+	"github.com/spf13/cobra"
+)
+
+var convertFrom string
+var convertTo string
+var convertReport string
+var convertPrefix string
+var convertYes bool
+
+var convertCmd = &cobra.Command{
+	Use:   "convert <SOURCE> --from FORMAT --to OUTPUT_FILE",
+	Short: "Converts wallets from third-party backups into an import file.",
+	Long: `Converts wallets from third-party backups into an import file.
+
+Reads the keys and seed phrases of another wallet's backup and writes them in
+the JSON import format, ready for 'vault.module import'. No vault is opened or
+changed, so the conversion can be checked before anything is imported.
+
+Source formats (--from):
+  keystore-dir    directory of Ethereum keystore V3 files (geth, Clef,
+                  MyEtherWallet); the files are opened with one password
+  metamask        MetaMask vault: the vault JSON, a state backup with
+                  KeyringController.vault, or the extension's raw storage
+  cosmos-keyring  Cosmos SDK keyring-file or keyring-test directory, or the
+                  node home containing it (e.g. ~/.gaia)
+  cosmos-armor    Cosmos SDK private key exported with 'keys export'
+
+Keystore and MetaMask wallets are for evm vaults, Cosmos keys for cosmos
+vaults. MetaMask seed phrases keep the accounts MetaMask shows; hardware and
+snap accounts, Ledger, multisig and offline Cosmos keys have no private key
+in the backup and are skipped.
+
+Every key found is reported as converted, skipped or failed, with the reason.
+Addresses recorded in the backup are checked against the keys, duplicates are
+dropped and keys are checked for known test vectors and low entropy. --report
+also writes the report as JSON.
+
+Wallets are named after the Cosmos key names or armored key files, or
+--prefix and a number.
+
+The output file holds the secrets in clear: import it and delete it.
+
+Examples:
+  vault.module convert ~/.ethereum/keystore --from keystore-dir --to wallets.json
+  vault.module convert metamask-state.json --from metamask --to mm.json --report mm-report.json
+  vault.module convert ~/.gaia --from cosmos-keyring --to cosmos.json
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("convert")
+			}
+			if convertFrom == "" {
+				return errors.NewInvalidInputError("", fmt.Sprintf("--from is required: %s", strings.Join(convert.Formats, ", ")))
+			}
+			if convertTo == "" {
+				return errors.NewInvalidInputError("", "--to is required")
+			}
+			if convertPrefix != "" {
+				if err := actions.ValidatePrefix(convertPrefix); err != nil {
+					return err
+				}
+			}
+
+			if _, err := os.Stat(convertTo); err == nil && !convertYes {
+				fmt.Printf("File '%s' already exists. Overwrite? [y/N]: ", convertTo)
+				reader := bufio.NewReader(os.Stdin)
+				answer, _ := reader.ReadString('\n')
+				answer = strings.TrimSpace(strings.ToLower(answer))
+				if answer != "y" && answer != "yes" {
+					fmt.Println("Cancelled.")
+					return nil
+				}
+			}
+
+			result, err := convert.Convert(convertFrom, args[0], convertPrefix, askForSecretInput)
+			if err != nil {
+				return err
+			}
+			defer result.Clear()
+
+			printConvertReport(result.Report)
+			if convertReport != "" {
+				data, err := json.MarshalIndent(result.Report, "", "  ")
+				if err != nil {
+					return errors.New(errors.ErrCodeInternal, "failed to generate JSON").WithContext("marshal_error", err.Error())
+				}
+				if err := os.WriteFile(convertReport, append(data, '\n'), 0600); err != nil {
+					return errors.NewFileSystemError("write", convertReport, err)
+				}
+			}
+
+			if len(result.Wallets) == 0 {
+				fmt.Println(colors.SafeColor("No wallets converted, nothing written.", colors.Warning))
+				return nil
+			}
+
+			jsonData, err := actions.ExportVault(result.Wallets)
+			if err != nil {
+				return errors.NewExportFailedError("json", "failed to generate JSON for the import file", err)
+			}
+			defer security.SecureZero(jsonData)
+			if err := os.WriteFile(convertTo, jsonData, 0600); err != nil {
+				return errors.NewFileSystemError("write", convertTo, err)
+			}
+
+			audit.Logger.Warn("Third-party backup converted to a plaintext import file",
+				slog.String("command", "convert"),
+				slog.String("format", convertFrom),
+				slog.Int("converted", result.Report.Converted),
+				slog.Int("skipped", result.Report.Skipped),
+				slog.Int("failed", result.Report.Failed),
+				slog.String("destination_file", filepath.Base(convertTo)))
+
+			fmt.Println(colors.SafeColor(
+				fmt.Sprintf("%d wallets written to '%s' for %s vaults. The file is not encrypted.", len(result.Wallets), convertTo, result.Report.VaultType),
+				colors.Success,
+			))
+			fmt.Println(colors.SafeColor(
+				fmt.Sprintf("💡 Import it with 'vault.module import %s', then delete it", convertTo),
+				colors.Info,
+			))
+			return nil
+		})
+	},
+}
+
+// printConvertReport prints one line per key found, with warnings below it
+func printConvertReport(report convert.Report) {
+	fmt.Println(colors.SafeColor(fmt.Sprintf("Converted %d, skipped %d, failed %d (%s, %s)", report.Converted, report.Skipped, report.Failed, report.Format, report.Source), colors.Bold))
+	for _, entry := range report.Entries {
+		var line string
+		switch entry.Status {
+		case convert.StatusConverted:
+			line = colors.SafeColor(fmt.Sprintf("  ✓ %s -> %s (%s)", entry.Source, entry.Prefix, entry.Address), colors.Success)
+		case convert.StatusSkipped:
+			line = colors.SafeColor(fmt.Sprintf("  - %s: %s", entry.Source, entry.Detail), colors.Dim)
+		default:
+			line = colors.SafeColor(fmt.Sprintf("  ✗ %s: %s", entry.Source, entry.Detail), colors.Error)
+		}
+		fmt.Println(line)
+		for _, warning := range entry.Warnings {
+			fmt.Println(colors.SafeColor("      WARNING: "+warning, colors.Warning))
+		}
+	}
+}
+
+func init() {
+	convertCmd.Flags().StringVar(&convertFrom, "from", "", "Source format: keystore-dir, metamask, cosmos-keyring or cosmos-armor")
+	convertCmd.Flags().StringVar(&convertTo, "to", "", "Import file to write (JSON import format)")
+	convertCmd.Flags().StringVar(&convertReport, "report", "", "Also write the validation report to this file as JSON")
+	convertCmd.Flags().StringVar(&convertPrefix, "prefix", "wallet", "Prefix base for wallets without a usable name")
+	convertCmd.Flags().BoolVar(&convertYes, "yes", false, "Overwrite the output file without confirmation prompt")
+}
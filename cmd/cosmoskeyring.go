@@ -0,0 +1,374 @@
+// File: cmd/cosmoskeyring.go
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"vault.module/internal/actions"
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/convert"
+	"vault.module/internal/cosmoskeyring"
+	"vault.module/internal/errors"
+	"vault.module/internal/keys"
+	"vault.module/internal/security"
+	"vault.module/internal/vault"
+	"vault.module/internal/webhook"
+
+	"github.com/spf13/cobra"
+)
+
+var cosmosKeyringConflict string
+var cosmosKeyringPrefix string
+var cosmosKeyringTo string
+var cosmosKeyringArmor string
+var cosmosKeyringName string
+var cosmosKeyringIndex int
+var cosmosKeyringAccount int
+var cosmosKeyringYes bool
+
+var cosmosKeyringCmd = &cobra.Command{
+	Use:   "cosmos-keyring",
+	Short: "Imports keys from and exports keys to a Cosmos SDK keyring.",
+	Long: `Imports keys from and exports keys to a Cosmos SDK keyring.
+
+Keys move between a cosmos vault and the keyring of a Cosmos SDK node or CLI
+(gaiad, osmosisd, ...), either as the keyring's own files or as an armored
+private key of 'keys export' and 'keys import'.
+
+The file and test keyring backends are supported: their directories
+(keyring-file, keyring-test) are read and written directly, so no node binary
+is needed. The os backend keeps keys in the system keychain and is not
+supported; move keys out of it with 'keys export' first.
+
+Only secp256k1 keys are handled. Ledger, multisig and offline keys hold no
+private key and are skipped.
+`,
+}
+
+var cosmosKeyringImportCmd = &cobra.Command{
+	Use:   "import <SOURCE>",
+	Short: "Imports the keys of a Cosmos SDK keyring or an armored key into the active vault.",
+	Long: `Imports the keys of a Cosmos SDK keyring or an armored key into the active vault.
+
+SOURCE is a keyring-file or keyring-test directory, the node home containing
+it (e.g. ~/.gaia), or a private key file written by 'keys export'. The
+passphrase of the keyring or of the armored key is asked for; the test
+backend has none.
+
+Wallets are named after the key names, or the armored key file's name. Keys
+already in the vault and prefixes that exist are handled by --on-conflict as
+in 'import'. Imported keys are checked for known test vectors, low entropy
+and known compromise, as in 'import'.
+
+Examples:
+  vault.module cosmos-keyring import ~/.gaia
+  vault.module cosmos-keyring import ~/.gaia/keyring-file --on-conflict overwrite
+  vault.module cosmos-keyring import validator.armor
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			if activeVault.Type != constants.VaultTypeCosmos {
+				return errors.NewInvalidInputError(activeVault.Type, "Cosmos keyring keys can only be imported into cosmos vaults")
+			}
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("cosmos-keyring import")
+			}
+			if err := validateConflictPolicy(cosmosKeyringConflict); err != nil {
+				return err
+			}
+			if err := actions.ValidatePrefix(cosmosKeyringPrefix); err != nil {
+				return err
+			}
+
+			source := args[0]
+			format := convert.FormatCosmosKeyring
+			if info, err := os.Stat(source); err == nil && info.Mode().IsRegular() {
+				format = convert.FormatCosmosArmor
+			}
+
+			result, err := convert.Convert(format, source, cosmosKeyringPrefix, askForSecretInput)
+			if err != nil {
+				return err
+			}
+			defer result.Clear()
+			printConvertReport(result.Report)
+			if len(result.Wallets) == 0 {
+				fmt.Println(colors.SafeColor("No keys to import.", colors.Warning))
+				return nil
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+
+			// Ensure vault secrets are cleared when function exits
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			content, err := actions.ExportVault(result.Wallets)
+			if err != nil {
+				return errors.New(errors.ErrCodeInternal, "failed to encode the converted keys").WithContext("error", err.Error())
+			}
+			policy, err := activePrefixPolicy()
+			if err != nil {
+				security.SecureZero(content)
+				return err
+			}
+			updatedVault, imported, report, err := actions.ImportWallets(v, content, constants.FormatJSON, cosmosKeyringConflict, activeVault.Type, policy, nil)
+			security.SecureZero(content)
+			if err != nil {
+				return err
+			}
+			for _, prefix := range imported {
+				warnKnownCompromised("cosmos-keyring import", prefix, updatedVault[prefix])
+				if !confirmWeakSecret("cosmos-keyring import", prefix, keys.AnalyzeWallet(updatedVault[prefix])) {
+					fmt.Println(colors.SafeColor("Cancelled. Nothing was imported.", colors.Info))
+					return nil
+				}
+			}
+
+			if err := vault.SaveVault(activeVault, updatedVault); err != nil {
+				return errors.NewVaultSaveError(activeVault.KeyFile, err)
+			}
+			notifyVaultMutation(webhook.EventWalletImported, "", fmt.Sprintf("%d wallet(s) imported from a Cosmos keyring", len(imported)))
+			runPostImportHooks(imported, fmt.Sprintf("%d wallet(s) imported from a Cosmos keyring", len(imported)))
+			audit.Logger.Info("Cosmos keyring keys imported",
+				slog.String("command", "cosmos-keyring import"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("format", format),
+				slog.String("source", filepath.Base(result.Report.Source)),
+				slog.Int("imported", len(imported)))
+
+			fmt.Println(colors.SafeColor(report, colors.Success))
+			return nil
+		})
+	},
+}
+
+var cosmosKeyringExportCmd = &cobra.Command{
+	Use:   "export <PREFIX> (--to KEYRING_DIR | --armor FILE)",
+	Short: "Exports a wallet's key to a Cosmos SDK keyring or an armored key file.",
+	Long: `Exports a wallet's key to a Cosmos SDK keyring or an armored key file.
+
+With --to, the key of the wallet's address at --account and --index is added
+to a keyring-file or keyring-test directory, or to the one in the node home
+given (e.g. ~/.gaia), under --name (default: the prefix). The keyring's
+passphrase is asked for; a new file keyring takes the passphrase given, asked
+twice. The key is then available to the node's CLI, e.g.
+'gaiad keys show NAME --keyring-backend file'.
+
+With --armor, the key is written to FILE as an armored private key, encrypted
+with a passphrase asked twice, for 'keys import NAME FILE'. New passphrases
+are checked for strength like every new passphrase (see 'envelope').
+
+The export is a secret retrieval: it is refused for frozen wallets, counts
+against the retrieval limits and is recorded in the wallet's access history.
+
+Examples:
+  vault.module cosmos-keyring export validator --to ~/.gaia/keyring-file
+  vault.module cosmos-keyring export hd --index 2 --to ~/.gaia --name ops
+  vault.module cosmos-keyring export validator --armor validator.armor
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if (cosmosKeyringTo == "") == (cosmosKeyringArmor == "") {
+				return errors.NewInvalidInputError("", "give either --to or --armor")
+			}
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			if activeVault.Type != constants.VaultTypeCosmos {
+				return errors.NewInvalidInputError(activeVault.Type, "only keys of cosmos vaults can be exported to a Cosmos keyring")
+			}
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("cosmos-keyring export")
+			}
+
+			prefix := args[0]
+			name := cosmosKeyringName
+			if name == "" {
+				name = prefix
+			}
+			var dir string
+			if cosmosKeyringTo != "" {
+				if err := cosmoskeyring.ValidateName(name); err != nil {
+					return err
+				}
+				if dir, err = cosmoskeyring.TargetDir(cosmosKeyringTo); err != nil {
+					return err
+				}
+			}
+			if cosmosKeyringArmor != "" {
+				if _, err := os.Stat(cosmosKeyringArmor); err == nil && !cosmosKeyringYes {
+					if !askForConfirmation(fmt.Sprintf("File '%s' already exists. Overwrite?", cosmosKeyringArmor)) {
+						fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+						return nil
+					}
+				}
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+
+			// Ensure vault secrets are cleared when function exits
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			wallet, exists := v[prefix]
+			if !exists {
+				return errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
+			}
+			if wallet.Kind == vault.KindConsensus {
+				return errors.NewWalletInvalidError(prefix, "consensus keys go to priv_validator_key.json, see 'consensus export'")
+			}
+			if err := openWalletEnvelope("cosmos-keyring export", prefix, &wallet); err != nil {
+				return err
+			}
+			v[prefix] = wallet
+			addr := wallet.AddressAt(cosmosKeyringAccount, cosmosKeyringIndex)
+			if addr == nil {
+				return errors.NewAddressNotFoundError(prefix, cosmosKeyringIndex)
+			}
+			if addr.PrivateKey == nil || addr.PrivateKey.IsEmpty() {
+				return missingKeyError(prefix, cosmosKeyringIndex, wallet)
+			}
+			if err := checkWalletNotFrozen("cosmos-keyring export", prefix, wallet); err != nil {
+				return err
+			}
+
+			destination := cosmosKeyringArmor
+			if dir != "" {
+				destination = fmt.Sprintf("%s (key '%s')", dir, name)
+			}
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Exporting the private key of %s (%s) to %s.", prefix, addr.Address, destination), colors.Warning))
+			if !cosmosKeyringYes && !askForConfirmation("Continue?") {
+				fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+				return nil
+			}
+			if err := checkSecretRateLimit(prefix); err != nil {
+				return err
+			}
+
+			var passphrase string
+			switch {
+			case dir != "" && cosmoskeyring.IsTestDir(dir):
+				// The test backend has a fixed password
+			case dir != "" && cosmoskeyring.HasPassphrase(dir):
+				if passphrase, err = askForSecretInput("Keyring passphrase"); err != nil {
+					return err
+				}
+			case dir != "":
+				if passphrase, err = askForNewPassphrase("cosmos-keyring export", "New keyring passphrase", name, prefix); err != nil {
+					return err
+				}
+			default:
+				if passphrase, err = askForNewPassphrase("cosmos-keyring export", "Passphrase for the armored key", name, prefix); err != nil {
+					return err
+				}
+			}
+
+			privKey, err := hex.DecodeString(strings.TrimPrefix(addr.PrivateKey.String(), "0x"))
+			if err != nil {
+				return errors.NewWalletInvalidError(prefix, "the private key is not hex")
+			}
+			defer security.SecureZero(privKey)
+
+			if dir != "" {
+				address, err := cosmoskeyring.Write(dir, passphrase, name, privKey)
+				if err != nil {
+					return err
+				}
+				if !strings.EqualFold(address, addr.Address) {
+					fmt.Println(colors.SafeColor(fmt.Sprintf("Note: the keyring address %s differs from the wallet's %s.", address, addr.Address), colors.Warning))
+				}
+			} else {
+				armored, err := cosmoskeyring.EncryptArmor(privKey, passphrase)
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(cosmosKeyringArmor, []byte(armored), 0600); err != nil {
+					return errors.NewFileSystemError("write", cosmosKeyringArmor, err)
+				}
+			}
+
+			recordWalletAccess(activeVault, v, prefix, "privateKey")
+			audit.Logger.Warn("Private key exported to a Cosmos keyring",
+				slog.String("command", "cosmos-keyring export"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("prefix", prefix),
+				slog.Int("account", cosmosKeyringAccount),
+				slog.Int("index", cosmosKeyringIndex),
+				slog.String("address", addr.Address),
+				slog.String("destination", filepath.Base(destination)))
+
+			if dir != "" {
+				backend := "file"
+				if cosmoskeyring.IsTestDir(dir) {
+					backend = "test"
+				}
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Key '%s' added to %s.", name, dir), colors.Success))
+				fmt.Println(colors.SafeColor(fmt.Sprintf("💡 Check it with 'gaiad keys show %s --keyring-backend %s --keyring-dir %s'", name, backend, filepath.Dir(filepath.Clean(dir))), colors.Info))
+			} else {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Armored key written to '%s'.", cosmosKeyringArmor), colors.Success))
+				fmt.Println(colors.SafeColor(fmt.Sprintf("💡 Import it with 'gaiad keys import %s %s', then delete it", name, cosmosKeyringArmor), colors.Info))
+			}
+			return nil
+		})
+	},
+}
+
+// validateConflictPolicy checks an --on-conflict value
+func validateConflictPolicy(policy string) error {
+	allowedPolicies := []string{constants.ConflictPolicySkip, constants.ConflictPolicyOverwrite, constants.ConflictPolicyFail, constants.ConflictPolicyMerge}
+	for _, allowed := range allowedPolicies {
+		if strings.EqualFold(policy, allowed) {
+			return nil
+		}
+	}
+	return errors.NewInvalidInputError(
+		policy,
+		fmt.Sprintf("invalid conflict policy '%s'. Allowed policies: %s", policy, strings.Join(allowedPolicies, ", ")),
+	)
+}
+
+func init() {
+	cosmosKeyringImportCmd.Flags().StringVar(&cosmosKeyringConflict, "on-conflict", constants.ConflictPolicySkip, "Behavior on conflict (skip, overwrite, fail, merge).")
+	cosmosKeyringImportCmd.Flags().StringVar(&cosmosKeyringPrefix, "prefix", "wallet", "Prefix base for keys without a usable name")
+
+	cosmosKeyringExportCmd.Flags().StringVar(&cosmosKeyringTo, "to", "", "Keyring directory (keyring-file or keyring-test) or node home to add the key to")
+	cosmosKeyringExportCmd.Flags().StringVar(&cosmosKeyringArmor, "armor", "", "Write the key to this file as an armored private key instead")
+	cosmosKeyringExportCmd.Flags().StringVar(&cosmosKeyringName, "name", "", "Key name in the keyring (default: the prefix)")
+	cosmosKeyringExportCmd.Flags().IntVar(&cosmosKeyringIndex, "index", 0, "Index of the address to export")
+	cosmosKeyringExportCmd.Flags().IntVar(&cosmosKeyringAccount, "account", 0, "Account of the address to export (BIP-44 account level)")
+	cosmosKeyringExportCmd.Flags().BoolVar(&cosmosKeyringYes, "yes", false, "Export without confirmation prompt")
+}
@@ -0,0 +1,108 @@
+// File: cmd/dashboard.go
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/dashboard"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var dashboardPort int
+var dashboardAuditLines int
+
+var dashboardCmd = &cobra.Command{
+	Use:   "dashboard",
+	Short: "Serves a read-only web view of the active vault on localhost.",
+	Long: `Serves a read-only web view of the active vault on localhost.
+
+The vault is decrypted once at startup; only public data (prefixes, addresses,
+derivation paths, statistics) is kept, together with a live tail of the audit log.
+Private keys, mnemonics and notes are never served. The page says when the
+vault file changes after startup or a tamper event awaits 'vaults verify'.
+
+The server listens on 127.0.0.1 only and requires a random token generated for
+this session, included in the printed URL. Press Ctrl+C to stop it.
+
+Examples:
+  vault.module dashboard
+  vault.module dashboard --port 9000 --audit-lines 100
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("dashboard")
+			}
+			if dashboardPort < 1 || dashboardPort > 65535 {
+				return errors.NewInvalidInputError(strconv.Itoa(dashboardPort), "port must be between 1 and 65535")
+			}
+			if dashboardAuditLines < 0 {
+				return errors.NewInvalidInputError(strconv.Itoa(dashboardAuditLines), "--audit-lines cannot be negative")
+			}
+
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			snapshot := dashboard.NewSnapshot(config.Cfg.ActiveVault, activeVault.Type, activeVault.Encryption, v)
+			// Only the snapshot is kept while serving
+			for _, wallet := range v {
+				wallet.Clear()
+			}
+
+			tokenBytes := make([]byte, 32)
+			if _, err := rand.Read(tokenBytes); err != nil {
+				return errors.New(errors.ErrCodeInternal, "failed to generate dashboard token").WithContext("error", err.Error())
+			}
+			token := hex.EncodeToString(tokenBytes)
+
+			addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(dashboardPort))
+			ctx := security.GetManager().Context()
+			store := dashboard.NewStore(snapshot, dashboardAuditLines)
+			go func() {
+				if err := store.Follow(ctx, activeVault.KeyFile, "audit.log"); err != nil {
+					audit.Logger.Warn("Dashboard cannot follow the vault and audit log", slog.String("error", err.Error()))
+				}
+			}()
+			server := &dashboard.Server{Store: store, Token: token}
+
+			audit.Logger.Info("Dashboard started", slog.String("command", "dashboard"), slog.String("vault", config.Cfg.ActiveVault), slog.String("addr", addr))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Dashboard for vault '%s' running at:", config.Cfg.ActiveVault), colors.Success))
+			fmt.Printf("   http://%s/?token=%s\n", addr, token)
+			fmt.Println(colors.SafeColor("Press Ctrl+C to stop.", colors.Info))
+
+			if err := server.ListenAndServe(ctx, addr); err != nil {
+				return errors.New(errors.ErrCodeSystem, "dashboard server failed").WithContext("error", err.Error())
+			}
+			audit.Logger.Info("Dashboard stopped", slog.String("command", "dashboard"), slog.String("vault", config.Cfg.ActiveVault))
+			return nil
+		})
+	},
+}
+
+func init() {
+	dashboardCmd.Flags().IntVar(&dashboardPort, "port", 8484, "Port to listen on (127.0.0.1 only).")
+	dashboardCmd.Flags().IntVar(&dashboardAuditLines, "audit-lines", 50, "Number of recent audit log lines to show.")
+}
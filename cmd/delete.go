@@ -4,17 +4,23 @@ package cmd
 import (
 	"fmt"
 	"log/slog"
+	"strings"
 
+	"vault.module/internal/actions"
 	"vault.module/internal/audit"
 	"vault.module/internal/colors"
 	"vault.module/internal/config"
 	"vault.module/internal/errors"
 	"vault.module/internal/vault"
+	"vault.module/internal/webhook"
 
 	"github.com/spf13/cobra"
 )
 
 var deleteYes bool
+var deleteCancel bool
+var deletePending bool
+var deleteNamespace string
 
 var deleteCmd = &cobra.Command{
 	Use:   "delete <PREFIX>",
@@ -22,15 +28,41 @@ var deleteCmd = &cobra.Command{
 	Long: `Deletes a wallet from the active vault.
 
 This command will permanently remove the specified wallet and all its data.
-You will be prompted for confirmation unless --yes flag is used.
+You confirm by typing the wallet's prefix, unless the --yes flag is used; the
+strict profile asks even with --yes. The removed wallet's secrets are wiped
+from memory before the vault is saved, and the deletion is recorded in the
+audit log.
+
+With a cooling-off period ("delete_cooling_off_hours" in config.json, 24 hours
+under the strict profile when not set), the confirmed deletion is only
+scheduled. Running the same delete again once the period has passed completes
+it; until then, --cancel calls it off and --pending lists what is scheduled.
+
+--namespace deletes every wallet of a prefix namespace and its sub-namespaces
+(team/alice deletes team/alice/eth-main and team/alice/ops/hot) at once: they
+are listed, confirmed by typing the namespace and deleted together, with the
+same cooling-off period. A frozen wallet in the namespace stops the deletion.
 
 Examples:
   vault.module delete A1
   vault.module delete mywallet --yes
+  vault.module delete A1 --cancel
+  vault.module delete --pending
+  vault.module delete --namespace team/alice
 `,
-	Args: cobra.ExactArgs(1),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if deletePending || deleteNamespace != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return errors.WrapCommand(func() error {
+			if deletePending {
+				printPendingDeletions()
+				return nil
+			}
+
 			// Check vault status before executing the command
 			if err := checkVaultStatus(); err != nil {
 				return err
@@ -44,6 +76,9 @@ Examples:
 			if programmaticMode {
 				return errors.NewProgrammaticModeError("delete")
 			}
+			if deleteNamespace != "" {
+				return deleteNamespaceWallets(activeVault, deleteNamespace)
+			}
 			
 			prefix := args[0]
 
@@ -52,6 +87,10 @@ Examples:
 			colors.Info,
 		))
 
+			if deleteCancel {
+				return cancelDeletion(config.Cfg.ActiveVault, prefix)
+			}
+
 			v, err := vault.LoadVault(activeVault)
 			if err != nil {
 				return errors.NewVaultLoadError(activeVault.KeyFile, err)
@@ -64,16 +103,20 @@ Examples:
 				}
 			}()
 
-			if _, exists := v[prefix]; !exists {
+			wallet, exists := v[prefix]
+			if !exists {
 				return errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
 			}
+			if err := checkWalletNotFrozen("delete", prefix, wallet); err != nil {
+				return err
+			}
 
-			if !deleteYes {
-				prompt := fmt.Sprintf("Are you sure you want to delete wallet '%s' from vault '%s'? This action is irreversible.", prefix, config.Cfg.ActiveVault)
-				if !askForConfirmation(colors.SafeColor(prompt, colors.Warning)) {
-					fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
-					return nil
-				}
+			proceed, err := confirmDeletion(config.Cfg.ActiveVault, prefix, func() bool {
+				prompt := fmt.Sprintf("You are about to delete wallet '%s' from vault '%s'. This action is irreversible.", prefix, config.Cfg.ActiveVault)
+				return confirmByTyping(prompt, prefix, deleteYes)
+			})
+			if err != nil || !proceed {
+				return err
 			}
 
 			audit.Logger.Warn("Attempting wallet deletion",
@@ -82,7 +125,11 @@ Examples:
 				slog.String("prefix", prefix),
 			)
 
-			delete(v, prefix)
+			removed, err := actions.DeleteWallet(v, config.Cfg.ActiveVault, prefix)
+			if err != nil {
+				return err
+			}
+			removed.Clear()
 
 			if err := vault.SaveVault(activeVault, v); err != nil {
 				audit.Logger.Error("Failed to save vault after deletion", "error", err.Error(), "prefix", prefix)
@@ -90,6 +137,12 @@ Examples:
 			}
 
 			audit.Logger.Info("Wallet deleted successfully", "prefix", prefix, "vault", config.Cfg.ActiveVault)
+			if config.RemovePendingDeletion(config.Cfg.ActiveVault, prefix) {
+				if err := config.SaveConfig(); err != nil {
+					return errors.NewConfigSaveError("config.json", err)
+				}
+			}
+			notifyVaultMutation(webhook.EventWalletDeleted, prefix, "")
 			fmt.Println(colors.SafeColor(
 				fmt.Sprintf("Wallet '%s' successfully deleted from vault '%s'.", prefix, config.Cfg.ActiveVault),
 				colors.Success,
@@ -99,7 +152,92 @@ Examples:
 	},
 }
 
+// deleteNamespaceWallets deletes the wallets of a namespace and its
+// sub-namespaces together, as delete does a single wallet. The deletion is
+// scheduled and cancelled under the namespace followed by '/'.
+func deleteNamespaceWallets(activeVault config.VaultDetails, namespace string) error {
+	if err := actions.ValidateNamespace(namespace); err != nil {
+		return err
+	}
+	namespace = strings.TrimSuffix(namespace, actions.NamespaceSeparator)
+	target := namespace + actions.NamespaceSeparator
+
+	fmt.Println(colors.SafeColor(
+		fmt.Sprintf("Active Vault: %s (Type: %s)", config.Cfg.ActiveVault, activeVault.Type),
+		colors.Info,
+	))
+	if deleteCancel {
+		return cancelDeletion(config.Cfg.ActiveVault, target)
+	}
+
+	v, err := vault.LoadVault(activeVault)
+	if err != nil {
+		return errors.NewVaultLoadError(activeVault.KeyFile, err)
+	}
+	defer func() {
+		for _, wallet := range v {
+			wallet.Clear()
+		}
+	}()
+
+	prefixes := actions.NamespacePrefixes(v, namespace)
+	if len(prefixes) == 0 {
+		return errors.NewInvalidInputError(namespace, fmt.Sprintf("namespace '%s' of vault '%s' has no wallets", namespace, config.Cfg.ActiveVault))
+	}
+	for _, prefix := range prefixes {
+		if err := checkWalletNotFrozen("delete", prefix, v[prefix]); err != nil {
+			return err
+		}
+	}
+
+	proceed, err := confirmDeletion(config.Cfg.ActiveVault, target, func() bool {
+		prompt := fmt.Sprintf("You are about to delete %d wallet(s) of namespace '%s' from vault '%s': %s. This action is irreversible.",
+			len(prefixes), namespace, config.Cfg.ActiveVault, strings.Join(prefixes, ", "))
+		return confirmByTyping(prompt, namespace, deleteYes)
+	})
+	if err != nil || !proceed {
+		return err
+	}
+
+	audit.Logger.Warn("Attempting namespace deletion",
+		slog.String("command", "delete"),
+		slog.String("vault", config.Cfg.ActiveVault),
+		slog.String("namespace", namespace),
+		slog.String("prefixes", strings.Join(prefixes, ",")),
+	)
+	for _, prefix := range prefixes {
+		removed, err := actions.DeleteWallet(v, config.Cfg.ActiveVault, prefix)
+		if err != nil {
+			return err
+		}
+		removed.Clear()
+	}
+
+	if err := vault.SaveVault(activeVault, v); err != nil {
+		audit.Logger.Error("Failed to save vault after deletion", "error", err.Error(), "namespace", namespace)
+		return errors.NewVaultSaveError(activeVault.KeyFile, err)
+	}
+
+	audit.Logger.Info("Namespace deleted successfully", "namespace", namespace, "count", len(prefixes), "vault", config.Cfg.ActiveVault)
+	if config.RemovePendingDeletion(config.Cfg.ActiveVault, target) {
+		if err := config.SaveConfig(); err != nil {
+			return errors.NewConfigSaveError("config.json", err)
+		}
+	}
+	for _, prefix := range prefixes {
+		notifyVaultMutation(webhook.EventWalletDeleted, prefix, "")
+	}
+	fmt.Println(colors.SafeColor(
+		fmt.Sprintf("%d wallet(s) of namespace '%s' successfully deleted from vault '%s'.", len(prefixes), namespace, config.Cfg.ActiveVault),
+		colors.Success,
+	))
+	return nil
+}
+
 func init() {
 
 	deleteCmd.Flags().BoolVar(&deleteYes, "yes", false, "Delete without confirmation prompt")
+	deleteCmd.Flags().BoolVar(&deleteCancel, "cancel", false, "Cancel the scheduled deletion of the wallet")
+	deleteCmd.Flags().BoolVar(&deletePending, "pending", false, "List the scheduled deletions")
+	deleteCmd.Flags().StringVar(&deleteNamespace, "namespace", "", "Delete every wallet of this prefix namespace, e.g. team/alice")
 }
@@ -0,0 +1,128 @@
+// File: cmd/deletesafety.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/display"
+	"vault.module/internal/errors"
+)
+
+// confirmByTyping asks the user to type name to confirm a destructive action.
+// The strict profile asks even when --yes was given.
+func confirmByTyping(prompt, name string, yes bool) bool {
+	if yes && !config.Cfg.Strict {
+		return true
+	}
+	fmt.Println(colors.SafeColor(prompt, colors.Warning))
+	typed, err := askForInput(fmt.Sprintf("Type '%s' to confirm", name))
+	if err != nil || typed != name {
+		if err == nil && typed != "" {
+			fmt.Println(colors.SafeColor(fmt.Sprintf("'%s' does not match '%s'.", typed, name), colors.Warning))
+		}
+		return false
+	}
+	return true
+}
+
+// deletionTarget names a wallet (prefix) of a vault, a namespace (prefix
+// ending in '/') or the vault itself (prefix "") in messages.
+func deletionTarget(vaultName, prefix string) string {
+	if prefix == "" {
+		return fmt.Sprintf("vault '%s'", vaultName)
+	}
+	if namespace, ok := strings.CutSuffix(prefix, "/"); ok {
+		return fmt.Sprintf("namespace '%s' of vault '%s'", namespace, vaultName)
+	}
+	return fmt.Sprintf("wallet '%s' of vault '%s'", prefix, vaultName)
+}
+
+// confirmDeletion confirms the deletion of a wallet (prefix) or a whole vault
+// (prefix "") and applies the cooling-off period. It reports whether the
+// deletion may be carried out now: confirm approved it and there is no
+// cooling-off period, or the one of the deletion scheduled earlier has passed.
+// Otherwise the deletion is scheduled in config.json, or left scheduled, and
+// the user told when it can be completed.
+func confirmDeletion(vaultName, prefix string, confirm func() bool) (bool, error) {
+	target := deletionTarget(vaultName, prefix)
+	now := time.Now().UTC()
+
+	pending := config.FindPendingDeletion(vaultName, prefix)
+	if pending >= 0 {
+		due, err := time.Parse(time.RFC3339, config.Cfg.PendingDeletions[pending].DueAt)
+		if err != nil {
+			return false, errors.NewConfigValidationError("pending_deletions", config.Cfg.PendingDeletions[pending].DueAt, "due_at is not an RFC 3339 time")
+		}
+		if now.Before(due) {
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Deletion of %s is scheduled and can be completed after %s (in %s).",
+				target, due.Local().Format("2006-01-02 15:04"), due.Sub(now).Round(time.Minute)), colors.Warning))
+			fmt.Println("Cancel it with --cancel.")
+			return false, nil
+		}
+	}
+
+	if !confirm() {
+		fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+		return false, nil
+	}
+	coolingOff := config.DeleteCoolingOff()
+	if pending >= 0 || coolingOff <= 0 {
+		return true, nil
+	}
+
+	due := now.Add(coolingOff)
+	config.Cfg.PendingDeletions = append(config.Cfg.PendingDeletions, config.PendingDeletion{
+		Vault:       vaultName,
+		Prefix:      prefix,
+		RequestedAt: now.Format(time.RFC3339),
+		DueAt:       due.Format(time.RFC3339),
+	})
+	if err := config.SaveConfig(); err != nil {
+		return false, errors.NewConfigSaveError("config.json", err)
+	}
+	audit.Logger.Warn("Deletion scheduled",
+		slog.String("vault", vaultName),
+		slog.String("prefix", prefix),
+		slog.String("due_at", due.Format(time.RFC3339)))
+	fmt.Println(colors.SafeColor(fmt.Sprintf("Deletion of %s is scheduled. Nothing has been deleted yet.", target), colors.Success))
+	fmt.Printf("Run the same delete again after %s to complete it, or cancel it with --cancel.\n", due.Local().Format("2006-01-02 15:04"))
+	return false, nil
+}
+
+// cancelDeletion cancels the deletion scheduled for a wallet (prefix) or a
+// whole vault (prefix "").
+func cancelDeletion(vaultName, prefix string) error {
+	target := deletionTarget(vaultName, prefix)
+	if !config.RemovePendingDeletion(vaultName, prefix) {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("No deletion of %s is scheduled.", target), colors.Info))
+		return nil
+	}
+	if err := config.SaveConfig(); err != nil {
+		return errors.NewConfigSaveError("config.json", err)
+	}
+	audit.Logger.Info("Scheduled deletion cancelled", slog.String("vault", vaultName), slog.String("prefix", prefix))
+	fmt.Println(colors.SafeColor(fmt.Sprintf("Deletion of %s cancelled.", target), colors.Success))
+	return nil
+}
+
+// printPendingDeletions lists the deletions waiting out their cooling-off period.
+func printPendingDeletions() {
+	if len(config.Cfg.PendingDeletions) == 0 {
+		fmt.Println(colors.SafeColor("No deletions are scheduled.", colors.Info))
+		return
+	}
+	now := time.Now()
+	for _, p := range config.Cfg.PendingDeletions {
+		status := "ready to complete"
+		if due, err := time.Parse(time.RFC3339, p.DueAt); err == nil && now.Before(due) {
+			status = fmt.Sprintf("due %s", display.Time(due))
+		}
+		fmt.Printf("  %s: %s\n", deletionTarget(p.Vault, p.Prefix), colors.SafeColor(status, colors.Yellow))
+	}
+}
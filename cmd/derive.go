@@ -2,28 +2,49 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"os"
 
 	"vault.module/internal/actions"
+	"vault.module/internal/audit"
 	"vault.module/internal/colors"
 	"vault.module/internal/config"
 	"vault.module/internal/errors"
+	"vault.module/internal/security"
+	"vault.module/internal/tasks"
 	"vault.module/internal/vault"
 
 	"github.com/spf13/cobra"
 )
 
+var deriveAccount int
+var deriveIndex int
+var deriveCount int
+
 var deriveCmd = &cobra.Command{
 	Use:   "derive <PREFIX>",
 	Short: "Derives and adds the next address for a wallet in the active vault.",
 	Long: `Derives and adds the next address for a wallet in the active vault.
 
 This command is only available for HD wallets (created from mnemonic).
-It will derive the next address using the wallet's derivation path.
+It will derive the next address using the wallet's derivation path and path
+scheme (see 'path-scheme'): the next index for BIP-44 and legacy Ledger
+wallets, the next account for Ledger Live wallets.
+
+--account selects another BIP-44 account of the same mnemonic, the hardened
+level before the address chain: m/44'/60'/1'/0/i for account 1 of an EVM
+wallet. --index derives a specific address instead of the next one.
+
+--count derives that many addresses in a row, showing the progress; Ctrl+C
+stops it and saves nothing.
 
 Examples:
   vault.module derive A1
   vault.module derive myhdwallet
+  vault.module derive A1 --account 1 --index 0
+  vault.module derive A1 --count 100
 `,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -66,10 +87,29 @@ Examples:
 				return errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
 			}
 
+			if deriveCount < 1 {
+				return errors.NewInvalidInputError(fmt.Sprintf("%d", deriveCount), "count must be at least 1")
+			}
+			if deriveCount > 1 && deriveIndex >= 0 {
+				return errors.NewInvalidInputError("--index", "--index derives one address; use --count without it")
+			}
+
 			// Pass the vault type to the action to use the correct key manager.
-			updatedWallet, newAddr, err := actions.DeriveNextAddress(wallet, activeVault.Type)
+			deriveOne := func(w vault.Wallet) (vault.Wallet, vault.Address, error) {
+				if !cmd.Flags().Changed("account") && deriveIndex < 0 {
+					// The next address in the wallet's path scheme
+					return actions.DeriveNextAddress(w, activeVault.Type)
+				}
+				return actions.DeriveAtIndex(w, activeVault.Type, deriveAccount, deriveIndex)
+			}
+
+			if deriveCount > 1 {
+				return deriveMany(activeVault, v, prefix, wallet, deriveOne)
+			}
+
+			updatedWallet, newAddr, err := deriveOne(wallet)
 			if err != nil {
-				return errors.NewWalletInvalidError(prefix, fmt.Sprintf("derivation error: %s", err.Error()))
+				return derivationError(prefix, err)
 			}
 
 			v[prefix] = updatedWallet
@@ -79,14 +119,75 @@ Examples:
 			}
 
 			fmt.Println(colors.SafeColor(
-				fmt.Sprintf("New address (index %d) successfully derived for wallet '%s'.", newAddr.Index, prefix),
+				fmt.Sprintf("New address (account %d, index %d) successfully derived for wallet '%s'.", newAddr.Account, newAddr.Index, prefix),
 				colors.Success,
 			))
+			fmt.Printf("   Path:    %s\n", newAddr.Path)
 			fmt.Printf("   Address: %s\n", colors.SafeColor(newAddr.Address, colors.Cyan))
 			return nil
 		})
 	},
 }
 
+// deriveMany derives --count addresses as a task and saves them all, or none
+// if the task is cancelled or fails
+func deriveMany(activeVault config.VaultDetails, v vault.Vault, prefix string, wallet vault.Wallet, deriveOne func(vault.Wallet) (vault.Wallet, vault.Address, error)) error {
+	var derived []vault.Address
+	result := tasks.Run(security.GetManager().Context(), tasks.Task{
+		Name:  fmt.Sprintf("Deriving addresses of '%s'", prefix),
+		Stage: "derive",
+		Run: func(ctx context.Context, report func(tasks.Progress)) error {
+			for i := 0; i < deriveCount; i++ {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				var addr vault.Address
+				var err error
+				wallet, addr, err = deriveOne(wallet)
+				if err != nil {
+					return err
+				}
+				derived = append(derived, addr)
+				report(tasks.Progress{Done: i + 1, Total: deriveCount, Item: addr.Path})
+			}
+			return nil
+		},
+	}, os.Stderr)
+	if result.Cancelled {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Cancelled after %d of %d addresses. Nothing was saved.", result.Progress.Done, deriveCount), colors.Info))
+		return nil
+	}
+	if result.Err != nil {
+		return derivationError(prefix, result.Err)
+	}
+
+	v[prefix] = wallet
+	if err := vault.SaveVault(activeVault, v); err != nil {
+		return errors.NewVaultSaveError(activeVault.KeyFile, err)
+	}
+	audit.Logger.Info("Addresses derived",
+		slog.String("vault", config.Cfg.ActiveVault),
+		slog.String("prefix", prefix),
+		slog.Int("count", len(derived)),
+		slog.Duration("elapsed", result.Elapsed))
+	fmt.Println(colors.SafeColor(fmt.Sprintf("%d addresses successfully derived for wallet '%s'.", len(derived), prefix), colors.Success))
+	for _, addr := range derived {
+		fmt.Printf("   %-24s %s\n", addr.Path, colors.SafeColor(addr.Address, colors.Cyan))
+	}
+	return nil
+}
+
+// derivationError reports a failed derivation. Wallets that cannot be derived
+// from and invalid input are reported as the action layer describes them.
+func derivationError(prefix string, err error) error {
+	if errors.IsCode(err, errors.ErrCodeWalletInvalid) || errors.IsCode(err, errors.ErrCodeInvalidInput) {
+		return err
+	}
+	return errors.NewWalletInvalidError(prefix, fmt.Sprintf("derivation error: %s", err.Error()))
+}
+
 func init() {
+	deriveCmd.Flags().IntVar(&deriveAccount, "account", 0, "BIP-44 account to derive in.")
+	deriveCmd.Flags().IntVar(&deriveIndex, "index", -1, "Address index to derive (default: the next one in the account).")
+	deriveCmd.Flags().IntVar(&deriveCount, "count", 1, "Number of addresses to derive in a row.")
 }
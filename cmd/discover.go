@@ -0,0 +1,214 @@
+// File: cmd/discover.go
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"vault.module/internal/actions"
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/keys"
+	"vault.module/internal/security"
+	"vault.module/internal/tasks"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var discoverGap int
+var discoverRPC string
+var discoverYes bool
+
+var discoverCmd = &cobra.Command{
+	Use:   "discover <PREFIX>",
+	Short: "Fills an HD wallet with exactly the addresses that were used on-chain.",
+	Long: `Fills an HD wallet with exactly the addresses that were used on-chain.
+
+Addresses are derived along the wallet's derivation path and checked against
+--rpc (EVM JSON-RPC, or Cosmos REST) until a gap of unused addresses in a row
+is found. The wallet then holds every address up to the last used one: missing
+addresses are derived, and unused addresses past the last used one are removed
+after confirmation. The first address is always kept.
+
+Discovery follows the wallet's path scheme (see 'path-scheme'): addresses of
+account 0 for BIP-44 and legacy Ledger wallets, accounts for Ledger Live
+wallets. Addresses outside the scheme are left alone.
+
+The gap defaults to "discovery_gap" in config.json (20, the BIP-44 gap limit).
+The address being checked is shown while discovery runs; Ctrl+C stops it
+without changing the wallet.
+
+Examples:
+  vault.module discover A1 --rpc https://eth.llamarpc.com
+  vault.module discover A1 --rpc https://rest.cosmos.directory/cosmoshub --gap 5
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("discover")
+			}
+			if discoverRPC == "" {
+				return errors.NewInvalidInputError("--rpc", "discover needs an endpoint to tell used addresses from unused ones")
+			}
+			gap := discoverGap
+			if gap == 0 {
+				gap = config.Cfg.DiscoveryGap
+			}
+			if gap < 1 {
+				return errors.NewInvalidInputError(fmt.Sprintf("%d", gap), "the gap must be at least 1")
+			}
+
+			fmt.Println(colors.SafeColor(
+				fmt.Sprintf("Active Vault: %s (Type: %s)", config.Cfg.ActiveVault, activeVault.Type),
+				colors.Info,
+			))
+
+			prefix := args[0]
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			wallet, exists := v[prefix]
+			if !exists {
+				return errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
+			}
+			if err := checkWalletNotFrozen("discover", prefix, wallet); err != nil {
+				return err
+			}
+			if wallet.Sealed() {
+				return errors.NewWalletInvalidError(prefix, "wallet is sealed in an envelope; remove it with 'envelope remove' before discovering addresses")
+			}
+			if !wallet.IsHD() {
+				return errors.NewWalletInvalidError(prefix, "discovery is only possible for HD wallets (with a mnemonic or seed)")
+			}
+
+			// Walk the address chain until gap addresses in a row are unused
+			lastUsed := -1
+			var used []string
+			result := tasks.Run(security.GetManager().Context(), tasks.Task{
+				Name:  fmt.Sprintf("Checking addresses of '%s'", prefix),
+				Stage: "discover",
+				Run: func(ctx context.Context, report func(tasks.Progress)) error {
+					for i := 0; i-lastUsed <= gap; i++ {
+						if err := ctx.Err(); err != nil {
+							return err
+						}
+						account, index := wallet.SchemePosition(i)
+						chainPath, err := keys.AccountChainPath(wallet.DerivationPath, account)
+						if err != nil {
+							return errors.NewWalletInvalidError(prefix, err.Error())
+						}
+						path := fmt.Sprintf("%s/%d", chainPath, index)
+						report(tasks.Progress{Done: i, Item: path})
+						address, err := keys.AddressAt(activeVault.Type, wallet, path)
+						if err != nil {
+							return errors.NewWalletInvalidError(prefix, fmt.Sprintf("derivation error at %s: %s", path, err.Error()))
+						}
+						activity, err := addressActivity(activeVault.Type, discoverRPC, address)
+						if err != nil {
+							return errors.Wrap(errors.ErrCodeSystem, fmt.Sprintf("failed to check %s", address), err)
+						}
+						if activity.Triggered {
+							lastUsed = i
+							used = append(used, fmt.Sprintf("  %-24s %s  %s", path, address, colors.SafeColor(fmt.Sprintf("used (nonce %d, balance %s)", activity.Nonce, activity.Balance), colors.Success)))
+						}
+					}
+					return nil
+				},
+			}, os.Stderr)
+			for _, line := range used {
+				fmt.Println(line)
+			}
+			if result.Cancelled {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Cancelled after checking %d addresses. Nothing was changed.", result.Progress.Done), colors.Info))
+				return nil
+			}
+			if result.Err != nil {
+				return result.Err
+			}
+
+			want := lastUsed + 1
+			if want < 1 {
+				want = 1
+			}
+			have := wallet.SchemeLength()
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Last used: #%d. The wallet holds addresses up to #%d and should hold up to #%d.", lastUsed, have-1, want-1), colors.Info))
+			missing := 0
+			for i := 0; i < want; i++ {
+				if wallet.AddressAt(wallet.SchemePosition(i)) == nil {
+					missing++
+				}
+			}
+			if want >= have && missing == 0 {
+				fmt.Println(colors.SafeColor("Nothing to change.", colors.Success))
+				return nil
+			}
+
+			if want < have {
+				if !discoverYes && !askForConfirmation(colors.SafeColor(fmt.Sprintf("Remove the unused addresses from #%d on?", want), colors.Warning)) {
+					fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+					return nil
+				}
+				kept := make([]vault.Address, 0, len(wallet.Addresses))
+				for _, addr := range wallet.Addresses {
+					if wallet.SchemeOrdinal(addr) >= want {
+						if addr.PrivateKey != nil {
+							addr.PrivateKey.Clear()
+						}
+						continue
+					}
+					kept = append(kept, addr)
+				}
+				wallet.Addresses = kept
+			}
+			for i := 0; i < want; i++ {
+				account, index := wallet.SchemePosition(i)
+				if wallet.AddressAt(account, index) != nil {
+					continue
+				}
+				wallet, _, err = actions.DeriveAddress(wallet, activeVault.Type, account, index)
+				if err != nil {
+					return errors.NewWalletInvalidError(prefix, fmt.Sprintf("derivation error: %s", err.Error()))
+				}
+			}
+
+			v[prefix] = wallet
+			if err := vault.SaveVault(activeVault, v); err != nil {
+				return errors.NewVaultSaveError(activeVault.KeyFile, err)
+			}
+
+			audit.Logger.Info("Addresses discovered", slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.Int("gap", gap), slog.Int("before", have), slog.Int("after", want))
+			fmt.Println(colors.SafeColor(
+				fmt.Sprintf("Wallet '%s' now holds addresses #0 to #%d of its %s path scheme.", prefix, want-1, wallet.Scheme()),
+				colors.Success,
+			))
+			return nil
+		})
+	},
+}
+
+func init() {
+	discoverCmd.Flags().IntVar(&discoverGap, "gap", 0, "Unused addresses in a row after which discovery stops (default: discovery_gap from config.json).")
+	discoverCmd.Flags().StringVar(&discoverRPC, "rpc", "", "Endpoint to check addresses for activity (EVM JSON-RPC or Cosmos REST).")
+	discoverCmd.Flags().BoolVar(&discoverYes, "yes", false, "Remove unused addresses without confirmation.")
+}
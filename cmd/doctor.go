@@ -0,0 +1,103 @@
+// File: cmd/doctor.go
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/errors"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnoses dependencies and connected YubiKeys.",
+	Long: `Diagnoses dependencies and connected YubiKeys.
+
+This command checks that the required external tools are installed and run,
+for every configured vault (other commands only look up the tools of the vault
+they work on, to start quickly), enumerates the age identities on all connected YubiKeys and shows which
+configured vault each key (serial and slot) is mapped to.
+
+Examples:
+  vault.module doctor
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			fmt.Println(colors.SafeColor("Dependencies:", colors.Bold))
+			if err := checkDependencies(); err != nil {
+				fmt.Printf("  %s %s\n", colors.SafeColor("✗", colors.Error), errors.FormatForUser(err))
+				return nil
+			}
+			fmt.Printf("  %s age is installed\n", colors.SafeColor("✓", colors.Success))
+			for _, plugin := range requiredPlugins() {
+				fmt.Printf("  %s %s is installed\n", colors.SafeColor("✓", colors.Success), plugin)
+			}
+
+			identities, err := vault.ListYubiKeys()
+			if err != nil {
+				fmt.Printf("  %s %s\n", colors.SafeColor("✗", colors.Error), errors.FormatForUser(err))
+				return nil
+			}
+
+			fmt.Println(colors.SafeColor("Connected YubiKey identities:", colors.Bold))
+			if len(identities) == 0 {
+				fmt.Println(colors.SafeColor("  No YubiKey with age identities found.", colors.Warning))
+			}
+			for _, id := range identities {
+				fmt.Printf("  - Serial %s, Slot %s", colors.SafeColor(id.Serial, colors.Cyan), colors.SafeColor(id.Slot, colors.Cyan))
+				if id.Name != "" {
+					fmt.Printf(" (%s)", id.Name)
+				}
+				fmt.Println()
+				if id.TouchPolicy != "" || id.PINPolicy != "" {
+					fmt.Printf("    PIN policy: %s, Touch policy: %s\n", id.PINPolicy, id.TouchPolicy)
+				}
+			}
+
+			names := make([]string, 0, len(config.Cfg.Vaults))
+			for name := range config.Cfg.Vaults {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			fmt.Println(colors.SafeColor("Vault mapping:", colors.Bold))
+			if len(names) == 0 {
+				fmt.Println(colors.SafeColor("  No vaults configured.", colors.Info))
+			}
+			for _, name := range names {
+				details := config.Cfg.Vaults[name]
+				if details.Encryption != constants.EncryptionYubiKey {
+					continue
+				}
+				id, found := vault.FindYubiKeyIdentity(identities, details)
+				if found {
+					fmt.Printf("  %s %s -> Serial %s, Slot %s\n", colors.SafeColor("✓", colors.Success), name, id.Serial, id.Slot)
+				} else {
+					fmt.Printf("  %s %s -> %s\n", colors.SafeColor("✗", colors.Warning), name,
+						colors.SafeColor(describeYubikeyRequirement(details), colors.Warning))
+				}
+			}
+			return nil
+		})
+	},
+}
+
+// describeYubikeyRequirement explains which YubiKey a vault expects when it is not connected
+func describeYubikeyRequirement(details config.VaultDetails) string {
+	serial := details.YubikeySerial
+	if serial == "" {
+		serial = "any"
+	}
+	slot := details.ResolveYubikeySlot()
+	if slot == "" {
+		slot = "any"
+	}
+	return fmt.Sprintf("no connected key matches (serial: %s, slot: %s)", serial, slot)
+}
@@ -0,0 +1,131 @@
+// File: cmd/envelope.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var envelopeCmd = &cobra.Command{
+	Use:   "envelope",
+	Short: "Seals high-value wallets with a passphrase of their own.",
+	Long: `Seals high-value wallets with a passphrase of their own.
+
+A sealed wallet's mnemonic, private keys or secret are encrypted a second time
+with a per-wallet passphrase (age scrypt) and kept inside the vault as an
+envelope. Unlocking the vault then reveals only the wallet's public data:
+get, secret get, exec, provision, prove, airgap sign and sign batch ask for the
+envelope passphrase on the terminal, and the opened secrets are never written
+back to the vault. Sealed wallets cannot be opened in programmatic mode, and
+derive refuses them; 'list' marks them [SEALED].
+
+seal asks for the passphrase twice and estimates its strength before sealing
+with it, as for every new passphrase: "passphrase_estimator" in config.json
+selects "zxcvbn" (default: common passwords, words, names, keyboard rows,
+sequences, repeats and dates), "length" or "command", a program given in
+"passphrase_estimator_command" that reads the passphrase on its first line of
+input and prints {"score": 0-4, "guesses_log10": ...}. Very weak passphrases
+(score 0 or 1) need a confirmation, and the strict profile refuses them.
+
+Examples:
+  vault.module envelope seal treasury
+  vault.module envelope remove treasury
+`,
+}
+
+var envelopeSealCmd = &cobra.Command{
+	Use:   "seal <PREFIX>",
+	Short: "Moves a wallet's secrets into a passphrase envelope.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			return setWalletEnvelope("envelope seal", args[0], true)
+		})
+	},
+}
+
+var envelopeRemoveCmd = &cobra.Command{
+	Use:   "remove <PREFIX>",
+	Short: "Opens a wallet's envelope for good, storing its secrets with the vault again.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			return setWalletEnvelope("envelope remove", args[0], false)
+		})
+	},
+}
+
+// setWalletEnvelope seals the wallet, or removes its envelope when seal is false
+func setWalletEnvelope(command, prefix string, seal bool) error {
+	if programmaticMode {
+		return errors.NewProgrammaticModeError(command)
+	}
+	if err := checkVaultStatus(); err != nil {
+		return err
+	}
+	activeVault, err := config.GetActiveVault()
+	if err != nil {
+		return err
+	}
+
+	v, err := vault.LoadVault(activeVault)
+	if err != nil {
+		return errors.NewVaultLoadError(activeVault.KeyFile, err)
+	}
+	defer func() {
+		for _, wallet := range v {
+			wallet.Clear()
+		}
+	}()
+
+	wallet, exists := v[prefix]
+	if !exists {
+		return errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
+	}
+	if err := checkWalletNotFrozen(command, prefix, wallet); err != nil {
+		return err
+	}
+
+	if seal {
+		if wallet.Sealed() {
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Wallet '%s' is already sealed.", prefix), colors.Info))
+			return nil
+		}
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Choose the envelope passphrase for wallet '%s'. It cannot be recovered.", prefix), colors.Warning))
+		passphrase, err := askForNewPassphrase(command, "Envelope passphrase", prefix)
+		if err != nil {
+			return err
+		}
+		err = vault.SealWallet(&wallet, passphrase)
+	} else {
+		if !wallet.Sealed() {
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Wallet '%s' is not sealed.", prefix), colors.Info))
+			return nil
+		}
+		err = vault.UnsealWallet(&wallet)
+	}
+	v[prefix] = wallet
+	if err != nil {
+		return err
+	}
+	if err := vault.SaveVault(activeVault, v); err != nil {
+		return errors.NewVaultSaveError(activeVault.KeyFile, err)
+	}
+
+	if seal {
+		audit.Logger.Warn("Wallet sealed", slog.String("command", command), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix))
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Wallet '%s' is sealed. Its secrets now need the envelope passphrase.", prefix), colors.Success))
+		return nil
+	}
+	audit.Logger.Warn("Wallet envelope removed", slog.String("command", command), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix))
+	fmt.Println(colors.SafeColor(fmt.Sprintf("Wallet '%s' is no longer sealed.", prefix), colors.Success))
+	return nil
+}
@@ -0,0 +1,195 @@
+// File: cmd/exec.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var execEnv []string
+var execIndex int
+
+// defaultExecEnv is injected when no --env mapping is given
+var defaultExecEnv = []string{"PRIVATE_KEY=privatekey", "ADDRESS=address"}
+
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var execCmd = &cobra.Command{
+	Use:   "exec <PREFIX> -- <COMMAND> [ARGS...]",
+	Short: "Runs a command with wallet secrets injected as environment variables.",
+	Long: `Runs a command with wallet secrets injected as environment variables.
+
+The secrets are only placed in the child's environment; they are never printed,
+copied to the clipboard or exported into the calling shell, and vault.module
+wipes its own copies before the child starts. The child is terminated if
+vault.module receives a shutdown signal, and vault.module exits with the
+child's exit status.
+
+Each --env flag maps an environment variable to a wallet field
+(address, privatekey, mnemonic, seed or secret). Without --env, PRIVATE_KEY and ADDRESS are set.
+
+Examples:
+  vault.module exec A1 -- node deploy.js
+  vault.module exec A1 --index 2 -- forge script Deploy.s.sol
+  vault.module exec A1 --env DEPLOYER_KEY=privatekey --env SEED=mnemonic -- ./run.sh
+`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			dash := cmd.ArgsLenAtDash()
+			if dash != 1 || len(args) < 2 {
+				return errors.NewInvalidInputError(strings.Join(args, " "), "usage: exec <PREFIX> -- <COMMAND> [ARGS...]")
+			}
+			prefix := args[0]
+			command := args[1:]
+
+			mappings := execEnv
+			if len(mappings) == 0 {
+				mappings = defaultExecEnv
+			}
+			fields, err := parseFieldMappings("--env", mappings, envNameRegex)
+			if err != nil {
+				return err
+			}
+
+			if security.IsShuttingDown() {
+				return errors.New(errors.ErrCodeSystem, "system is shutting down, cannot process new commands")
+			}
+
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			wallet, exists := v[prefix]
+			if !exists {
+				return errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
+			}
+
+			if hasSecretField(fields) {
+				if err := openWalletEnvelope("exec", prefix, &wallet); err != nil {
+					return err
+				}
+				v[prefix] = wallet
+			}
+			values, hasSecrets, err := resolveWalletFields(wallet, prefix, execIndex, fields)
+			if err != nil {
+				return err
+			}
+			if hasSecrets {
+				if err := checkWalletNotFrozen("exec", prefix, wallet); err != nil {
+					return err
+				}
+				if err := checkWalletSecretRateLimit("exec", prefix, wallet); err != nil {
+					return err
+				}
+				recordWalletAccess(activeVault, v, prefix, strings.Join(fieldMappingNames(fields), ","))
+			}
+
+			child := exec.Command(command[0], command[1:]...)
+			injected := make([]string, 0, len(values))
+			for _, name := range fieldMappingNames(fields) {
+				injected = append(injected, name+"="+values[name])
+			}
+			child.Env = append(scrubbedEnviron(fields), injected...)
+			child.Stdin = os.Stdin
+			child.Stdout = os.Stdout
+			child.Stderr = os.Stderr
+
+			// From here on the child's environment is the only copy needed: wipe
+			// the wallets and drop our references to the injected values before
+			// the child runs, however long it takes
+			for _, w := range v {
+				w.Clear()
+			}
+			for i := range injected {
+				injected[i] = ""
+			}
+			for name := range values {
+				delete(values, name)
+			}
+
+			audit.Logger.Warn("Secrets injected into child process",
+				slog.String("command", "exec"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("prefix", prefix),
+				slog.Int("index", execIndex),
+				slog.String("program", command[0]),
+				slog.String("variables", strings.Join(fieldMappingNames(fields), ",")),
+			)
+
+			err = child.Start()
+			child.Env = nil
+			if err != nil {
+				return errors.New(errors.ErrCodeSystem, "failed to start command").
+					WithContext("program", command[0]).
+					WithContext("error", err.Error())
+			}
+			security.GetManager().RegisterProcess(child.Process, fmt.Sprintf("exec child %s (pid %d)", command[0], child.Process.Pid))
+
+			waitErr := child.Wait()
+			security.GetManager().UnregisterProcess(child.Process)
+
+			if waitErr != nil {
+				if exitErr, ok := waitErr.(*exec.ExitError); ok && exitErr.ExitCode() > 0 {
+					// The child reported its own failure: pass its status on, as a wrapper does
+					cmd.SilenceErrors = true
+					cmd.SilenceUsage = true
+					return errors.NewChildExitError(command[0], exitErr.ExitCode())
+				}
+				return errors.New(errors.ErrCodeSystem, "command failed").
+					WithContext("program", command[0]).
+					WithContext("error", waitErr.Error())
+			}
+			return nil
+		})
+	},
+}
+
+// scrubbedEnviron returns the current environment without the injected names and
+// without vault.module's own credentials, so the child only sees what it was given.
+func scrubbedEnviron(fields map[string]string) []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, injected := fields[name]; injected {
+			continue
+		}
+		if name == "VAULT_AUTH_TOKEN" || name == "VAULT_APPROVAL_TOKEN" || name == "VAULT_MODULE_PROGRAMMATIC" {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
+
+func init() {
+	execCmd.Flags().StringArrayVar(&execEnv, "env", nil, "Map an environment variable to a wallet field as NAME=FIELD (repeatable).")
+	execCmd.Flags().IntVar(&execIndex, "index", 0, "Index of the address used for address/privatekey fields.")
+}
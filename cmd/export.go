@@ -3,6 +3,8 @@ package cmd
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
 	"os"
@@ -14,12 +16,17 @@ import (
 	"vault.module/internal/colors"
 	"vault.module/internal/config"
 	"vault.module/internal/errors"
+	"vault.module/internal/security"
 	"vault.module/internal/vault"
 
 	"github.com/spf13/cobra"
 )
 
 var exportYes bool
+var exportAllowScreenCapture bool
+var exportCanonical bool
+var exportDigest bool
+var exportNamespace string
 
 var exportCmd = &cobra.Command{
 	Use:   "export [OUTPUT_FILE]",
@@ -30,10 +37,30 @@ This command exports all wallets and their data to a JSON file.
 The exported file will be unencrypted, so handle it with care.
 If no output file is specified, it will create a file in the vault directory.
 
+With --canonical the file is written in a canonical form (keys sorted at every
+level, addresses ordered by index, compact, no timestamps) and its SHA-256 is
+printed; the hash is the same on every machine for the same logical contents.
+--digest prints only that hash and writes no file, so auditors can compare
+vault states without creating a plaintext copy. Envelopes of sealed wallets
+are included as stored, so re-sealing a wallet changes the hash.
+
+Every export file is signed with the vault's attestation key (see 'vaults
+attestation'). The signature, the vault's ID, the tool version and the time
+of the export are written next to the file as <OUTPUT_FILE>.sig, leaving the
+export itself unchanged; 'import --verify-signature' checks them.
+
+--namespace exports only the wallets of a prefix namespace and its
+sub-namespaces (team/alice exports team/alice/eth-main and
+team/alice/ops/hot), e.g. to hand a team member their own wallets; with
+--digest it hashes only those.
+
 Examples:
   vault.module export                    # Export to vault_directory/export.json
   vault.module export wallets.json       # Export to specific file
   vault.module export backup.json --yes  # Export with confirmation skip
+  vault.module export audit.json --canonical
+  vault.module export --digest           # Print the canonical SHA-256 only
+  vault.module export alice.json --namespace team/alice
 `,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -47,6 +74,15 @@ Examples:
 			if err != nil {
 				return err
 			}
+			if exportNamespace != "" {
+				if err := actions.ValidateNamespace(exportNamespace); err != nil {
+					return err
+				}
+			}
+
+			if exportDigest {
+				return printExportDigest(activeVault)
+			}
 
 			if programmaticMode {
 				return errors.NewProgrammaticModeError("export")
@@ -97,10 +133,29 @@ Examples:
 				))
 				return nil
 			}
+			exported := exportScope(v)
+			if len(exported) == 0 {
+				fmt.Println(colors.SafeColor(
+					fmt.Sprintf("Namespace '%s' of vault '%s' has no wallets. Nothing to export.", exportNamespace, config.Cfg.ActiveVault),
+					colors.Info,
+				))
+				return nil
+			}
+			if err := checkVaultNotFrozen("export", exported); err != nil {
+				return err
+			}
+
+			if err := confirmNoScreenCapture("export", exportAllowScreenCapture); err != nil {
+				return err
+			}
 
 			if !exportYes {
+				scope := "all secrets from the active vault"
+				if exportNamespace != "" {
+					scope = fmt.Sprintf("the secrets of %d wallet(s) in namespace '%s'", len(exported), exportNamespace)
+				}
 				if !askForConfirmation(colors.SafeColor(
-					"WARNING: You are about to create an unencrypted copy of all secrets from the active vault. Are you sure?",
+					fmt.Sprintf("WARNING: You are about to create an unencrypted copy of %s. Are you sure?", scope),
 					colors.Warning,
 				)) {
 					fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
@@ -111,19 +166,41 @@ Examples:
 			audit.Logger.Error("Executing plaintext export of an entire vault",
 				slog.String("command", "export"),
 				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("namespace", exportNamespace),
 				slog.String("destination_file", filepath.Base(outputFile)), // Log only filename, not full path
 			)
 
-			jsonData, err := actions.ExportVault(v)
+			var jsonData []byte
+			if exportCanonical {
+				jsonData, err = actions.CanonicalExportVault(exported)
+			} else {
+				jsonData, err = actions.ExportVault(exported)
+			}
 			if err != nil {
 				return errors.NewExportFailedError("json", "failed to generate JSON for export", err)
 			}
+			defer security.SecureZero(jsonData)
 
 			if err := os.WriteFile(outputFile, jsonData, 0600); err != nil {
 				return errors.NewFileSystemError("write", outputFile, err)
 			}
+			if err := signExport(config.Cfg.ActiveVault, activeVault, v, jsonData, outputFile); err != nil {
+				return err
+			}
+			if exportCanonical {
+				digest := sha256.Sum256(jsonData)
+				audit.Logger.Info("Canonical export digest", slog.String("vault", config.Cfg.ActiveVault), slog.String("sha256", hex.EncodeToString(digest[:])))
+				fmt.Printf("SHA-256: %s\n", hex.EncodeToString(digest[:]))
+			}
 
 			audit.Logger.Info("Plaintext export completed successfully", "destination_file", filepath.Base(outputFile)) // Log only filename, not full path
+			if exportNamespace != "" {
+				fmt.Println(colors.SafeColor(
+					fmt.Sprintf("Wallets of namespace '%s' (%d) from vault '%s' successfully exported to '%s'.", exportNamespace, len(exported), config.Cfg.ActiveVault, outputFile),
+					colors.Success,
+				))
+				return nil
+			}
 			fmt.Println(colors.SafeColor(
 				fmt.Sprintf("All wallets (%d) from vault '%s' successfully exported to '%s'.", len(v), config.Cfg.ActiveVault, outputFile),
 				colors.Success,
@@ -133,6 +210,48 @@ Examples:
 	},
 }
 
+// printExportDigest prints the SHA-256 of the canonical export of the vault without writing it
+func printExportDigest(activeVault config.VaultDetails) error {
+	v, err := vault.LoadVault(activeVault)
+	if err != nil {
+		return errors.NewVaultLoadError(activeVault.KeyFile, err)
+	}
+	defer func() {
+		for _, wallet := range v {
+			wallet.Clear()
+		}
+	}()
+
+	exported := exportScope(v)
+	jsonData, err := actions.CanonicalExportVault(exported)
+	if err != nil {
+		return errors.NewExportFailedError("json", "failed to generate canonical JSON", err)
+	}
+	digest := sha256.Sum256(jsonData)
+	security.SecureZero(jsonData)
+
+	audit.Logger.Info("Canonical export digest", slog.String("vault", config.Cfg.ActiveVault), slog.String("namespace", exportNamespace), slog.String("sha256", hex.EncodeToString(digest[:])), slog.Int("wallet_count", len(exported)))
+	fmt.Println(hex.EncodeToString(digest[:]))
+	return nil
+}
+
+// exportScope returns the wallets of v that export writes: all of them, or
+// those of --namespace
+func exportScope(v vault.Vault) vault.Vault {
+	if exportNamespace == "" {
+		return v
+	}
+	scoped := make(vault.Vault)
+	for _, prefix := range actions.NamespacePrefixes(v, exportNamespace) {
+		scoped[prefix] = v[prefix]
+	}
+	return scoped
+}
+
 func init() {
+	exportCmd.Flags().StringVar(&exportNamespace, "namespace", "", "Export only the wallets of this prefix namespace, e.g. team/alice.")
+	exportCmd.Flags().BoolVar(&exportCanonical, "canonical", false, "Write the canonical form and print its SHA-256.")
+	exportCmd.Flags().BoolVar(&exportDigest, "digest", false, "Print the SHA-256 of the canonical export without writing a file.")
 	exportCmd.Flags().BoolVar(&exportYes, "yes", false, "Skip confirmation prompt.")
+	exportCmd.Flags().BoolVar(&exportAllowScreenCapture, "allow-screen-capture", false, "Export even if screen sharing or recording software is running.")
 }
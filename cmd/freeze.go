@@ -0,0 +1,154 @@
+// File: cmd/freeze.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var freezeReason string
+var unfreezeYes bool
+
+var freezeCmd = &cobra.Command{
+	Use:   "freeze <PREFIX>",
+	Short: "Places a wallet under a legal hold.",
+	Long: `Places a wallet under a legal hold.
+
+A frozen wallet stays in the vault unchanged, but every command that would
+release or use its secrets refuses with a WALLET_FROZEN error: get for the
+private key or mnemonic, exec, provision, prove, clone, airgap sign and sign
+batch.
+The wallet cannot be deleted or overwritten by import, and the vault cannot be
+exported or packaged for inheritance while it holds a frozen wallet. Public
+data such as addresses and notes remain available, and 'list' marks the
+wallet [FROZEN].
+
+Freezing and every refused access are written to the audit log as critical.
+
+Examples:
+  vault.module freeze A1 --reason "Litigation hold, case 2024-117"
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			reason := strings.TrimSpace(freezeReason)
+			if reason == "" {
+				return errors.NewInvalidInputError("--reason", "a reason is required to freeze a wallet")
+			}
+			return setWalletFreeze(args[0], &vault.Freeze{
+				Reason: reason,
+				Since:  time.Now().UTC().Format(time.RFC3339),
+			})
+		})
+	},
+}
+
+var unfreezeCmd = &cobra.Command{
+	Use:   "unfreeze <PREFIX>",
+	Short: "Lifts the legal hold on a wallet.",
+	Long: `Lifts the legal hold on a wallet.
+
+The wallet's secrets become available again. You will be prompted for
+confirmation unless --yes is used; programmatic mode cannot lift a hold.
+
+Examples:
+  vault.module unfreeze A1
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("unfreeze")
+			}
+			return setWalletFreeze(args[0], nil)
+		})
+	},
+}
+
+// setWalletFreeze freezes the wallet with the given hold, or lifts its hold when freeze is nil
+func setWalletFreeze(prefix string, freeze *vault.Freeze) error {
+	if err := checkVaultStatus(); err != nil {
+		return err
+	}
+	activeVault, err := config.GetActiveVault()
+	if err != nil {
+		return err
+	}
+
+	v, err := vault.LoadVault(activeVault)
+	if err != nil {
+		return errors.NewVaultLoadError(activeVault.KeyFile, err)
+	}
+	defer func() {
+		for _, wallet := range v {
+			wallet.Clear()
+		}
+	}()
+
+	wallet, exists := v[prefix]
+	if !exists {
+		return errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
+	}
+
+	if freeze == nil {
+		if wallet.Frozen == nil {
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Wallet '%s' is not frozen.", prefix), colors.Info))
+			return nil
+		}
+		if !unfreezeYes {
+			prompt := fmt.Sprintf("Lift the hold on wallet '%s' (%s, since %s)?", prefix, wallet.Frozen.Reason, wallet.Frozen.Since)
+			if !askForConfirmation(colors.SafeColor(prompt, colors.Warning)) {
+				fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+				return nil
+			}
+		}
+	} else if wallet.Frozen != nil {
+		return errors.NewWalletFrozenError(prefix, wallet.Frozen.Reason).
+			WithDetails(fmt.Sprintf("the wallet has been frozen since %s", wallet.Frozen.Since))
+	}
+
+	previous := wallet.Frozen
+	wallet.Frozen = freeze
+	v[prefix] = wallet
+	if err := vault.SaveVault(activeVault, v); err != nil {
+		return errors.NewVaultSaveError(activeVault.KeyFile, err)
+	}
+
+	if freeze != nil {
+		audit.Logger.Error("Wallet frozen",
+			slog.String("command", "freeze"),
+			slog.String("vault", config.Cfg.ActiveVault),
+			slog.String("prefix", prefix),
+			slog.String("reason", freeze.Reason),
+			slog.String("severity", string(errors.SeverityCritical)),
+		)
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Wallet '%s' is frozen. Its secrets are no longer released.", prefix), colors.Success))
+		return nil
+	}
+
+	audit.Logger.Error("Wallet unfrozen",
+		slog.String("command", "unfreeze"),
+		slog.String("vault", config.Cfg.ActiveVault),
+		slog.String("prefix", prefix),
+		slog.String("reason", previous.Reason),
+		slog.String("frozen_since", previous.Since),
+		slog.String("severity", string(errors.SeverityCritical)),
+	)
+	fmt.Println(colors.SafeColor(fmt.Sprintf("Wallet '%s' is no longer frozen.", prefix), colors.Success))
+	return nil
+}
+
+func init() {
+	freezeCmd.Flags().StringVar(&freezeReason, "reason", "", "Reason for the hold, recorded in the vault and the audit log (required)")
+	unfreezeCmd.Flags().BoolVar(&unfreezeYes, "yes", false, "Lift the hold without confirmation prompt")
+}
@@ -0,0 +1,332 @@
+// File: cmd/generate.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+
+	"vault.module/internal/actions"
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+	"vault.module/internal/vault"
+	"vault.module/internal/webhook"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	maxPasswordLength = 256
+	maxHexBytes       = 1024
+)
+
+var generateLength int
+var generateNoSymbols bool
+var generateBytes int
+var generateStore string
+var generateNotes string
+var generateDice bool
+var generateWords int
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generates high-entropy secrets for non-blockchain credentials.",
+	Long: `Generates high-entropy secrets for non-blockchain credentials.
+
+Secrets come from the operating system's cryptographic random number
+generator, mixed with the sources listed in "entropy_sources" in config.json
+("hwrng" reads /dev/hwrng, "yubikey" uses the HMAC challenge-response of
+YubiKey OTP slot 2 through ykman). With --dice you also roll a six-sided die
+as many times as the secret has bits of entropy, divided by 2.58, and type the
+results. Extra sources can only add entropy, never replace the system's.
+
+Without --store the secret is printed once and kept nowhere. With --store it
+is saved in the active vault instead of being printed: passwords and hex as a
+generic secret entry (read it back with 'get <PREFIX> secret'), mnemonics as
+an HD wallet.
+
+Examples:
+  vault.module generate password
+  vault.module generate password --length 32 --no-symbols
+  vault.module generate hex --bytes 32 --store api-signing --notes "webhook HMAC key"
+  vault.module generate mnemonic --words 24 --dice --store cold1
+`,
+}
+
+var generatePasswordCmd = &cobra.Command{
+	Use:   "password",
+	Short: "Generates a random password.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if generateLength < security.MinPasswordLength || generateLength > maxPasswordLength {
+				return errors.NewInvalidInputError(fmt.Sprintf("%d", generateLength), fmt.Sprintf("--length must be between %d and %d", security.MinPasswordLength, maxPasswordLength))
+			}
+			alphabet := 62
+			if !generateNoSymbols {
+				alphabet += len(security.PasswordSymbols)
+			}
+			if err := collectDiceEntropy(int(float64(generateLength) * math.Log2(float64(alphabet)))); err != nil {
+				return err
+			}
+			password, err := security.GeneratePassword(generateLength, !generateNoSymbols)
+			if err != nil {
+				return errors.Wrap(errors.ErrCodeSystem, "failed to generate password", err)
+			}
+			return emitGeneratedSecret("generate password", vault.SecretTypePassword, password)
+		})
+	},
+}
+
+var generateHexCmd = &cobra.Command{
+	Use:   "hex",
+	Short: "Generates random bytes, hex encoded.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if generateBytes < 1 || generateBytes > maxHexBytes {
+				return errors.NewInvalidInputError(fmt.Sprintf("%d", generateBytes), fmt.Sprintf("--bytes must be between 1 and %d", maxHexBytes))
+			}
+			if err := collectDiceEntropy(8 * generateBytes); err != nil {
+				return err
+			}
+			secret, err := security.GenerateHex(generateBytes)
+			if err != nil {
+				return errors.Wrap(errors.ErrCodeSystem, "failed to generate random bytes", err)
+			}
+			return emitGeneratedSecret("generate hex", vault.SecretTypeGeneric, secret)
+		})
+	},
+}
+
+var generateMnemonicCmd = &cobra.Command{
+	Use:   "mnemonic",
+	Short: "Generates a BIP-39 mnemonic for a new HD wallet.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if generateWords != 12 && generateWords != 24 {
+				return errors.NewInvalidInputError(fmt.Sprintf("%d", generateWords), "--words must be 12 or 24")
+			}
+			if generateStore != "" && programmaticMode {
+				return errors.NewProgrammaticModeError("generate mnemonic --store")
+			}
+			if err := collectDiceEntropy(generateWords / 3 * 32); err != nil {
+				return err
+			}
+			mnemonic, err := actions.GenerateMnemonic(generateWords)
+			if err != nil {
+				return err
+			}
+			secret := security.NewSecureString(mnemonic)
+			defer secret.Clear()
+
+			if generateStore == "" {
+				if err := refuseSecretEcho("generate mnemonic"); err != nil {
+					return err
+				}
+				audit.Logger.Info("Mnemonic generated", slog.Int("words", generateWords), slog.Bool("stored", false))
+				return printSecret("generate mnemonic", secret.String(), true)
+			}
+
+			prefix := generateStore
+			if err := validatePrefix(prefix); err != nil {
+				return err
+			}
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+			if _, exists := v[prefix]; exists {
+				return errors.NewWalletExistsError(prefix)
+			}
+
+			wallet, address, err := actions.CreateWalletFromMnemonic(secret.String(), activeVault.Type)
+			if err != nil {
+				return errors.NewWalletInvalidError(prefix, err.Error())
+			}
+			wallet.Notes = generateNotes
+			v[prefix] = wallet
+			if err := vault.SaveVault(activeVault, v); err != nil {
+				return errors.NewVaultSaveError(activeVault.KeyFile, err)
+			}
+			notifyVaultMutation(webhook.EventWalletAdded, prefix, "generated HD wallet")
+
+			audit.Logger.Info("Mnemonic generated", slog.Int("words", generateWords), slog.Bool("stored", true), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("HD wallet '%s' stored in vault '%s'. First address: %s", prefix, config.Cfg.ActiveVault, address), colors.Success))
+			return nil
+		})
+	},
+}
+
+// configureEntropySources installs the entropy providers listed in config.json
+func configureEntropySources() error {
+	var providers []security.EntropyProvider
+	for _, source := range config.Cfg.EntropySources {
+		switch source {
+		case "hwrng":
+			providers = append(providers, security.HWRNGProvider{})
+		case "yubikey":
+			providers = append(providers, security.YubiKeyChallengeProvider{})
+		default:
+			return errors.NewConfigValidationError("entropy_sources", source, "entropy sources are \"hwrng\" and \"yubikey\"")
+		}
+	}
+	security.SetEntropyProviders(providers...)
+	return nil
+}
+
+// collectDiceEntropy asks for enough die rolls to carry bits of entropy when --dice is set
+func collectDiceEntropy(bits int) error {
+	if !generateDice {
+		return nil
+	}
+	if programmaticMode {
+		return errors.NewProgrammaticModeError("generate --dice")
+	}
+	needed := security.DiceRollsFor(bits)
+	fmt.Println(colors.SafeColor(fmt.Sprintf("Roll a six-sided die %d times and type the results (1-6). Spaces are ignored.", needed), colors.Info))
+	rolls, err := askForRolls("Rolls", needed, parseDieRoll)
+	if err != nil {
+		return err
+	}
+	security.AddEntropyProvider(security.DiceProvider{Rolls: rolls})
+	audit.Logger.Info("Dice entropy collected", slog.Int("rolls", len(rolls)))
+	return nil
+}
+
+// askForRolls reads physical random results at a hidden prompt until needed values
+// are collected. parse maps a typed character to a value; spaces and commas are ignored.
+func askForRolls(what string, needed int, parse func(rune) (byte, bool)) ([]byte, error) {
+	values := make([]byte, 0, needed)
+	for len(values) < needed {
+		input, err := askForSecretInputWithCleanup(fmt.Sprintf("%s (%d to go)", what, needed-len(values)))
+		if err != nil {
+			security.SecureZero(values)
+			return nil, err
+		}
+		for _, c := range input {
+			if c == ' ' || c == ',' {
+				continue
+			}
+			value, ok := parse(c)
+			if !ok {
+				security.SecureZero(values)
+				return nil, errors.NewInvalidInputError(strings.ToLower(what), fmt.Sprintf("unexpected character %q", c))
+			}
+			if len(values) < needed {
+				values = append(values, value)
+			}
+		}
+	}
+	return values, nil
+}
+
+// parseDieRoll accepts the faces of a six-sided die
+func parseDieRoll(c rune) (byte, bool) {
+	if c >= '1' && c <= '6' {
+		return byte(c - '0'), true
+	}
+	return 0, false
+}
+
+// parseCoinFlip accepts H/T or 1/0, heads being 1
+func parseCoinFlip(c rune) (byte, bool) {
+	switch c {
+	case 'h', 'H', '1':
+		return 1, true
+	case 't', 'T', '0':
+		return 0, true
+	}
+	return 0, false
+}
+
+// emitGeneratedSecret prints the secret or, with --store, saves it in the active vault
+func emitGeneratedSecret(command, secretType, value string) error {
+	secret := security.NewSecureString(value)
+	if generateStore == "" {
+		defer secret.Clear()
+		if err := refuseSecretEcho(command); err != nil {
+			return err
+		}
+		audit.Logger.Info("Secret generated", slog.String("command", command), slog.Bool("stored", false))
+		return printSecret(command, secret.String(), true)
+	}
+
+	if programmaticMode {
+		return errors.NewProgrammaticModeError(command + " --store")
+	}
+	prefix := generateStore
+	if err := validatePrefix(prefix); err != nil {
+		secret.Clear()
+		return err
+	}
+	if err := checkVaultStatus(); err != nil {
+		secret.Clear()
+		return err
+	}
+	activeVault, err := config.GetActiveVault()
+	if err != nil {
+		secret.Clear()
+		return err
+	}
+
+	v, err := vault.LoadVault(activeVault)
+	if err != nil {
+		secret.Clear()
+		return errors.NewVaultLoadError(activeVault.KeyFile, err)
+	}
+	defer func() {
+		for _, wallet := range v {
+			wallet.Clear()
+		}
+	}()
+
+	if _, exists := v[prefix]; exists {
+		secret.Clear()
+		return errors.NewWalletExistsError(prefix)
+	}
+	v[prefix] = vault.Wallet{
+		Kind:       vault.KindSecret,
+		SecretType: secretType,
+		Secret:     secret,
+		Addresses:  []vault.Address{},
+		Notes:      generateNotes,
+	}
+	if err := vault.SaveVault(activeVault, v); err != nil {
+		return errors.NewVaultSaveError(activeVault.KeyFile, err)
+	}
+	notifyVaultMutation(webhook.EventWalletAdded, prefix, "generic secret")
+
+	audit.Logger.Info("Secret generated", slog.String("command", command), slog.Bool("stored", true), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix))
+	fmt.Println(colors.SafeColor(fmt.Sprintf("Secret '%s' stored in vault '%s'. Read it with 'vault.module get %s secret'.", prefix, config.Cfg.ActiveVault, prefix), colors.Success))
+	return nil
+}
+
+func init() {
+	generatePasswordCmd.Flags().IntVar(&generateLength, "length", 24, "Password length")
+	generatePasswordCmd.Flags().BoolVar(&generateNoSymbols, "no-symbols", false, "Use only letters and digits")
+	generateHexCmd.Flags().IntVar(&generateBytes, "bytes", 32, "Number of random bytes")
+	generateMnemonicCmd.Flags().IntVar(&generateWords, "words", 24, "Number of words: 12 or 24")
+	generateCmd.PersistentFlags().BoolVar(&generateDice, "dice", false, "Mix in six-sided dice rolls typed at the prompt")
+	for _, c := range []*cobra.Command{generatePasswordCmd, generateHexCmd, generateMnemonicCmd} {
+		c.Flags().StringVar(&generateStore, "store", "", "Store the secret in the active vault under this prefix instead of printing it")
+		c.Flags().StringVar(&generateNotes, "notes", "", "Notes for the stored secret")
+	}
+}
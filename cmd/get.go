@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"os"
 	"strings"
 
 	"vault.module/internal/audit"
 	"vault.module/internal/colors"
 	"vault.module/internal/config"
 	"vault.module/internal/errors"
+	"vault.module/internal/ratelimit"
 	"vault.module/internal/security"
 	"vault.module/internal/vault"
 
@@ -28,9 +30,16 @@ const (
 )
 
 var getIndex int
+var getAccount int
 var getJson bool
 var getCopy bool
 var getClipboardTimeout int // New flag for configurable timeout
+var getAllowScreenCapture bool
+var getOutFD int
+var getOutFIFO string
+var getAnsible bool
+var getAddress string
+var getIndices string
 
 var getCmd = &cobra.Command{
 	Use:   "get <PREFIX> <FIELD>",
@@ -41,20 +50,67 @@ Available fields (FIELD):
   address      - public address (default --index 0)
   privatekey   - private key (default --index 0)
   mnemonic     - mnemonic phrase (if present)
+  seed         - hex BIP-32 seed (wallets imported from a seed, which have no mnemonic)
+  secret       - generic secret (entries created with 'generate --store')
   notes        - notes (if present)
+  path         - derivation path of the address (default --index 0)
+
+Several public fields (address,path) or --indices print a table of the
+wallet's addresses, or a JSON array with --json, from one decryption of the
+vault: one YubiKey touch for any number of addresses. --indices takes a range
+and single indices, e.g. 0-9 or 0,2,5-7, within --account.
+
+With --address the wallet, account and index are found from one of the
+vault's addresses, in any letter case, and only FIELD is given.
 
 Examples:
   vault.module get A1 address
   vault.module get A1 privatekey --index 0
+  vault.module get A1 address --account 1 --index 2      # m/44'/60'/1'/0/2
   vault.module get A1 mnemonic
   vault.module get A1 --json
   vault.module get A1 privatekey --clipboard-timeout 60  # Clear after 60 seconds
+  vault.module get A1 privatekey --out-fd 3 3>key.txt    # Write to an inherited descriptor
+  vault.module get A1 privatekey --out-fifo /tmp/key     # Serve once through a new FIFO
+  vault.module get A1 address --ansible                  # JSON for an Ansible lookup plugin
+  vault.module get A1 address,path --indices 0-9
+  vault.module get A1 address --indices 0-99 --json      # [{"prefix", "account", "index", "address"}, ...]
+  vault.module get --address 0x9858EfFD232B4033E47d90003D41EC34EcaEda94 privatekey
+
+When "no_echo_secrets" is true in config.json (or VAULT_NO_ECHO_SECRETS=true),
+secrets are never printed: modes that would print one (--programmatic, --json
+in programmatic mode, --ansible, strict reveal) fail, and the clipboard,
+--out-fd and --out-fifo remain available. Use it where terminal sessions are recorded.
+
+The strict profile reveals secrets on the terminal instead of the clipboard,
+on the alternate screen so they stay out of the scrollback. Ctrl+L, or no key
+press for "reveal_idle_lock" seconds (30), locks it; unlocking decrypts the
+vault again, with the YubiKey PIN and touch.
+
+Ansible protocol (--ansible):
+  Prints one JSON object {"vault", "prefix", "field", "index", "value"} on stdout
+  and never prompts or uses the clipboard. On failure the exit status is non-zero
+  and the error is written to stderr. Every retrieval is audited as programmatic.
 `,
-	Args: cobra.ExactArgs(2),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if getAddress != "" {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return errors.WrapCommand(func() error {
 		// Validate command arguments first
-		if err := validateGetCommandArgs(args); err != nil {
+		if getAddress != "" {
+			if cmd.Flags().Changed("index") || cmd.Flags().Changed("account") || getIndices != "" {
+				return errors.NewInvalidInputError("--address", "the address already selects the account and index")
+			}
+			if err := validateGetFields(args[0]); err != nil {
+				return err
+			}
+			// The prefix is filled in once the vault is decrypted
+			args = []string{"", args[0]}
+		} else if err := validateGetCommandArgs(args); err != nil {
 		return err
 		}
 
@@ -94,16 +150,48 @@ Examples:
 				}
 			}()
 
+			if getAddress != "" {
+				ref, err := resolveAddress(v, getAddress)
+				if err != nil {
+					return err
+				}
+				prefix, getAccount, getIndex = ref.Prefix, ref.Account, ref.Index
+			}
+
 			wallet, exists := v[prefix]
 			if !exists {
 				return errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
 			}
 
+			if isBatchGet(field) {
+				return printGetBatch(prefix, wallet, strings.Split(field, ","))
+			}
+
+			if !getJson && (field == "secret" || field == "mnemonic" || field == "seed" || field == "privatekey") {
+				if wallet.Archived() {
+					return archivedWalletError(prefix, wallet)
+				}
+				if err := openWalletEnvelope("get", prefix, &wallet); err != nil {
+					return err
+				}
+				v[prefix] = wallet
+			}
+
 			// --- Logic for the --json flag ---
 			if getJson {
 				audit.Logger.Info("Wallet data accessed", slog.String("command", "get"), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.Bool("json", true))
 				var dataToMarshal interface{}
 				if programmaticMode {
+					// Raw wallet JSON includes the secrets, so it counts against the limits
+					if err := refuseSecretEcho("get"); err != nil {
+						return err
+					}
+					if err := checkWalletNotFrozen("get", prefix, wallet); err != nil {
+						return err
+					}
+					if err := checkWalletSecretRateLimit("get", prefix, wallet); err != nil {
+						return err
+					}
 					dataToMarshal = wallet
 				} else {
 					dataToMarshal = wallet.Sanitize()
@@ -119,37 +207,78 @@ Examples:
 			// --- Logic for getting individual fields ---
 			var result string
 			isSecret := false
-			if field == "mnemonic" {
+			if field == "secret" {
+				audit.Logger.Warn("Secret data accessed", slog.String("command", "get"), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.String("field", "secret"))
+				if wallet.Secret == nil || wallet.Secret.String() == "" {
+					return errors.NewWalletInvalidError(prefix, "wallet is not a generic secret entry")
+				}
+				if err := checkWalletNotFrozen("get", prefix, wallet); err != nil {
+					return err
+				}
+				if err := checkSecretRateLimit(prefix); err != nil {
+					return err
+				}
+				result = wallet.Secret.String()
+				isSecret = true
+			} else if field == "mnemonic" {
 				audit.Logger.Warn("Secret data accessed", slog.String("command", "get"), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.String("field", "mnemonic"))
 				if wallet.Mnemonic == nil || wallet.Mnemonic.String() == "" {
+					if wallet.Source == vault.SourceSeed {
+						return errors.NewWalletInvalidError(prefix, "wallet was imported from a BIP-32 seed and has no mnemonic phrase; back up its 'seed' instead")
+					}
 					return errors.NewWalletInvalidError(prefix, "wallet does not have a mnemonic phrase")
 				}
+				if err := checkWalletNotFrozen("get", prefix, wallet); err != nil {
+					return err
+				}
+				if err := checkSecretRateLimit(prefix); err != nil {
+					return err
+				}
 				result = wallet.Mnemonic.String()
 				isSecret = true
-			} else {
-				var addressData *vault.Address
-				for i := range wallet.Addresses {
-					if wallet.Addresses[i].Index == getIndex {
-						addressData = &wallet.Addresses[i]
-						break
-					}
+			} else if field == "seed" {
+				audit.Logger.Warn("Secret data accessed", slog.String("command", "get"), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.String("field", "seed"))
+				if wallet.Seed == nil || wallet.Seed.String() == "" {
+					return errors.NewWalletInvalidError(prefix, "wallet was not imported from a BIP-32 seed")
+				}
+				if err := checkWalletNotFrozen("get", prefix, wallet); err != nil {
+					return err
 				}
+				if err := checkSecretRateLimit(prefix); err != nil {
+					return err
+				}
+				result = wallet.Seed.String()
+				isSecret = true
+			} else {
+				addressData := wallet.AddressAt(getAccount, getIndex)
 
 				if addressData == nil {
+					if getAccount != 0 {
+						return errors.NewAddressNotFoundError(prefix, getIndex).WithDetails(fmt.Sprintf("looked in account %d", getAccount))
+					}
 					return errors.NewAddressNotFoundError(prefix, getIndex)
 				}
 
 				switch field {
 				case "address":
-					audit.Logger.Info("Public data accessed", slog.String("command", "get"), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.Int("index", getIndex), slog.String("field", "address"))
+					audit.Logger.Info("Public data accessed", slog.String("command", "get"), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.Int("account", getAccount), slog.Int("index", getIndex), slog.String("field", "address"))
 					result = addressData.Address
 				case "privatekey":
-					audit.Logger.Warn("Secret data accessed", slog.String("command", "get"), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.Int("index", getIndex), slog.String("field", "privateKey"))
+					audit.Logger.Warn("Secret data accessed", slog.String("command", "get"), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.Int("account", getAccount), slog.Int("index", getIndex), slog.String("field", "privateKey"))
 					if addressData.PrivateKey == nil {
-						return errors.NewAddressNotFoundError(prefix, getIndex).WithDetails("address does not have a private key")
+						return missingKeyError(prefix, getIndex, wallet)
+					}
+					if err := checkWalletNotFrozen("get", prefix, wallet); err != nil {
+						return err
+					}
+					if err := checkWalletSecretRateLimit("get", prefix, wallet); err != nil {
+						return err
 					}
 					result = addressData.PrivateKey.String()
 					isSecret = true
+				case "path":
+					audit.Logger.Info("Public data accessed", slog.String("command", "get"), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.Int("account", getAccount), slog.Int("index", getIndex), slog.String("field", "path"))
+					result = addressData.Path
 				case "notes":
 					audit.Logger.Info("Notes accessed", slog.String("command", "get"), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.String("field", "notes"))
 					if wallet.Notes != "" {
@@ -158,46 +287,212 @@ Examples:
 						return errors.NewWalletInvalidError(prefix, "wallet does not have notes")
 					}
 				default:
-					return errors.NewInvalidInputError(args[1], fmt.Sprintf("unknown field '%s'. Available fields: address, privatekey, mnemonic, notes", args[1]))
+					return errors.NewInvalidInputError(args[1], fmt.Sprintf("unknown field '%s'. Available fields: address, privatekey, mnemonic, secret, notes, path", args[1]))
 				}
 			}
 
-			// --- Main logic for choosing the output mode ---
-			if programmaticMode {
-				fmt.Print(result)
-			} else {
-				if isSecret {
-					// Register clipboard for cleanup with shutdown manager
-					security.RegisterClipboardGlobal(fmt.Sprintf("clipboard for %s.%s", prefix, field))
-
-					// Copy to clipboard with configurable timeout
-					if err := security.GetClipboard().WriteAllWithCustomTimeout(result, getClipboardTimeout); err != nil {
-						return errors.NewClipboardError(err)
-					}
-					fmt.Println(colors.SafeColor(
-						fmt.Sprintf("Secret copied to clipboard. Independent process will clear it in %d seconds.", getClipboardTimeout),
-						colors.Success,
-					))
-				} else {
-					// For non-secret data, we can also copy to clipboard if --copy flag is specified
-					if getCopy {
-						if err := security.CopyToClipboard(result); err != nil {
-							return errors.NewClipboardError(err)
-						}
-						fmt.Println(colors.SafeColor(
-							fmt.Sprintf("Data copied to clipboard: %s", result),
-							colors.Success,
-						))
-					} else {
-						fmt.Println(result)
-					}
-				}
+			if isSecret {
+				recordWalletAccess(activeVault, v, prefix, field)
 			}
-			return nil
+			return emitGetResult("get", prefix, field, result, isSecret)
 		})
 	},
 }
 
+// batchGetFields are the fields get can print for many addresses at once. Secrets
+// are left out: each one is retrieved, limited and audited on its own.
+var batchGetFields = []string{"address", "path"}
+
+// isBatchGet reports whether get prints a table of addresses rather than one value
+func isBatchGet(field string) bool {
+	return getIndices != "" || strings.Contains(field, ",")
+}
+
+// printGetBatch prints fields of the wallet's addresses at --indices (or --index)
+// within --account, as a table or, with --json, a JSON array
+func printGetBatch(prefix string, wallet vault.Wallet, fields []string) error {
+	indices := []int{getIndex}
+	if getIndices != "" {
+		var err error
+		if indices, err = parseIndexList(getIndices, maxIndexValue); err != nil {
+			return err
+		}
+	}
+
+	rows := make([]addressRow, 0, len(indices))
+	for _, index := range indices {
+		addr := wallet.AddressAt(getAccount, index)
+		if addr == nil {
+			err := errors.NewAddressNotFoundError(prefix, index)
+			if getAccount != 0 {
+				err = err.WithDetails(fmt.Sprintf("looked in account %d", getAccount))
+			}
+			return err
+		}
+		rows = append(rows, newAddressRow(prefix, *addr))
+	}
+
+	audit.Logger.Info("Public data accessed",
+		slog.String("command", "get"),
+		slog.String("vault", config.Cfg.ActiveVault),
+		slog.String("prefix", prefix),
+		slog.Int("account", getAccount),
+		slog.String("indices", getIndices),
+		slog.Int("count", len(rows)),
+		slog.String("field", strings.Join(fields, ",")))
+
+	if getJson {
+		return printAddressJSON(os.Stdout, rows, append([]string{"prefix", "account", "index"}, fields...))
+	}
+	return printAddressTable(os.Stdout, rows, append([]string{"index"}, fields...))
+}
+
+// resolveAddress finds the wallet, account and index holding address in the
+// decrypted vault
+func resolveAddress(v vault.Vault, address string) (vault.AddressRef, error) {
+	refs := vault.IndexAddresses(v).Lookup(address)
+	switch len(refs) {
+	case 0:
+		return vault.AddressRef{}, errors.Newf(errors.ErrCodeWalletNotFound, "no wallet in vault '%s' has address '%s'", config.Cfg.ActiveVault, address).
+			WithContext("vault_name", config.Cfg.ActiveVault)
+	case 1:
+		audit.Logger.Info("Wallet resolved by address", slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", refs[0].Prefix), slog.Int("account", refs[0].Account), slog.Int("index", refs[0].Index))
+		return refs[0], nil
+	default:
+		prefixes := make([]string, len(refs))
+		for i, ref := range refs {
+			prefixes[i] = ref.Prefix
+		}
+		return vault.AddressRef{}, errors.NewInvalidInputError(address, fmt.Sprintf("the address is held by several wallets (%s); use the prefix", strings.Join(prefixes, ", ")))
+	}
+}
+
+// emitGetResult outputs a retrieved value in the mode selected by the get flags: a
+// descriptor or FIFO, Ansible JSON, stdout in programmatic mode, or the clipboard
+func emitGetResult(command, prefix, field, result string, isSecret bool) error {
+	if getOutFD >= 0 || getOutFIFO != "" {
+		return deliverGetResult(prefix, field, result)
+	}
+	if isSecret && (getAnsible || programmaticMode || config.Cfg.Strict) {
+		// These modes print the secret itself rather than using the clipboard
+		if err := refuseSecretEcho(command); err != nil {
+			return err
+		}
+	}
+	if getAnsible {
+		return printAnsibleResult(prefix, field, result)
+	}
+	if programmaticMode {
+		if isSecret {
+			return printSecret(command, result, false)
+		}
+		fmt.Print(result)
+	} else {
+		if isSecret && config.Cfg.Strict {
+			// Strict profile: never use the clipboard, reveal on the terminal after
+			// confirmation, on a screen that locks (see revealscreen.go)
+			if err := confirmNoScreenCapture(command, getAllowScreenCapture); err != nil {
+				return err
+			}
+			if !askForConfirmation(colors.SafeColor(fmt.Sprintf("Reveal %s of '%s' on screen?", field, prefix), colors.Warning)) {
+				fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+				return nil
+			}
+			return revealOnScreen(command, prefix, field, result)
+		} else if isSecret {
+			if err := confirmNoScreenCapture(command, getAllowScreenCapture); err != nil {
+				return err
+			}
+
+			// Register clipboard for cleanup with shutdown manager
+			security.RegisterClipboardGlobal(fmt.Sprintf("clipboard for %s.%s", prefix, field))
+
+			// Copy to clipboard with configurable timeout
+			if err := security.GetClipboard().WriteAllWithCustomTimeout(result, getClipboardTimeout); err != nil {
+				return errors.NewClipboardError(err)
+			}
+			fmt.Println(colors.SafeColor(
+				fmt.Sprintf("Secret copied to clipboard. Independent process will clear it in %d seconds.", getClipboardTimeout),
+				colors.Success,
+			))
+		} else {
+			// For non-secret data, we can also copy to clipboard if --copy flag is specified
+			if getCopy && config.Cfg.Strict {
+				return errors.NewInvalidInputError("--copy", "clipboard is disabled by the strict profile")
+			}
+			if getCopy {
+				if err := security.CopyToClipboard(result); err != nil {
+					return errors.NewClipboardError(err)
+				}
+				fmt.Println(colors.SafeColor(
+					fmt.Sprintf("Data copied to clipboard: %s", result),
+					colors.Success,
+				))
+			} else {
+				fmt.Println(result)
+			}
+		}
+	}
+	return nil
+}
+
+// deliverGetResult writes the result to the descriptor or FIFO requested with --out-fd / --out-fifo,
+// keeping it off stdout, the terminal and the clipboard.
+func deliverGetResult(prefix, field, result string) error {
+	if getOutFD >= 0 {
+		if err := security.WriteSecretToFD(getOutFD, result); err != nil {
+			return errors.New(errors.ErrCodeSystem, "failed to deliver data to file descriptor").WithContext("error", err.Error())
+		}
+		audit.Logger.Info("Data delivered to file descriptor", slog.String("command", "get"), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.String("field", field), slog.Int("fd", getOutFD))
+	} else {
+		if !programmaticMode {
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Waiting up to %s for a reader on %s...", security.FIFOOpenTimeout, getOutFIFO), colors.Info))
+		}
+		if err := security.WriteSecretToFIFO(getOutFIFO, result); err != nil {
+			return errors.New(errors.ErrCodeSystem, "failed to deliver data through FIFO").WithContext("error", err.Error())
+		}
+		audit.Logger.Info("Data delivered through FIFO", slog.String("command", "get"), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.String("field", field), slog.String("fifo", getOutFIFO))
+	}
+	if !programmaticMode {
+		fmt.Println(colors.SafeColor("Data delivered.", colors.Success))
+	}
+	return nil
+}
+
+// printAnsibleResult writes the result in the format read by the Ansible lookup plugin
+func printAnsibleResult(prefix, field, result string) error {
+	audit.Logger.Info("Data retrieved by automation",
+		slog.String("command", "get"),
+		slog.String("consumer", "ansible"),
+		slog.Bool("programmatic", true),
+		slog.String("vault", config.Cfg.ActiveVault),
+		slog.String("prefix", prefix),
+		slog.String("field", field),
+		slog.Int("index", getIndex),
+	)
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"vault":  config.Cfg.ActiveVault,
+		"prefix": prefix,
+		"field":  field,
+		"index":  getIndex,
+		"value":  result,
+	})
+	if err != nil {
+		return errors.New(errors.ErrCodeInternal, "failed to generate JSON").WithContext("marshal_error", err.Error())
+	}
+	fmt.Println(string(jsonData))
+	return nil
+}
+
+// checkSecretRateLimit enforces the configured secret retrieval limits for a wallet of the active vault
+func checkSecretRateLimit(prefix string) error {
+	global, wallet := config.SecretRateLimits()
+	return ratelimit.Allow(config.Cfg.ActiveVault, prefix, ratelimit.Limits{
+		Global: global,
+		Wallet: wallet,
+	})
+}
+
 // validateGetCommandInputs validates input parameters for the get command
 func validateGetCommandInputs() error {
 	// Validate clipboard timeout range with overflow protection
@@ -222,6 +517,22 @@ func validateGetCommandInputs() error {
 		)
 	}
 
+	if getOutFD >= 0 && getOutFIFO != "" {
+		return errors.NewInvalidInputError("--out-fd/--out-fifo", "only one of --out-fd and --out-fifo can be used")
+	}
+	if getOutFD >= 0 && getOutFD < 3 {
+		return errors.NewInvalidInputError(
+			fmt.Sprintf("%d", getOutFD),
+			"--out-fd must be 3 or higher; use plain output for stdout",
+		)
+	}
+	if (getOutFD >= 0 || getOutFIFO != "") && (getJson || getCopy || getAnsible) {
+		return errors.NewInvalidInputError("--out-fd/--out-fifo", "cannot be combined with --json, --copy or --ansible")
+	}
+	if getAnsible && (getJson || getCopy) {
+		return errors.NewInvalidInputError("--ansible", "cannot be combined with --json or --copy")
+	}
+
 	// Validate address index (must be non-negative and within reasonable range)
 	if getIndex < 0 {
 		return errors.NewInvalidInputError(
@@ -235,6 +546,12 @@ func validateGetCommandInputs() error {
 			fmt.Sprintf("address index must be at most %d", maxIndexValue),
 		)
 	}
+	if getAccount < 0 || getAccount > maxIndexValue {
+		return errors.NewInvalidInputError(
+			fmt.Sprintf("%d", getAccount),
+			fmt.Sprintf("account must be between 0 and %d", maxIndexValue),
+		)
+	}
 
 	return nil
 }
@@ -273,6 +590,36 @@ func validateGetCommandArgs(args []string) error {
 		}
 	}
 
+	return validateGetFields(field)
+}
+
+// validateGetFields validates the FIELD argument of get: one field, or several
+// public fields separated by commas
+func validateGetFields(field string) error {
+	fields := strings.Split(strings.ToLower(field), ",")
+	if len(fields) == 1 && getIndices == "" {
+		return validateGetField(field)
+	}
+	for _, f := range fields {
+		public := false
+		for _, allowed := range batchGetFields {
+			if f == allowed {
+				public = true
+				break
+			}
+		}
+		if !public {
+			return errors.NewInvalidInputError(field, fmt.Sprintf("several addresses can only be read for the fields %s", strings.Join(batchGetFields, ", ")))
+		}
+	}
+	if getOutFD >= 0 || getOutFIFO != "" || getAnsible || getCopy {
+		return errors.NewInvalidInputError(field, "several fields or --indices cannot be combined with --out-fd, --out-fifo, --ansible or --copy")
+	}
+	return nil
+}
+
+// validateGetField validates the FIELD argument of get
+func validateGetField(field string) error {
 	// Validate field length and content
 	if len(field) == 0 {
 		return errors.NewInvalidInputError(field, "field cannot be empty")
@@ -295,7 +642,7 @@ func validateGetCommandArgs(args []string) error {
 	}
 
 	// Validate field is one of allowed values
-	allowedFields := []string{"address", "privatekey", "mnemonic", "notes"}
+	allowedFields := []string{"address", "privatekey", "mnemonic", "seed", "secret", "notes", "path"}
 	fieldLower := strings.ToLower(field)
 	validField := false
 	for _, allowed := range allowedFields {
@@ -316,7 +663,14 @@ func validateGetCommandArgs(args []string) error {
 
 func init() {
 	getCmd.Flags().IntVar(&getIndex, "index", 0, "Index of the address within an HD wallet.")
+	getCmd.Flags().IntVar(&getAccount, "account", 0, "BIP-44 account of the address within an HD wallet.")
 	getCmd.Flags().BoolVar(&getJson, "json", false, "Output all wallet data in JSON format.")
 	getCmd.Flags().BoolVarP(&getCopy, "copy", "c", false, "Copy data to clipboard (applies to non-secret data).")
+	getCmd.Flags().BoolVar(&getAllowScreenCapture, "allow-screen-capture", false, "Reveal secrets even if screen sharing or recording software is running.")
+	getCmd.Flags().BoolVar(&getAnsible, "ansible", false, "Print the value as JSON for the Ansible lookup plugin (non-interactive).")
+	getCmd.Flags().IntVar(&getOutFD, "out-fd", -1, "Write the value to this inherited file descriptor (3 or higher) instead of stdout or the clipboard.")
+	getCmd.Flags().StringVar(&getOutFIFO, "out-fifo", "", "Create a 0600 FIFO at this path and write the value to its first reader.")
+	getCmd.Flags().StringVar(&getIndices, "indices", "", "Addresses to print, e.g. 0-9 or 0,2,5-7 (public fields only).")
+	getCmd.Flags().StringVar(&getAddress, "address", "", "Find the wallet, account and index by this address instead of a prefix.")
 	getCmd.Flags().IntVar(&getClipboardTimeout, "clipboard-timeout", defaultClipboardTimeout, fmt.Sprintf("Seconds after which clipboard will be cleared (range: %d-%d, default: %d).", minClipboardTimeout, maxClipboardTimeout, defaultClipboardTimeout))
 }
@@ -0,0 +1,265 @@
+// File: cmd/history.go
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/display"
+	"vault.module/internal/errors"
+	"vault.module/internal/hooks"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var historyLimit int
+var historyWallet string
+var historyJson bool
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Shows the mutation history recorded in the vault.",
+	Long: `Shows the mutation history recorded in the vault.
+
+Every save appends an entry for each wallet added, removed or changed, with
+the time, the command, the operator and the vault.module version. The
+history is kept in the encrypted vault header, so it travels with the file;
+only the most recent entries are kept.
+
+With an access log (see 'history access-log'), the vault also keeps the last
+accesses to each wallet's secrets, shown by 'history wallet'.
+
+Times are shown as "time_format" in config.json selects: "relative" ("3 days
+ago", the default), "local" (date and time in the local time zone) or "utc".
+--json always prints RFC 3339.
+`,
+}
+
+var historyShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Displays the history of the active vault.",
+	Long: `Displays the history of the active vault, oldest entry first.
+
+Examples:
+  vault.module history show
+  vault.module history show --wallet A1 --limit 20
+  vault.module history show --json
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if historyLimit < 0 {
+				return errors.NewInvalidInputError(fmt.Sprintf("%d", historyLimit), "--limit cannot be negative")
+			}
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+
+			history, err := vault.LoadHistory(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+
+			entries := make([]vault.HistoryEntry, 0, len(history))
+			for _, e := range history {
+				if historyWallet == "" || e.Wallet == historyWallet {
+					entries = append(entries, e)
+				}
+			}
+			if historyLimit > 0 && len(entries) > historyLimit {
+				entries = entries[len(entries)-historyLimit:]
+			}
+
+			if historyJson {
+				jsonData, err := json.MarshalIndent(entries, "", "  ")
+				if err != nil {
+					return errors.New(errors.ErrCodeInternal, "failed to generate JSON").WithContext("marshal_error", err.Error())
+				}
+				fmt.Println(string(jsonData))
+				return nil
+			}
+
+			if len(entries) == 0 {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("No history recorded in vault '%s'.", config.Cfg.ActiveVault), colors.Info))
+				return nil
+			}
+			fmt.Println(colors.SafeColor(fmt.Sprintf("History of '%s':", config.Cfg.ActiveVault), colors.Bold))
+			for _, e := range entries {
+				line := fmt.Sprintf("%s  %-8s", colors.SafeColor(fmt.Sprintf("%-16s", display.TimeString(e.Time)), colors.Dim), e.Op)
+				if e.Wallet != "" {
+					line += " " + colors.SafeColor(e.Wallet, colors.White)
+				}
+				if e.Command != "" {
+					line += fmt.Sprintf(" via '%s'", e.Command)
+				}
+				if e.Operator != "" {
+					line += " by " + e.Operator
+				}
+				line += colors.SafeColor(fmt.Sprintf(" (vault.module %s)", e.Tool), colors.Dim)
+				fmt.Println(line)
+			}
+			return nil
+		})
+	},
+}
+
+var historyWalletCmd = &cobra.Command{
+	Use:   "wallet <PREFIX>",
+	Short: "Displays the history and recent accesses of a wallet.",
+	Long: `Displays the history and recent accesses of a wallet.
+
+Accesses are the retrievals of the wallet's secrets by get, secret get, exec
+and provision. They are recorded inside the encrypted vault when the vault
+keeps an access log (see 'history access-log'), so the vault carries its own
+recent usage even if audit.log is wiped.
+
+Examples:
+  vault.module history wallet A1
+  vault.module history wallet A1 --json
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			prefix := args[0]
+
+			history, err := vault.LoadHistory(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			var entries []vault.HistoryEntry
+			for _, e := range history {
+				if e.Wallet == prefix {
+					entries = append(entries, e)
+				}
+			}
+			accesses := vault.WalletAccess(activeVault.KeyFile, prefix)
+
+			if historyJson {
+				jsonData, err := json.MarshalIndent(struct {
+					History  []vault.HistoryEntry `json:"history"`
+					Accesses []vault.AccessEntry  `json:"accesses"`
+				}{entries, accesses}, "", "  ")
+				if err != nil {
+					return errors.New(errors.ErrCodeInternal, "failed to generate JSON").WithContext("marshal_error", err.Error())
+				}
+				fmt.Println(string(jsonData))
+				return nil
+			}
+
+			fmt.Println(colors.SafeColor(fmt.Sprintf("History of '%s':", prefix), colors.Bold))
+			if len(entries) == 0 {
+				fmt.Println(colors.SafeColor("  No changes recorded.", colors.Info))
+			}
+			for _, e := range entries {
+				line := fmt.Sprintf("  %s  %-8s", colors.SafeColor(fmt.Sprintf("%-16s", display.TimeString(e.Time)), colors.Dim), e.Op)
+				if e.Command != "" {
+					line += fmt.Sprintf(" via '%s'", e.Command)
+				}
+				if e.Operator != "" {
+					line += " by " + e.Operator
+				}
+				fmt.Println(line)
+			}
+
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Recent accesses of '%s':", prefix), colors.Bold))
+			if len(accesses) == 0 {
+				if activeVault.AccessLog <= 0 {
+					fmt.Println(colors.SafeColor("  The vault keeps no access log; turn it on with 'history access-log <N>'.", colors.Info))
+				} else {
+					fmt.Println(colors.SafeColor("  No accesses recorded.", colors.Info))
+				}
+			}
+			for _, a := range accesses {
+				line := fmt.Sprintf("  %s  %s", colors.SafeColor(fmt.Sprintf("%-16s", display.TimeString(a.Time)), colors.Dim), a.Field)
+				if a.Command != "" {
+					line += fmt.Sprintf(" via '%s'", a.Command)
+				}
+				if a.Operator != "" {
+					line += " by " + a.Operator
+				}
+				fmt.Println(line)
+			}
+			return nil
+		})
+	},
+}
+
+var historyAccessLogCmd = &cobra.Command{
+	Use:   "access-log <N>",
+	Short: "Sets how many accesses per wallet the active vault keeps.",
+	Long: `Sets how many accesses per wallet the active vault keeps.
+
+Every retrieval of a wallet's secrets then re-encrypts the vault with the
+access appended, keeping the last N per wallet; 0 turns the log off. Saving
+needs only the vault's recipients, and a vault with a passphrase second factor
+is encrypted again with the passphrase that opened it. The setting is
+"access_log" of the vault in config.json.
+
+Examples:
+  vault.module history access-log 20
+  vault.module history access-log 0
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 0 {
+				return errors.NewInvalidInputError(args[0], "the number of accesses must be a non-negative integer")
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			activeVault.AccessLog = n
+			config.Cfg.Vaults[config.Cfg.ActiveVault] = activeVault
+			if err := config.SaveConfig(); err != nil {
+				return errors.NewConfigSaveError("config.json", err)
+			}
+			audit.Logger.Info("Vault access log set", slog.String("vault", config.Cfg.ActiveVault), slog.Int("access_log", n))
+			if n == 0 {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Vault '%s' no longer records accesses; the ones recorded stay until their wallets are removed.", config.Cfg.ActiveVault), colors.Success))
+			} else {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Vault '%s' keeps the last %d accesses of each wallet.", config.Cfg.ActiveVault, n), colors.Success))
+			}
+			return nil
+		})
+	},
+}
+
+// recordWalletAccess records an access to a wallet's secrets in the vault when
+// it keeps an access log. A failure is reported without failing the access:
+// audit.log has the event either way.
+func recordWalletAccess(details config.VaultDetails, v vault.Vault, prefix, field string) {
+	if err := vault.RecordAccess(details, v, prefix, field); err != nil {
+		audit.Logger.Error("Failed to record wallet access in vault", slog.String("prefix", prefix), slog.String("error", err.Error()))
+		fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("Warning: the access was not recorded in the vault: %v", err), colors.Warning))
+	}
+	for _, err := range hooks.Run(hooks.Event{Hook: hooks.PostGetSecret, Vault: config.NameForKeyFile(details.KeyFile), Prefix: prefix, Field: field}) {
+		fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("Warning: %v", err), colors.Warning))
+	}
+}
+
+func init() {
+	historyShowCmd.Flags().IntVar(&historyLimit, "limit", 0, "Show only the most recent N entries (0 shows all)")
+	historyShowCmd.Flags().StringVar(&historyWallet, "wallet", "", "Show only the entries for this wallet")
+	historyShowCmd.Flags().BoolVar(&historyJson, "json", false, "Output the history in JSON format.")
+	historyWalletCmd.Flags().BoolVar(&historyJson, "json", false, "Output the history and accesses in JSON format.")
+}
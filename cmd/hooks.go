@@ -0,0 +1,143 @@
+// File: cmd/hooks.go
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/hooks"
+
+	"github.com/spf13/cobra"
+)
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Lists and tests the scripts run at hook points.",
+	Long: `Lists and tests the scripts run at hook points.
+
+Hooks attach your own scripts to operations of the tool, to notify a
+ticketing system when a key is accessed, back up a vault before it changes or
+register imported wallets elsewhere. The hook points are:
+
+  pre-save         before a vault is written; a script that fails (non-zero
+                   exit status or timeout) aborts the save
+  post-import      after wallets were imported and saved
+  post-get-secret  after a mnemonic or private key was retrieved
+
+A script gets the event as JSON on stdin: the hook point, command, vault,
+wallet prefix or prefixes, field, user, operator, host and time. It never
+contains a secret. The script runs without a shell, with VAULT_MODULE_HOOK set
+to the hook point; hooks do not run while that variable is set, so a script
+can call vault.module itself. Its output is discarded; a post-import or
+post-get-secret script that fails is reported as a warning.
+
+Hooks are set in config.json:
+
+  "hooks": [
+    {"point": "post-get-secret", "command": "/usr/local/bin/ticket", "args": ["--queue", "keys"], "timeout": 10},
+    {"point": "pre-save", "command": "/usr/local/bin/backup-vault", "vaults": ["treasury"]}
+  ]
+
+"timeout" is in seconds (default 30); "vaults" limits a hook to some vaults.
+
+Examples:
+  vault.module hooks list
+  vault.module hooks test post-get-secret
+`,
+}
+
+var hooksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the configured hook scripts.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if len(config.Cfg.Hooks) == 0 {
+				fmt.Println(colors.SafeColor("No hooks configured. See 'vault.module hooks --help'.", colors.Info))
+				return nil
+			}
+			for _, point := range hooks.Points {
+				var lines []string
+				for _, hook := range config.Cfg.Hooks {
+					if hook.Point != point {
+						continue
+					}
+					line := strings.TrimSpace(hook.Command + " " + strings.Join(hook.Args, " "))
+					if len(hook.Vaults) > 0 {
+						line += colors.SafeColor(fmt.Sprintf(" [vaults: %s]", strings.Join(hook.Vaults, ", ")), colors.Cyan)
+					}
+					if hook.Timeout > 0 {
+						line += fmt.Sprintf(" (timeout %ds)", hook.Timeout)
+					}
+					lines = append(lines, line)
+				}
+				if len(lines) == 0 {
+					continue
+				}
+				fmt.Println(colors.SafeColor(point+":", colors.Bold))
+				for _, line := range lines {
+					fmt.Println("  " + line)
+				}
+			}
+			for _, hook := range config.Cfg.Hooks {
+				if !isHookPoint(hook.Point) {
+					fmt.Println(colors.SafeColor(fmt.Sprintf("Warning: unknown hook point '%s' (%s) never runs", hook.Point, hook.Command), colors.Warning))
+				}
+			}
+			return nil
+		})
+	},
+}
+
+var hooksTestCmd = &cobra.Command{
+	Use:   "test <POINT>",
+	Short: "Runs the scripts of a hook point with a sample event.",
+	Long: `Runs the scripts of a hook point with a sample event for the active
+vault, as the operation would, and reports those that fail. The event's
+prefix is "example" and its details say it is a test.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			point := args[0]
+			if !isHookPoint(point) {
+				return errors.NewInvalidInputError(point, "hook point must be one of: "+strings.Join(hooks.Points, ", "))
+			}
+			vaultName := config.Cfg.ActiveVault
+			if !hooks.Configured(point, vaultName) {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("No %s hooks for vault '%s'.", point, vaultName), colors.Info))
+				return nil
+			}
+
+			ev := hooks.Event{Hook: point, Vault: vaultName, Details: "test event from 'hooks test'"}
+			switch point {
+			case hooks.PostGetSecret:
+				ev.Prefix = "example"
+				ev.Field = "mnemonic"
+			default:
+				ev.Prefixes = []string{"example"}
+			}
+			errs := hooks.Run(ev)
+			for _, err := range errs {
+				fmt.Println(colors.SafeColor("Failed: "+err.Error(), colors.Error))
+			}
+			if len(errs) > 0 {
+				return errors.New(errors.ErrCodeSystem, fmt.Sprintf("%d %s hook(s) failed", len(errs), point))
+			}
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Every %s hook ran successfully.", point), colors.Success))
+			return nil
+		})
+	},
+}
+
+// isHookPoint reports whether point is a known hook point
+func isHookPoint(point string) bool {
+	for _, p := range hooks.Points {
+		if p == point {
+			return true
+		}
+	}
+	return false
+}
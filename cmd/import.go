@@ -3,23 +3,29 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"vault.module/internal/actions"
+	"vault.module/internal/audit"
 	"vault.module/internal/colors"
 	"vault.module/internal/config"
 	"vault.module/internal/constants"
 	"vault.module/internal/errors"
+	"vault.module/internal/keys"
 	"vault.module/internal/security"
 	"vault.module/internal/vault"
+	"vault.module/internal/webhook"
 
 	"github.com/spf13/cobra"
 )
 
 var importFormat string
 var importConflict string
+var importMergeHook string
+var importVerifySignature bool
 
 const (
 	// File validation constants
@@ -35,13 +41,48 @@ var importCmd = &cobra.Command{
 
 Supported formats:
   - JSON: Standard wallet export format
-  - Key-Value: Simple key=value format
-
-The command will prompt for conflict resolution if wallets with same names exist.
+  - Key-Value: Simple key=value format. A value is a mnemonic, a private key,
+    or hex BIP-39 entropy or a BIP-32 seed marked "entropy:" or "seed:"
+    (A1=entropy:0c1e24e5..., B2=seed:5eb00bbd...)
+
+A key-value private key is detected and normalized before it is validated.
+EVM vaults accept hex, 0x-prefixed hex, WIF and bracketed byte arrays
+([12, 255, ...]); Cosmos vaults accept hex, 0x-prefixed hex, base64 and
+bracketed byte arrays. The report lists the format detected for each entry,
+or why it was skipped.
+
+Wallets whose prefix exists in the vault are handled by --on-conflict:
+
+  skip       keep the existing wallet (default)
+  overwrite  replace it with the imported one
+  fail       abort the import
+  merge      merge wallets of the same seed: the existing wallet gains the
+             imported addresses, notes and tags it lacks
+
+Merging needs the same seed fingerprint (the BIP-32 master key fingerprint)
+and derivation path, or for single-key wallets the same key. Other conflicts
+are skipped, or passed to the --merge-hook script: it reads the conflict as
+JSON on stdin (both wallets without their secrets, the reason and the seed
+fingerprints) and prints "keep", "overwrite", "fail" or "rename <NEW_PREFIX>".
+
+The file is scanned for secrets that are not wallet keys, such as AWS keys, API
+tokens and SSH private keys left in a messy export. They are never imported;
+the findings are listed by line and the import continues only if confirmed.
+
+Imported keys and mnemonics are checked for publicly known test vectors,
+brainwallet keys and low-entropy patterns; storing a flagged wallet must be
+confirmed explicitly. Wallets whose keys or addresses are in the
+known-compromised database (see 'vault.module leaks') are reported.
+
+--verify-signature accepts the file only if its attestation (<INPUT_FILE>.sig,
+written by export) signs it and names a vault trusted here (see 'vaults
+trust'), so the file is known to come unmodified from that vault.
 
 Examples:
   vault.module import wallets.json
+  vault.module import wallets.json --verify-signature
   vault.module import backup.txt --format keyvalue
+  vault.module import other.json --on-conflict merge --merge-hook ./resolve.sh
 `,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -87,6 +128,27 @@ Examples:
 				colors.Info,
 			))
 
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				return errors.NewFileSystemError("read", filePath, err)
+			}
+
+			// Register file content for secure cleanup if it contains sensitive data
+			if len(content) > 0 {
+				security.RegisterTempFileGlobal(filePath, fmt.Sprintf("import file: %s", filePath))
+			}
+
+			if importVerifySignature {
+				if err := verifyImportSignature(importFormat, filePath, content); err != nil {
+					return err
+				}
+			}
+
+			if !confirmExtraneousSecrets(filePath, content) {
+				fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+				return nil
+			}
+
 			v, err := vault.LoadVault(activeVault)
 			if err != nil {
 				return errors.NewVaultLoadError(activeVault.KeyFile, err)
@@ -99,25 +161,43 @@ Examples:
 				}
 			}()
 
-			content, err := os.ReadFile(filePath)
-			if err != nil {
-				return errors.NewFileSystemError("read", filePath, err)
-			}
-
-			// Register file content for secure cleanup if it contains sensitive data
-			if len(content) > 0 {
-				security.RegisterTempFileGlobal(filePath, fmt.Sprintf("import file: %s", filePath))
+			existing := make(map[string]bool, len(v))
+			for prefix := range v {
+				existing[prefix] = true
 			}
 
 			// Pass the vault type to the action to use the correct key manager.
-			updatedVault, report, err := actions.ImportWallets(v, content, importFormat, importConflict, activeVault.Type)
+			var hook actions.ConflictHook
+			if importMergeHook != "" {
+				hook = actions.ScriptConflictHook(importMergeHook)
+			}
+			policy, err := activePrefixPolicy()
+			if err != nil {
+				return err
+			}
+			updatedVault, imported, report, err := actions.ImportWallets(v, content, importFormat, importConflict, activeVault.Type, policy, hook)
 			if err != nil {
 				return err
 			}
+			for _, prefix := range imported {
+				warnKnownCompromised("import", prefix, updatedVault[prefix])
+				if !confirmWeakSecret("import", prefix, keys.AnalyzeWallet(updatedVault[prefix])) {
+					fmt.Println(colors.SafeColor("Cancelled. Nothing was imported.", colors.Info))
+					return nil
+				}
+			}
 
 			if err := vault.SaveVault(activeVault, updatedVault); err != nil {
 				return errors.NewVaultSaveError(activeVault.KeyFile, err)
 			}
+			added := 0
+			for prefix := range updatedVault {
+				if !existing[prefix] {
+					added++
+				}
+			}
+			notifyVaultMutation(webhook.EventWalletImported, "", fmt.Sprintf("%d new wallet(s) imported from %s", added, filepath.Base(filePath)))
+			runPostImportHooks(imported, fmt.Sprintf("%d new wallet(s) imported from %s", added, filepath.Base(filePath)))
 
 			fmt.Println(colors.SafeColor(report, colors.Success))
 			return nil
@@ -125,6 +205,31 @@ Examples:
 	},
 }
 
+// confirmExtraneousSecrets warns about credentials in the import file that are not
+// wallet keys and asks whether to continue. Only the kind and line of each finding
+// are shown, never the secret itself.
+func confirmExtraneousSecrets(filePath string, content []byte) bool {
+	findings := security.ScanForSecrets(content)
+	if len(findings) == 0 {
+		return true
+	}
+
+	kinds := make([]string, 0, len(findings))
+	fmt.Println(colors.SafeColor(fmt.Sprintf("WARNING: %s contains %d secret(s) that are not wallet keys:", filepath.Base(filePath), len(findings)), colors.Warning))
+	for _, f := range findings {
+		fmt.Printf("  line %d: %s\n", f.Line, f.Kind)
+		kinds = append(kinds, f.Kind)
+	}
+	fmt.Println(colors.SafeColor("They are not imported, but the file should be cleaned or securely deleted, and exposed credentials rotated.", colors.Warning))
+	audit.Logger.Warn("Import file contains extraneous secrets",
+		slog.String("command", "import"),
+		slog.String("file", filepath.Base(filePath)),
+		slog.Int("count", len(findings)),
+		slog.String("kinds", strings.Join(kinds, ", ")),
+	)
+	return askForConfirmation("Continue with the import?")
+}
+
 // validateImportCommandArgs validates command line arguments
 func validateImportCommandArgs(args []string) error {
 	if len(args) != 1 {
@@ -197,19 +302,11 @@ func validateImportCommandInputs() error {
 	}
 
 	// Validate conflict policy parameter
-	allowedPolicies := []string{constants.ConflictPolicySkip, constants.ConflictPolicyOverwrite, constants.ConflictPolicyFail}
-	validPolicy := false
-	for _, allowed := range allowedPolicies {
-		if strings.EqualFold(importConflict, allowed) {
-			validPolicy = true
-			break
-		}
+	if err := validateConflictPolicy(importConflict); err != nil {
+		return err
 	}
-	if !validPolicy {
-		return errors.NewInvalidInputError(
-			importConflict,
-			fmt.Sprintf("invalid conflict policy '%s'. Allowed policies: %s", importConflict, strings.Join(allowedPolicies, ", ")),
-		)
+	if importMergeHook != "" && !strings.EqualFold(importConflict, constants.ConflictPolicyMerge) {
+		return errors.NewInvalidInputError(importMergeHook, "--merge-hook is only used with --on-conflict merge")
 	}
 
 	return nil
@@ -254,5 +351,7 @@ func validateFileForImport(filePath string) error {
 
 func init() {
 	importCmd.Flags().StringVar(&importFormat, "format", constants.FormatJSON, "File format (json or key-value).")
-	importCmd.Flags().StringVar(&importConflict, "on-conflict", constants.ConflictPolicySkip, "Behavior on conflict (skip, overwrite, fail).")
+	importCmd.Flags().StringVar(&importConflict, "on-conflict", constants.ConflictPolicySkip, "Behavior on conflict (skip, overwrite, fail, merge).")
+	importCmd.Flags().StringVar(&importMergeHook, "merge-hook", "", "Script resolving the conflicts the merge policy cannot.")
+	importCmd.Flags().BoolVar(&importVerifySignature, "verify-signature", false, "Import only if the file is signed by a trusted vault.")
 }
@@ -0,0 +1,311 @@
+// File: cmd/inheritance.go
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"vault.module/internal/actions"
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/inheritance"
+	"vault.module/internal/security"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	inheritancePackageName      = "inheritance.age"
+	inheritanceInstructionsName = "INSTRUCTIONS.md"
+)
+
+var inheritanceRecipients []string
+var inheritanceDir string
+var inheritanceUnlockAfter string
+var inheritanceCheckInDays int
+
+var inheritanceCmd = &cobra.Command{
+	Use:   "inheritance",
+	Short: "Prepares and maintains an encrypted inheritance package.",
+	Long: `Prepares and maintains an encrypted inheritance package.
+
+'inheritance prepare' exports the active vault encrypted to your heirs' age
+keys, optionally time-locked until a date, together with an instructions
+document. Hand both to the heirs or their custodian.
+
+The package becomes out of date when the vault changes. With --check-in-days,
+you are also expected to run 'inheritance checkin' periodically. Every command
+warns while the package is out of date or a check-in is overdue, and
+'inheritance status' exits with an error, so it can drive a scheduled alert.`,
+}
+
+var inheritancePrepareCmd = &cobra.Command{
+	Use:   "prepare",
+	Short: "Creates the inheritance package for the active vault.",
+	Long: `Creates the inheritance package for the active vault.
+
+Writes inheritance.age and INSTRUCTIONS.md to --dir. The package is an export of
+all wallets, age-encrypted so that any one of the recipients can open it. With
+--unlock-after it is first time-locked with drand tlock (requires the 'tle' tool),
+so it cannot be opened before that date even with a recipient's key.
+
+Running prepare again refreshes the package, reusing the recorded recipients,
+time lock and check-in interval unless new ones are given.
+
+Examples:
+  vault.module inheritance prepare --recipient age1... --recipient age1... --dir /media/usb/heirs
+  vault.module inheritance prepare --recipient age1... --dir heirs --unlock-after 2030-01-01 --check-in-days 90
+  vault.module inheritance prepare --dir heirs   # refresh with the recorded settings
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("inheritance prepare")
+			}
+
+			previous := activeVault.Inheritance
+			recipients := inheritanceRecipients
+			unlockAfter := inheritanceUnlockAfter
+			checkInDays := inheritanceCheckInDays
+			if previous != nil {
+				if len(recipients) == 0 {
+					recipients = previous.Recipients
+				}
+				if !cmd.Flags().Changed("unlock-after") {
+					unlockAfter = previous.UnlockAfter
+				}
+				if !cmd.Flags().Changed("check-in-days") {
+					checkInDays = previous.CheckInDays
+				}
+			}
+			if len(recipients) == 0 {
+				return errors.NewInvalidInputError("--recipient", "at least one heir's age recipient is required")
+			}
+			for _, r := range recipients {
+				if !strings.HasPrefix(r, "age1") && !strings.HasPrefix(r, "ssh-") {
+					return errors.NewInvalidInputError(r, "recipients must be age (age1...) or SSH public keys")
+				}
+			}
+			if checkInDays < 0 {
+				return errors.NewInvalidInputError(fmt.Sprintf("%d", checkInDays), "--check-in-days cannot be negative")
+			}
+			var unlockTime time.Time
+			if unlockAfter != "" {
+				if unlockTime, err = time.Parse(time.DateOnly, unlockAfter); err != nil {
+					return errors.NewInvalidInputError(unlockAfter, "--unlock-after must be YYYY-MM-DD")
+				}
+				if !unlockTime.After(time.Now()) {
+					return errors.NewInvalidInputError(unlockAfter, "--unlock-after must be in the future")
+				}
+				if _, err := exec.LookPath(inheritance.TimeLockTool); err != nil {
+					return errors.NewDependencyError(inheritance.TimeLockTool, "Please install tlock for time-locked packages: https://github.com/drand/tlock")
+				}
+			}
+			dir := inheritanceDir
+			if dir == "" && previous != nil {
+				dir = filepath.Dir(previous.Package)
+			}
+			if dir == "" {
+				return errors.NewInvalidInputError("--dir", "an output directory is required")
+			}
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				return errors.FromOSError(err, dir)
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			if err := checkVaultNotFrozen("inheritance prepare", v); err != nil {
+				return err
+			}
+			plaintext, err := actions.ExportVault(v)
+			if err != nil {
+				return errors.NewExportFailedError("json", "failed to generate JSON for export", err)
+			}
+			ctx, cancel := context.WithTimeout(security.GetManager().Context(), 2*time.Minute)
+			defer cancel()
+			ciphertext, err := inheritance.Encrypt(ctx, plaintext, recipients, unlockTime)
+			security.SecureZero(plaintext)
+			if err != nil {
+				return errors.NewExportFailedError("age", "failed to encrypt the inheritance package", err)
+			}
+
+			vaultDigest, err := inheritance.FileDigest(activeVault.KeyFile)
+			if err != nil {
+				return errors.FromOSError(err, activeVault.KeyFile)
+			}
+			now := time.Now().UTC().Format(time.RFC3339)
+			record := &config.Inheritance{
+				Package:      filepath.Join(dir, inheritancePackageName),
+				Instructions: filepath.Join(dir, inheritanceInstructionsName),
+				Recipients:   recipients,
+				CreatedAt:    now,
+				VaultDigest:  vaultDigest,
+				UnlockAfter:  unlockAfter,
+				CheckInDays:  checkInDays,
+				LastCheckIn:  now,
+			}
+
+			if err := os.WriteFile(record.Package, ciphertext, 0600); err != nil {
+				return errors.NewFileSystemError("write", record.Package, err)
+			}
+			packageDigest, err := inheritance.FileDigest(record.Package)
+			if err != nil {
+				return errors.FromOSError(err, record.Package)
+			}
+			doc := inheritance.Instructions(config.Cfg.ActiveVault, activeVault.Type, v, record, inheritancePackageName, packageDigest)
+			if err := os.WriteFile(record.Instructions, []byte(doc), 0600); err != nil {
+				return errors.NewFileSystemError("write", record.Instructions, err)
+			}
+
+			activeVault.Inheritance = record
+			config.Cfg.Vaults[config.Cfg.ActiveVault] = activeVault
+			if err := config.SaveConfig(); err != nil {
+				return err
+			}
+
+			audit.Logger.Warn("Inheritance package prepared",
+				slog.String("command", "inheritance prepare"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.Int("wallets", len(v)),
+				slog.Int("recipients", len(recipients)),
+				slog.String("unlock_after", unlockAfter),
+				slog.String("sha256", packageDigest),
+			)
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Inheritance package with %d wallet(s) written to %s", len(v), record.Package), colors.Success))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Instructions written to %s", record.Instructions), colors.Success))
+			if due, ok := inheritance.NextCheckIn(record); ok {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Next check-in due by %s ('vault.module inheritance checkin').", due.Format(time.DateOnly)), colors.Info))
+			}
+			return nil
+		})
+	},
+}
+
+var inheritanceCheckinCmd = &cobra.Command{
+	Use:   "checkin",
+	Short: "Records that the owner is still in control of the vault.",
+	Long: `Records that the owner is still in control of the vault.
+
+Resets the check-in period of the active vault's inheritance package.
+
+Examples:
+  vault.module inheritance checkin
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			if activeVault.Inheritance == nil {
+				return errors.NewConfigMissingError("inheritance").WithDetails("run 'vault.module inheritance prepare' first")
+			}
+			activeVault.Inheritance.LastCheckIn = time.Now().UTC().Format(time.RFC3339)
+			config.Cfg.Vaults[config.Cfg.ActiveVault] = activeVault
+			if err := config.SaveConfig(); err != nil {
+				return err
+			}
+			audit.Logger.Info("Inheritance check-in", slog.String("command", "inheritance checkin"), slog.String("vault", config.Cfg.ActiveVault))
+
+			if due, ok := inheritance.NextCheckIn(activeVault.Inheritance); ok {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Checked in. Next check-in due by %s.", due.Format(time.DateOnly)), colors.Success))
+			} else {
+				fmt.Println(colors.SafeColor("Checked in.", colors.Success))
+			}
+			return nil
+		})
+	},
+}
+
+var inheritanceStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Checks whether the inheritance package is up to date.",
+	Long: `Checks whether the inheritance package is up to date.
+
+Exits with an error if the vault changed since the package was prepared, the
+package file is missing, or a check-in is overdue.
+
+Examples:
+  vault.module inheritance status
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			inh := activeVault.Inheritance
+			if inh == nil {
+				return errors.NewConfigMissingError("inheritance").WithDetails("run 'vault.module inheritance prepare' first")
+			}
+
+			fmt.Printf("Package:    %s\n", inh.Package)
+			fmt.Printf("Prepared:   %s\n", inh.CreatedAt)
+			fmt.Printf("Recipients: %d\n", len(inh.Recipients))
+			if inh.UnlockAfter != "" {
+				fmt.Printf("Time lock:  until %s\n", inh.UnlockAfter)
+			}
+			if due, ok := inheritance.NextCheckIn(inh); ok {
+				fmt.Printf("Check-in:   due by %s\n", due.Format(time.DateOnly))
+			}
+
+			problems := inheritance.Problems(inh, activeVault.KeyFile, time.Now())
+			if len(problems) > 0 {
+				return errors.New(errors.ErrCodeConfigValidation, "the inheritance package is out of date").
+					WithDetails(strings.Join(problems, "; "))
+			}
+			fmt.Println(colors.SafeColor("The inheritance package is up to date.", colors.Success))
+			return nil
+		})
+	},
+}
+
+// warnInheritanceOutdated warns on every command while the active vault's inheritance
+// package is out of date or a check-in is overdue
+func warnInheritanceOutdated(cmd *cobra.Command) {
+	if cmd.Parent() == inheritanceCmd || config.Cfg.ActiveVault == "" {
+		return
+	}
+	details, ok := config.Cfg.Vaults[config.Cfg.ActiveVault]
+	if !ok || details.Inheritance == nil {
+		return
+	}
+	for _, problem := range inheritance.Problems(details.Inheritance, details.KeyFile, time.Now()) {
+		audit.Logger.Warn("Inheritance package out of date", slog.String("vault", config.Cfg.ActiveVault), slog.String("reason", problem))
+		fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("WARNING: %s. Run 'vault.module inheritance prepare' or 'inheritance checkin'.", problem), colors.Warning))
+	}
+}
+
+func init() {
+	inheritancePrepareCmd.Flags().StringArrayVar(&inheritanceRecipients, "recipient", nil, "Heir's age recipient (repeatable); any one of them can open the package.")
+	inheritancePrepareCmd.Flags().StringVar(&inheritanceDir, "dir", "", "Directory to write the package and instructions to.")
+	inheritancePrepareCmd.Flags().StringVar(&inheritanceUnlockAfter, "unlock-after", "", "Time-lock the package until this date, YYYY-MM-DD (requires tle).")
+	inheritancePrepareCmd.Flags().IntVar(&inheritanceCheckInDays, "check-in-days", 0, "Expect an owner check-in at least every N days (0 = no check-ins).")
+}
@@ -0,0 +1,141 @@
+// File: cmd/labels.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"vault.module/internal/addressbook"
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/errors"
+
+	"github.com/spf13/cobra"
+)
+
+var labelsImportFormat string
+
+var labelsCmd = &cobra.Command{
+	Use:   "labels",
+	Short: "Labels external addresses in the address book.",
+	Long: `Labels external addresses in the address book.
+
+The address book gives human names to addresses outside the vault, such as
+exchange deposit addresses and known contracts. When 'airgap sign' shows a
+transaction and when the web3signer asks to confirm one, a labeled recipient is
+shown as "Binance 14 (0x28C6...)" instead of raw hex.
+
+Labels are imported from files:
+
+  etherscan-csv  an Etherscan label export or a labels dataset in its layout:
+                 a header row with an "Address" column and a "Name Tag" (or
+                 "Private Name Tag", "Name", "Label") column
+  csv            a tag file of address,label rows, with or without a header
+
+Rows without a valid EVM address or a label are skipped. Importing a label for
+an address already in the book replaces its label. The address book is
+address-book.json next to config.json; it holds public data only.
+
+Examples:
+  vault.module labels import etherscan-labels.csv --format etherscan-csv
+  vault.module labels import exchanges.csv --format csv
+  vault.module labels list
+  vault.module labels remove 0x28C6c06298d514Db089934071355E5743bf21d60
+`,
+}
+
+var labelsImportCmd = &cobra.Command{
+	Use:   "import <FILE>",
+	Short: "Imports address labels from a file.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			filePath := args[0]
+			if err := validateFileForImport(filePath); err != nil {
+				return err
+			}
+			file, err := os.Open(filePath)
+			if err != nil {
+				return errors.NewFileSystemError("read", filePath, err)
+			}
+			defer file.Close()
+
+			entries, skipped, err := addressbook.Parse(file, labelsImportFormat)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				return errors.NewInvalidInputError(filePath, "the file has no address labels")
+			}
+			added, updated, err := addressbook.Import(entries, filepath.Base(filePath))
+			if err != nil {
+				return err
+			}
+
+			audit.Logger.Info("Address labels imported",
+				slog.String("file", filePath),
+				slog.String("format", labelsImportFormat),
+				slog.Int("added", added),
+				slog.Int("updated", updated),
+				slog.Int("skipped", skipped))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Imported %d label(s): %d added, %d updated, %d unchanged.", len(entries), added, updated, len(entries)-added-updated), colors.Success))
+			if skipped > 0 {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Skipped %d row(s) without a valid address or label.", skipped), colors.Warning))
+			}
+			return nil
+		})
+	},
+}
+
+var labelsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the labeled addresses.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			entries, err := addressbook.List()
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				fmt.Println(colors.SafeColor("The address book is empty. See 'vault.module labels --help'.", colors.Info))
+				return nil
+			}
+			width := 0
+			for _, e := range entries {
+				width = max(width, len([]rune(e.Label)))
+			}
+			for _, e := range entries {
+				line := fmt.Sprintf("%s%s  %s", e.Label, strings.Repeat(" ", width-len([]rune(e.Label))), e.Address)
+				if e.Source != "" {
+					line += colors.SafeColor(" ("+e.Source+")", colors.Cyan)
+				}
+				fmt.Println(line)
+			}
+			return nil
+		})
+	},
+}
+
+var labelsRemoveCmd = &cobra.Command{
+	Use:   "remove <ADDRESS>",
+	Short: "Removes the label of an address.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if err := addressbook.Remove(args[0]); err != nil {
+				return err
+			}
+			audit.Logger.Info("Address label removed", slog.String("address", args[0]))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Label of %s removed.", args[0]), colors.Success))
+			return nil
+		})
+	},
+}
+
+func init() {
+	labelsImportCmd.Flags().StringVar(&labelsImportFormat, "format", addressbook.FormatEtherscanCSV, "Format of the file: "+strings.Join(addressbook.Formats, ", "))
+}
@@ -0,0 +1,89 @@
+// File: cmd/leaks.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/errors"
+	"vault.module/internal/leakcheck"
+
+	"github.com/spf13/cobra"
+)
+
+var leaksCmd = &cobra.Command{
+	Use:   "leaks",
+	Short: "Manages the database of known-compromised keys and addresses.",
+	Long: `Manages the database of known-compromised keys and addresses.
+
+A list of published keys ships with vault.module: development-tool defaults,
+documentation examples and brainwallets. A larger database of leaked keys and
+addresses can be installed as leaked.bloom next to config.json. It is stored as
+a bloom filter, so the leaked keys themselves are never kept on disk.
+
+'import' and 'vaults verify' warn about every wallet whose private keys or
+addresses match either source.
+
+Examples:
+  vault.module leaks status
+  vault.module leaks update leaked-keys.txt
+  vault.module leaks update leaked.bloom
+`,
+}
+
+var leaksStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Shows the size of the known-compromised key database.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			st, err := leakcheck.GetStatus()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Built-in list:      %d entries\n", st.BuiltinEntries)
+			if !st.DatabaseInstalled {
+				fmt.Println(colors.SafeColor("No database installed. Install one with 'vault.module leaks update <FILE>'.", colors.Info))
+				return nil
+			}
+			fmt.Printf("Installed database: %d entries (updated %s)\n", st.DatabaseEntries, st.DatabaseUpdated)
+			return nil
+		})
+	},
+}
+
+var leaksUpdateCmd = &cobra.Command{
+	Use:   "update <FILE>",
+	Short: "Installs a new known-compromised key database.",
+	Long: `Installs a new known-compromised key database.
+
+FILE is either a bloom filter published for vault.module or a text list with
+one private key or address per line (blank lines and # comments are ignored).
+A text list is converted to a bloom filter; the file itself is not kept.
+The new database replaces the installed one.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return errors.FromOSError(err, args[0])
+			}
+			count, err := leakcheck.Install(data)
+			if err != nil {
+				return err
+			}
+
+			audit.Logger.Info("Leak database updated",
+				slog.String("source", filepath.Base(args[0])),
+				slog.Uint64("entries", count),
+			)
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Installed %d entries into %s.", count, leakcheck.DatabaseFile), colors.Success))
+			return nil
+		})
+	},
+}
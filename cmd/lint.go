@@ -0,0 +1,205 @@
+// File: cmd/lint.go
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/lint"
+	"vault.module/internal/noncelog"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+// lintFailNone is the --fail-on level that never fails
+const lintFailNone = "none"
+
+var lintRuleFlags []string
+var lintFailOn string
+var lintListRules bool
+var lintJson bool
+
+// lintReport is the result of 'lint', printed with --json
+type lintReport struct {
+	Vault   string         `json:"vault"`
+	Wallets int            `json:"wallets"`
+	Issues  []lint.Issue   `json:"issues"`
+	Counts  map[string]int `json:"counts"` // By severity
+}
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [NAME]",
+	Short: "Checks a vault against best-practice rules.",
+	Long: `Checks a vault against best-practice rules.
+
+Every wallet of the vault (the active vault by default) is checked:
+
+  secret-in-notes     notes hold what looks like a private key or mnemonic (error)
+  backup-unverified   the checklist does not mark the backup verified (warning)
+  stale-wallet        no change or use for lint_stale_days days, 365 by default (warning)
+  unused-addresses    HD addresses that never signed with vault.module, from
+                      the signed nonce record (info)
+  missing-tags        the wallet has no tags (info)
+
+Severities are set per rule in "lint_rules" in config.json, e.g.
+{"missing-tags": "warning", "unused-addresses": "off"}, or for one run with
+--rule. The command fails when an issue reaches --fail-on ("error" by
+default), so CI jobs of team vaults can run it; --json prints the issues for
+them. Issues never contain secrets: found ones are masked.
+
+Examples:
+  vault.module lint
+  vault.module lint cold --rule missing-tags=off --rule stale-wallet=error
+  vault.module lint --fail-on warning --json
+  vault.module lint --list-rules
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			severities, err := lintSeverities()
+			if err != nil {
+				return err
+			}
+			if lintFailOn != lintFailNone && lint.SeverityRank(lintFailOn) <= 0 {
+				return errors.NewInvalidInputError(lintFailOn, "--fail-on takes info, warning, error or none")
+			}
+			if lintListRules {
+				for _, rule := range lint.Rules {
+					severity := rule.Severity
+					if s, ok := severities[rule.ID]; ok {
+						severity = s
+					}
+					fmt.Printf("%-18s %-8s %s\n", rule.ID, severity, rule.Description)
+				}
+				return nil
+			}
+
+			name, details, err := vaultFromArgs(args)
+			if err != nil {
+				return err
+			}
+			v, err := vault.LoadVault(details)
+			if err != nil {
+				return errors.NewVaultLoadError(details.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			signed, err := noncelog.SignedAddresses()
+			if err != nil {
+				return err
+			}
+			in := &lint.Input{
+				Vault:           v,
+				History:         vault.LoadedHistory(details.KeyFile),
+				Access:          map[string][]vault.AccessEntry{},
+				SignedAddresses: signed,
+				StaleAfter:      time.Duration(config.Cfg.LintStaleDays) * 24 * time.Hour,
+				Now:             time.Now(),
+			}
+			for prefix := range v {
+				in.Access[prefix] = vault.WalletAccess(details.KeyFile, prefix)
+			}
+
+			report := lintReport{Vault: name, Wallets: len(v), Issues: lint.Run(in, severities), Counts: map[string]int{}}
+			if report.Issues == nil {
+				report.Issues = []lint.Issue{}
+			}
+			failing := 0
+			for _, issue := range report.Issues {
+				report.Counts[issue.Severity]++
+				if lintFailOn != lintFailNone && lint.SeverityRank(issue.Severity) >= lint.SeverityRank(lintFailOn) {
+					failing++
+				}
+			}
+			audit.Logger.Info("Vault linted",
+				slog.String("vault", name),
+				slog.Int("errors", report.Counts[lint.SeverityError]),
+				slog.Int("warnings", report.Counts[lint.SeverityWarning]),
+				slog.Int("infos", report.Counts[lint.SeverityInfo]),
+			)
+
+			if lintJson {
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return errors.New(errors.ErrCodeInternal, "failed to format JSON").WithContext("marshal_error", err.Error())
+				}
+				fmt.Println(string(data))
+			} else {
+				printLintReport(report)
+			}
+			if failing > 0 {
+				return errors.New(errors.ErrCodeWalletInvalid, "the vault breaks lint rules").
+					WithDetails(fmt.Sprintf("%d issues at or above %s", failing, lintFailOn))
+			}
+			return nil
+		})
+	},
+}
+
+// lintSeverities merges the rule severities of config.json and --rule
+func lintSeverities() (map[string]string, error) {
+	severities := map[string]string{}
+	for id, severity := range config.Cfg.LintRules {
+		if lint.FindRule(id) == nil {
+			return nil, errors.NewConfigValidationError("lint_rules", id, "no such lint rule")
+		}
+		if lint.SeverityRank(severity) < 0 {
+			return nil, errors.NewConfigValidationError("lint_rules", severity, "severities are off, info, warning and error")
+		}
+		severities[id] = severity
+	}
+	for _, flag := range lintRuleFlags {
+		id, severity, ok := strings.Cut(flag, "=")
+		if !ok {
+			return nil, errors.NewInvalidInputError(flag, "--rule takes RULE=SEVERITY, e.g. missing-tags=off")
+		}
+		if lint.FindRule(id) == nil {
+			return nil, errors.NewInvalidInputError(id, "no such lint rule; see 'lint --list-rules'")
+		}
+		if lint.SeverityRank(severity) < 0 {
+			return nil, errors.NewInvalidInputError(severity, "severities are off, info, warning and error")
+		}
+		severities[id] = severity
+	}
+	return severities, nil
+}
+
+// printLintReport lists the issues, most severe first, and counts them
+func printLintReport(r lintReport) {
+	fmt.Println(colors.SafeColor(fmt.Sprintf("Vault '%s' (%d wallets)", r.Vault, r.Wallets), colors.Bold))
+	if len(r.Issues) == 0 {
+		fmt.Println(colors.SafeColor("✓ No issues found.", colors.Success))
+		return
+	}
+	for _, issue := range r.Issues {
+		level := colors.Info
+		switch issue.Severity {
+		case lint.SeverityError:
+			level = colors.Error
+		case lint.SeverityWarning:
+			level = colors.Warning
+		}
+		fmt.Printf("%s %-18s %s\n", colors.SafeColor(fmt.Sprintf("%-7s", strings.ToUpper(issue.Severity)), level), issue.Rule, colors.SafeColor(issue.Wallet, colors.White))
+		fmt.Printf("        %s\n", issue.Message)
+	}
+	fmt.Println(colors.SafeColor(fmt.Sprintf("%d errors, %d warnings, %d infos", r.Counts[lint.SeverityError], r.Counts[lint.SeverityWarning], r.Counts[lint.SeverityInfo]), colors.Bold))
+}
+
+func init() {
+	lintCmd.Flags().StringArrayVar(&lintRuleFlags, "rule", nil, "Severity of a rule for this run, RULE=SEVERITY (off, info, warning, error); repeatable.")
+	lintCmd.Flags().StringVar(&lintFailOn, "fail-on", lint.SeverityError, "Fail when an issue reaches this severity: info, warning, error or none.")
+	lintCmd.Flags().BoolVar(&lintListRules, "list-rules", false, "List the rules with their severities.")
+	lintCmd.Flags().BoolVar(&lintJson, "json", false, "Output the issues in JSON format.")
+}
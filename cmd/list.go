@@ -4,10 +4,14 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"sort"
+	"strings"
 
+	"vault.module/internal/actions"
 	"vault.module/internal/colors"
 	"vault.module/internal/config"
+	"vault.module/internal/display"
 	"vault.module/internal/errors"
 	"vault.module/internal/vault"
 
@@ -15,6 +19,15 @@ import (
 )
 
 var listJson bool
+var listTag string
+var listAddresses bool
+var listCSV bool
+var listPage int
+var listPageSize int
+var listTree bool
+
+// listAddressColumns are the columns of 'list --addresses'
+var listAddressColumns = []string{"prefix", "account", "index", "address", "path"}
 
 var listCmd = &cobra.Command{
 	Use:   "list",
@@ -26,11 +39,26 @@ Displays:
   - Number of addresses per wallet
   - Public addresses for each wallet
 
+--addresses shows one row per address instead, with its wallet, account,
+index and derivation path in aligned columns, --page-size rows per page.
+--csv writes the same rows as CSV and --json as a JSON array; both include
+every address unless --page or --page-size is given.
+
+--tree shows hierarchical prefixes (team/alice/eth-main) as a tree of their
+namespaces, with the first address of each wallet.
+
 Examples:
   vault.module list
+  vault.module list --tag treasury
+  vault.module list --addresses --page 2
+  vault.module list --addresses --csv > addresses.csv
+  vault.module list --tree
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return errors.WrapCommand(func() error {
+			if err := validateListFlags(); err != nil {
+				return err
+			}
 			if err := checkVaultStatus(); err != nil {
 				return err
 			}
@@ -61,8 +89,10 @@ Examples:
 			}
 
 			filteredPrefixes := make([]string, 0, len(v))
-			for prefix := range v {
-				filteredPrefixes = append(filteredPrefixes, prefix)
+			for prefix, wallet := range v {
+				if listTag == "" || wallet.HasTag(strings.ToLower(listTag)) {
+					filteredPrefixes = append(filteredPrefixes, prefix)
+				}
 			}
 
 			if len(filteredPrefixes) == 0 {
@@ -75,12 +105,27 @@ Examples:
 
 			sort.Strings(filteredPrefixes)
 
+			if listAddresses {
+				return printAddressList(cmd, v, filteredPrefixes)
+			}
+			if listTree {
+				fmt.Println(colors.SafeColor(
+					fmt.Sprintf("Saved wallets in '%s' (Type: %s):", config.Cfg.ActiveVault, activeVault.Type),
+					colors.Bold,
+				))
+				printWalletTree(v, buildWalletTree(filteredPrefixes), "")
+				return nil
+			}
+
 			if listJson {
 				outputVault := make(vault.Vault)
 				for _, prefix := range filteredPrefixes {
 					wallet := v[prefix]
-					if !programmaticMode {
+					if !programmaticMode || wallet.Frozen != nil {
+						// Frozen wallets never release their secrets, not even to scripts
 						outputVault[prefix] = wallet.Sanitize()
+					} else if err := refuseSecretEcho("list"); err != nil {
+						return err
 					} else {
 						outputVault[prefix] = wallet
 					}
@@ -100,35 +145,70 @@ Examples:
 
 					// Determine wallet source and format display
 					var sourceInfo string
-					if wallet.Mnemonic != nil {
-						mnemonicHint := wallet.GetMnemonicHint()
-						if mnemonicHint != "" {
+					if wallet.Archived() {
+						sourceInfo = fmt.Sprintf("Archived in '%s'", wallet.Archive.Vault)
+					} else if wallet.Kind == vault.KindWatch {
+						sourceInfo = "Watch-only"
+					} else if wallet.Kind == vault.KindSecret && wallet.SecretType != "" {
+						sourceInfo = fmt.Sprintf("Secret: %s", wallet.SecretType)
+					} else if wallet.Kind == vault.KindSecret {
+						sourceInfo = "Generic secret"
+					} else if wallet.Kind == vault.KindConsensus {
+						sourceInfo = "Consensus key (ed25519)"
+					} else if wallet.Source == vault.SourceSeed {
+						sourceInfo = "HD from BIP-32 seed (no mnemonic)"
+					} else if wallet.Mnemonic != nil {
+						mnemonicHint := maskMnemonic(wallet)
+						if mnemonicHint == "" && !wallet.Mnemonic.IsEmpty() {
+							sourceInfo = "HD wallet"
+						} else if mnemonicHint != "" {
 							sourceInfo = fmt.Sprintf("HD from: %s", mnemonicHint)
 						} else {
 							sourceInfo = "HD wallet (mnemonic cleared)"
 						}
+					} else if wallet.Sealed() && wallet.DerivationPath != "" {
+						// The mnemonic is inside the envelope
+						sourceInfo = "HD wallet"
 					} else {
 						// Single key wallet - private keys are not saved to JSON for security
 						sourceInfo = "Wallet from private key (imported)"
 					}
 
-					fmt.Printf("- %s (%s)\n", colors.SafeColor(prefix, colors.White), colors.SafeColor(sourceInfo, colors.Yellow))
+					if wallet.Source == vault.SourceEntropy {
+						sourceInfo += " (reconstructed from entropy)"
+					}
+					if wallet.DerivationPath != "" {
+						sourceInfo += ", " + wallet.Scheme() + " paths"
+					}
+					fmt.Printf("- %s (%s)%s\n", colors.SafeColor(prefix, colors.White), colors.SafeColor(sourceInfo, colors.Yellow), frozenBadge(wallet)+checklistBadge(wallet))
 
 					// Show addresses with index and private key hint
 					for _, addr := range wallet.Addresses {
-						fmt.Printf("  [%d] %s", addr.Index, colors.SafeColor(addr.Address, colors.Cyan))
+						if addr.Account != 0 {
+							fmt.Printf("  [%d'/%d] %s", addr.Account, addr.Index, colors.SafeColor(addr.Address, colors.Cyan))
+						} else {
+							fmt.Printf("  [%d] %s", addr.Index, colors.SafeColor(addr.Address, colors.Cyan))
+						}
 
 						// Show private key hint if available
-						if addr.PrivateKey != nil && addr.PrivateKey.String() != "" {
-							privateKeyStr := addr.PrivateKey.String()
-							if len(privateKeyStr) >= 6 {
-								hint := fmt.Sprintf("%s...%s", privateKeyStr[:3], privateKeyStr[len(privateKeyStr)-3:])
-								fmt.Printf(" (private key: %s)", colors.SafeColor(hint, colors.Dim))
-							}
+						if hint := maskSecret(addr.PrivateKey); hint != "" {
+							fmt.Printf(" (private key: %s)", colors.SafeColor(hint, colors.Dim))
 						}
 						fmt.Println()
 					}
 
+					if wallet.Archived() {
+						fmt.Printf("  Archived: %s, since %s\n", wallet.Archive.KeyFile, colors.SafeColor(display.TimeString(wallet.Archive.Since), colors.Dim))
+					}
+
+					if wallet.Frozen != nil {
+						fmt.Printf("  Frozen: %s, since %s\n", wallet.Frozen.Reason, colors.SafeColor(display.TimeString(wallet.Frozen.Since), colors.Dim))
+					}
+
+					if len(wallet.Tags) > 0 {
+						fmt.Printf("  Tags: %s\n", colors.SafeColor(strings.Join(wallet.Tags, ", "), colors.Yellow))
+					}
+
 					// Show notes if present (after addresses)
 					if wallet.Notes != "" {
 						fmt.Printf("  Notes: %s\n", colors.SafeColor(wallet.Notes, colors.Dim))
@@ -140,6 +220,174 @@ Examples:
 	},
 }
 
+// validateListFlags checks the flags of list before the vault is decrypted
+func validateListFlags() error {
+	if listCSV && !listAddresses {
+		return errors.NewInvalidInputError("--csv", "CSV output is only available with --addresses")
+	}
+	if listCSV && listJson {
+		return errors.NewInvalidInputError("--csv", "cannot be combined with --json")
+	}
+	if listPage < 1 {
+		return errors.NewInvalidInputError(fmt.Sprintf("%d", listPage), "page must be at least 1")
+	}
+	if listPageSize < 0 {
+		return errors.NewInvalidInputError(fmt.Sprintf("%d", listPageSize), "page size must be non-negative")
+	}
+	if listTree && (listAddresses || listJson) {
+		return errors.NewInvalidInputError("--tree", "cannot be combined with --addresses or --json")
+	}
+	return nil
+}
+
+// printAddressList prints the addresses of the wallets at prefixes, one row
+// each, a page at a time
+func printAddressList(cmd *cobra.Command, v vault.Vault, prefixes []string) error {
+	var rows []addressRow
+	for _, prefix := range prefixes {
+		wallet := v[prefix]
+		wallet.Addresses = append([]vault.Address{}, wallet.Addresses...)
+		wallet.SortAddresses()
+		for _, addr := range wallet.Addresses {
+			rows = append(rows, newAddressRow(prefix, addr))
+		}
+	}
+
+	// Exports get every row unless a page is asked for
+	paginate := !(listCSV || listJson) || cmd.Flags().Changed("page") || cmd.Flags().Changed("page-size")
+	total := len(rows)
+	pages := 1
+	if paginate && listPageSize > 0 && total > 0 {
+		pages = (total + listPageSize - 1) / listPageSize
+		if listPage > pages {
+			return errors.NewInvalidInputError(fmt.Sprintf("%d", listPage), fmt.Sprintf("the last page is %d", pages))
+		}
+		end := listPage * listPageSize
+		if end > total {
+			end = total
+		}
+		rows = rows[(listPage-1)*listPageSize : end]
+	}
+
+	switch {
+	case listCSV:
+		return printAddressCSV(os.Stdout, rows, listAddressColumns)
+	case listJson:
+		return printAddressJSON(os.Stdout, rows, listAddressColumns)
+	}
+	if err := printAddressTable(os.Stdout, rows, listAddressColumns); err != nil {
+		return err
+	}
+	if pages > 1 {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Page %d of %d, %d addresses. Use --page for the others.", listPage, pages, total), colors.Dim))
+	}
+	return nil
+}
+
 func init() {
 	listCmd.Flags().BoolVar(&listJson, "json", false, "Output the list in JSON format.")
+	listCmd.Flags().StringVar(&listTag, "tag", "", "Show only the wallets with this tag.")
+	listCmd.Flags().BoolVar(&listAddresses, "addresses", false, "Show one row per address instead of one entry per wallet.")
+	listCmd.Flags().BoolVar(&listCSV, "csv", false, "With --addresses, output CSV.")
+	listCmd.Flags().IntVar(&listPage, "page", 1, "With --addresses, the page to show.")
+	listCmd.Flags().IntVar(&listPageSize, "page-size", 50, "With --addresses, rows per page (0 for all).")
+	listCmd.Flags().BoolVar(&listTree, "tree", false, "Show the wallets as a tree of their prefix namespaces.")
+}
+
+// checklistBadge renders the completion of a wallet's cold-storage checklist, if it has one
+// frozenBadge marks wallets under a legal hold
+func frozenBadge(wallet vault.Wallet) string {
+	badge := ""
+	if wallet.Sealed() {
+		badge += " " + colors.SafeColor("[SEALED]", colors.Cyan)
+	}
+	if wallet.Frozen != nil {
+		badge += " " + colors.SafeColor("[FROZEN]", colors.Error)
+	}
+	return badge
+}
+
+func checklistBadge(wallet vault.Wallet) string {
+	done, total := wallet.ChecklistProgress()
+	if total == 0 {
+		return ""
+	}
+	color := colors.Warning
+	if done == total {
+		color = colors.Success
+	}
+	return " " + colors.SafeColor(fmt.Sprintf("[checklist %d/%d]", done, total), color)
+}
+
+// walletTreeNode is a namespace of 'list --tree', or a wallet when prefix is
+// set; a prefix can be both a wallet and a namespace.
+type walletTreeNode struct {
+	name     string
+	prefix   string
+	children []*walletTreeNode
+}
+
+// buildWalletTree arranges sorted prefixes by namespace
+func buildWalletTree(prefixes []string) []*walletTreeNode {
+	root := &walletTreeNode{}
+	for _, prefix := range prefixes {
+		node := root
+		for _, segment := range strings.Split(prefix, actions.NamespaceSeparator) {
+			var child *walletTreeNode
+			for _, c := range node.children {
+				if c.name == segment {
+					child = c
+					break
+				}
+			}
+			if child == nil {
+				child = &walletTreeNode{name: segment}
+				node.children = append(node.children, child)
+			}
+			node = child
+		}
+		node.prefix = prefix
+	}
+	return root.children
+}
+
+// walletTreeCount returns the number of wallets at and below node
+func walletTreeCount(node *walletTreeNode) int {
+	count := 0
+	if node.prefix != "" {
+		count++
+	}
+	for _, child := range node.children {
+		count += walletTreeCount(child)
+	}
+	return count
+}
+
+// printWalletTree prints nodes with box-drawing lines, indented by indent
+func printWalletTree(v vault.Vault, nodes []*walletTreeNode, indent string) {
+	for i, node := range nodes {
+		branch, next := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, next = "└── ", "    "
+		}
+		var line string
+		if node.prefix != "" {
+			wallet := v[node.prefix]
+			line = colors.SafeColor(node.name, colors.White)
+			if len(wallet.Addresses) > 0 {
+				line += "  " + colors.SafeColor(wallet.Addresses[0].Address, colors.Cyan)
+				if more := len(wallet.Addresses) - 1; more > 0 {
+					line += colors.SafeColor(fmt.Sprintf(" (+%d)", more), colors.Dim)
+				}
+			} else if wallet.Kind == vault.KindSecret {
+				line += "  " + colors.SafeColor("secret", colors.Yellow)
+			}
+			line += frozenBadge(wallet) + checklistBadge(wallet)
+		} else {
+			// A namespace only, with the number of wallets in it
+			line = colors.SafeColor(node.name+actions.NamespaceSeparator, colors.Bold) + colors.SafeColor(fmt.Sprintf(" (%d)", walletTreeCount(node)), colors.Dim)
+		}
+		fmt.Println(colors.Text(indent+branch) + line)
+		printWalletTree(v, node.children, indent+next)
+	}
 }
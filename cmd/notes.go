@@ -21,6 +21,10 @@ This command allows you to update notes for an existing wallet.
 Notes are stored at the wallet level and apply to all addresses in the wallet.
 You will be prompted to enter new values interactively.
 
+Notes are not protected like keys: 'list' shows them and public twins copy
+them. Notes that look like a private key or mnemonic are warned about when
+saved, and refused by the strict profile; keep such values in a secret.
+
 Examples:
   vault.module notes A1
   vault.module notes mywallet
@@ -0,0 +1,312 @@
+// File: cmd/operators.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var operatorRecipient string
+var operatorIdentityFile string
+var operatorsRevokeYes bool
+
+var operatorsCmd = &cobra.Command{
+	Use:   "operators",
+	Short: "Manages the operators of organization mode.",
+	Long: `Manages the operators of organization mode.
+
+In organization mode every team member is an operator with their own age
+identity. A vault is encrypted to the recipients of the operators granted
+access to it, in addition to its recipients file, and each machine decrypts
+with the identity of the operator it acts as ("operator" in config.json, or
+VAULT_OPERATOR). The audit log records which operator decrypted the vault and
+ran each command.
+
+Examples:
+  vault.module operators add alice --recipient age1yubikey1... --identity-file ~/.age/alice.txt
+  vault.module operators use alice
+  vault.module operators grant bob
+  vault.module operators revoke bob
+  vault.module operators list
+`,
+}
+
+var operatorsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the operators and their access to the active vault.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if len(config.Cfg.Operators) == 0 {
+				fmt.Println(colors.SafeColor("No operators configured. Add one with 'vault.module operators add'.", colors.Info))
+				return nil
+			}
+			active, hasActive := config.Cfg.Vaults[config.Cfg.ActiveVault]
+			fmt.Println(colors.SafeColor("Operators:", colors.Bold))
+			for _, op := range config.Cfg.Operators {
+				marker := "  "
+				if op.Name == config.Cfg.Operator {
+					marker = "* "
+				}
+				access := ""
+				if hasActive && active.HasOperator(op.Name) {
+					access = " " + colors.SafeColor(fmt.Sprintf("[access to %s]", config.Cfg.ActiveVault), colors.Success)
+				}
+				fmt.Printf("%s%s %s%s\n", marker, colors.SafeColor(op.Name, colors.White), colors.SafeColor(op.Recipient, colors.Cyan), access)
+			}
+			return nil
+		})
+	},
+}
+
+var operatorsAddCmd = &cobra.Command{
+	Use:   "add <NAME>",
+	Short: "Adds an operator or updates their recipient and identity file.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			name := args[0]
+			// Operator names follow the vault name rules
+			if err := config.ValidateVaultName(name); err != nil {
+				return errors.NewInvalidInputError(name, strings.Replace(err.Error(), "vault name", "operator name", 1))
+			}
+			recipient := strings.TrimSpace(operatorRecipient)
+			if !strings.HasPrefix(recipient, "age1") {
+				return errors.NewInvalidInputError(recipient, "--recipient must be an age recipient (age1...)")
+			}
+			if operatorIdentityFile != "" {
+				if err := config.ValidateFilePath(operatorIdentityFile, "identity file"); err != nil {
+					return errors.NewVaultInvalidPathError(operatorIdentityFile, err)
+				}
+			}
+
+			op := config.Operator{Name: name, Recipient: recipient, IdentityFile: operatorIdentityFile}
+			updated := false
+			for i := range config.Cfg.Operators {
+				if config.Cfg.Operators[i].Name == name {
+					if config.Cfg.Operators[i].Recipient != recipient && operatorHasVaults(name) {
+						return errors.NewInvalidInputError(name, "operator has access to vaults; revoke it before changing the recipient")
+					}
+					config.Cfg.Operators[i] = op
+					updated = true
+				}
+			}
+			if !updated {
+				config.Cfg.Operators = append(config.Cfg.Operators, op)
+			}
+			if err := config.SaveConfig(); err != nil {
+				return errors.NewConfigSaveError("config.json", err)
+			}
+
+			audit.Logger.Info("Operator saved", slog.String("operator", name), slog.String("recipient", recipient), slog.Bool("updated", updated))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Operator '%s' saved.", name), colors.Success))
+			return nil
+		})
+	},
+}
+
+var operatorsRemoveCmd = &cobra.Command{
+	Use:   "remove <NAME>",
+	Short: "Removes an operator without vault access.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			name := args[0]
+			if _, ok := config.FindOperator(name); !ok {
+				return errors.NewInvalidInputError(name, "no such operator")
+			}
+			if operatorHasVaults(name) {
+				return errors.NewInvalidInputError(name, "operator still has access to vaults; revoke it first")
+			}
+			operators := config.Cfg.Operators[:0]
+			for _, op := range config.Cfg.Operators {
+				if op.Name != name {
+					operators = append(operators, op)
+				}
+			}
+			config.Cfg.Operators = operators
+			if config.PersistedOperator() == name {
+				config.SetOperator("")
+			}
+			if err := config.SaveConfig(); err != nil {
+				return errors.NewConfigSaveError("config.json", err)
+			}
+
+			audit.Logger.Info("Operator removed", slog.String("operator", name))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Operator '%s' removed.", name), colors.Success))
+			return nil
+		})
+	},
+}
+
+var operatorsUseCmd = &cobra.Command{
+	Use:   "use <NAME>",
+	Short: "Sets the operator this machine acts as.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			op, ok := config.FindOperator(args[0])
+			if !ok {
+				return errors.NewInvalidInputError(args[0], "no such operator")
+			}
+			if op.IdentityFile == "" {
+				return errors.NewConfigMissingError("operators." + op.Name + ".identityfile").
+					WithDetails("add the identity file with 'vault.module operators add " + op.Name + " --recipient " + op.Recipient + " --identity-file <path>'")
+			}
+			config.SetOperator(op.Name)
+			if err := config.SaveConfig(); err != nil {
+				return errors.NewConfigSaveError("config.json", err)
+			}
+
+			audit.Logger.Info("Operator selected", slog.String("operator", op.Name))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("This machine now acts as operator '%s'.", op.Name), colors.Success))
+			return nil
+		})
+	},
+}
+
+var operatorsGrantCmd = &cobra.Command{
+	Use:   "grant <NAME>",
+	Short: "Grants an operator access to the active vault.",
+	Long: `Grants an operator access to the active vault.
+
+The vault is decrypted and re-encrypted to include the operator's recipient.
+A signed vault configuration is re-signed with its current integrity mode.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			return updateVaultOperators(args[0], true)
+		})
+	},
+}
+
+var operatorsRevokeCmd = &cobra.Command{
+	Use:   "revoke <NAME>",
+	Short: "Revokes an operator's access to the active vault.",
+	Long: `Revokes an operator's access to the active vault.
+
+The vault is re-encrypted without the operator's recipient. Copies of the vault
+the operator made before cannot be revoked: rotate the keys they had access to.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("operators revoke")
+			}
+			return updateVaultOperators(args[0], false)
+		})
+	},
+}
+
+// operatorHasVaults reports whether any vault grants access to the operator
+func operatorHasVaults(name string) bool {
+	for _, details := range config.Cfg.Vaults {
+		if details.HasOperator(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// updateVaultOperators grants or revokes an operator's access to the active vault
+// and re-encrypts it accordingly
+func updateVaultOperators(name string, grant bool) error {
+	if err := checkVaultStatus(); err != nil {
+		return err
+	}
+	activeVault, err := config.GetActiveVault()
+	if err != nil {
+		return err
+	}
+	if _, ok := config.FindOperator(name); !ok {
+		return errors.NewInvalidInputError(name, "no such operator")
+	}
+	if activeVault.HasOperator(name) == grant {
+		state := "already has"
+		if !grant {
+			state = "does not have"
+		}
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Operator '%s' %s access to vault '%s'.", name, state, config.Cfg.ActiveVault), colors.Info))
+		return nil
+	}
+
+	if !grant && !operatorsRevokeYes {
+		prompt := fmt.Sprintf("Revoke the access of operator '%s' to vault '%s'?", name, config.Cfg.ActiveVault)
+		if !askForConfirmation(colors.SafeColor(prompt, colors.Warning)) {
+			fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+			return nil
+		}
+	}
+
+	v, err := vault.LoadVault(activeVault)
+	if err != nil {
+		return errors.NewVaultLoadError(activeVault.KeyFile, err)
+	}
+	defer func() {
+		for _, wallet := range v {
+			wallet.Clear()
+		}
+	}()
+
+	updated := activeVault
+	updated.Operators = nil
+	for _, n := range activeVault.Operators {
+		if n != name {
+			updated.Operators = append(updated.Operators, n)
+		}
+	}
+	if grant {
+		updated.Operators = append(updated.Operators, name)
+	}
+	if err := saveVaultOperators(updated, v); err != nil {
+		return err
+	}
+
+	if grant {
+		audit.Logger.Warn("Operator granted vault access", slog.String("vault", config.Cfg.ActiveVault), slog.String("operator", name))
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Operator '%s' can now decrypt vault '%s'.", name, config.Cfg.ActiveVault), colors.Success))
+		return nil
+	}
+	audit.Logger.Warn("Operator vault access revoked", slog.String("vault", config.Cfg.ActiveVault), slog.String("operator", name))
+	fmt.Println(colors.SafeColor(fmt.Sprintf("Operator '%s' can no longer decrypt vault '%s'.", name, config.Cfg.ActiveVault), colors.Success))
+	fmt.Println(colors.SafeColor("Earlier copies of the vault stay readable to them; rotate the keys they had access to.", colors.Warning))
+	return nil
+}
+
+// saveVaultOperators saves the active vault encrypted to the operators of
+// updated, and updated as its config entry. A signed vault configuration is
+// re-signed with its current integrity mode.
+func saveVaultOperators(updated config.VaultDetails, v vault.Vault) error {
+	if updated.IntegritySignature != "" {
+		signature, err := vault.SignVaultConfig(updated, v, vault.IntegrityModeFor(updated.KeyFile))
+		if err != nil {
+			return err
+		}
+		updated.IntegritySignature = signature
+	} else if err := vault.SaveVault(updated, v); err != nil {
+		return errors.NewVaultSaveError(updated.KeyFile, err)
+	}
+	config.Cfg.Vaults[config.Cfg.ActiveVault] = updated
+	if err := config.SaveConfig(); err != nil {
+		return errors.NewConfigSaveError("config.json", err)
+	}
+	return nil
+}
+
+func init() {
+	operatorsAddCmd.Flags().StringVar(&operatorRecipient, "recipient", "", "age recipient of the operator (required)")
+	operatorsAddCmd.Flags().StringVar(&operatorIdentityFile, "identity-file", "", "age identity file of the operator, on their own machine")
+	_ = operatorsAddCmd.MarkFlagRequired("recipient")
+	operatorsRevokeCmd.Flags().BoolVar(&operatorsRevokeYes, "yes", false, "Revoke without confirmation prompt")
+}
@@ -0,0 +1,124 @@
+// File: cmd/passphrase.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+	"vault.module/internal/vault"
+)
+
+// passphraseScoreNames describe the passphrase strength scores
+var passphraseScoreNames = []string{"very weak", "weak", "fair", "strong", "very strong"}
+
+func init() {
+	vault.OpenSecondFactorPassphrase = askOpenSecondFactorPassphrase
+}
+
+// configurePassphraseEstimator installs the passphrase estimator selected in config.json
+func configurePassphraseEstimator() error {
+	switch config.Cfg.PassphraseEstimator {
+	case "", "zxcvbn":
+		security.SetPassphraseEstimator(security.ZxcvbnEstimator{})
+	case "length":
+		security.SetPassphraseEstimator(security.LengthEstimator{})
+	case "command":
+		if config.Cfg.PassphraseEstimatorCommand == "" {
+			return errors.NewConfigValidationError("passphrase_estimator_command", "", "required for the \"command\" passphrase estimator")
+		}
+		if err := config.ValidateFilePath(config.Cfg.PassphraseEstimatorCommand, "passphrase estimator command"); err != nil {
+			return errors.NewConfigValidationError("passphrase_estimator_command", config.Cfg.PassphraseEstimatorCommand, err.Error())
+		}
+		security.SetPassphraseEstimator(security.CommandEstimator{Path: config.Cfg.PassphraseEstimatorCommand})
+	default:
+		return errors.NewConfigValidationError("passphrase_estimator", config.Cfg.PassphraseEstimator, "passphrase estimators are \"zxcvbn\", \"length\" and \"command\"")
+	}
+	return nil
+}
+
+// askForNewPassphrase asks for a passphrase twice and returns it if both match
+// and checkPassphraseStrength accepts it
+func askForNewPassphrase(command, prompt string, userInputs ...string) (string, error) {
+	passphrase, err := askForSecretInput(prompt)
+	if err != nil {
+		return "", err
+	}
+	if len(passphrase) < 8 {
+		return "", errors.NewInvalidInputError("passphrase", "the passphrase must be at least 8 characters")
+	}
+	if err := checkPassphraseStrength(command, strings.ToLower(prompt), passphrase, userInputs...); err != nil {
+		return "", err
+	}
+	again, err := askForSecretInput("Repeat to confirm")
+	if err != nil {
+		return "", err
+	}
+	if again != passphrase {
+		return "", errors.NewInvalidInputError("passphrase", "the passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// askOpenSecondFactorPassphrase asks for the passphrase that opens a vault's
+// passphrase second factor. Saves in the same run encrypt with it again.
+func askOpenSecondFactorPassphrase(details config.VaultDetails) (string, error) {
+	return askForSecretInput(fmt.Sprintf("Second factor passphrase for '%s'", filepath.Base(details.KeyFile)))
+}
+
+// checkPassphraseStrength estimates a new passphrase and shows its strength.
+// Very weak passphrases are refused by the strict profile and need a confirmation
+// otherwise; weak ones only warn. userInputs are names the passphrase should
+// not be built from; the active vault's name is always one.
+func checkPassphraseStrength(command, what, passphrase string, userInputs ...string) error {
+	inputs := append([]string{config.Cfg.ActiveVault}, userInputs...)
+	for _, op := range config.Cfg.Operators {
+		inputs = append(inputs, op.Name)
+	}
+	strength, estimator, err := security.EstimatePassphrase(passphrase, inputs...)
+	if err != nil {
+		if config.Cfg.Strict {
+			return errors.Wrap(errors.ErrCodeSystem, "could not check the passphrase strength", err).
+				WithDetails("the strict profile refuses passphrases it cannot check")
+		}
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Could not check the passphrase strength: %v", err), colors.Warning))
+		return nil
+	}
+
+	level := colors.Success
+	if strength.Score < security.PassphraseSafelyUnguessable {
+		level = colors.Warning
+	}
+	fmt.Println(colors.SafeColor(fmt.Sprintf("Passphrase strength: %s (%d/4, about 10^%.0f guesses)", passphraseScoreNames[strength.Score], strength.Score, strength.GuessesLog10), level))
+	if strength.Warning != "" {
+		fmt.Println(colors.SafeColor("  "+strength.Warning, colors.Warning))
+	}
+	for _, s := range strength.Suggestions {
+		fmt.Printf("  - %s\n", s)
+	}
+	if strength.Score > security.PassphraseVeryGuessable {
+		return nil
+	}
+
+	audit.Logger.Warn("Weak passphrase chosen",
+		slog.String("command", command),
+		slog.String("what", what),
+		slog.String("estimator", estimator),
+		slog.Int("score", strength.Score),
+		slog.Bool("strict", config.Cfg.Strict),
+	)
+	if config.Cfg.Strict {
+		return errors.NewInvalidInputError(what, "the passphrase is too easy to guess; the strict profile refuses it")
+	}
+	if !askForConfirmation(colors.SafeColor("This passphrase is easy to guess. Use it anyway?", colors.Warning)) {
+		return errors.NewInvalidInputError(what, "the passphrase is too easy to guess")
+	}
+	audit.Logger.Warn("Weak passphrase accepted after confirmation", slog.String("command", command), slog.String("what", what))
+	return nil
+}
@@ -0,0 +1,150 @@
+// File: cmd/pathscheme.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/keys"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var pathSchemeReset bool
+var pathSchemeYes bool
+
+var pathSchemeCmd = &cobra.Command{
+	Use:   "path-scheme <PREFIX> [SCHEME]",
+	Short: "Shows or sets how an HD wallet derives its next addresses.",
+	Long: `Shows or sets how an HD wallet derives its next addresses.
+
+Schemes (EVM paths shown; Cosmos wallets use coin type 118):
+  bip44          m/44'/60'/0'/0/i   MetaMask, Trezor, Rabby (default)
+  ledger-live    m/44'/60'/i'/0/0   Ledger Live: one account per address
+  ledger-legacy  m/44'/60'/0'/i     Ledger Chrome app, MyEtherWallet
+
+'derive' and 'discover' follow the scheme, so a mnemonic shared with Ledger
+Live gets the same addresses here as there. The first address of bip44 and
+ledger-live is the same, so a wallet holding only it can switch freely. Other
+switches change the addresses, and need --reset: the wallet's addresses are
+replaced by the first address of the new scheme.
+
+Examples:
+  vault.module path-scheme A1
+  vault.module path-scheme A1 ledger-live
+  vault.module path-scheme A1 ledger-legacy --reset
+`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+
+			prefix := args[0]
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			wallet, exists := v[prefix]
+			if !exists {
+				return errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
+			}
+			if wallet.DerivationPath == "" {
+				return errors.NewWalletInvalidError(prefix, "path schemes only apply to HD wallets (with a mnemonic)")
+			}
+
+			if len(args) == 1 {
+				fmt.Printf("%s: %s (%s)\n", prefix, colors.SafeColor(wallet.Scheme(), colors.Cyan), wallet.DerivationPath)
+				return nil
+			}
+
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("path-scheme")
+			}
+			scheme := strings.ToLower(args[1])
+			derivationPath, err := keys.SchemeDerivationPath(activeVault.Type, scheme)
+			if err != nil {
+				return errors.NewInvalidInputError(args[1], err.Error())
+			}
+			if scheme == wallet.Scheme() {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Wallet '%s' already uses the %s path scheme.", prefix, scheme), colors.Info))
+				return nil
+			}
+			if err := checkWalletNotFrozen("path-scheme", prefix, wallet); err != nil {
+				return err
+			}
+			if wallet.Sealed() {
+				return errors.NewWalletInvalidError(prefix, "wallet is sealed in an envelope; remove it with 'envelope remove' before changing its path scheme")
+			}
+
+			// The switch keeps the addresses if all of them belong to the new scheme
+			switched := wallet
+			switched.PathScheme = scheme
+			if scheme == vault.PathSchemeBIP44 {
+				switched.PathScheme = ""
+			}
+			compatible := derivationPath == wallet.DerivationPath
+			for _, addr := range wallet.Addresses {
+				if switched.SchemeOrdinal(addr) < 0 {
+					compatible = false
+				}
+			}
+
+			previous := wallet.Scheme()
+			if compatible {
+				wallet = switched
+			} else {
+				if !pathSchemeReset {
+					return errors.NewWalletInvalidError(prefix, fmt.Sprintf("its addresses do not follow the %s scheme", scheme)).
+						WithDetails("use --reset to replace them with the first address of the new scheme")
+				}
+				if !pathSchemeYes && !askForConfirmation(colors.SafeColor(
+					fmt.Sprintf("WARNING: the %d address(es) of '%s' will be replaced by the first %s address. Continue?", len(wallet.Addresses), prefix, scheme),
+					colors.Warning,
+				)) {
+					fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+					return nil
+				}
+				wallet, err = keys.ApplyPathScheme(wallet, activeVault.Type, scheme)
+				if err != nil {
+					return errors.NewWalletInvalidError(prefix, fmt.Sprintf("derivation error: %s", err.Error()))
+				}
+			}
+
+			v[prefix] = wallet
+			if err := vault.SaveVault(activeVault, v); err != nil {
+				return errors.NewVaultSaveError(activeVault.KeyFile, err)
+			}
+
+			audit.Logger.Info("Path scheme changed", slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.String("from", previous), slog.String("to", scheme), slog.Bool("reset", !compatible))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Wallet '%s' now uses the %s path scheme.", prefix, scheme), colors.Success))
+			if !compatible {
+				fmt.Printf("   Address: %s\n", colors.SafeColor(wallet.Addresses[0].Address, colors.Cyan))
+			}
+			return nil
+		})
+	},
+}
+
+func init() {
+	pathSchemeCmd.Flags().BoolVar(&pathSchemeReset, "reset", false, "Replace the wallet's addresses when they do not follow the new scheme.")
+	pathSchemeCmd.Flags().BoolVar(&pathSchemeYes, "yes", false, "Skip the confirmation of --reset.")
+}
@@ -0,0 +1,285 @@
+#!/usr/bin/env python3
+"""Verifies a vault.module ownership proof bundle without vault.module.
+
+Usage: python3 verify.py bundle.json
+
+Checks, for every proof in the bundle, that the statement names the proof's
+address and the bundle's challenge, and that the signature was made by the key
+of that address. Needs only the Python 3 standard library. Exits 0 when every
+proof is valid, 1 otherwise.
+"""
+
+import hashlib
+import json
+import sys
+
+# --- Keccak-256 (Ethereum's pre-standard SHA-3) ---
+
+_RC = [
+    0x0000000000000001, 0x0000000000008082, 0x800000000000808A, 0x8000000080008000,
+    0x000000000000808B, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+    0x000000000000008A, 0x0000000000000088, 0x0000000080008009, 0x000000008000000A,
+    0x000000008000808B, 0x800000000000008B, 0x8000000000008089, 0x8000000000008003,
+    0x8000000000008002, 0x8000000000000080, 0x000000000000800A, 0x800000008000000A,
+    0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+]
+_ROT = [
+    [0, 36, 3, 41, 18], [1, 44, 10, 45, 2], [62, 6, 43, 15, 61],
+    [28, 55, 25, 21, 56], [27, 20, 39, 8, 14],
+]
+_M64 = (1 << 64) - 1
+
+
+def _rol(x, n):
+    return ((x << n) | (x >> (64 - n))) & _M64 if n else x
+
+
+def _keccak_f(a):
+    for rc in _RC:
+        c = [a[x][0] ^ a[x][1] ^ a[x][2] ^ a[x][3] ^ a[x][4] for x in range(5)]
+        d = [c[(x - 1) % 5] ^ _rol(c[(x + 1) % 5], 1) for x in range(5)]
+        a = [[a[x][y] ^ d[x] for y in range(5)] for x in range(5)]
+        b = [[0] * 5 for _ in range(5)]
+        for x in range(5):
+            for y in range(5):
+                b[y][(2 * x + 3 * y) % 5] = _rol(a[x][y], _ROT[x][y])
+        a = [[b[x][y] ^ (~b[(x + 1) % 5][y] & b[(x + 2) % 5][y]) for y in range(5)] for x in range(5)]
+        a[0][0] ^= rc
+    return a
+
+
+def keccak256(data):
+    rate = 136
+    msg = bytearray(data) + b"\x01"
+    while len(msg) % rate:
+        msg.append(0)
+    msg[-1] |= 0x80
+    a = [[0] * 5 for _ in range(5)]
+    for off in range(0, len(msg), rate):
+        block = msg[off:off + rate]
+        for i in range(rate // 8):
+            a[i % 5][i // 5] ^= int.from_bytes(block[8 * i:8 * i + 8], "little")
+        a = _keccak_f(a)
+    out = b"".join(a[i % 5][i // 5].to_bytes(8, "little") for i in range(4))
+    return out
+
+
+# --- RIPEMD-160, for hashlib builds without it ---
+
+def _ripemd160_py(data):
+    def f(j, x, y, z):
+        if j < 16:
+            return x ^ y ^ z
+        if j < 32:
+            return (x & y) | (~x & z)
+        if j < 48:
+            return (x | ~y) ^ z
+        if j < 64:
+            return (x & z) | (y & ~z)
+        return x ^ (y | ~z)
+
+    def rol(x, n):
+        x &= 0xFFFFFFFF
+        return ((x << n) | (x >> (32 - n))) & 0xFFFFFFFF
+
+    kl = [0x00000000, 0x5A827999, 0x6ED9EBA1, 0x8F1BBCDC, 0xA953FD4E]
+    kr = [0x50A28BE6, 0x5C4DD124, 0x6D703EF3, 0x7A6D76E9, 0x00000000]
+    rl = [0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+          7, 4, 13, 1, 10, 6, 15, 3, 12, 0, 9, 5, 2, 14, 11, 8,
+          3, 10, 14, 4, 9, 15, 8, 1, 2, 7, 0, 6, 13, 11, 5, 12,
+          1, 9, 11, 10, 0, 8, 12, 4, 13, 3, 7, 15, 14, 5, 6, 2,
+          4, 0, 5, 9, 7, 12, 2, 10, 14, 1, 3, 8, 11, 6, 15, 13]
+    rr = [5, 14, 7, 0, 9, 2, 11, 4, 13, 6, 15, 8, 1, 10, 3, 12,
+          6, 11, 3, 7, 0, 13, 5, 10, 14, 15, 8, 12, 4, 9, 1, 2,
+          15, 5, 1, 3, 7, 14, 6, 9, 11, 8, 12, 2, 10, 0, 4, 13,
+          8, 6, 4, 1, 3, 11, 15, 0, 5, 12, 2, 13, 9, 7, 10, 14,
+          12, 15, 10, 4, 1, 5, 8, 7, 6, 2, 13, 14, 0, 3, 9, 11]
+    sl = [11, 14, 15, 12, 5, 8, 7, 9, 11, 13, 14, 15, 6, 7, 9, 8,
+          7, 6, 8, 13, 11, 9, 7, 15, 7, 12, 15, 9, 11, 7, 13, 12,
+          11, 13, 6, 7, 14, 9, 13, 15, 14, 8, 13, 6, 5, 12, 7, 5,
+          11, 12, 14, 15, 14, 15, 9, 8, 9, 14, 5, 6, 8, 6, 5, 12,
+          9, 15, 5, 11, 6, 8, 13, 12, 5, 12, 13, 14, 11, 8, 5, 6]
+    sr = [8, 9, 9, 11, 13, 15, 15, 5, 7, 7, 8, 11, 14, 14, 12, 6,
+          9, 13, 15, 7, 12, 8, 9, 11, 7, 7, 12, 7, 6, 15, 13, 11,
+          9, 7, 15, 11, 8, 6, 6, 14, 12, 13, 5, 14, 13, 13, 7, 5,
+          15, 5, 8, 11, 14, 14, 6, 14, 6, 9, 12, 9, 12, 5, 15, 8,
+          8, 5, 12, 9, 12, 5, 14, 6, 8, 13, 6, 5, 15, 13, 11, 11]
+
+    msg = bytearray(data) + b"\x80"
+    while len(msg) % 64 != 56:
+        msg.append(0)
+    msg += (8 * len(data)).to_bytes(8, "little")
+    h = [0x67452301, 0xEFCDAB89, 0x98BADCFE, 0x10325476, 0xC3D2E1F0]
+    for off in range(0, len(msg), 64):
+        x = [int.from_bytes(msg[off + 4 * i:off + 4 * i + 4], "little") for i in range(16)]
+        al, bl, cl, dl, el = h
+        ar, br, cr, dr, er = h
+        for j in range(80):
+            t = rol(al + f(j, bl, cl, dl) + x[rl[j]] + kl[j // 16], sl[j]) + el
+            al, el, dl, cl, bl = el, dl, rol(cl, 10), bl, t & 0xFFFFFFFF
+            t = rol(ar + f(79 - j, br, cr, dr) + x[rr[j]] + kr[j // 16], sr[j]) + er
+            ar, er, dr, cr, br = er, dr, rol(cr, 10), br, t & 0xFFFFFFFF
+        t = (h[1] + cl + dr) & 0xFFFFFFFF
+        h[1] = (h[2] + dl + er) & 0xFFFFFFFF
+        h[2] = (h[3] + el + ar) & 0xFFFFFFFF
+        h[3] = (h[4] + al + br) & 0xFFFFFFFF
+        h[4] = (h[0] + bl + cr) & 0xFFFFFFFF
+        h[0] = t
+    return b"".join(v.to_bytes(4, "little") for v in h)
+
+
+def ripemd160(data):
+    try:
+        return hashlib.new("ripemd160", data).digest()
+    except ValueError:
+        return _ripemd160_py(data)
+
+
+# --- secp256k1 ---
+
+P = 0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F
+N = 0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141
+G = (0x79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798,
+     0x483ADA7726A3C4655DA4FBFC0E1108A8FD17B448A68554199C47D08FFB10D4B8)
+
+
+def _add(p, q):
+    if p is None:
+        return q
+    if q is None:
+        return p
+    if p[0] == q[0] and (p[1] + q[1]) % P == 0:
+        return None
+    if p == q:
+        m = 3 * p[0] * p[0] * pow(2 * p[1], -1, P)
+    else:
+        m = (q[1] - p[1]) * pow(q[0] - p[0], -1, P)
+    x = (m * m - p[0] - q[0]) % P
+    return (x, (m * (p[0] - x) - p[1]) % P)
+
+
+def _mul(k, p):
+    r = None
+    while k:
+        if k & 1:
+            r = _add(r, p)
+        p = _add(p, p)
+        k >>= 1
+    return r
+
+
+def _lift_x(x, odd):
+    y = pow((x * x * x + 7) % P, (P + 1) // 4, P)
+    if (y * y - x * x * x - 7) % P:
+        return None
+    return (x, y if (y & 1) == odd else P - y)
+
+
+def decode_pubkey(data):
+    if len(data) == 33 and data[0] in (2, 3):
+        return _lift_x(int.from_bytes(data[1:], "big"), data[0] & 1)
+    if len(data) == 65 and data[0] == 4:
+        return (int.from_bytes(data[1:33], "big"), int.from_bytes(data[33:], "big"))
+    return None
+
+
+def compress(point):
+    return bytes([2 + (point[1] & 1)]) + point[0].to_bytes(32, "big")
+
+
+def ecdsa_verify(point, digest, r, s):
+    if not (0 < r < N and 0 < s < N) or point is None:
+        return False
+    z = int.from_bytes(digest, "big")
+    w = pow(s, -1, N)
+    q = _add(_mul(z * w % N, G), _mul(r * w % N, point))
+    return q is not None and q[0] % N == r
+
+
+def ecdsa_recover(digest, r, s, recid):
+    if not (0 < r < N and 0 < s < N):
+        return None
+    rp = _lift_x(r, recid & 1)
+    if rp is None:
+        return None
+    z = int.from_bytes(digest, "big")
+    rinv = pow(r, -1, N)
+    return _add(_mul(s * rinv % N, rp), _mul((-z * rinv) % N, G))
+
+
+# --- Proof schemes ---
+
+def verify_eip191(proof):
+    sig = bytes.fromhex(proof["signature"].removeprefix("0x"))
+    if len(sig) != 65:
+        return "malformed signature"
+    msg = proof["statement"].encode()
+    digest = keccak256(b"\x19Ethereum Signed Message:\n" + str(len(msg)).encode() + msg)
+    v = sig[64] - 27 if sig[64] >= 27 else sig[64]
+    point = ecdsa_recover(digest, int.from_bytes(sig[:32], "big"), int.from_bytes(sig[32:64], "big"), v)
+    if point is None:
+        return "invalid signature"
+    raw = point[0].to_bytes(32, "big") + point[1].to_bytes(32, "big")
+    address = "0x" + keccak256(raw)[-20:].hex()
+    if address.lower() != proof["address"].lower():
+        return "signature was made by %s" % address
+    if proof.get("public_key") and compress(point).hex() != proof["public_key"].lower():
+        return "public key does not match the signature"
+    return None
+
+
+def verify_secp256k1(proof):
+    pub = bytes.fromhex(proof["public_key"])
+    point = decode_pubkey(pub)
+    if point is None:
+        return "malformed public key"
+    address = ripemd160(hashlib.sha256(pub).digest()).hex()
+    if address.lower() != proof["address"].lower():
+        return "public key does not belong to the address"
+    sig = bytes.fromhex(proof["signature"])
+    if len(sig) != 64:
+        return "malformed signature"
+    digest = hashlib.sha256(proof["statement"].encode()).digest()
+    if not ecdsa_verify(point, digest, int.from_bytes(sig[:32], "big"), int.from_bytes(sig[32:], "big")):
+        return "signature does not match the statement"
+    return None
+
+
+SCHEMES = {"eip191-personal-sign": verify_eip191, "secp256k1-sha256": verify_secp256k1}
+
+
+def main():
+    if len(sys.argv) != 2:
+        print(__doc__.strip())
+        return 2
+    with open(sys.argv[1], encoding="utf-8") as f:
+        bundle = json.load(f)
+
+    challenge = bundle.get("challenge", "")
+    print("Bundle: tag %s, %s, challenge %r, %d proof(s)" % (
+        bundle.get("tag"), bundle.get("date"), challenge, len(bundle.get("proofs", []))))
+    failures = 0
+    for proof in bundle.get("proofs", []):
+        lines = proof.get("statement", "").split("\n")
+        if "Address: " + proof.get("address", "") not in lines:
+            error = "statement does not name the address"
+        elif "Challenge: " + challenge not in lines:
+            error = "statement does not carry the bundle's challenge"
+        elif proof.get("scheme") not in SCHEMES:
+            error = "unsupported scheme %r" % proof.get("scheme")
+        else:
+            try:
+                error = SCHEMES[proof["scheme"]](proof)
+            except (KeyError, ValueError) as e:
+                error = "malformed proof: %s" % e
+        if error:
+            failures += 1
+            print("FAIL %s: %s" % (proof.get("address"), error))
+        else:
+            print("OK   %s" % proof["address"])
+    print("%d valid, %d invalid" % (len(bundle.get("proofs", [])) - failures, failures))
+    return 1 if failures else 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())
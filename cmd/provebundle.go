@@ -0,0 +1,236 @@
+// File: cmd/provebundle.go
+package cmd
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/keys"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+// bundleVerifyScript checks a bundle with nothing but Python 3
+//
+//go:embed prove_bundle_verify.py
+var bundleVerifyScript []byte
+
+var proveBundleTag string
+var proveBundleChallenge string
+var proveBundleLabel string
+var proveBundleDate string
+var proveBundleOut string
+
+// ProofBundle holds ownership proofs for every address of the wallets with a tag
+type ProofBundle struct {
+	Version   int           `json:"version"`
+	Tag       string        `json:"tag"`
+	Type      string        `json:"type"`
+	Label     string        `json:"label"`
+	Date      string        `json:"date"`
+	Challenge string        `json:"challenge"`
+	Proofs    []BundleProof `json:"proofs"`
+}
+
+// BundleProof is an ownership proof in a bundle; on its own it is a valid 'verify-proof' document
+type BundleProof struct {
+	Prefix string `json:"prefix"`
+	Index  int    `json:"index"`
+	Path   string `json:"path,omitempty"`
+	OwnershipProof
+}
+
+var proveBundleCmd = &cobra.Command{
+	Use:   "prove-bundle --tag <TAG> --challenge <TEXT> --label <LABEL>",
+	Short: "Proves ownership of every address of the wallets with a tag.",
+	Long: `Proves ownership of every address of the wallets with a tag.
+
+Every address of every wallet tagged <TAG> signs a statement like 'prove'
+does, extended with the auditor's challenge, for proof-of-reserves style
+attestations. The bundle directory holds:
+
+  bundle.json  all proofs with the tag, label, date and challenge
+  bundle.csv   one row per address, for spreadsheets
+  verify.py    a standalone verifier needing only Python 3
+
+Each entry of bundle.json is also a proof that 'verify-proof' accepts.
+Addresses without a private key (watch-only) are skipped and reported.
+
+Examples:
+  vault.module prove-bundle --tag treasury --challenge "audit-7f3a91" --label "Example Ltd reserves"
+  python3 proof-bundle-treasury/verify.py proof-bundle-treasury/bundle.json
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			tag := strings.ToLower(proveBundleTag)
+			if !tagRegex.MatchString(tag) {
+				return errors.NewInvalidInputError(proveBundleTag, "--tag must name a wallet tag")
+			}
+			label := strings.TrimSpace(proveBundleLabel)
+			if label == "" || len(label) > maxProofLabelLength || strings.ContainsAny(label, "\r\n") {
+				return errors.NewInvalidInputError(proveBundleLabel, fmt.Sprintf("--label must be a single line of 1 to %d characters", maxProofLabelLength))
+			}
+			challenge := strings.TrimSpace(proveBundleChallenge)
+			if challenge == "" || len(challenge) > maxProofLabelLength || strings.ContainsAny(challenge, "\r\n") {
+				return errors.NewInvalidInputError(proveBundleChallenge, fmt.Sprintf("--challenge must be a single line of 1 to %d characters", maxProofLabelLength))
+			}
+			date := proveBundleDate
+			if date == "" {
+				date = time.Now().UTC().Format(time.DateOnly)
+			} else if _, err := time.Parse(time.DateOnly, date); err != nil {
+				return errors.NewInvalidInputError(date, "--date must be YYYY-MM-DD")
+			}
+			out := proveBundleOut
+			if out == "" {
+				out = "proof-bundle-" + tag
+			}
+			if _, err := os.Stat(out); err == nil {
+				return errors.NewInvalidInputError(out, "output directory already exists")
+			}
+
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			prefixes := v.TaggedPrefixes(tag)
+			if len(prefixes) == 0 {
+				return errors.NewInvalidInputError(tag, fmt.Sprintf("no wallet in vault '%s' has this tag", config.Cfg.ActiveVault))
+			}
+
+			bundle := ProofBundle{Version: proofVersion, Tag: tag, Type: activeVault.Type, Label: label, Date: date, Challenge: challenge}
+			skipped := 0
+			for _, prefix := range prefixes {
+				wallet := v[prefix]
+				if wallet.Kind == vault.KindConsensus {
+					skipped += len(wallet.Addresses)
+					fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("Skipping %s: consensus keys sign blocks, not ownership proofs.", prefix), colors.Warning))
+					continue
+				}
+				if err := checkWalletNotFrozen("prove-bundle", prefix, wallet); err != nil {
+					return err
+				}
+				if err := openWalletEnvelope("prove-bundle", prefix, &wallet); err != nil {
+					return err
+				}
+				v[prefix] = wallet
+				for i := range wallet.Addresses {
+					addr := &wallet.Addresses[i]
+					if addr.PrivateKey == nil || addr.PrivateKey.IsEmpty() {
+						skipped++
+						fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("Skipping %s [%d] %s: no private key.", prefix, addr.Index, addr.Address), colors.Warning))
+						continue
+					}
+					statement := ownershipStatement(activeVault.Type, addr, label, date) + "\nChallenge: " + challenge
+					scheme, signature, publicKey, err := keys.SignStatement(activeVault.Type, addr.PrivateKey.String(), statement)
+					if err != nil {
+						return errors.NewWalletInvalidError(prefix, err.Error())
+					}
+					bundle.Proofs = append(bundle.Proofs, BundleProof{
+						Prefix: prefix,
+						Index:  addr.Index,
+						Path:   addr.Path,
+						OwnershipProof: OwnershipProof{
+							Version:   proofVersion,
+							Type:      activeVault.Type,
+							Address:   addr.Address,
+							Statement: statement,
+							Scheme:    scheme,
+							Signature: signature,
+							PublicKey: publicKey,
+						},
+					})
+				}
+			}
+			if len(bundle.Proofs) == 0 {
+				return errors.NewInvalidInputError(tag, "no address with this tag has a private key")
+			}
+
+			if err := writeProofBundle(out, bundle); err != nil {
+				return err
+			}
+
+			audit.Logger.Info("Ownership proof bundle created",
+				slog.String("command", "prove-bundle"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("tag", tag),
+				slog.Int("proofs", len(bundle.Proofs)),
+				slog.Int("skipped", skipped),
+				slog.String("challenge", challenge),
+				slog.String("date", date),
+			)
+			fmt.Println(colors.SafeColor(fmt.Sprintf("%d proof(s) for tag '%s' written to %s. Verify with: python3 %s %s",
+				len(bundle.Proofs), tag, out, filepath.Join(out, "verify.py"), filepath.Join(out, "bundle.json")), colors.Success))
+			return nil
+		})
+	},
+}
+
+// writeProofBundle creates the bundle directory with bundle.json, bundle.csv and verify.py
+func writeProofBundle(dir string, bundle ProofBundle) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.FromOSError(err, dir)
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return errors.New(errors.ErrCodeInternal, "failed to generate JSON").WithContext("marshal_error", err.Error())
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bundle.json"), append(data, '\n'), 0644); err != nil {
+		return errors.FromOSError(err, filepath.Join(dir, "bundle.json"))
+	}
+
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	_ = w.Write([]string{"prefix", "index", "address", "type", "path", "scheme", "public_key", "signature", "statement"})
+	for _, p := range bundle.Proofs {
+		_ = w.Write([]string{p.Prefix, strconv.Itoa(p.Index), p.Address, p.Type, p.Path, p.Scheme, p.PublicKey, p.Signature, p.Statement})
+	}
+	w.Flush()
+	if err := os.WriteFile(filepath.Join(dir, "bundle.csv"), []byte(b.String()), 0644); err != nil {
+		return errors.FromOSError(err, filepath.Join(dir, "bundle.csv"))
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "verify.py"), bundleVerifyScript, 0755); err != nil {
+		return errors.FromOSError(err, filepath.Join(dir, "verify.py"))
+	}
+	return nil
+}
+
+func init() {
+	proveBundleCmd.Flags().StringVar(&proveBundleTag, "tag", "", "Tag of the wallets to prove (required).")
+	proveBundleCmd.Flags().StringVar(&proveBundleChallenge, "challenge", "", "Challenge given by the verifier, included in every statement (required).")
+	proveBundleCmd.Flags().StringVar(&proveBundleLabel, "label", "", "Label bound to the addresses, e.g. the owner's name (required).")
+	proveBundleCmd.Flags().StringVar(&proveBundleDate, "date", "", "Date of the statements, YYYY-MM-DD (default: today, UTC).")
+	proveBundleCmd.Flags().StringVarP(&proveBundleOut, "out", "o", "", "Directory to create (default: proof-bundle-<TAG>).")
+	_ = proveBundleCmd.MarkFlagRequired("tag")
+	_ = proveBundleCmd.MarkFlagRequired("challenge")
+	_ = proveBundleCmd.MarkFlagRequired("label")
+}
@@ -0,0 +1,326 @@
+// File: cmd/provision.go
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var provisionKeys []string
+var provisionIndex int
+var provisionForce bool
+var provisionK8sName string
+var provisionK8sNamespace string
+var provisionK8sOutput string
+var provisionK8sSeal bool
+var provisionK8sCert string
+var provisionDockerDir string
+
+// Default key mappings when no --key flag is given
+var (
+	defaultK8sKeys    = []string{"private-key=privatekey", "address=address"}
+	defaultDockerKeys = []string{"private_key=privatekey", "address=address"}
+)
+
+var (
+	k8sKeyRegex    = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+	k8sNameRegex   = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
+	dockerKeyRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+)
+
+var provisionCmd = &cobra.Command{
+	Use:   "provision",
+	Short: "Renders wallet fields into deployment secrets (Kubernetes, Docker).",
+	Long: `Renders wallet fields into deployment secrets for controlled CI deployment of hot-wallet keys.
+
+Each --key flag maps a secret key to a wallet field as NAME=FIELD
+(address, privatekey, mnemonic, seed or secret). Every provisioning is recorded in the audit log.`,
+}
+
+var provisionK8sCmd = &cobra.Command{
+	Use:   "k8s-secret <PREFIX>",
+	Short: "Renders a Kubernetes Secret manifest, optionally sealed with kubeseal.",
+	Long: `Renders a Kubernetes Secret manifest from wallet fields.
+
+Unsealed manifests contain the secrets (base64, not encrypted) and are only
+written to a file given with --output. With --seal the manifest is passed
+through kubeseal and may be written to stdout.
+
+Examples:
+  vault.module provision k8s-secret A1 --name hot-wallet --output secret.yaml
+  vault.module provision k8s-secret A1 --name hot-wallet --namespace prod --seal > sealed.yaml
+  vault.module provision k8s-secret A1 --name signer --key SIGNER_KEY=privatekey --seal --kubeseal-cert pub.pem
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			prefix := args[0]
+			if !k8sNameRegex.MatchString(provisionK8sName) || len(provisionK8sName) > 253 {
+				return errors.NewInvalidInputError(provisionK8sName, "--name must be a valid Kubernetes resource name (lowercase letters, digits, '-' and '.')")
+			}
+			if provisionK8sNamespace != "" && !k8sNameRegex.MatchString(provisionK8sNamespace) {
+				return errors.NewInvalidInputError(provisionK8sNamespace, "--namespace must be a valid Kubernetes namespace")
+			}
+			if !provisionK8sSeal && provisionK8sOutput == "" {
+				return errors.NewInvalidInputError("--output", "unsealed manifests contain plaintext secrets and must be written to a file with --output")
+			}
+
+			values, err := provisionWalletFields(prefix, provisionKeyMappings(defaultK8sKeys), k8sKeyRegex)
+			if err != nil {
+				return err
+			}
+
+			manifest := renderK8sSecret(provisionK8sName, provisionK8sNamespace, values)
+			defer func() {
+				for i := range manifest {
+					manifest[i] = 0
+				}
+			}()
+
+			output := manifest
+			if provisionK8sSeal {
+				sealed, err := kubeseal(manifest)
+				if err != nil {
+					return err
+				}
+				output = sealed
+			}
+
+			if provisionK8sOutput == "" {
+				fmt.Print(string(output))
+			} else if err := writeProvisionFile(provisionK8sOutput, output); err != nil {
+				return err
+			}
+
+			audit.Logger.Warn("Wallet provisioned as Kubernetes secret",
+				slog.String("command", "provision k8s-secret"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("prefix", prefix),
+				slog.String("secret", provisionK8sName),
+				slog.String("keys", strings.Join(fieldMappingNames(values), ",")),
+				slog.Bool("sealed", provisionK8sSeal),
+				slog.String("output", provisionK8sOutput),
+			)
+
+			if provisionK8sOutput != "" && !programmaticMode {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Secret manifest '%s' written to %s", provisionK8sName, provisionK8sOutput), colors.Success))
+				if !provisionK8sSeal {
+					fmt.Println(colors.SafeColor("The manifest is not encrypted. Apply it and delete the file.", colors.Warning))
+				}
+			}
+			return nil
+		})
+	},
+}
+
+var provisionDockerCmd = &cobra.Command{
+	Use:   "docker-secret <PREFIX>",
+	Short: "Writes wallet fields as docker secret files.",
+	Long: `Writes each mapped wallet field to its own 0600 file in --dir, in the layout
+expected by "docker secret create <name> <file>" and compose file secrets.
+
+Examples:
+  vault.module provision docker-secret A1 --dir ./secrets
+  vault.module provision docker-secret A1 --dir ./secrets --key signer_key=privatekey --index 1
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			prefix := args[0]
+			if provisionDockerDir == "" {
+				return errors.NewInvalidInputError("--dir", "a directory for the secret files is required")
+			}
+
+			values, err := provisionWalletFields(prefix, provisionKeyMappings(defaultDockerKeys), dockerKeyRegex)
+			if err != nil {
+				return err
+			}
+
+			if err := os.MkdirAll(provisionDockerDir, 0700); err != nil {
+				return errors.FromOSError(err, provisionDockerDir)
+			}
+
+			var written []string
+			for _, name := range fieldMappingNames(values) {
+				path := filepath.Join(provisionDockerDir, name)
+				if err := writeProvisionFile(path, []byte(values[name])); err != nil {
+					return err
+				}
+				written = append(written, path)
+			}
+
+			audit.Logger.Warn("Wallet provisioned as docker secrets",
+				slog.String("command", "provision docker-secret"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("prefix", prefix),
+				slog.String("dir", provisionDockerDir),
+				slog.String("keys", strings.Join(fieldMappingNames(values), ",")),
+			)
+
+			if !programmaticMode {
+				for _, path := range written {
+					fmt.Println(colors.SafeColor("Written: "+path, colors.Success))
+				}
+				fmt.Println(colors.SafeColor("These files are not encrypted. Delete them once the secrets are created.", colors.Warning))
+			}
+			return nil
+		})
+	},
+}
+
+// provisionKeyMappings returns the --key flags, or the defaults if none were given
+func provisionKeyMappings(defaults []string) []string {
+	if len(provisionKeys) == 0 {
+		return defaults
+	}
+	return provisionKeys
+}
+
+// provisionWalletFields loads the active vault and resolves the mapped fields of a wallet,
+// enforcing the secret retrieval limits.
+func provisionWalletFields(prefix string, mappings []string, nameRegex *regexp.Regexp) (map[string]string, error) {
+	fields, err := parseFieldMappings("--key", mappings, nameRegex)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkVaultStatus(); err != nil {
+		return nil, err
+	}
+
+	activeVault, err := config.GetActiveVault()
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := vault.LoadVault(activeVault)
+	if err != nil {
+		return nil, errors.NewVaultLoadError(activeVault.KeyFile, err)
+	}
+	defer func() {
+		for _, wallet := range v {
+			wallet.Clear()
+		}
+	}()
+
+	wallet, exists := v[prefix]
+	if !exists {
+		return nil, errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
+	}
+
+	if hasSecretField(fields) {
+		if err := openWalletEnvelope("provision", prefix, &wallet); err != nil {
+			return nil, err
+		}
+		v[prefix] = wallet
+	}
+	values, hasSecrets, err := resolveWalletFields(wallet, prefix, provisionIndex, fields)
+	if err != nil {
+		return nil, err
+	}
+	if hasSecrets {
+		if err := checkWalletNotFrozen("provision", prefix, wallet); err != nil {
+			return nil, err
+		}
+		if err := checkWalletSecretRateLimit("provision", prefix, wallet); err != nil {
+			return nil, err
+		}
+		recordWalletAccess(activeVault, v, prefix, strings.Join(fieldMappingNames(fields), ","))
+	}
+	return values, nil
+}
+
+// renderK8sSecret renders an Opaque Secret manifest with base64-encoded data
+func renderK8sSecret(name, namespace string, values map[string]string) []byte {
+	var b bytes.Buffer
+	b.WriteString("apiVersion: v1\n")
+	b.WriteString("kind: Secret\n")
+	b.WriteString("metadata:\n")
+	fmt.Fprintf(&b, "  name: %s\n", name)
+	if namespace != "" {
+		fmt.Fprintf(&b, "  namespace: %s\n", namespace)
+	}
+	b.WriteString("  labels:\n")
+	b.WriteString("    app.kubernetes.io/managed-by: vault.module\n")
+	b.WriteString("type: Opaque\n")
+	b.WriteString("data:\n")
+	for _, key := range fieldMappingNames(values) {
+		fmt.Fprintf(&b, "  %s: %s\n", key, base64.StdEncoding.EncodeToString([]byte(values[key])))
+	}
+	return b.Bytes()
+}
+
+// kubeseal encrypts a Secret manifest into a SealedSecret
+func kubeseal(manifest []byte) ([]byte, error) {
+	if _, err := exec.LookPath("kubeseal"); err != nil {
+		return nil, errors.NewDependencyError("kubeseal", "Please install kubeseal: https://github.com/bitnami-labs/sealed-secrets")
+	}
+
+	args := []string{"--format", "yaml"}
+	if provisionK8sCert != "" {
+		args = append(args, "--cert", provisionK8sCert)
+	}
+	cmd := exec.Command("kubeseal", args...)
+	cmd.Stdin = bytes.NewReader(manifest)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.New(errors.ErrCodeSystem, "kubeseal failed to seal the secret").
+			WithContext("error", strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// writeProvisionFile writes data with 0600 permissions, refusing to overwrite without --force
+func writeProvisionFile(path string, data []byte) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if provisionForce {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return errors.NewInvalidInputError(path, "file already exists; use --force to overwrite")
+		}
+		return errors.FromOSError(err, path)
+	}
+	defer f.Close()
+	if err := f.Chmod(0600); err != nil {
+		return errors.FromOSError(err, path)
+	}
+	if _, err := f.Write(data); err != nil {
+		return errors.FromOSError(err, path)
+	}
+	return nil
+}
+
+func init() {
+	provisionCmd.PersistentFlags().StringArrayVar(&provisionKeys, "key", nil, "Map a secret key to a wallet field as NAME=FIELD (repeatable).")
+	provisionCmd.PersistentFlags().IntVar(&provisionIndex, "index", 0, "Index of the address used for address/privatekey fields.")
+	provisionCmd.PersistentFlags().BoolVar(&provisionForce, "force", false, "Overwrite existing output files.")
+
+	provisionK8sCmd.Flags().StringVar(&provisionK8sName, "name", "", "Name of the Kubernetes Secret (required).")
+	provisionK8sCmd.Flags().StringVar(&provisionK8sNamespace, "namespace", "", "Namespace of the Secret.")
+	provisionK8sCmd.Flags().StringVarP(&provisionK8sOutput, "output", "o", "", "File to write the manifest to (required unless --seal).")
+	provisionK8sCmd.Flags().BoolVar(&provisionK8sSeal, "seal", false, "Encrypt the manifest with kubeseal into a SealedSecret.")
+	provisionK8sCmd.Flags().StringVar(&provisionK8sCert, "kubeseal-cert", "", "Public certificate passed to kubeseal --cert (offline sealing).")
+	_ = provisionK8sCmd.MarkFlagRequired("name")
+
+	provisionDockerCmd.Flags().StringVar(&provisionDockerDir, "dir", "", "Directory to write the secret files to (required).")
+}
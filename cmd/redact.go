@@ -0,0 +1,69 @@
+// File: cmd/redact.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+	"vault.module/internal/vault"
+)
+
+// Secrets reach the terminal only through this file and revealscreen.go:
+// maskSecret and maskMnemonic render the hints shown by default, printSecret
+// prints a secret in full where the command's output is the secret, and
+// revealOnScreen shows one on a screen that locks. New output of secret
+// material goes through them, so no_echo_secrets and masking hold everywhere.
+
+// refuseSecretEcho blocks printing secret material when no_echo_secrets is set, for
+// machines whose terminal sessions are recorded (asciinema, auditd tty logging)
+func refuseSecretEcho(command string) error {
+	if !config.Cfg.NoEchoSecrets {
+		return nil
+	}
+	audit.Logger.Warn("Secret output refused by no_echo_secrets", slog.String("command", command))
+	return errors.New(errors.ErrCodePermission, "printing secrets is disabled by no_echo_secrets").
+		WithDetails("use the clipboard, or deliver the secret with 'get --out-fd' / 'get --out-fifo'")
+}
+
+// maskSecret renders a secret as its first and last three characters. Hints
+// are partial secrets, so no_echo_secrets hides them: the result is then "",
+// as it is for secrets too short to hint at.
+func maskSecret(secret *security.SecureString) string {
+	if secret == nil || config.Cfg.NoEchoSecrets {
+		return ""
+	}
+	return secret.WithValueSync(func(value string) string {
+		if len(value) < 6 {
+			return ""
+		}
+		return value[:3] + "..." + value[len(value)-3:]
+	})
+}
+
+// maskMnemonic renders the mnemonic of a wallet as its first and last word,
+// or "" under no_echo_secrets
+func maskMnemonic(wallet vault.Wallet) string {
+	if config.Cfg.NoEchoSecrets {
+		return ""
+	}
+	return wallet.GetMnemonicHint()
+}
+
+// printSecret prints a secret in full on stdout, with a newline unless the
+// output is for a program. It refuses under no_echo_secrets.
+func printSecret(command, value string, newline bool) error {
+	if err := refuseSecretEcho(command); err != nil {
+		return err
+	}
+	if newline && !strings.HasSuffix(value, "\n") {
+		fmt.Println(value)
+	} else {
+		fmt.Print(value)
+	}
+	return nil
+}
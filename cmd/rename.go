@@ -11,6 +11,7 @@ import (
 	"vault.module/internal/config"
 	"vault.module/internal/errors"
 	"vault.module/internal/vault"
+	"vault.module/internal/webhook"
 
 	"github.com/spf13/cobra"
 )
@@ -55,17 +56,12 @@ Examples:
 				}
 			}()
 			
-			if _, exists := v[oldPrefix]; !exists {
-				return errors.NewWalletNotFoundError(oldPrefix, config.Cfg.ActiveVault)
-			}
-
-			// Validate the new prefix
-			if err := actions.ValidatePrefix(newPrefix); err != nil {
-				return errors.NewInvalidPrefixError(newPrefix, err.Error())
+			policy, err := activePrefixPolicy()
+			if err != nil {
+				return err
 			}
-
-			if _, exists := v[newPrefix]; exists {
-				return errors.NewWalletExistsError(newPrefix)
+			if err := actions.ValidateRename(v, config.Cfg.ActiveVault, oldPrefix, newPrefix, policy); err != nil {
+				return err
 			}
 			
 			if !renameYesFlag {
@@ -79,12 +75,14 @@ Examples:
 				}
 			}
 			
-			v[newPrefix] = v[oldPrefix]
-			delete(v, oldPrefix)
+			if err := actions.RenameWallet(v, config.Cfg.ActiveVault, oldPrefix, newPrefix, policy); err != nil {
+				return err
+			}
 			
 			if err := vault.SaveVault(activeVault, v); err != nil {
 				return errors.NewVaultSaveError(activeVault.KeyFile, err)
 			}
+			notifyVaultMutation(webhook.EventWalletRenamed, newPrefix, fmt.Sprintf("renamed from '%s'", oldPrefix))
 			
 			fmt.Printf("Wallet '%s' renamed to '%s'.\n", oldPrefix, newPrefix)
 			return nil
@@ -0,0 +1,219 @@
+// File: cmd/revealscreen.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+	"vault.module/internal/vault"
+)
+
+// Keys of the reveal screen
+const (
+	keyCtrlC = 0x03
+	keyCtrlL = 0x0c
+	keyEnter = '\r'
+	keyEsc   = 0x1b
+)
+
+// Actions of a key press on the reveal screen
+const (
+	revealQuit = iota
+	revealLock
+	revealUnlock
+)
+
+// revealTTY is the terminal of a reveal screen, in raw mode while keys are read
+type revealTTY struct {
+	file  *os.File
+	fd    int
+	mu    sync.Mutex
+	state *term.State // Cooked mode to restore while in raw mode
+}
+
+// openRevealTTY opens the controlling terminal. The file is opened rather than
+// taken from stdin so reads can time out.
+func openRevealTTY() (*revealTTY, error) {
+	file, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := file.SyscallConn()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	// Fd would switch the file to blocking mode and disable read deadlines
+	var fd int
+	if err := conn.Control(func(f uintptr) { fd = int(f) }); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if !term.IsTerminal(fd) {
+		file.Close()
+		return nil, fmt.Errorf("/dev/tty is not a terminal")
+	}
+	return &revealTTY{file: file, fd: fd}, nil
+}
+
+// restore leaves the alternate screen, blanking it, and cooked terminal mode
+func (t *revealTTY) restore() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.state != nil {
+		if err := term.Restore(t.fd, t.state); err != nil {
+			return err
+		}
+		t.state = nil
+	}
+	_, err := fmt.Fprint(t.file, "\033[H\033[2J\033[?1049l")
+	return err
+}
+
+// draw clears the screen and prints lines. Raw mode needs explicit carriage returns.
+func (t *revealTTY) draw(lines ...string) {
+	fmt.Fprint(t.file, "\033[H\033[2J")
+	for _, line := range lines {
+		fmt.Fprint(t.file, line+"\r\n")
+	}
+}
+
+// makeRaw puts the terminal in raw mode, to read single key presses
+func (t *revealTTY) makeRaw() error {
+	state, err := term.MakeRaw(t.fd)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.state = state
+	t.mu.Unlock()
+	return nil
+}
+
+// restoreMode returns the terminal to cooked mode, if it is raw
+func (t *revealTTY) restoreMode() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.state != nil {
+		term.Restore(t.fd, t.state)
+		t.state = nil
+	}
+}
+
+// waitKey waits for a key press, at most idle (0 waits forever), and returns
+// what it asks for. A timeout locks the screen.
+func (t *revealTTY) waitKey(idle time.Duration, locked bool) int {
+	if err := t.makeRaw(); err != nil {
+		return revealQuit
+	}
+	defer t.restoreMode()
+
+	var deadline time.Time
+	if idle > 0 {
+		deadline = time.Now().Add(idle)
+	}
+	if err := t.file.SetReadDeadline(deadline); err != nil && idle > 0 {
+		// No deadlines on this terminal: never leave a secret up unattended
+		return revealQuit
+	}
+	buf := make([]byte, 16)
+	for {
+		n, err := t.file.Read(buf)
+		if os.IsTimeout(err) {
+			return revealLock
+		}
+		if err != nil {
+			return revealQuit
+		}
+		for _, key := range buf[:n] {
+			switch {
+			case key == 'q' || key == 'Q' || key == keyEsc || key == keyCtrlC:
+				return revealQuit
+			case key == keyCtrlL && !locked:
+				return revealLock
+			case key == keyEnter && locked:
+				return revealUnlock
+			case key == keyEnter:
+				return revealQuit
+			}
+		}
+	}
+}
+
+// revealOnScreen shows a secret on the terminal's alternate screen, so it never
+// reaches the scrollback. Ctrl+L, or no key press for reveal_idle_lock seconds,
+// locks the screen: the secret is blanked and showing it again decrypts the
+// vault anew, which needs the YubiKey (PIN, touch) or other vault key. Without
+// a terminal the secret is printed as before.
+func revealOnScreen(command, prefix, field, secret string) error {
+	tty, err := openRevealTTY()
+	if err != nil {
+		return printSecret(command, secret, true)
+	}
+	defer tty.file.Close()
+
+	idle := time.Duration(config.GetRevealIdleLock()) * time.Second
+	fmt.Fprint(tty.file, "\033[?1049h")
+	defer fmt.Fprint(tty.file, "\033[H\033[2J\033[?1049l")
+
+	// A signal must not leave the secret on screen or the terminal raw
+	restore := security.RegisterFuncGlobal("reveal screen", security.PriorityTerminal, tty.restore)
+	defer security.UnregisterGlobal(restore)
+
+	for {
+		tty.draw(
+			colors.SafeColor(fmt.Sprintf("%s of '%s'", field, prefix), colors.Bold),
+			"",
+			secret,
+			"",
+			colors.SafeColor(fmt.Sprintf("Enter or q closes, Ctrl+L locks. Locks after %s without a key press.", idle), colors.Dim),
+		)
+		action := tty.waitKey(idle, false)
+		if action == revealQuit {
+			return nil
+		}
+
+		tty.draw(
+			colors.SafeColor("Locked.", colors.Bold),
+			"",
+			fmt.Sprintf("Press Enter to unlock %s of '%s' with the vault's key, or q to close.", field, prefix),
+		)
+		audit.Logger.Info("Revealed secret locked", slog.String("command", command), slog.String("prefix", prefix), slog.String("field", field))
+		if tty.waitKey(0, true) != revealUnlock {
+			return nil
+		}
+		if err := reauthenticateReveal(tty); err != nil {
+			return err
+		}
+		audit.Logger.Warn("Revealed secret unlocked", slog.String("command", command), slog.String("prefix", prefix), slog.String("field", field))
+	}
+}
+
+// reauthenticateReveal decrypts the active vault again to prove the user still
+// holds its key, on the normal screen so the key's prompts are visible
+func reauthenticateReveal(tty *revealTTY) error {
+	fmt.Fprint(tty.file, "\033[H\033[2J\033[?1049l")
+	defer fmt.Fprint(tty.file, "\033[?1049h")
+
+	activeVault, err := config.GetActiveVault()
+	if err != nil {
+		return err
+	}
+	v, err := vault.LoadVault(activeVault)
+	if err != nil {
+		return errors.NewVaultLoadError(activeVault.KeyFile, err)
+	}
+	for _, wallet := range v {
+		wallet.Clear()
+	}
+	return nil
+}
@@ -6,17 +6,46 @@ import (
 	"log/slog"
 	"os"
 	"os/exec"
+	"sort"
+	"strings"
+	"time"
 
 	"vault.module/internal/audit"
+	"vault.module/internal/budget"
+	"vault.module/internal/colors"
 	"vault.module/internal/config"
+	"vault.module/internal/constants"
 	"vault.module/internal/errors"
+	"vault.module/internal/hooks"
+	"vault.module/internal/security"
+	"vault.module/internal/tasks"
+	"vault.module/internal/vault"
 
 	"github.com/spf13/cobra"
 )
 
 var programmaticMode bool
+var vaultOverride string
+var noColor bool
+var scrubReport bool
+var commandTimeout time.Duration
+var timedCommand string // Command path bounded by --timeout, for its TIMEOUT error
+var configLoaded bool   // config.json was read by Execute, to expand an alias
 
-// checkDependencies checks for the availability and functionality of required external tools
+// Startup reads config.json and looks up age and its plugins through these,
+// so that tests can check which commands do either
+var (
+	loadConfig = config.LoadConfig
+	lookPath   = exec.LookPath
+)
+
+// timeoutGrace is how long operations get, once --timeout has run out, to
+// stop on their own before the command is ended
+const timeoutGrace = 5 * time.Second
+
+// checkDependencies checks for the availability and functionality of required external tools:
+// age and the plugins of every configured vault. It runs each of them, so only 'doctor' calls
+// it; other commands look the tools up with checkCommandDependencies.
 func checkDependencies() error {
 	// Check for age availability and basic functionality
 	if _, err := exec.LookPath("age"); err != nil {
@@ -28,19 +57,79 @@ func checkDependencies() error {
 		return errors.NewDependencyError("age", "age command is not working properly").WithContext("test_error", err.Error())
 	}
 
-	// Check for age-plugin-yubikey availability
-	if _, err := exec.LookPath("age-plugin-yubikey"); err != nil {
-		return errors.NewDependencyError("age-plugin-yubikey", "Please install age-plugin-yubikey: https://github.com/str4d/age-plugin-yubikey")
-	}
-	
-	// Test age-plugin-yubikey basic functionality
-	if err := testAgePluginYubikeyCommand(); err != nil {
-		return errors.NewDependencyError("age-plugin-yubikey", "age-plugin-yubikey is not working properly").WithContext("test_error", err.Error())
+	// Check the plugins required by the configured vaults
+	for _, plugin := range requiredPlugins() {
+		if _, err := exec.LookPath(plugin); err != nil {
+			return errors.NewDependencyError(plugin, pluginInstallHint(plugin))
+		}
+
+		// Test plugin basic functionality
+		if err := testPluginCommand(plugin); err != nil {
+			return errors.NewDependencyError(plugin, fmt.Sprintf("%s is not working properly", plugin)).WithContext("test_error", err.Error())
+		}
 	}
 
 	return nil
 }
 
+// checkCommandDependencies looks up age and the plugin of the vault the command works on,
+// without running them: one process per tool and configured vault made every command start
+// slowly. The vault package looks the tools up again before it runs them.
+func checkCommandDependencies() error {
+	if _, err := lookPath("age"); err != nil {
+		return errors.NewDependencyError("age", "Please install age: https://github.com/FiloSottile/age")
+	}
+	plugins := requiredPlugins()
+	if details, err := config.GetActiveVault(); err == nil {
+		plugins = nil
+		if plugin := config.PluginForEncryption(details.Encryption); plugin != "" {
+			plugins = append(plugins, plugin)
+		}
+	}
+	for _, plugin := range plugins {
+		if _, err := lookPath(plugin); err != nil {
+			return errors.NewDependencyError(plugin, pluginInstallHint(plugin)).
+				WithDetails("run 'vault.module doctor' to check all dependencies")
+		}
+	}
+	return nil
+}
+
+// requiredPlugins returns the age plugins needed by the configured vaults.
+// YubiKey is the default backend, so its plugin is required when no vault is configured yet.
+func requiredPlugins() []string {
+	seen := make(map[string]bool)
+	plugins := []string{}
+	for _, details := range config.Cfg.Vaults {
+		plugin := config.PluginForEncryption(details.Encryption)
+		if plugin != "" && !seen[plugin] {
+			seen[plugin] = true
+			plugins = append(plugins, plugin)
+		}
+	}
+	if len(config.Cfg.Vaults) == 0 {
+		plugins = append(plugins, constants.PluginYubiKey)
+	}
+	sort.Strings(plugins)
+	return plugins
+}
+
+// pluginInstallHint returns installation guidance for an age plugin
+func pluginInstallHint(plugin string) string {
+	switch plugin {
+	case constants.PluginYubiKey:
+		return "Please install age-plugin-yubikey: https://github.com/str4d/age-plugin-yubikey"
+	case constants.PluginFIDO2:
+		return "Please install age-plugin-fido2-hmac: https://github.com/olastor/age-plugin-fido2-hmac"
+	case constants.PluginSE:
+		return "Please install age-plugin-se: https://github.com/remko/age-plugin-se"
+	case constants.ToolTPM2:
+		return "Please install tpm2-tools: https://github.com/tpm2-software/tpm2-tools"
+	default:
+		return fmt.Sprintf("Please install %s", plugin)
+	}
+}
+
 // testAgeCommand tests if age command is working properly
 func testAgeCommand() error {
 	cmd := exec.Command("age", "--version")
@@ -50,15 +139,69 @@ func testAgeCommand() error {
 	return nil
 }
 
-// testAgePluginYubikeyCommand tests if age-plugin-yubikey command is working properly
-func testAgePluginYubikeyCommand() error {
-	cmd := exec.Command("age-plugin-yubikey", "--version")
+// testPluginCommand tests if an age plugin command is working properly
+func testPluginCommand(plugin string) error {
+	cmd := exec.Command(plugin, "--version")
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to run 'age-plugin-yubikey --version': %v", err) 
+		return fmt.Errorf("failed to run '%s --version': %v", plugin, err) 
 	}
 	return nil
 }
 
+// noDependencyCommands run without checking for age and its plugins, keyed
+// by command path
+var noDependencyCommands = map[string]bool{
+	"vault.module":        true,
+	"help":                true,
+	"audit-stream":        true,
+	"doctor":              true,
+	"scrub-history":       true,
+	"convert":             true,
+	"schema":              true,
+	"verify-proof":        true,
+	"verify-binary":       true,
+	"tour":                true,
+	"airgap send":         true,
+	"airgap receive":      true,
+	"vaults list":         true,
+	"vaults templates":    true,
+	"vaults trash":        true,
+	"vaults purge":        true,
+	"vaults trust":        true,
+	"stats usage":         true,
+	"inheritance checkin": true,
+	"inheritance status":  true,
+	"leaks status":        true,
+	"leaks update":        true,
+	"generate password":   true, // unless --store
+	"generate hex":        true, // unless --store
+	"generate mnemonic":   true, // unless --store
+	"generate wizard":     true, // unless --store
+	"approvals reject":    true,
+	"approvals result":    true,
+	"approvals token":     true,
+	"alias list":          true,
+	"alias set":           true,
+	"alias remove":        true,
+	"audit export":        true,
+	"audit verify":        true,
+	"audit rotate":        true,
+	"hooks list":          true,
+	"hooks test":          true,
+	"labels import":       true,
+	"labels list":         true,
+	"labels remove":       true,
+}
+
+// listingCommands only print configuration. They start without the audit log,
+// its rotation, the memory exposure check and usage statistics.
+var listingCommands = map[string]bool{
+	"vaults list": true,
+	"alias list":  true,
+	"labels list": true,
+	"hooks list":  true,
+}
+
 var rootCmd = &cobra.Command{
 	Use:                   "vault.module",
 	Short:                 "A secure CLI manager for crypto keys with YubiKey support.",
@@ -74,14 +217,25 @@ var rootCmd = &cobra.Command{
 		return nil
 	},
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		// Check dependencies only for commands that use them
-		if cmd.Use != "vault.module" && cmd.Use != "help" {
-			if err := checkDependencies(); err != nil {
-				return err
-			}
+		if noColor {
+			colors.Disable()
 		}
-
-		if err := audit.InitLogger(); err != nil {
+		if scrubReport {
+			security.GetManager().EnableScrubReport()
+		}
+		if err := startCommandBudget(cmd); err != nil {
+			return err
+		}
+		// Tools wrapping the programmatic and JSON modes read progress as
+		// newline-delimited JSON events on stderr, not the progress line
+		if programmaticMode || jsonOutput(cmd) {
+			tasks.EnableEvents(os.Stderr)
+		}
+		path := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+		listing := listingCommands[path]
+		if listing {
+			audit.InitDiscardLogger()
+		} else if err := audit.InitLogger(); err != nil {
 			return errors.NewConfigLoadError("audit.log", err)
 		}
 		
@@ -89,45 +243,380 @@ var rootCmd = &cobra.Command{
 		if err := errors.InitWithAuditLogger(); err != nil {
 			return err
 		}
+		logSecretArgs(path)
 		
-		if err := config.LoadConfig(); err != nil {
-			return errors.NewConfigLoadError("config.json", err)
+		if !configLoaded {
+			endConfig := tasks.Stage("startup.config")
+			err := loadConfig()
+			endConfig(err)
+			if err != nil {
+				return errors.NewConfigLoadError("config.json", err)
+			}
+		}
+		colors.SetASCIIOnly(config.Cfg.ASCIIOnly)
+
+		// --vault, or VAULT_NAME, selects the vault for this invocation only
+		if vaultOverride == "" {
+			vaultOverride = os.Getenv("VAULT_NAME")
+		}
+		if vaultOverride != "" {
+			if err := config.OverrideActiveVault(vaultOverride); err != nil {
+				return err
+			}
+		}
+
+		if config.Cfg.Strict {
+			if err := applyStrictProfile(); err != nil {
+				return err
+			}
+		}
+
+		if !listing && path != "help" && path != "audit rotate" {
+			rotateAuditLog()
+		}
+
+		if err := applyVaultDefaults(cmd); err != nil {
+			return err
+		}
+		if err := configureEntropySources(); err != nil {
+			return err
+		}
+		if err := configurePassphraseEstimator(); err != nil {
+			return err
+		}
+
+		// mlock cannot keep secrets out of unencrypted swap areas or hibernation images
+		if path != "help" {
+			// Listings handle no secrets
+			if !listing {
+				exposures, err := security.CheckMemoryExposure()
+				if err != nil {
+					return err
+				}
+				for _, e := range exposures {
+					audit.Logger.Warn("Secrets may reach disk unencrypted", slog.String("kind", e.Kind), slog.String("detail", e.Detail))
+					fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("WARNING: %s (%s). Set memory_protection to \"strict\" to refuse running.", e.Detail, e.Kind), colors.Warning))
+				}
+			}
+			warnInheritanceOutdated(cmd)
+		}
+
+		// First run: the tour explains what the following output means
+		if err := maybeShowTour(cmd); err != nil {
+			return err
 		}
-		if cmd.Use != "vault.module" {
-			audit.Logger.Info("Command executed", slog.String("command", cmd.Use))
+
+		// Check dependencies only for commands that use them.
+		// Runs after config load because required plugins depend on configured vaults.
+		vault.HistoryCommand = path
+		hooks.Command = path
+		if !noDependencyCommands[path] || cmd.Flags().Changed("store") {
+			endDependencies := tasks.Stage("startup.dependencies")
+			err := checkCommandDependencies()
+			endDependencies(err)
+			if err != nil {
+				return err
+			}
+		}
+
+		if cmd.Use != "vault.module" && !listing {
+			recordUsage(cmd, path)
+			if config.Cfg.Operator != "" {
+				audit.Logger.Info("Command executed", slog.String("command", cmd.Use), slog.String("operator", config.Cfg.Operator))
+			} else {
+				audit.Logger.Info("Command executed", slog.String("command", cmd.Use))
+			}
 		}
 		return nil
 	},
 }
 
+// applyStrictProfile enforces the parts of the strict profile that apply to every command
+// and records the active profile in the audit log. The other subsystems read config.Cfg.Strict.
+func applyStrictProfile() error {
+	if programmaticMode {
+		return errors.New(errors.ErrCodePermission, "programmatic mode is disabled by the strict profile").
+			WithDetails("unset VAULT_MODULE_PROGRAMMATIC or set strict to false")
+	}
+	if err := security.VerifyMlock(); err != nil {
+		return errors.Wrap(errors.ErrCodePermission, "the strict profile requires locked memory", err).
+			WithDetails("raise RLIMIT_MEMLOCK (ulimit -l) or set strict to false").
+			WithSeverity(errors.SeverityCritical)
+	}
+
+	global, wallet := config.SecretRateLimits()
+	audit.Logger.Info("Strict profile active",
+		slog.Bool("clipboard", false),
+		slog.Bool("reveal_confirmation", true),
+		slog.Bool("fsync", true),
+		slog.Bool("mlock_required", true),
+		slog.Int("rate_limit_global", global),
+		slog.Int("rate_limit_wallet", wallet),
+		slog.Bool("programmatic_mode", false),
+		slog.String("memory_protection", config.MemoryProtectionMode()))
+	return nil
+}
+
+// jsonOutput reports whether the command was asked for JSON output
+func jsonOutput(cmd *cobra.Command) bool {
+	flag := cmd.Flags().Lookup("json")
+	return flag != nil && flag.Value.String() == "true"
+}
+
+// startCommandBudget bounds the command by --timeout, or VAULT_TIMEOUT.
+// Decryption, plugins, hook scripts and network calls stop when it runs out;
+// a command still running timeoutGrace later is ended with a TIMEOUT error.
+func startCommandBudget(cmd *cobra.Command) error {
+	if !cmd.Flags().Changed("timeout") {
+		if env := os.Getenv("VAULT_TIMEOUT"); env != "" {
+			d, err := time.ParseDuration(env)
+			if err != nil {
+				return errors.NewInvalidInputError(env, "VAULT_TIMEOUT must be a duration such as 90s or 5m")
+			}
+			commandTimeout = d
+		}
+	}
+	if commandTimeout < 0 {
+		return errors.NewInvalidInputError(commandTimeout.String(), "--timeout cannot be negative")
+	}
+	if commandTimeout == 0 {
+		return nil
+	}
+
+	budget.Set(commandTimeout)
+	timedCommand = strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	time.AfterFunc(commandTimeout+timeoutGrace, func() {
+		timeoutErr := errors.NewTimeoutError(timedCommand, commandTimeout.String())
+		audit.Logger.Error("Command timed out", slog.String("command", timedCommand), slog.String("timeout", commandTimeout.String()))
+		fmt.Fprintln(os.Stderr, "Error:", errors.FormatForUser(timeoutErr))
+		security.GetManager().Shutdown()
+		os.Exit(1)
+	})
+	return nil
+}
+
 func Execute() error {
-	return rootCmd.Execute()
+	if err := guardSecretArgs(); err != nil {
+		return err
+	}
+	// Aliases are expanded before dispatch, so config.json is read early here,
+	// unless the command is built in: --help and built-in commands read it once,
+	// in PersistentPreRunE. A config error is left for PersistentPreRunE to report.
+	if aliasPosition(os.Args[1:]) >= 0 && loadConfig() == nil {
+		configLoaded = true
+		args, ok, err := expandAlias(os.Args[1:])
+		if err != nil {
+			return err
+		}
+		if ok {
+			rootCmd.SetArgs(args)
+		}
+	}
+	err := rootCmd.Execute()
+	if err != nil && budget.Expired() {
+		// Whatever failed, it failed because the budget ran out
+		timeoutErr := errors.NewTimeoutError(timedCommand, budget.Timeout().String())
+		timeoutErr.Cause = err
+		return timeoutErr
+	}
+	return err
 }
 
 func init() {
+	vault.Warn = func(message string) {
+		fmt.Fprintln(os.Stderr, colors.SafeColor("WARNING: "+message, colors.Warning))
+	}
 	// Check if programmatic mode is enabled via environment variable
 	if os.Getenv("VAULT_MODULE_PROGRAMMATIC") == "1" {
 		programmaticMode = true
 	}
+	// TestScrubReport in main_test.go sets VAULT_SCRUB_REPORT=1 for the commands it runs
+	if os.Getenv("VAULT_SCRUB_REPORT") == "1" {
+		security.GetManager().EnableScrubReport()
+	}
+
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Print no ANSI colors (also NO_COLOR); \"ascii_only\" in config.json also drops emoji")
+	rootCmd.PersistentFlags().BoolVar(&scrubReport, "scrub-report", false, "Debug: report at exit which secrets, temp files and clipboard registrations were scrubbed; exit with status 1 if any leaked (env: VAULT_SCRUB_REPORT=1)")
+	rootCmd.PersistentFlags().DurationVar(&commandTimeout, "timeout", 0, "Fail with a TIMEOUT error if the command runs longer than this, e.g. 90s or 5m (env: VAULT_TIMEOUT)")
+	rootCmd.PersistentFlags().BoolVar(&allowSecretArgs, "allow-secret-args", false, "Run even if an argument looks like a private key or mnemonic, e.g. a hash; the arguments are still hidden from ps where possible")
+	rootCmd.PersistentFlags().StringVar(&vaultOverride, "vault", "", "Vault to operate on instead of the active vault (env: VAULT_NAME); config.json is not changed")
 
 	// Register all commands
 	rootCmd.AddCommand(addCmd)
+	rootCmd.AddCommand(aliasCmd)
+	rootCmd.AddCommand(airgapCmd)
+	rootCmd.AddCommand(approvalsCmd)
+	rootCmd.AddCommand(auditStreamCmd)
+	rootCmd.AddCommand(canaryCmd)
+	rootCmd.AddCommand(checklistCmd)
+	rootCmd.AddCommand(lintCmd)
 	rootCmd.AddCommand(cloneCmd)
 	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(consensusCmd)
+	rootCmd.AddCommand(convertCmd)
+	rootCmd.AddCommand(cosmosKeyringCmd)
+	rootCmd.AddCommand(dashboardCmd)
+	rootCmd.AddCommand(web3signerCmd)
 	rootCmd.AddCommand(deleteCmd)
 	rootCmd.AddCommand(deriveCmd)
+	rootCmd.AddCommand(treeCmd)
+	rootCmd.AddCommand(scanCmd)
+	rootCmd.AddCommand(discoverCmd)
+	rootCmd.AddCommand(pathSchemeCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(execCmd)
+	rootCmd.AddCommand(envelopeCmd)
 	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(freezeCmd)
+	rootCmd.AddCommand(generateCmd)
 	rootCmd.AddCommand(getCmd)
+	rootCmd.AddCommand(historyCmd)
 	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(inheritanceCmd)
+	rootCmd.AddCommand(leaksCmd)
 	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(proveCmd)
+	rootCmd.AddCommand(proveBundleCmd)
+	rootCmd.AddCommand(provisionCmd)
 	rootCmd.AddCommand(renameCmd)
+	rootCmd.AddCommand(schemaCmd)
+	rootCmd.AddCommand(scrubHistoryCmd)
+	rootCmd.AddCommand(secretCmd)
+	rootCmd.AddCommand(signCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(tagsCmd)
+	rootCmd.AddCommand(terraformBridgeCmd)
 	rootCmd.AddCommand(tokenCmd)
+	rootCmd.AddCommand(tourCmd)
+	rootCmd.AddCommand(unfreezeCmd)
+	rootCmd.AddCommand(archiveCmd)
+	rootCmd.AddCommand(unarchiveCmd)
 	rootCmd.AddCommand(notesCmd)
+	rootCmd.AddCommand(hooksCmd)
+	rootCmd.AddCommand(labelsCmd)
+	rootCmd.AddCommand(operatorsCmd)
+	rootCmd.AddCommand(ceremonyCmd)
 	rootCmd.AddCommand(vaultsCmd)
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditExportCmd)
+	auditCmd.AddCommand(auditVerifyCmd)
+	auditCmd.AddCommand(auditRotateCmd)
+	rootCmd.AddCommand(verifyProofCmd)
+	rootCmd.AddCommand(verifyBinaryCmd)
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.AddCommand(statsUsageCmd)
+
+	// Register alias subcommands
+	aliasCmd.AddCommand(aliasListCmd)
+	aliasCmd.AddCommand(aliasSetCmd)
+	aliasCmd.AddCommand(aliasRemoveCmd)
 
 	// Register vaults subcommands
 	vaultsCmd.AddCommand(vaultsListCmd)
 	vaultsCmd.AddCommand(vaultsAddCmd)
 	vaultsCmd.AddCommand(vaultsUseCmd)
 	vaultsCmd.AddCommand(vaultsDeleteCmd)
+	vaultsCmd.AddCommand(vaultsUndeleteCmd)
+	vaultsCmd.AddCommand(vaultsTrashCmd)
+	vaultsCmd.AddCommand(vaultsPurgeCmd)
+	vaultsCmd.AddCommand(vaultsWatchCmd)
+	vaultsCmd.AddCommand(vaultsVerifyCmd)
+	vaultsCmd.AddCommand(vaultsSignCmd)
+	vaultsCmd.AddCommand(vaultsPassphraseCmd)
+	vaultsCmd.AddCommand(vaultsAttestationCmd)
+	vaultsCmd.AddCommand(vaultsTrustCmd)
+	vaultsCmd.AddCommand(vaultsPublishCmd)
+	vaultsCmd.AddCommand(vaultsTemplatesCmd)
+	vaultsCmd.AddCommand(vaultsNamingCmd)
+
+	// Register operators subcommands
+	operatorsCmd.AddCommand(operatorsListCmd)
+	operatorsCmd.AddCommand(operatorsAddCmd)
+	operatorsCmd.AddCommand(operatorsRemoveCmd)
+	operatorsCmd.AddCommand(operatorsUseCmd)
+	operatorsCmd.AddCommand(operatorsGrantCmd)
+	operatorsCmd.AddCommand(operatorsRevokeCmd)
+
+	// Register canary subcommands
+	canaryCmd.AddCommand(canaryCreateCmd)
+	canaryCmd.AddCommand(canaryListCmd)
+	canaryCmd.AddCommand(canaryCheckCmd)
+
+	// Register airgap subcommands
+	airgapCmd.AddCommand(airgapSendCmd)
+	airgapCmd.AddCommand(airgapReceiveCmd)
+	airgapCmd.AddCommand(airgapSignCmd)
+	airgapCmd.AddCommand(airgapAccountCmd)
+
+	// Register sign subcommands
+	signCmd.AddCommand(signBatchCmd)
+
+	// Register ceremony subcommands
+	ceremonyCmd.AddCommand(ceremonyRunCmd)
+	ceremonyCmd.AddCommand(ceremonyVerifyCmd)
+
+	// Register approvals subcommands
+	approvalsCmd.AddCommand(approvalsListCmd)
+	approvalsCmd.AddCommand(approvalsReviewCmd)
+	approvalsCmd.AddCommand(approvalsRejectCmd)
+	approvalsCmd.AddCommand(approvalsResultCmd)
+	approvalsCmd.AddCommand(approvalsTokenCmd)
+
+	// Register generate subcommands
+	generateCmd.AddCommand(generatePasswordCmd)
+	generateCmd.AddCommand(generateHexCmd)
+	generateCmd.AddCommand(generateMnemonicCmd)
+	generateCmd.AddCommand(generateWizardCmd)
+
+	// Register secret subcommands
+	secretCmd.AddCommand(secretAddCmd)
+	secretCmd.AddCommand(secretGetCmd)
+	secretCmd.AddCommand(secretListCmd)
+
+	// Register envelope subcommands
+	envelopeCmd.AddCommand(envelopeSealCmd)
+	envelopeCmd.AddCommand(envelopeRemoveCmd)
+
+	// Register tags subcommands
+	tagsCmd.AddCommand(tagsAddCmd)
+	tagsCmd.AddCommand(tagsRemoveCmd)
+	tagsCmd.AddCommand(tagsListCmd)
+
+	// Register hooks subcommands
+	hooksCmd.AddCommand(hooksListCmd)
+	hooksCmd.AddCommand(hooksTestCmd)
+
+	// Register labels subcommands
+	labelsCmd.AddCommand(labelsImportCmd)
+	labelsCmd.AddCommand(labelsListCmd)
+	labelsCmd.AddCommand(labelsRemoveCmd)
+
+	// Register history subcommands
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historyWalletCmd)
+	historyCmd.AddCommand(historyAccessLogCmd)
+
+	// Register leaks subcommands
+	leaksCmd.AddCommand(leaksStatusCmd)
+	leaksCmd.AddCommand(leaksUpdateCmd)
+
+	// Register inheritance subcommands
+	inheritanceCmd.AddCommand(inheritancePrepareCmd)
+	inheritanceCmd.AddCommand(inheritanceCheckinCmd)
+	inheritanceCmd.AddCommand(inheritanceStatusCmd)
+
+	// Register consensus subcommands
+	consensusCmd.AddCommand(consensusImportCmd)
+	consensusCmd.AddCommand(consensusExportCmd)
+
+	// Register cosmos-keyring subcommands
+	cosmosKeyringCmd.AddCommand(cosmosKeyringImportCmd)
+	cosmosKeyringCmd.AddCommand(cosmosKeyringExportCmd)
+
+	// Register sync subcommands
+	syncCmd.AddCommand(syncKeystoreCmd)
+
+	// Register provision subcommands
+	provisionCmd.AddCommand(provisionK8sCmd)
+	provisionCmd.AddCommand(provisionDockerCmd)
 }
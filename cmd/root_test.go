@@ -0,0 +1,115 @@
+// File: cmd/root_test.go
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// startupRun counts what one invocation did before its command ran
+type startupRun struct {
+	configReads int
+	earlyConfig bool // config.json was read before dispatch, by Execute
+	lookups     []string
+	err         error
+	elapsed     time.Duration
+}
+
+// runStartup runs vault.module with args in dir, counting config reads and
+// lookups of age and its plugins. Lookups fail, so a command that needs a
+// plugin stops before it runs.
+func runStartup(t *testing.T, dir string, args ...string) startupRun {
+	t.Helper()
+	t.Chdir(dir)
+
+	var run startupRun
+	origArgs, origStdout := os.Args, os.Stdout
+	origLoad, origLook := loadConfig, lookPath
+	defer func() {
+		os.Args, os.Stdout = origArgs, origStdout
+		loadConfig, lookPath = origLoad, origLook
+		configLoaded = false
+	}()
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	os.Args, os.Stdout = append([]string{"vault.module"}, args...), devNull
+	loadConfig = func() error {
+		run.configReads++
+		return origLoad()
+	}
+	lookPath = func(file string) (string, error) {
+		run.lookups = append(run.lookups, file)
+		return "", exec.ErrNotFound
+	}
+
+	start := time.Now()
+	run.err = Execute()
+	run.elapsed = time.Since(start)
+	run.earlyConfig = configLoaded
+	return run
+}
+
+func startupConfigDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	config := `{"active_vault": "main", "tour_seen": true, "vaults": {"main": {"KeyFile": "` +
+		filepath.Join(dir, "main.key") + `", "RecipientsFile": "` + filepath.Join(dir, "recipients.txt") +
+		`", "Type": "evm", "Encryption": "yubikey"}}}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// TestStartupDispatch checks that --help and 'vaults list' reach their command
+// without reading config.json early or looking up age and its plugins, and
+// that 'vaults list' leaves the audit log alone
+func TestStartupDispatch(t *testing.T) {
+	dir := startupConfigDir(t)
+
+	t.Run("--help", func(t *testing.T) {
+		run := runStartup(t, dir, "--help")
+		if run.err != nil {
+			t.Fatalf("--help failed: %v", run.err)
+		}
+		if run.configReads != 0 || len(run.lookups) != 0 {
+			t.Fatalf("--help read config.json %d time(s) and looked up %v", run.configReads, run.lookups)
+		}
+		t.Logf("--help dispatched in %v", run.elapsed)
+	})
+
+	t.Run("vaults list", func(t *testing.T) {
+		run := runStartup(t, dir, "vaults", "list")
+		if run.err != nil {
+			t.Fatalf("vaults list failed: %v", run.err)
+		}
+		if run.earlyConfig {
+			t.Fatal("vaults list read config.json before dispatch")
+		}
+		if run.configReads != 1 {
+			t.Fatalf("vaults list read config.json %d times, want once", run.configReads)
+		}
+		if len(run.lookups) != 0 {
+			t.Fatalf("vaults list looked up %v", run.lookups)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "audit.log")); !os.IsNotExist(err) {
+			t.Fatalf("vaults list opened the audit log (%v)", err)
+		}
+		t.Logf("vaults list ran in %v", run.elapsed)
+	})
+
+	// The counters see the lookups of a command that needs age
+	t.Run("list", func(t *testing.T) {
+		run := runStartup(t, dir, "list")
+		if run.err == nil || len(run.lookups) == 0 || run.lookups[0] != "age" {
+			t.Fatalf("list = %v after looking up %v, want a missing age", run.err, run.lookups)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+// File: cmd/scan.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/canary"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/errors"
+	"vault.module/internal/keys"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var scanPaths string
+var scanCount int
+var scanRPC string
+
+var scanCmd = &cobra.Command{
+	Use:   "scan <PREFIX>",
+	Short: "Lists the addresses an HD wallet has under common derivation paths.",
+	Long: `Lists the addresses an HD wallet has under common derivation paths.
+
+Wallet software has not always agreed on derivation paths: Ledger Live counts
+accounts instead of addresses, old MyEtherWallet versions dropped a level, and
+other chains use their own coin type. If a restored mnemonic does not show the
+addresses you expect, scan shows where they went.
+
+--paths is 'common' or a comma-separated list of paths. {i} marks the component
+that counts up; a path without it counts up in a new last component.
+
+With --rpc each address is checked for on-chain activity: a JSON-RPC endpoint
+for EVM vaults (nonce and balance), a REST (LCD) endpoint for Cosmos vaults.
+Nothing is written to the vault.
+
+Examples:
+  vault.module scan A1
+  vault.module scan A1 --count 10 --rpc https://eth.llamarpc.com
+  vault.module scan A1 --paths "m/44'/60'/{i}'/0/0,m/44'/60'/0'/0"
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			if scanCount < 1 || scanCount > 100 {
+				return errors.NewInvalidInputError(fmt.Sprintf("%d", scanCount), "--count must be between 1 and 100")
+			}
+			paths, err := resolveScanPaths(activeVault.Type, scanPaths)
+			if err != nil {
+				return err
+			}
+
+			prefix := args[0]
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			wallet, exists := v[prefix]
+			if !exists {
+				return errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
+			}
+			if err := openWalletEnvelope("scan", prefix, &wallet); err != nil {
+				return err
+			}
+			if !wallet.IsHD() {
+				return errors.NewWalletInvalidError(prefix, "scanning is only possible for HD wallets (with a mnemonic or seed)")
+			}
+
+			// Addresses the wallet already holds, to mark them in the output
+			known := make(map[string]bool, len(wallet.Addresses))
+			for _, addr := range wallet.Addresses {
+				known[strings.ToLower(addr.Address)] = true
+			}
+
+			audit.Logger.Info("Scanning derivation paths", slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.Int("paths", len(paths)), slog.Int("count", scanCount), slog.Bool("online", scanRPC != ""))
+			fmt.Println(colors.SafeColor(
+				fmt.Sprintf("Active Vault: %s (Type: %s)", config.Cfg.ActiveVault, activeVault.Type),
+				colors.Info,
+			))
+
+			active := 0
+			for _, p := range paths {
+				fmt.Println()
+				fmt.Println(colors.SafeColor(fmt.Sprintf("%s  %s", p.Template, p.Name), colors.Bold))
+				for i := 0; i < scanCount; i++ {
+					path := p.Path(i)
+					address, err := keys.AddressAt(activeVault.Type, wallet, path)
+					if err != nil {
+						return errors.NewWalletInvalidError(prefix, fmt.Sprintf("derivation error at %s: %s", path, err.Error()))
+					}
+
+					line := fmt.Sprintf("  %-24s %s", path, address)
+					if known[strings.ToLower(address)] {
+						line += colors.SafeColor("  [IN VAULT]", colors.Success)
+					}
+					if scanRPC != "" {
+						activity, err := addressActivity(activeVault.Type, scanRPC, address)
+						switch {
+						case err != nil:
+							line += colors.SafeColor("  error: "+err.Error(), colors.Error)
+						case activity.Triggered:
+							active++
+							line += colors.SafeColor(fmt.Sprintf("  ACTIVE nonce %d, balance %s", activity.Nonce, activity.Balance), colors.Warning)
+						default:
+							line += "  unused"
+						}
+					}
+					fmt.Println(line)
+				}
+			}
+
+			if scanRPC != "" {
+				fmt.Println()
+				fmt.Println(colors.SafeColor(fmt.Sprintf("%d active address(es) found.", active), colors.Info))
+			}
+			return nil
+		})
+	},
+}
+
+// resolveScanPaths expands the --paths flag
+func resolveScanPaths(vaultType, flag string) ([]keys.ScanPath, error) {
+	if strings.TrimSpace(flag) == "" || flag == "common" {
+		paths := keys.CommonScanPaths(vaultType)
+		if len(paths) == 0 {
+			return nil, errors.NewInvalidInputError(vaultType, "no common derivation paths for this vault type")
+		}
+		return paths, nil
+	}
+	var paths []keys.ScanPath
+	for _, template := range strings.Split(flag, ",") {
+		p, err := keys.ParseScanPath(template)
+		if err != nil {
+			return nil, errors.NewInvalidInputError(template, err.Error())
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
+// addressActivity checks an address against an endpoint with the canary monitors
+func addressActivity(vaultType, endpoint, address string) (canary.Activity, error) {
+	if vaultType == constants.VaultTypeCosmos {
+		return canary.CheckCosmos(endpoint, address)
+	}
+	return canary.CheckEVM(endpoint, address)
+}
+
+func init() {
+	scanCmd.Flags().StringVar(&scanPaths, "paths", "common", "'common' or a comma-separated list of derivation paths, {i} marking the counter.")
+	scanCmd.Flags().IntVar(&scanCount, "count", 5, "Addresses to derive per path.")
+	scanCmd.Flags().StringVar(&scanRPC, "rpc", "", "Endpoint to check each address for activity (EVM JSON-RPC or Cosmos REST).")
+}
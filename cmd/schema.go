@@ -0,0 +1,107 @@
+// File: cmd/schema.go
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"vault.module/internal/colors"
+	"vault.module/internal/dashboard"
+	"vault.module/internal/errors"
+	"vault.module/internal/schema"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+// schemaDoc is a JSON document the tool reads or writes
+type schemaDoc struct {
+	value       interface{}
+	title       string
+	description string
+}
+
+// schemaDocs are the documents 'schema' describes, by name. The schemas are
+// generated from the types that are marshalled, so they follow the code.
+var schemaDocs = map[string]schemaDoc{
+	"vault": {
+		value:       vault.VaultHeader{},
+		title:       "Vault file",
+		description: "Decrypted content of a vault file. On disk it is age-encrypted.",
+	},
+	"export": {
+		value:       vault.Vault{},
+		title:       "Export",
+		description: "Output of 'export': wallets by prefix. Secrets are included in clear.",
+	},
+	"import": {
+		value:       vault.Vault{},
+		title:       "JSON import",
+		description: "Input of 'import --format json': wallets by prefix, as written by 'export'. The key-value format is described in 'schema --help'.",
+	},
+	"attestation": {
+		value:       vault.Attestation{},
+		title:       "Attestation",
+		description: "Signed statement about a vault, kept in the vault file and written by 'prove'.",
+	},
+	"api-vault": {
+		value:       dashboard.VaultResponse{},
+		title:       "Dashboard /api/vault",
+		description: "Vault snapshot served by 'dashboard'. It has no secrets.",
+	},
+	"api-audit": {
+		value:       []string{},
+		title:       "Dashboard /api/audit",
+		description: "Latest audit log lines served by 'dashboard', one JSON log record per string.",
+	},
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [NAME]",
+	Short: "Prints JSON Schemas of the vault, export, import and API formats.",
+	Long: `Prints JSON Schemas (draft 2020-12) of the JSON documents vault.module reads
+and writes, for validating files and generating clients. Without NAME it lists
+the schemas.
+
+The schemas are generated from the program's own types, so they match the
+version that prints them.
+
+The key-value import format (--format key-value) is not JSON. Each line is
+PREFIX=VALUE or PREFIX:VALUE, where VALUE is a private key or a mnemonic and
+may be quoted. Empty lines and lines starting with # are ignored.
+
+Examples:
+  vault.module schema
+  vault.module schema vault > vault.schema.json
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			names := make([]string, 0, len(schemaDocs))
+			for name := range schemaDocs {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			if len(args) == 0 {
+				fmt.Println(colors.SafeColor("Schemas:", colors.Bold))
+				for _, name := range names {
+					fmt.Printf("  %-12s %s\n", name, schemaDocs[name].title)
+				}
+				return nil
+			}
+
+			doc, ok := schemaDocs[args[0]]
+			if !ok {
+				return errors.NewInvalidInputError(args[0], fmt.Sprintf("unknown schema, choose one of: %v", names))
+			}
+			data, err := json.MarshalIndent(schema.Generate(doc.value, doc.title, doc.description), "", "  ")
+			if err != nil {
+				return errors.New(errors.ErrCodeInternal, "failed to generate JSON").WithContext("marshal_error", err.Error())
+			}
+			fmt.Println(string(data))
+			return nil
+		})
+	},
+}
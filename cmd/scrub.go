@@ -0,0 +1,95 @@
+// File: cmd/scrub.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/errors"
+	"vault.module/internal/scrub"
+
+	"github.com/spf13/cobra"
+)
+
+var scrubRedact bool
+var scrubYes bool
+
+var scrubHistoryCmd = &cobra.Command{
+	Use:   "scrub-history [FILE...]",
+	Short: "Finds private keys and mnemonics leaked into shell history.",
+	Long: `Finds private keys and mnemonics leaked into shell history.
+
+Scans the current user's shell history files, plus any files given as
+arguments (e.g. exported terminal scrollback), for strings that look like
+secp256k1 private keys (64 hex characters) or BIP-39 mnemonic phrases.
+The vault is not decrypted; matching is purely pattern based.
+
+With --redact every match is replaced by [REDACTED] in place. Restart open
+shells afterwards, as they rewrite history from memory on exit.
+
+vault.module refuses to run when its own arguments match: the secret is
+hidden from ps where the platform allows (Linux, macOS) and the command fails
+with directions to type it at a prompt. --allow-secret-args lets values that
+only look like secrets, such as hashes, through.
+
+Examples:
+  vault.module scrub-history
+  vault.module scrub-history ~/terminal-export.txt
+  vault.module scrub-history --redact
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			files := append(scrub.DefaultHistoryFiles(), args...)
+			if len(files) == 0 {
+				fmt.Println(colors.SafeColor("No history files found.", colors.Info))
+				return nil
+			}
+
+			if scrubRedact && !scrubYes && !programmaticMode {
+				if !askForConfirmation(colors.SafeColor("Matches will be overwritten in place. Continue?", colors.Warning)) {
+					fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+					return nil
+				}
+			}
+
+			total := 0
+			for _, file := range files {
+				findings, err := scrub.ScanFile(file, scrubRedact)
+				if err != nil {
+					return errors.FromOSError(err, file)
+				}
+				if len(findings) == 0 {
+					fmt.Printf("%s %s\n", colors.SafeColor("✓", colors.Success), file)
+					continue
+				}
+
+				total += len(findings)
+				fmt.Printf("%s %s: %d suspected secret(s)\n", colors.SafeColor("✗", colors.Error), file, len(findings))
+				for _, f := range findings {
+					fmt.Printf("    line %d: %s (%s)\n", f.Line, f.Kind, colors.Text(f.Preview))
+				}
+				audit.Logger.Warn("Suspected secrets found in history file",
+					slog.String("file", file),
+					slog.Int("count", len(findings)),
+					slog.Bool("redacted", scrubRedact))
+			}
+
+			switch {
+			case total == 0:
+				fmt.Println(colors.SafeColor("No suspected secrets found.", colors.Success))
+			case scrubRedact:
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Redacted %d suspected secret(s). Treat the affected keys as exposed.", total), colors.Warning))
+			default:
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Found %d suspected secret(s). Run with --redact to remove them.", total), colors.Warning))
+			}
+			return nil
+		})
+	},
+}
+
+func init() {
+	scrubHistoryCmd.Flags().BoolVar(&scrubRedact, "redact", false, "Replace matches with [REDACTED] in place.")
+	scrubHistoryCmd.Flags().BoolVar(&scrubYes, "yes", false, "Skip confirmation prompt when redacting.")
+}
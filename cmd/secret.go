@@ -0,0 +1,265 @@
+// File: cmd/secret.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+	"vault.module/internal/vault"
+	"vault.module/internal/webhook"
+
+	"github.com/spf13/cobra"
+)
+
+var secretType string
+var secretNotes string
+var secretGetCode bool
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manages generic secrets such as API keys and TOTP seeds.",
+	Long: `Manages generic secrets such as API keys and TOTP seeds.
+
+Secret entries live in the active vault next to the wallets and share their
+prefix namespace, encryption, audit trail, freezing and rate limits. They have
+no addresses: read them with 'secret get' or 'get <NAME> secret', and remove
+them with 'delete'.
+
+Types: password, api-key, totp, seed, generic. A totp entry accepts a base32
+seed or an otpauth:// URI, and 'secret get --code' yields the current
+one-time code instead of the seed.
+
+Examples:
+  vault.module secret add binance-api --type api-key --notes "read-only key"
+  vault.module secret add github-2fa --type totp
+  vault.module secret get binance-api
+  vault.module secret get github-2fa --code
+  vault.module secret list
+`,
+}
+
+var secretAddCmd = &cobra.Command{
+	Use:   "add <NAME>",
+	Short: "Adds a generic secret to the active vault.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("secret add")
+			}
+			name := args[0]
+			if err := validatePrefix(name); err != nil {
+				return err
+			}
+			kind := strings.ToLower(secretType)
+			if !isSecretType(kind) {
+				return errors.NewInvalidInputError(secretType, "--type must be one of: "+strings.Join(vault.SecretTypes, ", "))
+			}
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+			if _, exists := v[name]; exists {
+				return errors.NewWalletExistsError(name)
+			}
+
+			value, err := askForSecretInputWithCleanup(fmt.Sprintf("Enter the %s", kind))
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(value) == "" {
+				return errors.NewInvalidInputError("secret", "secret cannot be empty")
+			}
+			if kind == vault.SecretTypeTOTP {
+				if value, err = security.NormalizeTOTPSeed(value); err != nil {
+					return errors.NewInvalidInputError("totp seed", err.Error())
+				}
+			}
+
+			v[name] = vault.Wallet{
+				Kind:       vault.KindSecret,
+				SecretType: kind,
+				Secret:     security.NewSecureString(value),
+				Addresses:  []vault.Address{},
+				Notes:      secretNotes,
+			}
+			if err := vault.SaveVault(activeVault, v); err != nil {
+				return errors.NewVaultSaveError(activeVault.KeyFile, err)
+			}
+			notifyVaultMutation(webhook.EventWalletAdded, name, kind+" secret")
+
+			audit.Logger.Info("Secret added", slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", name), slog.String("type", kind))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Secret '%s' (%s) added to vault '%s'.", name, kind, config.Cfg.ActiveVault), colors.Success))
+			return nil
+		})
+	},
+}
+
+var secretGetCmd = &cobra.Command{
+	Use:   "get <NAME>",
+	Short: "Gets a generic secret from the active vault.",
+	Long: `Gets a generic secret from the active vault.
+
+The secret is delivered like 'get <NAME> secret': to the clipboard, or to
+stdout in programmatic mode. With --code, a totp entry yields its current
+one-time code instead of the seed.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if err := validateGetCommandInputs(); err != nil {
+				return err
+			}
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			name := args[0]
+			wallet, exists := v[name]
+			if !exists {
+				return errors.NewWalletNotFoundError(name, config.Cfg.ActiveVault)
+			}
+			if err := openWalletEnvelope("secret get", name, &wallet); err != nil {
+				return err
+			}
+			v[name] = wallet
+			if wallet.Kind != vault.KindSecret || wallet.Secret == nil || wallet.Secret.IsEmpty() {
+				return errors.NewWalletInvalidError(name, "wallet is not a generic secret entry")
+			}
+			if secretGetCode && wallet.SecretType != vault.SecretTypeTOTP {
+				return errors.NewInvalidInputError("--code", fmt.Sprintf("secret '%s' is of type %s, not totp", name, wallet.SecretType))
+			}
+			if err := checkWalletNotFrozen("secret get", name, wallet); err != nil {
+				return err
+			}
+			if err := checkSecretRateLimit(name); err != nil {
+				return err
+			}
+
+			if secretGetCode {
+				code, remaining, err := security.TOTPCode(wallet.Secret.String(), time.Now())
+				if err != nil {
+					return errors.NewWalletInvalidError(name, err.Error())
+				}
+				audit.Logger.Warn("Secret data accessed", slog.String("command", "secret get"), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", name), slog.String("field", "totp-code"))
+				recordWalletAccess(activeVault, v, name, "totp-code")
+				if !programmaticMode {
+					fmt.Println(colors.SafeColor(fmt.Sprintf("Valid for %d more seconds.", remaining), colors.Dim))
+				}
+				return emitGetResult("secret get", name, "totp-code", code, true)
+			}
+
+			audit.Logger.Warn("Secret data accessed", slog.String("command", "secret get"), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", name), slog.String("field", "secret"))
+			recordWalletAccess(activeVault, v, name, "secret")
+			return emitGetResult("secret get", name, "secret", wallet.Secret.String(), true)
+		})
+	},
+}
+
+var secretListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the generic secrets in the active vault without their values.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			names := make([]string, 0, len(v))
+			for name, wallet := range v {
+				if wallet.Kind == vault.KindSecret {
+					names = append(names, name)
+				}
+			}
+			if len(names) == 0 {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("No secrets in vault '%s'.", config.Cfg.ActiveVault), colors.Info))
+				return nil
+			}
+			sort.Strings(names)
+
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Secrets in '%s':", config.Cfg.ActiveVault), colors.Bold))
+			for _, name := range names {
+				wallet := v[name]
+				kind := wallet.SecretType
+				if kind == "" {
+					kind = vault.SecretTypeGeneric
+				}
+				fmt.Printf("- %s (%s)%s\n", colors.SafeColor(name, colors.White), colors.SafeColor(kind, colors.Yellow), frozenBadge(wallet))
+				if wallet.Notes != "" {
+					fmt.Printf("  Notes: %s\n", colors.SafeColor(wallet.Notes, colors.Dim))
+				}
+			}
+			return nil
+		})
+	},
+}
+
+// isSecretType reports whether t is an accepted secret entry type
+func isSecretType(t string) bool {
+	for _, known := range vault.SecretTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	secretAddCmd.Flags().StringVar(&secretType, "type", vault.SecretTypeGeneric, "Type of secret: "+strings.Join(vault.SecretTypes, ", "))
+	secretAddCmd.Flags().StringVar(&secretNotes, "notes", "", "Notes for the secret")
+	secretGetCmd.Flags().BoolVar(&secretGetCode, "code", false, "Output the current one-time code of a totp secret")
+	secretGetCmd.Flags().BoolVar(&getAllowScreenCapture, "allow-screen-capture", false, "Reveal secrets even if screen sharing or recording software is running.")
+	secretGetCmd.Flags().IntVar(&getOutFD, "out-fd", -1, "Write the value to this inherited file descriptor (3 or higher) instead of stdout or the clipboard.")
+	secretGetCmd.Flags().StringVar(&getOutFIFO, "out-fifo", "", "Create a 0600 FIFO at this path and write the value to its first reader.")
+	secretGetCmd.Flags().IntVar(&getClipboardTimeout, "clipboard-timeout", defaultClipboardTimeout, fmt.Sprintf("Seconds after which clipboard will be cleared (range: %d-%d, default: %d).", minClipboardTimeout, maxClipboardTimeout, defaultClipboardTimeout))
+}
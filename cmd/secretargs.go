@@ -0,0 +1,112 @@
+// File: cmd/secretargs.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/errors"
+	"vault.module/internal/scrub"
+	"vault.module/internal/security"
+)
+
+// allowSecretArgsFlag lets arguments that only look like secrets through
+const allowSecretArgsFlag = "--allow-secret-args"
+
+var allowSecretArgs bool
+
+// secretArgFindings are the suspected secrets of an allowed command line,
+// recorded in the audit log once it is open
+var secretArgFindings []scrub.Finding
+
+// secretArgsScrubbed tells whether they were removed from the process's command line
+var secretArgsScrubbed bool
+
+// guardSecretArgs refuses a command line holding what looks like a private key
+// or a mnemonic: other users see it in ps and the shell keeps it in its
+// history. It runs before the arguments are parsed. The secrets are first
+// scrubbed from the command line of the process where the platform allows it.
+func guardSecretArgs() error {
+	findings, indexes := scrub.ScanArgs(os.Args[1:])
+	if len(findings) == 0 {
+		return nil
+	}
+	for i := range indexes {
+		indexes[i]++ // Indexes of os.Args
+	}
+	secretArgsScrubbed = security.ScrubArgs(indexes)
+
+	kinds := make([]string, 0, len(findings))
+	for _, f := range findings {
+		kinds = append(kinds, fmt.Sprintf("%s (%s)", f.Kind, f.Preview))
+	}
+	if secretArgsAllowedByUser() {
+		secretArgFindings = findings
+		fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("WARNING: the command line holds what looks like a %s; allowed by %s.", strings.Join(kinds, ", "), allowSecretArgsFlag), colors.Warning))
+		return nil
+	}
+
+	if audit.InitLogger() == nil {
+		audit.Logger.Warn("Secret on the command line refused",
+			slog.Int("count", len(findings)),
+			slog.String("kinds", secretArgKinds(findings)),
+			slog.Bool("scrubbed", secretArgsScrubbed),
+		)
+	}
+	fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("The command line holds what looks like a %s.", strings.Join(kinds, ", ")), colors.Error))
+	fmt.Fprintln(os.Stderr, "Arguments are visible to other users in ps and are kept in your shell history. Type secrets at a prompt instead:")
+	fmt.Fprintln(os.Stderr, "  vault.module add PREFIX           asks for the mnemonic or private key")
+	fmt.Fprintln(os.Stderr, "  vault.module secret add NAME      asks for the secret")
+	fmt.Fprintln(os.Stderr, "  vault.module import FILE          reads wallets from a file; delete it afterwards")
+	fmt.Fprintln(os.Stderr, "Treat the secret as exposed, and remove it from your history with 'vault.module scrub-history --redact'.")
+	if !secretArgsScrubbed {
+		fmt.Fprintln(os.Stderr, "This platform does not let vault.module hide its command line from other processes.")
+	}
+	fmt.Fprintf(os.Stderr, "For values that only look like secrets, such as hashes, add %s.\n", allowSecretArgsFlag)
+	return errors.New(errors.ErrCodePermission, "secrets on the command line are refused").
+		WithDetails(fmt.Sprintf("found a %s", secretArgKinds(findings)))
+}
+
+// secretArgsAllowedByUser tells whether --allow-secret-args was given. It is
+// looked for before cobra parses the flags.
+func secretArgsAllowedByUser() bool {
+	for _, arg := range os.Args[1:] {
+		if arg == "--" {
+			break
+		}
+		if arg == allowSecretArgsFlag || arg == allowSecretArgsFlag+"=true" {
+			return true
+		}
+	}
+	return false
+}
+
+// logSecretArgs records an allowed command line with suspected secrets
+func logSecretArgs(command string) {
+	if len(secretArgFindings) == 0 {
+		return
+	}
+	audit.Logger.Warn("Secret on the command line allowed",
+		slog.String("command", command),
+		slog.Int("count", len(secretArgFindings)),
+		slog.String("kinds", secretArgKinds(secretArgFindings)),
+		slog.Bool("scrubbed", secretArgsScrubbed),
+	)
+}
+
+// secretArgKinds lists the kinds of findings, without their previews
+func secretArgKinds(findings []scrub.Finding) string {
+	var kinds []string
+	seen := make(map[string]bool)
+	for _, f := range findings {
+		if !seen[f.Kind] {
+			seen[f.Kind] = true
+			kinds = append(kinds, f.Kind)
+		}
+	}
+	return strings.Join(kinds, " and ")
+}
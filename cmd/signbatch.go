@@ -0,0 +1,571 @@
+// File: cmd/signbatch.go
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"vault.module/internal/addressbook"
+	"vault.module/internal/airgap"
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/errors"
+	"vault.module/internal/keys"
+	"vault.module/internal/noncelog"
+	"vault.module/internal/signbatch"
+	"vault.module/internal/simulate"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var signBatchManifest string
+var signBatchOut string
+var signBatchForce bool
+var signBatchEach bool
+var signBatchYes bool
+var signBatchChainID int64
+var signBatchAllowUnprotected bool
+var signBatchAllowNonceReuse bool
+var signBatchNoSimulate bool
+
+var signCmd = &cobra.Command{
+	Use:   "sign",
+	Short: "Signs payloads with the keys of the active vault.",
+	Long: `Signs payloads with the keys of the active vault.
+
+'airgap sign' signs one request scanned from a watch-only wallet and the
+web3signer signs requests of a local client; 'sign batch' signs many payloads
+listed in a manifest file at once.
+`,
+}
+
+var signBatchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Signs the payloads of a manifest file in one vault unlock.",
+	Long: `Signs the payloads of a manifest file in one vault unlock.
+
+For airdrops, payouts and other runs of many signatures, the vault is unlocked
+once and every item of the manifest is signed with the matching address of the
+active EVM vault. The manifest is a JSON file:
+
+  {
+    "version": 1,
+    "description": "Airdrop round 3",
+    "items": [
+      {"id": "tx-1", "address": "0x19E7...", "type": "typed-transaction",
+       "chain_id": 1, "payload": "0x02f86f...", "note": "alice"},
+      {"id": "msg-1", "address": "0x19E7...", "type": "personal-message",
+       "message": "Claim #42"},
+      {"id": "permit-1", "path": "m/44'/60'/0'/0/0", "key": "A1",
+       "type": "typed-data", "chain_id": 1, "typed_data": {...}}
+    ]
+  }
+
+An item is signed by the address it names or, without one, by the address at
+its derivation path; "key" restricts the search to one wallet. Its type is
+transaction (legacy), typed-transaction (EIP-2718), personal-message or
+typed-data (EIP-712). Transactions and messages are given as hex "payload",
+personal messages also as "message" text, typed data as a "typed_data"
+object. Unknown fields are refused.
+
+Before anything is signed, the batch is reviewed. By default a single summary
+lists every item with its recipient, value or message, the totals per chain,
+and the items that grant token approvals or reuse a nonce, which must be
+confirmed a second time. With --each, every item is shown in full, previewed as
+'airgap sign' does, and approved on its own. --yes signs without review.
+
+The checks of 'airgap sign' apply to every item: the chain ID of a transaction
+or typed data must be the item's (and --chain-id when given), legacy
+transactions without an EIP-155 chain ID need --allow-unprotected, and a nonce
+already signed for another transaction, or used twice in the batch, is refused
+with --yes unless --allow-nonce-reuse is given. An item that fails a check is
+not signed; the others are.
+
+The results manifest, written to --out (default: the manifest's name with
+.results.json), lists every item with its address, the SHA-256 of its payload,
+its status (signed, declined or failed), its signature as hex r || s || v and
+the reason it failed. The command fails when an item failed.
+
+In programmatic mode (VAULT_MODULE_PROGRAMMATIC=1), --yes is required, and
+with "signing_queue" enabled every item must be approved by a signing policy
+of the client, which authenticates with its token of 'approvals token' in
+VAULT_MODULE_CLIENT_TOKEN: batches are not queued.
+
+Examples:
+  vault.module sign batch --manifest signs.json
+  vault.module sign batch --manifest signs.json --each --out signed.json
+  VAULT_MODULE_PROGRAMMATIC=1 VAULT_MODULE_CLIENT_TOKEN=... vault.module sign batch --manifest signs.json --yes
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if signBatchManifest == "" {
+				return errors.NewInvalidInputError("--manifest", "the manifest file is required")
+			}
+			if programmaticMode && !signBatchYes {
+				return errors.NewInvalidInputError("--yes", "programmatic signing requires --yes")
+			}
+			if signBatchEach && signBatchYes {
+				return errors.NewInvalidInputError("--each", "--each and --yes cannot be combined")
+			}
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			if activeVault.Type != constants.VaultTypeEVM {
+				return errors.NewInvalidInputError(activeVault.Type, "batch signing is only available for EVM vaults")
+			}
+
+			if err := validateFileForImport(signBatchManifest); err != nil {
+				return err
+			}
+			data, err := os.ReadFile(signBatchManifest)
+			if err != nil {
+				return errors.NewFileSystemError("read", signBatchManifest, err)
+			}
+			manifest, err := signbatch.Parse(data, signBatchManifest)
+			if err != nil {
+				return err
+			}
+			out := signBatchOut
+			if out == "" {
+				out = strings.TrimSuffix(signBatchManifest, ".json") + ".results.json"
+			}
+			if _, err := os.Stat(out); err == nil && !signBatchForce {
+				return errors.NewInvalidInputError(out, "file already exists; use --force to overwrite")
+			}
+
+			// A batch is signed as a whole: it is never queued item by item
+			if programmaticMode && config.Cfg.SigningQueue {
+				client, err := authenticatedClient("programmatic batch signing")
+				if err != nil {
+					return err
+				}
+				for _, item := range manifest.Items {
+					if !config.AutoApproves(client, item.Type, item.ChainID) {
+						return errors.NewInvalidInputError(item.ID, fmt.Sprintf("no signing policy of client '%s' approves this item; batches are not queued", client))
+					}
+				}
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			items := make([]*batchItem, len(manifest.Items))
+			for i, item := range manifest.Items {
+				items[i] = prepareBatchItem(v, item)
+			}
+			checkBatchNonces(items)
+
+			switch {
+			case signBatchYes:
+				for _, item := range items {
+					if item.result.Status == "" && item.reused != nil && !signBatchAllowNonceReuse {
+						item.fail(errors.NewInvalidInputError(strconv.FormatUint(item.nonce, 10), "another transaction was already signed with this nonce; sign a replacement with --allow-nonce-reuse only if that is intended"))
+					}
+				}
+			case signBatchEach:
+				reviewBatchEach(items)
+			default:
+				reviewBatchSummary(manifest, items)
+			}
+
+			signBatchItems(items)
+
+			results := signbatch.Results{
+				Version:        signbatch.Version,
+				Manifest:       signBatchManifest,
+				ManifestSHA256: fmt.Sprintf("%x", sha256.Sum256(data)),
+				Vault:          config.Cfg.ActiveVault,
+				SignedAt:       time.Now().UTC(),
+			}
+			for _, item := range items {
+				switch item.result.Status {
+				case signbatch.StatusSigned:
+					results.Signed++
+				case signbatch.StatusDeclined:
+					results.Declined++
+				default:
+					results.Failed++
+				}
+				results.Items = append(results.Items, item.result)
+			}
+			encoded, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				return errors.New(errors.ErrCodeInternal, "failed to generate JSON").WithContext("marshal_error", err.Error())
+			}
+			if err := os.WriteFile(out, append(encoded, '\n'), 0644); err != nil {
+				return errors.FromOSError(err, out)
+			}
+
+			audit.Logger.Warn("Signing batch completed",
+				slog.String("command", "sign batch"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("manifest", signBatchManifest),
+				slog.String("manifest_sha256", results.ManifestSHA256),
+				slog.String("results", out),
+				slog.Int("signed", results.Signed),
+				slog.Int("declined", results.Declined),
+				slog.Int("failed", results.Failed),
+			)
+			if !programmaticMode {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Signed %d of %d item(s), %d declined, %d failed. Results written to %s", results.Signed, len(items), results.Declined, results.Failed, out), colors.Success))
+			}
+			if results.Failed > 0 {
+				return errors.NewInvalidInputError(signBatchManifest, fmt.Sprintf("%d item(s) failed; their reasons are in %s", results.Failed, out))
+			}
+			return nil
+		})
+	},
+}
+
+// batchItem is a manifest item with its signing address and the findings of
+// its checks. An item whose result has a status is settled and not signed.
+type batchItem struct {
+	item        signbatch.Item
+	req         *airgap.EthSignRequest
+	kind        keys.EVMPayloadKind
+	prefix      string
+	addr        *vault.Address
+	signChainID int64
+	digest      string
+	grants      []simulate.Grant
+	tx          *keys.EVMTransaction
+	nonce       uint64
+	reused      *noncelog.Entry
+	approved    bool
+	result      signbatch.Result
+}
+
+// fail settles an item as failed
+func (b *batchItem) fail(err error) {
+	b.result.Status = signbatch.StatusFailed
+	var vErr *errors.VaultError
+	if errors.AsVaultError(err, &vErr) {
+		// The details say what is wrong with this item; the message is generic
+		b.result.Error = vErr.Message
+		if vErr.Details != "" {
+			b.result.Error = vErr.Details
+		}
+	} else {
+		b.result.Error = err.Error()
+	}
+}
+
+// prepareBatchItem decodes an item, finds its address and runs the checks
+// 'airgap sign' runs before a request is shown
+func prepareBatchItem(v vault.Vault, item signbatch.Item) *batchItem {
+	b := &batchItem{item: item, result: signbatch.Result{ID: item.ID, Type: item.Type, ChainID: item.ChainID}}
+	req, err := item.Request()
+	if err != nil {
+		b.fail(errors.NewInvalidInputError(item.ID, err.Error()))
+		return b
+	}
+	b.req = req
+	digest := sha256.Sum256(req.SignData)
+	b.digest = hex.EncodeToString(digest[:])
+	b.result.SHA256 = b.digest
+
+	if b.kind, err = ethSignKind(req.DataType); err != nil {
+		b.fail(err)
+		return b
+	}
+	if b.signChainID, err = checkReplayProtection(req, b.kind, signBatchChainID, signBatchAllowUnprotected); err != nil {
+		b.fail(err)
+		return b
+	}
+	prefix, addr, err := findSigningAddress(v, req, item.Key)
+	if err != nil {
+		b.fail(err)
+		return b
+	}
+	b.result.Address = addr.Address
+	if err := checkWalletNotFrozen("sign batch", prefix, v[prefix]); err != nil {
+		b.fail(err)
+		return b
+	}
+	if wallet := v[prefix]; wallet.Sealed() {
+		if err := openWalletEnvelope("sign batch", prefix, &wallet); err != nil {
+			b.fail(err)
+			return b
+		}
+		v[prefix] = wallet
+		if prefix, addr, err = findSigningAddress(v, req, item.Key); err != nil {
+			b.fail(err)
+			return b
+		}
+	}
+	if addr.PrivateKey == nil || addr.PrivateKey.IsEmpty() {
+		b.fail(missingKeyError(prefix, addr.Index, v[prefix]))
+		return b
+	}
+	b.prefix, b.addr = prefix, addr
+	b.grants = simulate.Grants(req.SignData, b.kind)
+	if b.kind == keys.EVMLegacyTransaction || b.kind == keys.EVMTypedTransaction {
+		if b.tx, err = keys.DecodeEVMTransaction(req.SignData, b.kind); err != nil {
+			b.fail(errors.NewInvalidInputError(item.ID, err.Error()))
+			return b
+		}
+		b.nonce = b.tx.Nonce
+	}
+	return b
+}
+
+// checkBatchNonces finds the transactions whose nonce was already signed for
+// another transaction, earlier or by another item of the batch
+func checkBatchNonces(items []*batchItem) {
+	first := make(map[string]*batchItem)
+	for _, b := range items {
+		if b.result.Status != "" || b.tx == nil {
+			continue
+		}
+		reused, err := noncelog.Conflict(b.addr.Address, b.signChainID, b.nonce, b.digest)
+		if err != nil {
+			b.fail(err)
+			continue
+		}
+		key := fmt.Sprintf("%s/%d/%d", strings.ToLower(b.addr.Address), b.signChainID, b.nonce)
+		if earlier, ok := first[key]; reused == nil && ok && earlier.digest != b.digest {
+			reused = &noncelog.Entry{Address: b.addr.Address, ChainID: b.signChainID, Nonce: b.nonce, SHA256: earlier.digest, Command: "item " + earlier.item.ID + " of this batch", SignedAt: time.Now().UTC()}
+		} else if !ok {
+			first[key] = b
+		}
+		b.reused = reused
+	}
+}
+
+// reviewBatchEach shows every item in full and asks for its approval
+func reviewBatchEach(items []*batchItem) {
+	for i, b := range items {
+		if b.result.Status != "" {
+			fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("Item %d of %d (%s) cannot be signed: %s", i+1, len(items), b.item.ID, b.result.Error), colors.Error))
+			continue
+		}
+		fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("Item %d of %d: %s", i+1, len(items), b.item.ID), colors.Bold))
+		if b.item.Note != "" {
+			fmt.Fprintf(os.Stderr, "  Note:      %s\n", b.item.Note)
+		}
+		printSignRequest(b.req, b.prefix, b.addr, b.digest, "", !signBatchNoSimulate)
+		printGrantWarnings(os.Stderr, b.grants, b.req.ChainID)
+		if b.reused != nil {
+			printNonceReuse(os.Stderr, b.reused)
+		}
+		b.approved = askForConfirmation(fmt.Sprintf("Sign item %s?", b.item.ID)) &&
+			(b.reused == nil || askForConfirmation(fmt.Sprintf("Sign a second transaction with nonce %d? Only one of them can be mined.", b.nonce))) &&
+			(len(b.grants) == 0 || askForConfirmation(fmt.Sprintf("This item lets %d spender(s) move your tokens. Sign it anyway?", len(b.grants))))
+		if !b.approved {
+			b.result.Status = signbatch.StatusDeclined
+		}
+	}
+}
+
+// reviewBatchSummary shows one line per item and the totals of the batch, and
+// asks once for all of them. Items that grant approvals or reuse a nonce are
+// confirmed a second time, together.
+func reviewBatchSummary(manifest *signbatch.Manifest, items []*batchItem) {
+	out := os.Stderr
+	fmt.Fprintln(out, colors.SafeColor("Signing batch", colors.Info))
+	if manifest.Description != "" {
+		fmt.Fprintf(out, "  Description: %s\n", addressbook.Clean(manifest.Description))
+	}
+	fmt.Fprintf(out, "  Manifest:    %s\n", signBatchManifest)
+
+	width := 0
+	for _, b := range items {
+		width = max(width, utf8.RuneCountInString(b.item.ID))
+	}
+	totals := make(map[int64]*big.Int)
+	var pending, risky []*batchItem
+	for _, b := range items {
+		id := b.item.ID + strings.Repeat(" ", width-utf8.RuneCountInString(b.item.ID))
+		if b.result.Status != "" {
+			fmt.Fprintf(out, "  %s  %s\n", id, colors.SafeColor("FAILED: "+b.result.Error, colors.Error))
+			continue
+		}
+		line := fmt.Sprintf("  %s  %s  %s", id, b.prefix, describeBatchItem(b))
+		if b.item.Note != "" {
+			line += "  (" + b.item.Note + ")"
+		}
+		var flags []string
+		if len(b.grants) > 0 {
+			flags = append(flags, "APPROVAL")
+		}
+		if b.reused != nil {
+			flags = append(flags, fmt.Sprintf("NONCE %d REUSED", b.nonce))
+		}
+		if b.tx != nil && b.tx.ChainID == nil {
+			flags = append(flags, "UNPROTECTED")
+		}
+		if len(flags) > 0 {
+			line += "  " + colors.SafeColor(strings.Join(flags, ", "), colors.Error)
+			risky = append(risky, b)
+		}
+		fmt.Fprintln(out, line)
+		if b.tx != nil && b.tx.Value != nil {
+			if totals[b.req.ChainID] == nil {
+				totals[b.req.ChainID] = new(big.Int)
+			}
+			totals[b.req.ChainID].Add(totals[b.req.ChainID], b.tx.Value)
+		}
+		pending = append(pending, b)
+	}
+
+	chains := make([]int64, 0, len(totals))
+	for chainID := range totals {
+		chains = append(chains, chainID)
+	}
+	sort.Slice(chains, func(i, j int) bool { return chains[i] < chains[j] })
+	for _, chainID := range chains {
+		fmt.Fprintf(out, "  Total:       %s %s on chain %d\n", simulate.FormatAmount(totals[chainID], 18), simulate.NativeSymbol(chainID), chainID)
+	}
+	for _, b := range risky {
+		if len(b.grants) > 0 {
+			fmt.Fprintf(out, "Item %s:\n", b.item.ID)
+			printGrantWarnings(out, b.grants, b.req.ChainID)
+		}
+		if b.reused != nil {
+			fmt.Fprintf(out, "Item %s:\n", b.item.ID)
+			printNonceReuse(out, b.reused)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	approved := askForConfirmation(fmt.Sprintf("Sign %d item(s) with the vault '%s'?", len(pending), config.Cfg.ActiveVault))
+	if approved && len(risky) > 0 {
+		approved = askForConfirmation(fmt.Sprintf("%d item(s) grant token approvals, reuse a nonce or can be replayed on other chains. Sign them anyway?", len(risky)))
+	}
+	for _, b := range pending {
+		b.approved = approved
+		if !approved {
+			b.result.Status = signbatch.StatusDeclined
+		}
+	}
+	if !approved {
+		fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+	}
+}
+
+// describeBatchItem summarizes an item on one line
+func describeBatchItem(b *batchItem) string {
+	switch b.kind {
+	case keys.EVMLegacyTransaction, keys.EVMTypedTransaction:
+		to := "contract creation"
+		if b.tx.To != nil {
+			to = "to " + addressbook.Describe(*b.tx.To)
+		}
+		return fmt.Sprintf("chain %d, nonce %d, %s %s %s", b.req.ChainID, b.nonce, to, simulate.FormatAmount(b.tx.Value, 18), simulate.NativeSymbol(b.req.ChainID))
+	case keys.EVMTypedData:
+		var typedData struct {
+			PrimaryType string `json:"primaryType"`
+			Domain      struct {
+				Name string `json:"name"`
+			} `json:"domain"`
+		}
+		_ = json.Unmarshal(b.req.SignData, &typedData)
+		return fmt.Sprintf("chain %d, typed data %s of %q", b.req.ChainID, addressbook.Clean(typedData.PrimaryType), addressbook.Clean(typedData.Domain.Name))
+	default:
+		message := "0x" + hex.EncodeToString(b.req.SignData)
+		if utf8.Valid(b.req.SignData) && !strings.ContainsFunc(string(b.req.SignData), isControlRune) {
+			message = strconv.Quote(string(b.req.SignData))
+		}
+		if runes := []rune(message); len(runes) > 60 {
+			message = string(runes[:57]) + "..."
+		}
+		return "message " + message
+	}
+}
+
+// signBatchItems signs the approved items. Each wallet counts once against the
+// retrieval limits, as its key is retrieved once for the whole batch.
+func signBatchItems(items []*batchItem) {
+	allowed := make(map[string]error)
+	for _, b := range items {
+		if b.result.Status != "" || (!b.approved && !signBatchYes) {
+			continue
+		}
+		limitErr, checked := allowed[b.prefix]
+		if !checked {
+			limitErr = checkSecretRateLimit(b.prefix)
+			allowed[b.prefix] = limitErr
+		}
+		if limitErr != nil {
+			b.fail(limitErr)
+			continue
+		}
+
+		if b.tx != nil && b.reused == nil {
+			// Another signer may have taken the nonce since the batch was checked
+			reused, err := noncelog.Claim(b.addr.Address, b.signChainID, b.nonce, b.digest, "sign batch")
+			if err != nil {
+				b.fail(err)
+				continue
+			}
+			if reused != nil {
+				b.fail(errors.NewInvalidInputError(strconv.FormatUint(b.nonce, 10), fmt.Sprintf("another transaction was signed with this nonce at %s while the batch was reviewed", reused.SignedAt.Format(time.RFC3339))))
+				continue
+			}
+		}
+		signature, err := keys.SignEVMPayload(b.addr.PrivateKey.String(), b.req.SignData, b.kind, b.signChainID)
+		if err != nil {
+			b.fail(errors.NewInvalidInputError(b.item.ID, err.Error()))
+			continue
+		}
+		b.result.Status = signbatch.StatusSigned
+		b.result.Signature = "0x" + hex.EncodeToString(signature)
+
+		audit.Logger.Warn("Batch item signed",
+			slog.String("command", "sign batch"),
+			slog.String("vault", config.Cfg.ActiveVault),
+			slog.String("prefix", b.prefix),
+			slog.String("address", b.addr.Address),
+			slog.String("item", b.item.ID),
+			slog.Int64("chain_id", b.req.ChainID),
+			slog.String("data_type", b.item.Type),
+			slog.String("sha256", b.digest),
+			slog.Int("approval_grants", len(b.grants)),
+			slog.Bool("nonce_reused", b.reused != nil),
+		)
+		if b.tx != nil {
+			if err := noncelog.Record(b.addr.Address, b.signChainID, b.nonce, b.digest, "sign batch"); err != nil {
+				fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("Warning: the nonce of item %s was not recorded: %v", b.item.ID, err), colors.Warning))
+			}
+		}
+	}
+}
+
+func init() {
+	signBatchCmd.Flags().StringVar(&signBatchManifest, "manifest", "", "Manifest file listing the payloads to sign.")
+	signBatchCmd.Flags().StringVar(&signBatchOut, "out", "", "Results manifest to write (default: <manifest>.results.json).")
+	signBatchCmd.Flags().BoolVar(&signBatchForce, "force", false, "Overwrite the results manifest if it exists.")
+	signBatchCmd.Flags().BoolVar(&signBatchEach, "each", false, "Show and approve every item on its own instead of a single summary.")
+	signBatchCmd.Flags().BoolVar(&signBatchYes, "yes", false, "Sign without review.")
+	signBatchCmd.Flags().Int64Var(&signBatchChainID, "chain-id", 0, "Only sign items for this chain (default: any chain).")
+	signBatchCmd.Flags().BoolVar(&signBatchAllowUnprotected, "allow-unprotected", false, "Sign legacy transactions without an EIP-155 chain ID, which are valid on every chain.")
+	signBatchCmd.Flags().BoolVar(&signBatchAllowNonceReuse, "allow-nonce-reuse", false, "With --yes, sign transactions whose nonce was already signed for another one.")
+	signBatchCmd.Flags().BoolVar(&signBatchNoSimulate, "no-simulate", false, "With --each, do not run transactions against simulation_rpc; decode them offline only.")
+}
@@ -0,0 +1,140 @@
+// File: cmd/stats.go
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/usagestats"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var statsUsageEnable bool
+var statsUsageDisable bool
+var statsUsageReset bool
+var statsUsageJson bool
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Shows statistics kept on this machine.",
+	Long: `Shows statistics kept on this machine.
+
+Examples:
+  vault.module stats usage
+`,
+}
+
+var statsUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Shows or switches the local usage statistics.",
+	Long: `Shows or switches the local usage statistics.
+
+Usage statistics are off unless enabled with --enable. They count how often
+each command runs and which flags it is given, in usage-stats.json next to
+config.json. Only command paths and flag names are kept: no argument, flag
+value, vault, wallet or address. vault.module never transmits them; print them
+with --json to share them with the maintainers if you wish.
+
+--disable stops counting and keeps the statistics; --reset deletes them.
+
+Examples:
+  vault.module stats usage --enable
+  vault.module stats usage
+  vault.module stats usage --json
+  vault.module stats usage --disable --reset
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if statsUsageEnable && statsUsageDisable {
+				return errors.NewInvalidInputError("--enable", "--enable and --disable cannot be combined")
+			}
+			if statsUsageEnable || statsUsageDisable {
+				config.Cfg.UsageStats = statsUsageEnable
+				if err := config.SaveConfig(); err != nil {
+					return errors.NewConfigSaveError("config.json", err)
+				}
+				audit.Logger.Info("Usage statistics switched", slog.Bool("enabled", config.Cfg.UsageStats))
+				if config.Cfg.UsageStats {
+					fmt.Println(colors.SafeColor("Usage statistics enabled. They stay in "+usagestats.StateFile+" and are never transmitted.", colors.Success))
+				} else {
+					fmt.Println(colors.SafeColor("Usage statistics disabled.", colors.Success))
+				}
+			}
+			if statsUsageReset {
+				if err := usagestats.Reset(); err != nil {
+					return err
+				}
+				audit.Logger.Info("Usage statistics reset")
+				fmt.Println(colors.SafeColor("Usage statistics deleted.", colors.Success))
+			}
+			if statsUsageEnable || statsUsageDisable || statsUsageReset {
+				return nil
+			}
+
+			st, err := usagestats.Load()
+			if err != nil {
+				return err
+			}
+			if statsUsageJson {
+				data, err := json.MarshalIndent(st, "", "  ")
+				if err != nil {
+					return errors.New(errors.ErrCodeInternal, "failed to format JSON").WithContext("marshal_error", err.Error())
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+			if !config.Cfg.UsageStats {
+				fmt.Println(colors.SafeColor("Usage statistics are disabled. Enable them with 'vault.module stats usage --enable'.", colors.Info))
+			}
+			if len(st.Commands) == 0 {
+				fmt.Println(colors.SafeColor("No usage recorded.", colors.Info))
+				return nil
+			}
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Usage since %s", st.Since.Format("2006-01-02")), colors.Bold))
+			printUsageCounts("Commands", st.Commands)
+			if len(st.Features) > 0 {
+				printUsageCounts("Flags", st.Features)
+			}
+			return nil
+		})
+	},
+}
+
+// printUsageCounts lists counts under a heading, most used first
+func printUsageCounts(heading string, counts map[string]usagestats.Count) {
+	fmt.Println(colors.SafeColor(heading+":", colors.Bold))
+	for _, u := range usagestats.Sorted(counts) {
+		fmt.Printf("  %6d  %-32s %s\n", u.Count.Count, u.Name, colors.SafeColor("last "+u.LastUsed.Local().Format("2006-01-02"), colors.Dim))
+	}
+}
+
+// recordUsage counts the command and the names of its flags when usage
+// statistics are enabled. Counting never stops a command.
+func recordUsage(cmd *cobra.Command, path string) {
+	if !config.Cfg.UsageStats {
+		return
+	}
+	var flags []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		flags = append(flags, f.Name)
+	})
+	if err := usagestats.Record(path, flags); err != nil {
+		fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("WARNING: could not record usage statistics: %v", err), colors.Warning))
+	}
+}
+
+func init() {
+	statsUsageCmd.Flags().BoolVar(&statsUsageEnable, "enable", false, "Start counting commands and flags on this machine.")
+	statsUsageCmd.Flags().BoolVar(&statsUsageDisable, "disable", false, "Stop counting; the statistics are kept.")
+	statsUsageCmd.Flags().BoolVar(&statsUsageReset, "reset", false, "Delete the statistics.")
+	statsUsageCmd.Flags().BoolVar(&statsUsageJson, "json", false, "Output the statistics in JSON format, e.g. to share them.")
+}
@@ -0,0 +1,441 @@
+// File: cmd/sync.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/errors"
+	"vault.module/internal/keystoresync"
+	"vault.module/internal/security"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var syncDir string
+var syncWallets []string
+var syncTag string
+var syncPrune bool
+var syncDryRun bool
+var syncLight bool
+var syncWatch bool
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Keeps key files of other tools in sync with the active vault.",
+}
+
+var syncKeystoreCmd = &cobra.Command{
+	Use:   "keystore --dir DIR [--wallets PREFIX,... | --tag TAG]",
+	Short: "Keeps a Geth keystore directory in sync with selected wallets.",
+	Long: `Keeps a Geth keystore directory in sync with selected wallets.
+
+For nodes and tools that read their keys from a keystore directory (geth,
+Clef, ...). The addresses of the selected wallets are written to DIR as
+password-protected keystore V3 files, one per address, named as geth names
+them. The first sync selects the wallets with --wallets or --tag; later runs
+need only --dir, and giving either flag again changes the selection.
+
+Each run compares the directory with the vault and with what was synced
+before, recorded in DIR/.vault.module-sync.json (geth skips dotfiles):
+
+  written    address of a selected wallet without a key file: written
+  unchanged  key file as synced before
+  modified   key file changed outside vault.module: left alone, reported
+  stale      key file of an address no longer selected: removed with
+             --prune, reported otherwise
+  external   key file not written by vault.module: reported, with the
+             wallet if the vault holds the address
+
+All files share one password, asked for when files are written: twice for a
+new directory, once and checked against the synced files otherwise. A new
+password is checked for strength like every new passphrase (see 'envelope').
+The scrypt parameters are geth's standard ones, or its --lightkdf ones with
+--light. Every wallet whose keys are written counts as a secret retrieval.
+
+--watch keeps running after the sync and reports keys added, changed or
+removed by others as it happens.
+
+Examples:
+  vault.module sync keystore --dir ~/.ethereum/keystore --wallets hot,relayer
+  vault.module sync keystore --dir /srv/node/keystore --tag node --light
+  vault.module sync keystore --dir ~/.ethereum/keystore --dry-run
+  vault.module sync keystore --dir ~/.ethereum/keystore --prune --watch
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("sync keystore")
+			}
+			if syncDir == "" {
+				return errors.NewInvalidInputError("", "--dir is required")
+			}
+			if len(syncWallets) > 0 && syncTag != "" {
+				return errors.NewInvalidInputError("--wallets/--tag", "select wallets with either --wallets or --tag")
+			}
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			if activeVault.Type != constants.VaultTypeEVM {
+				return errors.NewInvalidInputError(activeVault.Type, "keystore directories hold EVM keys; use an evm vault")
+			}
+
+			if err := os.MkdirAll(syncDir, 0700); err != nil {
+				return errors.NewFileSystemError("create", syncDir, err)
+			}
+			manifest, err := keystoresync.LoadManifest(syncDir)
+			if err != nil {
+				return err
+			}
+			if manifest == nil {
+				manifest = &keystoresync.Manifest{Vault: config.Cfg.ActiveVault}
+			} else if manifest.Vault != config.Cfg.ActiveVault {
+				return errors.NewInvalidInputError(syncDir, fmt.Sprintf("the directory is synced with vault '%s'", manifest.Vault))
+			}
+			switch {
+			case len(syncWallets) > 0:
+				manifest.Wallets, manifest.Tag = syncWallets, ""
+			case syncTag != "":
+				manifest.Wallets, manifest.Tag = nil, syncTag
+			case len(manifest.Wallets) == 0 && manifest.Tag == "":
+				return errors.NewInvalidInputError("", "select the wallets to sync with --wallets or --tag")
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			selected, err := selectKeyWallets(v, manifest.Wallets, manifest.Tag)
+			if err != nil {
+				return err
+			}
+			entries, err := keystoresync.Scan(syncDir)
+			if err != nil {
+				return err
+			}
+			plan := planKeystoreSync(v, selected, manifest, entries)
+			printKeystoreSyncPlan(plan)
+			if syncDryRun {
+				fmt.Println(colors.SafeColor("Dry run: nothing was changed.", colors.Info))
+				return nil
+			}
+
+			written, err := writeKeystoreFiles(activeVault, v, plan.passwordReference(), plan.write)
+			if err != nil {
+				return err
+			}
+			removed := 0
+			if syncPrune {
+				for _, file := range plan.stale {
+					if err := security.SecureFileDelete(filepath.Join(syncDir, file.Name)); err != nil {
+						return errors.NewFileSystemError("delete", filepath.Join(syncDir, file.Name), err)
+					}
+					removed++
+				}
+			}
+			manifest.Files = plan.keep
+			manifest.Files = append(manifest.Files, written...)
+			if !syncPrune {
+				manifest.Files = append(manifest.Files, plan.stale...)
+			}
+			manifest.UpdatedAt = time.Now().UTC()
+			if err := keystoresync.SaveManifest(syncDir, manifest); err != nil {
+				return err
+			}
+
+			for _, entry := range plan.external {
+				audit.Logger.Warn("Keystore file not written by vault.module",
+					slog.String("command", "sync keystore"),
+					slog.String("dir", syncDir),
+					slog.String("file", entry.Name),
+					slog.String("address", entry.Address))
+			}
+			audit.Logger.Info("Keystore directory synced",
+				slog.String("command", "sync keystore"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("dir", syncDir),
+				slog.Int("written", len(written)),
+				slog.Int("removed", removed),
+				slog.Int("modified", len(plan.modified)),
+				slog.Int("external", len(plan.external)))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Synced '%s': %d written, %d removed.", syncDir, len(written), removed), colors.Success))
+
+			if !syncWatch {
+				return nil
+			}
+			// Only the addresses are needed while watching
+			for prefix, wallet := range v {
+				wallet.Clear()
+				v[prefix] = wallet
+			}
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Watching '%s' for changes by others (Ctrl+C to stop)...", syncDir), colors.Info))
+			return keystoresync.Watch(security.GetManager().Context(), syncDir, func(event keystoresync.Event) {
+				audit.Logger.Warn("Keystore directory changed outside vault.module",
+					slog.String("dir", syncDir),
+					slog.String("file", event.Name),
+					slog.String("address", event.Address),
+					slog.String("op", event.Op))
+				line := fmt.Sprintf("%s: key file %s %s", time.Now().Format("15:04:05"), event.Name, event.Op)
+				if event.Address != "" {
+					line += fmt.Sprintf(" (%s%s)", event.Address, vaultHolder(v, event.Address))
+				}
+				fmt.Println(colors.SafeColor(line, colors.Warning))
+			})
+		})
+	},
+}
+
+// keystoreSyncTarget is an address of a selected wallet
+type keystoreSyncTarget struct {
+	prefix  string
+	account int
+	index   int
+	address string
+}
+
+// keystoreSyncPlan sorts a keystore directory against the selected wallets
+type keystoreSyncPlan struct {
+	write    []keystoreSyncTarget
+	keep     []keystoresync.File // Synced before, unchanged or modified
+	modified []keystoresync.File
+	stale    []keystoresync.File
+	external []keystoresync.Entry
+	present  []keystoreSyncTarget // Selected addresses held by an external file
+}
+
+// selectKeyWallets returns the wallets named, or those with tag, sorted. Named
+// wallets must exist; wallets without keys are left out.
+func selectKeyWallets(v vault.Vault, names []string, tag string) ([]string, error) {
+	var prefixes []string
+	if tag != "" {
+		for prefix, wallet := range v {
+			if wallet.HasTag(tag) {
+				prefixes = append(prefixes, prefix)
+			}
+		}
+		if len(prefixes) == 0 {
+			return nil, errors.NewInvalidInputError(tag, fmt.Sprintf("no wallet in vault '%s' has this tag", config.Cfg.ActiveVault))
+		}
+	} else {
+		for _, prefix := range names {
+			if _, exists := v[prefix]; !exists {
+				return nil, errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
+			}
+			prefixes = append(prefixes, prefix)
+		}
+	}
+
+	keyed := prefixes[:0]
+	for _, prefix := range prefixes {
+		switch v[prefix].Kind {
+		case vault.KindSecret, vault.KindWatch:
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Skipping '%s': it holds no keys.", prefix), colors.Dim))
+		default:
+			keyed = append(keyed, prefix)
+		}
+	}
+	sort.Strings(keyed)
+	return keyed, nil
+}
+
+// planKeystoreSync compares the directory's files with the selected wallets'
+// addresses and the files synced before
+func planKeystoreSync(v vault.Vault, selected []string, manifest *keystoresync.Manifest, entries []keystoresync.Entry) keystoreSyncPlan {
+	var plan keystoreSyncPlan
+	wanted := map[string]bool{}
+	var targets []keystoreSyncTarget
+	for _, prefix := range selected {
+		for _, addr := range v[prefix].Addresses {
+			key := strings.ToLower(addr.Address)
+			if wanted[key] {
+				continue
+			}
+			wanted[key] = true
+			targets = append(targets, keystoreSyncTarget{prefix: prefix, account: addr.Account, index: addr.Index, address: addr.Address})
+		}
+	}
+
+	synced := map[string]bool{} // Addresses with a file written by sync
+	managed := map[string]bool{}
+	for _, file := range manifest.Files {
+		if _, err := os.Stat(filepath.Join(syncDir, file.Name)); os.IsNotExist(err) {
+			continue // Deleted: written again if still selected
+		}
+		managed[file.Name] = true
+		if !wanted[strings.ToLower(file.Address)] {
+			plan.stale = append(plan.stale, file)
+			continue
+		}
+		synced[strings.ToLower(file.Address)] = true
+		plan.keep = append(plan.keep, file)
+		if keystoresync.Hash(syncDir, file.Name) != file.SHA256 {
+			plan.modified = append(plan.modified, file)
+		}
+	}
+	external := map[string]bool{}
+	for _, entry := range entries {
+		if !managed[entry.Name] {
+			plan.external = append(plan.external, entry)
+			external[strings.ToLower(entry.Address)] = true
+		}
+	}
+
+	for _, target := range targets {
+		key := strings.ToLower(target.address)
+		switch {
+		case synced[key]:
+		case external[key]:
+			// A second file for the address would make geth refuse to pick one
+			plan.present = append(plan.present, target)
+		default:
+			plan.write = append(plan.write, target)
+		}
+	}
+	return plan
+}
+
+// printKeystoreSyncPlan lists what the sync finds and will do
+func printKeystoreSyncPlan(plan keystoreSyncPlan) {
+	for _, target := range plan.write {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("  + %s [%d] %s: to be written", target.prefix, target.index, target.address), colors.Success))
+	}
+	for _, file := range plan.modified {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("  ! %s (%s, %s): modified outside vault.module, left alone", file.Name, file.Prefix, file.Address), colors.Warning))
+	}
+	for _, file := range plan.stale {
+		action := "no longer selected; --prune removes it"
+		if syncPrune {
+			action = "no longer selected, to be removed"
+		}
+		fmt.Println(colors.SafeColor(fmt.Sprintf("  - %s (%s, %s): %s", file.Name, file.Prefix, file.Address, action), colors.Warning))
+	}
+	for _, entry := range plan.external {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("  ? %s (%s): not written by vault.module", entry.Name, entry.Address), colors.Warning))
+	}
+	for _, target := range plan.present {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("  = %s [%d] %s: already in an external key file, not written", target.prefix, target.index, target.address), colors.Dim))
+	}
+	fmt.Println(colors.SafeColor(fmt.Sprintf("%d to write, %d unchanged, %d modified, %d stale, %d external",
+		len(plan.write), len(plan.keep)-len(plan.modified), len(plan.modified), len(plan.stale), len(plan.external)), colors.Bold))
+	if len(plan.external) > 0 {
+		fmt.Println(colors.SafeColor("💡 Keys added by others can be brought into the vault with 'vault.module convert DIR --from keystore-dir'", colors.Info))
+	}
+}
+
+// passwordReference returns a file synced before and unchanged since, whose
+// password new files must share; "" if there is none
+func (plan keystoreSyncPlan) passwordReference() string {
+	modified := map[string]bool{}
+	for _, file := range plan.modified {
+		modified[file.Name] = true
+	}
+	for _, file := range append(plan.keep, plan.stale...) {
+		if !modified[file.Name] {
+			return file.Name
+		}
+	}
+	return ""
+}
+
+// writeKeystoreFiles writes the key files of the targets, wallet by wallet,
+// each one a secret retrieval. New files share the password of reference, or
+// a new one for a directory without synced files. It returns the files written.
+func writeKeystoreFiles(details config.VaultDetails, v vault.Vault, reference string, targets []keystoreSyncTarget) ([]keystoresync.File, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	var password string
+	var err error
+	if reference != "" {
+		if password, err = askForSecretInput("Keystore password"); err != nil {
+			return nil, err
+		}
+		if err := keystoresync.CheckPassword(syncDir, reference, password); err != nil {
+			return nil, err
+		}
+	} else if password, err = askForNewPassphrase("sync keystore", "New keystore password"); err != nil {
+		return nil, err
+	}
+
+	var written []keystoresync.File
+	opened := map[string]bool{}
+	for _, target := range targets {
+		wallet := v[target.prefix]
+		if !opened[target.prefix] {
+			if err := openWalletEnvelope("sync keystore", target.prefix, &wallet); err != nil {
+				return written, err
+			}
+			v[target.prefix] = wallet
+			if err := checkWalletNotFrozen("sync keystore", target.prefix, wallet); err != nil {
+				return written, err
+			}
+			if err := checkWalletSecretRateLimit("sync keystore", target.prefix, wallet); err != nil {
+				return written, err
+			}
+			recordWalletAccess(details, v, target.prefix, "privateKey")
+			opened[target.prefix] = true
+		}
+		addr := wallet.AddressAt(target.account, target.index)
+		if addr == nil || addr.PrivateKey == nil || addr.PrivateKey.IsEmpty() {
+			fmt.Println(colors.SafeColor(fmt.Sprintf("  %s [%d] %s: no private key, not written", target.prefix, target.index, target.address), colors.Warning))
+			continue
+		}
+		name, address, sum, err := keystoresync.WriteKey(syncDir, addr.PrivateKey.String(), password, syncLight)
+		if err != nil {
+			return written, err
+		}
+		written = append(written, keystoresync.File{Name: name, Address: address, Prefix: target.prefix, Account: target.account, Index: target.index, SHA256: sum})
+		audit.Logger.Warn("Private key written to keystore directory",
+			slog.String("command", "sync keystore"),
+			slog.String("vault", config.Cfg.ActiveVault),
+			slog.String("prefix", target.prefix),
+			slog.Int("index", target.index),
+			slog.String("address", address),
+			slog.String("file", name))
+	}
+	return written, nil
+}
+
+// vaultHolder names the wallet of the active vault holding address, if any
+func vaultHolder(v vault.Vault, address string) string {
+	for prefix, wallet := range v {
+		for _, addr := range wallet.Addresses {
+			if strings.EqualFold(addr.Address, address) {
+				return fmt.Sprintf(", wallet '%s'", prefix)
+			}
+		}
+	}
+	return ""
+}
+
+func init() {
+	syncKeystoreCmd.Flags().StringVar(&syncDir, "dir", "", "Keystore directory to keep in sync (created if missing)")
+	syncKeystoreCmd.Flags().StringSliceVar(&syncWallets, "wallets", nil, "Wallets to sync, comma-separated (remembered for later runs)")
+	syncKeystoreCmd.Flags().StringVar(&syncTag, "tag", "", "Sync the wallets with this tag (remembered for later runs)")
+	syncKeystoreCmd.Flags().BoolVar(&syncPrune, "prune", false, "Remove key files of addresses no longer selected")
+	syncKeystoreCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Show what would change without writing anything")
+	syncKeystoreCmd.Flags().BoolVar(&syncLight, "light", false, "Use geth's light scrypt parameters (faster, weaker)")
+	syncKeystoreCmd.Flags().BoolVar(&syncWatch, "watch", false, "Keep running and report changes made by others")
+}
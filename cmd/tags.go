@@ -0,0 +1,177 @@
+// File: cmd/tags.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var tagRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "Groups wallets with tags.",
+	Long: `Groups wallets with tags.
+
+Tags are short lowercase labels stored with the wallet, such as "treasury" or
+"ops". 'list --tag' and 'prove-bundle --tag' select wallets by tag.
+
+Examples:
+  vault.module tags add A1 treasury cold
+  vault.module tags remove A1 cold
+  vault.module tags list
+`,
+}
+
+var tagsAddCmd = &cobra.Command{
+	Use:   "add <PREFIX> <TAG...>",
+	Short: "Adds tags to a wallet.",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			return updateWalletTags(args[0], args[1:], true)
+		})
+	},
+}
+
+var tagsRemoveCmd = &cobra.Command{
+	Use:   "remove <PREFIX> <TAG...>",
+	Short: "Removes tags from a wallet.",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			return updateWalletTags(args[0], args[1:], false)
+		})
+	},
+}
+
+var tagsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the tags in the active vault and their wallets.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			byTag := map[string][]string{}
+			for prefix, wallet := range v {
+				for _, tag := range wallet.Tags {
+					byTag[tag] = append(byTag[tag], prefix)
+				}
+			}
+			if len(byTag) == 0 {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("No tagged wallets in vault '%s'.", config.Cfg.ActiveVault), colors.Info))
+				return nil
+			}
+			tags := make([]string, 0, len(byTag))
+			for tag := range byTag {
+				tags = append(tags, tag)
+			}
+			sort.Strings(tags)
+			for _, tag := range tags {
+				prefixes := byTag[tag]
+				sort.Strings(prefixes)
+				fmt.Printf("%s: %s\n", colors.SafeColor(tag, colors.Yellow), strings.Join(prefixes, ", "))
+			}
+			return nil
+		})
+	},
+}
+
+// updateWalletTags adds tags to, or removes them from, a wallet of the active vault
+func updateWalletTags(prefix string, tags []string, add bool) error {
+	for i, tag := range tags {
+		tags[i] = strings.ToLower(tag)
+		if !tagRegex.MatchString(tags[i]) {
+			return errors.NewInvalidInputError(tag, "tags start with a letter or digit and contain only lowercase letters, digits, '-' and '_' (max 32)")
+		}
+	}
+	if err := checkVaultStatus(); err != nil {
+		return err
+	}
+	activeVault, err := config.GetActiveVault()
+	if err != nil {
+		return err
+	}
+	if add {
+		if err := checkTagTaxonomy(activeVault, tags); err != nil {
+			return err
+		}
+	}
+
+	v, err := vault.LoadVault(activeVault)
+	if err != nil {
+		return errors.NewVaultLoadError(activeVault.KeyFile, err)
+	}
+	defer func() {
+		for _, wallet := range v {
+			wallet.Clear()
+		}
+	}()
+
+	wallet, exists := v[prefix]
+	if !exists {
+		return errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
+	}
+	changed := false
+	for _, tag := range tags {
+		if add && !wallet.HasTag(tag) {
+			wallet.Tags = append(wallet.Tags, tag)
+			changed = true
+		}
+		if !add && wallet.HasTag(tag) {
+			kept := wallet.Tags[:0:0]
+			for _, t := range wallet.Tags {
+				if t != tag {
+					kept = append(kept, t)
+				}
+			}
+			wallet.Tags = kept
+			changed = true
+		}
+	}
+	if !changed {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Tags of '%s' unchanged.", prefix), colors.Info))
+		return nil
+	}
+	sort.Strings(wallet.Tags)
+	v[prefix] = wallet
+	if err := vault.SaveVault(activeVault, v); err != nil {
+		return errors.NewVaultSaveError(activeVault.KeyFile, err)
+	}
+
+	audit.Logger.Info("Wallet tags updated", slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.String("tags", strings.Join(wallet.Tags, ",")))
+	if len(wallet.Tags) == 0 {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Wallet '%s' has no tags.", prefix), colors.Success))
+	} else {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Tags of '%s': %s", prefix, strings.Join(wallet.Tags, ", ")), colors.Success))
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// File: cmd/terraform.go
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+// maxTerraformQuerySize bounds the query read from stdin
+const maxTerraformQuerySize = 64 * 1024
+
+var terraformBridgeCmd = &cobra.Command{
+	Use:   "terraform-bridge",
+	Short: "Terraform/OpenTofu external data source for public wallet fields.",
+	Long: `Implements the Terraform/OpenTofu external program protocol.
+
+The query is read as a JSON object from stdin and the result is written as a
+flat JSON object of strings to stdout. Only public fields are returned:
+Terraform stores data source results in its state file in plaintext, so
+private keys and mnemonics are never available through the bridge.
+
+Query keys:
+  prefix   - wallet prefix (required)
+  index    - address index (default "0")
+  vault    - vault name (default: the active vault)
+
+Result keys: address, path, index, prefix, type, vault
+
+Example:
+  data "external" "deployer" {
+    program = ["vault.module", "terraform-bridge"]
+    query   = { prefix = "A1", index = "0" }
+  }
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			raw, err := io.ReadAll(io.LimitReader(os.Stdin, maxTerraformQuerySize+1))
+			if err != nil {
+				return errors.NewInvalidInputError("stdin", "failed to read query")
+			}
+			if len(raw) > maxTerraformQuerySize {
+				return errors.NewInvalidInputError("stdin", "query is too large")
+			}
+
+			// The protocol only passes string values
+			var query map[string]string
+			if err := json.Unmarshal(raw, &query); err != nil {
+				return errors.NewInvalidInputError("query", "query must be a JSON object with string values")
+			}
+
+			prefix := query["prefix"]
+			if prefix == "" {
+				return errors.NewInvalidInputError("prefix", "query key 'prefix' is required")
+			}
+			index := 0
+			if s, ok := query["index"]; ok && s != "" {
+				index, err = strconv.Atoi(s)
+				if err != nil || index < 0 || index > maxIndexValue {
+					return errors.NewInvalidInputError(s, fmt.Sprintf("query key 'index' must be a number between 0 and %d", maxIndexValue))
+				}
+			}
+
+			vaultName := query["vault"]
+			if vaultName == "" {
+				if err := checkVaultStatus(); err != nil {
+					return err
+				}
+				vaultName = config.Cfg.ActiveVault
+			}
+			details, ok := config.Cfg.Vaults[vaultName]
+			if !ok {
+				return errors.NewVaultNotFoundError(vaultName)
+			}
+			if vault.IsMarkedTampered(details) {
+				return errors.NewVaultTamperedError(vaultName)
+			}
+
+			v, err := vault.LoadVault(details)
+			if err != nil {
+				return errors.NewVaultLoadError(details.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			wallet, exists := v[prefix]
+			if !exists {
+				return errors.NewWalletNotFoundError(prefix, vaultName)
+			}
+			addressData := wallet.AddressAt(0, index)
+			if addressData == nil {
+				return errors.NewAddressNotFoundError(prefix, index)
+			}
+
+			audit.Logger.Info("Public data accessed",
+				slog.String("command", "terraform-bridge"),
+				slog.String("vault", vaultName),
+				slog.String("prefix", prefix),
+				slog.Int("index", index),
+				slog.String("field", "address"),
+			)
+
+			result := map[string]string{
+				"address": addressData.Address,
+				"path":    addressData.Path,
+				"index":   strconv.Itoa(addressData.Index),
+				"prefix":  prefix,
+				"type":    details.Type,
+				"vault":   vaultName,
+			}
+			return json.NewEncoder(os.Stdout).Encode(result)
+		})
+	},
+}
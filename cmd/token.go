@@ -21,6 +21,12 @@ var tokenCmd = &cobra.Command{
 This command generates and displays a secret token that can be used
 to enable programmatic mode for automated operations.
 
+In programmatic mode, and with --json, long operations report their progress
+on stderr as newline-delimited JSON events instead of a progress line:
+{"event":"start|progress|end","stage":"vault.decrypt","done":3,"total":10,
+"percent":30,"status":"done|cancelled|failed","elapsed_ms":412}. The stages
+are vault.decrypt, vault.save, derive and discover.
+
 Examples:
   vault.module token
 `,
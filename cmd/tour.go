@@ -0,0 +1,149 @@
+// File: cmd/tour.go
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+)
+
+// tourPage is one screen of the onboarding tour
+type tourPage struct {
+	title string
+	lines []string
+}
+
+// tourPages returns the pages of the tour. They are built on use so the
+// badges follow the color settings.
+func tourPages() []tourPage {
+	return []tourPage{
+		{
+			title: "Vaults, wallets and prefixes",
+			lines: []string{
+				"A vault is one age-encrypted file holding wallets. 'vaults list' shows them,",
+				"'vaults use NAME' picks the active one, and --vault NAME (or VAULT_NAME)",
+				"switches for a single command.",
+				"",
+				"Every wallet has a prefix. Commands take the prefix, then what they act on:",
+				"  vault.module list                  wallets and addresses, no secrets",
+				"  vault.module get PREFIX address    one field of a wallet",
+				"  vault.module derive PREFIX         the next HD address",
+				"",
+				"Every command has --help with examples.",
+			},
+		},
+		{
+			title: "Security indicators",
+			lines: []string{
+				"'list' marks wallets that need care:",
+				"  " + colors.SafeColor("[FROZEN]", colors.Error) + "         no secret leaves the wallet until 'unfreeze'",
+				"  " + colors.SafeColor("[SEALED]", colors.Cyan) + "         the secret is in an envelope that needs its own key",
+				"  " + colors.SafeColor("[checklist 2/5]", colors.Yellow) + "  a cold-storage checklist is not finished",
+				"",
+				"A vault changed outside this tool is marked tampered by 'vaults watch' and",
+				"refused until 'vaults verify' clears it. Warnings about swap, screen",
+				"capture or an outdated inheritance plan are printed before a command runs.",
+				"\"strict\": true in config.json picks the most careful setting of each.",
+			},
+		},
+		{
+			title: "Where secrets are shown",
+			lines: []string{
+				"Secrets are hidden unless you ask for one field of one wallet:",
+				"  'list' shows masked hints such as 0x1...cdf, never whole keys",
+				"  'get PREFIX privatekey' copies to the clipboard, which is cleared after",
+				"  clipboard_timeout seconds; under the strict profile it is shown on a",
+				"  separate screen instead, locked after reveal_idle_lock seconds",
+				"  'exec' hands secrets to another program in its environment, unprinted",
+				"",
+				"With \"no_echo_secrets\": true nothing is ever printed to the terminal.",
+				"Every reveal is written to audit.log.",
+			},
+		},
+	}
+}
+
+var tourCmd = &cobra.Command{
+	Use:   "tour",
+	Short: "Shows the introduction for new users.",
+	Long: `Shows the introduction for new users.
+
+The tour explains how commands address vaults and wallets, what the security
+markers in the output mean and where secrets are shown or kept hidden. It is
+shown once, before the first interactive command; "tour_seen" in config.json
+records that it was shown or skipped. This command shows it again.
+
+Examples:
+  vault.module tour
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			return runTour()
+		})
+	},
+}
+
+// maybeShowTour shows the tour to a first-time user before the command runs.
+// It is skipped when nobody is there to read it: programmatic mode, or
+// stdin/stdout not a terminal.
+func maybeShowTour(cmd *cobra.Command) error {
+	if config.Cfg.TourSeen || programmaticMode {
+		return nil
+	}
+	switch cmd.Name() {
+	case "tour", "help", "completion", "vault.module":
+		return nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil
+	}
+	return runTour()
+}
+
+// runTour pages through the tour and records that it was seen. q skips the
+// rest, which also counts as seen.
+func runTour() error {
+	reader := bufio.NewReader(os.Stdin)
+	pages := tourPages()
+	completed := true
+	for i, page := range pages {
+		fmt.Println()
+		fmt.Println(colors.SafeColor(fmt.Sprintf("%s (%d/%d)", page.title, i+1, len(pages)), colors.Bold))
+		fmt.Println()
+		for _, line := range page.lines {
+			fmt.Println(line)
+		}
+		fmt.Println()
+		if i == len(pages)-1 {
+			fmt.Println(colors.SafeColor("Run 'vault.module tour' to see this again.", colors.Dim))
+			break
+		}
+		fmt.Print(colors.SafeColor("Enter: next, q: skip the tour ", colors.Dim))
+		answer, _ := reader.ReadString('\n')
+		if strings.TrimSpace(strings.ToLower(answer)) == "q" {
+			completed = false
+			break
+		}
+	}
+	fmt.Println()
+
+	audit.Logger.Info("Onboarding tour shown", slog.Bool("completed", completed))
+	if config.Cfg.TourSeen {
+		return nil
+	}
+	config.Cfg.TourSeen = true
+	if err := config.SaveConfig(); err != nil {
+		return errors.NewConfigSaveError(config.ConfigFile, err)
+	}
+	return nil
+}
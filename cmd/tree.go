@@ -0,0 +1,323 @@
+// File: cmd/tree.go
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"golang.org/x/term"
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var treeInteractive bool
+var treeJson bool
+
+var treeCmd = &cobra.Command{
+	Use:   "tree <PREFIX>",
+	Short: "Shows the derivation tree of a wallet in the active vault.",
+	Long: `Shows the derivation tree of a wallet in the active vault.
+
+Each level of the wallet's derivation paths is a branch: purpose, coin type,
+account and change chain, down to the derived addresses. Every address shows
+whether the vault holds its key, keeps it sealed in the wallet's envelope
+(see 'envelope'), or only watches it. The change chains list the derived
+indices, so gaps left by skipped or deleted addresses stand out. Addresses
+imported without a derivation path are listed apart.
+
+--interactive browses the tree on the terminal's alternate screen: the arrow
+keys or j and k move, right and left (or l and h) expand and collapse a
+branch, space toggles it and q closes. No secret is shown.
+
+Examples:
+  vault.module tree A1
+  vault.module tree A1 --interactive
+  vault.module tree A1 --json
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			if treeInteractive && treeJson {
+				return errors.NewInvalidInputError("--interactive", "--interactive and --json cannot be combined")
+			}
+			if treeInteractive && programmaticMode {
+				return errors.NewProgrammaticModeError("tree --interactive")
+			}
+
+			prefix := args[0]
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+			wallet, exists := v[prefix]
+			if !exists {
+				return errors.NewWalletNotFoundError(prefix, config.Cfg.ActiveVault)
+			}
+			if len(wallet.Addresses) == 0 {
+				return errors.NewInvalidInputError(prefix, "the wallet has no addresses to show")
+			}
+
+			root := wallet.DerivationTree()
+			audit.Logger.Info("Derivation tree shown", slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.Bool("interactive", treeInteractive))
+
+			if treeJson {
+				data, err := json.MarshalIndent(root, "", "  ")
+				if err != nil {
+					return errors.New(errors.ErrCodeInternal, "failed to format JSON").WithContext("marshal_error", err.Error())
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+			if treeInteractive {
+				return browseDerivationTree(prefix, wallet, root)
+			}
+
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Wallet '%s' (%s)", prefix, walletKeySource(wallet)), colors.Bold))
+			for _, line := range derivationTreeLines(root, nil) {
+				fmt.Println(line.text)
+			}
+			fmt.Println(colors.SafeColor(keyStatusSummary(root), colors.Dim))
+			return nil
+		})
+	},
+}
+
+// walletKeySource describes where the wallet's keys come from
+func walletKeySource(w vault.Wallet) string {
+	switch {
+	case w.Kind == vault.KindWatch && w.XPub != "":
+		return "watch-only, account xpub"
+	case w.Kind == vault.KindWatch:
+		return "watch-only"
+	case w.Sealed():
+		return "keys sealed in an envelope"
+	case w.Mnemonic != nil && !w.Mnemonic.IsEmpty():
+		return "HD, mnemonic, path scheme " + w.Scheme()
+	case w.IsHD():
+		return "HD, BIP-32 seed, path scheme " + w.Scheme()
+	}
+	return "imported keys"
+}
+
+// keyStatusSummary counts the addresses by key status
+func keyStatusSummary(root *vault.DerivationNode) string {
+	counts := map[string]int{}
+	var count func(n *vault.DerivationNode)
+	count = func(n *vault.DerivationNode) {
+		if n.IsAddress() {
+			counts[n.KeyStatus]++
+		}
+		for _, c := range n.Children {
+			count(c)
+		}
+	}
+	count(root)
+	total := root.AddressCount()
+	parts := []string{fmt.Sprintf("%d addresses", total)}
+	if total == 1 {
+		parts[0] = "1 address"
+	}
+	for _, status := range []string{vault.KeyHeld, vault.KeySealed, vault.KeyWatchOnly} {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], keyStatusNames[status]))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// keyStatusNames describe the key statuses in summaries
+var keyStatusNames = map[string]string{
+	vault.KeyHeld:      "with keys",
+	vault.KeySealed:    "sealed",
+	vault.KeyWatchOnly: "watch-only",
+}
+
+// keyStatusBadge shows the key status of an address
+func keyStatusBadge(status string) string {
+	switch status {
+	case vault.KeyHeld:
+		return colors.SafeColor("key", colors.Success)
+	case vault.KeySealed:
+		return colors.SafeColor("sealed", colors.Yellow)
+	}
+	return colors.SafeColor("watch-only", colors.Dim)
+}
+
+// treeLine is a printed node of a derivation tree
+type treeLine struct {
+	node *vault.DerivationNode
+	text string
+}
+
+// derivationTreeLines renders root with box-drawing lines, one line per node.
+// Branches in collapsed are shown without their children.
+func derivationTreeLines(root *vault.DerivationNode, collapsed map[*vault.DerivationNode]bool) []treeLine {
+	lines := []treeLine{{node: root, text: derivationNodeText(root, collapsed)}}
+	var walk func(n *vault.DerivationNode, indent string)
+	walk = func(n *vault.DerivationNode, indent string) {
+		if collapsed[n] {
+			return
+		}
+		for i, c := range n.Children {
+			branch, next := "├── ", "│   "
+			if i == len(n.Children)-1 {
+				branch, next = "└── ", "    "
+			}
+			lines = append(lines, treeLine{node: c, text: colors.Text(indent+branch) + derivationNodeText(c, collapsed)})
+			walk(c, indent+next)
+		}
+	}
+	walk(root, "")
+	return lines
+}
+
+// derivationNodeText is the line of a node, without its branch
+func derivationNodeText(n *vault.DerivationNode, collapsed map[*vault.DerivationNode]bool) string {
+	if n.IsAddress() {
+		segment := n.Segment
+		if segment == "" {
+			segment = "-"
+		}
+		return colors.SafeColor(segment, colors.White) + "  " + colors.SafeColor(n.Address, colors.Cyan) + "  " + keyStatusBadge(n.KeyStatus)
+	}
+	text := colors.SafeColor(n.Segment, colors.Bold)
+	if n.Segment != "" {
+		text += "  "
+	}
+	text += n.Label
+	if ranges := n.IndexRanges(); ranges != "" {
+		text += colors.SafeColor(fmt.Sprintf(" (indices %s)", ranges), colors.Dim)
+	}
+	if collapsed[n] {
+		text += colors.SafeColor(fmt.Sprintf(" [+%d]", n.AddressCount()), colors.Dim)
+	}
+	return text
+}
+
+// browseDerivationTree shows the tree on the alternate screen and lets the
+// user move through it and fold its branches
+func browseDerivationTree(prefix string, wallet vault.Wallet, root *vault.DerivationNode) error {
+	tty, err := openRevealTTY()
+	if err != nil {
+		return errors.NewInvalidInputError("--interactive", "needs a terminal")
+	}
+	defer tty.file.Close()
+
+	fmt.Fprint(tty.file, "\033[?1049h")
+	defer fmt.Fprint(tty.file, "\033[H\033[2J\033[?1049l")
+	restore := security.RegisterFuncGlobal("tree screen", security.PriorityTerminal, tty.restore)
+	defer security.UnregisterGlobal(restore)
+	if err := tty.makeRaw(); err != nil {
+		return errors.Wrap(errors.ErrCodeSystem, "could not switch the terminal to raw mode", err)
+	}
+	defer tty.restoreMode()
+
+	collapsed := map[*vault.DerivationNode]bool{}
+	parents := map[*vault.DerivationNode]*vault.DerivationNode{}
+	var link func(n *vault.DerivationNode)
+	link = func(n *vault.DerivationNode) {
+		for _, c := range n.Children {
+			parents[c] = n
+			link(c)
+		}
+	}
+	link(root)
+
+	cursor, top := 0, 0
+	buf := make([]byte, 16)
+	for {
+		lines := derivationTreeLines(root, collapsed)
+		if cursor >= len(lines) {
+			cursor = len(lines) - 1
+		}
+		height := 24
+		if _, h, err := term.GetSize(tty.fd); err == nil && h > 0 {
+			height = h
+		}
+		rows := height - 4 // Header and footer
+		if rows < 1 {
+			rows = 1
+		}
+		if cursor < top {
+			top = cursor
+		}
+		if cursor >= top+rows {
+			top = cursor - rows + 1
+		}
+
+		screen := []string{colors.SafeColor(fmt.Sprintf("Wallet '%s' (%s)", prefix, walletKeySource(wallet)), colors.Bold), ""}
+		for i := top; i < len(lines) && i < top+rows; i++ {
+			marker := "  "
+			if i == cursor {
+				marker = colors.SafeColor("> ", colors.Bold)
+			}
+			screen = append(screen, marker+lines[i].text)
+		}
+		selected := lines[cursor].node
+		status := selected.Path
+		if selected.IsAddress() {
+			status = fmt.Sprintf("%s  %s  %s", selected.Path, selected.Address, keyStatusNames[selected.KeyStatus])
+		}
+		screen = append(screen, "", colors.SafeColor(status+"  |  arrows or hjkl move and fold, space toggles, q closes", colors.Dim))
+		tty.draw(screen...)
+
+		n, err := tty.file.Read(buf)
+		if err != nil {
+			return nil
+		}
+		switch key := string(buf[:n]); key {
+		case "q", "Q", string(rune(keyEsc)), string(rune(keyCtrlC)):
+			return nil
+		case "k", "\033[A":
+			if cursor > 0 {
+				cursor--
+			}
+		case "j", "\033[B":
+			if cursor < len(lines)-1 {
+				cursor++
+			}
+		case "l", "\033[C", string(rune(keyEnter)):
+			delete(collapsed, selected)
+		case "h", "\033[D":
+			if len(selected.Children) > 0 && !collapsed[selected] {
+				collapsed[selected] = true
+			} else if parent := parents[selected]; parent != nil {
+				// Move up to the parent, as file trees do
+				for i, line := range lines {
+					if line.node == parent {
+						cursor = i
+					}
+				}
+			}
+		case " ":
+			if len(selected.Children) > 0 {
+				collapsed[selected] = !collapsed[selected]
+			}
+		}
+	}
+}
+
+func init() {
+	treeCmd.Flags().BoolVarP(&treeInteractive, "interactive", "i", false, "Browse the tree on the terminal, folding its branches.")
+	treeCmd.Flags().BoolVar(&treeJson, "json", false, "Output the tree in JSON format.")
+}
@@ -4,15 +4,24 @@ package cmd
 import (
 	"bufio"
 	"fmt"
+	"log/slog"
 	"os"
+	"regexp"
+	"sort"
 	"strings"
 	"syscall"
 
 	"golang.org/x/term"
+	"vault.module/internal/actions"
+	"vault.module/internal/audit"
 	"vault.module/internal/colors"
 	"vault.module/internal/config"
 	"vault.module/internal/errors"
+	"vault.module/internal/hooks"
+	"vault.module/internal/leakcheck"
 	"vault.module/internal/security"
+	"vault.module/internal/vault"
+	"vault.module/internal/webhook"
 )
 
 func checkVaultStatus() error {
@@ -25,13 +34,17 @@ func checkVaultStatus() error {
 		return errors.NewVaultNotFoundError(config.Cfg.ActiveVault)
 	}
 
+	if vault.IsMarkedTampered(activeVault) {
+		return errors.NewVaultTamperedError(config.Cfg.ActiveVault)
+	}
+
 	// Check file existence
 	if _, err := os.Stat(activeVault.KeyFile); os.IsNotExist(err) {
 		return errors.NewFileSystemError("access", activeVault.KeyFile, err).
 			WithDetails("vault key file not found")
 	}
 
-	if activeVault.Encryption == "yubikey" && activeVault.RecipientsFile != "" {
+	if config.UsesRecipientsFile(activeVault.Encryption) && activeVault.RecipientsFile != "" {
 		if _, err := os.Stat(activeVault.RecipientsFile); os.IsNotExist(err) {
 			return errors.NewFileSystemError("access", activeVault.RecipientsFile, err).
 				WithDetails("recipients file not found")
@@ -116,3 +129,347 @@ func askForConfirmation(prompt string) bool {
 	response = strings.TrimSpace(strings.ToLower(response))
 	return response == "y" || response == "yes"
 }
+
+// confirmNoScreenCapture blocks a secret reveal while screen-sharing or recording
+// software is running, unless the user explicitly types "reveal" to override.
+func confirmNoScreenCapture(command string, allow bool) error {
+	if allow {
+		return nil
+	}
+	processes := security.DetectScreenCapture()
+	if len(processes) == 0 {
+		return nil
+	}
+
+	audit.Logger.Warn("Screen capture software detected before secret reveal",
+		slog.String("command", command),
+		slog.String("processes", strings.Join(processes, ",")))
+
+	fmt.Println(colors.SafeColor("WARNING: screen sharing or recording software is running:", colors.Warning))
+	for _, p := range processes {
+		fmt.Printf("  - %s\n", p)
+	}
+	fmt.Println(colors.SafeColor("Secrets may be captured if they appear on screen or in a shared clipboard.", colors.Warning))
+
+	answer, err := askForInput("Type 'reveal' to continue anyway")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(answer) != "reveal" {
+		return errors.New(errors.ErrCodePermission, "secret reveal cancelled because screen capture software is running").
+			WithDetails("close the listed applications or pass --allow-screen-capture")
+	}
+
+	audit.Logger.Warn("Screen capture warning overridden", slog.String("command", command))
+	return nil
+}
+
+// checkWalletNotFrozen refuses secret retrieval and signing for a wallet under a legal hold.
+// Every refusal is audited as critical so attempts on a frozen wallet stand out.
+// Stubs of archived wallets are refused too: their secrets are in the archive vault.
+func checkWalletNotFrozen(command, prefix string, wallet vault.Wallet) error {
+	if wallet.Archived() {
+		return archivedWalletError(prefix, wallet)
+	}
+	if wallet.Frozen == nil {
+		return nil
+	}
+	audit.Logger.Error("Access to frozen wallet refused",
+		slog.String("command", command),
+		slog.String("vault", config.Cfg.ActiveVault),
+		slog.String("prefix", prefix),
+		slog.String("reason", wallet.Frozen.Reason),
+		slog.String("severity", string(errors.SeverityCritical)),
+	)
+	return errors.NewWalletFrozenError(prefix, wallet.Frozen.Reason)
+}
+
+// archivedWalletError refuses access to the secrets of an archived wallet's stub
+func archivedWalletError(prefix string, wallet vault.Wallet) error {
+	return errors.New(errors.ErrCodeWalletInvalid, fmt.Sprintf("wallet '%s' is archived in vault '%s'", prefix, wallet.Archive.Vault)).
+		WithDetails(fmt.Sprintf("bring it back with 'unarchive %s'", prefix))
+}
+
+// missingKeyError is the error for an address of the wallet without a private
+// key: the archive's, for stubs of archived wallets
+func missingKeyError(prefix string, index int, wallet vault.Wallet) error {
+	if wallet.Archived() {
+		return archivedWalletError(prefix, wallet)
+	}
+	return errors.NewAddressNotFoundError(prefix, index).WithDetails("address does not have a private key")
+}
+
+// openWalletEnvelope asks for the envelope passphrase of a sealed wallet and restores
+// its secrets in memory. It does nothing for wallets that are not sealed.
+func openWalletEnvelope(command, prefix string, wallet *vault.Wallet) error {
+	if !wallet.Sealed() {
+		return nil
+	}
+	if err := checkWalletNotFrozen(command, prefix, *wallet); err != nil {
+		return err
+	}
+	if programmaticMode {
+		return errors.NewProgrammaticModeError(command + " on a sealed wallet")
+	}
+	fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("Wallet '%s' is sealed. Enter its envelope passphrase.", prefix), colors.Info))
+	if err := vault.OpenWallet(wallet); err != nil {
+		audit.Logger.Error("Wallet envelope not opened", slog.String("command", command), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix), slog.String("error", err.Error()))
+		return err
+	}
+	audit.Logger.Warn("Wallet envelope opened", slog.String("command", command), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix))
+	return nil
+}
+
+// hasSecretField reports whether a field mapping asks for any secret field
+func hasSecretField(fields map[string]string) bool {
+	for _, field := range fields {
+		if field == "privatekey" || field == "mnemonic" || field == "seed" || field == "secret" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkVaultNotFrozen applies checkWalletNotFrozen to every wallet, for commands
+// that release the whole vault such as export
+func checkVaultNotFrozen(command string, v vault.Vault) error {
+	prefixes := v.FrozenPrefixes()
+	if len(prefixes) == 0 {
+		return nil
+	}
+	sort.Strings(prefixes)
+	return checkWalletNotFrozen(command, prefixes[0], v[prefixes[0]])
+}
+
+// confirmWeakSecret reports the entropy problems found in a wallet's key material and
+// asks the user to type "store" to keep it anyway. A wallet without problems passes.
+func confirmWeakSecret(command, prefix string, issues []string) bool {
+	if len(issues) == 0 {
+		return true
+	}
+	fmt.Println(colors.SafeColor(fmt.Sprintf("WARNING: the key material of wallet '%s' looks weak:", prefix), colors.Warning))
+	for _, issue := range issues {
+		fmt.Printf("  - %s\n", issue)
+	}
+	fmt.Println(colors.SafeColor("Funds held by weak or published keys can be swept by anyone.", colors.Warning))
+	audit.Logger.Warn("Weak key material detected",
+		slog.String("command", command),
+		slog.String("vault", config.Cfg.ActiveVault),
+		slog.String("prefix", prefix),
+		slog.String("issues", strings.Join(issues, "; ")),
+	)
+
+	answer, err := askForInput("Type 'store' to store it anyway")
+	if err != nil || answer != "store" {
+		return false
+	}
+	audit.Logger.Warn("Weak key material stored after confirmation", slog.String("command", command), slog.String("prefix", prefix))
+	return true
+}
+
+// warnKnownCompromised warns when a wallet matches the known-compromised key
+// database. A database error is reported but does not stop the command.
+func warnKnownCompromised(command, prefix string, wallet vault.Wallet) {
+	matches, err := leakcheck.MatchWallet(wallet)
+	if err != nil {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Could not check wallet '%s' for leaked keys: %s", prefix, errors.FormatForUser(err)), colors.Warning))
+		return
+	}
+	if len(matches) == 0 {
+		return
+	}
+	fmt.Println(colors.SafeColor(fmt.Sprintf("WARNING: wallet '%s' matches the known-compromised key database:", prefix), colors.Error))
+	for _, m := range matches {
+		fmt.Printf("  - %s\n", m)
+	}
+	fmt.Println(colors.SafeColor("Assume anything sent to these addresses will be stolen. Move funds to fresh keys.", colors.Error))
+	audit.Logger.Warn("Wallet matches known-compromised keys",
+		slog.String("command", command),
+		slog.String("vault", config.Cfg.ActiveVault),
+		slog.String("prefix", prefix),
+		slog.String("matches", strings.Join(matches, "; ")),
+	)
+}
+
+// parseFieldMappings parses NAME=FIELD flag values into a map of output name to wallet field.
+// Names must match nameRegex; fields are address, privatekey, mnemonic or secret.
+func parseFieldMappings(flag string, mappings []string, nameRegex *regexp.Regexp) (map[string]string, error) {
+	fields := make(map[string]string, len(mappings))
+	for _, m := range mappings {
+		name, field, ok := strings.Cut(m, "=")
+		if !ok || !nameRegex.MatchString(name) {
+			return nil, errors.NewInvalidInputError(m, fmt.Sprintf("%s must be NAME=FIELD with a valid name", flag))
+		}
+		field = strings.ToLower(field)
+		switch field {
+		case "address", "privatekey", "mnemonic", "seed", "secret":
+		default:
+			return nil, errors.NewInvalidInputError(field, "field must be one of: address, privatekey, mnemonic, seed, secret")
+		}
+		if _, dup := fields[name]; dup {
+			return nil, errors.NewInvalidInputError(name, "name mapped more than once")
+		}
+		fields[name] = field
+	}
+	return fields, nil
+}
+
+// fieldMappingNames returns the mapped names in a stable order
+func fieldMappingNames(fields map[string]string) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveWalletFields looks up the mapped fields in the wallet, using the address at index
+// for address and privatekey. It reports whether any of the values is a secret.
+func resolveWalletFields(wallet vault.Wallet, prefix string, index int, fields map[string]string) (map[string]string, bool, error) {
+	addressData := wallet.AddressAt(0, index)
+
+	values := make(map[string]string, len(fields))
+	hasSecrets := false
+	for name, field := range fields {
+		switch field {
+		case "address":
+			if addressData == nil {
+				return nil, false, errors.NewAddressNotFoundError(prefix, index)
+			}
+			values[name] = addressData.Address
+		case "privatekey":
+			if addressData == nil || addressData.PrivateKey == nil {
+				return nil, false, missingKeyError(prefix, index, wallet)
+			}
+			values[name] = addressData.PrivateKey.String()
+			hasSecrets = true
+		case "mnemonic":
+			if wallet.Mnemonic == nil || wallet.Mnemonic.String() == "" {
+				if wallet.Archived() {
+					return nil, false, archivedWalletError(prefix, wallet)
+				}
+				return nil, false, errors.NewWalletInvalidError(prefix, "wallet does not have a mnemonic phrase")
+			}
+			values[name] = wallet.Mnemonic.String()
+			hasSecrets = true
+		case "seed":
+			if wallet.Seed == nil || wallet.Seed.String() == "" {
+				return nil, false, errors.NewWalletInvalidError(prefix, "wallet was not imported from a BIP-32 seed")
+			}
+			values[name] = wallet.Seed.String()
+			hasSecrets = true
+		case "secret":
+			if wallet.Secret == nil || wallet.Secret.String() == "" {
+				return nil, false, errors.NewWalletInvalidError(prefix, "wallet is not a generic secret entry")
+			}
+			values[name] = wallet.Secret.String()
+			hasSecrets = true
+		}
+	}
+	return values, hasSecrets, nil
+}
+
+// notifyVaultMutation sends a mutation event for the active vault to the configured webhooks.
+// Delivery failures are audited and reported, but never fail the command.
+func notifyVaultMutation(event, prefix, details string) {
+	if len(config.Cfg.Webhooks) == 0 {
+		return
+	}
+	errs := webhook.Notify(webhook.Event{
+		Event:   event,
+		Vault:   config.Cfg.ActiveVault,
+		Prefix:  prefix,
+		Details: details,
+	})
+	for _, err := range errs {
+		audit.Logger.Error("Webhook delivery failed", slog.String("event", event), slog.String("error", err.Error()))
+		fmt.Fprintln(os.Stderr, colors.SafeColor("Warning: webhook delivery failed: "+err.Error(), colors.Warning))
+	}
+}
+
+// runPostImportHooks runs the post-import hook scripts of the active vault with
+// the imported prefixes, if any. A failing script only warns: the wallets are
+// saved.
+func runPostImportHooks(imported []string, details string) {
+	if len(imported) == 0 {
+		return
+	}
+	prefixes := append([]string(nil), imported...)
+	sort.Strings(prefixes)
+	errs := hooks.Run(hooks.Event{
+		Hook:     hooks.PostImport,
+		Vault:    config.Cfg.ActiveVault,
+		Prefixes: prefixes,
+		Details:  details,
+	})
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, colors.SafeColor("Warning: "+err.Error(), colors.Warning))
+	}
+}
+
+// prefixPolicy returns the naming policy of a vault's wallet prefixes
+func prefixPolicy(details config.VaultDetails) actions.PrefixPolicy {
+	policy := actions.PrefixPolicy{
+		Pattern:   details.PrefixPattern,
+		MaxLength: details.PrefixMaxLength,
+		Reserved:  details.ReservedPrefixes,
+		Scheme:    details.PrefixScheme,
+	}
+	if len(details.Namespaces) > 0 {
+		policy.Namespaces = make(map[string]actions.PrefixPolicy, len(details.Namespaces))
+		for namespace, ns := range details.Namespaces {
+			policy.Namespaces[namespace] = actions.PrefixPolicy{
+				Pattern:   ns.PrefixPattern,
+				MaxLength: ns.PrefixMaxLength,
+				Reserved:  ns.ReservedPrefixes,
+				Scheme:    ns.PrefixScheme,
+			}
+		}
+	}
+	return policy
+}
+
+// activePrefixPolicy returns the naming policy of the active vault, failing
+// if config.json gives it one that cannot be applied
+func activePrefixPolicy() (actions.PrefixPolicy, error) {
+	activeVault, err := config.GetActiveVault()
+	if err != nil {
+		return actions.PrefixPolicy{}, nil
+	}
+	policy := prefixPolicy(activeVault)
+	if err := policy.Check(); err != nil {
+		return actions.PrefixPolicy{}, errors.New(errors.ErrCodeConfigValidation, fmt.Sprintf("vault '%s' has an invalid prefix policy in config.json", config.Cfg.ActiveVault)).WithDetails(err.Error())
+	}
+	return policy, nil
+}
+
+// validatePrefix checks a new wallet prefix against the naming policy of the
+// active vault
+func validatePrefix(prefix string) error {
+	policy, err := activePrefixPolicy()
+	if err != nil {
+		return err
+	}
+	return policy.Validate(prefix)
+}
+
+// checkTagTaxonomy rejects tags outside the taxonomy of the vault, if it has one
+func checkTagTaxonomy(details config.VaultDetails, tags []string) error {
+	if len(details.Tags) == 0 {
+		return nil
+	}
+	for _, tag := range tags {
+		allowed := false
+		for _, t := range details.Tags {
+			if t == tag {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return errors.NewInvalidInputError(tag, fmt.Sprintf("not in the tags of vault '%s': %s", config.Cfg.ActiveVault, strings.Join(details.Tags, ", ")))
+		}
+	}
+	return nil
+}
@@ -7,19 +7,33 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"vault.module/internal/actions"
+	"vault.module/internal/approval"
 	"vault.module/internal/audit"
 	"vault.module/internal/colors"
 	"vault.module/internal/config"
 	"vault.module/internal/constants"
 	"vault.module/internal/errors"
+	"vault.module/internal/security"
 	"vault.module/internal/vault"
+	"vault.module/internal/vaulttemplate"
 )
 
 var keyFile, recipientsFile, vaultType string
+var vaultYubikeySerial, vaultYubikeySlot string
+var vaultEncryption, vaultIdentityFile string
+var vaultTPMPCRs string
+var vaultSecondFactor, vaultSecondFactorFile string
+var vaultApprovalURL string
+var vaultApprovalTimeout int
+var vaultTemplate string
 var vaultsDeleteYesFlag bool
+var vaultsDeleteCancelFlag bool
+var vaultsDeletePurgeFlag bool
 
 // vaultsCmd represents the base command for vault management.
 var vaultsCmd = &cobra.Command{
@@ -30,6 +44,14 @@ var vaultsCmd = &cobra.Command{
 This command allows you to manage multiple vault configurations.
 Use subcommands to add, list, delete, or switch between vaults.
 
+Each vault can set flag defaults in config.json, applied to commands run on
+it unless the flag is given on the command line:
+
+  "defaults": {"index": 2, "clipboard_timeout": 10, "output": "json"}
+
+index applies to get, exec, prove and provision; clipboard_timeout to get and
+secret get; output "json" turns on --json for get and list.
+
 Examples:
   vault.module vaults list
   vault.module vaults add myvault --type evm
@@ -74,9 +96,59 @@ var vaultsListCmd = &cobra.Command{
 					)
 				}
 				fmt.Printf("     - Key File: %s\n", colors.SafeColor(details.KeyFile, colors.Yellow))
-				if details.Encryption == constants.EncryptionYubiKey {
+				if config.UsesRecipientsFile(details.Encryption) {
 					fmt.Printf("     - Recipients File: %s\n", colors.SafeColor(details.RecipientsFile, colors.Yellow))
 				}
+				if details.IdentityFile != "" {
+					fmt.Printf("     - Identity File: %s\n", colors.SafeColor(details.IdentityFile, colors.Yellow))
+				}
+				if len(details.Operators) > 0 {
+					fmt.Printf("     - Operators: %s\n", colors.SafeColor(strings.Join(details.Operators, ", "), colors.Yellow))
+				}
+				if details.TPMSealDir != "" {
+					fmt.Printf("     - TPM Seal Dir: %s\n", colors.SafeColor(details.TPMSealDir, colors.Yellow))
+				}
+				if details.TPMPCRs != "" {
+					fmt.Printf("     - TPM PCRs: %s\n", colors.SafeColor(details.TPMPCRs, colors.Yellow))
+				}
+				if details.SecondFactor != "" {
+					fmt.Printf("     - Second Factor: %s\n", colors.SafeColor(details.SecondFactor, colors.Yellow))
+				}
+				if details.SecondFactorFile != "" {
+					fmt.Printf("     - Second Factor File: %s\n", colors.SafeColor(details.SecondFactorFile, colors.Yellow))
+				}
+				if details.ApprovalURL != "" {
+					fmt.Printf("     - Approval URL: %s\n", colors.SafeColor(details.ApprovalURL, colors.Yellow))
+				}
+				if details.Template != "" {
+					fmt.Printf("     - Template: %s\n", colors.SafeColor(details.Template, colors.Yellow))
+				}
+				if details.PrefixPattern != "" {
+					fmt.Printf("     - Prefix Pattern: %s\n", colors.SafeColor(details.PrefixPattern, colors.Yellow))
+				}
+				if details.PrefixScheme != "" {
+					fmt.Printf("     - Prefix Scheme: %s\n", colors.SafeColor(details.PrefixScheme, colors.Yellow))
+				}
+				if details.PrefixMaxLength != 0 {
+					fmt.Printf("     - Prefix Max Length: %s\n", colors.SafeColor(fmt.Sprintf("%d", details.PrefixMaxLength), colors.Yellow))
+				}
+				if len(details.ReservedPrefixes) > 0 {
+					fmt.Printf("     - Reserved Prefixes: %s\n", colors.SafeColor(strings.Join(details.ReservedPrefixes, ", "), colors.Yellow))
+				}
+				if len(details.Tags) > 0 {
+					fmt.Printf("     - Tags: %s\n", colors.SafeColor(strings.Join(details.Tags, ", "), colors.Yellow))
+				}
+				if details.PolicyFile != "" {
+					fmt.Printf("     - Policy: %s\n", colors.SafeColor(details.PolicyFile, colors.Yellow))
+				}
+				if details.Encryption == constants.EncryptionYubiKey {
+					if details.YubikeySerial != "" {
+						fmt.Printf("     - YubiKey Serial: %s\n", colors.SafeColor(details.YubikeySerial, colors.Yellow))
+					}
+					if slot := details.ResolveYubikeySlot(); slot != "" {
+						fmt.Printf("     - YubiKey Slot: %s\n", colors.SafeColor(slot, colors.Yellow))
+					}
+				}
 			}
 			return nil
 		})
@@ -94,9 +166,25 @@ This command:
   2. Sets the vault as active (if no active vault exists)
   3. Automatically creates the encrypted vault file
 
+The tpm and secure-enclave backends create the vault's identity and write its
+recipient to --recipientsfile, which must not exist or be empty then.
+
 Examples:
   vault.module vaults add myvault --type evm --keyfile myvault.key --recipientsfile recipients.txt
   vault.module vaults add myvault --type cosmos --keyfile myvault.key --recipientsfile recipients.txt
+  vault.module vaults add cold --type evm --keyfile cold.key --recipientsfile cold.txt --yubikey-serial 12345678 --yubikey-slot 2
+  vault.module vaults add solo --type evm --encryption fido2 --keyfile solo.key --recipientsfile fido2-recipients.txt
+  vault.module vaults add mac --type evm --encryption secure-enclave --keyfile mac.key --recipientsfile mac.txt
+  vault.module vaults add treasury --type evm --keyfile treasury.key --recipientsfile recipients.txt --second-factor keyfile --second-factor-file /media/usb/treasury.factor
+  vault.module vaults add team --type evm --keyfile team.key --recipientsfile team.txt --approval-url https://approvals.example.com/requests
+  vault.module vaults add laptop --type evm --encryption tpm --tpm-pcrs sha256:0,7 --keyfile laptop.key --recipientsfile laptop.txt
+  vault.module vaults add desk --template trading-desk --keyfile desk.key --recipientsfile desk.txt
+
+--template starts the vault from a built-in template (see 'vaults templates') or
+a template file. The template supplies the vault type unless --type is given,
+a tag taxonomy (the only tags its wallets may carry), a naming convention for
+wallet prefixes, command defaults, a written policy saved next to the vault
+file, and placeholder watch-only wallets.
 `,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -106,8 +194,57 @@ Examples:
 				return errors.NewVaultExistsError(name)
 			}
 
-			if recipientsFile == "" {
-				return errors.NewInvalidInputError("recipientsfile", "--recipientsfile is required for yubikey encryption")
+			encryption := strings.ToLower(strings.TrimSpace(vaultEncryption))
+			if !isSupportedEncryption(encryption) {
+				return errors.NewInvalidInputError(vaultEncryption, fmt.Sprintf("unsupported encryption method (supported: %s)", strings.Join(config.GetAllEncryptionMethods(), ", ")))
+			}
+
+			if config.UsesRecipientsFile(encryption) && recipientsFile == "" {
+				return errors.NewInvalidInputError("recipientsfile", fmt.Sprintf("--recipientsfile is required for %s encryption", encryption))
+			}
+
+			if err := config.ValidateYubikeySerial(vaultYubikeySerial); err != nil {
+				return errors.NewInvalidInputError(vaultYubikeySerial, err.Error())
+			}
+			if err := config.ValidateYubikeySlot(vaultYubikeySlot); err != nil {
+				return errors.NewInvalidInputError(vaultYubikeySlot, err.Error())
+			}
+			if err := vault.ValidatePCRSelection(vaultTPMPCRs); err != nil {
+				return errors.NewInvalidInputError(vaultTPMPCRs, err.Error())
+			}
+
+			if vaultApprovalURL != "" {
+				if err := approval.ValidateURL(vaultApprovalURL); err != nil {
+					return errors.NewInvalidInputError(vaultApprovalURL, err.Error())
+				}
+			}
+			if vaultApprovalTimeout < 0 {
+				return errors.NewInvalidInputError(strconv.Itoa(vaultApprovalTimeout), "approval timeout cannot be negative")
+			}
+
+			secondFactor := strings.ToLower(strings.TrimSpace(vaultSecondFactor))
+			switch secondFactor {
+			case "", constants.SecondFactorKeyfile, constants.SecondFactorPassphrase:
+			default:
+				return errors.NewInvalidInputError(vaultSecondFactor, "second factor must be 'keyfile' or 'passphrase'")
+			}
+			if secondFactor == constants.SecondFactorKeyfile && vaultSecondFactorFile == "" {
+				return errors.NewInvalidInputError("second-factor-file", "--second-factor-file is required for the keyfile second factor")
+			}
+
+			var tmpl *vaulttemplate.Template
+			if vaultTemplate != "" {
+				var err error
+				tmpl, _, err = vaulttemplate.Load(vaultTemplate)
+				if err != nil {
+					return errors.NewInvalidInputError(vaultTemplate, err.Error())
+				}
+				if err := tmpl.Validate(actions.ValidatePrefix, tagRegex.MatchString); err != nil {
+					return errors.NewInvalidInputError(vaultTemplate, err.Error())
+				}
+				if vaultType == "" {
+					vaultType = tmpl.Type
+				}
 			}
 
 			// Normalize vault type to lowercase
@@ -123,6 +260,19 @@ Examples:
 				return errors.NewVaultInvalidPathError(keyFile, err)
 			}
 
+			// The passphrase is chosen once here; every save reuses the one that opened the vault
+			if secondFactor == constants.SecondFactorPassphrase {
+				if programmaticMode {
+					return errors.NewProgrammaticModeError("vaults add --second-factor passphrase")
+				}
+				fmt.Println(colors.SafeColor("Choose the second factor passphrase. It is needed with the vault's key on every load and cannot be recovered.", colors.Warning))
+				passphrase, err := askForNewPassphrase("vaults add", "Second factor passphrase", name)
+				if err != nil {
+					return err
+				}
+				vault.SetSecondFactorPassphrase(absKeyFile, passphrase)
+			}
+
 			var absRecipientsFile string
 			if recipientsFile != "" {
 				if err := config.ValidateFilePath(recipientsFile, "recipients file"); err != nil {
@@ -135,12 +285,101 @@ Examples:
 				}
 			}
 
+			var absIdentityFile string
+			if vaultIdentityFile != "" {
+				if err := config.ValidateFilePath(vaultIdentityFile, "identity file"); err != nil {
+					return errors.NewVaultInvalidPathError(vaultIdentityFile, fmt.Errorf("identity file validation failed: %w", err))
+				}
+
+				absIdentityFile, err = filepath.Abs(filepath.Clean(vaultIdentityFile))
+				if err != nil {
+					return errors.NewVaultInvalidPathError(vaultIdentityFile, err)
+				}
+			}
+
+			// The identity created for a TPM or Secure Enclave vault is the only
+			// recipient of its new recipients file, removed again if the vault
+			// file cannot be created
+			createdRecipientsFile := false
+			defer func() {
+				if createdRecipientsFile {
+					os.Remove(absRecipientsFile)
+				}
+			}()
+
+			var tpmSealDir string
+			if encryption == constants.EncryptionTPM {
+				if err := checkNewRecipientsFile(absRecipientsFile, encryption); err != nil {
+					return err
+				}
+				tpmSealDir = vault.DefaultTPMSealDir(absKeyFile)
+				fmt.Println(colors.SafeColor("Sealing a new age identity to the TPM...", colors.Info))
+				recipient, err := vault.SealTPMIdentity(tpmSealDir, vaultTPMPCRs)
+				if err != nil {
+					return err
+				}
+				if err := writeRecipientsFile(absRecipientsFile, recipient); err != nil {
+					return err
+				}
+				createdRecipientsFile = true
+			}
+
+			if encryption == constants.EncryptionSecureEnclave {
+				if !vault.SecureEnclaveSupported() {
+					return errors.NewInvalidInputError(encryption, "the Secure Enclave backend is only available on macOS")
+				}
+				if absIdentityFile == "" {
+					absIdentityFile = absKeyFile + ".se-identity"
+				}
+				if _, err := os.Stat(absIdentityFile); os.IsNotExist(err) {
+					if err := checkNewRecipientsFile(absRecipientsFile, encryption); err != nil {
+						return err
+					}
+					fmt.Println(colors.SafeColor("Creating a Secure Enclave key (Touch ID required for every decrypt)...", colors.Info))
+					recipient, err := vault.CreateSecureEnclaveIdentity(absIdentityFile)
+					if err != nil {
+						return err
+					}
+					if err := writeRecipientsFile(absRecipientsFile, recipient); err != nil {
+						return err
+					}
+					createdRecipientsFile = true
+				}
+			}
+
+			var absSecondFactorFile string
+			if secondFactor == constants.SecondFactorKeyfile {
+				if err := config.ValidateFilePath(vaultSecondFactorFile, "second factor file"); err != nil {
+					return errors.NewVaultInvalidPathError(vaultSecondFactorFile, fmt.Errorf("second factor file validation failed: %w", err))
+				}
+				absSecondFactorFile, err = filepath.Abs(filepath.Clean(vaultSecondFactorFile))
+				if err != nil {
+					return errors.NewVaultInvalidPathError(vaultSecondFactorFile, err)
+				}
+				if _, err := os.Stat(absSecondFactorFile); os.IsNotExist(err) {
+					fmt.Println(colors.SafeColor("Generating second factor keyfile...", colors.Info))
+					if err := vault.GenerateSecondFactorKeyfile(absSecondFactorFile); err != nil {
+						return err
+					}
+					fmt.Println(colors.SafeColor(fmt.Sprintf("Keep %s separate from your hardware key; both are needed to decrypt.", absSecondFactorFile), colors.Warning))
+				}
+			}
+
 			// Prepare vault details for creation
 			newVault := config.VaultDetails{
-				KeyFile:        absKeyFile,
-				RecipientsFile: absRecipientsFile,
-				Type:           normalizedVaultType,
-				Encryption:     constants.EncryptionYubiKey,
+				KeyFile:          absKeyFile,
+				RecipientsFile:   absRecipientsFile,
+				Type:             normalizedVaultType,
+				Encryption:       encryption,
+				YubikeySerial:    vaultYubikeySerial,
+				YubikeySlot:      vaultYubikeySlot,
+				IdentityFile:     absIdentityFile,
+				TPMSealDir:       tpmSealDir,
+				TPMPCRs:          vaultTPMPCRs,
+				SecondFactor:     secondFactor,
+				SecondFactorFile: absSecondFactorFile,
+				ApprovalURL:      vaultApprovalURL,
+				ApprovalTimeout:  vaultApprovalTimeout,
 			}
 
 			// Automatically create the physical vault file first
@@ -149,11 +388,25 @@ Examples:
 				colors.Info,
 			))
 
-			// Create an empty vault
+			// Create an empty vault, or the template's placeholder wallets
 			emptyVault := make(vault.Vault)
+			if tmpl != nil {
+				emptyVault = tmpl.Vault()
+				newVault.Template = tmpl.Name
+				newVault.Tags = tmpl.Tags
+				newVault.PrefixPattern = tmpl.PrefixPattern
+				newVault.Defaults = tmpl.Defaults
+				if tmpl.Policy != "" {
+					newVault.PolicyFile = absKeyFile + ".policy.txt"
+					if err := os.WriteFile(newVault.PolicyFile, []byte(tmpl.Policy), 0644); err != nil {
+						return errors.NewFileSystemError("write", newVault.PolicyFile, err)
+					}
+				}
+			}
 			if err := vault.SaveVault(newVault, emptyVault); err != nil {
 				return errors.NewVaultSaveError(absKeyFile, err)
 			}
+			createdRecipientsFile = false
 
 			// Only add to config.json after successful vault file creation
 			if config.Cfg.Vaults == nil {
@@ -161,8 +414,8 @@ Examples:
 			}
 			config.Cfg.Vaults[name] = newVault
 
-			if config.Cfg.ActiveVault == "" {
-				config.Cfg.ActiveVault = name
+			if config.PersistedActiveVault() == "" {
+				config.SetActiveVault(name)
 			}
 
 			if err := config.SaveConfig(); err != nil {
@@ -172,6 +425,7 @@ Examples:
 			audit.Logger.Info("Vault configuration added",
 				slog.String("vault_name", name),
 				slog.String("vault_type", normalizedVaultType),
+				slog.String("template", newVault.Template),
 				slog.String("key_file", absKeyFile),
 				slog.Bool("is_active", config.Cfg.ActiveVault == name))
 
@@ -186,6 +440,15 @@ Examples:
 					colors.Success,
 				))
 			}
+			if tmpl != nil {
+				fmt.Println(colors.SafeColor(
+					fmt.Sprintf("Template '%s' applied: %d placeholder wallet(s), %d tag(s).", tmpl.Name, len(tmpl.Wallets), len(tmpl.Tags)),
+					colors.Info,
+				))
+				if newVault.PolicyFile != "" {
+					fmt.Printf("   Policy: %s\n", colors.SafeColor(newVault.PolicyFile, colors.Yellow))
+				}
+			}
 			fmt.Println(colors.SafeColor(
 				"💡 Next step: Run 'vault.module add <wallet>' to add wallets",
 				colors.Info,
@@ -196,11 +459,25 @@ Examples:
 	},
 }
 
+var vaultsUseSession bool
+
 // vaultsUseCmd sets a vault as the active one.
 var vaultsUseCmd = &cobra.Command{
 	Use:   "use <NAME>",
 	Short: "Sets the active vault.",
-	Args:  cobra.ExactArgs(1),
+	Long: `Sets the active vault.
+
+By default the active vault is recorded in config.json and applies to every
+shell. With --session, config.json is left alone and a command setting
+VAULT_NAME is printed instead; evaluate it to switch only the current shell,
+so terminals working on different vaults do not race over config.json.
+
+Examples:
+  vault.module vaults use cold
+  eval "$(vault.module vaults use cold --session)"
+  unset VAULT_NAME                     # back to the vault in config.json
+`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return errors.WrapCommand(func() error {
 			name := args[0]
@@ -208,39 +485,546 @@ var vaultsUseCmd = &cobra.Command{
 				return errors.NewVaultNotFoundError(name)
 			}
 
-			config.Cfg.ActiveVault = name
+			if vaultsUseSession {
+				// Valid vault names need no shell quoting; a hand-edited config.json may hold others
+				if err := config.ValidateVaultName(name); err != nil {
+					return errors.NewInvalidInputError(name, err.Error())
+				}
+				if strings.HasSuffix(os.Getenv("SHELL"), "fish") {
+					fmt.Printf("set -gx VAULT_NAME %s;\n", name)
+				} else {
+					fmt.Printf("export VAULT_NAME=%s\n", name)
+				}
+				fmt.Fprintln(os.Stderr, colors.SafeColor(fmt.Sprintf("Evaluate the output to use vault '%s' in this shell only.", name), colors.Info))
+				return nil
+			}
+
+			config.SetActiveVault(name)
 			if err := config.SaveConfig(); err != nil {
 				return errors.NewConfigSaveError("config.json", err)
 			}
 			fmt.Printf("Switched to vault '%s'.\n", name)
+			if session := os.Getenv("VAULT_NAME"); session != "" && session != name {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("This shell still uses vault '%s' from VAULT_NAME; run 'unset VAULT_NAME' to follow config.json.", session), colors.Warning))
+			}
+			return nil
+		})
+	},
+}
+
+// vaultsWatchCmd watches a vault's files for external modification.
+var vaultsWatchCmd = &cobra.Command{
+	Use:   "watch [NAME]",
+	Short: "Watches a vault's keyfile and recipients file for tampering.",
+	Long: `Watches a vault's keyfile and recipients file for tampering.
+
+Runs in the foreground until interrupted. Any modification not made by
+vault.module is reported immediately, the files are re-verified, and the
+vault is blocked until 'vaults verify' re-authenticates it.
+
+The vault is opened once at startup: a save by vault.module is recognized
+by a record signed with the vault's integrity key ('vaults sign'). For a
+vault without one, every change of its keyfile is reported.
+
+Examples:
+  vault.module vaults watch
+  vault.module vaults watch cold
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			name, details, err := vaultFromArgs(args)
+			if err != nil {
+				return err
+			}
+
+			v, err := vault.LoadVault(details)
+			if err != nil {
+				return errors.NewVaultLoadError(details.KeyFile, err)
+			}
+			for _, wallet := range v {
+				wallet.Clear()
+			}
+			if vault.IntegrityModeFor(details.KeyFile) == "" {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Vault '%s' has no integrity key: saves by vault.module are reported too. Run 'vault.module vaults sign %s' to tell them apart.", name, name), colors.Warning))
+			}
+
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Watching vault '%s' for external modification (Ctrl+C to stop)...", name), colors.Info))
+			return vault.WatchVaultFiles(security.GetManager().Context(), details, func(event vault.TamperEvent) {
+				fmt.Println(colors.SafeColor(
+					fmt.Sprintf("ALERT: %s was modified externally (%s). Vault '%s' is blocked until re-verified.", event.Path, event.Op, name),
+					colors.Error,
+				))
+				if event.Integrity != nil {
+					fmt.Println(colors.SafeColor(fmt.Sprintf("Integrity check failed: %s", errors.FormatForUser(event.Integrity)), colors.Error))
+				}
+			})
+		})
+	},
+}
+
+// vaultsVerifyCmd re-authenticates a vault after a tamper alert.
+var vaultsVerifyCmd = &cobra.Command{
+	Use:   "verify [NAME]",
+	Short: "Re-verifies a vault and clears a tamper alert.",
+	Long: `Re-verifies a vault and clears a tamper alert.
+
+Checks the keyfile and recipients file and decrypts the vault with its
+hardware key, then prints the recipients and the recipients added or removed
+since the vault was last signed. Review them before typing the vault name to
+confirm: an attacker who added a recipient can read everything saved
+afterwards. Wallets matching the known-compromised key database are reported.
+
+Examples:
+  vault.module vaults verify cold
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			name, details, err := vaultFromArgs(args)
+			if err != nil {
+				return err
+			}
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("vaults verify")
+			}
+
+			if err := vault.VerifyFileIntegrity(details); err != nil {
+				return err
+			}
+			// The recipients are reviewed below, even if they no longer match the signature
+			v, err := vault.LoadVaultUnverified(details)
+			if err != nil {
+				return errors.NewVaultLoadError(details.KeyFile, err)
+			}
+			prefixes := make([]string, 0, len(v))
+			for prefix := range v {
+				prefixes = append(prefixes, prefix)
+			}
+			sort.Strings(prefixes)
+			for _, prefix := range prefixes {
+				warnKnownCompromised("vaults verify", prefix, v[prefix])
+			}
+			for _, wallet := range v {
+				wallet.Clear()
+			}
+
+			review, err := vault.ReviewRecipients(details)
+			if err != nil {
+				return err
+			}
+			printRecipientsReview(name, details, review)
+			if !confirmByTyping(fmt.Sprintf("Clear the tamper alert of vault '%s' and trust these recipients?", name), name, false) {
+				fmt.Println(colors.SafeColor("Cancelled. The vault stays blocked.", colors.Info))
+				return nil
+			}
+
+			if err := vault.ClearTamperMarker(details); err != nil {
+				return err
+			}
+			audit.Logger.Info("Vault re-verified", slog.String("vault_name", name))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Vault '%s' verified (%d wallets).", name, len(v)), colors.Success))
+			return nil
+		})
+	},
+}
+
+// printRecipientsReview shows the recipients file of a vault and how it differs
+// from the one the vault was last signed with
+func printRecipientsReview(name string, details config.VaultDetails, review vault.RecipientsReview) {
+	if details.RecipientsFile != "" {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Recipients in %s:", details.RecipientsFile), colors.Bold))
+		fmt.Println(strings.TrimSpace(review.Current))
+	}
+	for _, name := range details.Operators {
+		op, _ := config.FindOperator(name)
+		fmt.Printf("Operator %s: %s\n", name, op.Recipient)
+	}
+
+	switch {
+	case !review.Signed:
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Vault '%s' is not signed: compare the recipients with your records.", name), colors.Warning))
+		return
+	case review.Matches:
+		fmt.Println(colors.SafeColor("The configuration and recipients match the last signature.", colors.Success))
+	default:
+		fmt.Println(colors.SafeColor("The configuration or recipients do NOT match the last signature.", colors.Error))
+	}
+	if !review.SignedCopy {
+		if !review.Matches {
+			fmt.Println(colors.SafeColor(fmt.Sprintf("The vault holds no copy of the signed recipients; run 'vault.module vaults sign %s' once they are reviewed.", name), colors.Warning))
+		}
+		return
+	}
+	if len(review.Added) == 0 && len(review.Removed) == 0 {
+		fmt.Println("No recipients were added or removed since the last signature.")
+		return
+	}
+	fmt.Println(colors.SafeColor("Changes since the last signature:", colors.Bold))
+	for _, line := range review.Removed {
+		fmt.Println(colors.SafeColor("- "+line, colors.Warning))
+	}
+	for _, line := range review.Added {
+		fmt.Println(colors.SafeColor("+ "+line, colors.Error))
+	}
+	if !review.Matches {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Loads stay refused in enforce mode until 'vault.module vaults sign %s'.", name), colors.Warning))
+	}
+}
+
+var vaultsSignMode string
+
+// vaultsSignCmd signs a vault's configuration with its vault-held integrity key.
+var vaultsSignCmd = &cobra.Command{
+	Use:   "sign [NAME]",
+	Short: "Signs a vault's config entry and recipients file with a key held in the vault.",
+	Long: `Signs a vault's config entry and recipients file with a key held in the vault.
+
+The signing key is created on first use and stored inside the encrypted vault.
+Every later load verifies that config.json and the recipients file still match
+the signature, so an attacker with file access cannot silently redirect
+encryption to their own recipients. In 'enforce' mode a mismatch refuses to
+load the vault; in 'warn' mode it only prints a warning.
+
+Run this again after intentionally changing the vault's settings or recipients.
+
+Examples:
+  vault.module vaults sign
+  vault.module vaults sign cold --mode warn
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			name, details, err := vaultFromArgs(args)
+			if err != nil {
+				return err
+			}
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("vaults sign")
+			}
+
+			v, err := vault.LoadVaultUnverified(details)
+			if err != nil {
+				return errors.NewVaultLoadError(details.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			if details.RecipientsFile != "" {
+				recipients, err := os.ReadFile(details.RecipientsFile)
+				if err != nil {
+					return errors.FromOSError(err, details.RecipientsFile)
+				}
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Recipients in %s:", details.RecipientsFile), colors.Bold))
+				fmt.Println(strings.TrimSpace(string(recipients)))
+			}
+			for _, name := range details.Operators {
+				op, _ := config.FindOperator(name)
+				fmt.Printf("Operator %s: %s\n", name, op.Recipient)
+			}
+			if !askForConfirmation(colors.SafeColor("Sign this configuration as trusted?", colors.Warning)) {
+				fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+				return nil
+			}
+
+			signature, err := vault.SignVaultConfig(details, v, vaultsSignMode)
+			if err != nil {
+				return err
+			}
+			details.IntegritySignature = signature
+			config.Cfg.Vaults[name] = details
+			if err := config.SaveConfig(); err != nil {
+				return errors.NewConfigSaveError("config.json", err)
+			}
+
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Configuration of vault '%s' signed (mode: %s).", name, vaultsSignMode), colors.Success))
+			return nil
+		})
+	},
+}
+
+// vaultsPassphraseCmd changes the passphrase of a vault's passphrase second factor.
+var vaultsPassphraseCmd = &cobra.Command{
+	Use:   "passphrase [NAME]",
+	Short: "Changes the passphrase of a vault's passphrase second factor.",
+	Long: `Changes the passphrase of a vault's passphrase second factor.
+
+Opens the vault with its current passphrase, asks for the new one twice,
+checks its strength as for every new passphrase, and saves the vault with it.
+Other commands save a vault with the passphrase that opened it and never ask
+for a new one.
+
+Examples:
+  vault.module vaults passphrase treasury
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			name, details, err := vaultFromArgs(args)
+			if err != nil {
+				return err
+			}
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("vaults passphrase")
+			}
+			if details.SecondFactor != constants.SecondFactorPassphrase {
+				return errors.NewInvalidInputError(name, "the vault has no passphrase second factor")
+			}
+
+			v, err := vault.LoadVault(details)
+			if err != nil {
+				return errors.NewVaultLoadError(details.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			passphrase, err := askForNewPassphrase("vaults passphrase", "New second factor passphrase", name)
+			if err != nil {
+				return err
+			}
+			vault.SetSecondFactorPassphrase(details.KeyFile, passphrase)
+			if err := vault.SaveVault(details, v); err != nil {
+				return errors.NewVaultSaveError(details.KeyFile, err)
+			}
+
+			audit.Logger.Warn("Second factor passphrase changed", slog.String("vault_name", name))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Second factor passphrase of vault '%s' changed. The old one no longer opens it.", name), colors.Success))
 			return nil
 		})
 	},
 }
 
+// vaultFromArgs resolves the optional NAME argument, defaulting to the active vault
+func vaultFromArgs(args []string) (string, config.VaultDetails, error) {
+	name := config.Cfg.ActiveVault
+	if len(args) > 0 {
+		name = args[0]
+	}
+	if name == "" {
+		return "", config.VaultDetails{}, errors.NewActiveVaultNotSetError()
+	}
+	details, exists := config.Cfg.Vaults[name]
+	if !exists {
+		return "", config.VaultDetails{}, errors.NewVaultNotFoundError(name)
+	}
+	return name, details, nil
+}
+
 // vaultsDeleteCmd deletes a vault from the configuration and deletes the vault file.
-var vaultsDeleteCmd = &cobra.Command{
-	Use:   "delete <NAME>",
-	Short: "Deletes a vault from the configuration and deletes the vault file.",
-	Args:  cobra.ExactArgs(1),
+// vaultsTemplatesCmd lists the built-in vault templates or prints one.
+var vaultsTemplatesCmd = &cobra.Command{
+	Use:   "templates [NAME]",
+	Short: "Lists the built-in vault templates, or prints one.",
+	Long: `Lists the built-in vault templates, or prints one.
+
+A printed template is a starting point for your own: save it, edit it and pass
+the file to 'vaults add --template'.
+
+Examples:
+  vault.module vaults templates
+  vault.module vaults templates trading-desk > desk-template.json
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if len(args) == 1 {
+				_, data, err := vaulttemplate.Load(args[0])
+				if err != nil {
+					return errors.NewInvalidInputError(args[0], err.Error())
+				}
+				fmt.Print(string(data))
+				return nil
+			}
+			for _, name := range vaulttemplate.Builtin() {
+				tmpl, _, err := vaulttemplate.Load(name)
+				if err != nil {
+					return errors.Wrap(errors.ErrCodeSystem, "built-in template is invalid", err)
+				}
+				fmt.Printf("%s  %s\n", colors.SafeColor(fmt.Sprintf("%-16s", name), colors.Cyan), tmpl.Description)
+			}
+			return nil
+		})
+	},
+}
+
+var vaultsPublishTo string
+var vaultsPublishUnlink bool
+var vaultsPublishYes bool
+
+var vaultsPublishCmd = &cobra.Command{
+	Use:   "publish <NAME> --to <PUBLIC_NAME>",
+	Short: "Maintains a public twin of a vault with its addresses, xpubs and notes only.",
+	Long: `Maintains a public twin of a vault with its addresses, xpubs and notes only.
+
+The public twin is a second configured vault, usually protected by an
+everyday key, that holds the public data of every wallet in the cold vault:
+addresses, the account xpub of HD wallets, notes, checklists and holds, but
+never a mnemonic, private key, secret or envelope. Once linked, every save of
+the cold vault regenerates the twin, so list and 'get <PREFIX> address' work
+on the twin without touching the cold key. The twin's previous contents are
+replaced, and it carries the cold vault's history.
+
+Create the twin first with 'vaults add', using the same --type.
+
+Examples:
+  vault.module vaults publish cold --to cold-public
+  vault.module vaults publish cold --unlink
+`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return errors.WrapCommand(func() error {
 			name := args[0]
-			vaultDetails, exists := config.Cfg.Vaults[name]
+			details, exists := config.Cfg.Vaults[name]
 			if !exists {
 				return errors.NewVaultNotFoundError(name)
 			}
 
-			if !vaultsDeleteYesFlag {
-				prompt := fmt.Sprintf("Are you sure you want to delete vault '%s' and delete its file at '%s'? This action is irreversible.", name, vaultDetails.KeyFile)
+			if vaultsPublishUnlink {
+				if details.PublicTwin == "" {
+					fmt.Println(colors.SafeColor(fmt.Sprintf("Vault '%s' has no public twin.", name), colors.Info))
+					return nil
+				}
+				twin := details.PublicTwin
+				details.PublicTwin = ""
+				config.Cfg.Vaults[name] = details
+				if err := config.SaveConfig(); err != nil {
+					return errors.NewConfigSaveError("config.json", err)
+				}
+				audit.Logger.Info("Public twin unlinked", slog.String("vault", name), slog.String("twin", twin))
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Vault '%s' no longer updates '%s'. The twin's file is kept.", name, twin), colors.Success))
+				return nil
+			}
+
+			if vaultsPublishTo == "" {
+				return errors.NewInvalidInputError("--to", "the name of the public twin vault is required")
+			}
+			twin, exists := config.Cfg.Vaults[vaultsPublishTo]
+			if !exists {
+				return errors.NewVaultNotFoundError(vaultsPublishTo).WithDetails("create the public twin first with 'vaults add'")
+			}
+			if vaultsPublishTo == name || twin.KeyFile == details.KeyFile {
+				return errors.NewInvalidInputError(vaultsPublishTo, "a vault cannot be its own public twin")
+			}
+			if twin.Type != details.Type {
+				return errors.NewInvalidInputError(vaultsPublishTo, fmt.Sprintf("public twin is a %s vault, but '%s' is %s", twin.Type, name, details.Type))
+			}
+			if twin.PublicTwin != "" {
+				return errors.NewInvalidInputError(vaultsPublishTo, "a public twin cannot have a twin of its own")
+			}
+			for other, d := range config.Cfg.Vaults {
+				if other == name {
+					continue
+				}
+				if d.PublicTwin == name {
+					return errors.NewInvalidInputError(name, fmt.Sprintf("vault is the public twin of '%s'", other))
+				}
+				if d.PublicTwin == vaultsPublishTo {
+					return errors.NewInvalidInputError(vaultsPublishTo, fmt.Sprintf("vault is already the public twin of '%s'", other))
+				}
+			}
+
+			if _, err := os.Stat(twin.KeyFile); err == nil && details.PublicTwin != vaultsPublishTo && !vaultsPublishYes {
+				prompt := fmt.Sprintf("Vault '%s' already has a file at '%s'. Replace its contents with the public data of '%s'?", vaultsPublishTo, twin.KeyFile, name)
 				if !askForConfirmation(colors.SafeColor(prompt, colors.Warning)) {
 					fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
 					return nil
 				}
 			}
 
-			// Delete the vault file first
-			if err := os.Remove(vaultDetails.KeyFile); err != nil {
+			if vault.IsMarkedTampered(details) {
+				return errors.NewVaultTamperedError(name)
+			}
+			v, err := vault.LoadVault(details)
+			if err != nil {
+				return errors.NewVaultLoadError(details.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+
+			details.PublicTwin = vaultsPublishTo
+			if err := vault.SyncPublicTwin(details, v); err != nil {
+				return err
+			}
+			config.Cfg.Vaults[name] = details
+			if err := config.SaveConfig(); err != nil {
+				return errors.NewConfigSaveError("config.json", err)
+			}
+
+			audit.Logger.Info("Public twin published", slog.String("vault", name), slog.String("twin", vaultsPublishTo), slog.Int("wallet_count", len(v)))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Published %d wallet(s) of '%s' to '%s'. The twin is updated on every save of '%s'.", len(v), name, vaultsPublishTo, name), colors.Success))
+			return nil
+		})
+	},
+}
+
+var vaultsDeleteCmd = &cobra.Command{
+	Use:   "delete <NAME>",
+	Short: "Deletes a vault from the configuration and moves its file to the trash.",
+	Long: `Deletes a vault from the configuration and moves its file to the trash.
+
+The vault file, still encrypted, and the files kept next to it (Secure Enclave
+identity, policy) are moved to vault-trash/ with the vault's config.json entry.
+'vaults undelete' restores the vault until 'vaults purge' removes it for good;
+'vaults trash' lists what is there. --purge deletes the files right away.
+
+You confirm by typing the vault's name, unless the --yes flag is used; the
+strict profile asks even with --yes. With a cooling-off period
+("delete_cooling_off_hours" in config.json, 24 hours under the strict profile
+when not set), the confirmed deletion is only scheduled: running the same
+command again once the period has passed completes it, --cancel calls it off.
+
+Examples:
+  vault.module vaults delete old
+  vault.module vaults delete old --cancel
+  vault.module vaults delete old --purge
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			name := args[0]
+			vaultDetails, exists := config.Cfg.Vaults[name]
+			if !exists {
+				return errors.NewVaultNotFoundError(name)
+			}
+
+			if vaultsDeleteCancelFlag {
+				return cancelDeletion(name, "")
+			}
+			proceed, err := confirmDeletion(name, "", func() bool {
+				prompt := fmt.Sprintf("You are about to delete vault '%s'. Its file at '%s' is moved to the trash.", name, vaultDetails.KeyFile)
+				if vaultsDeletePurgeFlag {
+					prompt = fmt.Sprintf("You are about to delete vault '%s' and its file at '%s'. This action is irreversible.", name, vaultDetails.KeyFile)
+				}
+				return confirmByTyping(prompt, name, vaultsDeleteYesFlag)
+			})
+			if err != nil || !proceed {
+				return err
+			}
+
+			// Move the vault file to the trash first, or delete it with --purge
+			if !vaultsDeletePurgeFlag {
+				entry, err := vault.MoveToTrash(name, vaultDetails)
+				if err != nil {
+					audit.Logger.Error("Failed to move vault to the trash",
+						slog.String("vault_name", name),
+						slog.String("key_file", vaultDetails.KeyFile),
+						slog.String("error", err.Error()))
+					return errors.NewFileSystemError("move", vaultDetails.KeyFile, err).WithDetails("the vault was not deleted")
+				}
+				audit.Logger.Info("Vault moved to the trash",
+					slog.String("vault_name", name),
+					slog.String("key_file", vaultDetails.KeyFile),
+					slog.String("trash", entry.Dir))
+			} else if err := os.Remove(vaultDetails.KeyFile); err != nil {
 				if !os.IsNotExist(err) {
 					audit.Logger.Error("Failed to delete vault file",
 						slog.String("vault_name", name),
@@ -258,13 +1042,34 @@ var vaultsDeleteCmd = &cobra.Command{
 					slog.String("key_file", vaultDetails.KeyFile))
 			}
 
-			// Delete from configuration
+			// Delete from configuration, with the deletions scheduled in it
 			delete(config.Cfg.Vaults, name)
-			if config.Cfg.ActiveVault == name {
-				config.Cfg.ActiveVault = ""
-				fmt.Printf("Deleted active vault '%s' and deleted its file. No vault is active now.\n", name)
+			pending := config.Cfg.PendingDeletions[:0]
+			for _, p := range config.Cfg.PendingDeletions {
+				if p.Vault != name {
+					pending = append(pending, p)
+				}
+			}
+			config.Cfg.PendingDeletions = pending
+			for other, d := range config.Cfg.Vaults {
+				if d.PublicTwin == name {
+					d.PublicTwin = ""
+					config.Cfg.Vaults[other] = d
+					fmt.Printf("Vault '%s' no longer has a public twin.\n", other)
+				}
+			}
+			what := "moved its file to the trash"
+			if vaultsDeletePurgeFlag {
+				what = "deleted its file"
+			}
+			if config.PersistedActiveVault() == name {
+				config.SetActiveVault("")
+				fmt.Printf("Deleted active vault '%s' and %s. No vault is active now.\n", name, what)
 			} else {
-				fmt.Printf("Deleted vault '%s' and deleted its file.\n", name)
+				fmt.Printf("Deleted vault '%s' and %s.\n", name, what)
+			}
+			if !vaultsDeletePurgeFlag {
+				fmt.Printf("Restore it with 'vaults undelete %s'.\n", name)
 			}
 
 			if err := config.SaveConfig(); err != nil {
@@ -276,12 +1081,67 @@ var vaultsDeleteCmd = &cobra.Command{
 	},
 }
 
+// isSupportedEncryption checks the encryption method against the supported list
+func isSupportedEncryption(method string) bool {
+	for _, m := range config.GetAllEncryptionMethods() {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNewRecipientsFile refuses a recipients file that already holds
+// recipients for a backend that creates its own identity: the new identity
+// would be added to recipients nobody reviewed
+func checkNewRecipientsFile(path, encryption string) error {
+	if path == "" {
+		return errors.NewConfigMissingError("recipients_file").WithDetails(fmt.Sprintf("recipients file is required for %s encryption", encryption))
+	}
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.FromOSError(err, path)
+	}
+	if info.Size() > 0 {
+		return errors.NewInvalidInputError(path, fmt.Sprintf("the recipients file of a new %s vault must not exist or be empty: its identity is created now and is the only recipient", encryption))
+	}
+	return nil
+}
+
+// writeRecipientsFile writes the new recipients file of a vault
+func writeRecipientsFile(path, recipient string) error {
+	if err := os.WriteFile(path, []byte(recipient+"\n"), 0600); err != nil {
+		return errors.FromOSError(err, path)
+	}
+	return nil
+}
+
 func init() {
 	vaultsAddCmd.Flags().StringVar(&keyFile, "keyfile", "", "Path to the encrypted key file for the new vault (required)")
-	vaultsAddCmd.Flags().StringVar(&recipientsFile, "recipientsfile", "", "Path to the recipients file (required for yubikey encryption)")
-	vaultsAddCmd.Flags().StringVar(&vaultType, "type", "", "Type of the vault, e.g., EVM (required)")
+	vaultsAddCmd.Flags().StringVar(&recipientsFile, "recipientsfile", "", "Path to the recipients file (required for all encryption methods)")
+	vaultsAddCmd.Flags().StringVar(&vaultEncryption, "encryption", constants.EncryptionYubiKey, "Encryption method: yubikey, fido2, tpm or secure-enclave (macOS)")
+	vaultsAddCmd.Flags().StringVar(&vaultSecondFactor, "second-factor", "", "Require a second factor to decrypt: keyfile or passphrase (optional)")
+	vaultsAddCmd.Flags().StringVar(&vaultSecondFactorFile, "second-factor-file", "", "age identity file used as the keyfile second factor; generated if missing")
+	vaultsAddCmd.Flags().StringVar(&vaultApprovalURL, "approval-url", "", "Endpoint that must approve every decryption of this vault (optional; token from VAULT_APPROVAL_TOKEN)")
+	vaultsAddCmd.Flags().IntVar(&vaultApprovalTimeout, "approval-timeout", 0, "Seconds to wait for remote approval (default 300)")
+	vaultsAddCmd.Flags().StringVar(&vaultTPMPCRs, "tpm-pcrs", "", "PCR selection the TPM identity is bound to, e.g. sha256:0,7 (tpm encryption only)")
+	vaultsAddCmd.Flags().StringVar(&vaultIdentityFile, "identityfile", "", "Path to the age identity file (fido2 non-discoverable credentials, secure-enclave key reference)")
+	vaultsAddCmd.Flags().StringVar(&vaultType, "type", "", "Type of the vault, e.g., EVM (required unless the template sets it)")
+	vaultsAddCmd.Flags().StringVar(&vaultTemplate, "template", "", "Built-in template name or template file to start the vault from")
+	vaultsAddCmd.Flags().StringVar(&vaultYubikeySerial, "yubikey-serial", "", "Serial number of the YubiKey that protects this vault (optional)")
+	vaultsAddCmd.Flags().StringVar(&vaultYubikeySlot, "yubikey-slot", "", "PIV slot (1-20) of the age identity for this vault; overrides the global yubikeyslot")
 
 	_ = vaultsAddCmd.MarkFlagRequired("keyfile")
 	_ = vaultsAddCmd.MarkFlagRequired("type")
+	vaultsSignCmd.Flags().StringVar(&vaultsSignMode, "mode", vault.IntegrityEnforce, "What to do on a signature mismatch: enforce or warn")
+	vaultsPublishCmd.Flags().StringVar(&vaultsPublishTo, "to", "", "Name of the vault that becomes the public twin")
+	vaultsPublishCmd.Flags().BoolVar(&vaultsPublishUnlink, "unlink", false, "Stop updating the public twin")
+	vaultsPublishCmd.Flags().BoolVar(&vaultsPublishYes, "yes", false, "Replace an existing twin file without confirmation prompt")
 	vaultsDeleteCmd.Flags().BoolVar(&vaultsDeleteYesFlag, "yes", false, "Delete without confirmation prompt")
+	vaultsDeleteCmd.Flags().BoolVar(&vaultsDeleteCancelFlag, "cancel", false, "Cancel the scheduled deletion of the vault")
+	vaultsDeleteCmd.Flags().BoolVar(&vaultsDeletePurgeFlag, "purge", false, "Delete the vault file instead of moving it to the trash")
+	vaultsUseCmd.Flags().BoolVar(&vaultsUseSession, "session", false, "Print a VAULT_NAME export for the current shell instead of changing config.json")
 }
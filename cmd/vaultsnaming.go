@@ -0,0 +1,234 @@
+// File: cmd/vaultsnaming.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"vault.module/internal/actions"
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var vaultsNamingPattern string
+var vaultsNamingMaxLength int
+var vaultsNamingReserved []string
+var vaultsNamingScheme string
+var vaultsNamingClear bool
+var vaultsNamingNamespace string
+
+var vaultsNamingCmd = &cobra.Command{
+	Use:   "naming [NAME]",
+	Short: "Shows or sets the naming policy of a vault's wallet prefixes.",
+	Long: `Shows or sets the naming policy of a vault's wallet prefixes.
+
+Every prefix is made of latin letters, numbers and '_', starts with a letter,
+is at most 32 characters long and is not one of the reserved names (system,
+config, admin, root, vault, temp, tmp). Prefixes can be hierarchical, with
+'/' between namespaces (team/alice/eth-main); their namespaces and names may
+also contain '-' and '.', and the reserved names cannot be top-level
+namespaces either. A vault can tighten or extend this:
+
+  --pattern     regular expression prefixes must also match
+  --max-length  longest prefix allowed, up to 128
+  --reserved    prefixes reserved on top of the built-in ones
+  --scheme      segments the last name of a prefix is made of, e.g.
+                team-env-name: it then has one run of letters and numbers per
+                segment, joined by the scheme's separators ('-', '_' or '.'),
+                which become valid characters
+
+With --namespace the settings are those of a namespace: they apply to the
+names below it (eth-main in team/alice/eth-main) instead of the vault's
+pattern, scheme and reserved prefixes, and --max-length limits those names.
+The most specific namespace with a policy applies; the vault's character
+rules, maximum length and built-in reserved names always do.
+
+The policy applies to every new prefix: add, generate --store, rename, secret
+add, canary, consensus import, the wizard and imports, which skip wallets that
+break it and list them. Existing wallets are not renamed; those that break a
+new policy are listed. An empty value removes a setting, --clear all of them.
+The settings are "prefix_pattern", "prefix_max_length", "reserved_prefixes"
+and "prefix_scheme" of the vault in config.json, and the same under
+"namespaces" for each namespace.
+
+Examples:
+  vault.module vaults naming
+  vault.module vaults naming --scheme team-env-name --max-length 48
+  vault.module vaults naming desk --pattern '^(ops|trading)-' --reserved treasury,multisig
+  vault.module vaults naming --namespace team/alice --scheme chain-env --max-length 24
+  vault.module vaults naming --clear
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			name, details, err := vaultFromArgs(args)
+			if err != nil {
+				return err
+			}
+
+			flags := cmd.Flags()
+			changed := vaultsNamingClear || flags.Changed("pattern") || flags.Changed("max-length") || flags.Changed("reserved") || flags.Changed("scheme")
+			if !changed {
+				printPrefixPolicy(name, prefixPolicy(details))
+				return nil
+			}
+
+			// The vault's own settings, or those of --namespace
+			var settings config.NamespacePolicy
+			if vaultsNamingNamespace == "" {
+				settings = config.NamespacePolicy{
+					PrefixPattern:    details.PrefixPattern,
+					PrefixMaxLength:  details.PrefixMaxLength,
+					ReservedPrefixes: details.ReservedPrefixes,
+					PrefixScheme:     details.PrefixScheme,
+				}
+			} else {
+				if err := actions.ValidateNamespace(vaultsNamingNamespace); err != nil {
+					return err
+				}
+				settings = details.Namespaces[vaultsNamingNamespace]
+			}
+
+			if vaultsNamingClear {
+				settings = config.NamespacePolicy{}
+			}
+			if flags.Changed("pattern") {
+				settings.PrefixPattern = vaultsNamingPattern
+			}
+			if flags.Changed("max-length") {
+				settings.PrefixMaxLength = vaultsNamingMaxLength
+			}
+			if flags.Changed("reserved") {
+				settings.ReservedPrefixes = nil
+				for _, reserved := range vaultsNamingReserved {
+					if reserved = strings.TrimSpace(reserved); reserved != "" {
+						settings.ReservedPrefixes = append(settings.ReservedPrefixes, reserved)
+					}
+				}
+			}
+			if flags.Changed("scheme") {
+				settings.PrefixScheme = vaultsNamingScheme
+			}
+
+			if vaultsNamingNamespace == "" {
+				details.PrefixPattern = settings.PrefixPattern
+				details.PrefixMaxLength = settings.PrefixMaxLength
+				details.ReservedPrefixes = settings.ReservedPrefixes
+				details.PrefixScheme = settings.PrefixScheme
+			} else {
+				namespaces := make(map[string]config.NamespacePolicy, len(details.Namespaces)+1)
+				for namespace, ns := range details.Namespaces {
+					namespaces[namespace] = ns
+				}
+				if settings.PrefixPattern == "" && settings.PrefixMaxLength == 0 && len(settings.ReservedPrefixes) == 0 && settings.PrefixScheme == "" {
+					delete(namespaces, vaultsNamingNamespace)
+				} else {
+					namespaces[vaultsNamingNamespace] = settings
+				}
+				details.Namespaces = namespaces
+				if len(namespaces) == 0 {
+					details.Namespaces = nil
+				}
+			}
+			policy := prefixPolicy(details)
+			if err := policy.Check(); err != nil {
+				return errors.NewInvalidInputError(name, err.Error())
+			}
+
+			config.Cfg.Vaults[name] = details
+			if err := config.SaveConfig(); err != nil {
+				return errors.NewConfigSaveError("config.json", err)
+			}
+			audit.Logger.Info("Vault naming policy set",
+				slog.String("vault", name),
+				slog.String("namespace", vaultsNamingNamespace),
+				slog.String("prefix_pattern", settings.PrefixPattern),
+				slog.Int("prefix_max_length", settings.PrefixMaxLength),
+				slog.String("reserved_prefixes", strings.Join(settings.ReservedPrefixes, ",")),
+				slog.String("prefix_scheme", settings.PrefixScheme))
+			printPrefixPolicy(name, policy)
+
+			v, err := vault.LoadVault(details)
+			if err != nil {
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Warning: the existing wallets were not checked: %v", err), colors.Warning))
+				return nil
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+			var violations []string
+			for prefix := range v {
+				if err := policy.Validate(prefix); err != nil {
+					violations = append(violations, prefix)
+				}
+			}
+			if len(violations) > 0 {
+				sort.Strings(violations)
+				fmt.Println(colors.SafeColor(fmt.Sprintf("%d existing wallet(s) break the policy: %s", len(violations), strings.Join(violations, ", ")), colors.Warning))
+				fmt.Println("They keep working; rename them with 'rename <OLD> <NEW>' to follow it.")
+			}
+			return nil
+		})
+	},
+}
+
+// printPrefixPolicy prints the naming policy of a vault and its namespaces
+func printPrefixPolicy(name string, policy actions.PrefixPolicy) {
+	maxLength := policy.MaxLength
+	if maxLength == 0 {
+		maxLength = actions.DefaultPrefixMaxLength
+	}
+	fmt.Println(colors.SafeColor(fmt.Sprintf("Naming policy of vault '%s':", name), colors.Bold))
+	fmt.Printf("  Max length: %d\n", maxLength)
+	printPolicyRules("  ", policy)
+	if len(policy.Reserved) > 0 {
+		fmt.Printf("  Reserved:   %s (and the built-in names)\n", strings.Join(policy.Reserved, ", "))
+	} else {
+		fmt.Println("  Reserved:   the built-in names only")
+	}
+
+	namespaces := make([]string, 0, len(policy.Namespaces))
+	for namespace := range policy.Namespaces {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		ns := policy.Namespaces[namespace]
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Namespace '%s':", namespace), colors.Bold))
+		if ns.MaxLength > 0 {
+			fmt.Printf("  Max length: %d\n", ns.MaxLength)
+		}
+		printPolicyRules("  ", ns)
+		if len(ns.Reserved) > 0 {
+			fmt.Printf("  Reserved:   %s\n", strings.Join(ns.Reserved, ", "))
+		}
+	}
+}
+
+// printPolicyRules prints the scheme and pattern of a naming policy
+func printPolicyRules(indent string, policy actions.PrefixPolicy) {
+	if policy.Scheme != "" {
+		fmt.Printf("%sScheme:     %s\n", indent, policy.Scheme)
+	}
+	if policy.Pattern != "" {
+		fmt.Printf("%sPattern:    %s\n", indent, policy.Pattern)
+	}
+}
+
+func init() {
+	vaultsNamingCmd.Flags().StringVar(&vaultsNamingPattern, "pattern", "", "Regular expression prefixes must match (empty removes it)")
+	vaultsNamingCmd.Flags().IntVar(&vaultsNamingMaxLength, "max-length", 0, "Longest prefix allowed (0 restores the default of 32)")
+	vaultsNamingCmd.Flags().StringSliceVar(&vaultsNamingReserved, "reserved", nil, "Prefixes reserved on top of the built-in ones (empty removes them)")
+	vaultsNamingCmd.Flags().StringVar(&vaultsNamingScheme, "scheme", "", "Segments prefixes are made of, e.g. team-env-name (empty removes it)")
+	vaultsNamingCmd.Flags().BoolVar(&vaultsNamingClear, "clear", false, "Remove every setting, back to the built-in policy")
+	vaultsNamingCmd.Flags().StringVar(&vaultsNamingNamespace, "namespace", "", "Set the policy of this prefix namespace, e.g. team/alice")
+}
@@ -0,0 +1,152 @@
+// File: cmd/vaultstrash.go
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/display"
+	"vault.module/internal/errors"
+	"vault.module/internal/vault"
+
+	"github.com/spf13/cobra"
+)
+
+var vaultsPurgeAll bool
+var vaultsPurgeYes bool
+
+var vaultsUndeleteCmd = &cobra.Command{
+	Use:   "undelete <NAME>",
+	Short: "Restores a deleted vault from the trash.",
+	Long: `Restores a deleted vault from the trash.
+
+The vault's files are moved back where they were and its config.json entry is
+added again. If the name was deleted several times, the latest one is restored.
+
+Examples:
+  vault.module vaults undelete old
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			name := args[0]
+			if _, exists := config.Cfg.Vaults[name]; exists {
+				return errors.NewInvalidInputError(name, fmt.Sprintf("a vault named '%s' exists; rename or delete it first", name))
+			}
+			entry, found, err := vault.FindTrash(name)
+			if err != nil {
+				return errors.NewFileSystemError("read", vault.TrashDir, err)
+			}
+			if !found {
+				return errors.NewVaultNotFoundError(name).WithDetails("no vault of that name is in the trash")
+			}
+
+			if err := vault.RestoreFromTrash(entry); err != nil {
+				return errors.NewFileSystemError("restore", entry.Dir, err)
+			}
+			config.Cfg.Vaults[name] = entry.Details
+			if err := config.SaveConfig(); err != nil {
+				return errors.NewConfigSaveError("config.json", err)
+			}
+
+			audit.Logger.Info("Vault restored from the trash",
+				slog.String("vault_name", name),
+				slog.String("key_file", entry.Details.KeyFile),
+				slog.String("deleted_at", entry.DeletedAt))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Vault '%s' restored to '%s'.", name, entry.Details.KeyFile), colors.Success))
+			fmt.Printf("Switch to it with 'vaults use %s'.\n", name)
+			return nil
+		})
+	},
+}
+
+var vaultsTrashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Lists the deleted vaults in the trash.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			entries, err := vault.ListTrash()
+			if err != nil {
+				return errors.NewFileSystemError("read", vault.TrashDir, err)
+			}
+			if len(entries) == 0 {
+				fmt.Println(colors.SafeColor("The trash is empty.", colors.Info))
+				return nil
+			}
+			for _, e := range entries {
+				deleted := display.TimeString(e.DeletedAt)
+				fmt.Printf("- %s (%s, %s) deleted %s, %d file(s)\n",
+					colors.SafeColor(e.Name, colors.Bold), e.Details.Type, e.Details.Encryption, deleted, len(e.Files))
+				fmt.Println(colors.SafeColor("  was "+e.Details.KeyFile, colors.Dim))
+			}
+			return nil
+		})
+	},
+}
+
+var vaultsPurgeCmd = &cobra.Command{
+	Use:   "purge [NAME]",
+	Short: "Removes deleted vaults from the trash for good.",
+	Long: `Removes deleted vaults from the trash for good.
+
+Purges every deleted vault of that name, or the whole trash with --all. A
+purged vault cannot be restored.
+
+Examples:
+  vault.module vaults purge old
+  vault.module vaults purge --all
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if (len(args) == 1) == vaultsPurgeAll {
+				return errors.NewInvalidInputError("NAME", "give a vault name or --all")
+			}
+			entries, err := vault.ListTrash()
+			if err != nil {
+				return errors.NewFileSystemError("read", vault.TrashDir, err)
+			}
+			var purge []vault.TrashEntry
+			for _, e := range entries {
+				if vaultsPurgeAll || e.Name == args[0] {
+					purge = append(purge, e)
+				}
+			}
+			if len(purge) == 0 {
+				fmt.Println(colors.SafeColor("Nothing to purge.", colors.Info))
+				return nil
+			}
+
+			confirmName := "all"
+			if !vaultsPurgeAll {
+				confirmName = args[0]
+			}
+			prompt := fmt.Sprintf("You are about to remove %d deleted vault(s) from the trash. This action is irreversible.", len(purge))
+			if !confirmByTyping(prompt, confirmName, vaultsPurgeYes) {
+				fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+				return nil
+			}
+
+			for _, e := range purge {
+				if err := vault.PurgeTrash(e); err != nil {
+					return errors.NewFileSystemError("delete", e.Dir, err)
+				}
+				audit.Logger.Warn("Vault purged from the trash",
+					slog.String("vault_name", e.Name),
+					slog.String("key_file", e.Details.KeyFile),
+					slog.String("deleted_at", e.DeletedAt))
+			}
+			fmt.Println(colors.SafeColor(fmt.Sprintf("%d deleted vault(s) purged.", len(purge)), colors.Success))
+			return nil
+		})
+	},
+}
+
+func init() {
+	vaultsPurgeCmd.Flags().BoolVar(&vaultsPurgeAll, "all", false, "Purge every vault in the trash")
+	vaultsPurgeCmd.Flags().BoolVar(&vaultsPurgeYes, "yes", false, "Purge without confirmation prompt")
+}
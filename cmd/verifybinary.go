@@ -0,0 +1,272 @@
+// File: cmd/verifybinary.go
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/constants"
+	"vault.module/internal/errors"
+	"vault.module/internal/release"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultManifestName is the manifest looked for next to the executable
+const defaultManifestName = "release-manifest.json"
+
+var verifyBinaryManifest string
+var verifyBinarySignature string
+
+// binaryReport is what 'verify-binary' found, printed with --json
+type binaryReport struct {
+	Executable string            `json:"executable"`
+	SHA256     string            `json:"sha256"`
+	Platform   string            `json:"platform"`
+	Version    string            `json:"version"`
+	Commit     string            `json:"commit,omitempty"`
+	Builder    string            `json:"builder,omitempty"`
+	BuildDate  string            `json:"build_date,omitempty"`
+	Toolchain  release.Toolchain `json:"toolchain"`
+	ReleaseKey string            `json:"release_key,omitempty"` // Fingerprint of the embedded key
+	Manifest   string            `json:"manifest,omitempty"`
+	Release    string            `json:"release,omitempty"`
+	Verified   bool              `json:"verified"`
+	Problem    string            `json:"problem,omitempty"`
+	Warnings   []string          `json:"warnings,omitempty"`
+}
+
+var verifyBinaryCmd = &cobra.Command{
+	Use:   "verify-binary",
+	Short: "Checks the running executable against the signed release manifest.",
+	Long: `Checks the running executable against the signed release manifest.
+
+Hashes the executable that is running, checks the signature of the release
+manifest with the release key embedded in the binary, and looks the hash up in
+the manifest. The build provenance is reported: version, commit and builder
+set by the release build, and what the Go toolchain recorded.
+
+On a shared machine, run it to find out whether someone replaced the binary.
+The manifest and its signature are published with each release; by default
+release-manifest.json and release-manifest.json.sig are looked for next to the
+executable. A replaced binary can carry a key of its own, so compare the
+printed key fingerprint with the one published by the project, from another
+machine.
+
+The command fails if the build has no release key (a development build), the
+signature is invalid, the hash is not in the manifest, or the manifest does
+not match the version, commit or platform the binary reports. Executables or
+directories writable by other users are warned about.
+
+Examples:
+  vault.module verify-binary
+  vault.module verify-binary --manifest ~/Downloads/release-manifest.json
+  vault.module verify-binary --json
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			report, err := verifyBinary()
+			if report != nil {
+				logBinaryReport(report)
+				if jsonOutput(cmd) {
+					data, jerr := json.MarshalIndent(report, "", "  ")
+					if jerr != nil {
+						return errors.Wrap(errors.ErrCodeInternal, "could not encode the report", jerr)
+					}
+					fmt.Println(string(data))
+				} else {
+					printBinaryReport(report)
+				}
+			}
+			return err
+		})
+	},
+}
+
+// verifyBinary hashes the running executable and checks it against the signed
+// manifest. The report is returned with the error when there is one.
+func verifyBinary() (*binaryReport, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrCodeSystem, "could not find the running executable", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	sum, err := release.HashFile(exe)
+	if err != nil {
+		return nil, errors.FromOSError(err, exe)
+	}
+
+	report := &binaryReport{
+		Executable: exe,
+		SHA256:     sum,
+		Platform:   runtime.GOOS + "/" + runtime.GOARCH,
+		Version:    constants.Version,
+		Commit:     constants.Commit,
+		Builder:    constants.Builder,
+		BuildDate:  constants.BuildDate,
+		Toolchain:  release.ReadToolchain(),
+		Warnings:   binaryWriteWarnings(exe),
+	}
+	fail := func(msg, details string) (*binaryReport, error) {
+		report.Problem = msg
+		return report, errors.New(errors.ErrCodeAuthFailed, msg).WithDetails(details)
+	}
+
+	if constants.ReleasePublicKey == "" {
+		return fail("this build has no release key", "development builds cannot be verified; use a release binary")
+	}
+	key, err := release.ParsePublicKey(constants.ReleasePublicKey)
+	if err != nil {
+		return fail("the embedded release key is invalid", err.Error())
+	}
+	report.ReleaseKey = release.KeyFingerprint(key)
+
+	manifestPath := verifyBinaryManifest
+	if manifestPath == "" {
+		manifestPath = filepath.Join(filepath.Dir(exe), defaultManifestName)
+	}
+	signaturePath := verifyBinarySignature
+	if signaturePath == "" {
+		signaturePath = manifestPath + release.SignatureSuffix
+	}
+	report.Manifest = manifestPath
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		report.Problem = "the release manifest could not be read"
+		return report, errors.FromOSError(err, manifestPath)
+	}
+	signature, err := os.ReadFile(signaturePath)
+	if err != nil {
+		report.Problem = "the manifest signature could not be read"
+		return report, errors.FromOSError(err, signaturePath)
+	}
+	manifest, err := release.VerifyManifest(data, signature, key)
+	if err != nil {
+		return fail("the release manifest is not valid", err.Error())
+	}
+	report.Release = manifest.Release
+
+	binary := manifest.Find(sum)
+	if binary == nil {
+		return fail("the executable is not part of release "+manifest.Release, "its SHA-256 is in no entry of the signed manifest; it may have been replaced or modified")
+	}
+	var mismatches []string
+	if binary.Platform != report.Platform {
+		mismatches = append(mismatches, fmt.Sprintf("platform %s in the manifest, %s running", binary.Platform, report.Platform))
+	}
+	if manifest.Release != constants.Version {
+		mismatches = append(mismatches, fmt.Sprintf("release %s in the manifest, version %s in the binary", manifest.Release, constants.Version))
+	}
+	if manifest.Commit != "" && manifest.Commit != constants.Commit {
+		mismatches = append(mismatches, fmt.Sprintf("commit %s in the manifest, %q in the binary", manifest.Commit, constants.Commit))
+	}
+	if len(mismatches) > 0 {
+		return fail("the executable does not match its manifest entry", strings.Join(mismatches, "; "))
+	}
+	if report.Builder == "" {
+		report.Builder = manifest.Builder
+	}
+	report.Verified = true
+	return report, nil
+}
+
+// binaryWriteWarnings warns about an executable, or a directory holding it,
+// that other users can write to: they could replace it
+func binaryWriteWarnings(exe string) []string {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	var warnings []string
+	for _, path := range []string{exe, filepath.Dir(exe)} {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		mode := info.Mode()
+		// A sticky directory, such as /tmp, only lets owners remove their files
+		if mode.IsDir() && mode&os.ModeSticky != 0 {
+			continue
+		}
+		if mode.Perm()&0o022 != 0 {
+			warnings = append(warnings, fmt.Sprintf("%s is writable by other users (%s)", path, mode.Perm()))
+		}
+	}
+	return warnings
+}
+
+// printBinaryReport prints the provenance and result of 'verify-binary'
+func printBinaryReport(r *binaryReport) {
+	fmt.Println(colors.SafeColor("Executable", colors.Bold))
+	fmt.Printf("  Path:     %s\n", r.Executable)
+	fmt.Printf("  SHA-256:  %s\n", r.SHA256)
+	fmt.Printf("  Platform: %s\n", r.Platform)
+	fmt.Println(colors.SafeColor("Provenance", colors.Bold))
+	fmt.Printf("  Version:  %s\n", r.Version)
+	fmt.Printf("  Commit:   %s\n", valueOrUnknown(r.Commit))
+	fmt.Printf("  Builder:  %s\n", valueOrUnknown(r.Builder))
+	if r.BuildDate != "" {
+		fmt.Printf("  Built:    %s\n", r.BuildDate)
+	}
+	toolchain := valueOrUnknown(r.Toolchain.GoVersion)
+	if r.Toolchain.Revision != "" {
+		toolchain += ", source " + r.Toolchain.Revision
+		if r.Toolchain.Modified {
+			toolchain += " with uncommitted changes"
+		}
+	}
+	fmt.Printf("  Go:       %s\n", toolchain)
+	if r.ReleaseKey != "" {
+		fmt.Printf("  Key:      %s\n", r.ReleaseKey)
+	}
+	if r.Release != "" {
+		fmt.Printf("  Manifest: %s (release %s)\n", r.Manifest, r.Release)
+	}
+	for _, w := range r.Warnings {
+		fmt.Println(colors.SafeColor("WARNING: "+w, colors.Warning))
+	}
+	if r.Verified {
+		fmt.Println(colors.SafeColor(fmt.Sprintf("Verified: this is the %s binary of release %s. Check that the key fingerprint is the one the project publishes.", r.Platform, r.Release), colors.Success))
+	} else if r.Problem != "" {
+		fmt.Println(colors.SafeColor("NOT verified: "+r.Problem, colors.Error))
+	}
+}
+
+// logBinaryReport records the verification in the audit log
+func logBinaryReport(r *binaryReport) {
+	attrs := []any{
+		slog.String("command", "verify-binary"),
+		slog.String("executable", r.Executable),
+		slog.String("sha256", r.SHA256),
+		slog.String("version", r.Version),
+		slog.Bool("verified", r.Verified),
+	}
+	if r.Verified {
+		audit.Logger.Info("Binary verified", attrs...)
+		return
+	}
+	audit.Logger.Warn("Binary verification failed", append(attrs, slog.String("problem", r.Problem))...)
+}
+
+// valueOrUnknown shows an empty provenance field
+func valueOrUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+func init() {
+	verifyBinaryCmd.Flags().StringVar(&verifyBinaryManifest, "manifest", "", "Release manifest (default: "+defaultManifestName+" next to the executable)")
+	verifyBinaryCmd.Flags().StringVar(&verifyBinarySignature, "signature", "", "Signature of the manifest (default: the manifest path with "+release.SignatureSuffix+")")
+	verifyBinaryCmd.Flags().Bool("json", false, "Print the report as JSON")
+}
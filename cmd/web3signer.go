@@ -0,0 +1,353 @@
+// File: cmd/web3signer.go
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/dashboard"
+	"vault.module/internal/errors"
+	"vault.module/internal/keys"
+	"vault.module/internal/noncelog"
+	"vault.module/internal/security"
+	"vault.module/internal/vault"
+	"vault.module/internal/web3signer"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+var web3signerPort int
+var web3signerWallets []string
+var web3signerTag string
+var web3signerChainID int64
+var web3signerRawSign bool
+var web3signerToken bool
+var web3signerAllowNonceReuse bool
+
+var web3signerCmd = &cobra.Command{
+	Use:   "web3signer --wallets PREFIX,... | --tag TAG",
+	Short: "Serves selected wallets as a Web3Signer-compatible remote signer.",
+	Long: `Serves selected wallets as a Web3Signer-compatible remote signer.
+
+Ethereum tools that support a remote signer sign with vault keys through it;
+the keys never leave this process. The vault is decrypted once at startup and
+the addresses of the selected wallets are served on 127.0.0.1 only:
+
+  GET  /upcheck, /healthcheck
+  GET  /api/v1/eth1/publicKeys
+  POST /api/v1/eth1/sign/{publicKey}   only with --raw-sign
+  GET  /api/v1/eth2/publicKeys         always empty: the vault has no BLS keys
+  POST /                               JSON-RPC: eth_accounts, eth_chainId,
+                                       eth_sign, eth_signTypedData(_v4),
+                                       eth_signTransaction
+
+/api/v1/eth1/sign signs the Keccak-256 hash of any data, which may be a
+transaction hash; it is served only with --raw-sign. eth_signTransaction
+needs nonce and gas, as the signer has no node to ask, and returns the signed
+transaction for eth_sendRawTransaction. Transactions are signed for --chain-id
+with EIP-155 replay protection, and typed data whose domain names another chain
+is refused. A transaction whose nonce was already signed for another one, by
+the signer or 'airgap sign', is refused unless --allow-nonce-reuse is given.
+
+Every request must carry "Authorization: Bearer <token>": any process on the
+machine can reach 127.0.0.1. The token is generated for each run and printed
+at startup, or, with --token, it is the token of 'token generate'. A client
+token of 'approvals token' is accepted too. Requests
+from browsers (with an Origin header) are refused. Every signature is audited and counts against the secret retrieval
+limits. With "signing_queue" enabled, only requests that a signing policy of
+the client approves are signed: the client authenticates with its client
+token, as a name alone approves nothing. Raw signing is refused then.
+
+Signing stops when the vault file changes or a tamper event awaits 'vaults
+verify': restart the signer to serve the vault as it is now (a wallet frozen
+meanwhile, for instance).
+
+Examples:
+  vault.module web3signer --wallets relayer
+  vault.module web3signer --tag node --chain-id 11155111 --port 9100
+  vault.module web3signer --wallets ops --token
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("web3signer")
+			}
+			if web3signerPort < 1 || web3signerPort > 65535 {
+				return errors.NewInvalidInputError(strconv.Itoa(web3signerPort), "port must be between 1 and 65535")
+			}
+			if len(web3signerWallets) > 0 && web3signerTag != "" {
+				return errors.NewInvalidInputError("--wallets/--tag", "select wallets with either --wallets or --tag")
+			}
+			if len(web3signerWallets) == 0 && web3signerTag == "" {
+				return errors.NewInvalidInputError("", "select the wallets to serve with --wallets or --tag")
+			}
+			if web3signerChainID < 1 {
+				return errors.NewInvalidInputError(strconv.FormatInt(web3signerChainID, 10), "--chain-id must be positive")
+			}
+			// Clients authenticate with the configured token or one made for this run
+			token := config.Cfg.AuthToken
+			if web3signerToken {
+				if token == "" {
+					return errors.NewInvalidInputError("--token", "no token has been generated; use 'token generate'")
+				}
+			} else {
+				tokenBytes := make([]byte, 32)
+				if _, err := rand.Read(tokenBytes); err != nil {
+					return errors.New(errors.ErrCodeInternal, "failed to generate signer token").WithContext("error", err.Error())
+				}
+				token = hex.EncodeToString(tokenBytes)
+			}
+			if err := checkVaultStatus(); err != nil {
+				return err
+			}
+			activeVault, err := config.GetActiveVault()
+			if err != nil {
+				return err
+			}
+			if activeVault.Type != constants.VaultTypeEVM {
+				return errors.NewInvalidInputError(activeVault.Type, "the remote signer serves EVM keys; use an evm vault")
+			}
+
+			v, err := vault.LoadVault(activeVault)
+			if err != nil {
+				return errors.NewVaultLoadError(activeVault.KeyFile, err)
+			}
+			defer func() {
+				for _, wallet := range v {
+					wallet.Clear()
+				}
+			}()
+			selected, err := selectKeyWallets(v, web3signerWallets, web3signerTag)
+			if err != nil {
+				return err
+			}
+			signer, err := newVaultSigner(activeVault, v, selected)
+			if err != nil {
+				return err
+			}
+			if len(signer.keys) == 0 {
+				return errors.NewInvalidInputError("", "the selected wallets have no private keys to serve")
+			}
+
+			// Signing stops once the vault changes under the signer
+			snapshot := dashboard.NewSnapshot(config.Cfg.ActiveVault, activeVault.Type, activeVault.Encryption, v)
+			signer.store = dashboard.NewStore(snapshot, 0)
+			ctx := security.GetManager().Context()
+			go func() {
+				if err := signer.store.Follow(ctx, activeVault.KeyFile, "audit.log"); err != nil {
+					audit.Logger.Warn("Remote signer cannot follow the vault", slog.String("error", err.Error()))
+				}
+			}()
+
+			server := &web3signer.Server{
+				Keys:    signer.publicKeys(),
+				Signer:  signer,
+				ChainID: web3signerChainID,
+				RawSign: web3signerRawSign,
+				Token:   token,
+				Clients: config.AuthenticateClient,
+			}
+			addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(web3signerPort))
+			audit.Logger.Warn("Remote signer started",
+				slog.String("command", "web3signer"),
+				slog.String("vault", config.Cfg.ActiveVault),
+				slog.String("addr", addr),
+				slog.String("wallets", strings.Join(selected, ",")),
+				slog.Int("addresses", len(signer.keys)),
+				slog.Bool("raw_sign", web3signerRawSign))
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Remote signer for vault '%s' listening on http://%s (chain ID %d)", config.Cfg.ActiveVault, addr, web3signerChainID), colors.Success))
+			for _, key := range server.Keys {
+				fmt.Printf("   %s  %s\n", key.Address.Hex(), signer.keys[key.Address].prefix)
+			}
+			if web3signerToken {
+				fmt.Println(colors.SafeColor("Clients authenticate with the token of 'token generate'.", colors.Info))
+			} else {
+				fmt.Printf("   Authorization: Bearer %s\n", token)
+			}
+			fmt.Println(colors.SafeColor("Press Ctrl+C to stop.", colors.Info))
+
+			if err := server.ListenAndServe(ctx, addr); err != nil {
+				return errors.New(errors.ErrCodeSystem, "remote signer failed").WithContext("error", err.Error())
+			}
+			audit.Logger.Info("Remote signer stopped", slog.String("command", "web3signer"), slog.String("vault", config.Cfg.ActiveVault))
+			return nil
+		})
+	},
+}
+
+// signingKey is an address served by the remote signer
+type signingKey struct {
+	prefix    string
+	address   *vault.Address
+	publicKey []byte
+}
+
+// vaultSigner signs remote signing requests with keys of the active vault
+type vaultSigner struct {
+	mu    sync.Mutex
+	keys  map[common.Address]signingKey
+	store *dashboard.Store
+}
+
+// newVaultSigner collects the addresses of the selected wallets. Sealed
+// wallets are opened; frozen ones are refused.
+func newVaultSigner(details config.VaultDetails, v vault.Vault, selected []string) (*vaultSigner, error) {
+	signer := &vaultSigner{keys: map[common.Address]signingKey{}}
+	for _, prefix := range selected {
+		wallet := v[prefix]
+		if err := openWalletEnvelope("web3signer", prefix, &wallet); err != nil {
+			return nil, err
+		}
+		v[prefix] = wallet
+		if err := checkWalletNotFrozen("web3signer", prefix, wallet); err != nil {
+			return nil, err
+		}
+		for i := range wallet.Addresses {
+			addr := &wallet.Addresses[i]
+			if addr.PrivateKey == nil || addr.PrivateKey.IsEmpty() {
+				continue
+			}
+			publicKey, address, err := keys.EVMPublicKey(addr.PrivateKey.String())
+			if err != nil || !strings.EqualFold(address, addr.Address) {
+				return nil, errors.NewWalletInvalidError(prefix, fmt.Sprintf("the private key of %s does not match its address", addr.Address))
+			}
+			if _, exists := signer.keys[common.HexToAddress(address)]; !exists {
+				signer.keys[common.HexToAddress(address)] = signingKey{prefix: prefix, address: addr, publicKey: publicKey}
+			}
+		}
+		recordWalletAccess(details, v, prefix, "web3signer")
+	}
+	// Only the served wallets stay decrypted
+	for prefix, wallet := range v {
+		if !slices.Contains(selected, prefix) {
+			wallet.Clear()
+		}
+	}
+	return signer, nil
+}
+
+// publicKeys lists the served keys by address
+func (s *vaultSigner) publicKeys() []web3signer.Key {
+	var served []web3signer.Key
+	for address, key := range s.keys {
+		served = append(served, web3signer.Key{Address: address, PublicKey: key.publicKey})
+	}
+	sort.Slice(served, func(i, j int) bool { return served[i].Address.Hex() < served[j].Address.Hex() })
+	return served
+}
+
+// Sign signs a request after checking that the vault is unchanged, the
+// signing policies and the retrieval limits
+func (s *vaultSigner) Sign(address common.Address, req web3signer.Request) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[address]
+	if !ok {
+		return nil, fmt.Errorf("%w: no key for account %s", web3signer.ErrRefused, address.Hex())
+	}
+	refuse := func(reason string) error {
+		audit.Logger.Warn("Remote signing request refused",
+			slog.String("command", "web3signer"),
+			slog.String("vault", config.Cfg.ActiveVault),
+			slog.String("prefix", key.prefix),
+			slog.String("address", address.Hex()),
+			slog.String("method", req.Method),
+			slog.String("client", req.Client),
+			slog.String("reason", reason))
+		return fmt.Errorf("%w: %s", web3signer.ErrRefused, reason)
+	}
+
+	if state := s.store.State(); state.Stale || state.ReauthRequired {
+		return nil, refuse("the vault changed since the signer started; restart it")
+	}
+	if config.Cfg.SigningQueue {
+		dataType, known := web3signerDataTypes[req.Kind]
+		if req.Client == "" {
+			return nil, refuse("signing policies apply to clients authenticated with a client token of 'approvals token'")
+		}
+		if !known || !config.AutoApproves(req.Client, dataType, req.ChainID) {
+			return nil, refuse(fmt.Sprintf("no signing policy of client '%s' approves this request", req.Client))
+		}
+	}
+	digest := sha256.Sum256(req.Payload)
+	nonce, isTransaction := transactionNonce(req.Payload, req.Kind)
+	if isTransaction && !web3signerAllowNonceReuse {
+		reused, err := noncelog.Conflict(address.Hex(), req.ChainID, nonce, hex.EncodeToString(digest[:]))
+		if err != nil {
+			return nil, err
+		}
+		if reused != nil {
+			return nil, refuse(fmt.Sprintf("nonce %d was already signed for another transaction at %s", nonce, reused.SignedAt.Format(time.RFC3339)))
+		}
+	}
+	if err := checkSecretRateLimit(key.prefix); err != nil {
+		return nil, refuse("secret retrieval limit reached")
+	}
+	if isTransaction && !web3signerAllowNonceReuse {
+		// Another request signed meanwhile must not get the same nonce
+		reused, err := noncelog.Claim(address.Hex(), req.ChainID, nonce, hex.EncodeToString(digest[:]), "web3signer")
+		if err != nil {
+			return nil, err
+		}
+		if reused != nil {
+			return nil, refuse(fmt.Sprintf("nonce %d was already signed for another transaction at %s", nonce, reused.SignedAt.Format(time.RFC3339)))
+		}
+	}
+
+	signature, err := keys.SignEVMPayload(key.address.PrivateKey.String(), req.Payload, req.Kind, req.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	audit.Logger.Warn("Remote signing request signed",
+		slog.String("command", "web3signer"),
+		slog.String("vault", config.Cfg.ActiveVault),
+		slog.String("prefix", key.prefix),
+		slog.String("address", address.Hex()),
+		slog.String("method", req.Method),
+		slog.String("client", req.Client),
+		slog.Int64("chain_id", req.ChainID),
+		slog.String("summary", req.Summary),
+		slog.String("sha256", hex.EncodeToString(digest[:])))
+	fmt.Println(colors.SafeColor(fmt.Sprintf("Signed %s for '%s' (%s): %s", req.Method, key.prefix, address.Hex(), req.Summary), colors.Info))
+	if isTransaction {
+		if err := noncelog.Record(address.Hex(), req.ChainID, nonce, hex.EncodeToString(digest[:]), "web3signer"); err != nil {
+			fmt.Println(colors.SafeColor(fmt.Sprintf("Warning: the nonce of this transaction was not recorded: %v", err), colors.Warning))
+		}
+	}
+	return signature, nil
+}
+
+// web3signerDataTypes names payload kinds as signing policies do. Raw data
+// has no name: no policy can approve it.
+var web3signerDataTypes = map[keys.EVMPayloadKind]string{
+	keys.EVMLegacyTransaction: "transaction",
+	keys.EVMTypedTransaction:  "typed-transaction",
+	keys.EVMPersonalMessage:   "personal-message",
+	keys.EVMTypedData:         "typed-data",
+}
+
+func init() {
+	web3signerCmd.Flags().IntVar(&web3signerPort, "port", 9000, "Port to listen on (127.0.0.1 only).")
+	web3signerCmd.Flags().StringSliceVar(&web3signerWallets, "wallets", nil, "Wallets to serve, comma-separated")
+	web3signerCmd.Flags().StringVar(&web3signerTag, "tag", "", "Serve the wallets with this tag")
+	web3signerCmd.Flags().Int64Var(&web3signerChainID, "chain-id", 1, "Chain ID of eth_chainId and of signed transactions")
+	web3signerCmd.Flags().BoolVar(&web3signerRawSign, "raw-sign", false, "Serve /api/v1/eth1/sign, which signs arbitrary hashes")
+	web3signerCmd.Flags().BoolVar(&web3signerToken, "token", false, "Authenticate clients with the programmatic-mode token instead of one generated for this run")
+	web3signerCmd.Flags().BoolVar(&web3signerAllowNonceReuse, "allow-nonce-reuse", false, "Sign a transaction whose nonce was already signed for another one")
+}
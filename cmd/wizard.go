@@ -0,0 +1,216 @@
+// File: cmd/wizard.go
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+
+	"vault.module/internal/actions"
+	"vault.module/internal/audit"
+	"vault.module/internal/colors"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/keys"
+	"vault.module/internal/security"
+	"vault.module/internal/vault"
+	"vault.module/internal/webhook"
+
+	"github.com/spf13/cobra"
+)
+
+var wizardSource string
+var wizardWords int
+
+var generateWizardCmd = &cobra.Command{
+	Use:   "wizard",
+	Short: "Creates a mnemonic from physical dice rolls or coin flips.",
+	Long: `Creates a mnemonic from physical dice rolls or coin flips.
+
+The computer's random number generator is not used: the mnemonic follows
+from your rolls alone, by rules you can check by hand or on another machine.
+
+  coins  128 flips for 12 words, 256 for 24. Heads (H or 1) is a 1 bit,
+         tails (T or 0) a 0 bit; the flips are the entropy itself.
+  dice   at least 50 rolls for 12 words, 100 for 24. The entropy is the
+         SHA-256 of the rolls written as one string of digits, cut to 128
+         or 256 bits.
+
+The wizard checks the results for obvious bias, then shows a worksheet: the
+entropy, the checksum, and every word with the 11 bits that select it. Only
+after you confirm the worksheet is the wallet stored (with --store) or the
+mnemonic printed.
+
+Examples:
+  vault.module generate wizard --source coins --words 12
+  vault.module generate wizard --source dice --store cold2 --notes "dice wallet"
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WrapCommand(func() error {
+			if programmaticMode {
+				return errors.NewProgrammaticModeError("generate wizard")
+			}
+			if wizardWords != 12 && wizardWords != 24 {
+				return errors.NewInvalidInputError(fmt.Sprintf("%d", wizardWords), "--words must be 12 or 24")
+			}
+			bits := wizardWords / 3 * 32
+			if generateStore != "" {
+				if err := validatePrefix(generateStore); err != nil {
+					return err
+				}
+			} else if err := refuseSecretEcho("generate wizard"); err != nil {
+				return err
+			}
+
+			var entropy []byte
+			var values []byte
+			var err error
+			switch wizardSource {
+			case "coins":
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Flip a coin %d times and type each result: H for heads, T for tails.", bits), colors.Info))
+				if values, err = askForRolls("Flips", bits, parseCoinFlip); err != nil {
+					return err
+				}
+				entropy, err = keys.EntropyFromCoins(values)
+			case "dice":
+				needed := security.DiceRollsFor(bits)
+				fmt.Println(colors.SafeColor(fmt.Sprintf("Roll a six-sided die %d times and type the results (1-6).", needed), colors.Info))
+				if values, err = askForRolls("Rolls", needed, parseDieRoll); err != nil {
+					return err
+				}
+				entropy, err = keys.EntropyFromDice(values, bits)
+			default:
+				return errors.NewInvalidInputError(wizardSource, "--source must be dice or coins")
+			}
+			defer security.SecureZero(values)
+			if err != nil {
+				return errors.NewInvalidInputError(wizardSource, err.Error())
+			}
+			defer security.SecureZero(entropy)
+
+			mnemonic, rows, err := keys.MnemonicWorksheet(entropy)
+			if err != nil {
+				return errors.Wrap(errors.ErrCodeSystem, "failed to derive mnemonic", err)
+			}
+			secret := security.NewSecureString(mnemonic)
+			defer secret.Clear()
+
+			warnings := physicalEntropyBias(wizardSource, values)
+			warnings = append(warnings, keys.AnalyzeMnemonic(secret.String())...)
+			for _, w := range warnings {
+				fmt.Println(colors.SafeColor("WARNING: "+w, colors.Warning))
+			}
+			if len(warnings) > 0 && !askForConfirmation(colors.SafeColor("These results do not look random. Continue anyway?", colors.Warning)) {
+				fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+				return nil
+			}
+
+			printMnemonicWorksheet(wizardSource, values, entropy, rows)
+			if !askForConfirmation("Does the worksheet match your own calculation?") {
+				fmt.Println(colors.SafeColor("Cancelled.", colors.Info))
+				return nil
+			}
+
+			if generateStore == "" {
+				audit.Logger.Info("Mnemonic created from physical entropy", slog.String("source", wizardSource), slog.Int("words", wizardWords), slog.Bool("stored", false))
+				return printSecret("generate wizard", secret.String(), true)
+			}
+			return storeWizardMnemonic(generateStore, secret)
+		})
+	},
+}
+
+// physicalEntropyBias flags dice or coin results that are unlikely from a fair source
+func physicalEntropyBias(source string, values []byte) []string {
+	var warnings []string
+	if source == "coins" {
+		heads := 0
+		for _, v := range values {
+			heads += int(v)
+		}
+		// Beyond about four standard deviations of a fair coin
+		if share := float64(heads) / float64(len(values)); share < 0.33 || share > 0.67 {
+			warnings = append(warnings, fmt.Sprintf("%d of %d flips are heads", heads, len(values)))
+		}
+		return warnings
+	}
+	counts := make([]int, 7)
+	for _, v := range values {
+		counts[v]++
+	}
+	expected := float64(len(values)) / 6
+	for face := 1; face <= 6; face++ {
+		if counts[face] == 0 || float64(counts[face]) > 2.2*expected {
+			warnings = append(warnings, fmt.Sprintf("face %d came up %d times in %d rolls (about %.0f expected)", face, counts[face], len(values), expected))
+		}
+	}
+	return warnings
+}
+
+// printMnemonicWorksheet shows how the mnemonic follows from the physical results
+func printMnemonicWorksheet(source string, values, entropy []byte, rows []keys.WorksheetRow) {
+	fmt.Println(colors.SafeColor("Worksheet", colors.Bold))
+	if source == "dice" {
+		digits := make([]byte, len(values))
+		for i, v := range values {
+			digits[i] = '0' + v
+		}
+		fmt.Printf("  1. SHA-256 of the rolls: printf %%s %s | sha256sum\n", string(digits))
+		security.SecureZero(digits)
+		fmt.Printf("  2. Entropy, the first %d hex digits: %s\n", len(entropy)*2, hex.EncodeToString(entropy))
+	} else {
+		fmt.Println("  1. Entropy: the flips in order, heads as 1 and tails as 0")
+		fmt.Printf("  2. The same %d bits in hex: %s\n", len(entropy)*8, hex.EncodeToString(entropy))
+	}
+	fmt.Printf("  3. Checksum: the first %d bits of SHA-256 of the entropy bytes, appended to the bits\n", len(entropy)*8/32)
+	fmt.Println("  4. Each group of 11 bits is a word's position in the BIP-39 English list (from 0):")
+	for i, row := range rows {
+		fmt.Printf("     %2d. %s  %4d  %s\n", i+1, row.Bits, row.Index, row.Word)
+	}
+}
+
+// storeWizardMnemonic saves the mnemonic as a new HD wallet in the active vault
+func storeWizardMnemonic(prefix string, mnemonic *security.SecureString) error {
+	if err := checkVaultStatus(); err != nil {
+		return err
+	}
+	activeVault, err := config.GetActiveVault()
+	if err != nil {
+		return err
+	}
+	v, err := vault.LoadVault(activeVault)
+	if err != nil {
+		return errors.NewVaultLoadError(activeVault.KeyFile, err)
+	}
+	defer func() {
+		for _, wallet := range v {
+			wallet.Clear()
+		}
+	}()
+	if _, exists := v[prefix]; exists {
+		return errors.NewWalletExistsError(prefix)
+	}
+
+	wallet, address, err := actions.CreateWalletFromMnemonic(mnemonic.String(), activeVault.Type)
+	if err != nil {
+		return errors.NewWalletInvalidError(prefix, err.Error())
+	}
+	wallet.Notes = generateNotes
+	v[prefix] = wallet
+	if err := vault.SaveVault(activeVault, v); err != nil {
+		return errors.NewVaultSaveError(activeVault.KeyFile, err)
+	}
+	notifyVaultMutation(webhook.EventWalletAdded, prefix, "HD wallet from physical entropy")
+
+	audit.Logger.Info("Mnemonic created from physical entropy", slog.String("source", wizardSource), slog.Int("words", wizardWords), slog.Bool("stored", true), slog.String("vault", config.Cfg.ActiveVault), slog.String("prefix", prefix))
+	fmt.Println(colors.SafeColor(fmt.Sprintf("HD wallet '%s' stored in vault '%s'. First address: %s", prefix, config.Cfg.ActiveVault, address), colors.Success))
+	return nil
+}
+
+func init() {
+	generateWizardCmd.Flags().StringVar(&wizardSource, "source", "dice", "Physical source: dice or coins")
+	generateWizardCmd.Flags().IntVar(&wizardWords, "words", 24, "Number of words: 12 or 24")
+	generateWizardCmd.Flags().StringVar(&generateStore, "store", "", "Store the mnemonic in the active vault as an HD wallet under this prefix")
+	generateWizardCmd.Flags().StringVar(&generateNotes, "notes", "", "Notes for the stored wallet")
+}
@@ -3,15 +3,20 @@ package actions
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"vault.module/internal/constants"
 	"vault.module/internal/errors"
 	"vault.module/internal/keys"
+	"vault.module/internal/security"
 	"vault.module/internal/vault"
+
+	"github.com/tyler-smith/go-bip39"
 )
 
 // CreateWalletFromMnemonic creates a wallet from a mnemonic for a specific vault type.
@@ -77,43 +82,103 @@ func CreateWalletFromPrivateKey(pkStr, vaultType string) (vault.Wallet, string,
 	return newWallet, finalAddress, nil
 }
 
-// ValidatePrefix checks if a prefix follows the naming rules with enhanced security.
-func ValidatePrefix(prefix string) error {
-	if prefix == "" {
-		return errors.NewInvalidPrefixError(prefix, "prefix cannot be empty")
-	}
-	
-	// Check maximum length (32 characters - optimal balance)
-	if len(prefix) > 32 {
-		return errors.NewInvalidPrefixError(prefix, fmt.Sprintf("prefix too long (max 32 characters, got %d)", len(prefix)))
-	}
-	
-	// Check for valid characters (latin letters, numbers, underscore)
-	match, _ := regexp.MatchString("^[a-zA-Z0-9_]+$", prefix)
-	if !match {
-		return errors.NewInvalidPrefixError(prefix, "prefix can only contain latin letters, numbers and '_' symbols")
-	}
-	
-	// Check that prefix doesn't start with number or underscore
-	match, _ = regexp.MatchString("^[0-9_]", prefix)
-	if match {
-		return errors.NewInvalidPrefixError(prefix, "prefix cannot start with number or '_'")
-	}
-	
-	// Check for reserved prefixes that might cause conflicts
-	reservedPrefixes := []string{"system", "config", "admin", "root", "vault", "temp", "tmp"}
-	lowerPrefix := strings.ToLower(prefix)
-	for _, reserved := range reservedPrefixes {
-		if lowerPrefix == reserved {
-			return errors.NewInvalidPrefixError(prefix, fmt.Sprintf("prefix '%s' is reserved and cannot be used", prefix))
-		}
+// CreateWalletFromEntropy creates an HD wallet from hex BIP-39 entropy for a
+// specific vault type; its mnemonic is reconstructed.
+func CreateWalletFromEntropy(entropyHex, vaultType string) (vault.Wallet, string, error) {
+	newWallet, err := keys.CreateWalletFromEntropy(entropyHex, vaultType)
+	if err != nil {
+		return vault.Wallet{}, "", err
+	}
+	return newWallet, newWallet.Addresses[0].Address, nil
+}
+
+// CreateWalletFromSeed creates an HD wallet from a hex BIP-32 seed for a
+// specific vault type. The wallet has no mnemonic.
+func CreateWalletFromSeed(seedHex, vaultType string) (vault.Wallet, string, error) {
+	newWallet, err := keys.CreateWalletFromSeed(seedHex, vaultType)
+	if err != nil {
+		return vault.Wallet{}, "", err
+	}
+	return newWallet, newWallet.Addresses[0].Address, nil
+}
+
+// CreateRandomWallet creates an HD wallet from a freshly generated 12-word mnemonic.
+func CreateRandomWallet(vaultType string) (vault.Wallet, string, error) {
+	mnemonic, err := GenerateMnemonic(12)
+	if err != nil {
+		return vault.Wallet{}, "", err
+	}
+	return CreateWalletFromMnemonic(mnemonic, vaultType)
+}
+
+// GenerateMnemonic returns a new BIP-39 mnemonic of 12 or 24 words from the
+// system generator mixed with the configured entropy providers.
+func GenerateMnemonic(words int) (string, error) {
+	if words != 12 && words != 24 {
+		return "", errors.NewInvalidInputError(fmt.Sprintf("%d", words), "a mnemonic has 12 or 24 words")
+	}
+	entropy, err := security.RandomBytes(words / 3 * 4)
+	if err != nil {
+		return "", errors.Wrap(errors.ErrCodeSystem, "failed to generate entropy", err)
+	}
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	security.SecureZero(entropy)
+	if err != nil {
+		return "", errors.Wrap(errors.ErrCodeSystem, "failed to generate mnemonic", err)
+	}
+	return mnemonic, nil
+}
+
+// ValidateRename checks that the wallet at oldPrefix of v can be renamed to
+// newPrefix under the vault's naming policy. vaultName is only used in errors.
+func ValidateRename(v vault.Vault, vaultName, oldPrefix, newPrefix string, policy PrefixPolicy) error {
+	if _, exists := v[oldPrefix]; !exists {
+		return errors.NewWalletNotFoundError(oldPrefix, vaultName)
+	}
+	if err := policy.Validate(newPrefix); err != nil {
+		return err
+	}
+	if _, exists := v[newPrefix]; exists {
+		return errors.NewWalletExistsError(newPrefix)
+	}
+	return nil
+}
+
+// RenameWallet moves the wallet at oldPrefix of v to newPrefix, keeping all its data.
+func RenameWallet(v vault.Vault, vaultName, oldPrefix, newPrefix string, policy PrefixPolicy) error {
+	if err := ValidateRename(v, vaultName, oldPrefix, newPrefix, policy); err != nil {
+		return err
+	}
+	v[newPrefix] = v[oldPrefix]
+	delete(v, oldPrefix)
+	return nil
+}
+
+// DeleteWallet removes the wallet at prefix from v and returns it, so the caller
+// can clear its secrets. vaultName is only used in errors.
+func DeleteWallet(v vault.Vault, vaultName, prefix string) (vault.Wallet, error) {
+	wallet, exists := v[prefix]
+	if !exists {
+		return vault.Wallet{}, errors.NewWalletNotFoundError(prefix, vaultName)
+	}
+	delete(v, prefix)
+	return wallet, nil
+}
+
+// checkDerivable refuses wallets that have no mnemonic to derive from right now
+func checkDerivable(wallet vault.Wallet) error {
+	if wallet.Sealed() {
+		return errors.New(errors.ErrCodeWalletInvalid, "wallet is sealed in an envelope").
+			WithDetails("remove it with 'envelope remove' before deriving")
 	}
-	
 	return nil
 }
 
 // DeriveNextAddress derives the next address using the appropriate key manager.
 func DeriveNextAddress(wallet vault.Wallet, vaultType string) (vault.Wallet, vault.Address, error) {
+	if err := checkDerivable(wallet); err != nil {
+		return wallet, vault.Address{}, err
+	}
 	manager, err := keys.GetKeyManager(vaultType)
 	if err != nil {
 		return wallet, vault.Address{}, err
@@ -121,6 +186,30 @@ func DeriveNextAddress(wallet vault.Wallet, vaultType string) (vault.Wallet, vau
 	return manager.DeriveNextAddress(wallet)
 }
 
+// DeriveAddress derives the address at a given BIP-44 account and index.
+func DeriveAddress(wallet vault.Wallet, vaultType string, account, index int) (vault.Wallet, vault.Address, error) {
+	manager, err := keys.GetKeyManager(vaultType)
+	if err != nil {
+		return wallet, vault.Address{}, err
+	}
+	return manager.DeriveAddress(wallet, account, index)
+}
+
+// DeriveAtIndex derives the address at index of a BIP-44 account, or the next
+// unused index of the account if index is negative.
+func DeriveAtIndex(wallet vault.Wallet, vaultType string, account, index int) (vault.Wallet, vault.Address, error) {
+	if err := checkDerivable(wallet); err != nil {
+		return wallet, vault.Address{}, err
+	}
+	if account < 0 {
+		return wallet, vault.Address{}, errors.NewInvalidInputError(fmt.Sprintf("%d", account), "account must be non-negative")
+	}
+	if index < 0 {
+		index = wallet.NextIndex(account)
+	}
+	return DeriveAddress(wallet, vaultType, account, index)
+}
+
 // CloneVault creates a new vault containing only the specified wallets.
 func CloneVault(sourceVault vault.Vault, prefixesToClone []string) (vault.Vault, error) {
 	clonedVault := make(vault.Vault)
@@ -142,48 +231,188 @@ func ExportVault(v vault.Vault) ([]byte, error) {
 	return json.MarshalIndent(v, "", "  ")
 }
 
-// ImportWallets imports wallets into an existing vault.
-func ImportWallets(v vault.Vault, content []byte, format, conflictPolicy, vaultType string) (vault.Vault, string, error) {
+// CanonicalExportVault converts the vault to canonical JSON: object keys sorted
+// at every level, addresses ordered by account and index, no insignificant whitespace and no
+// timestamps (checklist completion times, freeze dates). Its SHA-256 is the same
+// on every machine for the same logical contents. The caller must zero the result.
+func CanonicalExportVault(v vault.Vault) ([]byte, error) {
+	canonical := make(vault.Vault, len(v))
+	for prefix, w := range v {
+		c := w
+		c.Addresses = append([]vault.Address{}, w.Addresses...) // nil and empty encode alike
+		c.SortAddresses()
+		if w.Frozen != nil {
+			frozen := *w.Frozen
+			frozen.Since = ""
+			c.Frozen = &frozen
+		}
+		if w.Checklist != nil {
+			c.Checklist = make([]vault.ChecklistItem, len(w.Checklist))
+			for i, item := range w.Checklist {
+				item.DoneAt = nil
+				c.Checklist[i] = item
+			}
+		}
+		canonical[prefix] = c
+	}
+
+	data, err := json.Marshal(canonical)
+	if err != nil {
+		return nil, err
+	}
+	defer security.SecureZero(data)
+
+	// Round-trip through generic values, which encoding/json writes with sorted keys
+	var generic interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(generic); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// ImportWallets imports wallets into an existing vault. It also returns the
+// prefixes of the wallets added, overwritten or merged, sorted, and a report
+// that for key-value files lists the format detected for each entry.
+//
+// Wallets whose prefix breaks the vault's naming policy are not imported; the
+// report lists them with the rule they break.
+//
+// Under the merge policy, a conflicting wallet of the same seed (or key) is
+// merged into the existing one; other conflicts go to hook, or are skipped
+// when hook is nil.
+func ImportWallets(v vault.Vault, content []byte, format, conflictPolicy, vaultType string, policy PrefixPolicy, hook ConflictHook) (vault.Vault, []string, string, error) {
 	var walletsToImport map[string]vault.Wallet
+	var detected map[string]string
 	var err error
 
 	switch format {
 	case constants.FormatJSON:
 		walletsToImport, err = parseJsonImport(content)
 	case constants.FormatKeyValue:
-		walletsToImport, err = parseKeyValueImport(content, vaultType)
+		walletsToImport, detected, err = parseKeyValueImport(content, vaultType)
 	default:
-		return v, "", errors.NewFormatInvalidError(format, "unknown format")
+		return v, nil, "", errors.NewFormatInvalidError(format, "unknown format")
 	}
 
 	if err != nil {
-		return v, "", errors.NewImportFailedError(format, "error parsing import file", err)
+		return v, nil, "", errors.NewImportFailedError(format, "error parsing import file", err)
 	}
 
 	addedCount := 0
 	skippedCount := 0
 	overwrittenCount := 0
+	mergedCount := 0
+	imported := make([]string, 0, len(walletsToImport))
+	var rejected []string
+
+	// In a fixed order, so hooks see the conflicts the same way every time
+	prefixes := make([]string, 0, len(walletsToImport))
+	for prefix := range walletsToImport {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
 
-	for prefix, newWalletData := range walletsToImport {
+	for _, prefix := range prefixes {
+		newWalletData := walletsToImport[prefix]
+		if err := policy.Validate(prefix); err != nil {
+			newWalletData.Clear()
+			rejected = append(rejected, fmt.Sprintf("%s: %s", prefix, prefixViolation(err)))
+			continue
+		}
 		if oldWallet, exists := v[prefix]; exists {
 			switch conflictPolicy {
 			case constants.ConflictPolicySkip:
 				skippedCount++
 				continue
 			case constants.ConflictPolicyOverwrite:
+				if oldWallet.Frozen != nil {
+					return v, nil, "", errors.NewWalletFrozenError(prefix, oldWallet.Frozen.Reason)
+				}
 				overwrittenCount++
 				oldWallet.Clear() // clear secrets from old wallet
 			case constants.ConflictPolicyFail:
-				return v, "", errors.NewWalletExistsError(prefix)
+				return v, nil, "", errors.NewWalletExistsError(prefix)
+			case constants.ConflictPolicyMerge:
+				if oldWallet.Frozen != nil {
+					return v, nil, "", errors.NewWalletFrozenError(prefix, oldWallet.Frozen.Reason)
+				}
+				if ok, _ := mergeable(oldWallet, newWalletData); ok {
+					v[prefix] = mergeWallets(oldWallet, newWalletData)
+					mergedCount++
+					imported = append(imported, prefix)
+					continue
+				}
+				if hook == nil {
+					newWalletData.Clear()
+					skippedCount++
+					continue
+				}
+				resolution, err := hook(prefix, oldWallet, newWalletData)
+				if err != nil {
+					return v, nil, "", errors.NewImportFailedError(format, "conflict hook failed", err)
+				}
+				switch resolution.Action {
+				case ResolutionKeep:
+					newWalletData.Clear()
+					skippedCount++
+					continue
+				case ResolutionOverwrite:
+					overwrittenCount++
+					oldWallet.Clear()
+				case ResolutionRename:
+					if err := policy.Validate(resolution.Prefix); err != nil {
+						return v, nil, "", err
+					}
+					if _, taken := v[resolution.Prefix]; taken {
+						return v, nil, "", errors.NewWalletExistsError(resolution.Prefix).WithDetails(fmt.Sprintf("the conflict hook renamed '%s' to a prefix in use", prefix))
+					}
+					if _, taken := walletsToImport[resolution.Prefix]; taken {
+						return v, nil, "", errors.NewWalletExistsError(resolution.Prefix).WithDetails(fmt.Sprintf("the conflict hook renamed '%s' to a prefix of the import file", prefix))
+					}
+					prefix = resolution.Prefix
+					addedCount++
+				default:
+					return v, nil, "", errors.NewWalletExistsError(prefix).WithDetails("the conflict hook failed the import")
+				}
 			}
 		} else {
 			addedCount++
 		}
 		v[prefix] = newWalletData
+		imported = append(imported, prefix)
 	}
+	sort.Strings(imported)
 
 	report := fmt.Sprintf("Import complete. Added: %d, Overwritten: %d, Skipped: %d", addedCount, overwrittenCount, skippedCount)
-	return v, report, nil
+	if conflictPolicy == constants.ConflictPolicyMerge {
+		report += fmt.Sprintf(", Merged: %d", mergedCount)
+	}
+	if len(rejected) > 0 {
+		report += fmt.Sprintf(", Rejected: %d\nRejected by the naming policy:", len(rejected))
+		for _, line := range rejected {
+			report += "\n  " + line
+		}
+	}
+	if len(detected) > 0 {
+		entries := make([]string, 0, len(detected))
+		for prefix := range detected {
+			entries = append(entries, prefix)
+		}
+		sort.Strings(entries)
+		report += "\nDetected formats:"
+		for _, prefix := range entries {
+			report += fmt.Sprintf("\n  %s: %s", prefix, detected[prefix])
+		}
+	}
+	return v, imported, report, nil
 }
 
 func parseJsonImport(content []byte) (map[string]vault.Wallet, error) {
@@ -194,14 +423,17 @@ func parseJsonImport(content []byte) (map[string]vault.Wallet, error) {
 	return importedVault, nil
 }
 
-func parseKeyValueImport(content []byte, vaultType string) (map[string]vault.Wallet, error) {
+// parseKeyValueImport parses "prefix=value" lines. It also returns, by prefix,
+// the format each value was detected in, or why it was skipped.
+func parseKeyValueImport(content []byte, vaultType string) (map[string]vault.Wallet, map[string]string, error) {
 	wallets := make(map[string]vault.Wallet)
+	formats := make(map[string]string)
 	scanner := bufio.NewScanner(strings.NewReader(string(content)))
 	re := regexp.MustCompile(`[:=]`)
 
 	manager, err := keys.GetKeyManager(vaultType)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	for scanner.Scan() {
@@ -217,30 +449,45 @@ func parseKeyValueImport(content []byte, vaultType string) (map[string]vault.Wal
 		prefix := strings.TrimSpace(parts[0])
 		value := strings.TrimSpace(parts[1])
 		value = strings.Trim(value, "\"")
-
-		if err := ValidatePrefix(prefix); err != nil {
+		if prefix == "" || strings.ContainsAny(prefix, " \t") {
 			continue
 		}
 
 		var newWallet vault.Wallet
 		var creationErr error
 
-		if manager.ValidateMnemonic(value) {
+		if entropy, ok := strings.CutPrefix(value, "entropy:"); ok {
+			newWallet, creationErr = keys.CreateWalletFromEntropy(entropy, vaultType)
+			formats[prefix] = "BIP-39 entropy"
+		} else if seed, ok := strings.CutPrefix(value, "seed:"); ok {
+			newWallet, creationErr = keys.CreateWalletFromSeed(seed, vaultType)
+			formats[prefix] = "BIP-32 seed"
+		} else if manager.ValidateMnemonic(value) {
 			newWallet, creationErr = manager.CreateWalletFromMnemonic(value)
-		} else if manager.ValidatePrivateKey(value) {
-			newWallet, creationErr = manager.CreateWalletFromPrivateKey(value)
+			formats[prefix] = "mnemonic"
 		} else {
-			continue
+			privateKey, format, err := keys.NormalizePrivateKey(vaultType, value)
+			if err != nil {
+				if format != "" {
+					formats[prefix] = fmt.Sprintf("%s private key, skipped: %v", format, err)
+				} else {
+					formats[prefix] = "not recognized, skipped"
+				}
+				continue
+			}
+			newWallet, creationErr = manager.CreateWalletFromPrivateKey(privateKey)
+			formats[prefix] = format + " private key"
 		}
 
 		if creationErr != nil {
+			formats[prefix] += fmt.Sprintf(", skipped: %v", creationErr)
 			continue
 		}
 		wallets[prefix] = newWallet
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return wallets, nil
+	return wallets, formats, nil
 }
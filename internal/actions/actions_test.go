@@ -0,0 +1,183 @@
+// File: internal/actions/actions_test.go
+package actions
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"vault.module/internal/constants"
+	"vault.module/internal/errors"
+	"vault.module/internal/vault"
+)
+
+// testMnemonic is the BIP-39 test vector mnemonic
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// Its first addresses on the standard EVM path, m/44'/60'/0'/0/i
+const (
+	testAddress0 = "0x9858EfFD232B4033E47d90003D41EC34EcaEda94"
+	testAddress1 = "0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0"
+	testAddress2 = "0xb6716976A3ebe8D39aCEB04372f22Ff8e6802D7A"
+)
+
+func testWallet(t *testing.T) vault.Wallet {
+	t.Helper()
+	wallet, address, err := CreateWalletFromMnemonic(testMnemonic, constants.VaultTypeEVM)
+	if err != nil {
+		t.Fatalf("CreateWalletFromMnemonic() error = %v", err)
+	}
+	if address != testAddress0 {
+		t.Fatalf("CreateWalletFromMnemonic() address = %s, want %s", address, testAddress0)
+	}
+	return wallet
+}
+
+// errorCode returns the code of a VaultError, or "" for nil and other errors
+func errorCode(err error) errors.ErrorCode {
+	var vaultErr *errors.VaultError
+	if stderrors.As(err, &vaultErr) {
+		return vaultErr.Code
+	}
+	return ""
+}
+
+func TestValidateRename(t *testing.T) {
+	v := vault.Vault{"A1": {Notes: "first"}, "B2": {Notes: "second"}}
+
+	tests := []struct {
+		name      string
+		oldPrefix string
+		newPrefix string
+		policy    PrefixPolicy
+		want      errors.ErrorCode // "" when the rename is allowed
+	}{
+		{name: "free prefix", oldPrefix: "A1", newPrefix: "C3"},
+		{name: "missing wallet", oldPrefix: "Z9", newPrefix: "C3", want: errors.ErrCodeWalletNotFound},
+		{name: "taken prefix", oldPrefix: "A1", newPrefix: "B2", want: errors.ErrCodeWalletExists},
+		{name: "invalid prefix", oldPrefix: "A1", newPrefix: "9abc", want: errors.ErrCodeInvalidPrefix},
+		{name: "reserved prefix", oldPrefix: "A1", newPrefix: "admin", want: errors.ErrCodeInvalidPrefix},
+		{name: "policy pattern", oldPrefix: "A1", newPrefix: "C3", policy: PrefixPolicy{Pattern: "^ops_"}, want: errors.ErrCodeInvalidPrefix},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRename(v, "main", tt.oldPrefix, tt.newPrefix, tt.policy)
+			if got := errorCode(err); got != tt.want || (tt.want == "" && err != nil) {
+				t.Fatalf("ValidateRename() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if len(v) != 2 || v["A1"].Notes != "first" || v["B2"].Notes != "second" {
+		t.Fatalf("ValidateRename() changed the vault: %v", v)
+	}
+}
+
+func TestRenameWallet(t *testing.T) {
+	v := vault.Vault{"A1": {Notes: "first", Tags: []string{"hot"}}, "B2": {Notes: "second"}}
+
+	if err := RenameWallet(v, "main", "A1", "C3", PrefixPolicy{}); err != nil {
+		t.Fatalf("RenameWallet() error = %v", err)
+	}
+	if _, exists := v["A1"]; exists {
+		t.Error("the old prefix is still in the vault")
+	}
+	if got := v["C3"]; got.Notes != "first" || len(got.Tags) != 1 || got.Tags[0] != "hot" {
+		t.Errorf("the renamed wallet lost its data: %+v", got)
+	}
+
+	// A refused rename leaves the vault as it was
+	if err := RenameWallet(v, "main", "C3", "B2", PrefixPolicy{}); errorCode(err) != errors.ErrCodeWalletExists {
+		t.Fatalf("RenameWallet() onto a taken prefix = %v, want %s", err, errors.ErrCodeWalletExists)
+	}
+	if err := RenameWallet(v, "main", "A1", "D4", PrefixPolicy{}); errorCode(err) != errors.ErrCodeWalletNotFound {
+		t.Fatalf("RenameWallet() of a missing wallet = %v, want %s", err, errors.ErrCodeWalletNotFound)
+	}
+	if len(v) != 2 || v["C3"].Notes != "first" || v["B2"].Notes != "second" {
+		t.Fatalf("a refused rename changed the vault: %v", v)
+	}
+}
+
+func TestDeleteWallet(t *testing.T) {
+	v := vault.Vault{"A1": {Notes: "first"}, "B2": {Notes: "second"}}
+
+	deleted, err := DeleteWallet(v, "main", "A1")
+	if err != nil {
+		t.Fatalf("DeleteWallet() error = %v", err)
+	}
+	if deleted.Notes != "first" {
+		t.Errorf("DeleteWallet() returned %+v, want the deleted wallet", deleted)
+	}
+	if _, exists := v["A1"]; exists || len(v) != 1 {
+		t.Errorf("DeleteWallet() left %v", v)
+	}
+
+	if _, err := DeleteWallet(v, "main", "A1"); errorCode(err) != errors.ErrCodeWalletNotFound {
+		t.Fatalf("DeleteWallet() of a missing wallet = %v, want %s", err, errors.ErrCodeWalletNotFound)
+	}
+	if len(v) != 1 {
+		t.Fatalf("a refused delete changed the vault: %v", v)
+	}
+}
+
+func TestDeriveAtIndex(t *testing.T) {
+	t.Run("next unused index", func(t *testing.T) {
+		wallet := testWallet(t)
+		wallet, addr, err := DeriveAtIndex(wallet, constants.VaultTypeEVM, 0, -1)
+		if err != nil {
+			t.Fatalf("DeriveAtIndex() error = %v", err)
+		}
+		if addr.Index != 1 || addr.Address != testAddress1 {
+			t.Fatalf("DeriveAtIndex() = index %d %s, want index 1 %s", addr.Index, addr.Address, testAddress1)
+		}
+		if len(wallet.Addresses) != 2 {
+			t.Fatalf("the wallet has %d addresses, want 2", len(wallet.Addresses))
+		}
+	})
+
+	t.Run("given index", func(t *testing.T) {
+		wallet := testWallet(t)
+		wallet, addr, err := DeriveAtIndex(wallet, constants.VaultTypeEVM, 0, 2)
+		if err != nil {
+			t.Fatalf("DeriveAtIndex() error = %v", err)
+		}
+		if addr.Index != 2 || addr.Address != testAddress2 {
+			t.Fatalf("DeriveAtIndex() = index %d %s, want index 2 %s", addr.Index, addr.Address, testAddress2)
+		}
+		// The gap is not filled: the next unused index follows the highest
+		if next := wallet.NextIndex(0); next != 3 {
+			t.Fatalf("NextIndex() = %d after deriving index 2, want 3", next)
+		}
+	})
+
+	t.Run("index already derived", func(t *testing.T) {
+		wallet := testWallet(t)
+		if _, _, err := DeriveAtIndex(wallet, constants.VaultTypeEVM, 0, 0); err == nil {
+			t.Fatal("DeriveAtIndex() of an index the wallet has succeeded")
+		}
+	})
+
+	t.Run("negative account", func(t *testing.T) {
+		wallet := testWallet(t)
+		if _, _, err := DeriveAtIndex(wallet, constants.VaultTypeEVM, -1, 0); errorCode(err) != errors.ErrCodeInvalidInput {
+			t.Fatalf("DeriveAtIndex() = %v, want %s", err, errors.ErrCodeInvalidInput)
+		}
+	})
+
+	t.Run("sealed wallet", func(t *testing.T) {
+		wallet := testWallet(t)
+		wallet.Envelope = "-----BEGIN AGE ENCRYPTED FILE-----"
+		_, _, err := DeriveAtIndex(wallet, constants.VaultTypeEVM, 0, -1)
+		if errorCode(err) != errors.ErrCodeWalletInvalid {
+			t.Fatalf("DeriveAtIndex() = %v, want %s", err, errors.ErrCodeWalletInvalid)
+		}
+	})
+
+	t.Run("imported key", func(t *testing.T) {
+		wallet, _, err := CreateWalletFromPrivateKey("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318", constants.VaultTypeEVM)
+		if err != nil {
+			t.Fatalf("CreateWalletFromPrivateKey() error = %v", err)
+		}
+		if _, _, err := DeriveAtIndex(wallet, constants.VaultTypeEVM, 0, -1); err == nil {
+			t.Fatal("DeriveAtIndex() of a wallet without a mnemonic succeeded")
+		}
+	})
+}
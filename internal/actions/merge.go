@@ -0,0 +1,184 @@
+// File: internal/actions/merge.go
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"vault.module/internal/budget"
+	"vault.module/internal/keys"
+	"vault.module/internal/vault"
+)
+
+// Resolutions of an import conflict returned by a conflict hook
+const (
+	ResolutionKeep      = "keep"      // the existing wallet stays, the imported one is dropped
+	ResolutionOverwrite = "overwrite" // the imported wallet replaces the existing one
+	ResolutionRename    = "rename"    // the imported wallet is added under another prefix
+	ResolutionFail      = "fail"      // the import is aborted
+)
+
+// ConflictResolution is how a conflict hook resolved an import conflict.
+type ConflictResolution struct {
+	Action string
+	Prefix string // New prefix of the imported wallet, for ResolutionRename
+}
+
+// ConflictHook resolves an import conflict the merge policy cannot: wallets of
+// different seeds, watch-only wallets, secrets.
+type ConflictHook func(prefix string, existing, imported vault.Wallet) (ConflictResolution, error)
+
+// mergeable reports whether two wallets hold the same keys and can be merged:
+// HD wallets of the same seed fingerprint and derivation scheme, or
+// single-key wallets of the same address. Otherwise it says why not.
+func mergeable(existing, imported vault.Wallet) (bool, string) {
+	if existing.Kind != "" || imported.Kind != "" {
+		return false, "only HD and single-key wallets are merged"
+	}
+	existingHD := existing.IsHD()
+	importedHD := imported.IsHD()
+	switch {
+	case existingHD && importedHD:
+		a, errA := keys.WalletFingerprint(existing)
+		b, errB := keys.WalletFingerprint(imported)
+		if errA != nil || errB != nil || a != b {
+			return false, "the wallets are of different seeds"
+		}
+		if existing.DerivationPath != imported.DerivationPath || existing.Scheme() != imported.Scheme() {
+			return false, "the wallets are of the same seed but use different derivation paths"
+		}
+		return true, ""
+	case !existingHD && !importedHD:
+		if len(existing.Addresses) == 0 || len(imported.Addresses) == 0 ||
+			!strings.EqualFold(existing.Addresses[0].Address, imported.Addresses[0].Address) {
+			return false, "the wallets hold different keys"
+		}
+		return true, ""
+	}
+	return false, "only one of the wallets is an HD wallet"
+}
+
+// mergeWallets adds to existing the addresses, notes and tags of imported
+// that it lacks. The secrets of imported that are not kept are cleared.
+func mergeWallets(existing, imported vault.Wallet) vault.Wallet {
+	merged := existing
+	merged.Addresses = append([]vault.Address(nil), existing.Addresses...)
+	paths := make(map[string]bool, len(existing.Addresses))
+	for _, a := range existing.Addresses {
+		paths[a.Path] = true
+	}
+	for _, a := range imported.Addresses {
+		if paths[a.Path] {
+			if a.PrivateKey != nil {
+				a.PrivateKey.Clear()
+			}
+			continue
+		}
+		paths[a.Path] = true
+		merged.Addresses = append(merged.Addresses, a)
+	}
+	merged.SortAddresses()
+
+	if notes := strings.TrimSpace(imported.Notes); notes != "" && !strings.Contains(existing.Notes, notes) {
+		if strings.TrimSpace(existing.Notes) == "" {
+			merged.Notes = imported.Notes
+		} else {
+			merged.Notes = strings.TrimRight(existing.Notes, "\n") + "\n" + imported.Notes
+		}
+	}
+
+	merged.Tags = append([]string(nil), existing.Tags...)
+	for _, tag := range imported.Tags {
+		if !merged.HasTag(tag) {
+			merged.Tags = append(merged.Tags, tag)
+		}
+	}
+
+	// A mnemonic of the same seed is a better backup than the seed itself
+	if existing.Seed != nil && imported.Mnemonic != nil && !imported.Mnemonic.IsEmpty() {
+		merged.Mnemonic, merged.Seed, merged.Source = imported.Mnemonic, nil, imported.Source
+		existing.Seed.Clear()
+		imported.Mnemonic = nil
+	}
+	if imported.Mnemonic != nil {
+		imported.Mnemonic.Clear()
+	}
+	if imported.Seed != nil {
+		imported.Seed.Clear()
+	}
+	return merged
+}
+
+// hookConflict is what a conflict hook script reads on stdin. Wallets are
+// sanitized: the script sees addresses, notes and tags, never secrets.
+type hookConflict struct {
+	Prefix              string       `json:"prefix"`
+	Reason              string       `json:"reason"`
+	Existing            vault.Wallet `json:"existing"`
+	Imported            vault.Wallet `json:"imported"`
+	ExistingFingerprint string       `json:"existingSeedFingerprint,omitempty"`
+	ImportedFingerprint string       `json:"importedSeedFingerprint,omitempty"`
+}
+
+// ScriptConflictHook returns a hook that runs script for each conflict. The
+// script reads the conflict as JSON on stdin and prints its resolution:
+// "keep", "overwrite", "fail" or "rename <NEW_PREFIX>".
+func ScriptConflictHook(script string) ConflictHook {
+	return func(prefix string, existing, imported vault.Wallet) (ConflictResolution, error) {
+		_, reason := mergeable(existing, imported)
+		conflict := hookConflict{
+			Prefix:   prefix,
+			Reason:   reason,
+			Existing: existing.Sanitize(),
+			Imported: imported.Sanitize(),
+		}
+		if existing.IsHD() {
+			conflict.ExistingFingerprint, _ = keys.WalletFingerprint(existing)
+		}
+		if imported.IsHD() {
+			conflict.ImportedFingerprint, _ = keys.WalletFingerprint(imported)
+		}
+		input, err := json.Marshal(conflict)
+		if err != nil {
+			return ConflictResolution{}, err
+		}
+
+		ctx, cancel := budget.WithTimeout(30 * time.Second)
+		defer cancel()
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, script)
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		cmd.WaitDelay = time.Second
+		if err := cmd.Run(); err != nil {
+			return ConflictResolution{}, fmt.Errorf("conflict hook %s failed for '%s': %v: %s", script, prefix, err, strings.TrimSpace(stderr.String()))
+		}
+		return parseResolution(stdout.String())
+	}
+}
+
+// parseResolution reads the resolution printed by a conflict hook
+func parseResolution(output string) (ConflictResolution, error) {
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return ConflictResolution{}, fmt.Errorf("conflict hook printed no resolution")
+	}
+	resolution := ConflictResolution{Action: strings.ToLower(fields[0])}
+	switch resolution.Action {
+	case ResolutionKeep, ResolutionOverwrite, ResolutionFail:
+		if len(fields) == 1 {
+			return resolution, nil
+		}
+	case ResolutionRename:
+		if len(fields) == 2 {
+			resolution.Prefix = fields[1]
+			return resolution, nil
+		}
+	}
+	return ConflictResolution{}, fmt.Errorf("conflict hook printed %q; expected keep, overwrite, fail or rename <NEW_PREFIX>", strings.TrimSpace(output))
+}
@@ -0,0 +1,259 @@
+// File: internal/actions/prefix.go
+package actions
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"vault.module/internal/errors"
+	"vault.module/internal/vault"
+)
+
+// DefaultPrefixMaxLength is the longest prefix allowed unless a vault sets its own limit
+const DefaultPrefixMaxLength = 32
+
+// maxPrefixMaxLength bounds the limit a vault can set
+const maxPrefixMaxLength = 128
+
+// reservedPrefixes might conflict with the tool's own names, in every vault
+var reservedPrefixes = []string{"system", "config", "admin", "root", "vault", "temp", "tmp"}
+
+// namespaceSegmentRegex matches one name of a namespace path
+var namespaceSegmentRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.-]*$`)
+
+// schemeRegex matches a naming scheme: segment names joined by '-', '_' or '.'
+var schemeRegex = regexp.MustCompile(`^[a-zA-Z]+([-_.][a-zA-Z]+)*$`)
+
+// NamespaceSeparator separates the namespaces of a hierarchical prefix, as in
+// team/alice/eth-main
+const NamespaceSeparator = "/"
+
+// PrefixPolicy is the naming policy of a vault's wallet prefixes. Its zero
+// value is the built-in policy.
+type PrefixPolicy struct {
+	Pattern    string                  // Regular expression prefixes must match, on top of the character rules
+	MaxLength  int                     // Longest prefix allowed (0 = DefaultPrefixMaxLength)
+	Reserved   []string                // Prefixes reserved in addition to the built-in ones, compared case-insensitively
+	Scheme     string                  // Segments the last name of a prefix is made of, e.g. "team-env-name"; its separators become valid characters
+	Namespaces map[string]PrefixPolicy // Policies of namespaces, applied to the names below them instead of Pattern, Reserved and Scheme
+}
+
+// ValidatePrefix checks if a prefix follows the built-in naming rules.
+func ValidatePrefix(prefix string) error {
+	return PrefixPolicy{}.Validate(prefix)
+}
+
+// Check reports a policy that cannot be applied: a pattern that does not
+// compile, a malformed scheme or a limit out of range.
+func (p PrefixPolicy) Check() error {
+	if p.Pattern != "" {
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return fmt.Errorf("prefix pattern: %v", err)
+		}
+	}
+	if p.MaxLength < 0 || p.MaxLength > maxPrefixMaxLength {
+		return fmt.Errorf("prefix max length must be between 1 and %d", maxPrefixMaxLength)
+	}
+	if p.Scheme != "" && !schemeRegex.MatchString(p.Scheme) {
+		return fmt.Errorf("prefix scheme %q must be segment names joined by '-', '_' or '.', e.g. team-env-name", p.Scheme)
+	}
+	for _, reserved := range p.Reserved {
+		if strings.TrimSpace(reserved) == "" {
+			return fmt.Errorf("reserved prefixes cannot be empty")
+		}
+	}
+	for namespace, policy := range p.Namespaces {
+		if err := ValidateNamespace(namespace); err != nil {
+			return fmt.Errorf("namespace %q: %s", namespace, prefixViolation(err))
+		}
+		if len(policy.Namespaces) > 0 {
+			return fmt.Errorf("namespace %q: namespace policies cannot be nested", namespace)
+		}
+		if err := policy.Check(); err != nil {
+			return fmt.Errorf("namespace %q: %v", namespace, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks a prefix against the policy. The policy is assumed to pass
+// Check. A prefix in a namespace with a policy follows that policy below the
+// namespace; the vault's character rules, maximum length and built-in
+// reserved names apply to the whole prefix either way.
+func (p PrefixPolicy) Validate(prefix string) error {
+	if prefix == "" {
+		return errors.NewInvalidPrefixError(prefix, "prefix cannot be empty")
+	}
+
+	maxLength := p.MaxLength
+	if maxLength == 0 {
+		maxLength = DefaultPrefixMaxLength
+	}
+	if len(prefix) > maxLength {
+		return errors.NewInvalidPrefixError(prefix, fmt.Sprintf("prefix too long (max %d characters, got %d)", maxLength, len(prefix)))
+	}
+
+	namespace, name, governing := p.governing(prefix)
+
+	// Namespaces and the names in them are made of latin letters, numbers,
+	// '_', '-' and '.'; a prefix outside any namespace has latin letters,
+	// numbers and '_', plus the separators of the scheme
+	segments := strings.Split(prefix, NamespaceSeparator)
+	for _, segment := range segments {
+		if segment == "" {
+			return errors.NewInvalidPrefixError(prefix, "namespaces and names cannot be empty (no leading, trailing or double '/')")
+		}
+		if first := segment[0]; !(first >= 'a' && first <= 'z') && !(first >= 'A' && first <= 'Z') {
+			if len(segments) == 1 {
+				return errors.NewInvalidPrefixError(prefix, "prefix must start with a latin letter")
+			}
+			return errors.NewInvalidPrefixError(prefix, fmt.Sprintf("'%s' must start with a latin letter", segment))
+		}
+		if len(segments) > 1 {
+			if !namespaceSegmentRegex.MatchString(segment) {
+				return errors.NewInvalidPrefixError(prefix, fmt.Sprintf("'%s' can only contain latin letters, numbers, '_', '-' and '.' symbols", segment))
+			}
+			continue
+		}
+		separators := governing.separators()
+		for _, r := range segment {
+			valid := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || strings.ContainsRune(separators, r)
+			if !valid {
+				allowed := "latin letters, numbers and '_'"
+				for _, sep := range strings.ReplaceAll(separators, "_", "") {
+					allowed += fmt.Sprintf(", '%c'", sep)
+				}
+				return errors.NewInvalidPrefixError(prefix, fmt.Sprintf("prefix can only contain %s symbols", allowed))
+			}
+		}
+	}
+
+	// Built-in names are reserved as prefixes and as top-level namespaces
+	lowerPrefix := strings.ToLower(prefix)
+	lowerTop := strings.ToLower(segments[0])
+	for _, reserved := range reservedPrefixes {
+		if lowerPrefix == reserved || lowerTop == reserved {
+			return errors.NewInvalidPrefixError(prefix, fmt.Sprintf("prefix '%s' is reserved and cannot be used", prefix))
+		}
+	}
+	for _, reserved := range p.Reserved {
+		if lowerPrefix == strings.ToLower(strings.TrimSpace(reserved)) {
+			return errors.NewInvalidPrefixError(prefix, fmt.Sprintf("prefix '%s' is reserved and cannot be used", prefix))
+		}
+	}
+
+	where := ""
+	if namespace != "" {
+		where = fmt.Sprintf(" of namespace '%s'", namespace)
+		if governing.MaxLength > 0 && len(name) > governing.MaxLength {
+			return errors.NewInvalidPrefixError(prefix, fmt.Sprintf("names%s are at most %d characters, got %d", where, governing.MaxLength, len(name)))
+		}
+		for _, reserved := range governing.Reserved {
+			if strings.ToLower(name) == strings.ToLower(strings.TrimSpace(reserved)) {
+				return errors.NewInvalidPrefixError(prefix, fmt.Sprintf("name '%s' is reserved in namespace '%s'", name, namespace))
+			}
+		}
+	}
+	if governing.Scheme != "" && !governing.schemeRegexp().MatchString(segments[len(segments)-1]) {
+		return errors.NewInvalidPrefixError(prefix, fmt.Sprintf("names%s must follow the naming scheme %s", where, governing.Scheme))
+	}
+	if governing.Pattern != "" {
+		pattern, err := regexp.Compile(governing.Pattern)
+		if err != nil {
+			return errors.NewInvalidPrefixError(prefix, fmt.Sprintf("invalid prefix pattern: %v", err))
+		}
+		if !pattern.MatchString(name) {
+			return errors.NewInvalidPrefixError(prefix, fmt.Sprintf("names%s must match the pattern %s", where, governing.Pattern))
+		}
+	}
+	return nil
+}
+
+// governing returns the most specific namespace of prefix with a policy, the
+// name of prefix below it and its policy; or "", prefix and p itself when no
+// namespace of prefix has a policy
+func (p PrefixPolicy) governing(prefix string) (string, string, PrefixPolicy) {
+	for namespace := Namespace(prefix); namespace != ""; namespace = Namespace(namespace) {
+		if policy, ok := p.Namespaces[namespace]; ok {
+			return namespace, strings.TrimPrefix(prefix, namespace+NamespaceSeparator), policy
+		}
+	}
+	return "", prefix, p
+}
+
+// Namespace returns the namespace of a prefix (team/alice for
+// team/alice/eth-main), or "" for a prefix outside any namespace
+func Namespace(prefix string) string {
+	if i := strings.LastIndex(prefix, NamespaceSeparator); i >= 0 {
+		return prefix[:i]
+	}
+	return ""
+}
+
+// InNamespace reports whether prefix is in namespace or one of its sub-namespaces
+func InNamespace(prefix, namespace string) bool {
+	return strings.HasPrefix(prefix, strings.TrimSuffix(namespace, NamespaceSeparator)+NamespaceSeparator)
+}
+
+// NamespacePrefixes returns the prefixes of v in namespace or its sub-namespaces, sorted
+func NamespacePrefixes(v vault.Vault, namespace string) []string {
+	var prefixes []string
+	for prefix := range v {
+		if InNamespace(prefix, namespace) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+// ValidateNamespace checks that namespace is a well-formed namespace path,
+// such as team or team/alice
+func ValidateNamespace(namespace string) error {
+	if namespace == "" {
+		return errors.NewInvalidPrefixError(namespace, "namespace cannot be empty")
+	}
+	for _, segment := range strings.Split(namespace, NamespaceSeparator) {
+		if !namespaceSegmentRegex.MatchString(segment) {
+			return errors.NewInvalidPrefixError(namespace, "a namespace is names of latin letters, numbers, '_', '-' and '.', each starting with a letter, joined by '/'")
+		}
+	}
+	return nil
+}
+
+// separators returns the separator characters of the scheme
+func (p PrefixPolicy) separators() string {
+	var separators strings.Builder
+	for _, r := range p.Scheme {
+		if (r == '-' || r == '_' || r == '.') && !strings.ContainsRune(separators.String(), r) {
+			separators.WriteRune(r)
+		}
+	}
+	return separators.String()
+}
+
+// schemeRegexp matches the prefixes of the scheme: one non-empty run of
+// letters and numbers per segment, joined by the scheme's separators
+func (p PrefixPolicy) schemeRegexp() *regexp.Regexp {
+	segment := `[a-zA-Z0-9]+`
+	var expr strings.Builder
+	expr.WriteString("^" + segment)
+	for _, r := range p.Scheme {
+		if r == '-' || r == '_' || r == '.' {
+			expr.WriteString(regexp.QuoteMeta(string(r)) + segment)
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String())
+}
+
+// prefixViolation returns the rule a prefix error reports
+func prefixViolation(err error) string {
+	var vErr *errors.VaultError
+	if errors.AsVaultError(err, &vErr) && vErr.Details != "" {
+		return vErr.Details
+	}
+	return err.Error()
+}
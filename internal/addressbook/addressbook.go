@@ -0,0 +1,232 @@
+// File: internal/addressbook/addressbook.go
+package addressbook
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"vault.module/internal/errors"
+	"vault.module/internal/statefile"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// StateFile holds the labels of external addresses. It lives next to
+// config.json and audit.log; addresses and labels are public data.
+const StateFile = "address-book.json"
+
+var stateFile = statefile.File{Path: StateFile, Name: "address book"}
+
+// Import formats
+const (
+	FormatEtherscanCSV = "etherscan-csv" // Etherscan label export: a header row naming the address and name tag columns
+	FormatCSV          = "csv"           // Tag file: address,label rows, with or without a header
+)
+
+// Formats lists the import formats
+var Formats = []string{FormatEtherscanCSV, FormatCSV}
+
+// maxLabelLength bounds a label; longer ones are cut
+const maxLabelLength = 64
+
+// Entry is the label of one address
+type Entry struct {
+	Address string    `json:"address"` // EIP-55 checksummed
+	Label   string    `json:"label"`
+	Source  string    `json:"source,omitempty"` // File the label was imported from
+	AddedAt time.Time `json:"added_at"`
+}
+
+type state struct {
+	Entries []Entry `json:"entries"`
+}
+
+// Parse reads the labels of an import file. Rows without a valid EVM address
+// or a label are skipped and counted.
+func Parse(r io.Reader, format string) ([]Entry, int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.LazyQuotes = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, 0, errors.NewFormatInvalidError(format, fmt.Sprintf("not a CSV file: %v", err))
+	}
+	if len(rows) == 0 {
+		return nil, 0, nil
+	}
+
+	addressColumn, labelColumn := 0, 1
+	switch format {
+	case FormatEtherscanCSV:
+		addressColumn, labelColumn = headerColumns(rows[0])
+		if addressColumn < 0 || labelColumn < 0 {
+			return nil, 0, errors.NewFormatInvalidError(format, "the header row names no \"Address\" and \"Name Tag\" (or \"Name\", \"Label\") columns")
+		}
+		rows = rows[1:]
+	case FormatCSV:
+		// A header row is recognized by its address column not being an address
+		if len(rows[0]) > 0 && !common.IsHexAddress(strings.TrimSpace(rows[0][0])) {
+			rows = rows[1:]
+		}
+	default:
+		return nil, 0, errors.NewInvalidInputError(format, "format must be one of: "+strings.Join(Formats, ", "))
+	}
+
+	var entries []Entry
+	skipped := 0
+	for _, row := range rows {
+		if len(row) <= addressColumn || len(row) <= labelColumn {
+			skipped++
+			continue
+		}
+		address := strings.TrimSpace(row[addressColumn])
+		label := Clean(row[labelColumn])
+		if !common.IsHexAddress(address) || label == "" {
+			skipped++
+			continue
+		}
+		entries = append(entries, Entry{Address: common.HexToAddress(address).Hex(), Label: label})
+	}
+	return entries, skipped, nil
+}
+
+// headerColumns returns the address and label columns of an Etherscan header
+// row, or -1 for those it lacks. A name tag column is preferred to a plain
+// name or label column.
+func headerColumns(header []string) (int, int) {
+	addressColumn, labelColumn, labelRank := -1, -1, 0
+	for i, name := range header {
+		name = strings.ToLower(strings.Trim(strings.TrimSpace(name), "\ufeff\""))
+		rank := 0
+		switch {
+		case name == "address" || name == "contract address":
+			if addressColumn < 0 {
+				addressColumn = i
+			}
+		case strings.Contains(name, "name tag") || name == "nametag":
+			rank = 3
+		case name == "name" || name == "label":
+			rank = 2
+		case name == "labels" || name == "tag":
+			rank = 1
+		}
+		if rank > labelRank {
+			labelColumn, labelRank = i, rank
+		}
+	}
+	return addressColumn, labelColumn
+}
+
+// Clean strips control characters from a label, which comes from a file and
+// is printed to the terminal, and bounds its length
+func Clean(label string) string {
+	label = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == unicode.ReplacementChar {
+			return -1
+		}
+		return r
+	}, label)
+	label = strings.Join(strings.Fields(label), " ")
+	if runes := []rune(label); len(runes) > maxLabelLength {
+		label = string(runes[:maxLabelLength])
+	}
+	return label
+}
+
+// Import adds the entries to the address book, replacing the labels of the
+// addresses it has, and returns how many were added and changed
+func Import(entries []Entry, source string) (int, int, error) {
+	st := &state{}
+	added, updated := 0, 0
+	err := stateFile.Update(st, func() error {
+		index := make(map[string]int, len(st.Entries))
+		for i, e := range st.Entries {
+			index[e.Address] = i
+		}
+
+		now := time.Now().UTC()
+		for _, e := range entries {
+			e.Source = source
+			e.AddedAt = now
+			if i, ok := index[e.Address]; ok {
+				if st.Entries[i].Label != e.Label {
+					st.Entries[i] = e
+					updated++
+				}
+				continue
+			}
+			index[e.Address] = len(st.Entries)
+			st.Entries = append(st.Entries, e)
+			added++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return added, updated, nil
+}
+
+// Remove removes the label of an address
+func Remove(address string) error {
+	if !common.IsHexAddress(address) {
+		return errors.NewInvalidInputError(address, "not an EVM address")
+	}
+	address = common.HexToAddress(address).Hex()
+	st := &state{}
+	return stateFile.Update(st, func() error {
+		for i, e := range st.Entries {
+			if e.Address == address {
+				st.Entries = append(st.Entries[:i], st.Entries[i+1:]...)
+				return nil
+			}
+		}
+		return errors.NewInvalidInputError(address, "the address book has no label for this address")
+	})
+}
+
+// List returns the entries, sorted by label
+func List() ([]Entry, error) {
+	st := &state{}
+	if err := stateFile.Load(st); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(st.Entries, func(i, j int) bool {
+		return strings.ToLower(st.Entries[i].Label) < strings.ToLower(st.Entries[j].Label)
+	})
+	return st.Entries, nil
+}
+
+// Label returns the label of an address, or "" when it has none or the
+// address book cannot be read
+func Label(address string) string {
+	if !common.IsHexAddress(address) {
+		return ""
+	}
+	address = common.HexToAddress(address).Hex()
+	st := &state{}
+	if err := stateFile.Load(st); err != nil {
+		return ""
+	}
+	for _, e := range st.Entries {
+		if e.Address == address {
+			return e.Label
+		}
+	}
+	return ""
+}
+
+// Describe returns an address as "label (address)" when it has a label, or
+// as the checksummed address
+func Describe(address common.Address) string {
+	if label := Label(address.Hex()); label != "" {
+		return fmt.Sprintf("%s (%s)", label, address.Hex())
+	}
+	return address.Hex()
+}
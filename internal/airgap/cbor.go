@@ -0,0 +1,221 @@
+// File: internal/airgap/cbor.go
+package airgap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// The UR registry types are small CBOR documents; this is the subset of CBOR
+// (RFC 8949) they use: unsigned/negative integers, byte and text strings,
+// arrays, maps, tags, booleans and null. Indefinite lengths are not supported.
+
+// CBORTag is a tagged CBOR item
+type CBORTag struct {
+	Number  uint64
+	Content interface{}
+}
+
+// CBORMap is a CBOR map with unsigned integer keys, as used by the UR registry
+type CBORMap map[uint64]interface{}
+
+// maxCBORDepth bounds nesting when decoding untrusted input
+const maxCBORDepth = 16
+
+// EncodeCBOR encodes v, which may be built from uint64/int/int64, []byte, string,
+// bool, nil, []interface{}, CBORMap and CBORTag
+func EncodeCBOR(v interface{}) ([]byte, error) {
+	return appendCBOR(nil, v)
+}
+
+func appendCBOR(out []byte, v interface{}) ([]byte, error) {
+	var err error
+	switch x := v.(type) {
+	case nil:
+		return append(out, 0xF6), nil
+	case bool:
+		if x {
+			return append(out, 0xF5), nil
+		}
+		return append(out, 0xF4), nil
+	case uint64:
+		return cborHead(out, 0, x), nil
+	case uint32:
+		return cborHead(out, 0, uint64(x)), nil
+	case int:
+		return appendCBOR(out, int64(x))
+	case int64:
+		if x >= 0 {
+			return cborHead(out, 0, uint64(x)), nil
+		}
+		return cborHead(out, 1, uint64(-1-x)), nil
+	case []byte:
+		return append(cborHead(out, 2, uint64(len(x))), x...), nil
+	case string:
+		return append(cborHead(out, 3, uint64(len(x))), x...), nil
+	case []interface{}:
+		out = cborHead(out, 4, uint64(len(x)))
+		for _, item := range x {
+			if out, err = appendCBOR(out, item); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	case CBORMap:
+		// Canonical order: keys are small unsigned integers, so numeric order is
+		// the same as the bytewise order of their encodings
+		keys := make([]uint64, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+		out = cborHead(out, 5, uint64(len(x)))
+		for _, k := range keys {
+			out = cborHead(out, 0, k)
+			if out, err = appendCBOR(out, x[k]); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	case CBORTag:
+		out = cborHead(out, 6, x.Number)
+		return appendCBOR(out, x.Content)
+	default:
+		return nil, fmt.Errorf("cannot encode %T as CBOR", v)
+	}
+}
+
+// DecodeCBOR decodes a single CBOR item that must span all of b. Unsigned integers
+// decode as uint64, negative ones as int64, maps with integer keys as CBORMap.
+func DecodeCBOR(b []byte) (interface{}, error) {
+	v, rest, err := decodeCBORItem(b, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("trailing data after CBOR item")
+	}
+	return v, nil
+}
+
+func decodeCBORItem(b []byte, depth int) (interface{}, []byte, error) {
+	if depth > maxCBORDepth {
+		return nil, nil, fmt.Errorf("CBOR nesting too deep")
+	}
+	if len(b) > 0 && b[0]>>5 == 7 {
+		switch b[0] {
+		case 0xF4:
+			return false, b[1:], nil
+		case 0xF5:
+			return true, b[1:], nil
+		case 0xF6, 0xF7:
+			return nil, b[1:], nil
+		default:
+			return nil, nil, fmt.Errorf("unsupported CBOR simple value 0x%02x", b[0])
+		}
+	}
+	major, arg, rest, err := cborReadHead(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch major {
+	case 0:
+		return arg, rest, nil
+	case 1:
+		if arg > 1<<62 {
+			return nil, nil, fmt.Errorf("CBOR negative integer out of range")
+		}
+		return -1 - int64(arg), rest, nil
+	case 2, 3:
+		if uint64(len(rest)) < arg {
+			return nil, nil, fmt.Errorf("unexpected end of CBOR data")
+		}
+		if major == 2 {
+			return append([]byte(nil), rest[:arg]...), rest[arg:], nil
+		}
+		return string(rest[:arg]), rest[arg:], nil
+	case 4:
+		if arg > uint64(len(rest)) {
+			return nil, nil, fmt.Errorf("CBOR array length exceeds data")
+		}
+		items := make([]interface{}, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			var item interface{}
+			if item, rest, err = decodeCBORItem(rest, depth+1); err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+		}
+		return items, rest, nil
+	case 5:
+		if arg > uint64(len(rest)) {
+			return nil, nil, fmt.Errorf("CBOR map length exceeds data")
+		}
+		m := make(CBORMap, arg)
+		for i := uint64(0); i < arg; i++ {
+			var key, val interface{}
+			if key, rest, err = decodeCBORItem(rest, depth+1); err != nil {
+				return nil, nil, err
+			}
+			k, ok := key.(uint64)
+			if !ok {
+				return nil, nil, fmt.Errorf("unsupported CBOR map key %T", key)
+			}
+			if val, rest, err = decodeCBORItem(rest, depth+1); err != nil {
+				return nil, nil, err
+			}
+			m[k] = val
+		}
+		return m, rest, nil
+	case 6:
+		content, rest, err := decodeCBORItem(rest, depth+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		return CBORTag{Number: arg, Content: content}, rest, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported CBOR major type %d", major)
+	}
+}
+
+// cborHead appends a CBOR item header with the shortest argument encoding
+func cborHead(out []byte, major byte, v uint64) []byte {
+	m := major << 5
+	switch {
+	case v < 24:
+		return append(out, m|byte(v))
+	case v <= 0xFF:
+		return append(out, m|24, byte(v))
+	case v <= 0xFFFF:
+		return append(out, m|25, byte(v>>8), byte(v))
+	case v <= 0xFFFFFFFF:
+		return append(out, m|26, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	default:
+		out = append(out, m|27)
+		return binary.BigEndian.AppendUint64(out, v)
+	}
+}
+
+func cborReadHead(b []byte) (major byte, v uint64, rest []byte, err error) {
+	if len(b) == 0 {
+		return 0, 0, nil, fmt.Errorf("unexpected end of CBOR data")
+	}
+	major, info := b[0]>>5, b[0]&0x1F
+	b = b[1:]
+	switch {
+	case info < 24:
+		return major, uint64(info), b, nil
+	case info <= 27:
+		n := 1 << (info - 24)
+		if len(b) < n {
+			return 0, 0, nil, fmt.Errorf("unexpected end of CBOR data")
+		}
+		for _, c := range b[:n] {
+			v = v<<8 | uint64(c)
+		}
+		return major, v, b[n:], nil
+	default:
+		return 0, 0, nil, fmt.Errorf("unsupported CBOR encoding")
+	}
+}
@@ -0,0 +1,376 @@
+// File: internal/airgap/fountain.go
+package airgap
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// minFragmentLen is the smallest fragment the encoder will produce
+const minFragmentLen = 10
+
+// Part is one fountain-coded fragment of a message. Parts 1..SeqLen carry the
+// fragments in order; later parts carry pseudo-random XOR mixes of them, so a
+// receiver can complete the message from any sufficiently large set of parts.
+type Part struct {
+	SeqNum     uint32
+	SeqLen     int
+	MessageLen int
+	Checksum   uint32
+	Data       []byte
+}
+
+// Encoder produces an endless sequence of parts for a message
+type Encoder struct {
+	message   []byte
+	fragments [][]byte
+	checksum  uint32
+	seqNum    uint32
+}
+
+// NewEncoder splits message into fragments of at most maxFragmentLen bytes
+func NewEncoder(message []byte, maxFragmentLen int) (*Encoder, error) {
+	if len(message) == 0 {
+		return nil, fmt.Errorf("message is empty")
+	}
+	if maxFragmentLen < minFragmentLen {
+		return nil, fmt.Errorf("fragment length must be at least %d", minFragmentLen)
+	}
+	fragmentLen := nominalFragmentLen(len(message), minFragmentLen, maxFragmentLen)
+	return &Encoder{
+		message:   message,
+		fragments: partitionMessage(message, fragmentLen),
+		checksum:  crc32.ChecksumIEEE(message),
+	}, nil
+}
+
+// SeqLen is the number of fragments; at least this many parts are needed to decode
+func (e *Encoder) SeqLen() int {
+	return len(e.fragments)
+}
+
+// IsSinglePart reports whether the message fits in one part
+func (e *Encoder) IsSinglePart() bool {
+	return len(e.fragments) == 1
+}
+
+// NextPart returns the next part of the sequence
+func (e *Encoder) NextPart() Part {
+	e.seqNum++
+	indexes := chooseFragments(e.seqNum, len(e.fragments), e.checksum)
+	data := make([]byte, len(e.fragments[0]))
+	for _, i := range indexes {
+		xorInto(data, e.fragments[i])
+	}
+	return Part{
+		SeqNum:     e.seqNum,
+		SeqLen:     len(e.fragments),
+		MessageLen: len(e.message),
+		Checksum:   e.checksum,
+		Data:       data,
+	}
+}
+
+// Decoder reassembles a message from parts received in any order
+type Decoder struct {
+	seqLen     int
+	messageLen int
+	checksum   uint32
+	fragLen    int
+
+	simple  map[int][]byte
+	mixed   []mixedPart
+	seen    map[string]bool
+	message []byte
+	err     error
+}
+
+type mixedPart struct {
+	indexes []int
+	data    []byte
+}
+
+// NewDecoder returns an empty decoder
+func NewDecoder() *Decoder {
+	return &Decoder{simple: map[int][]byte{}, seen: map[string]bool{}}
+}
+
+// Receive adds a part. It returns false if the part was redundant or inconsistent
+// with the parts received so far.
+func (d *Decoder) Receive(p Part) bool {
+	if d.IsComplete() {
+		return false
+	}
+	if p.SeqLen == 0 || p.MessageLen == 0 || len(p.Data) == 0 {
+		return false
+	}
+	if d.seqLen == 0 {
+		d.seqLen, d.messageLen, d.checksum, d.fragLen = p.SeqLen, p.MessageLen, p.Checksum, len(p.Data)
+	} else if p.SeqLen != d.seqLen || p.MessageLen != d.messageLen || p.Checksum != d.checksum || len(p.Data) != d.fragLen {
+		return false
+	}
+
+	indexes := chooseFragments(p.SeqNum, p.SeqLen, p.Checksum)
+	key := fmt.Sprint(indexes)
+	if d.seen[key] {
+		return false
+	}
+	d.seen[key] = true
+
+	d.process(mixedPart{indexes: indexes, data: append([]byte(nil), p.Data...)})
+	if len(d.simple) == d.seqLen {
+		d.finish()
+	}
+	return true
+}
+
+// process reduces a part by the known fragments and propagates any new fragment
+func (d *Decoder) process(p mixedPart) {
+	queue := []mixedPart{p}
+	for len(queue) > 0 {
+		part := queue[0]
+		queue = queue[1:]
+		part = d.reduce(part)
+		switch len(part.indexes) {
+		case 0:
+			continue
+		case 1:
+			idx := part.indexes[0]
+			if _, ok := d.simple[idx]; ok {
+				continue
+			}
+			d.simple[idx] = part.data
+			// The new fragment may reduce pending mixed parts
+			pending := d.mixed
+			d.mixed = nil
+			queue = append(queue, pending...)
+		default:
+			d.mixed = append(d.mixed, part)
+		}
+	}
+}
+
+// reduce XORs out every fragment of p that is already known
+func (d *Decoder) reduce(p mixedPart) mixedPart {
+	var remaining []int
+	for _, i := range p.indexes {
+		if frag, ok := d.simple[i]; ok {
+			xorInto(p.data, frag)
+		} else {
+			remaining = append(remaining, i)
+		}
+	}
+	p.indexes = remaining
+	return p
+}
+
+func (d *Decoder) finish() {
+	message := make([]byte, 0, d.seqLen*d.fragLen)
+	for i := 0; i < d.seqLen; i++ {
+		message = append(message, d.simple[i]...)
+	}
+	message = message[:d.messageLen]
+	if crc32.ChecksumIEEE(message) != d.checksum {
+		d.err = fmt.Errorf("message checksum mismatch")
+		return
+	}
+	d.message = message
+}
+
+// IsComplete reports whether the message has been reassembled (or failed to verify)
+func (d *Decoder) IsComplete() bool {
+	return d.message != nil || d.err != nil
+}
+
+// Result returns the reassembled message
+func (d *Decoder) Result() ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if d.message == nil {
+		return nil, fmt.Errorf("message is incomplete")
+	}
+	return d.message, nil
+}
+
+// Progress returns the number of known fragments and the total
+func (d *Decoder) Progress() (int, int) {
+	return len(d.simple), d.seqLen
+}
+
+// nominalFragmentLen finds the fragment length that splits the message into the
+// fewest fragments not longer than maxFragmentLen
+func nominalFragmentLen(messageLen, minLen, maxLen int) int {
+	maxCount := messageLen / minLen
+	if maxCount < 1 {
+		maxCount = 1
+	}
+	fragmentLen := 0
+	for count := 1; count <= maxCount; count++ {
+		fragmentLen = (messageLen + count - 1) / count
+		if fragmentLen <= maxLen {
+			break
+		}
+	}
+	return fragmentLen
+}
+
+// partitionMessage splits message into zero-padded fragments of fragmentLen bytes
+func partitionMessage(message []byte, fragmentLen int) [][]byte {
+	var fragments [][]byte
+	for off := 0; off < len(message); off += fragmentLen {
+		frag := make([]byte, fragmentLen)
+		copy(frag, message[off:])
+		fragments = append(fragments, frag)
+	}
+	return fragments
+}
+
+func xorInto(dst, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+// chooseFragments returns the fragment indexes mixed into part seqNum. The first
+// seqLen parts are the fragments themselves; later parts are derived from a PRNG
+// seeded with the sequence number and message checksum, so encoder and decoder agree.
+func chooseFragments(seqNum uint32, seqLen int, checksum uint32) []int {
+	if int(seqNum) <= seqLen {
+		return []int{int(seqNum) - 1}
+	}
+
+	var seed [8]byte
+	binary.BigEndian.PutUint32(seed[0:4], seqNum)
+	binary.BigEndian.PutUint32(seed[4:8], checksum)
+	rng := newXoshiro(sha256.Sum256(seed[:]))
+
+	degree := chooseDegree(seqLen, rng)
+	indexes := make([]int, seqLen)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	shuffled := shuffle(indexes, rng)
+	chosen := shuffled[:degree]
+	sort.Ints(chosen)
+	return chosen
+}
+
+// chooseDegree samples the number of fragments in a mixed part with probability
+// proportional to 1/degree
+func chooseDegree(seqLen int, rng *xoshiro) int {
+	probs := make([]float64, seqLen)
+	for i := range probs {
+		probs[i] = 1 / float64(i+1)
+	}
+	return newSampler(probs).next(rng) + 1
+}
+
+func shuffle(items []int, rng *xoshiro) []int {
+	remaining := append([]int(nil), items...)
+	result := make([]int, 0, len(items))
+	for len(remaining) > 0 {
+		i := rng.nextInt(0, len(remaining)-1)
+		result = append(result, remaining[i])
+		remaining = append(remaining[:i], remaining[i+1:]...)
+	}
+	return result
+}
+
+// xoshiro is the Xoshiro256** generator
+type xoshiro struct {
+	s [4]uint64
+}
+
+func newXoshiro(seed [32]byte) *xoshiro {
+	x := &xoshiro{}
+	for i := range x.s {
+		x.s[i] = binary.BigEndian.Uint64(seed[i*8:])
+	}
+	return x
+}
+
+func (x *xoshiro) next() uint64 {
+	result := bits.RotateLeft64(x.s[1]*5, 7) * 9
+	t := x.s[1] << 17
+	x.s[2] ^= x.s[0]
+	x.s[3] ^= x.s[1]
+	x.s[1] ^= x.s[2]
+	x.s[0] ^= x.s[3]
+	x.s[2] ^= t
+	x.s[3] = bits.RotateLeft64(x.s[3], 45)
+	return result
+}
+
+func (x *xoshiro) nextDouble() float64 {
+	return float64(x.next()) / (float64(math.MaxUint64) + 1)
+}
+
+func (x *xoshiro) nextInt(low, high int) int {
+	return int(x.nextDouble()*float64(high-low+1)) + low
+}
+
+// sampler draws from a discrete distribution using Vose's alias method
+type sampler struct {
+	probs   []float64
+	aliases []int
+}
+
+func newSampler(weights []float64) *sampler {
+	n := len(weights)
+	sum := 0.0
+	for _, w := range weights {
+		sum += w
+	}
+	p := make([]float64, n)
+	for i, w := range weights {
+		p[i] = w * float64(n) / sum
+	}
+
+	var small, large []int
+	for i := n - 1; i >= 0; i-- {
+		if p[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+
+	s := &sampler{probs: make([]float64, n), aliases: make([]int, n)}
+	for len(small) > 0 && len(large) > 0 {
+		a := small[len(small)-1]
+		small = small[:len(small)-1]
+		g := large[len(large)-1]
+		large = large[:len(large)-1]
+		s.probs[a] = p[a]
+		s.aliases[a] = g
+		p[g] += p[a] - 1
+		if p[g] < 1 {
+			small = append(small, g)
+		} else {
+			large = append(large, g)
+		}
+	}
+	for _, i := range large {
+		s.probs[i] = 1
+	}
+	for _, i := range small {
+		s.probs[i] = 1
+	}
+	return s
+}
+
+func (s *sampler) next(rng *xoshiro) int {
+	r1 := rng.nextDouble()
+	r2 := rng.nextDouble()
+	i := int(float64(len(s.probs)) * r1)
+	if r2 < s.probs[i] {
+		return i
+	}
+	return s.aliases[i]
+}
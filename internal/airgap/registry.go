@@ -0,0 +1,297 @@
+// File: internal/airgap/registry.go
+package airgap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// UR registry types understood by Keystone, SeedSigner and compatible wallets
+// (BCR-2020-006 and the Keystone Ethereum extensions)
+const (
+	TypeCryptoPSBT     = "crypto-psbt"
+	TypeCryptoAccount  = "crypto-account"
+	TypeCryptoHDKey    = "crypto-hdkey"
+	TypeEthSignRequest = "eth-sign-request"
+	TypeEthSignature   = "eth-signature"
+)
+
+// CBOR tags of the registry types
+const (
+	tagUUID       = 37
+	tagHDKey      = 303
+	tagKeypath    = 304
+	tagCoinInfo   = 305
+	tagOutput     = 308
+	coinTypeEther = 60
+)
+
+// Data types of an eth-sign-request
+const (
+	EthDataTransaction      = 1 // RLP-encoded legacy transaction
+	EthDataTypedData        = 2 // EIP-712 typed data as JSON
+	EthDataPersonalMessage  = 3 // personal_sign message
+	EthDataTypedTransaction = 4 // EIP-2718 typed transaction
+)
+
+// EthDataTypeNames names the data types in signing policies and summaries
+var EthDataTypeNames = map[int]string{
+	EthDataTransaction:      "transaction",
+	EthDataTypedData:        "typed-data",
+	EthDataPersonalMessage:  "personal-message",
+	EthDataTypedTransaction: "typed-transaction",
+}
+
+// IsByteStringType reports whether URs of urType carry a payload wrapped in a
+// CBOR byte string
+func IsByteStringType(urType string) bool {
+	return urType == TypeBytes || urType == TypeCryptoPSBT
+}
+
+// EthSignRequest asks the signer to sign SignData with the key at Path
+type EthSignRequest struct {
+	RequestID         []byte
+	SignData          []byte
+	DataType          int
+	ChainID           int64
+	Path              string
+	SourceFingerprint uint32
+	Address           []byte
+	Origin            string
+}
+
+// DecodeEthSignRequest parses the CBOR body of an eth-sign-request UR
+func DecodeEthSignRequest(b []byte) (*EthSignRequest, error) {
+	v, err := DecodeCBOR(b)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(CBORMap)
+	if !ok {
+		return nil, fmt.Errorf("eth-sign-request is not a CBOR map")
+	}
+
+	req := &EthSignRequest{DataType: EthDataTransaction, ChainID: 1}
+	if id, ok := m[1]; ok {
+		if req.RequestID, ok = untag(id, tagUUID).([]byte); !ok {
+			return nil, fmt.Errorf("eth-sign-request has a malformed request id")
+		}
+	}
+	if req.SignData, ok = m[2].([]byte); !ok || len(req.SignData) == 0 {
+		return nil, fmt.Errorf("eth-sign-request has no sign data")
+	}
+	if dt, ok := m[3]; ok {
+		n, ok := dt.(uint64)
+		if !ok || n < EthDataTransaction || n > EthDataTypedTransaction {
+			return nil, fmt.Errorf("eth-sign-request has unsupported data type %v", dt)
+		}
+		req.DataType = int(n)
+	}
+	if c, ok := m[4]; ok {
+		switch id := c.(type) {
+		case uint64:
+			if id > 1<<53 {
+				return nil, fmt.Errorf("eth-sign-request chain id out of range")
+			}
+			req.ChainID = int64(id)
+		case int64:
+			req.ChainID = id
+		default:
+			return nil, fmt.Errorf("eth-sign-request has a malformed chain id")
+		}
+	}
+	if req.Path, req.SourceFingerprint, err = decodeKeypath(m[5]); err != nil {
+		return nil, err
+	}
+	if a, ok := m[6]; ok {
+		if req.Address, ok = a.([]byte); !ok || len(req.Address) != 20 {
+			return nil, fmt.Errorf("eth-sign-request has a malformed address")
+		}
+	}
+	if o, ok := m[7]; ok {
+		req.Origin, _ = o.(string)
+	}
+	return req, nil
+}
+
+// EncodeEthSignRequest encodes req as the CBOR body of an eth-sign-request UR
+func EncodeEthSignRequest(req *EthSignRequest) ([]byte, error) {
+	path, err := encodeKeypath(req.Path, req.SourceFingerprint)
+	if err != nil {
+		return nil, err
+	}
+	m := CBORMap{
+		2: req.SignData,
+		3: uint64(req.DataType),
+		4: req.ChainID,
+		5: path,
+	}
+	if req.RequestID != nil {
+		m[1] = CBORTag{Number: tagUUID, Content: req.RequestID}
+	}
+	if req.Address != nil {
+		m[6] = req.Address
+	}
+	if req.Origin != "" {
+		m[7] = req.Origin
+	}
+	return EncodeCBOR(m)
+}
+
+// EncodeEthSignature encodes the signer's answer to the request with requestID
+func EncodeEthSignature(requestID, signature []byte, origin string) ([]byte, error) {
+	m := CBORMap{2: signature}
+	if requestID != nil {
+		m[1] = CBORTag{Number: tagUUID, Content: requestID}
+	}
+	if origin != "" {
+		m[3] = origin
+	}
+	return EncodeCBOR(m)
+}
+
+// HDKey is an extended public key with its origin, as exported to watch-only wallets
+type HDKey struct {
+	PublicKey         []byte // compressed secp256k1 public key
+	ChainCode         []byte
+	Path              string // origin path, e.g. m/44'/60'/0'
+	MasterFingerprint uint32
+	ParentFingerprint uint32
+	Children          string // relative path of the derived addresses, e.g. 0/*
+	Name              string
+	Note              string
+}
+
+func (k *HDKey) cbor() (CBORMap, error) {
+	origin, err := encodeKeypath(k.Path, k.MasterFingerprint)
+	if err != nil {
+		return nil, err
+	}
+	m := CBORMap{
+		3: k.PublicKey,
+		4: k.ChainCode,
+		5: CBORTag{Number: tagCoinInfo, Content: CBORMap{1: uint64(coinTypeEther)}},
+		6: origin,
+		8: uint64(k.ParentFingerprint),
+	}
+	if k.Children != "" {
+		children, err := encodeKeypath(k.Children, 0)
+		if err != nil {
+			return nil, err
+		}
+		m[7] = children
+	}
+	if k.Name != "" {
+		m[9] = k.Name
+	}
+	if k.Note != "" {
+		m[10] = k.Note
+	}
+	return m, nil
+}
+
+// EncodeCryptoHDKey encodes key as the CBOR body of a crypto-hdkey UR
+func EncodeCryptoHDKey(key *HDKey) ([]byte, error) {
+	m, err := key.cbor()
+	if err != nil {
+		return nil, err
+	}
+	return EncodeCBOR(m)
+}
+
+// EncodeCryptoAccount encodes the keys of one seed as the CBOR body of a
+// crypto-account UR. Each key is an output descriptor holding the bare hdkey.
+func EncodeCryptoAccount(masterFingerprint uint32, keys []*HDKey) ([]byte, error) {
+	outputs := make([]interface{}, 0, len(keys))
+	for _, k := range keys {
+		m, err := k.cbor()
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, CBORTag{Number: tagOutput, Content: CBORTag{Number: tagHDKey, Content: m}})
+	}
+	return EncodeCBOR(CBORMap{1: uint64(masterFingerprint), 2: outputs})
+}
+
+// encodeKeypath encodes a path such as m/44'/60'/0' or 0/* as a crypto-keypath.
+// Components are [index, hardened] pairs; a wildcard is an empty array.
+func encodeKeypath(path string, sourceFingerprint uint32) (CBORTag, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "m"), "/")
+	components := []interface{}{}
+	if path != "" {
+		for _, c := range strings.Split(path, "/") {
+			hardened := strings.HasSuffix(c, "'") || strings.HasSuffix(c, "h")
+			c = strings.TrimRight(c, "'h")
+			if c == "*" {
+				components = append(components, []interface{}{}, hardened)
+				continue
+			}
+			idx, err := strconv.ParseUint(c, 10, 31)
+			if err != nil {
+				return CBORTag{}, fmt.Errorf("invalid derivation path component %q", c)
+			}
+			components = append(components, idx, hardened)
+		}
+	}
+	m := CBORMap{1: components}
+	if sourceFingerprint != 0 {
+		m[2] = uint64(sourceFingerprint)
+	}
+	return CBORTag{Number: tagKeypath, Content: m}, nil
+}
+
+// decodeKeypath returns the path of a crypto-keypath as m/44'/60'/0'/0/0
+func decodeKeypath(v interface{}) (string, uint32, error) {
+	if v == nil {
+		return "", 0, nil
+	}
+	m, ok := untag(v, tagKeypath).(CBORMap)
+	if !ok {
+		return "", 0, fmt.Errorf("malformed derivation path")
+	}
+	components, ok := m[1].([]interface{})
+	if !ok || len(components)%2 != 0 {
+		return "", 0, fmt.Errorf("malformed derivation path components")
+	}
+	var b strings.Builder
+	b.WriteString("m")
+	for i := 0; i < len(components); i += 2 {
+		hardened, ok := components[i+1].(bool)
+		if !ok {
+			return "", 0, fmt.Errorf("malformed derivation path components")
+		}
+		b.WriteByte('/')
+		switch c := components[i].(type) {
+		case uint64:
+			if c >= 1<<31 {
+				return "", 0, fmt.Errorf("derivation path index out of range")
+			}
+			b.WriteString(strconv.FormatUint(c, 10))
+		case []interface{}:
+			b.WriteByte('*')
+		default:
+			return "", 0, fmt.Errorf("unsupported derivation path component")
+		}
+		if hardened {
+			b.WriteByte('\'')
+		}
+	}
+	var fingerprint uint32
+	if fp, ok := m[2].(uint64); ok && fp <= 0xFFFFFFFF {
+		fingerprint = uint32(fp)
+	}
+	return b.String(), fingerprint, nil
+}
+
+// untag returns the content of v if it carries tag, and v unchanged if it is untagged.
+// Registry types may be embedded with or without their tag.
+func untag(v interface{}, tag uint64) interface{} {
+	if t, ok := v.(CBORTag); ok {
+		if t.Number != tag {
+			return nil
+		}
+		return t.Content
+	}
+	return v
+}
@@ -0,0 +1,229 @@
+// File: internal/airgap/ur.go
+package airgap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TypeBytes is the UR type for an opaque payload
+const TypeBytes = "bytes"
+
+var urTypeRegex = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// bytewords maps each byte value to a four-letter word; the minimal encoding
+// used in URs keeps only the first and last letter of each word
+var bytewords = strings.Fields(`
+able acid also apex aqua arch atom aunt away axis back bald barn belt beta bias
+blue body brag brew bulb buzz calm cash cats chef city claw code cola cook cost
+crux curl cusp cyan dark data days deli dice diet door down draw drop drum dull
+duty each easy echo edge epic even exam exit eyes fact fair fern figs film fish
+fizz flap flew flux foxy free frog fuel fund gala game gear gems gift girl glow
+good gray grim guru gush gyro half hang hard hawk heat help high hill holy hope
+horn huts iced idea idle inch inky into iris iron item jade jazz join jolt jowl
+judo jugs jump junk jury keep keno kept keys kick kiln king kite kiwi knob lamb
+lava lazy leaf legs liar limp lion list logo loud love luau luck lung main many
+math maze memo menu meow mild mint miss monk nail navy need news next noon note
+numb obey oboe omit onyx open oval owls paid part peck play plus poem pool pose
+puff puma purr quad quiz race ramp real redo rich road rock roof ruby ruin runs
+rust safe saga scar sets silk skew slot soap solo song stub surf swan taco task
+taxi tent tied time tiny toil tomb toys trip tuna twin ugly undo unit urge user
+vast very veto vial vibe view visa void vows wall wand warm wasp wave waxy webs
+what when whiz wolf work yank yawn yell yoga yurt zaps zero zest zinc zone zoom
+`)
+
+var minimalBytewords = func() map[string]byte {
+	m := make(map[string]byte, len(bytewords))
+	for i, w := range bytewords {
+		m[w[:1]+w[3:]] = byte(i)
+	}
+	return m
+}()
+
+// encodeBytewords returns the minimal bytewords encoding of data with its CRC32 appended
+func encodeBytewords(data []byte) string {
+	var crc [4]byte
+	binary.BigEndian.PutUint32(crc[:], crc32.ChecksumIEEE(data))
+	var b strings.Builder
+	for _, c := range append(append([]byte(nil), data...), crc[:]...) {
+		w := bytewords[c]
+		b.WriteByte(w[0])
+		b.WriteByte(w[3])
+	}
+	return b.String()
+}
+
+// decodeBytewords reverses encodeBytewords and verifies the checksum
+func decodeBytewords(s string) ([]byte, error) {
+	s = strings.ToLower(s)
+	if len(s)%2 != 0 || len(s) < 10 {
+		return nil, fmt.Errorf("invalid bytewords length")
+	}
+	out := make([]byte, 0, len(s)/2)
+	for i := 0; i < len(s); i += 2 {
+		c, ok := minimalBytewords[s[i:i+2]]
+		if !ok {
+			return nil, fmt.Errorf("invalid byteword %q", s[i:i+2])
+		}
+		out = append(out, c)
+	}
+	data, crc := out[:len(out)-4], out[len(out)-4:]
+	if binary.BigEndian.Uint32(crc) != crc32.ChecksumIEEE(data) {
+		return nil, fmt.Errorf("bytewords checksum mismatch")
+	}
+	return data, nil
+}
+
+// EncodeUR encodes message as a sequence of UR strings. A message that fits in
+// one fragment yields a single "ur:<type>/<body>"; otherwise parts are produced
+// on demand by the returned function, starting with the plain fragments.
+func EncodeUR(urType string, message []byte, maxFragmentLen int) (single string, next func() string, seqLen int, err error) {
+	if !urTypeRegex.MatchString(urType) {
+		return "", nil, 0, fmt.Errorf("invalid UR type %q", urType)
+	}
+	enc, err := NewEncoder(message, maxFragmentLen)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	if enc.IsSinglePart() {
+		return "ur:" + urType + "/" + encodeBytewords(message), nil, 1, nil
+	}
+	next = func() string {
+		p := enc.NextPart()
+		return fmt.Sprintf("ur:%s/%d-%d/%s", urType, p.SeqNum, p.SeqLen, encodeBytewords(encodePartCBOR(p)))
+	}
+	return "", next, enc.SeqLen(), nil
+}
+
+// URDecoder collects UR strings until the message is complete
+type URDecoder struct {
+	urType  string
+	single  []byte
+	decoder *Decoder
+}
+
+// NewURDecoder returns an empty UR decoder
+func NewURDecoder() *URDecoder {
+	return &URDecoder{decoder: NewDecoder()}
+}
+
+// Receive parses one UR string. Scanners often upper-case QR contents, so the
+// comparison is case-insensitive.
+func (u *URDecoder) Receive(s string) error {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if !strings.HasPrefix(s, "ur:") {
+		return fmt.Errorf("not a UR string")
+	}
+	components := strings.Split(s[3:], "/")
+	if len(components) < 2 || len(components) > 3 || !urTypeRegex.MatchString(components[0]) {
+		return fmt.Errorf("malformed UR string")
+	}
+	if u.urType != "" && components[0] != u.urType {
+		return fmt.Errorf("UR type %q does not match %q", components[0], u.urType)
+	}
+	u.urType = components[0]
+
+	body, err := decodeBytewords(components[len(components)-1])
+	if err != nil {
+		return err
+	}
+	if len(components) == 2 {
+		u.single = body
+		return nil
+	}
+
+	seq := strings.SplitN(components[1], "-", 2)
+	if len(seq) != 2 {
+		return fmt.Errorf("malformed UR sequence %q", components[1])
+	}
+	if _, err := strconv.ParseUint(seq[0], 10, 32); err != nil {
+		return fmt.Errorf("malformed UR sequence %q", components[1])
+	}
+	part, err := decodePartCBOR(body)
+	if err != nil {
+		return err
+	}
+	u.decoder.Receive(part)
+	return nil
+}
+
+// IsComplete reports whether the message is available
+func (u *URDecoder) IsComplete() bool {
+	return u.single != nil || u.decoder.IsComplete()
+}
+
+// Progress returns the number of known fragments and the total
+func (u *URDecoder) Progress() (int, int) {
+	if u.single != nil {
+		return 1, 1
+	}
+	return u.decoder.Progress()
+}
+
+// Result returns the UR type and the reassembled message
+func (u *URDecoder) Result() (string, []byte, error) {
+	if u.single != nil {
+		return u.urType, u.single, nil
+	}
+	msg, err := u.decoder.Result()
+	return u.urType, msg, err
+}
+
+// encodePartCBOR encodes a part as the CBOR array [seqNum, seqLen, messageLen, checksum, data]
+func encodePartCBOR(p Part) []byte {
+	out := []byte{0x85}
+	out = cborHead(out, 0, uint64(p.SeqNum))
+	out = cborHead(out, 0, uint64(p.SeqLen))
+	out = cborHead(out, 0, uint64(p.MessageLen))
+	out = cborHead(out, 0, uint64(p.Checksum))
+	out = cborHead(out, 2, uint64(len(p.Data)))
+	return append(out, p.Data...)
+}
+
+func decodePartCBOR(b []byte) (Part, error) {
+	if len(b) == 0 || b[0] != 0x85 {
+		return Part{}, fmt.Errorf("UR part is not a 5-element CBOR array")
+	}
+	b = b[1:]
+	var vals [4]uint64
+	var err error
+	for i := range vals {
+		var major byte
+		major, vals[i], b, err = cborReadHead(b)
+		if err != nil || major != 0 {
+			return Part{}, fmt.Errorf("malformed UR part header")
+		}
+	}
+	major, n, b, err := cborReadHead(b)
+	if err != nil || major != 2 || uint64(len(b)) != n {
+		return Part{}, fmt.Errorf("malformed UR part data")
+	}
+	if vals[0] > 0xFFFFFFFF || vals[3] > 0xFFFFFFFF || vals[1] > 1<<20 || vals[2] > 1<<30 {
+		return Part{}, fmt.Errorf("UR part header out of range")
+	}
+	return Part{
+		SeqNum:     uint32(vals[0]),
+		SeqLen:     int(vals[1]),
+		MessageLen: int(vals[2]),
+		Checksum:   uint32(vals[3]),
+		Data:       b,
+	}, nil
+}
+
+// EncodeCBORBytes wraps data in a CBOR byte string, the payload format of ur:bytes
+func EncodeCBORBytes(data []byte) []byte {
+	return append(cborHead(nil, 2, uint64(len(data))), data...)
+}
+
+// DecodeCBORBytes unwraps a CBOR byte string
+func DecodeCBORBytes(b []byte) ([]byte, error) {
+	major, n, rest, err := cborReadHead(b)
+	if err != nil || major != 2 || uint64(len(rest)) != n {
+		return nil, fmt.Errorf("payload is not a CBOR byte string")
+	}
+	return rest, nil
+}
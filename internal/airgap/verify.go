@@ -0,0 +1,23 @@
+// File: internal/airgap/verify.go
+package airgap
+
+import (
+	"crypto/sha256"
+	"strings"
+)
+
+// verificationCodeWords is the number of bytewords in a verification code (32 bits)
+const verificationCodeWords = 4
+
+// VerificationCode returns a short code derived from payload: the first four bytes
+// of its SHA-256 as upper-case bytewords, e.g. "JADE KITE ONYX TUNA". A companion
+// device computes the same code from what it received, so comparing the two codes
+// reveals a payload substituted on the way.
+func VerificationCode(payload []byte) string {
+	digest := sha256.Sum256(payload)
+	words := make([]string, verificationCodeWords)
+	for i := range words {
+		words[i] = strings.ToUpper(bytewords[digest[i]])
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,255 @@
+// File: internal/approval/approval.go
+package approval
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/budget"
+	"vault.module/internal/errors"
+)
+
+// DefaultTimeout is how long a decryption waits for approval when the vault does not configure one
+const DefaultTimeout = 5 * time.Minute
+
+// pollInterval is the delay between status checks while a request is pending
+const pollInterval = 2 * time.Second
+
+// Approval states reported by the approval service
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusDenied   = "denied"
+)
+
+// Request is the JSON body POSTed to the approval endpoint.
+type Request struct {
+	ID          string    `json:"request_id"`
+	Vault       string    `json:"vault"`
+	Host        string    `json:"host"`
+	User        string    `json:"user"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// Response is returned by the approval endpoint, both for the initial POST and for polls.
+// PollURL is optional; when empty the status is polled at <endpoint>/<request_id>. It
+// may be relative to the endpoint, and must have the endpoint's scheme and host.
+type Response struct {
+	Status  string `json:"status"`
+	PollURL string `json:"poll_url,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ValidateURL checks an approval endpoint. Plain HTTP is only accepted for loopback hosts.
+func ValidateURL(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("approval URL must be an absolute http(s) URL")
+	}
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		host := u.Hostname()
+		if host == "localhost" || host == "127.0.0.1" || host == "::1" {
+			return nil
+		}
+		return fmt.Errorf("approval URL must use https for non-local hosts")
+	default:
+		return fmt.Errorf("approval URL must use http or https")
+	}
+}
+
+// Await sends an approval request for decrypting vaultName and blocks until it is
+// approved, denied or the timeout passes. A bearer token is taken from
+// VAULT_APPROVAL_TOKEN if set.
+func Await(endpoint, vaultName string, timeout time.Duration) error {
+	if err := ValidateURL(endpoint); err != nil {
+		return errors.NewConfigValidationError("approval_url", endpoint, err.Error())
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	ctx, cancel := budget.WithTimeout(timeout)
+	defer cancel()
+
+	req := newRequest(vaultName)
+	audit.Logger.Info("Requesting remote approval for decryption",
+		slog.String("vault", vaultName),
+		slog.String("request_id", req.ID))
+	fmt.Fprintf(os.Stderr, "Waiting for remote approval of request %s (timeout %s)...\n", req.ID, timeout)
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return errors.New(errors.ErrCodeInternal, "failed to encode approval request").WithContext("marshal_error", err.Error())
+	}
+
+	resp, err := send(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return approvalError(ctx, vaultName, req.ID, timeout, err)
+	}
+
+	pollURL := strings.TrimRight(endpoint, "/") + "/" + req.ID
+	if resp.PollURL != "" {
+		// The token in VAULT_APPROVAL_TOKEN goes with every poll: a response
+		// must not send it to another host
+		if pollURL, err = resolvePollURL(endpoint, resp.PollURL); err != nil {
+			audit.Logger.Error("Approval service returned an unacceptable poll URL",
+				slog.String("vault", vaultName),
+				slog.String("request_id", req.ID),
+				slog.String("error", err.Error()))
+			return errors.New(errors.ErrCodeUnavailable, "approval service returned an unacceptable poll URL").
+				WithDetails(err.Error()).
+				WithContext("request_id", req.ID)
+		}
+	}
+
+	for {
+		switch resp.Status {
+		case StatusApproved:
+			audit.Logger.Info("Remote approval granted",
+				slog.String("vault", vaultName),
+				slog.String("request_id", req.ID))
+			return nil
+		case StatusDenied:
+			audit.Logger.Warn("Remote approval denied",
+				slog.String("vault", vaultName),
+				slog.String("request_id", req.ID),
+				slog.String("message", resp.Message))
+			return errors.NewAuthFailedError("decryption request was denied by the approver").
+				WithContext("request_id", req.ID)
+		case StatusPending, "":
+		default:
+			return errors.New(errors.ErrCodeUnavailable, "approval service returned an unknown status").
+				WithDetails(resp.Status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return approvalError(ctx, vaultName, req.ID, timeout, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+
+		resp, err = send(ctx, http.MethodGet, pollURL, nil)
+		if err != nil {
+			return approvalError(ctx, vaultName, req.ID, timeout, err)
+		}
+	}
+}
+
+// resolvePollURL resolves the poll URL of a response against the endpoint and
+// checks that it is a valid approval URL with the endpoint's scheme and host
+func resolvePollURL(endpoint, pollURL string) (string, error) {
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(pollURL)
+	if err != nil {
+		return "", fmt.Errorf("poll URL is not a URL")
+	}
+	resolved := base.ResolveReference(ref)
+	if err := ValidateURL(resolved.String()); err != nil {
+		return "", fmt.Errorf("poll URL: %v", err)
+	}
+	if resolved.Scheme != base.Scheme || !strings.EqualFold(resolved.Host, base.Host) {
+		return "", fmt.Errorf("poll URL %s://%s is not on the approval endpoint %s://%s", resolved.Scheme, resolved.Host, base.Scheme, base.Host)
+	}
+	if resolved.User != nil {
+		return "", fmt.Errorf("poll URL must not carry credentials")
+	}
+	return resolved.String(), nil
+}
+
+// newRequest builds the approval request describing this decryption
+func newRequest(vaultName string) Request {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	host, _ := os.Hostname()
+	username := ""
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+
+	return Request{
+		ID:          hex.EncodeToString(id),
+		Vault:       vaultName,
+		Host:        host,
+		User:        username,
+		RequestedAt: time.Now().UTC(),
+	}
+}
+
+// client follows redirects only on the approval service, as every request carries the token
+var client = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		if req.URL.Scheme != via[0].URL.Scheme || !strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+			return fmt.Errorf("approval service redirected to %s://%s", req.URL.Scheme, req.URL.Host)
+		}
+		return nil
+	},
+}
+
+// send performs one call against the approval service and decodes its response
+func send(ctx context.Context, method, endpoint string, body io.Reader) (*Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if token := os.Getenv("VAULT_APPROVAL_TOKEN"); token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		return nil, fmt.Errorf("approval service responded with %s", httpResp.Status)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(io.LimitReader(httpResp.Body, 64*1024)).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("invalid approval response: %w", err)
+	}
+	resp.Status = strings.ToLower(strings.TrimSpace(resp.Status))
+	return &resp, nil
+}
+
+// approvalError maps transport failures and timeouts to VaultErrors
+func approvalError(ctx context.Context, vaultName, requestID string, timeout time.Duration, cause error) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		audit.Logger.Warn("Remote approval timed out",
+			slog.String("vault", vaultName),
+			slog.String("request_id", requestID))
+		return errors.NewTimeoutError("remote approval", timeout.String()).
+			WithContext("request_id", requestID)
+	}
+	audit.Logger.Error("Remote approval request failed",
+		slog.String("vault", vaultName),
+		slog.String("request_id", requestID),
+		slog.String("error", cause.Error()))
+	return errors.Wrap(errors.ErrCodeUnavailable, "approval service unreachable", cause).
+		WithContext("request_id", requestID)
+}
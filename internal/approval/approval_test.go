@@ -0,0 +1,37 @@
+// File: internal/approval/approval_test.go
+package approval
+
+import "testing"
+
+func TestResolvePollURL(t *testing.T) {
+	const endpoint = "https://approve.example.com/v1/requests"
+	tests := []struct {
+		name    string
+		pollURL string
+		want    string
+		wantErr bool
+	}{
+		{name: "same host", pollURL: "https://approve.example.com/v1/requests/42", want: "https://approve.example.com/v1/requests/42"},
+		{name: "relative", pollURL: "/v1/status/42", want: "https://approve.example.com/v1/status/42"},
+		{name: "other host", pollURL: "https://attacker.example.net/collect", wantErr: true},
+		{name: "protocol-relative other host", pollURL: "//attacker.example.net/collect", wantErr: true},
+		{name: "downgrade to http", pollURL: "http://approve.example.com/v1/requests/42", wantErr: true},
+		{name: "other port", pollURL: "https://approve.example.com:8443/v1/requests/42", wantErr: true},
+		{name: "credentials", pollURL: "https://user:pw@approve.example.com/v1/requests/42", wantErr: true},
+		{name: "other scheme", pollURL: "file:///etc/passwd", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolvePollURL(endpoint, tt.pollURL)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("resolvePollURL(%q) = %q, want an error", tt.pollURL, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("resolvePollURL(%q) = %q, %v, want %q", tt.pollURL, got, err, tt.want)
+			}
+		})
+	}
+}
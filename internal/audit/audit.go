@@ -4,19 +4,38 @@ package audit
 import (
 	"log/slog"
 	"os"
+	"time"
 )
 
 var Logger *slog.Logger
 
 // InitLogger initializes the logger for auditing purposes.
 func InitLogger() error {
-	// Open or create the log file for appending.
-	logFile, err := os.OpenFile("audit.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	// Open or create the log file for appending. It is also read: every record
+	// continues the sequence and hash chain of the last one (see chain.go).
+	logFile, err := os.OpenFile("audit.log", os.O_APPEND|os.O_CREATE|os.O_RDWR, 0600)
 	if err != nil {
 		return err
 	}
 
-	// Create a logger that writes JSON to the specified file.
-	Logger = slog.New(slog.NewJSONHandler(logFile, nil))
+	// Create a logger that writes JSON to the specified file, with times in UTC
+	// whatever the machine's time zone.
+	json := slog.NewJSONHandler(logFile, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.String(slog.TimeKey, a.Value.Time().UTC().Format(time.RFC3339Nano))
+			}
+			return a
+		},
+	})
+	chain = &chainFile{path: "audit.log", file: logFile}
+	Logger = slog.New(&chainHandler{file: chain, inner: json})
 	return nil
 }
+
+// InitDiscardLogger initializes a logger that drops every record, for commands
+// that only list configuration and leave no audit trail.
+func InitDiscardLogger() {
+	chain = nil
+	Logger = slog.New(slog.DiscardHandler)
+}
@@ -0,0 +1,185 @@
+// File: internal/audit/chain.go
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"vault.module/internal/filelock"
+)
+
+// Every audit record carries a sequence number, one more than the record
+// before it in the file, and the SHA-256 of the previous line. Any number of
+// processes append to the same log; each takes an exclusive lock on the file
+// for the read-and-append, so the sequence orders records even when the clock
+// jumps, and a removed, reordered or edited line breaks the chain.
+
+// Attribute keys of the chain
+const (
+	SeqKey  = "seq"
+	PrevKey = "prev"
+)
+
+// chainFile is the audit log the handlers append to
+type chainFile struct {
+	path  string
+	file  *os.File
+	carry []byte // Last record of the rotated log, continued by an empty file
+}
+
+// chain is the log of Logger
+var chain *chainFile
+
+// chainHandler adds the sequence number and previous-line hash to records
+type chainHandler struct {
+	file  *chainFile
+	inner slog.Handler
+}
+
+func (h *chainHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h *chainHandler) Handle(ctx context.Context, r slog.Record) error {
+	f := h.file.file
+	if err := filelock.Lock(f); err != nil {
+		return err
+	}
+	defer filelock.Unlock(f)
+
+	last, err := lastLine(f)
+	if err != nil {
+		return err
+	}
+	if len(last) == 0 {
+		last = h.file.carry
+	}
+	seq, prev := int64(1), ""
+	if len(last) > 0 {
+		seq = recordSeq(last) + 1
+		sum := sha256.Sum256(last)
+		prev = hex.EncodeToString(sum[:])
+	}
+
+	// The chain goes first, right after time, level and message
+	chained := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	chained.AddAttrs(slog.Int64(SeqKey, seq), slog.String(PrevKey, prev))
+	r.Attrs(func(a slog.Attr) bool {
+		chained.AddAttrs(a)
+		return true
+	})
+	return h.inner.Handle(ctx, chained)
+}
+
+func (h *chainHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &chainHandler{file: h.file, inner: h.inner.WithAttrs(attrs)}
+}
+
+func (h *chainHandler) WithGroup(name string) slog.Handler {
+	return &chainHandler{file: h.file, inner: h.inner.WithGroup(name)}
+}
+
+// lastLine returns the last complete line of f without its newline
+func lastLine(f *os.File) ([]byte, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	for window := int64(64 * 1024); ; window *= 4 {
+		start := size - window
+		if start < 0 {
+			start = 0
+		}
+		buf := make([]byte, size-start)
+		if _, err := f.ReadAt(buf, start); err != nil && err != io.EOF {
+			return nil, err
+		}
+		buf = bytes.TrimRight(buf, "\n")
+		if i := bytes.LastIndexByte(buf, '\n'); i >= 0 {
+			return buf[i+1:], nil
+		}
+		if start == 0 {
+			return buf, nil
+		}
+	}
+}
+
+// recordSeq returns the sequence number of a record, 0 if it has none
+func recordSeq(line []byte) int64 {
+	var record struct {
+		Seq int64 `json:"seq"`
+	}
+	json.Unmarshal(line, &record)
+	return record.Seq
+}
+
+// ChainReport is the outcome of verifying an audit log.
+type ChainReport struct {
+	Records    int      // Records read
+	Unchained  int      // Records before the first one with a sequence number
+	Problems   []string // Breaks of the chain, by line
+	ClockJumps int      // Records timestamped before the record preceding them
+}
+
+// VerifyChain checks the sequence numbers and hash chain of the audit log at
+// path, or of a rotated .gz archive.
+func VerifyChain(path string) (ChainReport, error) {
+	var report ChainReport
+	scanner, closeLog, err := openLog(path)
+	if err != nil {
+		return report, err
+	}
+	defer closeLog()
+
+	var prevLine []byte
+	var prevSeq int64
+	var prevTime time.Time
+	chained := false
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Bytes()
+		report.Records++
+		var record struct {
+			Time string `json:"time"`
+			Seq  int64  `json:"seq"`
+			Prev string `json:"prev"`
+		}
+		if err := json.Unmarshal(line, &record); err != nil {
+			report.Problems = append(report.Problems, fmt.Sprintf("line %d: not an audit record", n))
+		} else if record.Seq == 0 {
+			if chained {
+				report.Problems = append(report.Problems, fmt.Sprintf("line %d: record without a sequence number inside the chain", n))
+			} else {
+				report.Unchained++
+			}
+		} else {
+			if chained && record.Seq != prevSeq+1 {
+				report.Problems = append(report.Problems, fmt.Sprintf("line %d: sequence %d follows %d", n, record.Seq, prevSeq))
+			}
+			if prevLine != nil {
+				sum := sha256.Sum256(prevLine)
+				if record.Prev != hex.EncodeToString(sum[:]) {
+					report.Problems = append(report.Problems, fmt.Sprintf("line %d: previous line does not match its hash", n))
+				}
+			}
+			if t, err := time.Parse(time.RFC3339Nano, record.Time); err == nil {
+				if chained && t.Before(prevTime) {
+					report.ClockJumps++
+				}
+				prevTime = t
+			}
+			chained = true
+			prevSeq = record.Seq
+		}
+		prevLine = append(prevLine[:0], line...)
+	}
+	return report, scanner.Err()
+}
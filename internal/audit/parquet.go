@@ -0,0 +1,309 @@
+// File: internal/audit/parquet.go
+package audit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// WriteParquet writes the events as a Parquet file: one row group, one
+// uncompressed PLAIN-encoded page per column. time is a UTC timestamp in
+// milliseconds and seq an optional 64-bit integer; level and message are
+// required strings, the other columns optional strings that are null when the
+// event has no such attribute. This
+// is the subset of the format every Parquet reader supports, written without
+// pulling a Parquet library into the build.
+func WriteParquet(w io.Writer, events []Event) error {
+	var file bytes.Buffer
+	file.WriteString("PAR1")
+
+	var chunks []parquetChunk
+	times := make([][]byte, len(events))
+	seqs := make([][]byte, len(events))
+	for i, e := range events {
+		times[i] = binary.LittleEndian.AppendUint64(nil, uint64(e.Time.UnixMilli()))
+		if e.Seq != 0 {
+			seqs[i] = binary.LittleEndian.AppendUint64(nil, uint64(e.Seq))
+		}
+	}
+	chunks = append(chunks, writeParquetColumn(&file, Columns[0], parquetTimestampMillis, false, times))
+	chunks = append(chunks, writeParquetColumn(&file, Columns[1], parquetInteger, true, seqs))
+	for c, name := range Columns[2:] {
+		values := make([][]byte, len(events))
+		for i, e := range events {
+			if v := e.values()[c]; v != "" || c < 2 {
+				values[i] = binary.LittleEndian.AppendUint32(nil, uint32(len(v)))
+				values[i] = append(values[i], v...)
+			}
+		}
+		chunks = append(chunks, writeParquetColumn(&file, name, parquetUTF8, c >= 2, values))
+	}
+
+	footer := parquetFileMetaData(int64(len(events)), chunks)
+	file.Write(footer)
+	file.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer))))
+	file.WriteString("PAR1")
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// Parquet physical types and enum values used here (parquet.thrift)
+const (
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	parquetPlain = 0
+	parquetRLE   = 3
+
+	parquetRequired = 0
+	parquetOptional = 1
+
+	parquetUTF8            = 0
+	parquetTimestampMillis = 9
+	parquetInteger         = -1 // no converted type: a plain INT64
+)
+
+// parquetChunk is what the footer needs to know about a written column chunk
+type parquetChunk struct {
+	name       string
+	converted  int32
+	physical   int32
+	optional   bool
+	numValues  int64
+	offset     int64
+	totalBytes int64
+}
+
+// writeParquetColumn appends one data page holding the column, a UTF-8 string,
+// timestamp or integer column by its converted type. values are PLAIN-encoded;
+// nil marks a null of an optional column.
+func writeParquetColumn(file *bytes.Buffer, name string, converted int32, optional bool, values [][]byte) parquetChunk {
+	physical := int32(parquetInt64)
+	if converted == parquetUTF8 {
+		physical = parquetByteArray
+	}
+
+	var page bytes.Buffer
+	if optional {
+		// Definition levels: RLE runs of 1-bit values, prefixed by their length
+		var levels []byte
+		for i := 0; i < len(values); {
+			j := i
+			for j < len(values) && (values[j] == nil) == (values[i] == nil) {
+				j++
+			}
+			levels = binary.AppendUvarint(levels, uint64(j-i)<<1)
+			if values[i] == nil {
+				levels = append(levels, 0)
+			} else {
+				levels = append(levels, 1)
+			}
+			i = j
+		}
+		page.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(levels))))
+		page.Write(levels)
+	}
+	for _, v := range values {
+		page.Write(v)
+	}
+
+	var header thriftWriter
+	header.i32(1, 0) // DATA_PAGE
+	header.i32(2, int32(page.Len()))
+	header.i32(3, int32(page.Len()))
+	header.beginStruct(5) // DataPageHeader
+	header.i32(1, int32(len(values)))
+	header.i32(2, parquetPlain)
+	header.i32(3, parquetRLE)
+	header.i32(4, parquetRLE)
+	header.endStruct()
+	header.stop()
+
+	chunk := parquetChunk{
+		name:       name,
+		converted:  converted,
+		physical:   physical,
+		optional:   optional,
+		numValues:  int64(len(values)),
+		offset:     int64(file.Len()),
+		totalBytes: int64(header.buf.Len() + page.Len()),
+	}
+	file.Write(header.buf.Bytes())
+	file.Write(page.Bytes())
+	return chunk
+}
+
+// parquetFileMetaData encodes the footer describing the schema and row group
+func parquetFileMetaData(rows int64, chunks []parquetChunk) []byte {
+	var m thriftWriter
+	m.i32(1, 1) // version
+
+	m.beginList(2, thriftStruct, len(chunks)+1) // schema
+	m.binary(4, "audit_event")
+	m.i32(5, int32(len(chunks)))
+	m.stop()
+	for _, c := range chunks {
+		m.i32(1, c.physical)
+		if c.optional {
+			m.i32(3, parquetOptional)
+		} else {
+			m.i32(3, parquetRequired)
+		}
+		m.binary(4, c.name)
+		switch c.converted {
+		case parquetTimestampMillis:
+			m.i32(6, parquetTimestampMillis)
+			m.beginStruct(10) // LogicalType union
+			m.beginStruct(8)  // TIMESTAMP
+			m.boolean(1, true)
+			m.beginStruct(2) // unit
+			m.beginStruct(1) // MILLIS
+			m.endStruct()
+			m.endStruct()
+			m.endStruct()
+			m.endStruct()
+		case parquetUTF8:
+			m.i32(6, parquetUTF8)
+			m.beginStruct(10) // LogicalType union
+			m.beginStruct(1)  // STRING
+			m.endStruct()
+			m.endStruct()
+		}
+		m.stop()
+	}
+	m.endList()
+
+	m.i64(3, rows)
+
+	var totalBytes int64
+	for _, c := range chunks {
+		totalBytes += c.totalBytes
+	}
+	m.beginList(4, thriftStruct, 1) // row_groups
+	m.beginList(1, thriftStruct, len(chunks))
+	for _, c := range chunks {
+		m.i64(2, c.offset) // file_offset
+		m.beginStruct(3)   // ColumnMetaData
+		m.i32(1, c.physical)
+		m.beginList(2, thriftI32, 2)
+		m.rawI32(parquetPlain)
+		m.rawI32(parquetRLE)
+		m.endList()
+		m.beginList(3, thriftBinary, 1)
+		m.rawBinary(c.name)
+		m.endList()
+		m.i32(4, 0) // UNCOMPRESSED
+		m.i64(5, c.numValues)
+		m.i64(6, c.totalBytes)
+		m.i64(7, c.totalBytes)
+		m.i64(9, c.offset)
+		m.endStruct()
+		m.stop()
+	}
+	m.endList()
+	m.i64(2, totalBytes)
+	m.i64(3, rows)
+	m.stop()
+	m.endList()
+
+	m.binary(6, "vault.module")
+	m.stop()
+	return m.buf.Bytes()
+}
+
+// Thrift compact protocol types
+const (
+	thriftTrue   = 1
+	thriftFalse  = 2
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs in the Thrift compact protocol. Structs inside
+// lists are written field by field and closed with stop.
+type thriftWriter struct {
+	buf     bytes.Buffer
+	last    int16
+	parents []int16
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	if delta := id - t.last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.buf.Write(binary.AppendVarint(nil, int64(id)))
+	}
+	t.last = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.rawI32(v)
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.buf.Write(binary.AppendVarint(nil, v))
+}
+
+func (t *thriftWriter) boolean(id int16, v bool) {
+	if v {
+		t.field(id, thriftTrue)
+	} else {
+		t.field(id, thriftFalse)
+	}
+}
+
+func (t *thriftWriter) binary(id int16, v string) {
+	t.field(id, thriftBinary)
+	t.rawBinary(v)
+}
+
+func (t *thriftWriter) rawI32(v int32) {
+	t.buf.Write(binary.AppendVarint(nil, int64(v)))
+}
+
+func (t *thriftWriter) rawBinary(v string) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64(len(v))))
+	t.buf.WriteString(v)
+}
+
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.parents = append(t.parents, t.last)
+	t.last = 0
+}
+
+func (t *thriftWriter) endStruct() {
+	t.buf.WriteByte(0)
+	t.last = t.parents[len(t.parents)-1]
+	t.parents = t.parents[:len(t.parents)-1]
+}
+
+// stop ends a struct that is an element of a list
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+	t.last = 0
+}
+
+func (t *thriftWriter) beginList(id int16, elem byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elem)
+	} else {
+		t.buf.WriteByte(0xF0 | elem)
+		t.buf.Write(binary.AppendUvarint(nil, uint64(size)))
+	}
+	t.parents = append(t.parents, t.last)
+	t.last = 0
+}
+
+func (t *thriftWriter) endList() {
+	t.last = t.parents[len(t.parents)-1]
+	t.parents = t.parents[:len(t.parents)-1]
+}
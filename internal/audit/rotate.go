@@ -0,0 +1,251 @@
+// File: internal/audit/rotate.go
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"vault.module/internal/budget"
+	"vault.module/internal/filelock"
+)
+
+// A rotated log is archived next to audit.log as audit-<UTC time>.log.gz, or
+// audit-<UTC time>.log.gz.age when it is encrypted. audit.log itself is
+// truncated in place, under the same lock the handler appends with, and
+// starts over with a record that continues the sequence and hash chain of the
+// archived one: no process keeps writing to a renamed file, and `audit verify`
+// of the archive followed by audit.log covers the whole history.
+
+// RotationPolicy says when audit.log is rotated and how archives are kept.
+type RotationPolicy struct {
+	MaxSize        int64         // Rotate when the log is larger (0 = no size limit)
+	MaxAge         time.Duration // Rotate when the first record is older (0 = no age limit)
+	Keep           int           // Archives to keep, the oldest are removed (0 = all)
+	RecipientsFile string        // Optional: age recipients file archives are encrypted to
+	Recipients     []string      // Optional: more age recipients
+}
+
+// Encrypted reports whether archives are encrypted with age.
+func (p RotationPolicy) Encrypted() bool {
+	return p.RecipientsFile != "" || len(p.Recipients) > 0
+}
+
+// due reports whether a log of size bytes whose first record was written at
+// first must be rotated
+func (p RotationPolicy) due(size int64, first time.Time, now time.Time) bool {
+	if p.MaxSize > 0 && size > p.MaxSize {
+		return true
+	}
+	return p.MaxAge > 0 && !first.IsZero() && now.Sub(first) > p.MaxAge
+}
+
+// RotateIfDue rotates the audit log when the policy says so. It returns the
+// archive written, or "" when the log was left alone.
+func RotateIfDue(policy RotationPolicy) (string, error) {
+	if policy.MaxSize <= 0 && policy.MaxAge <= 0 {
+		return "", nil
+	}
+	return rotate(policy, false)
+}
+
+// Rotate archives the audit log now, whatever its size and age. It returns
+// the archive written, or "" when the log is empty.
+func Rotate(policy RotationPolicy) (string, error) {
+	return rotate(policy, true)
+}
+
+func rotate(policy RotationPolicy, force bool) (string, error) {
+	if chain == nil {
+		return "", fmt.Errorf("audit logger is not initialized")
+	}
+	f := chain.file
+	if err := filelock.Lock(f); err != nil {
+		return "", err
+	}
+	defer filelock.Unlock(f)
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if info.Size() == 0 {
+		return "", nil
+	}
+	data := make([]byte, info.Size())
+	if _, err := f.ReadAt(data, 0); err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	if !force && !policy.due(info.Size(), firstRecordTime(data), now) {
+		return "", nil
+	}
+
+	archive, err := writeArchive(filepath.Join(filepath.Dir(chain.path), "audit-"+now.Format("20060102T150405Z")+".log"), data, policy)
+	if err != nil {
+		return "", err
+	}
+
+	// The archive is on disk: start the log over, carrying the chain across
+	last := bytes.TrimRight(data, "\n")
+	if i := bytes.LastIndexByte(last, '\n'); i >= 0 {
+		last = last[i+1:]
+	}
+	if err := f.Truncate(0); err != nil {
+		return archive, err
+	}
+	// Still under the lock, so the log's first record is this one (flock is
+	// per open file: the handler's lock and unlock are the same lock)
+	chain.carry = append([]byte(nil), last...)
+	Logger.Info("Audit log rotated",
+		slog.String("archive", filepath.Base(archive)),
+		slog.Int64("size", info.Size()),
+		slog.Bool("encrypted", policy.Encrypted()))
+	chain.carry = nil
+
+	if policy.Keep > 0 {
+		if err := pruneArchives(filepath.Dir(chain.path), policy.Keep); err != nil {
+			return archive, err
+		}
+	}
+	return archive, nil
+}
+
+// writeArchive compresses data to base.gz, then encrypts it to base.gz.age if
+// the policy has recipients. The archive is synced before it is returned.
+func writeArchive(base string, data []byte, policy RotationPolicy) (string, error) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Name = filepath.Base(base)
+	if _, err := zw.Write(data); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+
+	path := base + ".gz"
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	if !policy.Encrypted() {
+		if _, err := out.Write(compressed.Bytes()); err != nil {
+			os.Remove(path)
+			return "", err
+		}
+	} else {
+		out.Close()
+		os.Remove(path)
+		path += ".age"
+		if out, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600); err != nil {
+			return "", err
+		}
+		defer out.Close()
+
+		args := []string{}
+		if policy.RecipientsFile != "" {
+			args = append(args, "-R", policy.RecipientsFile)
+		}
+		for _, r := range policy.Recipients {
+			args = append(args, "-r", r)
+		}
+		ctx, cancel := budget.WithTimeout(30 * time.Second)
+		defer cancel()
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "age", args...)
+		cmd.Stdin = &compressed
+		cmd.Stdout = out
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			os.Remove(path)
+			return "", fmt.Errorf("age failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+		}
+	}
+	if err := out.Sync(); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// firstRecordTime returns the time of the first record of a log, zero if it
+// has none
+func firstRecordTime(data []byte) time.Time {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	var record struct {
+		Time string `json:"time"`
+	}
+	if json.Unmarshal(line, &record) != nil {
+		return time.Time{}
+	}
+	t, _ := time.Parse(time.RFC3339Nano, record.Time)
+	return t
+}
+
+// Archives returns the rotated logs in dir, oldest first.
+func Archives(dir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "audit-*.log.gz*"))
+	if err != nil {
+		return nil, err
+	}
+	var archives []string
+	for _, m := range matches {
+		if strings.HasSuffix(m, ".log.gz") || strings.HasSuffix(m, ".log.gz.age") {
+			archives = append(archives, m)
+		}
+	}
+	// The UTC time in the name sorts chronologically
+	sort.Strings(archives)
+	return archives, nil
+}
+
+// pruneArchives removes the oldest archives in dir beyond keep
+func pruneArchives(dir string, keep int) error {
+	archives, err := Archives(dir)
+	if err != nil || len(archives) <= keep {
+		return err
+	}
+	for _, old := range archives[:len(archives)-keep] {
+		if err := os.Remove(old); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openLog opens an audit log for reading, decompressing rotated .gz archives.
+// Encrypted archives must be decrypted with age first.
+func openLog(path string) (*bufio.Scanner, func() error, error) {
+	if strings.HasSuffix(path, ".age") {
+		return nil, nil, fmt.Errorf("%s is encrypted; decrypt it first: age -d -i <identity> -o %s %s", filepath.Base(path), strings.TrimSuffix(filepath.Base(path), ".age"), filepath.Base(path))
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var scanner *bufio.Scanner
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		scanner = bufio.NewScanner(zr)
+	} else {
+		scanner = bufio.NewScanner(f)
+	}
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return scanner, f.Close, nil
+}
@@ -0,0 +1,213 @@
+// File: internal/auditstream/auditstream.go
+package auditstream
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"vault.module/internal/audit"
+)
+
+// ServiceName is the fully qualified gRPC service name, see audit.proto
+const ServiceName = "vaultmodule.audit.v1.AuditStream"
+
+// subscriberBuffer is the number of events buffered per subscriber; a subscriber
+// that falls further behind loses events rather than stalling the others
+const subscriberBuffer = 256
+
+// Server streams new audit log records to gRPC subscribers
+type Server struct {
+	path  string
+	token string
+
+	mu          sync.Mutex
+	subscribers map[chan *structpb.Struct]struct{}
+}
+
+// NewServer creates a server that follows the audit log at path. Subscribers must
+// send token as "authorization: Bearer <token>" metadata.
+func NewServer(path, token string) *Server {
+	return &Server{
+		path:        path,
+		token:       token,
+		subscribers: make(map[chan *structpb.Struct]struct{}),
+	}
+}
+
+// Register adds the AuditStream service to g
+func (s *Server) Register(g *grpc.Server) {
+	g.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*interface{})(nil),
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "Subscribe",
+			Handler:       subscribeHandler,
+			ServerStreams: true,
+		}},
+		Metadata: "audit.proto",
+	}, s)
+}
+
+// subscribeHandler implements rpc Subscribe(google.protobuf.Empty) returns (stream google.protobuf.Struct)
+func subscribeHandler(srv interface{}, stream grpc.ServerStream) error {
+	if err := stream.RecvMsg(new(emptypb.Empty)); err != nil {
+		return err
+	}
+	return srv.(*Server).subscribe(stream)
+}
+
+func (s *Server) subscribe(stream grpc.ServerStream) error {
+	if !s.authorized(stream.Context()) {
+		return status.Error(codes.Unauthenticated, "invalid or missing token")
+	}
+
+	ch := make(chan *structpb.Struct, subscriberBuffer)
+	s.mu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, ch)
+		s.mu.Unlock()
+	}()
+
+	audit.Logger.Info("Audit stream subscriber connected", slog.String("command", "audit-stream"))
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case ev := <-ch:
+			if err := stream.SendMsg(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) authorized(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	for _, v := range md.Get("authorization") {
+		token := strings.TrimPrefix(v, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// publish fans a record out to all subscribers without blocking
+func (s *Server) publish(ev *structpb.Struct) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subscribers {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// Follow tails the audit log from its current end and publishes every new record
+// until ctx is cancelled. Records written by other vault.module processes are
+// picked up as well, since they all append to the same file.
+func (s *Server) Follow(ctx context.Context) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	if err := watcher.Add(filepath.Dir(s.path)); err != nil {
+		return err
+	}
+
+	name := filepath.Clean(s.path)
+	var partial string
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			audit.Logger.Warn("Audit stream watcher error", slog.String("error", err.Error()))
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) != name {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				// The log was rotated or recreated; follow the new file from the start
+				if nf, err := os.Open(s.path); err == nil {
+					f.Close()
+					f, offset, partial = nf, 0, ""
+				}
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			if info, err := f.Stat(); err == nil && info.Size() < offset {
+				// Truncated
+				offset, partial = 0, ""
+			}
+			if _, err := f.Seek(offset, io.SeekStart); err != nil {
+				continue
+			}
+			reader := bufio.NewReader(f)
+			for {
+				line, err := reader.ReadString('\n')
+				offset += int64(len(line))
+				if err != nil {
+					// Keep an incomplete trailing line until the writer finishes it
+					partial += line
+					break
+				}
+				s.publish(toStruct(partial + strings.TrimRight(line, "\n")))
+				partial = ""
+			}
+		}
+	}
+}
+
+// toStruct converts a JSON audit record; anything else is wrapped as {"raw": line}
+func toStruct(line string) *structpb.Struct {
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &record); err == nil {
+		if st, err := structpb.NewStruct(record); err == nil {
+			return st
+		}
+	}
+	st, _ := structpb.NewStruct(map[string]interface{}{"raw": line})
+	return st
+}
@@ -0,0 +1,63 @@
+// File: internal/budget/budget.go
+package budget
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// The time budget of the running command, set once by --timeout. Operations
+// that can block (vault decryption, plugins, hook scripts, network calls)
+// derive their contexts from it, so they all stop when it runs out.
+var (
+	mu       sync.RWMutex
+	ctx      = context.Background()
+	cancel   context.CancelFunc
+	timeout  time.Duration
+	deadline time.Time
+)
+
+// Set starts a budget of d for the rest of the command. A zero or negative d
+// leaves the command unbounded.
+func Set(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	timeout = d
+	deadline = time.Now().Add(d)
+	ctx, cancel = context.WithDeadline(context.Background(), deadline)
+}
+
+// Context returns the context bounded by the command's budget, or
+// context.Background() when it has none
+func Context() context.Context {
+	mu.RLock()
+	defer mu.RUnlock()
+	return ctx
+}
+
+// WithTimeout bounds one operation by timeout and by the command's budget,
+// whichever ends first
+func WithTimeout(d time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(Context(), d)
+}
+
+// Timeout returns the command's budget, or 0 when it has none
+func Timeout() time.Duration {
+	mu.RLock()
+	defer mu.RUnlock()
+	return timeout
+}
+
+// Expired reports whether the command has a budget and it has run out
+func Expired() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return !deadline.IsZero() && !time.Now().Before(deadline)
+}
@@ -0,0 +1,198 @@
+// File: internal/canary/canary.go
+package canary
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"vault.module/internal/budget"
+)
+
+// requestTimeout bounds each call to an RPC or webhook endpoint
+const requestTimeout = 15 * time.Second
+
+// Activity is the on-chain state observed for a canary address.
+type Activity struct {
+	Address   string `json:"address"`
+	Nonce     uint64 `json:"nonce"`
+	Balance   string `json:"balance"`
+	Triggered bool   `json:"triggered"`
+}
+
+// Alert is the payload POSTed to the canary webhook.
+type Alert struct {
+	Vault     string    `json:"vault"`
+	Prefix    string    `json:"prefix"`
+	Address   string    `json:"address"`
+	Nonce     uint64    `json:"nonce"`
+	Balance   string    `json:"balance"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// CheckEVM queries an Ethereum JSON-RPC endpoint for the nonce and balance of address.
+// A fresh decoy has neither, so any sent transaction or received funds triggers the canary.
+func CheckEVM(rpcURL, address string) (Activity, error) {
+	activity := Activity{Address: address}
+
+	var nonceHex, balanceHex string
+	if err := evmCall(rpcURL, "eth_getTransactionCount", []interface{}{address, "latest"}, &nonceHex); err != nil {
+		return activity, err
+	}
+	if err := evmCall(rpcURL, "eth_getBalance", []interface{}{address, "latest"}, &balanceHex); err != nil {
+		return activity, err
+	}
+
+	nonce, ok := new(big.Int).SetString(strings.TrimPrefix(nonceHex, "0x"), 16)
+	if !ok {
+		return activity, fmt.Errorf("invalid nonce %q from RPC", nonceHex)
+	}
+	balance, ok := new(big.Int).SetString(strings.TrimPrefix(balanceHex, "0x"), 16)
+	if !ok {
+		return activity, fmt.Errorf("invalid balance %q from RPC", balanceHex)
+	}
+
+	activity.Nonce = nonce.Uint64()
+	activity.Balance = balance.String()
+	activity.Triggered = nonce.Sign() > 0 || balance.Sign() > 0
+	return activity, nil
+}
+
+// CheckCosmos queries a Cosmos SDK REST (LCD) endpoint for the account sequence and balances of address.
+func CheckCosmos(restURL, address string) (Activity, error) {
+	activity := Activity{Address: address, Balance: "0"}
+	base := strings.TrimRight(restURL, "/")
+
+	var balances struct {
+		Balances []struct {
+			Denom  string `json:"denom"`
+			Amount string `json:"amount"`
+		} `json:"balances"`
+	}
+	if _, err := getJSON(base+"/cosmos/bank/v1beta1/balances/"+address, &balances); err != nil {
+		return activity, err
+	}
+	parts := []string{}
+	for _, b := range balances.Balances {
+		if b.Amount != "" && b.Amount != "0" {
+			parts = append(parts, b.Amount+b.Denom)
+		}
+	}
+	if len(parts) > 0 {
+		activity.Balance = strings.Join(parts, ",")
+		activity.Triggered = true
+	}
+
+	// Accounts that never signed a transaction are unknown to x/auth or have sequence 0
+	var account struct {
+		Account struct {
+			Sequence string `json:"sequence"`
+		} `json:"account"`
+	}
+	status, err := getJSON(base+"/cosmos/auth/v1beta1/accounts/"+address, &account)
+	if err != nil && status != http.StatusNotFound {
+		return activity, err
+	}
+	if account.Account.Sequence != "" && account.Account.Sequence != "0" {
+		fmt.Sscan(account.Account.Sequence, &activity.Nonce)
+		activity.Triggered = true
+	}
+	return activity, nil
+}
+
+// SendAlert POSTs alert to webhookURL.
+func SendAlert(webhookURL string, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := budget.WithTimeout(requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// evmCall performs a single JSON-RPC call and decodes its result
+func evmCall(rpcURL, method string, params []interface{}, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := budget.WithTimeout(requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rpcURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var rpcResp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("invalid JSON-RPC response: %w", err)
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("%s failed: %s", method, rpcResp.Error.Message)
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// getJSON performs a GET and decodes a JSON body, returning the HTTP status
+func getJSON(url string, out interface{}) (int, error) {
+	ctx, cancel := budget.WithTimeout(requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("%s responded with %s", url, resp.Status)
+	}
+	return resp.StatusCode, json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
@@ -0,0 +1,139 @@
+// File: internal/ceremony/transcript.go
+package ceremony
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Version is the version of the transcript document
+const Version = 1
+
+// Steps of a ceremony, in the order they are recorded
+const (
+	StepOpened       = "opened"
+	StepEntropy      = "entropy"      // One per operator contributing dice rolls
+	StepGenerated    = "generated"    // The mnemonic was generated from every contribution
+	StepBackup       = "backup"       // One per backup copy written and checked
+	StepVerification = "verification" // One per operator confirming the wallet
+	StepStored       = "stored"
+	StepRecipient    = "recipient" // One per operator given access to the vault
+	StepClosed       = "closed"
+)
+
+// Transcript is the record of a key ceremony. Each step carries the hash of the
+// step before it, so no step can be changed, removed or reordered without
+// breaking the chain; the transcript as a whole is signed with the vault's
+// attestation key. It holds no secret.
+type Transcript struct {
+	Version    int      `json:"version"`
+	CeremonyID string   `json:"ceremony_id"`
+	Tool       string   `json:"tool"`
+	Vault      string   `json:"vault"`
+	Prefix     string   `json:"prefix"`
+	Operators  []string `json:"operators"`
+	Steps      []Step   `json:"steps"`
+}
+
+// Step is one recorded step of a ceremony
+type Step struct {
+	Number   int               `json:"number"`
+	Name     string            `json:"name"`
+	Operator string            `json:"operator,omitempty"` // Operator who performed the step
+	At       time.Time         `json:"at"`
+	Details  map[string]string `json:"details,omitempty"`
+	Previous string            `json:"previous"` // Hash of the step before, or of the header for the first step
+	Hash     string            `json:"hash"`
+}
+
+// New starts the transcript of a ceremony with a random ID
+func New(tool, vaultName, prefix string, operators []string) (*Transcript, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	return &Transcript{
+		Version:    Version,
+		CeremonyID: hex.EncodeToString(id),
+		Tool:       tool,
+		Vault:      vaultName,
+		Prefix:     prefix,
+		Operators:  operators,
+	}, nil
+}
+
+// Record appends a step to the transcript and returns it
+func (t *Transcript) Record(name, operator string, details map[string]string) Step {
+	previous := t.headerHash()
+	if len(t.Steps) > 0 {
+		previous = t.Steps[len(t.Steps)-1].Hash
+	}
+	step := Step{
+		Number:   len(t.Steps) + 1,
+		Name:     name,
+		Operator: operator,
+		At:       time.Now().UTC().Truncate(time.Second),
+		Details:  details,
+		Previous: previous,
+	}
+	step.Hash = step.hash()
+	t.Steps = append(t.Steps, step)
+	return step
+}
+
+// Verify checks the hash chain of the transcript
+func (t *Transcript) Verify() error {
+	if t.Version != Version {
+		return fmt.Errorf("unsupported transcript version %d", t.Version)
+	}
+	if len(t.Steps) == 0 || t.Steps[0].Name != StepOpened {
+		return fmt.Errorf("the transcript does not start with the opening of the ceremony")
+	}
+	previous := t.headerHash()
+	for i, step := range t.Steps {
+		if step.Number != i+1 {
+			return fmt.Errorf("step %d is numbered %d", i+1, step.Number)
+		}
+		if step.Previous != previous {
+			return fmt.Errorf("step %d (%s) does not follow the step before it", step.Number, step.Name)
+		}
+		if step.hash() != step.Hash {
+			return fmt.Errorf("step %d (%s) was modified", step.Number, step.Name)
+		}
+		previous = step.Hash
+	}
+	if last := t.Steps[len(t.Steps)-1]; last.Name != StepClosed {
+		return fmt.Errorf("the ceremony was not closed")
+	}
+	return nil
+}
+
+// headerHash binds the first step to the ceremony it belongs to
+func (t *Transcript) headerHash() string {
+	header := *t
+	header.Steps = nil
+	data, _ := json.Marshal(header)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hash is the SHA-256 of the step without its hash
+func (s Step) hash() string {
+	s.Hash = ""
+	data, _ := json.Marshal(s)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Commitment returns the SHA-256 of a contribution bound to the ceremony, to
+// record that a contribution was made without disclosing it
+func (t *Transcript) Commitment(contribution []byte) string {
+	h := sha256.New()
+	h.Write([]byte("vault.module ceremony " + t.CeremonyID + "\n"))
+	h.Write(contribution)
+	return hex.EncodeToString(h.Sum(nil))
+}
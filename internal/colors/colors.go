@@ -2,6 +2,8 @@ package colors
 
 import (
 	"os"
+	"strings"
+	"unicode"
 )
 
 // ANSI color codes
@@ -55,8 +57,59 @@ func White(text string) string {
 	return WhiteCode + text + ResetCode
 }
 
+// Output policy, set once at startup from --no-color and config.json
+var (
+	colorDisabled bool
+	asciiOnly     bool
+)
+
+// Disable turns colors off, for --no-color
+func Disable() {
+	colorDisabled = true
+}
+
+// SetASCIIOnly limits output to ASCII (ascii_only in config.json), for logging
+// pipelines and terminals that render emoji poorly
+func SetASCIIOnly(on bool) {
+	asciiOnly = on
+}
+
+// asciiReplacements spell out the symbols the tool prints
+var asciiReplacements = strings.NewReplacer(
+	"✓", "OK",
+	"✗", "X",
+	"💡", "Tip:",
+	"…", "...",
+	"→", "->",
+	"├──", "|--",
+	"└──", "`--",
+	"│", "|",
+)
+
+// Text applies the ASCII-only policy to text printed without SafeColor. Known
+// symbols are spelled out and other symbols and emoji are dropped; letters of
+// any script, e.g. in wallet notes, are kept.
+func Text(text string) string {
+	if !asciiOnly {
+		return text
+	}
+	text = asciiReplacements.Replace(text)
+	return strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		return -1
+	}, text)
+}
+
 // Check if terminal supports colors
 func SupportsColors() bool {
+	if colorDisabled {
+		return false
+	}
 	// Check NO_COLOR environment variable
 	if os.Getenv("NO_COLOR") != "" {
 		return false
@@ -73,6 +126,7 @@ func SupportsColors() bool {
 
 // Safe color output (disables colors if not supported)
 func SafeColor(text string, colorFunc func(string) string) string {
+	text = Text(text)
 	if SupportsColors() {
 		return colorFunc(text)
 	}
@@ -2,8 +2,15 @@
 package config
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/spf13/viper"
 	"vault.module/internal/errors"
@@ -11,20 +18,293 @@ import (
 
 // VaultDetails holds the paths and type for a single vault.
 type VaultDetails struct {
-	KeyFile        string `mapstructure:"keyfile"`
-	RecipientsFile string `mapstructure:"recipientsfile"`
-	Type           string `mapstructure:"type"`
-	Encryption     string `mapstructure:"encryption"` // <-- NEW FIELD
+	KeyFile            string                     `mapstructure:"keyfile"`
+	RecipientsFile     string                     `mapstructure:"recipientsfile"`
+	Type               string                     `mapstructure:"type"`
+	Encryption         string                     `mapstructure:"encryption"`                                               // <-- NEW FIELD
+	YubikeySerial      string                     `mapstructure:"yubikey_serial" json:"yubikey_serial,omitempty"`           // Optional: pin the vault to a specific YubiKey
+	YubikeySlot        string                     `mapstructure:"yubikey_slot" json:"yubikey_slot,omitempty"`               // Optional: overrides the global yubikeyslot
+	IdentityFile       string                     `mapstructure:"identityfile" json:"identityfile,omitempty"`               // Optional: age identity file for plugin backends (e.g. fido2)
+	TPMSealDir         string                     `mapstructure:"tpm_seal_dir" json:"tpm_seal_dir,omitempty"`               // TPM backend: directory holding the sealed identity blobs
+	TPMPCRs            string                     `mapstructure:"tpm_pcrs" json:"tpm_pcrs,omitempty"`                       // TPM backend: optional PCR policy, e.g. "sha256:0,7"
+	SecondFactor       string                     `mapstructure:"second_factor" json:"second_factor,omitempty"`             // Optional: "keyfile" or "passphrase" inner encryption layer
+	SecondFactorFile   string                     `mapstructure:"second_factor_file" json:"second_factor_file,omitempty"`   // Identity file for the "keyfile" second factor
+	ApprovalURL        string                     `mapstructure:"approval_url" json:"approval_url,omitempty"`               // Optional: endpoint that must approve every decryption
+	ApprovalTimeout    int                        `mapstructure:"approval_timeout" json:"approval_timeout,omitempty"`       // Seconds to wait for approval (default 300)
+	IntegritySignature string                     `mapstructure:"integrity_signature" json:"integrity_signature,omitempty"` // Signature by the vault-held integrity key
+	Inheritance        *Inheritance               `mapstructure:"inheritance" json:"inheritance,omitempty"`                 // Optional: inheritance package prepared for this vault
+	Operators          []string                   `mapstructure:"operators" json:"operators,omitempty"`                     // Organization mode: operators granted access to this vault
+	Defaults           *VaultDefaults             `mapstructure:"defaults" json:"defaults,omitempty"`                       // Optional: flag defaults for commands run on this vault
+	PublicTwin         string                     `mapstructure:"public_twin" json:"public_twin,omitempty"`                 // Optional: vault kept in sync with this vault's public data
+	Template           string                     `mapstructure:"template" json:"template,omitempty"`                       // Template the vault was created from
+	Tags               []string                   `mapstructure:"tags" json:"tags,omitempty"`                               // Optional: the only tags wallets of this vault may carry
+	PrefixPattern      string                     `mapstructure:"prefix_pattern" json:"prefix_pattern,omitempty"`           // Optional: regular expression wallet prefixes must match
+	PrefixMaxLength    int                        `mapstructure:"prefix_max_length" json:"prefix_max_length,omitempty"`     // Optional: longest wallet prefix allowed (default 32)
+	ReservedPrefixes   []string                   `mapstructure:"reserved_prefixes" json:"reserved_prefixes,omitempty"`     // Optional: prefixes reserved on top of the built-in ones
+	PrefixScheme       string                     `mapstructure:"prefix_scheme" json:"prefix_scheme,omitempty"`             // Optional: segments wallet prefixes are made of, e.g. "team-env-name"
+	Namespaces         map[string]NamespacePolicy `mapstructure:"namespaces" json:"namespaces,omitempty"`                   // Optional: naming policies of prefix namespaces, e.g. "team/alice"
+	PolicyFile         string                     `mapstructure:"policy_file" json:"policy_file,omitempty"`                 // Optional: the vault's written policy
+	AccessLog          int                        `mapstructure:"access_log" json:"access_log,omitempty"`                   // Optional: accesses kept per wallet inside the vault (0 = none)
+}
+
+// NamespacePolicy is the naming policy of the wallets in a prefix namespace
+// (team/alice for team/alice/eth-main). It applies to the names below the
+// namespace instead of the vault's own pattern, scheme and reserved prefixes.
+type NamespacePolicy struct {
+	PrefixPattern    string   `mapstructure:"prefix_pattern" json:"prefix_pattern,omitempty"`       // Optional: regular expression the names must match
+	PrefixMaxLength  int      `mapstructure:"prefix_max_length" json:"prefix_max_length,omitempty"` // Optional: longest name allowed below the namespace
+	ReservedPrefixes []string `mapstructure:"reserved_prefixes" json:"reserved_prefixes,omitempty"` // Optional: names reserved below the namespace
+	PrefixScheme     string   `mapstructure:"prefix_scheme" json:"prefix_scheme,omitempty"`         // Optional: segments the names are made of, e.g. "chain-env"
+}
+
+// VaultDefaults are flag values applied to commands run on a vault unless the
+// flag is given on the command line. Zero values leave the built-in default.
+type VaultDefaults struct {
+	Index            int    `mapstructure:"index" json:"index,omitempty"`                         // --index of get, exec, prove and provision
+	ClipboardTimeout int    `mapstructure:"clipboard_timeout" json:"clipboard_timeout,omitempty"` // --clipboard-timeout of get and secret get
+	Output           string `mapstructure:"output" json:"output,omitempty"`                       // "json" turns on --json of get and list; "text" is the default
+}
+
+// Inheritance records the inheritance package of a vault and the owner's check-ins.
+// Times are RFC 3339 in UTC.
+type Inheritance struct {
+	Package      string   `mapstructure:"package" json:"package"`                       // Encrypted export handed to the heirs
+	Instructions string   `mapstructure:"instructions" json:"instructions"`             // Instructions document next to the package
+	Recipients   []string `mapstructure:"recipients" json:"recipients"`                 // Heirs' age recipients
+	CreatedAt    string   `mapstructure:"created_at" json:"created_at"`                 // When the package was prepared
+	VaultDigest  string   `mapstructure:"vault_digest" json:"vault_digest"`             // SHA-256 of the vault key file when prepared
+	UnlockAfter  string   `mapstructure:"unlock_after" json:"unlock_after,omitempty"`   // Optional: time lock date (drand tlock)
+	CheckInDays  int      `mapstructure:"check_in_days" json:"check_in_days,omitempty"` // Days between owner check-ins (0 = no check-ins)
+	LastCheckIn  string   `mapstructure:"last_check_in" json:"last_check_in,omitempty"` // Last owner check-in
+}
+
+// NameForKeyFile returns the configured name of the vault stored in keyFile, or
+// the file's base name if no vault points at it.
+func NameForKeyFile(keyFile string) string {
+	for name, details := range Cfg.Vaults {
+		if details.KeyFile == keyFile {
+			return name
+		}
+	}
+	return filepath.Base(keyFile)
+}
+
+// ResolveYubikeySlot returns the PIV slot to use for this vault.
+// The per-vault slot takes precedence over the global setting.
+func (d VaultDetails) ResolveYubikeySlot() string {
+	if d.YubikeySlot != "" {
+		return d.YubikeySlot
+	}
+	return Cfg.YubikeySlot
+}
+
+// Operator is a team member with their own age identity. Vaults list the operators
+// they are encrypted to; each machine decrypts with the identity of its operator.
+type Operator struct {
+	Name         string `mapstructure:"name" json:"name"`
+	Recipient    string `mapstructure:"recipient" json:"recipient"`                 // age recipient the vaults are encrypted to
+	IdentityFile string `mapstructure:"identityfile" json:"identityfile,omitempty"` // age identity (or plugin identity) file, on the operator's machine only
+}
+
+// FindOperator returns the configured operator with the given name
+func FindOperator(name string) (Operator, bool) {
+	for _, op := range Cfg.Operators {
+		if op.Name == name {
+			return op, true
+		}
+	}
+	return Operator{}, false
+}
+
+// CurrentOperator returns the operator this machine acts as, set by "operator" in
+// config.json or VAULT_OPERATOR. It reports false outside organization mode.
+func CurrentOperator() (Operator, bool) {
+	if Cfg.Operator == "" {
+		return Operator{}, false
+	}
+	return FindOperator(Cfg.Operator)
+}
+
+// HasOperator reports whether the operator is granted access to the vault
+func (d VaultDetails) HasOperator(name string) bool {
+	for _, n := range d.Operators {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditRotation says when audit.log is rotated into compressed archives and
+// whose recipients the archives are encrypted to.
+type AuditRotation struct {
+	MaxSizeMB    int    `mapstructure:"max_size_mb" json:"max_size_mb,omitempty"`     // Rotate when the log is larger (0 = no size limit)
+	MaxAgeDays   int    `mapstructure:"max_age_days" json:"max_age_days,omitempty"`   // Rotate when the first record is older (0 = no age limit)
+	Keep         int    `mapstructure:"keep" json:"keep,omitempty"`                   // Archives to keep, the oldest are removed (0 = all)
+	EncryptVault string `mapstructure:"encrypt_vault" json:"encrypt_vault,omitempty"` // Optional: vault whose recipients archives are encrypted to
+}
+
+// PendingDeletion is a deletion scheduled with a cooling-off period. Running the
+// delete again once DueAt has passed carries it out; until then it can be cancelled.
+type PendingDeletion struct {
+	Vault       string `mapstructure:"vault" json:"vault"`
+	Prefix      string `mapstructure:"prefix" json:"prefix,omitempty"` // Wallet to delete; empty for the whole vault
+	RequestedAt string `mapstructure:"requested_at" json:"requested_at"`
+	DueAt       string `mapstructure:"due_at" json:"due_at"`
+}
+
+// TrustedExporter is a vault whose signed exports import --verify-signature
+// accepts. Vaults of this installation are trusted when they first sign an export.
+type TrustedExporter struct {
+	Name      string `mapstructure:"name" json:"name"`
+	VaultID   string `mapstructure:"vault_id" json:"vault_id"`
+	PublicKey string `mapstructure:"public_key" json:"public_key"` // Base64 Ed25519 attestation key
+}
+
+// FindTrustedExporter returns the trusted vault holding the attestation key publicKey
+func FindTrustedExporter(publicKey string) (TrustedExporter, bool) {
+	for _, t := range Cfg.TrustedExporters {
+		if t.PublicKey == publicKey {
+			return t, true
+		}
+	}
+	return TrustedExporter{}, false
+}
+
+// SigningPolicy auto-approves queued signing requests of one programmatic client.
+// A request is approved when its data type and chain ID are both listed.
+type SigningPolicy struct {
+	Client    string   `mapstructure:"client" json:"client"`
+	DataTypes []string `mapstructure:"data_types" json:"data_types"` // transaction, typed-transaction, typed-data, personal-message
+	ChainIDs  []int64  `mapstructure:"chain_ids" json:"chain_ids"`
+}
+
+// ClientToken authenticates a programmatic client for its signing policies.
+// Only the SHA-256 of the token is stored.
+type ClientToken struct {
+	Client    string `mapstructure:"client" json:"client"`
+	TokenHash string `mapstructure:"token_hash" json:"token_hash"` // Hex SHA-256 of the token
+	CreatedAt string `mapstructure:"created_at" json:"created_at"`
+}
+
+// HashClientToken returns the form of a client token stored in config.json
+func HashClientToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// AuthenticateClient returns the client a token was issued to
+func AuthenticateClient(token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+	hash := []byte(HashClientToken(token))
+	client, found := "", false
+	for _, t := range Cfg.ClientTokens {
+		if subtle.ConstantTimeCompare(hash, []byte(t.TokenHash)) == 1 && !found {
+			client, found = t.Client, true
+		}
+	}
+	return client, found
+}
+
+// AutoApproves reports whether a policy of client approves the request without
+// review. client must have been authenticated by AuthenticateClient: a name a
+// caller merely claims approves nothing.
+func AutoApproves(client, dataType string, chainID int64) bool {
+	if client == "" {
+		return false
+	}
+	for _, p := range Cfg.SigningPolicies {
+		if p.Client != client {
+			continue
+		}
+		typeOK, chainOK := false, false
+		for _, t := range p.DataTypes {
+			typeOK = typeOK || t == dataType
+		}
+		for _, id := range p.ChainIDs {
+			chainOK = chainOK || id == chainID
+		}
+		if typeOK && chainOK {
+			return true
+		}
+	}
+	return false
+}
+
+// Canary describes a decoy wallet whose address is monitored for activity.
+// Canaries are recorded here rather than in the vault so a decrypted vault does not reveal them.
+type Canary struct {
+	Vault     string `mapstructure:"vault" json:"vault"`
+	Prefix    string `mapstructure:"prefix" json:"prefix"`
+	Address   string `mapstructure:"address" json:"address"`
+	Type      string `mapstructure:"type" json:"type"`
+	CreatedAt string `mapstructure:"created_at" json:"created_at"`
+}
+
+// Webhook receives a signed notification when a vault is modified.
+type Webhook struct {
+	URL    string   `mapstructure:"url" json:"url"`
+	Secret string   `mapstructure:"secret" json:"secret,omitempty"` // HMAC-SHA256 key for the X-Vault-Signature header
+	Events []string `mapstructure:"events" json:"events,omitempty"` // Events to deliver; empty means all
+}
+
+// Hook runs a script at a hook point of the tool (see internal/hooks). The
+// script gets the event as JSON on stdin, never a secret.
+type Hook struct {
+	Point   string   `mapstructure:"point" json:"point"`               // "pre-save", "post-import" or "post-get-secret"
+	Command string   `mapstructure:"command" json:"command"`           // Script to run, without a shell
+	Args    []string `mapstructure:"args" json:"args,omitempty"`       // Optional: arguments of the script
+	Timeout int      `mapstructure:"timeout" json:"timeout,omitempty"` // Seconds the script may run (default 30)
+	Vaults  []string `mapstructure:"vaults" json:"vaults,omitempty"`   // Optional: vaults the hook runs for; empty means all
 }
 
 // Config defines the new structure of the configuration file.
 type Config struct {
-	AuthToken           string                  `mapstructure:"authtoken"`
-	YubikeySlot         string                  `mapstructure:"yubikeyslot"`
-	YubikeyTimeout      int                     `mapstructure:"yubikey_timeout"`    // Timeout in seconds for YubiKey operations
-	ActiveVault         string                  `mapstructure:"active_vault"`
-	ClipboardTimeout    int                     `mapstructure:"clipboard_timeout"`    // Timeout in seconds for clipboard clearing
-	Vaults              map[string]VaultDetails `mapstructure:"vaults"`
+	AuthToken                  string                  `mapstructure:"authtoken"`
+	YubikeySlot                string                  `mapstructure:"yubikeyslot"`
+	YubikeyTimeout             int                     `mapstructure:"yubikey_timeout"` // Timeout in seconds for YubiKey operations
+	ActiveVault                string                  `mapstructure:"active_vault"`
+	ClipboardTimeout           int                     `mapstructure:"clipboard_timeout"`        // Timeout in seconds for clipboard clearing
+	SecretRateLimitGlobal      int                     `mapstructure:"secret_rate_limit_global"` // Max secret retrievals per hour across all wallets (0 = unlimited)
+	SecretRateLimitWallet      int                     `mapstructure:"secret_rate_limit_wallet"` // Max secret retrievals per hour for a single wallet (0 = unlimited)
+	ConsensusKeyRateLimit      int                     `mapstructure:"consensus_key_rate_limit"` // Max retrievals per hour of one consensus key (0 = default of 2)
+	Vaults                     map[string]VaultDetails `mapstructure:"vaults"`
+	Canaries                   []Canary                `mapstructure:"canaries"`
+	CanaryEVMRPC               string                  `mapstructure:"canary_evm_rpc"`               // JSON-RPC endpoint used to monitor EVM canaries
+	CanaryCosmosREST           string                  `mapstructure:"canary_cosmos_rest"`           // REST (LCD) endpoint used to monitor Cosmos canaries
+	CanaryWebhook              string                  `mapstructure:"canary_webhook"`               // Optional: receives a POST when a canary shows activity
+	ScreenCaptureProcesses     []string                `mapstructure:"screen_capture_processes"`     // Process names that trigger the screen-sharing warning
+	MemoryProtection           string                  `mapstructure:"memory_protection"`            // Unencrypted swap/hibernation: "warn" (default), "strict" or "off"
+	Strict                     bool                    `mapstructure:"strict"`                       // Enables the most conservative settings across all subsystems
+	Webhooks                   []Webhook               `mapstructure:"webhooks"`                     // Notified on wallet add/delete/import/rename
+	NoEchoSecrets              bool                    `mapstructure:"no_echo_secrets"`              // Refuse to print secrets to the terminal (session recording)
+	ChecklistItems             []string                `mapstructure:"checklist_items"`              // Steps of new cold-storage checklists (default: built-in list)
+	Operators                  []Operator              `mapstructure:"operators"`                    // Organization mode: the team's operators
+	Operator                   string                  `mapstructure:"operator"`                     // Operator this machine acts as (VAULT_OPERATOR overrides)
+	SigningQueue               bool                    `mapstructure:"signing_queue"`                // Queue programmatic signing requests for human approval
+	SigningPolicies            []SigningPolicy         `mapstructure:"signing_policies"`             // Per-client auto-approval of queued signing requests
+	ClientTokens               []ClientToken           `mapstructure:"client_tokens"`                // Hashed tokens authenticating the clients of signing_policies
+	Aliases                    map[string]string       `mapstructure:"aliases"`                      // User-defined commands, e.g. "pk": "get {} privatekey"
+	EntropySources             []string                `mapstructure:"entropy_sources"`              // Extra entropy mixed into key generation: "hwrng", "yubikey"
+	DiscoveryGap               int                     `mapstructure:"discovery_gap"`                // Consecutive unused addresses after which discover stops
+	AuditRotation              *AuditRotation          `mapstructure:"audit_rotation"`               // Optional: rotation, compression and encryption of audit.log
+	DeleteCoolingOffHours      int                     `mapstructure:"delete_cooling_off_hours"`     // Hours a scheduled deletion waits before it can be completed (0 = none)
+	PendingDeletions           []PendingDeletion       `mapstructure:"pending_deletions"`            // Deletions waiting out the cooling-off period
+	TrustedExporters           []TrustedExporter       `mapstructure:"trusted_exporters"`            // Vaults whose signed exports are accepted by import --verify-signature
+	RevealIdleLock             int                     `mapstructure:"reveal_idle_lock"`             // Seconds without a key press after which a revealed secret is locked
+	TourSeen                   bool                    `mapstructure:"tour_seen"`                    // The onboarding tour was shown or skipped; false shows it again
+	TimeFormat                 string                  `mapstructure:"time_format"`                  // Times in human output: "relative" (default), "local" or "utc"
+	ASCIIOnly                  bool                    `mapstructure:"ascii_only"`                   // Print no emoji or other non-ASCII symbols (VAULT_ASCII_ONLY)
+	Hooks                      []Hook                  `mapstructure:"hooks"`                        // Scripts run before saves and after imports and secret retrievals
+	SimulationRPC              map[string]string       `mapstructure:"simulation_rpc"`               // JSON-RPC endpoint per chain ID that previews transactions before signing
+	PassphraseEstimator        string                  `mapstructure:"passphrase_estimator"`         // Strength estimator of new passphrases: "zxcvbn" (default), "length" or "command"
+	PassphraseEstimatorCommand string                  `mapstructure:"passphrase_estimator_command"` // Program run by the "command" estimator
+	UsageStats                 bool                    `mapstructure:"usage_stats"`                  // Opt-in: count commands and flags in usage-stats.json; never transmitted
+	LintRules                  map[string]string       `mapstructure:"lint_rules"`                   // Severities of 'lint' rules by ID: "off", "info", "warning" or "error"
+	LintStaleDays              int                     `mapstructure:"lint_stale_days"`              // Days without change or use after which 'lint' reports a wallet as stale
+	MemoryBudgetMB             int                     `mapstructure:"memory_budget_mb"`             // Estimated memory a vault may take once decrypted; 0 for no budget
 }
 
 // Cfg is a global variable that holds the loaded configuration.
@@ -48,6 +328,60 @@ func GetActiveVault() (VaultDetails, error) {
 	return activeVault, nil
 }
 
+// savedActiveVault holds the active vault of config.json while OverrideActiveVault
+// selects another vault for the current invocation
+var savedActiveVault *string
+
+// OverrideActiveVault makes name the active vault for this invocation only.
+// SaveConfig keeps writing the active vault of config.json.
+func OverrideActiveVault(name string) error {
+	if _, ok := Cfg.Vaults[name]; !ok {
+		return errors.NewVaultNotFoundError(name)
+	}
+	if savedActiveVault == nil {
+		saved := Cfg.ActiveVault
+		savedActiveVault = &saved
+	}
+	Cfg.ActiveVault = name
+	return nil
+}
+
+// PersistedActiveVault returns the active vault recorded in config.json,
+// regardless of an override
+func PersistedActiveVault() string {
+	if savedActiveVault != nil {
+		return *savedActiveVault
+	}
+	return Cfg.ActiveVault
+}
+
+// SetActiveVault changes the active vault recorded in config.json. An override
+// for this invocation is replaced as well.
+func SetActiveVault(name string) {
+	Cfg.ActiveVault = name
+	savedActiveVault = nil
+}
+
+// savedOperator holds the operator of config.json while VAULT_OPERATOR selects
+// another operator for the current invocation
+var savedOperator *string
+
+// PersistedOperator returns the operator recorded in config.json, regardless
+// of VAULT_OPERATOR
+func PersistedOperator() string {
+	if savedOperator != nil {
+		return *savedOperator
+	}
+	return Cfg.Operator
+}
+
+// SetOperator changes the operator recorded in config.json. VAULT_OPERATOR no
+// longer applies to this invocation.
+func SetOperator(name string) {
+	Cfg.Operator = name
+	savedOperator = nil
+}
+
 // LoadConfig loads the configuration from a file and environment variables.
 func LoadConfig() error {
 	viper.SetDefault("authtoken", "")
@@ -55,21 +389,156 @@ func LoadConfig() error {
 	viper.SetDefault("yubikey_timeout", 60) // Default 60 seconds for YubiKey operations
 	viper.SetDefault("active_vault", "")
 	viper.SetDefault("clipboard_timeout", 30) // Default 30 seconds
+	viper.SetDefault("reveal_idle_lock", 30)
+	viper.SetDefault("secret_rate_limit_global", 0)
+	viper.SetDefault("secret_rate_limit_wallet", 0)
 	viper.SetDefault("vaults", map[string]VaultDetails{})
-	viper.SetConfigName("config")
+	viper.SetDefault("canaries", []Canary{})
+	viper.SetDefault("canary_evm_rpc", "")
+	viper.SetDefault("canary_cosmos_rest", "")
+	viper.SetDefault("canary_webhook", "")
+	viper.SetDefault("screen_capture_processes", []string{})
+	viper.SetDefault("memory_protection", "warn")
+	viper.SetDefault("strict", false)
+	viper.SetDefault("webhooks", []Webhook{})
+	viper.SetDefault("no_echo_secrets", false)
+	viper.SetDefault("checklist_items", []string{})
+	viper.SetDefault("operators", []Operator{})
+	viper.SetDefault("operator", "")
+	viper.SetDefault("signing_queue", false)
+	viper.SetDefault("signing_policies", []SigningPolicy{})
+	viper.SetDefault("client_tokens", []ClientToken{})
+	viper.SetDefault("aliases", map[string]string{})
+	viper.SetDefault("entropy_sources", []string{})
+	viper.SetDefault("discovery_gap", 20)
+	viper.SetDefault("delete_cooling_off_hours", 0)
+	viper.SetDefault("pending_deletions", []PendingDeletion{})
+	viper.SetDefault("trusted_exporters", []TrustedExporter{})
+	viper.SetDefault("tour_seen", false)
+	viper.SetDefault("time_format", "relative")
+	viper.SetDefault("ascii_only", false)
+	viper.SetDefault("hooks", []Hook{})
+	viper.SetDefault("simulation_rpc", map[string]string{})
+	viper.SetDefault("passphrase_estimator", "zxcvbn")
+	viper.SetDefault("passphrase_estimator_command", "")
+	viper.SetDefault("usage_stats", false)
+	viper.SetDefault("lint_rules", map[string]string{})
+	viper.SetDefault("lint_stale_days", 365)
+	viper.SetDefault("memory_budget_mb", 256)
 	viper.SetConfigType("json")
-	viper.AddConfigPath(".")
 	viper.SetEnvPrefix("VAULT")
 	viper.AutomaticEnv()
 	_ = viper.BindEnv("authtoken", "VAULT_AUTH_TOKEN")
 	_ = viper.BindEnv("yubikeyslot", "VAULT_YUBIKEY_SLOT")
 	_ = viper.BindEnv("yubikey_timeout", "VAULT_YUBIKEY_TIMEOUT")
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return errors.NewConfigLoadError("config.json", err)
+
+	// The file is read once so that SaveConfig can tell whether it changed since
+	data, err := os.ReadFile(ConfigFile)
+	switch {
+	case os.IsNotExist(err):
+		loadedDigest = nil
+	case err != nil:
+		return errors.NewConfigLoadError(ConfigFile, err)
+	default:
+		if err := viper.ReadConfig(bytes.NewReader(data)); err != nil {
+			return errors.NewConfigLoadError(ConfigFile, err)
+		}
+		loadedDigest = digestOf(data)
+	}
+	if err := viper.Unmarshal(&Cfg); err != nil {
+		return err
+	}
+
+	// VAULT_OPERATOR applies to this invocation only: SaveConfig keeps writing
+	// the operator of config.json
+	savedOperator = nil
+	if _, ok := os.LookupEnv("VAULT_OPERATOR"); ok {
+		var file struct {
+			Operator string `json:"operator"`
+		}
+		_ = json.Unmarshal(data, &file)
+		savedOperator = &file.Operator
+	}
+	return nil
+}
+
+// Secret retrieval limits applied by the strict profile when none are configured
+const (
+	strictRateLimitGlobal = 10
+	strictRateLimitWallet = 3
+)
+
+// SecretRateLimits returns the effective per-hour secret retrieval limits.
+// The strict profile turns limits on even if they are not configured.
+func SecretRateLimits() (global, wallet int) {
+	global, wallet = Cfg.SecretRateLimitGlobal, Cfg.SecretRateLimitWallet
+	if Cfg.Strict {
+		if global <= 0 {
+			global = strictRateLimitGlobal
+		}
+		if wallet <= 0 {
+			wallet = strictRateLimitWallet
 		}
 	}
-	return viper.Unmarshal(&Cfg)
+	return global, wallet
+}
+
+// defaultConsensusKeyRateLimit applies to consensus keys when none is configured
+const defaultConsensusKeyRateLimit = 2
+
+// ConsensusKeyRateLimit returns the per-hour retrieval limit of a consensus key.
+// It always applies: a consensus key in two places means double signing.
+func ConsensusKeyRateLimit() int {
+	if Cfg.ConsensusKeyRateLimit <= 0 {
+		return defaultConsensusKeyRateLimit
+	}
+	return Cfg.ConsensusKeyRateLimit
+}
+
+// Cooling-off period of deletions under the strict profile when none is configured
+const strictDeleteCoolingOffHours = 24
+
+// DeleteCoolingOff returns how long a deletion waits between being scheduled
+// and being carried out. The strict profile turns the wait on even if it is not configured.
+func DeleteCoolingOff() time.Duration {
+	hours := Cfg.DeleteCoolingOffHours
+	if Cfg.Strict && hours <= 0 {
+		hours = strictDeleteCoolingOffHours
+	}
+	if hours < 0 {
+		hours = 0
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// FindPendingDeletion returns the index of the deletion scheduled for a wallet
+// of a vault (prefix "" for the vault itself), or -1.
+func FindPendingDeletion(vaultName, prefix string) int {
+	for i, p := range Cfg.PendingDeletions {
+		if p.Vault == vaultName && p.Prefix == prefix {
+			return i
+		}
+	}
+	return -1
+}
+
+// RemovePendingDeletion drops the deletion scheduled for a wallet of a vault
+// (prefix "" for the vault itself). It reports whether there was one.
+func RemovePendingDeletion(vaultName, prefix string) bool {
+	i := FindPendingDeletion(vaultName, prefix)
+	if i < 0 {
+		return false
+	}
+	Cfg.PendingDeletions = append(Cfg.PendingDeletions[:i], Cfg.PendingDeletions[i+1:]...)
+	return true
+}
+
+// MemoryProtectionMode returns the effective swap/hibernation policy; strict forces "strict".
+func MemoryProtectionMode() string {
+	if Cfg.Strict {
+		return "strict"
+	}
+	return Cfg.MemoryProtection
 }
 
 // GetClipboardTimeout returns the clipboard timeout value from configuration.
@@ -81,19 +550,60 @@ func GetClipboardTimeout() int {
 	return Cfg.ClipboardTimeout
 }
 
-// SaveConfig saves the current configuration to a file.
+// GetRevealIdleLock returns how long a secret revealed on screen stays visible
+// without a key press. If not set or invalid, returns the default of 30 seconds.
+func GetRevealIdleLock() int {
+	if Cfg.RevealIdleLock <= 0 {
+		return 30
+	}
+	return Cfg.RevealIdleLock
+}
+
+// SaveConfig saves the current configuration to config.json. The write is atomic
+// and serialized with other processes, and fails if the file changed since it was loaded.
 func SaveConfig() error {
 	viper.Set("authtoken", Cfg.AuthToken)
 	viper.Set("yubikeyslot", Cfg.YubikeySlot)
 	viper.Set("yubikey_timeout", Cfg.YubikeyTimeout)
-	viper.Set("active_vault", Cfg.ActiveVault)
+	viper.Set("active_vault", PersistedActiveVault())
 	viper.Set("clipboard_timeout", Cfg.ClipboardTimeout)
+	viper.Set("reveal_idle_lock", Cfg.RevealIdleLock)
+	viper.Set("tour_seen", Cfg.TourSeen)
+	viper.Set("time_format", Cfg.TimeFormat)
+	viper.Set("ascii_only", Cfg.ASCIIOnly)
+	viper.Set("secret_rate_limit_global", Cfg.SecretRateLimitGlobal)
+	viper.Set("secret_rate_limit_wallet", Cfg.SecretRateLimitWallet)
+	viper.Set("consensus_key_rate_limit", Cfg.ConsensusKeyRateLimit)
 	viper.Set("vaults", Cfg.Vaults)
-	if err := os.MkdirAll(".", 0700); err != nil {
-		return errors.FromOSError(err, ".")
-	}
-	if err := viper.WriteConfigAs("config.json"); err != nil {
-		return errors.NewConfigSaveError("config.json", err)
-	}
-	return nil
+	viper.Set("canaries", Cfg.Canaries)
+	viper.Set("canary_evm_rpc", Cfg.CanaryEVMRPC)
+	viper.Set("canary_cosmos_rest", Cfg.CanaryCosmosREST)
+	viper.Set("canary_webhook", Cfg.CanaryWebhook)
+	viper.Set("screen_capture_processes", Cfg.ScreenCaptureProcesses)
+	viper.Set("memory_protection", Cfg.MemoryProtection)
+	viper.Set("strict", Cfg.Strict)
+	viper.Set("webhooks", Cfg.Webhooks)
+	viper.Set("no_echo_secrets", Cfg.NoEchoSecrets)
+	viper.Set("checklist_items", Cfg.ChecklistItems)
+	viper.Set("operators", Cfg.Operators)
+	viper.Set("operator", PersistedOperator())
+	viper.Set("signing_queue", Cfg.SigningQueue)
+	viper.Set("signing_policies", Cfg.SigningPolicies)
+	viper.Set("client_tokens", Cfg.ClientTokens)
+	viper.Set("aliases", Cfg.Aliases)
+	viper.Set("entropy_sources", Cfg.EntropySources)
+	viper.Set("discovery_gap", Cfg.DiscoveryGap)
+	viper.Set("audit_rotation", Cfg.AuditRotation)
+	viper.Set("delete_cooling_off_hours", Cfg.DeleteCoolingOffHours)
+	viper.Set("pending_deletions", Cfg.PendingDeletions)
+	viper.Set("trusted_exporters", Cfg.TrustedExporters)
+	viper.Set("hooks", Cfg.Hooks)
+	viper.Set("simulation_rpc", Cfg.SimulationRPC)
+	viper.Set("passphrase_estimator", Cfg.PassphraseEstimator)
+	viper.Set("passphrase_estimator_command", Cfg.PassphraseEstimatorCommand)
+	viper.Set("usage_stats", Cfg.UsageStats)
+	viper.Set("lint_rules", Cfg.LintRules)
+	viper.Set("lint_stale_days", Cfg.LintStaleDays)
+	viper.Set("memory_budget_mb", Cfg.MemoryBudgetMB)
+	return writeConfigLocked(viper.AllSettings())
 }
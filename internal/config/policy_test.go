@@ -0,0 +1,29 @@
+// File: internal/config/policy_test.go
+package config
+
+import "testing"
+
+// TestAutoApprovesAuthenticatedClient checks that signing policies apply to the
+// client a token was issued to, not to a name a caller claims
+func TestAutoApprovesAuthenticatedClient(t *testing.T) {
+	orig := Cfg
+	defer func() { Cfg = orig }()
+	Cfg.SigningPolicies = []SigningPolicy{{Client: "ci-bot", DataTypes: []string{"personal-message"}, ChainIDs: []int64{11155111}}}
+	Cfg.ClientTokens = []ClientToken{{Client: "ci-bot", TokenHash: HashClientToken("ci-token")}}
+
+	client, ok := AuthenticateClient("ci-token")
+	if !ok || client != "ci-bot" {
+		t.Fatalf("AuthenticateClient(issued token) = %q, %v, want ci-bot, true", client, ok)
+	}
+	if !AutoApproves(client, "personal-message", 11155111) {
+		t.Fatal("the policy of the authenticated client does not approve its request")
+	}
+	for _, token := range []string{"", "ci-bot", HashClientToken("ci-token")} {
+		if client, ok := AuthenticateClient(token); ok {
+			t.Fatalf("AuthenticateClient(%q) = %q, want no client", token, client)
+		}
+	}
+	if AutoApproves("", "personal-message", 11155111) {
+		t.Fatal("a request of no client was approved")
+	}
+}
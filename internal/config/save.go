@@ -0,0 +1,108 @@
+// File: internal/config/save.go
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"vault.module/internal/errors"
+	"vault.module/internal/filelock"
+)
+
+// ConfigFile is the configuration file in the working directory
+const ConfigFile = "config.json"
+
+// configLockTimeout bounds how long SaveConfig waits for another process's save
+const configLockTimeout = 5 * time.Second
+
+// loadedDigest is the SHA-256 of config.json as read by LoadConfig, or nil if
+// the file did not exist
+var loadedDigest []byte
+
+func digestOf(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+// lockConfig takes an exclusive flock on config.json.lock, waiting up to
+// configLockTimeout. The lock file is kept: removing it would let two processes
+// lock different inodes.
+func lockConfig() (*os.File, error) {
+	lockFile, err := os.OpenFile(ConfigFile+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, errors.NewFileSystemError("open", ConfigFile+".lock", err)
+	}
+	deadline := time.Now().Add(configLockTimeout)
+	for {
+		err := filelock.TryLock(lockFile)
+		if err == nil {
+			return lockFile, nil
+		}
+		if err != filelock.ErrLocked || time.Now().After(deadline) {
+			lockFile.Close()
+			return nil, errors.NewConfigSaveError(ConfigFile, err).WithDetails("config.json is locked by another process")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// writeConfigLocked writes settings to config.json under the lock, through a
+// synced temporary file renamed over the original
+func writeConfigLocked(settings map[string]interface{}) error {
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return errors.NewConfigSaveError(ConfigFile, err)
+	}
+
+	lockFile, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		filelock.Unlock(lockFile)
+		lockFile.Close()
+	}()
+
+	current, err := os.ReadFile(ConfigFile)
+	switch {
+	case os.IsNotExist(err):
+		if loadedDigest != nil {
+			return errors.NewConfigConflictError(ConfigFile)
+		}
+	case err != nil:
+		return errors.NewConfigSaveError(ConfigFile, err)
+	case loadedDigest == nil || !bytes.Equal(digestOf(current), loadedDigest):
+		return errors.NewConfigConflictError(ConfigFile)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(ConfigFile), "config-*.tmp")
+	if err != nil {
+		return errors.NewFileSystemError("create", ConfigFile, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return errors.NewFileSystemError("chmod", tmp.Name(), err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.NewFileSystemError("write", tmp.Name(), err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.NewFileSystemError("sync", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.NewFileSystemError("close", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), ConfigFile); err != nil {
+		return errors.NewFileSystemError("rename", tmp.Name(), err)
+	}
+	loadedDigest = digestOf(data)
+	return nil
+}
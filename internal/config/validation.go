@@ -4,10 +4,13 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"vault.module/internal/approval"
 	"vault.module/internal/constants"
 	"vault.module/internal/errors" // Ensure this import is present
 )
@@ -38,6 +41,34 @@ func ValidateConfig(cfg *Config) error {
 			return errors.NewVaultNotFoundError(cfg.ActiveVault)
 		}
 	}
+	switch cfg.MemoryProtection {
+	case "", "off", "warn", "strict":
+	default:
+		return errors.NewConfigValidationError("memory_protection", cfg.MemoryProtection, "must be one of: off, warn, strict")
+	}
+	switch cfg.TimeFormat {
+	case "", "relative", "local", "utc":
+	default:
+		return errors.NewConfigValidationError("time_format", cfg.TimeFormat, "must be one of: relative, local, utc")
+	}
+	if cfg.SecretRateLimitGlobal < 0 {
+		return errors.NewConfigValidationError("secret_rate_limit_global", strconv.Itoa(cfg.SecretRateLimitGlobal), "cannot be negative")
+	}
+	if cfg.SecretRateLimitWallet < 0 {
+		return errors.NewConfigValidationError("secret_rate_limit_wallet", strconv.Itoa(cfg.SecretRateLimitWallet), "cannot be negative")
+	}
+	if cfg.MemoryBudgetMB < 0 {
+		return errors.NewConfigValidationError("memory_budget_mb", strconv.Itoa(cfg.MemoryBudgetMB), "cannot be negative; 0 turns the budget off")
+	}
+	if cfg.ConsensusKeyRateLimit < 0 {
+		return errors.NewConfigValidationError("consensus_key_rate_limit", strconv.Itoa(cfg.ConsensusKeyRateLimit), "cannot be negative")
+	}
+	for i, hook := range cfg.Webhooks {
+		u, err := url.Parse(hook.URL)
+		if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+			return errors.NewConfigValidationError(fmt.Sprintf("webhooks[%d].url", i), hook.URL, "must be an absolute http(s) URL")
+		}
+	}
 	// Check each vault
 	for name, details := range cfg.Vaults {
 		if err := ValidateVaultDetails(name, details); err != nil {
@@ -90,6 +121,121 @@ func ValidateVaultDetails(name string, details VaultDetails) error {
 			// Use new error type
 			return errors.NewVaultInvalidPathError(details.RecipientsFile, err)
 		}
+
+		if err := ValidateYubikeySerial(details.YubikeySerial); err != nil {
+			return errors.NewConfigValidationError("yubikey_serial", details.YubikeySerial, err.Error())
+		}
+		if err := ValidateYubikeySlot(details.YubikeySlot); err != nil {
+			return errors.NewConfigValidationError("yubikey_slot", details.YubikeySlot, err.Error())
+		}
+	}
+
+	// FIDO2 vaults encrypt to fido2-hmac recipients; the identity file is only
+	// needed for non-discoverable credentials stored outside the ciphertext
+	if details.Encryption == constants.EncryptionFIDO2 {
+		if details.RecipientsFile == "" {
+			return errors.NewConfigValidationError("recipients_file", "", "required for fido2 encryption")
+		}
+		if err := ValidateFilePath(details.RecipientsFile, "recipients file"); err != nil {
+			return errors.NewVaultInvalidPathError(details.RecipientsFile, err)
+		}
+		if details.IdentityFile != "" {
+			if err := ValidateFilePath(details.IdentityFile, "identity file"); err != nil {
+				return errors.NewVaultInvalidPathError(details.IdentityFile, err)
+			}
+		}
+	}
+
+	if details.Encryption == constants.EncryptionTPM {
+		if details.RecipientsFile == "" {
+			return errors.NewConfigValidationError("recipients_file", "", "required for tpm encryption")
+		}
+		if details.TPMSealDir != "" {
+			if err := ValidateDirectoryPath(details.TPMSealDir, "tpm seal"); err != nil {
+				return errors.NewVaultInvalidPathError(details.TPMSealDir, err)
+			}
+		}
+	}
+
+	// Secure Enclave vaults need the identity file holding the enclave key reference
+	if details.Encryption == constants.EncryptionSecureEnclave {
+		if details.RecipientsFile == "" {
+			return errors.NewConfigValidationError("recipients_file", "", "required for secure-enclave encryption")
+		}
+		if details.IdentityFile == "" {
+			return errors.NewConfigValidationError("identityfile", "", "required for secure-enclave encryption")
+		}
+		if err := ValidateFilePath(details.IdentityFile, "identity file"); err != nil {
+			return errors.NewVaultInvalidPathError(details.IdentityFile, err)
+		}
+	}
+
+	if details.ApprovalURL != "" {
+		if err := approval.ValidateURL(details.ApprovalURL); err != nil {
+			return errors.NewConfigValidationError("approval_url", details.ApprovalURL, err.Error())
+		}
+	}
+	if details.ApprovalTimeout < 0 {
+		return errors.NewConfigValidationError("approval_timeout", strconv.Itoa(details.ApprovalTimeout), "cannot be negative")
+	}
+
+	switch details.SecondFactor {
+	case "", constants.SecondFactorPassphrase:
+	case constants.SecondFactorKeyfile:
+		if details.SecondFactorFile == "" {
+			return errors.NewConfigValidationError("second_factor_file", "", "required for the keyfile second factor")
+		}
+		if err := ValidateFilePath(details.SecondFactorFile, "second factor file"); err != nil {
+			return errors.NewVaultInvalidPathError(details.SecondFactorFile, err)
+		}
+	default:
+		return errors.NewConfigValidationError("second_factor", details.SecondFactor, "must be 'keyfile' or 'passphrase'")
+	}
+	return nil
+}
+
+// PluginForEncryption returns the age plugin (or helper tool) binary required by an
+// encryption method, or an empty string if the method needs none
+func PluginForEncryption(method string) string {
+	switch method {
+	case constants.EncryptionYubiKey:
+		return constants.PluginYubiKey
+	case constants.EncryptionFIDO2:
+		return constants.PluginFIDO2
+	case constants.EncryptionTPM:
+		return constants.ToolTPM2
+	case constants.EncryptionSecureEnclave:
+		return constants.PluginSE
+	default:
+		return ""
+	}
+}
+
+// UsesRecipientsFile reports whether an encryption method encrypts to an age recipients file
+func UsesRecipientsFile(method string) bool {
+	return method == constants.EncryptionYubiKey || method == constants.EncryptionFIDO2 ||
+		method == constants.EncryptionTPM || method == constants.EncryptionSecureEnclave
+}
+
+// ValidateYubikeySerial checks that a YubiKey serial, if set, is numeric
+func ValidateYubikeySerial(serial string) error {
+	if serial == "" {
+		return nil
+	}
+	if _, err := strconv.ParseUint(serial, 10, 32); err != nil {
+		return fmt.Errorf("serial must be a decimal number")
+	}
+	return nil
+}
+
+// ValidateYubikeySlot checks that a PIV retired slot number, if set, is in range 1-20
+func ValidateYubikeySlot(slot string) error {
+	if slot == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(slot)
+	if err != nil || n < 1 || n > 20 {
+		return fmt.Errorf("slot must be a number between 1 and 20")
 	}
 	return nil
 }
@@ -142,9 +288,17 @@ func getAllVaultTypes() []string {
 	}
 }
 
+// GetAllEncryptionMethods returns the list of supported encryption methods
+func GetAllEncryptionMethods() []string {
+	return getAllEncryptionMethods()
+}
+
 func getAllEncryptionMethods() []string {
 	return []string{
 		constants.EncryptionYubiKey,
+		constants.EncryptionFIDO2,
+		constants.EncryptionTPM,
+		constants.EncryptionSecureEnclave,
 	}
 }
 
@@ -10,6 +10,27 @@ const (
 // Encryption methods
 const (
 	EncryptionYubiKey = "yubikey"
+	EncryptionFIDO2   = "fido2"
+	EncryptionTPM     = "tpm"
+
+	// EncryptionSecureEnclave is only available on macOS
+	EncryptionSecureEnclave = "secure-enclave"
+)
+
+// Second factors for layered (two-factor) vault encryption
+const (
+	SecondFactorKeyfile    = "keyfile"
+	SecondFactorPassphrase = "passphrase"
+)
+
+// age plugin binaries used by the encryption methods
+const (
+	PluginYubiKey = "age-plugin-yubikey"
+	PluginFIDO2   = "age-plugin-fido2-hmac"
+	PluginSE      = "age-plugin-se"
+
+	// ToolTPM2 is the tpm2-tools binary probed for TPM-backed vaults
+	ToolTPM2 = "tpm2_unseal"
 )
 
 // Import formats
@@ -23,6 +44,7 @@ const (
 	ConflictPolicySkip      = "skip"
 	ConflictPolicyOverwrite = "overwrite"
 	ConflictPolicyFail      = "fail"
+	ConflictPolicyMerge     = "merge" // combine wallets of the same seed, see actions/merge.go
 )
 
 // Copyable Fields
@@ -31,3 +53,24 @@ const (
 	FieldPrivateKey = "privatekey"
 	FieldMnemonic   = "mnemonic"
 )
+
+// Version of vault.module, recorded in the vault history. Release builds set it with
+// -ldflags "-X vault.module/internal/constants.Version=<version>".
+var Version = "dev"
+
+// Build provenance, set by release builds like Version:
+//
+//	-ldflags "-X vault.module/internal/constants.Commit=<commit> -X vault.module/internal/constants.Builder=<builder>"
+//
+// Builds without them fall back on the VCS information the Go toolchain records.
+var (
+	Commit    = ""
+	Builder   = ""
+	BuildDate = ""
+)
+
+// ReleasePublicKey is the base64 Ed25519 key that signs release manifests,
+// embedded by release builds with
+// -ldflags "-X vault.module/internal/constants.ReleasePublicKey=<key>".
+// Development builds have none and cannot verify themselves.
+var ReleasePublicKey = ""
@@ -0,0 +1,218 @@
+// File: internal/convert/convert.go
+package convert
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"vault.module/internal/constants"
+	"vault.module/internal/errors"
+	"vault.module/internal/keys"
+	"vault.module/internal/vault"
+)
+
+// Source formats convert reads
+const (
+	FormatKeystoreDir   = "keystore-dir"   // Directory of Ethereum keystore V3 files (geth, Clef, MyEtherWallet)
+	FormatMetaMask      = "metamask"       // MetaMask vault or state backup
+	FormatCosmosKeyring = "cosmos-keyring" // Cosmos SDK keyring-file or keyring-test directory
+	FormatCosmosArmor   = "cosmos-armor"   // Cosmos SDK private key exported with 'keys export'
+)
+
+// Formats lists the accepted source formats
+var Formats = []string{FormatKeystoreDir, FormatMetaMask, FormatCosmosKeyring, FormatCosmosArmor}
+
+// Statuses of a report entry
+const (
+	StatusConverted = "converted"
+	StatusSkipped   = "skipped"
+	StatusFailed    = "failed"
+)
+
+// PasswordFunc asks for the password protecting the source. prompt says what it unlocks.
+type PasswordFunc func(prompt string) (string, error)
+
+// Entry is the outcome for one key or seed found in the source
+type Entry struct {
+	Source   string   `json:"source"`           // File or key name within the source
+	Prefix   string   `json:"prefix,omitempty"` // Prefix of the wallet in the output
+	Address  string   `json:"address,omitempty"`
+	Status   string   `json:"status"`
+	Detail   string   `json:"detail,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Report is the validation report of a conversion
+type Report struct {
+	Format    string  `json:"format"`
+	Source    string  `json:"source"`
+	VaultType string  `json:"vault_type"`
+	Converted int     `json:"converted"`
+	Skipped   int     `json:"skipped"`
+	Failed    int     `json:"failed"`
+	Entries   []Entry `json:"entries"`
+}
+
+// Result is a converted source: wallets in the JSON import format and the report
+type Result struct {
+	Wallets vault.Vault
+	Report  Report
+}
+
+// Clear wipes the secrets of the converted wallets
+func (r *Result) Clear() {
+	for _, wallet := range r.Wallets {
+		wallet.Clear()
+	}
+}
+
+// Convert reads the source at path in the given format. Problems with single
+// keys end up in the report; an error means the source as a whole could not
+// be read. prefix names the wallets when the source has no usable names.
+func Convert(format, path, prefix string, password PasswordFunc) (*Result, error) {
+	c := &converter{
+		result: &Result{
+			Wallets: vault.Vault{},
+			Report:  Report{Format: format, Source: path},
+		},
+		prefix:    prefix,
+		addresses: map[string]string{},
+	}
+
+	var err error
+	switch format {
+	case FormatKeystoreDir:
+		c.result.Report.VaultType = constants.VaultTypeEVM
+		err = c.keystoreDir(path, password)
+	case FormatMetaMask:
+		c.result.Report.VaultType = constants.VaultTypeEVM
+		err = c.metaMask(path, password)
+	case FormatCosmosKeyring:
+		c.result.Report.VaultType = constants.VaultTypeCosmos
+		err = c.cosmosKeyring(path, password)
+	case FormatCosmosArmor:
+		c.result.Report.VaultType = constants.VaultTypeCosmos
+		err = c.cosmosArmor(path, password)
+	default:
+		return nil, errors.NewFormatInvalidError(format, fmt.Sprintf("unknown source format, choose one of: %s", strings.Join(Formats, ", ")))
+	}
+	if err != nil {
+		c.result.Clear()
+		return nil, err
+	}
+	return c.result, nil
+}
+
+// converter collects the wallets and the report of one conversion
+type converter struct {
+	result    *Result
+	prefix    string
+	counter   int
+	addresses map[string]string // Lowercase address -> prefix, to drop duplicates
+}
+
+var (
+	prefixInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+	prefixStart        = regexp.MustCompile(`^[a-zA-Z]`)
+)
+
+// nextPrefix returns a free prefix, from name when it makes a valid one
+func (c *converter) nextPrefix(name string) string {
+	if name != "" {
+		candidate := prefixInvalidChars.ReplaceAllString(name, "_")
+		if len(candidate) > 32 {
+			candidate = candidate[:32]
+		}
+		if prefixStart.MatchString(candidate) {
+			if _, taken := c.result.Wallets[candidate]; !taken {
+				return candidate
+			}
+		}
+	}
+	for {
+		c.counter++
+		candidate := fmt.Sprintf("%s%d", c.prefix, c.counter)
+		if _, taken := c.result.Wallets[candidate]; !taken {
+			return candidate
+		}
+	}
+}
+
+// addPrivateKey converts one private key. expectedAddress, when the source
+// records one, is checked against the address derived from the key.
+func (c *converter) addPrivateKey(source, name, privateKey, expectedAddress string) {
+	manager, err := keys.GetKeyManager(c.result.Report.VaultType)
+	if err != nil {
+		c.fail(source, err.Error())
+		return
+	}
+	wallet, err := manager.CreateWalletFromPrivateKey(privateKey)
+	if err != nil {
+		c.fail(source, err.Error())
+		return
+	}
+	address := wallet.Addresses[0].Address
+	if expectedAddress != "" && !strings.EqualFold(strings.TrimPrefix(expectedAddress, "0x"), strings.TrimPrefix(address, "0x")) {
+		wallet.Clear()
+		c.fail(source, fmt.Sprintf("the key belongs to %s, not to %s as the source says", address, expectedAddress))
+		return
+	}
+	c.add(source, name, wallet)
+}
+
+// addMnemonic converts one seed phrase, with count addresses derived
+func (c *converter) addMnemonic(source, name, mnemonic string, count int) {
+	manager, err := keys.GetKeyManager(c.result.Report.VaultType)
+	if err != nil {
+		c.fail(source, err.Error())
+		return
+	}
+	wallet, err := manager.CreateWalletFromMnemonic(mnemonic)
+	if err != nil {
+		c.fail(source, err.Error())
+		return
+	}
+	for i := 1; i < count; i++ {
+		if wallet, _, err = manager.DeriveNextAddress(wallet); err != nil {
+			wallet.Clear()
+			c.fail(source, fmt.Sprintf("failed to derive address %d: %v", i, err))
+			return
+		}
+	}
+	c.add(source, name, wallet)
+}
+
+func (c *converter) add(source, name string, wallet vault.Wallet) {
+	address := wallet.Addresses[0].Address
+	if existing, ok := c.addresses[strings.ToLower(address)]; ok {
+		wallet.Clear()
+		c.result.Report.Entries = append(c.result.Report.Entries, Entry{
+			Source: source, Address: address, Status: StatusSkipped,
+			Detail: fmt.Sprintf("same key as wallet '%s'", existing),
+		})
+		c.result.Report.Skipped++
+		return
+	}
+
+	prefix := c.nextPrefix(name)
+	c.result.Wallets[prefix] = wallet
+	for _, addr := range wallet.Addresses {
+		c.addresses[strings.ToLower(addr.Address)] = prefix
+	}
+	c.result.Report.Entries = append(c.result.Report.Entries, Entry{
+		Source: source, Prefix: prefix, Address: address, Status: StatusConverted,
+		Warnings: keys.AnalyzeWallet(wallet),
+	})
+	c.result.Report.Converted++
+}
+
+func (c *converter) skip(source, detail string) {
+	c.result.Report.Entries = append(c.result.Report.Entries, Entry{Source: source, Status: StatusSkipped, Detail: detail})
+	c.result.Report.Skipped++
+}
+
+func (c *converter) fail(source, detail string) {
+	c.result.Report.Entries = append(c.result.Report.Entries, Entry{Source: source, Status: StatusFailed, Detail: detail})
+	c.result.Report.Failed++
+}
@@ -0,0 +1,92 @@
+// File: internal/convert/cosmoskeyring.go
+package convert
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"vault.module/internal/cosmoskeyring"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+)
+
+// maxArmorFileSize bounds an armored key file; real ones are under 1KB
+const maxArmorFileSize = 64 * 1024
+
+// cosmosKeyring converts the secp256k1 keys of a keyring-file or keyring-test
+// directory
+func (c *converter) cosmosKeyring(path string, password PasswordFunc) error {
+	dir, err := cosmoskeyring.Dir(path)
+	if err != nil {
+		return err
+	}
+	c.result.Report.Source = dir
+
+	var passphrase string
+	if !cosmoskeyring.IsTestDir(dir) {
+		if passphrase, err = password("Keyring passphrase"); err != nil {
+			return err
+		}
+	}
+	keys, err := cosmoskeyring.Read(dir, passphrase)
+	if err != nil {
+		return err
+	}
+
+	for i := range keys {
+		key := &keys[i]
+		switch {
+		case key.Err != nil:
+			c.fail(key.Name, key.Err.Error())
+		case key.Kind == cosmoskeyring.KindLedger:
+			c.skip(key.Name, "Ledger key, the keyring holds no private key")
+		case key.Kind == cosmoskeyring.KindMulti:
+			c.skip(key.Name, "multisig key, the keyring holds no private key")
+		case key.Kind == cosmoskeyring.KindOffline:
+			c.skip(key.Name, "offline key, the keyring holds no private key")
+		case key.PrivateKey == nil:
+			c.skip(key.Name, "key type "+key.KeyType+" is not supported, only secp256k1")
+		default:
+			privateKey := hex.EncodeToString(key.PrivateKey)
+			c.addPrivateKey(key.Name, key.Name, privateKey, key.Address)
+		}
+		key.Clear()
+	}
+	return nil
+}
+
+// cosmosArmor converts a private key exported with 'keys export'
+func (c *converter) cosmosArmor(path string, password PasswordFunc) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.NewFileSystemError("access", path, err)
+	}
+	if info.Size() > maxArmorFileSize {
+		return errors.NewInvalidInputError(path, "file too large for an armored key")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return errors.NewFileSystemError("read", path, err)
+	}
+	if !cosmoskeyring.IsArmored(content) {
+		return errors.NewFormatInvalidError(FormatCosmosArmor, "no armored private key (TENDERMINT PRIVATE KEY) in the file")
+	}
+
+	passphrase, err := password("Passphrase of the exported key")
+	if err != nil {
+		return err
+	}
+	privKey, err := cosmoskeyring.DecryptArmor(string(content), passphrase)
+	if err != nil {
+		return err
+	}
+	privateKey := hex.EncodeToString(privKey)
+	security.SecureZero(privKey)
+
+	// 'keys export NAME > NAME.armor' is the usual way to name the file
+	name := filepath.Base(path)
+	name = name[:len(name)-len(filepath.Ext(name))]
+	c.addPrivateKey(filepath.Base(path), name, privateKey, "")
+	return nil
+}
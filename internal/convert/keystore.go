@@ -0,0 +1,81 @@
+// File: internal/convert/keystore.go
+package convert
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+)
+
+// maxKeystoreFileSize bounds a keystore file; real ones are under 1KB
+const maxKeystoreFileSize = 64 * 1024
+
+// keystoreDir converts every keystore V3 file in dir. The files usually share
+// a password; the ones it does not open are reported as failed.
+func (c *converter) keystoreDir(dir string, password PasswordFunc) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return errors.NewFileSystemError("read", dir, err).WithDetails("the keystore directory could not be read")
+	}
+
+	type keystoreFile struct {
+		name    string
+		data    []byte
+		address string
+	}
+	var files []keystoreFile
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		info, err := entry.Info()
+		if err != nil || info.Size() > maxKeystoreFileSize {
+			c.skip(entry.Name(), "not a keystore V3 file")
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			c.fail(entry.Name(), err.Error())
+			continue
+		}
+		var header struct {
+			Version int             `json:"version"`
+			Address string          `json:"address"`
+			Crypto  json.RawMessage `json:"crypto"`
+		}
+		if err := json.Unmarshal(data, &header); err != nil || header.Version != 3 || len(header.Crypto) == 0 {
+			c.skip(entry.Name(), "not a keystore V3 file")
+			continue
+		}
+		files = append(files, keystoreFile{name: entry.Name(), data: data, address: header.Address})
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	pass, err := password("Password of the keystore files")
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		key, err := keystore.DecryptKey(file.data, pass)
+		if err != nil {
+			c.fail(file.name, err.Error())
+			continue
+		}
+		keyBytes := crypto.FromECDSA(key.PrivateKey)
+		privateKey := hex.EncodeToString(keyBytes)
+		security.SecureZero(keyBytes)
+		c.addPrivateKey(file.name, "", privateKey, file.address)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+// File: internal/convert/metamask.go
+package convert
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"vault.module/internal/errors"
+	"vault.module/internal/keys"
+	"vault.module/internal/security"
+)
+
+// maxMetaMaskFileSize bounds a MetaMask backup; LevelDB logs can be large
+const maxMetaMaskFileSize = 64 * 1024 * 1024
+
+// metaMaskDefaultIterations is the PBKDF2 iteration count of vaults written
+// before MetaMask recorded it in keyMetadata
+const metaMaskDefaultIterations = 10000
+
+// metaMaskVault is the encrypted keyring of MetaMask (@metamask/browser-passworder)
+type metaMaskVault struct {
+	Data        string `json:"data"`
+	IV          string `json:"iv"`
+	Salt        string `json:"salt"`
+	KeyMetadata *struct {
+		Algorithm string `json:"algorithm"`
+		Params    struct {
+			Iterations int `json:"iterations"`
+		} `json:"params"`
+	} `json:"keyMetadata,omitempty"`
+}
+
+// metaMaskVaultPattern finds a vault in raw browser storage, such as the
+// LevelDB files of the extension, where it is stored as an escaped JSON string
+var metaMaskVaultPattern = regexp.MustCompile(`\{"data":"[A-Za-z0-9+/=]+","iv":"[A-Za-z0-9+/=]+"(,"keyMetadata":\{[^}]*\}\})?,"salt":"[A-Za-z0-9+/=]+"\}`)
+
+// metaMask converts the keyrings of a MetaMask vault. The file may hold the
+// vault itself, a state backup with KeyringController.vault, or the raw
+// storage of the extension.
+func (c *converter) metaMask(path string, password PasswordFunc) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.NewFileSystemError("access", path, err)
+	}
+	if info.Size() > maxMetaMaskFileSize {
+		return errors.NewInvalidInputError(path, "file too large for a MetaMask backup")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return errors.NewFileSystemError("read", path, err)
+	}
+
+	encrypted, err := findMetaMaskVault(content)
+	if err != nil {
+		return err
+	}
+
+	pass, err := password("MetaMask password")
+	if err != nil {
+		return err
+	}
+	plaintext, err := decryptMetaMaskVault(encrypted, pass)
+	if err != nil {
+		return err
+	}
+	defer security.SecureZero(plaintext)
+
+	var keyrings []struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(plaintext, &keyrings); err != nil {
+		return errors.NewFormatInvalidError(FormatMetaMask, "the decrypted vault is not a list of keyrings")
+	}
+
+	for i, keyring := range keyrings {
+		source := fmt.Sprintf("keyring %d (%s)", i+1, keyring.Type)
+		switch keyring.Type {
+		case "HD Key Tree":
+			c.metaMaskHDKeyring(source, keyring.Data)
+		case "Simple Key Pair":
+			var privateKeys []string
+			if err := json.Unmarshal(keyring.Data, &privateKeys); err != nil {
+				c.fail(source, "the imported keys could not be read")
+				continue
+			}
+			for j, privateKey := range privateKeys {
+				c.addPrivateKey(fmt.Sprintf("%s key %d", source, j+1), "", privateKey, "")
+			}
+		default:
+			c.skip(source, "only seed phrases and imported keys can be converted; hardware and snap accounts have no keys in MetaMask")
+		}
+	}
+	return nil
+}
+
+// metaMaskHDKeyring converts the seed phrase of an HD keyring, with the
+// accounts MetaMask shows
+func (c *converter) metaMaskHDKeyring(source string, data json.RawMessage) {
+	var hd struct {
+		Mnemonic         json.RawMessage `json:"mnemonic"`
+		NumberOfAccounts int             `json:"numberOfAccounts"`
+		HDPath           string          `json:"hdPath"`
+	}
+	if err := json.Unmarshal(data, &hd); err != nil {
+		c.fail(source, "the seed phrase could not be read")
+		return
+	}
+	if hd.HDPath != "" && hd.HDPath != keys.EVMDerivationPath {
+		c.fail(source, fmt.Sprintf("derivation path %s is not supported, only %s", hd.HDPath, keys.EVMDerivationPath))
+		return
+	}
+
+	// Newer versions store the phrase as its UTF-8 bytes
+	var mnemonic string
+	if err := json.Unmarshal(hd.Mnemonic, &mnemonic); err != nil {
+		var utf8Bytes []byte
+		var numbers []int
+		if err := json.Unmarshal(hd.Mnemonic, &numbers); err != nil {
+			c.fail(source, "the seed phrase could not be read")
+			return
+		}
+		for _, n := range numbers {
+			utf8Bytes = append(utf8Bytes, byte(n))
+		}
+		mnemonic = string(utf8Bytes)
+		security.SecureZero(utf8Bytes)
+	}
+
+	count := hd.NumberOfAccounts
+	if count < 1 {
+		count = 1
+	}
+	c.addMnemonic(source, "", strings.TrimSpace(mnemonic), count)
+}
+
+// findMetaMaskVault returns the encrypted vault in content
+func findMetaMaskVault(content []byte) (metaMaskVault, error) {
+	var encrypted metaMaskVault
+	if err := json.Unmarshal(content, &encrypted); err == nil && encrypted.Data != "" && encrypted.Salt != "" {
+		return encrypted, nil
+	}
+
+	var state map[string]interface{}
+	if err := json.Unmarshal(content, &state); err == nil {
+		// A state backup: {"KeyringController": {"vault": "..."}}, possibly under "data"
+		for _, root := range []interface{}{state, state["data"]} {
+			object, _ := root.(map[string]interface{})
+			controller, _ := object["KeyringController"].(map[string]interface{})
+			if vaultJSON, ok := controller["vault"].(string); ok {
+				if err := json.Unmarshal([]byte(vaultJSON), &encrypted); err == nil && encrypted.Data != "" {
+					return encrypted, nil
+				}
+			}
+		}
+	}
+
+	unescaped := strings.ReplaceAll(string(content), `\"`, `"`)
+	if match := metaMaskVaultPattern.FindString(unescaped); match != "" {
+		if err := json.Unmarshal([]byte(match), &encrypted); err == nil {
+			return encrypted, nil
+		}
+	}
+	return encrypted, errors.NewFormatInvalidError(FormatMetaMask, "no MetaMask vault found in the file")
+}
+
+// decryptMetaMaskVault decrypts the vault: AES-256-GCM with a key from
+// PBKDF2-SHA256 of the password
+func decryptMetaMaskVault(encrypted metaMaskVault, password string) ([]byte, error) {
+	data, errData := base64.StdEncoding.DecodeString(encrypted.Data)
+	iv, errIV := base64.StdEncoding.DecodeString(encrypted.IV)
+	salt, errSalt := base64.StdEncoding.DecodeString(encrypted.Salt)
+	if errData != nil || errIV != nil || errSalt != nil || len(iv) == 0 {
+		return nil, errors.NewFormatInvalidError(FormatMetaMask, "the vault is not valid base64")
+	}
+
+	iterations := metaMaskDefaultIterations
+	if encrypted.KeyMetadata != nil {
+		if encrypted.KeyMetadata.Algorithm != "" && encrypted.KeyMetadata.Algorithm != "PBKDF2" {
+			return nil, errors.NewFormatInvalidError(FormatMetaMask, fmt.Sprintf("key derivation %s is not supported", encrypted.KeyMetadata.Algorithm))
+		}
+		if encrypted.KeyMetadata.Params.Iterations > 0 {
+			iterations = encrypted.KeyMetadata.Params.Iterations
+		}
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, 32)
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeInternal, "key derivation failed").WithContext("error", err.Error())
+	}
+	defer security.SecureZero(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeInternal, "cipher setup failed").WithContext("error", err.Error())
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeInternal, "cipher setup failed").WithContext("error", err.Error())
+	}
+	plaintext, err := gcm.Open(nil, iv, data, nil)
+	if err != nil {
+		return nil, errors.NewInvalidInputError("MetaMask password", "the password is wrong or the vault is damaged")
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,134 @@
+// File: internal/cosmoskeyring/armor.go
+package cosmoskeyring
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	sdkbcrypt "github.com/cosmos/cosmos-sdk/crypto/keys/bcrypt"
+	"github.com/cosmos/cosmos-sdk/crypto/xsalsa20symmetric"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/openpgp/armor" //nolint:staticcheck // The format 'keys export' writes
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+)
+
+// Armored private keys, as written by 'keys export' and read by 'keys import'
+const (
+	armorBlockType = "TENDERMINT PRIVATE KEY"
+	armorAlgo      = "secp256k1"
+
+	// Parameters of the kdfs, as in the Cosmos SDK
+	bcryptCost    = 12
+	argon2Time    = 1
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+)
+
+// aminoPrivKeyPrefix is the amino prefix of tendermint/PrivKeySecp256k1,
+// followed by the key length
+var aminoPrivKeyPrefix = []byte{0xe1, 0xb0, 0xf7, 0x9b, 0x20}
+
+// IsArmored reports whether content looks like an armored private key
+func IsArmored(content []byte) bool {
+	return bytes.Contains(content, []byte("-----BEGIN "+armorBlockType+"-----"))
+}
+
+// DecryptArmor returns the secp256k1 private key of an armored key. Both the
+// argon2 kdf of current SDKs and the bcrypt kdf of older ones are read.
+func DecryptArmor(armored, passphrase string) ([]byte, error) {
+	block, err := armor.Decode(strings.NewReader(armored))
+	if err != nil {
+		return nil, errors.NewFormatInvalidError("armor", "not an ASCII-armored key")
+	}
+	if block.Type != armorBlockType {
+		return nil, errors.NewFormatInvalidError("armor", fmt.Sprintf("armor type %q is not a private key", block.Type))
+	}
+	if algo := block.Header["type"]; algo != "" && algo != armorAlgo {
+		return nil, errors.NewFormatInvalidError("armor", fmt.Sprintf("key type %s is not supported, only secp256k1", algo))
+	}
+	encrypted, err := io.ReadAll(block.Body)
+	if err != nil {
+		return nil, errors.NewFormatInvalidError("armor", "the armored data is damaged")
+	}
+	salt, err := hex.DecodeString(block.Header["salt"])
+	if err != nil || len(salt) == 0 {
+		return nil, errors.NewFormatInvalidError("armor", "missing or invalid salt")
+	}
+
+	var plaintext []byte
+	switch block.Header["kdf"] {
+	case "argon2":
+		key := argon2.IDKey([]byte(passphrase), salt, argon2Time, argon2Memory, argon2Threads, chacha20poly1305.KeySize)
+		defer security.SecureZero(key)
+		aead, err := chacha20poly1305.New(key)
+		if err != nil {
+			return nil, errors.New(errors.ErrCodeInternal, "cipher setup failed").WithContext("error", err.Error())
+		}
+		// The nonce is fixed; the key is new for every export through its salt
+		nonce := make([]byte, aead.NonceSize())
+		if plaintext, err = aead.Open(nil, nonce, encrypted, nil); err != nil {
+			return nil, errors.NewInvalidInputError("passphrase", "the passphrase is wrong or the key is damaged")
+		}
+	case "bcrypt":
+		key, err := sdkbcrypt.GenerateFromPassword(salt, []byte(passphrase), bcryptCost)
+		if err != nil {
+			return nil, errors.New(errors.ErrCodeInternal, "key derivation failed").WithContext("error", err.Error())
+		}
+		digest := sha256.Sum256(key)
+		security.SecureZero(key)
+		plaintext, err = xsalsa20symmetric.DecryptSymmetric(encrypted, digest[:])
+		security.SecureZero(digest[:])
+		if err != nil {
+			return nil, errors.NewInvalidInputError("passphrase", "the passphrase is wrong or the key is damaged")
+		}
+	default:
+		return nil, errors.NewFormatInvalidError("armor", fmt.Sprintf("key derivation %q is not supported", block.Header["kdf"]))
+	}
+	defer security.SecureZero(plaintext)
+
+	if len(plaintext) != len(aminoPrivKeyPrefix)+32 || !bytes.HasPrefix(plaintext, aminoPrivKeyPrefix) {
+		return nil, errors.NewFormatInvalidError("armor", "the key is not a secp256k1 private key")
+	}
+	return append([]byte(nil), plaintext[len(aminoPrivKeyPrefix):]...), nil
+}
+
+// EncryptArmor armors a secp256k1 private key with passphrase, as 'keys
+// export' does, for 'keys import'
+func EncryptArmor(privKey []byte, passphrase string) (string, error) {
+	salt, err := security.RandomBytes(16)
+	if err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(passphrase), salt, argon2Time, argon2Memory, argon2Threads, chacha20poly1305.KeySize)
+	defer security.SecureZero(key)
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return "", errors.New(errors.ErrCodeInternal, "cipher setup failed").WithContext("error", err.Error())
+	}
+	plaintext := append(append([]byte(nil), aminoPrivKeyPrefix...), privKey...)
+	defer security.SecureZero(plaintext)
+	encrypted := aead.Seal(nil, make([]byte, aead.NonceSize()), plaintext, nil)
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, armorBlockType, map[string]string{
+		"kdf":  "argon2",
+		"salt": fmt.Sprintf("%X", salt),
+		"type": armorAlgo,
+	})
+	if err != nil {
+		return "", errors.New(errors.ErrCodeInternal, "armor encoding failed").WithContext("error", err.Error())
+	}
+	if _, err := w.Write(encrypted); err != nil {
+		return "", errors.New(errors.ErrCodeInternal, "armor encoding failed").WithContext("error", err.Error())
+	}
+	if err := w.Close(); err != nil {
+		return "", errors.New(errors.ErrCodeInternal, "armor encoding failed").WithContext("error", err.Error())
+	}
+	return buf.String() + "\n", nil
+}
@@ -0,0 +1,267 @@
+// File: internal/cosmoskeyring/keyring.go
+package cosmoskeyring
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cometbft/cometbft/crypto/secp256k1"
+	jose "github.com/dvsekhvalnov/jose2go"
+	"golang.org/x/crypto/bcrypt"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+)
+
+// Directories of the keyring backends that keep keys in files. The os
+// backend keeps them in the system keychain and is not supported.
+const (
+	FileDir = "keyring-file"
+	TestDir = "keyring-test"
+
+	// testPassword is the fixed password of the test backend
+	testPassword = "test"
+
+	// keyhashFile holds the bcrypt hash of the passphrase of a file backend
+	keyhashFile = "keyhash"
+)
+
+// item is an entry of a file keyring, as the 99designs/keyring library the
+// Cosmos SDK uses stores it inside each JWE file
+type item struct {
+	Key         string
+	Data        []byte
+	Label       string
+	Description string
+
+	KeychainNotTrustApplication bool
+	KeychainNotSynchronizable   bool
+}
+
+// Key is an entry of a keyring
+type Key struct {
+	Name       string // Key name, as in 'keys list'
+	Kind       string // KindLocal, KindLedger, ...
+	KeyType    string // Type URL of the private key of local keys
+	Address    string // Hex address, as the vault shows Cosmos addresses
+	PrivateKey []byte // secp256k1 private key of local secp256k1 keys
+	Err        error  // Why the entry could not be read
+}
+
+// Clear wipes the private key
+func (k *Key) Clear() {
+	security.SecureZero(k.PrivateKey)
+	k.PrivateKey = nil
+}
+
+// nameRe limits key names written to ones that need no escaping in file names
+var nameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// ValidateName checks a key name for Write
+func ValidateName(name string) error {
+	if !nameRe.MatchString(name) {
+		return errors.NewInvalidInputError(name, "key names are 1 to 64 letters, digits, '.', '_' or '-', starting with a letter or digit")
+	}
+	return nil
+}
+
+// Dir returns the keyring directory at path: path itself, or its
+// keyring-file or keyring-test subdirectory when path is a node home such as
+// ~/.gaia
+func Dir(path string) (string, error) {
+	for _, dir := range []string{path, filepath.Join(path, FileDir), filepath.Join(path, TestDir)} {
+		if matches, _ := filepath.Glob(filepath.Join(dir, "*.info")); len(matches) > 0 {
+			return dir, nil
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", errors.NewFileSystemError("access", path, err)
+	}
+	return "", errors.NewInvalidInputError(path, "no keyring-file or keyring-test keys (*.info) found")
+}
+
+// TargetDir returns the keyring directory to write to at path: a
+// keyring-file or keyring-test directory, which may not exist yet, or the one
+// inside the node home at path
+func TargetDir(path string) (string, error) {
+	switch filepath.Base(filepath.Clean(path)) {
+	case FileDir, TestDir:
+		return path, nil
+	}
+	for _, name := range []string{FileDir, TestDir} {
+		if info, err := os.Stat(filepath.Join(path, name)); err == nil && info.IsDir() {
+			return filepath.Join(path, name), nil
+		}
+	}
+	return "", errors.NewInvalidInputError(path, fmt.Sprintf("give a %s or %s directory, or a node home containing one", FileDir, TestDir))
+}
+
+// IsTestDir reports whether dir belongs to the test backend, whose password
+// is fixed
+func IsTestDir(dir string) bool {
+	return filepath.Base(filepath.Clean(dir)) == TestDir
+}
+
+// HasPassphrase reports whether the file backend at dir has its passphrase
+// set. A new one takes the passphrase of the first key written.
+func HasPassphrase(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, keyhashFile))
+	return err == nil
+}
+
+// passwordFor returns the password of the keyring at dir, checking a given
+// passphrase against the stored hash
+func passwordFor(dir, passphrase string) (string, error) {
+	if IsTestDir(dir) {
+		return testPassword, nil
+	}
+	keyhash, err := os.ReadFile(filepath.Join(dir, keyhashFile))
+	if err == nil {
+		if bcrypt.CompareHashAndPassword(keyhash, []byte(passphrase)) != nil {
+			return "", errors.NewInvalidInputError("keyring passphrase", "incorrect passphrase")
+		}
+	} else if !os.IsNotExist(err) {
+		return "", errors.NewFileSystemError("read", filepath.Join(dir, keyhashFile), err)
+	}
+	return passphrase, nil
+}
+
+// Read returns the keys in the keyring at dir. passphrase is ignored for the
+// test backend. Entries that cannot be read are returned with Err set.
+func Read(dir, passphrase string) ([]Key, error) {
+	password, err := passwordFor(dir, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.info"))
+	if err != nil {
+		return nil, errors.NewFileSystemError("read", dir, err)
+	}
+
+	keys := make([]Key, 0, len(files))
+	for _, file := range files {
+		key := Key{Name: strings.TrimSuffix(filepath.Base(file), ".info")}
+		data, err := readItem(file, password)
+		if err != nil {
+			key.Err = err
+			keys = append(keys, key)
+			continue
+		}
+		r, err := parseRecord(data)
+		security.SecureZero(data)
+		if err != nil {
+			key.Err = fmt.Errorf("legacy (amino) keyring entry; list the keys once with a Cosmos SDK v0.46+ binary to migrate it")
+			keys = append(keys, key)
+			continue
+		}
+		key.Name, key.Kind, key.KeyType = r.name, r.kind, r.privKeyType
+		if r.pubKeyType == Secp256k1PubKeyURL {
+			if pubKey, err := keyBytes(r.pubKey); err == nil && len(pubKey) == secp256k1.PubKeySize {
+				key.Address = secp256k1.PubKey(pubKey).Address().String()
+			}
+		}
+		if r.kind == KindLocal && r.privKeyType == Secp256k1PrivKeyURL {
+			privKey, err := keyBytes(r.privKey)
+			if err != nil || len(privKey) != secp256k1.PrivKeySize {
+				key.Err = fmt.Errorf("the private key could not be read")
+			} else {
+				key.PrivateKey = privKey
+			}
+		}
+		security.SecureZero(r.privKey)
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Write adds a secp256k1 key to the keyring at dir, as 'keys add' does: the
+// record under <name>.info and the name under <address>.address. The
+// directory and, for a new file backend, the passphrase hash are created.
+// It returns the hex address of the key.
+func Write(dir, passphrase, name string, privKey []byte) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	if len(privKey) != secp256k1.PrivKeySize {
+		return "", errors.NewInvalidInputError("private key", "not a secp256k1 private key")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", errors.NewFileSystemError("create", dir, err)
+	}
+	password, err := passwordFor(dir, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	pubKey := secp256k1.PrivKey(privKey).PubKey()
+	address := pubKey.Address()
+	infoKey := name + ".info"
+	addressKey := hex.EncodeToString(address) + ".address"
+	for _, key := range []string{infoKey, addressKey} {
+		if _, err := os.Stat(filepath.Join(dir, key)); err == nil {
+			return "", errors.NewInvalidInputError(name, fmt.Sprintf("the keyring already has %s", key))
+		}
+	}
+
+	if !IsTestDir(dir) && !HasPassphrase(dir) {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return "", errors.New(errors.ErrCodeInternal, "failed to hash the keyring passphrase").WithContext("error", err.Error())
+		}
+		if err := os.WriteFile(filepath.Join(dir, keyhashFile), hash, 0600); err != nil {
+			return "", errors.NewFileSystemError("write", filepath.Join(dir, keyhashFile), err)
+		}
+	}
+
+	recordData := marshalLocalRecord(name, privKey, pubKey.Bytes())
+	defer security.SecureZero(recordData)
+	if err := writeItem(dir, item{Key: infoKey, Data: recordData}, password); err != nil {
+		return "", err
+	}
+	if err := writeItem(dir, item{Key: addressKey, Data: []byte(infoKey)}, password); err != nil {
+		os.Remove(filepath.Join(dir, infoKey))
+		return "", err
+	}
+	return address.String(), nil
+}
+
+// readItem decrypts one keyring file and returns the item's data
+func readItem(file, password string) ([]byte, error) {
+	token, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	payload, _, err := jose.Decode(strings.TrimSpace(string(token)), password)
+	if err != nil {
+		return nil, fmt.Errorf("the passphrase is wrong or the file is damaged")
+	}
+	var it item
+	if err := json.Unmarshal([]byte(payload), &it); err != nil {
+		return nil, fmt.Errorf("not a keyring entry")
+	}
+	return it.Data, nil
+}
+
+// writeItem encrypts an item the way the file backend does
+func writeItem(dir string, it item, password string) error {
+	payload, err := json.Marshal(it)
+	if err != nil {
+		return errors.New(errors.ErrCodeInternal, "failed to encode keyring entry").WithContext("error", err.Error())
+	}
+	defer security.SecureZero(payload)
+	token, err := jose.Encrypt(string(payload), jose.PBES2_HS256_A128KW, jose.A256GCM, password,
+		jose.Headers(map[string]interface{}{"created": time.Now().String()}))
+	if err != nil {
+		return errors.New(errors.ErrCodeInternal, "failed to encrypt keyring entry").WithContext("error", err.Error())
+	}
+	path := filepath.Join(dir, it.Key)
+	if err := os.WriteFile(path, []byte(token), 0600); err != nil {
+		return errors.NewFileSystemError("write", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// File: internal/cosmoskeyring/record.go
+package cosmoskeyring
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Protobuf type URLs of the keys in a keyring record
+const (
+	Secp256k1PrivKeyURL = "/cosmos.crypto.secp256k1.PrivKey"
+	Secp256k1PubKeyURL  = "/cosmos.crypto.secp256k1.PubKey"
+)
+
+// Kinds of keyring records
+const (
+	KindLocal   = "local"   // Private key in the keyring
+	KindLedger  = "ledger"  // Key on a Ledger, only its path is kept
+	KindMulti   = "multi"   // Multisig public key
+	KindOffline = "offline" // Public key only
+)
+
+// record is a keyring record (cosmos.crypto.keyring.v1.Record):
+//
+//	Record { string name = 1; Any pub_key = 2; oneof item { Local local = 3;
+//	         Ledger ledger = 4; Multi multi = 5; Offline offline = 6; } }
+//	Local  { Any priv_key = 1; }
+type record struct {
+	name        string
+	kind        string
+	pubKeyType  string
+	pubKey      []byte // Key message, see keyBytes
+	privKeyType string
+	privKey     []byte // Key message, see keyBytes
+}
+
+// parseRecord decodes a record. Legacy (amino) entries of keyrings written
+// before Cosmos SDK v0.46 do not parse.
+func parseRecord(data []byte) (record, error) {
+	var r record
+	err := walkProto(data, func(num protowire.Number, value []byte) error {
+		var err error
+		switch num {
+		case 1:
+			r.name = string(value)
+		case 2:
+			r.pubKeyType, r.pubKey, err = parseAny(value)
+		case 3:
+			r.kind = KindLocal
+			err = walkProto(value, func(num protowire.Number, value []byte) error {
+				if num == 1 {
+					var err error
+					r.privKeyType, r.privKey, err = parseAny(value)
+					return err
+				}
+				return nil
+			})
+		case 4:
+			r.kind = KindLedger
+		case 5:
+			r.kind = KindMulti
+		case 6:
+			r.kind = KindOffline
+		}
+		return err
+	})
+	if err == nil && (r.name == "" || r.kind == "") {
+		err = fmt.Errorf("not a keyring record")
+	}
+	return r, err
+}
+
+// marshalLocalRecord encodes the record of a secp256k1 key held in the keyring
+func marshalLocalRecord(name string, privKey, pubKey []byte) []byte {
+	local := protowire.AppendTag(nil, 1, protowire.BytesType)
+	local = protowire.AppendBytes(local, marshalAny(Secp256k1PrivKeyURL, marshalKey(privKey)))
+
+	b := protowire.AppendTag(nil, 1, protowire.BytesType)
+	b = protowire.AppendString(b, name)
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendBytes(b, marshalAny(Secp256k1PubKeyURL, marshalKey(pubKey)))
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	return protowire.AppendBytes(b, local)
+}
+
+// parseAny decodes google.protobuf.Any { string type_url = 1; bytes value = 2; }
+func parseAny(data []byte) (typeURL string, value []byte, err error) {
+	err = walkProto(data, func(num protowire.Number, field []byte) error {
+		switch num {
+		case 1:
+			typeURL = string(field)
+		case 2:
+			value = append([]byte(nil), field...)
+		}
+		return nil
+	})
+	return typeURL, value, err
+}
+
+func marshalAny(typeURL string, value []byte) []byte {
+	b := protowire.AppendTag(nil, 1, protowire.BytesType)
+	b = protowire.AppendString(b, typeURL)
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	return protowire.AppendBytes(b, value)
+}
+
+// keyBytes decodes a secp256k1 PrivKey or PubKey message { bytes key = 1; }
+func keyBytes(data []byte) ([]byte, error) {
+	var key []byte
+	err := walkProto(data, func(num protowire.Number, field []byte) error {
+		if num == 1 {
+			key = append([]byte(nil), field...)
+		}
+		return nil
+	})
+	return key, err
+}
+
+func marshalKey(key []byte) []byte {
+	b := protowire.AppendTag(nil, 1, protowire.BytesType)
+	return protowire.AppendBytes(b, key)
+}
+
+// walkProto calls fn with every length-delimited field of a protobuf message.
+// Fields of other wire types are skipped.
+func walkProto(data []byte, fn func(num protowire.Number, value []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+		value, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if err := fn(num, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
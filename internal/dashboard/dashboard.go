@@ -0,0 +1,227 @@
+// File: internal/dashboard/dashboard.go
+package dashboard
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"vault.module/internal/display"
+	"vault.module/internal/vault"
+)
+
+// Address is the public part of a vault address
+type Address struct {
+	Index   int    `json:"index"`
+	Path    string `json:"path"`
+	Address string `json:"address"`
+}
+
+// Wallet is the sanitized view of a wallet. It is built field by field rather than
+// by redacting vault.Wallet, so a new secret field can never leak into the page.
+type Wallet struct {
+	Prefix         string    `json:"prefix"`
+	HD             bool      `json:"hd"`
+	DerivationPath string    `json:"derivation_path,omitempty"`
+	HasNotes       bool      `json:"has_notes"`
+	ChecklistDone  int       `json:"checklist_done"`
+	ChecklistTotal int       `json:"checklist_total"`
+	Addresses      []Address `json:"addresses"`
+}
+
+// Stats summarizes the vault
+type Stats struct {
+	Wallets   int `json:"wallets"`
+	HD        int `json:"hd_wallets"`
+	Addresses int `json:"addresses"`
+}
+
+// Snapshot is the data served by the dashboard, taken once when it starts
+type Snapshot struct {
+	Vault      string    `json:"vault"`
+	Type       string    `json:"type"`
+	Encryption string    `json:"encryption"`
+	TakenAt    time.Time `json:"taken_at"`
+	Stats      Stats     `json:"stats"`
+	Wallets    []Wallet  `json:"wallets"`
+}
+
+// NewSnapshot copies the public fields of v
+func NewSnapshot(name, vaultType, encryption string, v vault.Vault) Snapshot {
+	s := Snapshot{
+		Vault:      name,
+		Type:       vaultType,
+		Encryption: encryption,
+		TakenAt:    time.Now().UTC(),
+	}
+	for prefix, w := range v {
+		hd := w.IsHD()
+		dw := Wallet{
+			Prefix:         prefix,
+			HD:             hd,
+			DerivationPath: w.DerivationPath,
+			HasNotes:       w.Notes != "",
+		}
+		dw.ChecklistDone, dw.ChecklistTotal = w.ChecklistProgress()
+		for _, a := range w.Addresses {
+			dw.Addresses = append(dw.Addresses, Address{Index: a.Index, Path: a.Path, Address: a.Address})
+		}
+		sort.Slice(dw.Addresses, func(i, j int) bool { return dw.Addresses[i].Index < dw.Addresses[j].Index })
+		s.Wallets = append(s.Wallets, dw)
+		s.Stats.Wallets++
+		s.Stats.Addresses += len(dw.Addresses)
+		if hd {
+			s.Stats.HD++
+		}
+	}
+	sort.Slice(s.Wallets, func(i, j int) bool { return s.Wallets[i].Prefix < s.Wallets[j].Prefix })
+	return s
+}
+
+// Server serves the state of a store on a loopback address, guarded by a
+// bearer token
+type Server struct {
+	Store *Store
+	Token string
+}
+
+// ListenAndServe serves on addr, which must be a loopback address, until ctx is done
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return errors.New("dashboard may only listen on a loopback address")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.guard(s.handleIndex))
+	mux.HandleFunc("/api/vault", s.guard(s.handleVault))
+	mux.HandleFunc("/api/audit", s.guard(s.handleAudit))
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// guard rejects requests with a foreign Host header (DNS rebinding) or a wrong token,
+// and sets headers that keep the page out of caches, frames and referrers.
+func (s *Server) guard(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if host != "localhost" && !isLoopbackIP(host) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		token := r.URL.Query().Get("token")
+		if auth := r.Header.Get("Authorization"); len(auth) > 7 && auth[:7] == "Bearer " {
+			token = auth[7:]
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func isLoopbackIP(host string) bool {
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// VaultResponse is the body of /api/vault
+type VaultResponse struct {
+	Version        int        `json:"version"`
+	Stale          bool       `json:"stale"`
+	ChangedAt      *time.Time `json:"changed_at,omitempty"`
+	ReauthRequired bool       `json:"reauth_required"`
+	Snapshot
+}
+
+func (s *Server) handleVault(w http.ResponseWriter, r *http.Request) {
+	state := s.Store.State()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(VaultResponse{state.Version, state.Stale, state.ChangedAt, state.ReauthRequired, state.Snapshot})
+}
+
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.Store.State().Audit)
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = indexTemplate.Execute(w, s.Store.State())
+}
+
+var indexTemplate = template.Must(template.New("index").Funcs(template.FuncMap{"when": display.Time}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>vault.module - {{.Snapshot.Vault}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+code, pre { font-family: monospace; }
+pre { background: #f4f4f4; padding: 1em; overflow-x: auto; font-size: 12px; }
+</style>
+</head>
+<body>
+{{with .Snapshot}}<h1>Vault {{.Vault}}</h1>
+<p>Type: {{.Type}} &middot; Encryption: {{.Encryption}} &middot; Snapshot: {{when .TakenAt}}</p>
+<p>{{.Stats.Wallets}} wallets ({{.Stats.HD}} HD) &middot; {{.Stats.Addresses}} addresses</p>{{end}}
+{{if .ReauthRequired}}<p><strong>The vault was modified externally and must be re-verified with 'vaults verify'.</strong></p>{{end}}
+{{if .Stale}}<p><strong>The vault file changed {{when .ChangedAt}}; restart the dashboard to see the changes.</strong></p>{{end}}
+<h2>Wallets</h2>
+<table>
+<tr><th>Prefix</th><th>Kind</th><th>Index</th><th>Path</th><th>Address</th><th>Notes</th><th>Checklist</th></tr>
+{{range $w := .Snapshot.Wallets}}{{range $a := $w.Addresses}}
+<tr><td>{{$w.Prefix}}</td><td>{{if $w.HD}}HD{{else}}single key{{end}}</td><td>{{$a.Index}}</td><td><code>{{$a.Path}}</code></td><td><code>{{$a.Address}}</code></td><td>{{if $w.HasNotes}}yes{{end}}</td><td>{{if $w.ChecklistTotal}}{{$w.ChecklistDone}}/{{$w.ChecklistTotal}}{{end}}</td></tr>
+{{end}}{{end}}
+</table>
+<h2>Audit log (latest)</h2>
+<pre>{{range .Audit}}{{.}}
+{{end}}</pre>
+</body>
+</html>
+`))
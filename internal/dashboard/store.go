@@ -0,0 +1,231 @@
+// File: internal/dashboard/store.go
+package dashboard
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// StoreVersion is the version of the messages and the state a Store publishes.
+// It is served with the state so clients can tell an incompatible change.
+const StoreVersion = 1
+
+// Message is a change published by a Store to its subscribers
+type Message interface {
+	storeMessage()
+}
+
+// VaultLoaded replaces the snapshot, after the vault was decrypted
+type VaultLoaded struct {
+	Snapshot Snapshot
+}
+
+// VaultChanged reports that the vault file changed on disk after the snapshot
+// was taken, so the snapshot is stale until the next VaultLoaded
+type VaultChanged struct {
+	Path string
+	At   time.Time
+}
+
+// AuditAppended carries new lines of the audit log
+type AuditAppended struct {
+	Lines []string
+}
+
+// AuthStateChanged reports whether the vault needs re-authentication: a tamper
+// marker was left by 'vaults watch' and not yet cleared by 'vaults verify'
+type AuthStateChanged struct {
+	ReauthRequired bool
+}
+
+func (VaultLoaded) storeMessage()      {}
+func (VaultChanged) storeMessage()     {}
+func (AuditAppended) storeMessage()    {}
+func (AuthStateChanged) storeMessage() {}
+
+// State is what the store holds: the result of every message published so far
+type State struct {
+	Version        int        `json:"version"`
+	Snapshot       Snapshot   `json:"snapshot"`
+	Stale          bool       `json:"stale"`
+	ChangedAt      *time.Time `json:"changed_at,omitempty"`
+	ReauthRequired bool       `json:"reauth_required"`
+	Audit          []string   `json:"audit"`
+}
+
+// Store holds the dashboard's state and publishes its changes as messages.
+// Views read State or subscribe instead of re-reading the vault and the audit
+// log themselves.
+type Store struct {
+	mu          sync.RWMutex
+	state       State
+	auditLines  int
+	subscribers map[int]chan Message
+	next        int
+}
+
+// NewStore returns a store holding snapshot and keeping the last auditLines
+// lines of the audit log
+func NewStore(snapshot Snapshot, auditLines int) *Store {
+	return &Store{
+		state:       State{Version: StoreVersion, Snapshot: snapshot},
+		auditLines:  auditLines,
+		subscribers: map[int]chan Message{},
+	}
+}
+
+// State returns a copy of the current state
+func (s *Store) State() State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state := s.state
+	state.Audit = append([]string(nil), s.state.Audit...)
+	return state
+}
+
+// Publish applies m to the state and passes it to every subscriber. A
+// subscriber whose buffer is full misses the message rather than blocking the
+// store; it can catch up with State.
+func (s *Store) Publish(m Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch m := m.(type) {
+	case VaultLoaded:
+		s.state.Snapshot = m.Snapshot
+		s.state.Stale = false
+		s.state.ChangedAt = nil
+	case VaultChanged:
+		at := m.At
+		s.state.Stale = true
+		s.state.ChangedAt = &at
+	case AuditAppended:
+		s.state.Audit = append(s.state.Audit, m.Lines...)
+		if len(s.state.Audit) > s.auditLines {
+			s.state.Audit = s.state.Audit[len(s.state.Audit)-s.auditLines:]
+		}
+	case AuthStateChanged:
+		s.state.ReauthRequired = m.ReauthRequired
+	}
+	for _, ch := range s.subscribers {
+		select {
+		case ch <- m:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the messages published from now on,
+// and a function that ends the subscription and closes the channel
+func (s *Store) Subscribe(buffer int) (<-chan Message, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.next
+	s.next++
+	ch := make(chan Message, buffer)
+	s.subscribers[id] = ch
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			delete(s.subscribers, id)
+			close(ch)
+		})
+	}
+}
+
+// Follow watches the vault file, its tamper marker and the audit log until ctx
+// is done, and publishes their changes. The audit log is read once at the start.
+func (s *Store) Follow(ctx context.Context, keyFile, auditLog string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	// Watch directories so files replaced by rename are seen
+	for _, dir := range []string{filepath.Dir(keyFile), filepath.Dir(auditLog)} {
+		if err := watcher.Add(dir); err != nil {
+			return err
+		}
+	}
+
+	marker := keyFile + ".tampered"
+	_, err = os.Stat(marker)
+	s.Publish(AuthStateChanged{ReauthRequired: err == nil})
+
+	var offset int64
+	readAudit := func() {
+		lines, next := readLinesFrom(auditLog, offset)
+		offset = next
+		if len(lines) > 0 {
+			s.Publish(AuditAppended{Lines: lines})
+		}
+	}
+	readAudit()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			switch filepath.Clean(event.Name) {
+			case filepath.Clean(keyFile):
+				if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+					s.Publish(VaultChanged{Path: keyFile, At: time.Now().UTC()})
+				}
+			case filepath.Clean(marker):
+				if event.Has(fsnotify.Create) {
+					s.Publish(AuthStateChanged{ReauthRequired: true})
+				} else if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+					s.Publish(AuthStateChanged{ReauthRequired: false})
+				}
+			case filepath.Clean(auditLog):
+				readAudit()
+			}
+		}
+	}
+}
+
+// readLinesFrom returns the complete lines of path from offset on, and the
+// offset after them. A file shorter than offset was truncated by rotation and
+// is read from the start.
+func readLinesFrom(path string, offset int64) ([]string, int64) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, offset
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil && info.Size() < offset {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset
+	}
+
+	var lines []string
+	reader := bufio.NewReaderSize(f, 64*1024)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			// A partial last line is read again once it is complete
+			return lines, offset
+		}
+		offset += int64(len(line))
+		lines = append(lines, line[:len(line)-1])
+	}
+}
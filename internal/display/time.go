@@ -0,0 +1,75 @@
+// File: internal/display/time.go
+package display
+
+import (
+	"fmt"
+	"time"
+
+	"vault.module/internal/config"
+)
+
+// Time formats, set with "time_format" in config.json
+const (
+	TimeRelative = "relative" // "3 days ago", "in 2 hours"
+	TimeLocal    = "local"    // 2006-01-02 15:04 in the local time zone
+	TimeUTC      = "utc"      // RFC 3339 as stored
+)
+
+// TimeFormats lists the accepted time formats
+var TimeFormats = []string{TimeRelative, TimeLocal, TimeUTC}
+
+// Time formats t for human output. JSON output keeps RFC 3339 and does not
+// go through here.
+func Time(t time.Time) string {
+	switch config.Cfg.TimeFormat {
+	case TimeLocal:
+		return t.Local().Format("2006-01-02 15:04")
+	case TimeUTC:
+		return t.UTC().Format(time.RFC3339)
+	default:
+		return Relative(t, time.Now())
+	}
+}
+
+// TimeString formats a stored RFC 3339 timestamp for human output. Anything
+// else is returned unchanged.
+func TimeString(s string) string {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return s
+	}
+	return Time(t)
+}
+
+// Relative describes t as seen from now, in the largest whole unit
+func Relative(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var amount int
+	var unit string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		amount, unit = int(d/time.Minute), "minute"
+	case d < 24*time.Hour:
+		amount, unit = int(d/time.Hour), "hour"
+	case d < 30*24*time.Hour:
+		amount, unit = int(d/(24*time.Hour)), "day"
+	case d < 365*24*time.Hour:
+		amount, unit = int(d/(30*24*time.Hour)), "month"
+	default:
+		amount, unit = int(d/(365*24*time.Hour)), "year"
+	}
+	if amount != 1 {
+		unit += "s"
+	}
+	if future {
+		return fmt.Sprintf("in %d %s", amount, unit)
+	}
+	return fmt.Sprintf("%d %s ago", amount, unit)
+}
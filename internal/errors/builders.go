@@ -15,11 +15,23 @@ func NewConfigLoadError(path string, cause error) *VaultError {
 }
 
 func NewConfigSaveError(path string, cause error) *VaultError {
+	// A conflict already tells the user what happened and what to do
+	var conflict *VaultError
+	if AsVaultError(cause, &conflict) && conflict.Code == ErrCodeConfigConflict {
+		return conflict
+	}
 	return Wrap(ErrCodeConfigSave, "failed to save configuration", cause).
 		WithContext("config_path", path).
 		WithSeverity(SeverityError)
 }
 
+func NewConfigConflictError(path string) *VaultError {
+	return Newf(ErrCodeConfigConflict, "configuration was changed by another process").
+		WithDetails("config.json changed after this command read it; run the command again").
+		WithContext("config_path", path).
+		WithSeverity(SeverityWarning)
+}
+
 func NewConfigValidationError(field, value, message string) *VaultError {
 	return Newf(ErrCodeConfigValidation, "configuration validation failed").
 		WithDetails(fmt.Sprintf("field '%s' with value '%s': %s", field, value, message)).
@@ -35,10 +47,23 @@ func NewConfigMissingError(field string) *VaultError {
 }
 
 // Vault Error Builders
+// NewVaultLoadError wraps the cause of a failed load, which the user needs to
+// act on: a wrong passphrase, a keyfile that is not an age file, a vault over
+// the memory budget
 func NewVaultLoadError(path string, cause error) *VaultError {
-	return Wrap(ErrCodeVaultLoad, "failed to load vault", cause).
+	err := Wrap(ErrCodeVaultLoad, "failed to load vault", cause).
 		WithContext("vault_path", path).
 		WithSeverity(SeverityError)
+	var vErr *VaultError
+	switch {
+	case AsVaultError(cause, &vErr) && vErr.Details != "":
+		err.Details = vErr.Message + ": " + vErr.Details
+	case vErr != nil:
+		err.Details = vErr.Message
+	case cause != nil:
+		err.Details = cause.Error()
+	}
+	return err
 }
 
 func NewVaultSaveError(path string, cause error) *VaultError {
@@ -71,6 +96,13 @@ func NewVaultCorruptError(path string, cause error) *VaultError {
 		WithSeverity(SeverityCritical)
 }
 
+func NewVaultTamperedError(name string) *VaultError {
+	return Newf(ErrCodeVaultTampered, "vault '%s' files were modified externally", name).
+		WithDetails(fmt.Sprintf("Run 'vault.module vaults verify %s' to re-authenticate before further operations", name)).
+		WithContext("vault_name", name).
+		WithSeverity(SeverityCritical)
+}
+
 func NewVaultInvalidPathError(path string, cause error) *VaultError {
 	return Wrap(ErrCodeVaultInvalidPath, "invalid vault path", cause).
 		WithContext("path", path).
@@ -130,6 +162,13 @@ func NewAddressNotFoundError(prefix string, index int) *VaultError {
 		WithSeverity(SeverityError)
 }
 
+func NewWalletFrozenError(prefix, reason string) *VaultError {
+	return Newf(ErrCodeWalletFrozen, "wallet '%s' is frozen", prefix).
+		WithDetails(fmt.Sprintf("secret retrieval and signing are blocked (%s); run 'vault.module unfreeze %s' once the hold is lifted", reason, prefix)).
+		WithContext("wallet_prefix", prefix).
+		WithSeverity(SeverityCritical)
+}
+
 // Input Validation Error Builders
 func NewInvalidInputError(input, reason string) *VaultError {
 	return New(ErrCodeInvalidInput, "invalid input provided").
@@ -166,6 +205,32 @@ func NewFileSystemError(operation, path string, cause error) *VaultError {
 		WithSeverity(SeverityError)
 }
 
+func NewDiskFullError(path string, needed, available uint64, cause error) *VaultError {
+	var e *VaultError
+	if cause != nil {
+		e = Wrap(ErrCodeDiskFull, "not enough disk space", cause)
+	} else {
+		e = Newf(ErrCodeDiskFull, "not enough disk space: %d bytes needed, %d available", needed, available)
+	}
+	return e.WithContext("path", path).
+		WithDetails("free some space; the existing file was left untouched").
+		WithSeverity(SeverityError)
+}
+
+func NewMemoryBudgetError(path string, needed, budget uint64) *VaultError {
+	return Newf(ErrCodeMemoryBudget, "decrypting the vault would take about %.1f MB, over the memory budget of %d MB", float64(needed)/(1<<20), budget>>20).
+		WithContext("path", path).
+		WithDetails("move rarely used wallets to an archive vault with 'archive', or raise memory_budget_mb in config.json").
+		WithSeverity(SeverityError)
+}
+
+func NewReadOnlyFSError(path string, cause error) *VaultError {
+	return Wrap(ErrCodeReadOnlyFS, "the directory is not writable", cause).
+		WithContext("path", path).
+		WithDetails("remount it read-write or fix its permissions; the existing file was left untouched").
+		WithSeverity(SeverityError)
+}
+
 func NewPermissionError(path string, cause error) *VaultError {
 	return Wrap(ErrCodePermission, "permission denied", cause).
 		WithContext("path", path).
@@ -192,6 +257,14 @@ func NewTimeoutError(operation string, duration string) *VaultError {
 		WithSeverity(SeverityError)
 }
 
+func NewRateLimitError(scope string, limit int, window string) *VaultError {
+	return Newf(ErrCodeRateLimited, "secret retrieval rate limit exceeded (%s)", scope).
+		WithDetails(fmt.Sprintf("at most %d secret retrievals per %s are allowed", limit, window)).
+		WithContext("scope", scope).
+		WithContext("limit", limit).
+		WithSeverity(SeverityCritical)
+}
+
 // Import/Export Error Builders
 func NewImportFailedError(format, reason string, cause error) *VaultError {
 	return Wrap(ErrCodeImportFailed, fmt.Sprintf("import failed for format '%s'", format), cause).
@@ -2,6 +2,7 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"log/slog"
 )
@@ -15,6 +16,7 @@ const (
 	ErrCodeConfigSave        ErrorCode = "CONFIG_SAVE_FAILED"
 	ErrCodeConfigValidation  ErrorCode = "CONFIG_VALIDATION_FAILED"
 	ErrCodeConfigMissing     ErrorCode = "CONFIG_MISSING"
+	ErrCodeConfigConflict    ErrorCode = "CONFIG_CONFLICT"
 
 	// Vault errors
 	ErrCodeVaultLoad         ErrorCode = "VAULT_LOAD_FAILED"
@@ -24,18 +26,22 @@ const (
 	ErrCodeVaultCorrupt      ErrorCode = "VAULT_CORRUPT"
 	ErrCodeVaultNotFound     ErrorCode = "VAULT_NOT_FOUND"
 	ErrCodeVaultInvalidPath  ErrorCode = "VAULT_INVALID_PATH"
+	ErrCodeVaultTampered     ErrorCode = "VAULT_TAMPERED"
 
 	// Authentication errors
 	ErrCodeAuthFailed        ErrorCode = "AUTH_FAILED"
 	ErrCodeYubikeyNotFound   ErrorCode = "YUBIKEY_NOT_FOUND"
 	ErrCodeYubikeyAuth       ErrorCode = "YUBIKEY_AUTH_FAILED"
 	ErrCodeYubikeyConfig     ErrorCode = "YUBIKEY_CONFIG_ERROR"
+	ErrCodeCanaryTriggered   ErrorCode = "CANARY_TRIGGERED"
+	ErrCodeApprovalPending   ErrorCode = "APPROVAL_PENDING"
 
 	// Wallet errors
 	ErrCodeWalletNotFound    ErrorCode = "WALLET_NOT_FOUND"
 	ErrCodeWalletExists      ErrorCode = "WALLET_EXISTS"
 	ErrCodeWalletInvalid     ErrorCode = "WALLET_INVALID"
 	ErrCodeAddressNotFound   ErrorCode = "ADDRESS_NOT_FOUND"
+	ErrCodeWalletFrozen      ErrorCode = "WALLET_FROZEN"
 
 	// Input validation errors
 	ErrCodeInvalidInput      ErrorCode = "INVALID_INPUT"
@@ -50,6 +56,10 @@ const (
 	ErrCodeDependency        ErrorCode = "DEPENDENCY_MISSING"
 	ErrCodeClipboard         ErrorCode = "CLIPBOARD_ERROR"
 	ErrCodeTimeout           ErrorCode = "TIMEOUT"
+	ErrCodeRateLimited       ErrorCode = "RATE_LIMITED"
+	ErrCodeDiskFull          ErrorCode = "DISK_FULL"
+	ErrCodeReadOnlyFS        ErrorCode = "FILESYSTEM_READ_ONLY"
+	ErrCodeMemoryBudget      ErrorCode = "MEMORY_BUDGET_EXCEEDED"
 
 	// Import/Export errors
 	ErrCodeImportFailed      ErrorCode = "IMPORT_FAILED"
@@ -213,3 +223,31 @@ func GetSeverity(err error) ErrorSeverity {
 	}
 	return SeverityError
 }
+
+// ChildExitError is the cause of the error returned when a command run on the
+// user's behalf, as by 'exec', exits with a non-zero status. main exits with the
+// same status.
+type ChildExitError struct {
+	Program string
+	Code    int
+}
+
+// Error implements the error interface
+func (e *ChildExitError) Error() string {
+	return fmt.Sprintf("%s exited with status %d", e.Program, e.Code)
+}
+
+// NewChildExitError reports the exit status of a child process
+func NewChildExitError(program string, code int) *VaultError {
+	return Wrap(ErrCodeSystem, fmt.Sprintf("command exited with status %d", code), &ChildExitError{Program: program, Code: code}).
+		WithContext("program", program)
+}
+
+// ExitCode returns the status a child process exited with, if it caused err
+func ExitCode(err error) (int, bool) {
+	var childErr *ChildExitError
+	if stderrors.As(err, &childErr) {
+		return childErr.Code, true
+	}
+	return 0, false
+}
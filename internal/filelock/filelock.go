@@ -0,0 +1,35 @@
+// File: internal/filelock/filelock.go
+
+// Package filelock takes advisory locks on open files: flock on Unix,
+// LockFileEx on Windows. Locks are held by the process until Unlock or until
+// the file is closed.
+package filelock
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrLocked is returned by the Try functions when another process holds a
+// conflicting lock
+var ErrLocked = errors.New("file is locked by another process")
+
+// Lock takes an exclusive lock on f, waiting for other holders to release it
+func Lock(f *os.File) error {
+	return lock(f, true, true)
+}
+
+// TryLock takes an exclusive lock on f, or returns ErrLocked at once
+func TryLock(f *os.File) error {
+	return lock(f, true, false)
+}
+
+// TryRLock takes a shared lock on f, or returns ErrLocked at once
+func TryRLock(f *os.File) error {
+	return lock(f, false, false)
+}
+
+// Unlock releases the lock on f
+func Unlock(f *os.File) error {
+	return unlock(f)
+}
@@ -0,0 +1,40 @@
+//go:build windows
+// +build windows
+
+// File: internal/filelock/filelock_windows.go
+package filelock
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// Windows byte-range locks are mandatory: a lock on the data would fail the
+// reads of other processes, such as age decrypting a locked vault. The lock is
+// taken on a byte far past the end of any file instead, as flock would be.
+const (
+	lockOffsetHigh = 0x7fffffff
+	lockLength     = 1
+)
+
+func lock(f *os.File, exclusive, wait bool) error {
+	var flags uint32
+	if exclusive {
+		flags |= windows.LOCKFILE_EXCLUSIVE_LOCK
+	}
+	if !wait {
+		flags |= windows.LOCKFILE_FAIL_IMMEDIATELY
+	}
+	ol := &windows.Overlapped{OffsetHigh: lockOffsetHigh}
+	err := windows.LockFileEx(windows.Handle(f.Fd()), flags, 0, lockLength, 0, ol)
+	if err == windows.ERROR_LOCK_VIOLATION || err == windows.ERROR_IO_PENDING {
+		return ErrLocked
+	}
+	return err
+}
+
+func unlock(f *os.File) error {
+	ol := &windows.Overlapped{OffsetHigh: lockOffsetHigh}
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, lockLength, 0, ol)
+}
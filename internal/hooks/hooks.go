@@ -0,0 +1,172 @@
+// File: internal/hooks/hooks.go
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/user"
+	"strings"
+	"time"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/budget"
+	"vault.module/internal/config"
+)
+
+// Hook points scripts can be attached to
+const (
+	PreSave       = "pre-save"        // Before a vault is written; a failing script aborts the save
+	PostImport    = "post-import"     // After wallets were imported and saved
+	PostGetSecret = "post-get-secret" // After a mnemonic or private key was retrieved
+)
+
+// Points lists the hook points, in the order of an operation
+var Points = []string{PreSave, PostImport, PostGetSecret}
+
+// EnvHook is set to the hook point in the environment of a hook script. Hooks
+// do not run while it is set, so a script calling vault.module does not
+// trigger hooks again.
+const EnvHook = "VAULT_MODULE_HOOK"
+
+// DefaultTimeout bounds a hook script that sets no timeout of its own
+const DefaultTimeout = 30 * time.Second
+
+// maxStderr bounds the output of a failed script kept in its error
+const maxStderr = 512
+
+// Command names the command that is running, for the events of its hooks
+var Command string
+
+// Event is the JSON payload a hook script reads on stdin. It never contains
+// secrets: wallets are named by their prefix, fields by their name.
+type Event struct {
+	Hook      string    `json:"hook"`
+	Command   string    `json:"command,omitempty"`
+	Vault     string    `json:"vault"`
+	Prefix    string    `json:"prefix,omitempty"`
+	Prefixes  []string  `json:"prefixes,omitempty"`
+	Field     string    `json:"field,omitempty"`
+	Details   string    `json:"details,omitempty"`
+	User      string    `json:"user,omitempty"`
+	Operator  string    `json:"operator,omitempty"`
+	Host      string    `json:"host,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Configured reports whether any hook is attached to point for the vault
+func Configured(point, vaultName string) bool {
+	for _, hook := range config.Cfg.Hooks {
+		if applies(hook, point, vaultName) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run runs every configured script attached to the event's hook point, one
+// after the other, with the event on stdin. The returned errors are one per
+// failed script.
+func Run(ev Event) []error {
+	if os.Getenv(EnvHook) != "" || !Configured(ev.Hook, ev.Vault) {
+		return nil
+	}
+	if ev.Command == "" {
+		ev.Command = Command
+	}
+	if ev.Timestamp.IsZero() {
+		ev.Timestamp = time.Now().UTC()
+	}
+	if ev.Operator == "" {
+		ev.Operator = config.Cfg.Operator
+	}
+	if ev.Host == "" {
+		ev.Host, _ = os.Hostname()
+	}
+	if ev.User == "" {
+		if u, err := user.Current(); err == nil {
+			ev.User = u.Username
+		}
+	}
+
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, hook := range config.Cfg.Hooks {
+		if !applies(hook, ev.Hook, ev.Vault) {
+			continue
+		}
+		start := time.Now()
+		err := run(hook, ev.Hook, payload)
+		attrs := []any{
+			slog.String("hook", ev.Hook),
+			slog.String("script", hook.Command),
+			slog.String("vault", ev.Vault),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if err != nil {
+			audit.Logger.Warn("Hook script failed", append(attrs, slog.String("error", err.Error()))...)
+			errs = append(errs, fmt.Errorf("%s hook %s: %w", ev.Hook, hook.Command, err))
+			continue
+		}
+		audit.Logger.Info("Hook script ran", attrs...)
+	}
+	return errs
+}
+
+// applies reports whether hook is attached to point for the vault; an empty
+// vault list means every vault
+func applies(hook config.Hook, point, vaultName string) bool {
+	if hook.Point != point || hook.Command == "" {
+		return false
+	}
+	if len(hook.Vaults) == 0 {
+		return true
+	}
+	for _, name := range hook.Vaults {
+		if name == vaultName || name == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// run runs one hook script with payload on stdin. Its stdout is discarded;
+// its stderr is part of the error when it fails.
+func run(hook config.Hook, point string, payload []byte) error {
+	timeout := DefaultTimeout
+	if hook.Timeout > 0 {
+		timeout = time.Duration(hook.Timeout) * time.Second
+	}
+	ctx, cancel := budget.WithTimeout(timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, hook.Command, hook.Args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), EnvHook+"="+point)
+	// Children of a killed script must not keep it waiting on its stderr
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("timed out after %s", timeout)
+		}
+		output := strings.TrimSpace(stderr.String())
+		if len(output) > maxStderr {
+			output = output[:maxStderr] + "..."
+		}
+		if output != "" {
+			return fmt.Errorf("%v: %s", err, output)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// File: internal/inheritance/inheritance.go
+package inheritance
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"vault.module/internal/config"
+	"vault.module/internal/vault"
+)
+
+// TimeLockTool is the drand tlock CLI used for time-locked packages
+const TimeLockTool = "tle"
+
+// Encrypt encrypts plaintext so that any one of recipients can decrypt it. With a
+// non-zero unlockAfter, the plaintext is first time-locked with drand tlock, so
+// the package cannot be opened before that time even with a recipient's key.
+func Encrypt(ctx context.Context, plaintext []byte, recipients []string, unlockAfter time.Time) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+	data := plaintext
+	if !unlockAfter.IsZero() {
+		days := int(math.Ceil(time.Until(unlockAfter).Hours() / 24))
+		if days < 1 {
+			return nil, fmt.Errorf("unlock date must be in the future")
+		}
+		locked, err := run(ctx, data, TimeLockTool, "--encrypt", "--armor", "--duration", fmt.Sprintf("%dd", days))
+		if err != nil {
+			return nil, err
+		}
+		data = locked
+	}
+
+	args := []string{"--armor"}
+	for _, r := range recipients {
+		args = append(args, "-r", r)
+	}
+	return run(ctx, data, "age", args...)
+}
+
+func run(ctx context.Context, stdin []byte, tool string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, tool, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed: %v: %s", tool, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// FileDigest returns the hex SHA-256 of the file at path
+func FileDigest(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// Problems returns the reasons the package recorded in inh is out of date: the
+// vault has changed since it was prepared, or the owner's check-in is overdue.
+func Problems(inh *config.Inheritance, keyFile string, now time.Time) []string {
+	var problems []string
+	if digest, err := FileDigest(keyFile); err == nil && digest != inh.VaultDigest {
+		problems = append(problems, "the vault has changed since the inheritance package was prepared")
+	}
+	if _, err := os.Stat(inh.Package); err != nil {
+		problems = append(problems, fmt.Sprintf("the inheritance package %s is missing", inh.Package))
+	}
+	if due, ok := NextCheckIn(inh); ok && now.After(due) {
+		problems = append(problems, fmt.Sprintf("the owner check-in was due on %s", due.Format(time.DateOnly)))
+	}
+	return problems
+}
+
+// NextCheckIn returns when the next owner check-in is due, if check-ins are enabled
+func NextCheckIn(inh *config.Inheritance) (time.Time, bool) {
+	if inh.CheckInDays <= 0 {
+		return time.Time{}, false
+	}
+	last := inh.LastCheckIn
+	if last == "" {
+		last = inh.CreatedAt
+	}
+	t, err := time.Parse(time.RFC3339, last)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t.AddDate(0, 0, inh.CheckInDays), true
+}
+
+// Instructions renders the document that accompanies the package. It lists only
+// public data: the wallets, their addresses and how to open the package.
+func Instructions(vaultName, vaultType string, v vault.Vault, inh *config.Inheritance, packageName, packageDigest string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Inheritance package for vault \"%s\"\n\n", vaultName)
+	fmt.Fprintf(&b, "Prepared: %s\n", inh.CreatedAt)
+	fmt.Fprintf(&b, "Package:  %s (SHA-256 %s)\n", packageName, packageDigest)
+	if inh.UnlockAfter != "" {
+		fmt.Fprintf(&b, "Time lock: cannot be opened before %s\n", inh.UnlockAfter)
+	}
+	b.WriteString("\nThe package can be opened by the holder of any one of these age keys:\n\n")
+	for _, r := range inh.Recipients {
+		fmt.Fprintf(&b, "- %s\n", r)
+	}
+
+	fmt.Fprintf(&b, "\n## Contents\n\nAn export of %d %s wallet(s) with their private keys and mnemonic phrases:\n\n", len(v), vaultType)
+	prefixes := make([]string, 0, len(v))
+	for prefix := range v {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		w := v[prefix]
+		fmt.Fprintf(&b, "- %s", prefix)
+		if w.Notes != "" {
+			fmt.Fprintf(&b, " (%s)", w.Notes)
+		}
+		b.WriteString("\n")
+		for _, a := range w.Addresses {
+			fmt.Fprintf(&b, "  - %s\n", a.Address)
+		}
+	}
+
+	b.WriteString("\n## Opening the package\n\n")
+	b.WriteString("1. On an offline computer, install age: https://github.com/FiloSottile/age\n")
+	if inh.UnlockAfter != "" {
+		b.WriteString("   and tle (drand tlock): https://github.com/drand/tlock\n")
+		b.WriteString("   Decrypting the time lock needs network access to the drand beacon once the date has passed.\n")
+		fmt.Fprintf(&b, "2. Run: age -d -i <your-age-identity> %s | tle --decrypt > wallets.json\n", packageName)
+	} else {
+		fmt.Fprintf(&b, "2. Run: age -d -i <your-age-identity> %s > wallets.json\n", packageName)
+	}
+	b.WriteString("3. wallets.json holds the private keys and mnemonic phrases. Import them with\n")
+	b.WriteString("   'vault.module import wallets.json' or any compatible wallet, move the funds,\n")
+	b.WriteString("   then securely delete wallets.json.\n")
+	b.WriteString("\nAnyone who can read wallets.json controls the funds. Never share it or enter it online.\n")
+	return b.String()
+}
@@ -0,0 +1,122 @@
+// File: internal/keys/consensus.go
+package keys
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	cmted25519 "github.com/cometbft/cometbft/crypto/ed25519"
+	"vault.module/internal/security"
+	"vault.module/internal/vault"
+)
+
+// ConsensusKeyPath is the path of the address of a consensus wallet, which is
+// not derived from a seed
+const ConsensusKeyPath = "consensus"
+
+// Amino type names of the keys in priv_validator_key.json
+const (
+	consensusPubKeyType  = "tendermint/PubKeyEd25519"
+	consensusPrivKeyType = "tendermint/PrivKeyEd25519"
+)
+
+// privValidatorKey is the priv_validator_key.json of a CometBFT node
+type privValidatorKey struct {
+	Address string   `json:"address"`
+	PubKey  typedKey `json:"pub_key"`
+	PrivKey typedKey `json:"priv_key"`
+}
+
+type typedKey struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// ParsePrivValidatorKey reads a priv_validator_key.json into a consensus
+// wallet. The key's seed is stored as hex, like other private keys; the
+// address and public key in the file must match it.
+func ParsePrivValidatorKey(data []byte) (vault.Wallet, error) {
+	var file privValidatorKey
+	if err := json.Unmarshal(data, &file); err != nil {
+		return vault.Wallet{}, fmt.Errorf("not a priv_validator_key.json: %v", err)
+	}
+	if file.PrivKey.Type != consensusPrivKeyType {
+		if file.PrivKey.Type == "" {
+			return vault.Wallet{}, fmt.Errorf("not a priv_validator_key.json: no priv_key")
+		}
+		return vault.Wallet{}, fmt.Errorf("key type %s is not supported, only %s", file.PrivKey.Type, consensusPrivKeyType)
+	}
+	raw, err := base64.StdEncoding.DecodeString(file.PrivKey.Value)
+	if err != nil || len(raw) != ed25519.PrivateKeySize {
+		security.SecureZero(raw)
+		return vault.Wallet{}, fmt.Errorf("priv_key is not a base64 ed25519 private key")
+	}
+	defer security.SecureZero(raw)
+
+	seed := raw[:ed25519.SeedSize]
+	privKey := ed25519.NewKeyFromSeed(seed)
+	defer security.SecureZero(privKey)
+	if !bytes.Equal(privKey, raw) {
+		return vault.Wallet{}, fmt.Errorf("the public half of priv_key does not belong to its seed")
+	}
+	pubKey := cmted25519.PubKey(privKey.Public().(ed25519.PublicKey))
+	if file.PubKey.Value != "" && file.PubKey.Value != base64.StdEncoding.EncodeToString(pubKey) {
+		return vault.Wallet{}, fmt.Errorf("pub_key does not match priv_key")
+	}
+	address := pubKey.Address().String()
+	if file.Address != "" && !strings.EqualFold(file.Address, address) {
+		return vault.Wallet{}, fmt.Errorf("address %s does not match priv_key (%s)", file.Address, address)
+	}
+
+	return vault.Wallet{
+		Kind: vault.KindConsensus,
+		Addresses: []vault.Address{
+			{
+				Index:      0,
+				Path:       ConsensusKeyPath,
+				Address:    address,
+				PrivateKey: security.NewSecureString(fmt.Sprintf("%X", seed)),
+			},
+		},
+	}, nil
+}
+
+// MarshalPrivValidatorKey writes the priv_validator_key.json of a consensus
+// key, as a CometBFT node expects it
+func MarshalPrivValidatorKey(privateKey string) ([]byte, error) {
+	seed, err := hex.DecodeString(strings.TrimPrefix(privateKey, "0x"))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		security.SecureZero(seed)
+		return nil, fmt.Errorf("not an ed25519 consensus key")
+	}
+	privKey := ed25519.NewKeyFromSeed(seed)
+	security.SecureZero(seed)
+	defer security.SecureZero(privKey)
+	pubKey := cmted25519.PubKey(privKey.Public().(ed25519.PublicKey))
+
+	file := privValidatorKey{
+		Address: pubKey.Address().String(),
+		PubKey:  typedKey{Type: consensusPubKeyType, Value: base64.StdEncoding.EncodeToString(pubKey)},
+		PrivKey: typedKey{Type: consensusPrivKeyType, Value: base64.StdEncoding.EncodeToString(privKey)},
+	}
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// ConsensusPubKey returns the base64 public key of a consensus key, as
+// 'tendermint show-validator' prints it
+func ConsensusPubKey(privateKey string) (string, error) {
+	seed, err := hex.DecodeString(strings.TrimPrefix(privateKey, "0x"))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		security.SecureZero(seed)
+		return "", fmt.Errorf("not an ed25519 consensus key")
+	}
+	privKey := ed25519.NewKeyFromSeed(seed)
+	security.SecureZero(seed)
+	defer security.SecureZero(privKey)
+	return base64.StdEncoding.EncodeToString(privKey.Public().(ed25519.PublicKey)), nil
+}
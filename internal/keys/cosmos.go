@@ -2,7 +2,10 @@
 package keys
 
 import (
+	"encoding/hex"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	"github.com/cosmos/go-bip39"
@@ -65,28 +68,68 @@ func (m *CosmosManager) CreateWalletFromMnemonic(mnemonic string) (vault.Wallet,
 	return wallet, nil
 }
 
-// CreateWalletFromPrivateKey is not supported for Cosmos in this implementation
-// as it's less common and secure than using mnemonics.
+// CreateWalletFromPrivateKey creates a single-key Cosmos wallet from a hex
+// secp256k1 private key, as held by the Cosmos SDK keyring.
 func (m *CosmosManager) CreateWalletFromPrivateKey(pk string) (vault.Wallet, error) {
-	return vault.Wallet{}, fmt.Errorf("creating from a raw private key is not supported for Cosmos wallets; please use a mnemonic")
+	if !m.ValidatePrivateKey(pk) {
+		return vault.Wallet{}, fmt.Errorf("the provided private key is invalid")
+	}
+	privKeyBytes, err := hex.DecodeString(strings.TrimPrefix(pk, "0x"))
+	if err != nil {
+		return vault.Wallet{}, fmt.Errorf("failed to process private key: %s", err.Error())
+	}
+	privKey := secp256k1.PrivKey(privKeyBytes)
+	address := privKey.PubKey().Address().String()
+
+	privateKeyStr := fmt.Sprintf("%X", privKeyBytes)
+	privateKeySecure := security.NewSecureString(privateKeyStr)
+
+	privateKeyStrBytes := []byte(privateKeyStr)
+	security.SecureClearBytes(privateKeyStrBytes)
+	privateKeyStr = ""
+	for i := range privKeyBytes {
+		privKeyBytes[i] = 0
+	}
+
+	wallet := vault.Wallet{
+		Addresses: []vault.Address{
+			{
+				Index:      0,
+				Path:       "imported",
+				Address:    address,
+				PrivateKey: privateKeySecure,
+			},
+		},
+	}
+	return wallet, nil
 }
 
-// DeriveNextAddress derives the next address for a Cosmos HD wallet.
+// DeriveNextAddress derives the next address in the path scheme of a Cosmos HD wallet.
 func (m *CosmosManager) DeriveNextAddress(wallet vault.Wallet) (vault.Wallet, vault.Address, error) {
-	if wallet.Mnemonic == nil || wallet.Mnemonic.IsEmpty() {
-		return wallet, vault.Address{}, fmt.Errorf("derivation is only possible for HD wallets (with a mnemonic)")
-	}
+	account, index := wallet.SchemePosition(wallet.SchemeLength())
+	return m.DeriveAddress(wallet, account, index)
+}
 
-	nextIndex := len(wallet.Addresses)
-	path := fmt.Sprintf("%s/%d", wallet.DerivationPath, nextIndex)
+// DeriveAddress derives the address at the given account and index for a Cosmos HD wallet.
+func (m *CosmosManager) DeriveAddress(wallet vault.Wallet, account, index int) (vault.Wallet, vault.Address, error) {
+	if !wallet.IsHD() {
+		return wallet, vault.Address{}, fmt.Errorf("derivation is only possible for HD wallets (with a mnemonic or seed)")
+	}
+	chainPath, err := AccountChainPath(wallet.DerivationPath, account)
+	if err != nil {
+		return wallet, vault.Address{}, err
+	}
+	if wallet.AddressAt(account, index) != nil {
+		return wallet, vault.Address{}, fmt.Errorf("address %d of account %d is already in the wallet", index, account)
+	}
+	path := fmt.Sprintf("%s/%d", chainPath, index)
 
-	// Use WithValue to safely access mnemonic
-	var privKey secp256k1.PrivKey
-	var err error
-	err = wallet.Mnemonic.WithValue(func(mnemonicStr string) error {
-		privKey, err = deriveCosmosPrivateKey(mnemonicStr, path)
-		return err
-	})
+	seed, err := walletSeed(wallet)
+	if err != nil {
+		return wallet, vault.Address{}, err
+	}
+	privKey, err := deriveCosmosPrivateKeyFromSeed(seed, path)
+	zeroBytes(seed)
 	if err != nil {
 		return wallet, vault.Address{}, err
 	}
@@ -110,7 +153,8 @@ func (m *CosmosManager) DeriveNextAddress(wallet vault.Wallet) (vault.Wallet, va
 
 	// Create new address structure
 	newAddress := vault.Address{
-		Index:      nextIndex,
+		Index:      index,
+		Account:    account,
 		Path:       path,
 		Address:    address,
 		PrivateKey: privateKeySecure,
@@ -125,6 +169,7 @@ func (m *CosmosManager) DeriveNextAddress(wallet vault.Wallet) (vault.Wallet, va
 	}()
 
 	wallet.Addresses = append(wallet.Addresses, newAddress)
+	wallet.SortAddresses()
 	return wallet, newAddress, nil
 }
 
@@ -133,9 +178,10 @@ func (m *CosmosManager) ValidateMnemonic(mnemonic string) bool {
 	return bip39.IsMnemonicValid(mnemonic)
 }
 
-// ValidatePrivateKey is not applicable for Cosmos in this context.
+// ValidatePrivateKey checks the format of a hex secp256k1 private key.
 func (m *CosmosManager) ValidatePrivateKey(pk string) bool {
-	return false
+	match, _ := regexp.MatchString(`^(0x)?[0-9a-fA-F]{64}$`, pk)
+	return match
 }
 
 // --- Cosmos Helper Functions ---
@@ -145,6 +191,11 @@ func deriveCosmosPrivateKey(mnemonic, path string) (secp256k1.PrivKey, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer zeroBytes(seed)
+	return deriveCosmosPrivateKeyFromSeed(seed, path)
+}
+
+func deriveCosmosPrivateKeyFromSeed(seed []byte, path string) (secp256k1.PrivKey, error) {
 	master, ch := hd.ComputeMastersFromSeed(seed)
 	derived, err := hd.DerivePrivateKeyForPath(master, ch, path)
 	if err != nil {
@@ -0,0 +1,168 @@
+// File: internal/keys/entropy.go
+package keys
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+	"vault.module/internal/vault"
+)
+
+// knownWeakMnemonics are published test vectors and development-tool defaults.
+// Funds sent to their addresses are swept by bots within seconds.
+var knownWeakMnemonics = map[string]string{
+	strings.Repeat("abandon ", 11) + "about":                                          "BIP-39 test vector",
+	strings.Repeat("abandon ", 17) + "agent":                                          "BIP-39 test vector",
+	strings.Repeat("abandon ", 23) + "art":                                            "BIP-39 test vector",
+	"legal winner thank year wave sausage worth useful legal winner thank yellow":     "BIP-39 test vector",
+	"letter advice cage absurd amount doctor acoustic avoid letter advice cage above": "BIP-39 test vector",
+	strings.Repeat("zoo ", 11) + "wrong":                                              "BIP-39 test vector",
+	strings.Repeat("zoo ", 23) + "vote":                                               "BIP-39 test vector",
+	"test test test test test test test test test test test junk":                     "Hardhat/Foundry default",
+	"myth like bonus scare over problem client lizard pioneer submit female collect":  "Ganache default",
+	"candy maple cake sugar pudding cream honey rich smooth crumble sweet treat":      "Truffle/Ganache default",
+}
+
+// knownWeakPrivateKeys are private keys published in documentation and tooling
+var knownWeakPrivateKeys = map[string]string{
+	"ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80": "Hardhat/Anvil default account #0",
+	"59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d": "Hardhat/Anvil default account #1",
+	"5de4111afa1a4b94908f83103eb1f1706367c2e68ca870fc3fb9a804cdab365a": "Hardhat/Anvil default account #2",
+	"4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318": "web3.js documentation example",
+	"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140": "secp256k1 order minus one",
+}
+
+// brainwalletPhrases are common passphrases whose SHA-256 has been used as a private key
+var brainwalletPhrases = []string{
+	"", "password", "passphrase", "123456", "12345678", "bitcoin", "ethereum", "satoshi",
+	"satoshi nakamoto", "hello", "hello world", "test", "secret", "wallet", "crypto",
+	"correct horse battery staple", "the quick brown fox jumps over the lazy dog",
+}
+
+// AnalyzeWallet checks the mnemonic or seed or, for single-key wallets, the private
+// keys of a wallet for known test vectors and patterns of low entropy. It returns
+// one description per problem found.
+func AnalyzeWallet(w vault.Wallet) []string {
+	if w.Mnemonic != nil && !w.Mnemonic.IsEmpty() {
+		return AnalyzeMnemonic(w.Mnemonic.String())
+	}
+	if w.Seed != nil && !w.Seed.IsEmpty() {
+		if seed, err := hex.DecodeString(w.Seed.String()); err == nil {
+			defer zeroBytes(seed)
+			if issue := lowEntropyPattern(seed); issue != "" {
+				return []string{"seed " + issue}
+			}
+		}
+		return nil
+	}
+	var issues []string
+	for _, addr := range w.Addresses {
+		if addr.PrivateKey == nil || addr.PrivateKey.IsEmpty() {
+			continue
+		}
+		for _, issue := range AnalyzePrivateKey(addr.PrivateKey.String()) {
+			issues = append(issues, fmt.Sprintf("address %d: %s", addr.Index, issue))
+		}
+	}
+	return issues
+}
+
+// AnalyzeMnemonic flags well-known mnemonics, repeated words and low-entropy seeds
+func AnalyzeMnemonic(mnemonic string) []string {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	normalized := strings.Join(words, " ")
+	if source, ok := knownWeakMnemonics[normalized]; ok {
+		return []string{fmt.Sprintf("publicly known mnemonic (%s)", source)}
+	}
+
+	var issues []string
+	counts := make(map[string]int, len(words))
+	maxRepeat := 0
+	for _, w := range words {
+		counts[w]++
+		if counts[w] > maxRepeat {
+			maxRepeat = counts[w]
+		}
+	}
+	// One repeated word is common in random mnemonics; more is not
+	if maxRepeat >= 3 || len(words)-len(counts) >= 3 {
+		issues = append(issues, fmt.Sprintf("only %d distinct words out of %d", len(counts), len(words)))
+	}
+	if entropy, err := bip39.EntropyFromMnemonic(normalized); err == nil {
+		if issue := lowEntropyPattern(entropy); issue != "" {
+			issues = append(issues, "seed entropy "+issue)
+		}
+	}
+	return issues
+}
+
+// AnalyzePrivateKey flags published keys, brainwallet keys, small scalars and
+// repetitive byte patterns
+func AnalyzePrivateKey(pk string) []string {
+	clean := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(pk), "0x"))
+	if source, ok := knownWeakPrivateKeys[clean]; ok {
+		return []string{fmt.Sprintf("publicly known private key (%s)", source)}
+	}
+	key, err := hex.DecodeString(clean)
+	if err != nil {
+		return nil
+	}
+	for _, phrase := range brainwalletPhrases {
+		sum := sha256.Sum256([]byte(phrase))
+		if hex.EncodeToString(sum[:]) == clean {
+			return []string{fmt.Sprintf("brainwallet key: SHA-256 of %q", phrase)}
+		}
+	}
+
+	var issues []string
+	// A random 256-bit key is below 2^128 with probability 2^-128
+	if new(big.Int).SetBytes(key).BitLen() <= 128 {
+		issues = append(issues, "key value is far too small to be random")
+	}
+	if issue := lowEntropyPattern(key); issue != "" {
+		issues = append(issues, "key "+issue)
+	}
+	return issues
+}
+
+// lowEntropyPattern describes byte strings that are clearly not random: a single
+// repeated byte, a short repeating block, a counting sequence or very few distinct values
+func lowEntropyPattern(b []byte) string {
+	if len(b) < 8 {
+		return ""
+	}
+	for period := 1; period <= len(b)/4; period++ {
+		repeating := true
+		for i := period; i < len(b); i++ {
+			if b[i] != b[i-period] {
+				repeating = false
+				break
+			}
+		}
+		if repeating {
+			return fmt.Sprintf("repeats a %d-byte pattern", period)
+		}
+	}
+	counting := true
+	for i := 1; i < len(b); i++ {
+		if b[i]-b[i-1] != b[1]-b[0] {
+			counting = false
+			break
+		}
+	}
+	if counting {
+		return "is a counting sequence"
+	}
+	distinct := make(map[byte]bool, len(b))
+	for _, c := range b {
+		distinct[c] = true
+	}
+	if len(distinct) <= len(b)/4 {
+		return fmt.Sprintf("has only %d distinct byte values out of %d", len(distinct), len(b))
+	}
+	return ""
+}
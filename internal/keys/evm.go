@@ -125,26 +125,36 @@ func (m *EVMManager) CreateWalletFromPrivateKey(pkStr string) (vault.Wallet, err
 	return wallet, nil
 }
 
-// DeriveNextAddress derives the next address for an HD wallet.
+// DeriveNextAddress derives the next address in the path scheme of an HD wallet.
 func (m *EVMManager) DeriveNextAddress(wallet vault.Wallet) (vault.Wallet, vault.Address, error) {
-	if wallet.Mnemonic == nil || wallet.Mnemonic.String() == "" {
-		return wallet, vault.Address{}, fmt.Errorf("derivation is only possible for HD wallets (with a mnemonic)")
-	}
+	account, index := wallet.SchemePosition(wallet.SchemeLength())
+	return m.DeriveAddress(wallet, account, index)
+}
 
-	nextIndex := len(wallet.Addresses)
+// DeriveAddress derives the address at the given account and index for an HD wallet.
+func (m *EVMManager) DeriveAddress(wallet vault.Wallet, account, index int) (vault.Wallet, vault.Address, error) {
+	if !wallet.IsHD() {
+		return wallet, vault.Address{}, fmt.Errorf("derivation is only possible for HD wallets (with a mnemonic or seed)")
+	}
+	chainPath, err := AccountChainPath(wallet.DerivationPath, account)
+	if err != nil {
+		return wallet, vault.Address{}, err
+	}
+	if wallet.AddressAt(account, index) != nil {
+		return wallet, vault.Address{}, fmt.Errorf("address %d of account %d is already in the wallet", index, account)
+	}
 
-	// Use WithValue to safely access mnemonic
-	var hdWallet *hdwallet.Wallet
-	var err error
-	err = wallet.Mnemonic.WithValue(func(mnemonicStr string) error {
-		hdWallet, err = createEVMWalletFromMnemonic(mnemonicStr)
-		return err
-	})
+	seed, err := walletSeed(wallet)
+	if err != nil {
+		return wallet, vault.Address{}, err
+	}
+	hdWallet, err := hdwallet.NewFromSeed(seed)
+	zeroBytes(seed)
 	if err != nil {
-		return wallet, vault.Address{}, fmt.Errorf("failed to create wallet from mnemonic: %s", err.Error())
+		return wallet, vault.Address{}, fmt.Errorf("failed to create wallet from seed: %s", err.Error())
 	}
 
-	path := fmt.Sprintf("%s/%d", wallet.DerivationPath, nextIndex)
+	path := fmt.Sprintf("%s/%d", chainPath, index)
 	privateKey, err := deriveEVMPrivateKey(hdWallet, path)
 	if err != nil {
 		return wallet, vault.Address{}, fmt.Errorf("failed to derive private key: %s", err.Error())
@@ -172,7 +182,8 @@ func (m *EVMManager) DeriveNextAddress(wallet vault.Wallet) (vault.Wallet, vault
 
 	// Create new address structure
 	newAddress := vault.Address{
-		Index:      nextIndex,
+		Index:      index,
+		Account:    account,
 		Path:       path,
 		Address:    address,
 		PrivateKey: privateKeySecure,
@@ -187,6 +198,7 @@ func (m *EVMManager) DeriveNextAddress(wallet vault.Wallet) (vault.Wallet, vault
 	}()
 
 	wallet.Addresses = append(wallet.Addresses, newAddress)
+	wallet.SortAddresses()
 	return wallet, newAddress, nil
 }
 
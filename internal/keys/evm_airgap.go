@@ -0,0 +1,297 @@
+// File: internal/keys/evm_airgap.go
+package keys
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/tyler-smith/go-bip39"
+	"vault.module/internal/vault"
+)
+
+// EVMAccountPath is the account-level path exported to watch-only wallets;
+// addresses are derived below it as 0/<index>
+const EVMAccountPath = "m/44'/60'/0'"
+
+// EVMPayloadKind selects how a payload is hashed and how v is encoded when signing
+type EVMPayloadKind int
+
+const (
+	EVMLegacyTransaction EVMPayloadKind = iota // RLP of an EIP-155 unsigned transaction
+	EVMTypedTransaction                        // EIP-2718 type byte followed by the RLP payload
+	EVMPersonalMessage                         // personal_sign (EIP-191 version 0x45)
+	EVMTypedData                               // EIP-712 typed data as JSON
+	EVMRawData                                 // Arbitrary bytes, Keccak-256 hashed without a prefix
+)
+
+// ExtendedPublicKey is an account-level extended public key and its origin
+type ExtendedPublicKey struct {
+	MasterFingerprint uint32
+	ParentFingerprint uint32
+	PublicKey         []byte
+	ChainCode         []byte
+	Path              string
+}
+
+// EVMAccountKey derives the extended public key at EVMAccountPath from an HD wallet's seed
+func EVMAccountKey(wallet vault.Wallet) (*ExtendedPublicKey, error) {
+	seed, err := walletSeed(wallet)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(seed)
+	return accountKeyFromSeed(seed, EVMAccountPath)
+}
+
+// accountKey derives the extended public key at the hardened account path from a mnemonic
+func accountKey(mnemonic, accountPath string) (*ExtendedPublicKey, error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, fmt.Errorf("the provided mnemonic phrase is invalid")
+	}
+	seed := bip39.NewSeed(mnemonic, "")
+	defer zeroBytes(seed)
+	return accountKeyFromSeed(seed, accountPath)
+}
+
+// accountKeyFromSeed derives the extended public key at the hardened account path from a BIP-32 seed
+func accountKeyFromSeed(seed []byte, accountPath string) (*ExtendedPublicKey, error) {
+	master, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		return nil, err
+	}
+	defer master.Zero()
+	masterPub, err := master.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+	masterFingerprint := binary.BigEndian.Uint32(btcutil.Hash160(masterPub.SerializeCompressed())[:4])
+
+	key := master
+	for _, c := range strings.Split(strings.TrimPrefix(accountPath, "m/"), "/") {
+		idx, err := strconv.ParseUint(strings.TrimSuffix(c, "'"), 10, 31)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(c, "'") {
+			idx += hdkeychain.HardenedKeyStart
+		}
+		child, err := key.Derive(uint32(idx))
+		if err != nil {
+			return nil, err
+		}
+		if key != master {
+			key.Zero()
+		}
+		key = child
+	}
+	defer key.Zero()
+
+	pub, err := key.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+	return &ExtendedPublicKey{
+		MasterFingerprint: masterFingerprint,
+		ParentFingerprint: key.ParentFingerprint(),
+		PublicKey:         pub.SerializeCompressed(),
+		ChainCode:         append([]byte(nil), key.ChainCode()...),
+		Path:              accountPath,
+	}, nil
+}
+
+// EVMPayloadHash returns the hash that is signed for payload
+func EVMPayloadHash(payload []byte, kind EVMPayloadKind) ([]byte, error) {
+	switch kind {
+	case EVMLegacyTransaction, EVMTypedTransaction, EVMRawData:
+		return crypto.Keccak256(payload), nil
+	case EVMPersonalMessage:
+		return accounts.TextHash(payload), nil
+	case EVMTypedData:
+		var typedData apitypes.TypedData
+		if err := json.Unmarshal(payload, &typedData); err != nil {
+			return nil, fmt.Errorf("invalid typed data: %v", err)
+		}
+		hash, _, err := apitypes.TypedDataAndHash(typedData)
+		if err != nil {
+			return nil, fmt.Errorf("invalid typed data: %v", err)
+		}
+		return hash, nil
+	default:
+		return nil, fmt.Errorf("unsupported payload kind %d", kind)
+	}
+}
+
+// EVMTypedDataChainID returns the chain ID in the domain of EIP-712 typed
+// data, or nil when it names none
+func EVMTypedDataChainID(payload []byte) (*big.Int, error) {
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal(payload, &typedData); err != nil {
+		return nil, fmt.Errorf("invalid typed data: %v", err)
+	}
+	if typedData.Domain.ChainId == nil {
+		return nil, nil
+	}
+	return (*big.Int)(typedData.Domain.ChainId), nil
+}
+
+// SignEVMPayload signs payload with the hex private key and returns r || s || v.
+// v is EIP-155 encoded for legacy transactions (27 + recovery id with a
+// chainID of 0, for those without replay protection), the bare recovery id for
+// typed transactions, and 27 + recovery id for messages.
+func SignEVMPayload(privateKey string, payload []byte, kind EVMPayloadKind, chainID int64) ([]byte, error) {
+	hash, err := EVMPayloadHash(payload, kind)
+	if err != nil {
+		return nil, err
+	}
+	key, err := privateKeyFromEVMString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %v", err)
+	}
+
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		return nil, err
+	}
+	recID := int64(sig[64])
+	var v *big.Int
+	switch kind {
+	case EVMLegacyTransaction:
+		if chainID == 0 {
+			// Without EIP-155 replay protection
+			v = big.NewInt(27 + recID)
+		} else {
+			v = big.NewInt(chainID*2 + 35 + recID)
+		}
+	case EVMTypedTransaction:
+		v = big.NewInt(recID)
+	default:
+		v = big.NewInt(27 + recID)
+	}
+	vBytes := v.Bytes()
+	if len(vBytes) == 0 {
+		vBytes = []byte{0}
+	}
+	return append(sig[:64], vBytes...), nil
+}
+
+// EVMTransaction is what an unsigned transaction payload does
+type EVMTransaction struct {
+	To      *common.Address // nil for a contract creation
+	Value   *big.Int
+	Data    []byte
+	Gas     uint64
+	Nonce   uint64
+	ChainID *big.Int // nil for a legacy transaction without EIP-155 replay protection
+}
+
+// DecodeEVMTransaction decodes the recipient, value, data, gas limit, nonce
+// and chain ID of an unsigned transaction payload
+func DecodeEVMTransaction(payload []byte, kind EVMPayloadKind) (*EVMTransaction, error) {
+	// Position of the recipient in the RLP list; the gas limit precedes it,
+	// the value and the data follow it
+	toIndex := 3
+	if kind == EVMTypedTransaction {
+		if len(payload) == 0 {
+			return nil, fmt.Errorf("empty transaction")
+		}
+		switch payload[0] {
+		case 1: // EIP-2930
+			toIndex = 4
+		case 2, 3, 4: // EIP-1559, EIP-4844, EIP-7702
+			toIndex = 5
+		default:
+			return nil, fmt.Errorf("unknown transaction type %d", payload[0])
+		}
+		payload = payload[1:]
+	} else if kind != EVMLegacyTransaction {
+		return nil, fmt.Errorf("payload is not a transaction")
+	}
+
+	content, _, err := rlp.SplitList(payload)
+	if err != nil {
+		return nil, fmt.Errorf("malformed transaction: %v", err)
+	}
+	var fields [][]byte
+	for len(content) > 0 {
+		_, field, rest, err := rlp.Split(content)
+		if err != nil {
+			return nil, fmt.Errorf("malformed transaction: %v", err)
+		}
+		fields, content = append(fields, field), rest
+	}
+	if len(fields) <= toIndex+2 {
+		return nil, fmt.Errorf("malformed transaction: too few fields")
+	}
+
+	tx := &EVMTransaction{
+		Value: new(big.Int).SetBytes(fields[toIndex+1]),
+		Data:  fields[toIndex+2],
+	}
+	switch len(fields[toIndex]) {
+	case 0:
+	case common.AddressLength:
+		to := common.BytesToAddress(fields[toIndex])
+		tx.To = &to
+	default:
+		return nil, fmt.Errorf("malformed transaction: invalid recipient")
+	}
+	gas := new(big.Int).SetBytes(fields[toIndex-1])
+	if !gas.IsUint64() {
+		return nil, fmt.Errorf("malformed transaction: invalid gas limit")
+	}
+	tx.Gas = gas.Uint64()
+	// The nonce comes first, after the chain ID of a typed transaction
+	nonceIndex := 0
+	if kind == EVMTypedTransaction {
+		nonceIndex = 1
+	}
+	nonce := new(big.Int).SetBytes(fields[nonceIndex])
+	if !nonce.IsUint64() {
+		return nil, fmt.Errorf("malformed transaction: invalid nonce")
+	}
+	tx.Nonce = nonce.Uint64()
+
+	// A typed transaction starts with its chain ID. A legacy one has six
+	// fields, or nine when EIP-155 appends the chain ID and two zeros.
+	switch {
+	case kind == EVMTypedTransaction:
+		tx.ChainID = new(big.Int).SetBytes(fields[0])
+	case len(fields) == 9:
+		if len(fields[7]) != 0 || len(fields[8]) != 0 {
+			return nil, fmt.Errorf("malformed transaction: EIP-155 fields must end with two zeros")
+		}
+		tx.ChainID = new(big.Int).SetBytes(fields[6])
+	case len(fields) != 6:
+		return nil, fmt.Errorf("malformed transaction: a legacy transaction has 6 or 9 fields, not %d", len(fields))
+	}
+	return tx, nil
+}
+
+// EVMPublicKey returns the uncompressed public key of a hex private key,
+// without the 0x04 prefix, and its address
+func EVMPublicKey(privateKey string) ([]byte, string, error) {
+	key, err := privateKeyFromEVMString(privateKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid private key: %v", err)
+	}
+	defer key.D.SetInt64(0)
+	return crypto.FromECDSAPub(&key.PublicKey)[1:], crypto.PubkeyToAddress(key.PublicKey).Hex(), nil
+}
+
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
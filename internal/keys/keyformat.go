@@ -0,0 +1,121 @@
+// File: internal/keys/keyformat.go
+package keys
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"vault.module/internal/constants"
+)
+
+// Private key formats recognized on import
+const (
+	KeyFormatHex       = "hex"
+	KeyFormatHex0x     = "0x-hex"
+	KeyFormatWIF       = "WIF"
+	KeyFormatBase64    = "base64"
+	KeyFormatBracketed = "bracketed"
+)
+
+// privateKeySize is the length of a secp256k1 private key in bytes
+const privateKeySize = 32
+
+// chainKeyFormats lists the private key formats each vault type accepts, in
+// detection order. WIF is a Bitcoin encoding that EVM keys are often exported
+// in; base64 is how Cosmos SDK tools (Amino JSON, priv_validator files) write
+// keys.
+var chainKeyFormats = map[string][]string{
+	constants.VaultTypeEVM:    {KeyFormatHex, KeyFormatHex0x, KeyFormatBracketed, KeyFormatWIF},
+	constants.VaultTypeCosmos: {KeyFormatHex, KeyFormatHex0x, KeyFormatBracketed, KeyFormatBase64},
+}
+
+// NormalizePrivateKey detects the format of a private key for the vault type
+// and returns it as 64 hex characters, with the name of the format detected.
+// A format that is recognized but not accepted for the chain is an error.
+func NormalizePrivateKey(vaultType, input string) (string, string, error) {
+	vaultType = strings.ToLower(strings.TrimSpace(vaultType))
+	accepted, ok := chainKeyFormats[vaultType]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported vault type: %s", vaultType)
+	}
+	input = strings.TrimSpace(input)
+
+	format, raw := detectPrivateKey(input)
+	if format == "" {
+		return "", "", fmt.Errorf("not a private key in a format accepted for %s vaults (%s)", vaultType, strings.Join(accepted, ", "))
+	}
+	defer zeroBytes(raw)
+	for _, f := range accepted {
+		if f == format {
+			return hex.EncodeToString(raw), format, nil
+		}
+	}
+	return "", format, fmt.Errorf("%s private keys are not accepted for %s vaults (%s)", format, vaultType, strings.Join(accepted, ", "))
+}
+
+// detectPrivateKey decodes a 32-byte private key in any known format, or
+// returns an empty format
+func detectPrivateKey(input string) (string, []byte) {
+	if body, ok := strings.CutPrefix(input, "0x"); ok {
+		if raw := decodeKeyHex(body); raw != nil {
+			return KeyFormatHex0x, raw
+		}
+		return "", nil
+	}
+	if raw := decodeKeyHex(input); raw != nil {
+		return KeyFormatHex, raw
+	}
+	if strings.HasPrefix(input, "[") && strings.HasSuffix(input, "]") {
+		if raw := decodeKeyBytes(input[1 : len(input)-1]); raw != nil {
+			return KeyFormatBracketed, raw
+		}
+		return "", nil
+	}
+	if wif, err := btcutil.DecodeWIF(input); err == nil {
+		return KeyFormatWIF, wif.PrivKey.Serialize()
+	}
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if raw, err := encoding.DecodeString(input); err == nil {
+			if len(raw) == privateKeySize {
+				return KeyFormatBase64, raw
+			}
+			zeroBytes(raw)
+		}
+	}
+	return "", nil
+}
+
+// decodeKeyHex decodes 64 hex characters, or returns nil
+func decodeKeyHex(s string) []byte {
+	if len(s) != 2*privateKeySize {
+		return nil
+	}
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
+
+// decodeKeyBytes decodes a comma-separated list of 32 bytes, as a JSON byte
+// array writes them ("[12, 255, ...]"), or returns nil
+func decodeKeyBytes(s string) []byte {
+	fields := strings.Split(s, ",")
+	if len(fields) != privateKeySize {
+		return nil
+	}
+	raw := make([]byte, 0, privateKeySize)
+	for _, field := range fields {
+		b, err := strconv.ParseUint(strings.TrimSpace(field), 10, 8)
+		if err != nil {
+			zeroBytes(raw)
+			return nil
+		}
+		raw = append(raw, byte(b))
+	}
+	return raw
+}
@@ -14,6 +14,7 @@ type KeyManager interface {
 	CreateWalletFromMnemonic(mnemonic string) (vault.Wallet, error)
 	CreateWalletFromPrivateKey(pk string) (vault.Wallet, error)
 	DeriveNextAddress(wallet vault.Wallet) (vault.Wallet, vault.Address, error)
+	DeriveAddress(wallet vault.Wallet, account, index int) (vault.Wallet, vault.Address, error)
 	ValidateMnemonic(mnemonic string) bool
 	ValidatePrivateKey(pk string) bool
 }
@@ -31,3 +32,71 @@ func GetKeyManager(vaultType string) (KeyManager, error) {
 			vaultType, constants.VaultTypeEVM, constants.VaultTypeCosmos)
 	}
 }
+
+// AccountChainPath returns the address chain of a BIP-44 account: the wallet's
+// derivation path (m/purpose'/coin'/account'/change) with another account.
+func AccountChainPath(derivationPath string, account int) (string, error) {
+	if account < 0 || account >= 1<<31 {
+		return "", fmt.Errorf("account %d is out of range", account)
+	}
+	parts := strings.Split(derivationPath, "/")
+	if len(parts) != 5 || parts[0] != "m" || !strings.HasSuffix(parts[3], "'") {
+		if account == 0 {
+			return derivationPath, nil
+		}
+		return "", fmt.Errorf("derivation path %q has no BIP-44 account level", derivationPath)
+	}
+	parts[3] = fmt.Sprintf("%d'", account)
+	return strings.Join(parts, "/"), nil
+}
+
+// SchemeDerivationPath returns the derivation path of new wallets of a vault
+// type that follow a path scheme.
+func SchemeDerivationPath(vaultType, scheme string) (string, error) {
+	var base string
+	switch strings.ToLower(strings.TrimSpace(vaultType)) {
+	case constants.VaultTypeEVM:
+		base = EVMDerivationPath
+	case constants.VaultTypeCosmos:
+		base = CosmosDerivationPath
+	default:
+		return "", fmt.Errorf("unsupported vault type: %s", vaultType)
+	}
+	switch scheme {
+	case "", vault.PathSchemeBIP44, vault.PathSchemeLedgerLive:
+		return base, nil
+	case vault.PathSchemeLedgerLegacy:
+		return base[:strings.LastIndex(base, "/")], nil
+	}
+	return "", fmt.Errorf("unknown path scheme %q (supported: %s)", scheme, strings.Join(vault.PathSchemes, ", "))
+}
+
+// ApplyPathScheme switches an HD wallet to a path scheme. Its addresses are
+// replaced by the first address of the new scheme.
+func ApplyPathScheme(wallet vault.Wallet, vaultType, scheme string) (vault.Wallet, error) {
+	if !wallet.IsHD() {
+		return wallet, fmt.Errorf("path schemes only apply to HD wallets (with a mnemonic or seed)")
+	}
+	derivationPath, err := SchemeDerivationPath(vaultType, scheme)
+	if err != nil {
+		return wallet, err
+	}
+	manager, err := GetKeyManager(vaultType)
+	if err != nil {
+		return wallet, err
+	}
+
+	for i := range wallet.Addresses {
+		if wallet.Addresses[i].PrivateKey != nil {
+			wallet.Addresses[i].PrivateKey.Clear()
+		}
+	}
+	wallet.Addresses = nil
+	wallet.DerivationPath = derivationPath
+	wallet.PathScheme = scheme
+	if scheme == vault.PathSchemeBIP44 {
+		wallet.PathScheme = ""
+	}
+	wallet, _, err = manager.DeriveNextAddress(wallet)
+	return wallet, err
+}
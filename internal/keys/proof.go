@@ -0,0 +1,98 @@
+// File: internal/keys/proof.go
+package keys
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/cometbft/cometbft/crypto/secp256k1"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"vault.module/internal/constants"
+)
+
+// Signature schemes of ownership proofs
+const (
+	// ProofSchemeEIP191 is an Ethereum personal_sign signature (r || s || v, v = 27/28)
+	ProofSchemeEIP191 = "eip191-personal-sign"
+	// ProofSchemeSecp256k1 is a secp256k1 signature (r || s) over the SHA-256 of the statement
+	ProofSchemeSecp256k1 = "secp256k1-sha256"
+)
+
+// SignStatement signs statement with the hex private key of a vault of vaultType.
+// It returns the scheme, the hex signature and the hex compressed public key.
+func SignStatement(vaultType, privateKey, statement string) (scheme, signature, publicKey string, err error) {
+	switch vaultType {
+	case constants.VaultTypeEVM:
+		key, err := privateKeyFromEVMString(privateKey)
+		if err != nil {
+			return "", "", "", fmt.Errorf("invalid private key: %v", err)
+		}
+		sig, err := crypto.Sign(accounts.TextHash([]byte(statement)), key)
+		if err != nil {
+			return "", "", "", err
+		}
+		sig[64] += 27
+		return ProofSchemeEIP191, "0x" + hex.EncodeToString(sig), hex.EncodeToString(crypto.CompressPubkey(&key.PublicKey)), nil
+	case constants.VaultTypeCosmos:
+		keyBytes, err := hex.DecodeString(privateKey)
+		if err != nil || len(keyBytes) != secp256k1.PrivKeySize {
+			return "", "", "", fmt.Errorf("invalid private key")
+		}
+		key := secp256k1.PrivKey(keyBytes)
+		defer func() {
+			for i := range keyBytes {
+				keyBytes[i] = 0
+			}
+		}()
+		sig, err := key.Sign([]byte(statement))
+		if err != nil {
+			return "", "", "", err
+		}
+		return ProofSchemeSecp256k1, hex.EncodeToString(sig), hex.EncodeToString(key.PubKey().Bytes()), nil
+	default:
+		return "", "", "", fmt.Errorf("unsupported vault type: %s", vaultType)
+	}
+}
+
+// VerifyStatement checks that signature over statement was made by the key of address
+func VerifyStatement(scheme, address, statement, signature, publicKey string) error {
+	switch scheme {
+	case ProofSchemeEIP191:
+		sig, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+		if err != nil || len(sig) != crypto.SignatureLength {
+			return fmt.Errorf("malformed signature")
+		}
+		if sig[64] >= 27 {
+			sig[64] -= 27
+		}
+		pub, err := crypto.SigToPub(accounts.TextHash([]byte(statement)), sig)
+		if err != nil {
+			return fmt.Errorf("invalid signature: %v", err)
+		}
+		if recovered := crypto.PubkeyToAddress(*pub).Hex(); !strings.EqualFold(recovered, address) {
+			return fmt.Errorf("signature was made by %s, not %s", recovered, address)
+		}
+		return nil
+	case ProofSchemeSecp256k1:
+		sig, err := hex.DecodeString(signature)
+		if err != nil {
+			return fmt.Errorf("malformed signature")
+		}
+		pubBytes, err := hex.DecodeString(publicKey)
+		if err != nil || len(pubBytes) != secp256k1.PubKeySize {
+			return fmt.Errorf("malformed public key")
+		}
+		pub := secp256k1.PubKey(pubBytes)
+		if !strings.EqualFold(pub.Address().String(), address) {
+			return fmt.Errorf("public key does not belong to %s", address)
+		}
+		if !pub.VerifySignature([]byte(statement), sig) {
+			return fmt.Errorf("signature does not match the statement")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported proof scheme %q", scheme)
+	}
+}
@@ -0,0 +1,98 @@
+// File: internal/keys/scan.go
+package keys
+
+import (
+	"fmt"
+	"strings"
+
+	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
+	"vault.module/internal/constants"
+	"vault.module/internal/vault"
+)
+
+// ScanIndex marks the varying component of a ScanPath template.
+const ScanIndex = "{i}"
+
+// ScanPath is a derivation path layout used by some family of wallets. The
+// Template holds ScanIndex where the wallets count up, e.g. m/44'/60'/{i}'/0/0
+// for Ledger Live, which creates one account per address.
+type ScanPath struct {
+	Name     string
+	Template string
+}
+
+// CommonScanPaths returns the layouts most wallets of the vault type have used.
+func CommonScanPaths(vaultType string) []ScanPath {
+	switch strings.ToLower(strings.TrimSpace(vaultType)) {
+	case constants.VaultTypeEVM:
+		return []ScanPath{
+			{"BIP-44 (MetaMask, Trezor, this vault)", EVMDerivationPath + "/" + ScanIndex},
+			{"Ledger Live", "m/44'/60'/" + ScanIndex + "'/0/0"},
+			{"Ledger legacy, MyEtherWallet", "m/44'/60'/0'/" + ScanIndex},
+			{"Ethereum Classic", "m/44'/61'/0'/0/" + ScanIndex},
+			{"Testnet coin type", "m/44'/1'/0'/0/" + ScanIndex},
+		}
+	case constants.VaultTypeCosmos:
+		return []ScanPath{
+			{"BIP-44 (Keplr, gaiad, this vault)", CosmosDerivationPath + "/" + ScanIndex},
+			{"Cosmos, account per address", "m/44'/118'/" + ScanIndex + "'/0/0"},
+			{"Terra", "m/44'/330'/0'/0/" + ScanIndex},
+			{"Crypto.org", "m/44'/394'/0'/0/" + ScanIndex},
+			{"Kava", "m/44'/459'/0'/0/" + ScanIndex},
+			{"Secret Network", "m/44'/529'/0'/0/" + ScanIndex},
+		}
+	}
+	return nil
+}
+
+// ParseScanPath turns a user-supplied template into a ScanPath. A path without
+// ScanIndex counts up in a new last component.
+func ParseScanPath(template string) (ScanPath, error) {
+	template = strings.TrimSpace(template)
+	if !strings.HasPrefix(template, "m/") {
+		return ScanPath{}, fmt.Errorf("derivation path %q must start with m/", template)
+	}
+	if strings.Count(template, ScanIndex) > 1 {
+		return ScanPath{}, fmt.Errorf("derivation path %q has more than one %s", template, ScanIndex)
+	}
+	if !strings.Contains(template, ScanIndex) {
+		template = strings.TrimSuffix(template, "/") + "/" + ScanIndex
+	}
+	return ScanPath{Name: "custom", Template: template}, nil
+}
+
+// Path returns the derivation path for index i.
+func (p ScanPath) Path(i int) string {
+	return strings.Replace(p.Template, ScanIndex, fmt.Sprintf("%d", i), 1)
+}
+
+// AddressAt derives the address of the vault type at an arbitrary path of an
+// HD wallet. The private key is discarded.
+func AddressAt(vaultType string, wallet vault.Wallet, path string) (string, error) {
+	seed, err := walletSeed(wallet)
+	if err != nil {
+		return "", err
+	}
+	defer zeroBytes(seed)
+	switch strings.ToLower(strings.TrimSpace(vaultType)) {
+	case constants.VaultTypeEVM:
+		hdWallet, err := hdwallet.NewFromSeed(seed)
+		if err != nil {
+			return "", err
+		}
+		privateKey, err := deriveEVMPrivateKey(hdWallet, path)
+		if err != nil {
+			return "", err
+		}
+		defer privateKey.D.SetInt64(0)
+		return privateKeyToEVMAddress(privateKey)
+	case constants.VaultTypeCosmos:
+		privKey, err := deriveCosmosPrivateKeyFromSeed(seed, path)
+		if err != nil {
+			return "", err
+		}
+		defer zeroBytes(privKey)
+		return privKey.PubKey().Address().String(), nil
+	}
+	return "", fmt.Errorf("unsupported vault type: %s", vaultType)
+}
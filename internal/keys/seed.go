@@ -0,0 +1,127 @@
+// File: internal/keys/seed.go
+package keys
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+	"vault.module/internal/security"
+	"vault.module/internal/vault"
+)
+
+// BIP-32 seed lengths in bytes
+const (
+	minSeedSize = 16
+	maxSeedSize = 64
+)
+
+// MnemonicFromEntropy returns the BIP-39 mnemonic encoding hex entropy of 16,
+// 20, 24, 28 or 32 bytes (12 to 24 words)
+func MnemonicFromEntropy(entropyHex string) (string, error) {
+	entropy, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(entropyHex), "0x"))
+	if err != nil {
+		return "", fmt.Errorf("entropy must be hex")
+	}
+	defer zeroBytes(entropy)
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return "", fmt.Errorf("entropy must be 16, 20, 24, 28 or 32 bytes, got %d", len(entropy))
+	}
+	return mnemonic, nil
+}
+
+// CreateWalletFromEntropy creates an HD wallet from BIP-39 entropy. Its
+// mnemonic is reconstructed, so it is backed up and restored like any other.
+func CreateWalletFromEntropy(entropyHex, vaultType string) (vault.Wallet, error) {
+	mnemonic, err := MnemonicFromEntropy(entropyHex)
+	if err != nil {
+		return vault.Wallet{}, err
+	}
+	manager, err := GetKeyManager(vaultType)
+	if err != nil {
+		return vault.Wallet{}, err
+	}
+	wallet, err := manager.CreateWalletFromMnemonic(mnemonic)
+	if err != nil {
+		return vault.Wallet{}, err
+	}
+	wallet.Source = vault.SourceEntropy
+	return wallet, nil
+}
+
+// CreateWalletFromSeed creates an HD wallet from a hex BIP-32 seed of 16 to 64
+// bytes. No mnemonic can be reconstructed from a seed: the wallet keeps the
+// seed and derives its addresses from it.
+func CreateWalletFromSeed(seedHex, vaultType string) (vault.Wallet, error) {
+	seed, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(seedHex), "0x"))
+	if err != nil {
+		return vault.Wallet{}, fmt.Errorf("seed must be hex")
+	}
+	defer zeroBytes(seed)
+	if len(seed) < minSeedSize || len(seed) > maxSeedSize {
+		return vault.Wallet{}, fmt.Errorf("seed must be %d to %d bytes, got %d", minSeedSize, maxSeedSize, len(seed))
+	}
+	derivationPath, err := SchemeDerivationPath(vaultType, "")
+	if err != nil {
+		return vault.Wallet{}, err
+	}
+	manager, err := GetKeyManager(vaultType)
+	if err != nil {
+		return vault.Wallet{}, err
+	}
+	wallet := vault.Wallet{
+		Seed:           security.NewSecureString(hex.EncodeToString(seed)),
+		Source:         vault.SourceSeed,
+		DerivationPath: derivationPath,
+	}
+	wallet, _, err = manager.DeriveNextAddress(wallet)
+	if err != nil {
+		wallet.Clear()
+		return vault.Wallet{}, err
+	}
+	return wallet, nil
+}
+
+// walletSeed returns the BIP-32 seed of an HD wallet: derived from its
+// mnemonic, or the seed it was imported from. The caller zeroes it.
+func walletSeed(wallet vault.Wallet) ([]byte, error) {
+	if wallet.Mnemonic != nil && !wallet.Mnemonic.IsEmpty() {
+		var seed []byte
+		err := wallet.Mnemonic.WithValue(func(mnemonic string) error {
+			var err error
+			seed, err = bip39.NewSeedWithErrorChecking(mnemonic, "")
+			return err
+		})
+		return seed, err
+	}
+	if wallet.Seed != nil && !wallet.Seed.IsEmpty() {
+		var seed []byte
+		err := wallet.Seed.WithValue(func(seedHex string) error {
+			var err error
+			seed, err = hex.DecodeString(seedHex)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("the wallet's seed is corrupt")
+		}
+		return seed, nil
+	}
+	return nil, fmt.Errorf("derivation is only possible for HD wallets (with a mnemonic or seed)")
+}
+
+// WalletFingerprint returns the BIP-32 master key fingerprint of an HD
+// wallet's seed, as SeedFingerprint does for a mnemonic
+func WalletFingerprint(wallet vault.Wallet) (string, error) {
+	seed, err := walletSeed(wallet)
+	if err != nil {
+		return "", err
+	}
+	defer zeroBytes(seed)
+	key, err := accountKeyFromSeed(seed, "m/44'")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%08x", key.MasterFingerprint), nil
+}
@@ -0,0 +1,88 @@
+// File: internal/keys/wizard.go
+package keys
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// Physical entropy is turned into a mnemonic by rules simple enough to check by
+// hand: coin flips are the entropy bits themselves (heads = 1), dice rolls are
+// hashed as the string of their faces with SHA-256, like hardware wallets do,
+// so `printf %s <rolls> | sha256sum` reproduces the entropy.
+
+// EntropyFromCoins packs coin flips (1 = heads, 0 = tails) into entropy bytes.
+// It needs exactly 128 or 256 flips.
+func EntropyFromCoins(flips []byte) ([]byte, error) {
+	if len(flips) != 128 && len(flips) != 256 {
+		return nil, fmt.Errorf("need 128 or 256 coin flips, got %d", len(flips))
+	}
+	entropy := make([]byte, len(flips)/8)
+	for i, f := range flips {
+		if f > 1 {
+			return nil, fmt.Errorf("flip %d is not 0 or 1", i+1)
+		}
+		entropy[i/8] |= f << (7 - uint(i%8))
+	}
+	return entropy, nil
+}
+
+// EntropyFromDice hashes six-sided dice rolls into bits/8 bytes of entropy. The
+// rolls must carry at least bits of entropy (2.58 bits per roll).
+func EntropyFromDice(rolls []byte, bits int) ([]byte, error) {
+	if bits != 128 && bits != 256 {
+		return nil, fmt.Errorf("entropy must be 128 or 256 bits")
+	}
+	if needed := (bits*100 + 257) / 258; len(rolls) < needed {
+		return nil, fmt.Errorf("need at least %d dice rolls for %d bits, got %d", needed, bits, len(rolls))
+	}
+	var b strings.Builder
+	for i, r := range rolls {
+		if r < 1 || r > 6 {
+			return nil, fmt.Errorf("roll %d is not between 1 and 6", i+1)
+		}
+		b.WriteByte('0' + r)
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return append([]byte(nil), sum[:bits/8]...), nil
+}
+
+// WorksheetRow is one word of a mnemonic with the bits that select it
+type WorksheetRow struct {
+	Bits  string // 11 bits; the last row ends with the checksum bits
+	Index int    // position in the BIP-39 English word list, from 0
+	Word  string
+}
+
+// MnemonicWorksheet returns the mnemonic for entropy and the rows that derive it:
+// the entropy bits followed by the first len/32 bits of its SHA-256, split into
+// 11-bit word indices
+func MnemonicWorksheet(entropy []byte) (string, []WorksheetRow, error) {
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return "", nil, err
+	}
+	var bits strings.Builder
+	for _, b := range entropy {
+		fmt.Fprintf(&bits, "%08b", b)
+	}
+	checksum := sha256.Sum256(entropy)
+	checksumBits := len(entropy) * 8 / 32
+	for i := 0; i < checksumBits; i++ {
+		bits.WriteByte('0' + (checksum[i/8]>>(7-uint(i%8)))&1)
+	}
+
+	words := strings.Fields(mnemonic)
+	all := bits.String()
+	rows := make([]WorksheetRow, len(words))
+	for i, word := range words {
+		chunk := all[i*11 : i*11+11]
+		index, _ := strconv.ParseInt(chunk, 2, 32)
+		rows[i] = WorksheetRow{Bits: chunk, Index: int(index), Word: word}
+	}
+	return mnemonic, rows, nil
+}
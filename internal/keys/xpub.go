@@ -0,0 +1,74 @@
+// File: internal/keys/xpub.go
+package keys
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"vault.module/internal/vault"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func init() {
+	vault.XPubFor = WalletXPub
+}
+
+// String encodes the key in the BIP-32 xpub format
+func (k *ExtendedPublicKey) String() string {
+	depth := uint8(strings.Count(k.Path, "/"))
+	var childNum uint32
+	if i := strings.LastIndex(k.Path, "/"); i >= 0 {
+		var n uint32
+		if _, err := fmt.Sscanf(strings.TrimSuffix(k.Path[i+1:], "'"), "%d", &n); err == nil {
+			childNum = n
+			if strings.HasSuffix(k.Path, "'") {
+				childNum += hdkeychain.HardenedKeyStart
+			}
+		}
+	}
+	parent := make([]byte, 4)
+	binary.BigEndian.PutUint32(parent, k.ParentFingerprint)
+	return hdkeychain.NewExtendedKey(chaincfg.MainNetParams.HDPublicKeyID[:], k.PublicKey, k.ChainCode, parent, depth, childNum, false).String()
+}
+
+// WalletXPub returns the account-level xpub of an HD wallet: the key one level
+// above its address chain, e.g. m/44'/60'/0' for m/44'/60'/0'/0. It returns ""
+// for wallets that are not HD.
+func WalletXPub(wallet vault.Wallet) (string, error) {
+	if !wallet.IsHD() || wallet.DerivationPath == "" {
+		return "", nil
+	}
+	accountPath := wallet.DerivationPath
+	if wallet.Scheme() != vault.PathSchemeLedgerLegacy {
+		// Legacy Ledger paths have no change level: the addresses are children of the account
+		i := strings.LastIndex(wallet.DerivationPath, "/")
+		if i <= 0 {
+			return "", fmt.Errorf("derivation path %q has no account level", wallet.DerivationPath)
+		}
+		accountPath = wallet.DerivationPath[:i]
+	}
+	seed, err := walletSeed(wallet)
+	if err != nil {
+		return "", err
+	}
+	defer zeroBytes(seed)
+	key, err := accountKeyFromSeed(seed, accountPath)
+	if err != nil {
+		return "", err
+	}
+	return key.String(), nil
+}
+
+// SeedFingerprint returns the BIP-32 master key fingerprint of a mnemonic as 8
+// hex digits: the same for every wallet derived from that seed, whatever its
+// chain or derivation path.
+func SeedFingerprint(mnemonic string) (string, error) {
+	key, err := accountKey(mnemonic, "m/44'")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%08x", key.MasterFingerprint), nil
+}
@@ -0,0 +1,299 @@
+// File: internal/keystoresync/keystoresync.go
+package keystoresync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fsnotify/fsnotify"
+	"github.com/google/uuid"
+	"vault.module/internal/audit"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+)
+
+// ManifestFile records what sync wrote to a keystore directory. Geth skips
+// files starting with a dot, so it does not disturb the node.
+const ManifestFile = ".vault.module-sync.json"
+
+// maxKeyFileSize bounds a keystore file; real ones are under 1KB
+const maxKeyFileSize = 64 * 1024
+
+// Manifest is the sync state of a keystore directory
+type Manifest struct {
+	Vault     string    `json:"vault"`             // Vault the directory is synced with
+	Wallets   []string  `json:"wallets,omitempty"` // Selected wallets
+	Tag       string    `json:"tag,omitempty"`     // Or the tag selecting them
+	Files     []File    `json:"files"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// File is a keystore file written by sync
+type File struct {
+	Name    string `json:"name"`
+	Address string `json:"address"` // Checksummed 0x address
+	Prefix  string `json:"prefix"`
+	Account int    `json:"account,omitempty"`
+	Index   int    `json:"index"`
+	SHA256  string `json:"sha256"` // Of the file as written, to notice changes
+}
+
+// Entry is a keystore V3 file found in the directory
+type Entry struct {
+	Name    string
+	Address string // Checksummed 0x address, "" if the file does not name one
+}
+
+// LoadManifest reads the manifest of dir; it is nil if dir was never synced
+func LoadManifest(dir string) (*Manifest, error) {
+	path := filepath.Join(dir, ManifestFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewFileSystemError("read", path, err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, errors.NewFormatInvalidError(ManifestFile, fmt.Sprintf("the sync manifest is corrupt: %v", err))
+	}
+	return &m, nil
+}
+
+// SaveManifest writes the manifest of dir atomically with owner-only permissions
+func SaveManifest(dir string, m *Manifest) error {
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Name < m.Files[j].Name })
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return errors.New(errors.ErrCodeInternal, "failed to serialize the sync manifest").WithContext("marshal_error", err.Error())
+	}
+	path := filepath.Join(dir, ManifestFile)
+	tmp, err := os.CreateTemp(dir, ".vault.module-sync-*.tmp")
+	if err != nil {
+		return errors.NewFileSystemError("create", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return errors.NewFileSystemError("chmod", tmp.Name(), err)
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return errors.NewFileSystemError("write", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.NewFileSystemError("close", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.NewFileSystemError("rename", tmp.Name(), err)
+	}
+	return nil
+}
+
+// Scan lists the keystore V3 files in dir, skipping dotfiles as geth does
+func Scan(dir string) ([]Entry, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.NewFileSystemError("read", dir, err)
+	}
+	var entries []Entry
+	for _, dirEntry := range dirEntries {
+		if entry, ok := readEntry(dir, dirEntry.Name()); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// readEntry reads the address of the keystore file name in dir. It reports
+// false for dotfiles, directories and files that are not keystore V3 files.
+func readEntry(dir, name string) (Entry, bool) {
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "~") {
+		return Entry{}, false
+	}
+	path := filepath.Join(dir, name)
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > maxKeyFileSize {
+		return Entry{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Entry{}, false
+	}
+	var header struct {
+		Version int             `json:"version"`
+		Address string          `json:"address"`
+		Crypto  json.RawMessage `json:"crypto"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil || header.Version != 3 || len(header.Crypto) == 0 {
+		return Entry{}, false
+	}
+	entry := Entry{Name: name}
+	if common.IsHexAddress(header.Address) {
+		entry.Address = common.HexToAddress(header.Address).Hex()
+	}
+	return entry, true
+}
+
+// Hash returns the SHA-256 of the file name in dir, "" if it cannot be read
+func Hash(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// WriteKey encrypts a private key into a new keystore V3 file in dir, named
+// as geth names them. light selects the faster scrypt parameters of geth's
+// --lightkdf. It returns the file name, the address and the file's SHA-256.
+func WriteKey(dir, privateKey, password string, light bool) (name, address, sum string, err error) {
+	ecdsaKey, err := crypto.HexToECDSA(strings.TrimPrefix(privateKey, "0x"))
+	if err != nil {
+		return "", "", "", errors.NewInvalidInputError("private key", "not a secp256k1 private key")
+	}
+	defer func() {
+		keyBytes := crypto.FromECDSA(ecdsaKey)
+		security.SecureZero(keyBytes)
+		ecdsaKey.D.SetInt64(0)
+	}()
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return "", "", "", errors.New(errors.ErrCodeInternal, "failed to create key ID").WithContext("error", err.Error())
+	}
+	key := &keystore.Key{Id: id, Address: crypto.PubkeyToAddress(ecdsaKey.PublicKey), PrivateKey: ecdsaKey}
+
+	scryptN, scryptP := keystore.StandardScryptN, keystore.StandardScryptP
+	if light {
+		scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+	}
+	data, err := keystore.EncryptKey(key, password, scryptN, scryptP)
+	if err != nil {
+		return "", "", "", errors.New(errors.ErrCodeInternal, "failed to encrypt the key").WithContext("error", err.Error())
+	}
+
+	name = keyFileName(key.Address)
+	path := filepath.Join(dir, name)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", "", "", errors.NewFileSystemError("create", path, err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(path)
+		return "", "", "", errors.NewFileSystemError("write", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return "", "", "", errors.NewFileSystemError("close", path, err)
+	}
+	digest := sha256.Sum256(data)
+	return name, key.Address.Hex(), hex.EncodeToString(digest[:]), nil
+}
+
+// CheckPassword reports whether password opens the keystore file name in dir
+func CheckPassword(dir, name, password string) error {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return errors.NewFileSystemError("read", filepath.Join(dir, name), err)
+	}
+	key, err := keystore.DecryptKey(data, password)
+	if err != nil {
+		return errors.NewInvalidInputError("keystore password", "the password does not open the files synced before")
+	}
+	keyBytes := crypto.FromECDSA(key.PrivateKey)
+	security.SecureZero(keyBytes)
+	key.PrivateKey.D.SetInt64(0)
+	return nil
+}
+
+// keyFileName names a key file as geth does: UTC--<created>--<address>
+func keyFileName(address common.Address) string {
+	ts := time.Now().UTC()
+	return fmt.Sprintf("UTC--%s--%s", ts.Format("2006-01-02T15-04-05.000000000Z"), hex.EncodeToString(address[:]))
+}
+
+// Event is a change to a keystore directory seen by Watch
+type Event struct {
+	Name    string
+	Address string // For files that are keystore files
+	Op      string // "added", "modified" or "removed"
+}
+
+// Watch reports changes to the keystore files of dir until ctx is done.
+// Writes by sync itself are recognized by the hashes in the manifest.
+func Watch(ctx context.Context, dir string, onChange func(Event)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(errors.ErrCodeSystem, "failed to start file watcher", err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(dir); err != nil {
+		return errors.FromOSError(err, dir)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			audit.Logger.Warn("File watcher error", slog.String("error", err.Error()))
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			name := filepath.Base(event.Name)
+			if strings.HasPrefix(name, ".") {
+				continue
+			}
+			managed := managedFile(dir, name)
+			switch {
+			case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+				if managed != nil {
+					onChange(Event{Name: name, Address: managed.Address, Op: "removed"})
+				}
+			case event.Has(fsnotify.Create), event.Has(fsnotify.Write):
+				entry, isKey := readEntry(dir, name)
+				if !isKey {
+					continue
+				}
+				if managed == nil {
+					onChange(Event{Name: name, Address: entry.Address, Op: "added"})
+				} else if Hash(dir, name) != managed.SHA256 {
+					onChange(Event{Name: name, Address: entry.Address, Op: "modified"})
+				}
+			}
+		}
+	}
+}
+
+// managedFile returns the manifest entry of the file name, nil if sync did
+// not write it. The manifest is read again as sync may have changed it.
+func managedFile(dir, name string) *File {
+	m, err := LoadManifest(dir)
+	if err != nil || m == nil {
+		return nil
+	}
+	for i := range m.Files {
+		if m.Files[i].Name == name {
+			return &m.Files[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// File: internal/leakcheck/bloom.go
+package leakcheck
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// bloomMagic starts every serialized filter
+var bloomMagic = []byte("VMBF1")
+
+// falsePositiveRate is the target rate filters are sized for. A false positive
+// only causes a spurious warning, never a lost key.
+const falsePositiveRate = 1e-6
+
+// Filter is a bloom filter over normalized keys and addresses. It answers
+// "definitely not leaked" or "probably leaked" without shipping the list itself.
+type Filter struct {
+	k     uint32
+	m     uint64
+	count uint64
+	bits  []byte
+}
+
+// NewFilter returns an empty filter sized for n items
+func NewFilter(n int) *Filter {
+	if n < 1 {
+		n = 1
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	k := uint32(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &Filter{k: k, m: m, bits: make([]byte, (m+7)/8)}
+}
+
+// positions derives the k bit positions of an item by double hashing one SHA-256
+func (f *Filter) positions(item string) []uint64 {
+	sum := sha256.Sum256([]byte(item))
+	h1 := binary.BigEndian.Uint64(sum[0:8])
+	h2 := binary.BigEndian.Uint64(sum[8:16]) | 1
+	pos := make([]uint64, f.k)
+	for i := range pos {
+		pos[i] = (h1 + uint64(i)*h2) % f.m
+	}
+	return pos
+}
+
+// Add inserts a normalized item
+func (f *Filter) Add(item string) {
+	for _, p := range f.positions(item) {
+		f.bits[p/8] |= 1 << (p % 8)
+	}
+	f.count++
+}
+
+// Contains reports whether a normalized item is probably in the filter
+func (f *Filter) Contains(item string) bool {
+	for _, p := range f.positions(item) {
+		if f.bits[p/8]&(1<<(p%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Count returns the number of items added to the filter
+func (f *Filter) Count() uint64 {
+	return f.count
+}
+
+// MarshalBinary serializes the filter as magic, k, m, count and the bit array
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(bloomMagic)
+	_ = binary.Write(&buf, binary.BigEndian, f.k)
+	_ = binary.Write(&buf, binary.BigEndian, f.m)
+	_ = binary.Write(&buf, binary.BigEndian, f.count)
+	buf.Write(f.bits)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary loads a filter written by MarshalBinary
+func (f *Filter) UnmarshalBinary(data []byte) error {
+	header := len(bloomMagic) + 4 + 8 + 8
+	if len(data) < header || !bytes.HasPrefix(data, bloomMagic) {
+		return fmt.Errorf("not a vault.module bloom filter")
+	}
+	r := bytes.NewReader(data[len(bloomMagic):header])
+	_ = binary.Read(r, binary.BigEndian, &f.k)
+	_ = binary.Read(r, binary.BigEndian, &f.m)
+	_ = binary.Read(r, binary.BigEndian, &f.count)
+	if f.k == 0 || f.k > 64 || f.m == 0 || uint64(len(data)-header) != (f.m+7)/8 {
+		return fmt.Errorf("bloom filter header does not match its size")
+	}
+	f.bits = append([]byte(nil), data[header:]...)
+	return nil
+}
+
+// IsFilter reports whether data is a serialized filter rather than a text list
+func IsFilter(data []byte) bool {
+	return bytes.HasPrefix(data, bloomMagic)
+}
@@ -0,0 +1,179 @@
+// File: internal/leakcheck/leakcheck.go
+package leakcheck
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"vault.module/internal/errors"
+	"vault.module/internal/vault"
+)
+
+// DatabaseFile holds the updatable bloom filter of leaked keys and addresses.
+// It lives next to config.json and audit.log.
+const DatabaseFile = "leaked.bloom"
+
+// knownCompromised is the list shipped with the binary. It is checked even when
+// no database has been installed.
+//
+//go:embed known_compromised.txt
+var knownCompromised []byte
+
+var (
+	builtinOnce   sync.Once
+	builtinFilter *Filter
+)
+
+// builtin returns the filter of the shipped list
+func builtin() *Filter {
+	builtinOnce.Do(func() {
+		items := parseList(knownCompromised)
+		builtinFilter = NewFilter(len(items))
+		for _, item := range items {
+			builtinFilter.Add(item)
+		}
+	})
+	return builtinFilter
+}
+
+// normalize makes keys and addresses comparable regardless of case and 0x prefix
+func normalize(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.TrimPrefix(s, "0x")
+}
+
+// parseList reads one key or address per line, skipping blanks and # comments
+func parseList(data []byte) []string {
+	var items []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		items = append(items, normalize(line))
+	}
+	return items
+}
+
+// loadDatabase returns the installed filter, or nil if none is installed
+func loadDatabase() (*Filter, error) {
+	data, err := os.ReadFile(DatabaseFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewFileSystemError("read", DatabaseFile, err)
+	}
+	f := &Filter{}
+	if err := f.UnmarshalBinary(data); err != nil {
+		return nil, errors.NewFormatInvalidError(DatabaseFile, err.Error())
+	}
+	return f, nil
+}
+
+// Status describes the filters in use
+type Status struct {
+	BuiltinEntries    uint64
+	DatabaseEntries   uint64
+	DatabaseUpdated   string
+	DatabaseInstalled bool
+}
+
+// GetStatus reports the size of the shipped list and of the installed database
+func GetStatus() (Status, error) {
+	st := Status{BuiltinEntries: builtin().Count()}
+	db, err := loadDatabase()
+	if err != nil || db == nil {
+		return st, err
+	}
+	st.DatabaseInstalled = true
+	st.DatabaseEntries = db.Count()
+	if info, err := os.Stat(DatabaseFile); err == nil {
+		st.DatabaseUpdated = info.ModTime().Format("2006-01-02 15:04")
+	}
+	return st, nil
+}
+
+// MatchWallet checks the private keys and addresses of a wallet against the
+// shipped list and the installed database. It returns one description per match.
+func MatchWallet(w vault.Wallet) ([]string, error) {
+	filters := []*Filter{builtin()}
+	db, err := loadDatabase()
+	if err != nil {
+		return nil, err
+	}
+	if db != nil {
+		filters = append(filters, db)
+	}
+	contains := func(item string) bool {
+		for _, f := range filters {
+			if f.Contains(normalize(item)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var matches []string
+	for _, addr := range w.Addresses {
+		if addr.PrivateKey != nil && !addr.PrivateKey.IsEmpty() && contains(addr.PrivateKey.String()) {
+			matches = append(matches, fmt.Sprintf("address %d: private key is publicly known", addr.Index))
+		} else if addr.Address != "" && contains(addr.Address) {
+			matches = append(matches, fmt.Sprintf("address %d: %s is a publicly known address", addr.Index, addr.Address))
+		}
+	}
+	return matches, nil
+}
+
+// Install replaces the database with the given file, which is either a text list
+// of keys and addresses or a serialized bloom filter. It returns the entry count.
+func Install(data []byte) (uint64, error) {
+	f := &Filter{}
+	if IsFilter(data) {
+		if err := f.UnmarshalBinary(data); err != nil {
+			return 0, errors.NewFormatInvalidError("bloom", err.Error())
+		}
+	} else {
+		items := parseList(data)
+		if len(items) == 0 {
+			return 0, errors.NewInvalidInputError("leak list", "file contains no keys or addresses")
+		}
+		f = NewFilter(len(items))
+		for _, item := range items {
+			f.Add(item)
+		}
+	}
+	serialized, err := f.MarshalBinary()
+	if err != nil {
+		return 0, errors.New(errors.ErrCodeInternal, "failed to serialize leak database")
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(DatabaseFile), "leaked-*.tmp")
+	if err != nil {
+		return 0, errors.NewFileSystemError("create", DatabaseFile, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return 0, errors.NewFileSystemError("chmod", tmp.Name(), err)
+	}
+	if _, err := tmp.Write(serialized); err != nil {
+		tmp.Close()
+		return 0, errors.NewFileSystemError("write", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, errors.NewFileSystemError("close", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), DatabaseFile); err != nil {
+		return 0, errors.NewFileSystemError("rename", tmp.Name(), err)
+	}
+	return f.Count(), nil
+}
@@ -0,0 +1,228 @@
+// File: internal/lint/lint.go
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"vault.module/internal/scrub"
+	"vault.module/internal/vault"
+)
+
+// Severities of a rule. A rule set to SeverityOff is not checked.
+const (
+	SeverityOff     = "off"
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// Severities lists the severities, from the lowest
+var Severities = []string{SeverityOff, SeverityInfo, SeverityWarning, SeverityError}
+
+// SeverityRank orders severities; unknown ones rank -1
+func SeverityRank(severity string) int {
+	for i, s := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+// Input is what the rules look at: a decrypted vault and the records kept
+// about it. Secrets are never copied into issues.
+type Input struct {
+	Vault           vault.Vault
+	History         []vault.HistoryEntry           // Of the vault, oldest first
+	Access          map[string][]vault.AccessEntry // By prefix, when the vault keeps an access log
+	SignedAddresses map[string]bool                // Lower case, from the signed nonce record
+	StaleAfter      time.Duration
+	Now             time.Time
+}
+
+// Issue is a finding of a rule on a wallet
+type Issue struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Wallet   string `json:"wallet"`
+	Message  string `json:"message"`
+}
+
+// Rule is a best practice the vault is checked against
+type Rule struct {
+	ID          string
+	Description string
+	Severity    string // Default severity
+	check       func(in *Input, prefix string, w vault.Wallet) string
+}
+
+// Rules are the lint rules, in the order they are reported
+var Rules = []Rule{
+	{
+		ID:          "secret-in-notes",
+		Description: "Notes hold what looks like a private key or mnemonic",
+		Severity:    SeverityError,
+		check:       checkSecretInNotes,
+	},
+	{
+		ID:          "backup-unverified",
+		Description: "The wallet's backup is not marked verified in its checklist",
+		Severity:    SeverityWarning,
+		check:       checkBackupUnverified,
+	},
+	{
+		ID:          "stale-wallet",
+		Description: "The wallet was neither changed nor used for a long time",
+		Severity:    SeverityWarning,
+		check:       checkStaleWallet,
+	},
+	{
+		ID:          "unused-addresses",
+		Description: "Derived addresses never signed a transaction with vault.module",
+		Severity:    SeverityInfo,
+		check:       checkUnusedAddresses,
+	},
+	{
+		ID:          "missing-tags",
+		Description: "The wallet has no tags",
+		Severity:    SeverityInfo,
+		check:       checkMissingTags,
+	},
+}
+
+// FindRule returns the rule with the given ID, or nil
+func FindRule(id string) *Rule {
+	for i := range Rules {
+		if Rules[i].ID == id {
+			return &Rules[i]
+		}
+	}
+	return nil
+}
+
+// Run checks every wallet against every rule not turned off. severities
+// overrides the default severity of rules by ID. Issues are sorted by
+// severity, most severe first, then in rule and wallet order.
+func Run(in *Input, severities map[string]string) []Issue {
+	prefixes := make([]string, 0, len(in.Vault))
+	for prefix := range in.Vault {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	var issues []Issue
+	for _, rule := range Rules {
+		severity := rule.Severity
+		if s, ok := severities[rule.ID]; ok {
+			severity = s
+		}
+		if severity == SeverityOff {
+			continue
+		}
+		for _, prefix := range prefixes {
+			if msg := rule.check(in, prefix, in.Vault[prefix]); msg != "" {
+				issues = append(issues, Issue{Rule: rule.ID, Severity: severity, Wallet: prefix, Message: msg})
+			}
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return SeverityRank(issues[i].Severity) > SeverityRank(issues[j].Severity)
+	})
+	return issues
+}
+
+func checkSecretInNotes(_ *Input, _ string, w vault.Wallet) string {
+	findings := scrub.ScanText(w.Notes)
+	if len(findings) == 0 {
+		return ""
+	}
+	kinds := make([]string, 0, len(findings))
+	for _, f := range findings {
+		kinds = append(kinds, fmt.Sprintf("%s (%s)", f.Kind, f.Preview))
+	}
+	return "the notes hold what looks like a " + strings.Join(kinds, ", ") + "; move it to a secret with 'secret add' and edit the notes"
+}
+
+func checkBackupUnverified(_ *Input, prefix string, w vault.Wallet) string {
+	if w.Kind == vault.KindWatch {
+		return "" // Nothing to back up
+	}
+	if len(w.Checklist) == 0 {
+		return "no checklist records a verified backup; see 'checklist " + prefix + "'"
+	}
+	for _, item := range w.Checklist {
+		if strings.Contains(item.ID, "backup") && strings.Contains(item.ID, "verif") {
+			if item.Done {
+				return ""
+			}
+			return fmt.Sprintf("the checklist item %q is not done; mark it with 'checklist %s --done %s'", item.Title, prefix, item.ID)
+		}
+	}
+	return fmt.Sprintf("the checklist has no backup verification item; add one with 'checklist %s --add \"Backup verified\"'", prefix)
+}
+
+func checkStaleWallet(in *Input, prefix string, _ vault.Wallet) string {
+	if in.StaleAfter <= 0 {
+		return ""
+	}
+	var last time.Time
+	consider := func(stamp string) {
+		if t, err := time.Parse(time.RFC3339, stamp); err == nil && t.After(last) {
+			last = t
+		}
+	}
+	for _, e := range in.History {
+		if e.Wallet == prefix {
+			consider(e.Time)
+		}
+	}
+	for _, e := range in.Access[prefix] {
+		consider(e.Time)
+	}
+	if last.IsZero() {
+		return "" // The vault predates its history
+	}
+	if idle := in.Now.Sub(last); idle > in.StaleAfter {
+		return fmt.Sprintf("last changed or used %s, %d days ago; check that it is still needed and its backup still readable", last.Format("2006-01-02"), int(idle.Hours()/24))
+	}
+	return ""
+}
+
+// maxListedIndices bounds the indices named by unused-addresses
+const maxListedIndices = 10
+
+func checkUnusedAddresses(in *Input, _ string, w vault.Wallet) string {
+	if !w.IsHD() || len(w.Addresses) < 2 || in.SignedAddresses == nil {
+		return ""
+	}
+	var unused []string
+	for _, addr := range w.Addresses {
+		if !in.SignedAddresses[strings.ToLower(addr.Address)] {
+			index := strconv.Itoa(addr.Index)
+			if addr.Account != 0 {
+				index = fmt.Sprintf("%d/%d", addr.Account, addr.Index)
+			}
+			unused = append(unused, index)
+		}
+	}
+	// One address is expected to wait for its first use
+	if len(unused) < 2 {
+		return ""
+	}
+	listed := strings.Join(unused, ", ")
+	if len(unused) > maxListedIndices {
+		listed = strings.Join(unused[:maxListedIndices], ", ") + ", ..."
+	}
+	return fmt.Sprintf("%d of %d derived addresses never signed with vault.module (indices %s); derive addresses when they are needed", len(unused), len(w.Addresses), listed)
+}
+
+func checkMissingTags(_ *Input, prefix string, w vault.Wallet) string {
+	if len(w.Tags) > 0 {
+		return ""
+	}
+	return fmt.Sprintf("no tags; tag it by owner, purpose or chain with 'tags add %s <TAG>'", prefix)
+}
@@ -0,0 +1,179 @@
+// File: internal/nfc/nfc.go
+package nfc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// Tool is the OpenSC utility used to talk to PC/SC readers
+const Tool = "opensc-tool"
+
+// AID selects the vault.module transfer applet on the companion device
+// (a phone emulating a card over NFC, or a dedicated smart card applet).
+var AID = []byte{0xF0, 'V', 'A', 'U', 'L', 'T', 0x01}
+
+// MaxPayload bounds transfers; 16-bit chunk indexes of chunkSize bytes
+const MaxPayload = 0xFFFF * chunkSize
+
+const chunkSize = 240
+
+// Instructions of the transfer protocol. Every command is sent after SELECT.
+const (
+	insPutChunk = 0xD0 // P1P2 = chunk index, data = chunk
+	insCommit   = 0xD2 // data = length (4 bytes) || CRC32 (4 bytes)
+	insGetInfo  = 0xCA // response = length (4 bytes) || CRC32 (4 bytes)
+	insGetChunk = 0xCB // P1P2 = chunk index, response = chunk
+	claProtocol = 0x80
+)
+
+// Available reports whether the PC/SC tool is installed
+func Available() bool {
+	_, err := exec.LookPath(Tool)
+	return err == nil
+}
+
+// Send transfers payload to the companion device on reader ("" for the first reader)
+func Send(reader string, payload []byte) error {
+	if len(payload) == 0 || len(payload) > MaxPayload {
+		return fmt.Errorf("payload must be between 1 and %d bytes", MaxPayload)
+	}
+	apdus := [][]byte{selectAPDU()}
+	for i := 0; i*chunkSize < len(payload); i++ {
+		end := (i + 1) * chunkSize
+		if end > len(payload) {
+			end = len(payload)
+		}
+		apdus = append(apdus, apdu(insPutChunk, uint16(i), payload[i*chunkSize:end]))
+	}
+	var info [8]byte
+	binary.BigEndian.PutUint32(info[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(info[4:8], crc32.ChecksumIEEE(payload))
+	apdus = append(apdus, apdu(insCommit, 0, info[:]))
+
+	_, err := transmit(reader, apdus)
+	return err
+}
+
+// Receive fetches the payload offered by the companion device on reader
+func Receive(reader string) ([]byte, error) {
+	resp, err := transmit(reader, [][]byte{selectAPDU(), apdu(insGetInfo, 0, nil)})
+	if err != nil {
+		return nil, err
+	}
+	info := resp[1]
+	if len(info) != 8 {
+		return nil, fmt.Errorf("unexpected payload info from device")
+	}
+	length := binary.BigEndian.Uint32(info[0:4])
+	checksum := binary.BigEndian.Uint32(info[4:8])
+	if length == 0 || length > MaxPayload {
+		return nil, fmt.Errorf("device offers no payload or an oversized one (%d bytes)", length)
+	}
+
+	apdus := [][]byte{selectAPDU()}
+	chunks := (int(length) + chunkSize - 1) / chunkSize
+	for i := 0; i < chunks; i++ {
+		apdus = append(apdus, apdu(insGetChunk, uint16(i), nil))
+	}
+	resp, err = transmit(reader, apdus)
+	if err != nil {
+		return nil, err
+	}
+	payload := bytes.Join(resp[1:], nil)
+	if uint32(len(payload)) != length || crc32.ChecksumIEEE(payload) != checksum {
+		return nil, fmt.Errorf("payload from device failed verification")
+	}
+	return payload, nil
+}
+
+func selectAPDU() []byte {
+	return append([]byte{0x00, 0xA4, 0x04, 0x00, byte(len(AID))}, AID...)
+}
+
+// apdu builds a short APDU; commands without data expect a response (Le = 00)
+func apdu(ins byte, p1p2 uint16, data []byte) []byte {
+	cmd := []byte{claProtocol, ins, byte(p1p2 >> 8), byte(p1p2)}
+	if len(data) == 0 {
+		return append(cmd, 0x00)
+	}
+	return append(append(cmd, byte(len(data))), data...)
+}
+
+var receivedRegex = regexp.MustCompile(`^Received \(SW1=0x([0-9A-Fa-f]{2}), SW2=0x([0-9A-Fa-f]{2})\)`)
+
+// transmit sends the APDUs in a single card session and returns the response data
+// of each. Any status word other than 9000 aborts the exchange.
+func transmit(reader string, apdus [][]byte) ([][]byte, error) {
+	var args []string
+	if reader != "" {
+		args = append(args, "--reader", reader)
+	}
+	for _, a := range apdus {
+		args = append(args, "--send-apdu", strings.ToUpper(hex.EncodeToString(a)))
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(Tool, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %v: %s", Tool, err, strings.TrimSpace(stderr.String()))
+	}
+	responses, err := parseResponses(string(out))
+	if err != nil {
+		return nil, err
+	}
+	if len(responses) != len(apdus) {
+		return nil, fmt.Errorf("device answered %d of %d commands", len(responses), len(apdus))
+	}
+	return responses, nil
+}
+
+// parseResponses extracts the response data from opensc-tool output. Each response
+// is a "Received (SW1=.., SW2=..):" line followed by a hex dump of 16 bytes per
+// line, with the hex in the first 48 columns and an ASCII rendering after it.
+func parseResponses(out string) ([][]byte, error) {
+	var responses [][]byte
+	var current []byte
+	inResponse := false
+	flush := func() {
+		if inResponse {
+			responses = append(responses, current)
+		}
+		current, inResponse = nil, false
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if m := receivedRegex.FindStringSubmatch(line); m != nil {
+			flush()
+			if sw := strings.ToUpper(m[1] + m[2]); sw != "9000" {
+				return nil, fmt.Errorf("device returned status %s", sw)
+			}
+			inResponse = true
+			continue
+		}
+		if !inResponse {
+			continue
+		}
+		if strings.HasPrefix(line, "Sending:") || strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		hexPart := line
+		if len(hexPart) > 48 {
+			hexPart = hexPart[:48]
+		}
+		b, err := hex.DecodeString(strings.ReplaceAll(strings.TrimSpace(hexPart), " ", ""))
+		if err != nil {
+			return nil, fmt.Errorf("unexpected %s output: %q", Tool, line)
+		}
+		current = append(current, b...)
+	}
+	flush()
+	return responses, nil
+}
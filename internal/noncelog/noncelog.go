@@ -0,0 +1,116 @@
+// File: internal/noncelog/noncelog.go
+package noncelog
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"vault.module/internal/statefile"
+)
+
+// StateFile records the nonces signed by each key on each chain. It lives next
+// to config.json and audit.log; it holds addresses and hashes, no secret.
+const StateFile = "signed-nonces.json"
+
+var stateFile = statefile.File{Path: StateFile, Name: "signed nonce record"}
+
+// maxEntries bounds the record; the oldest entries are dropped first
+const maxEntries = 5000
+
+// Entry is a transaction signed with a nonce
+type Entry struct {
+	Address  string    `json:"address"`
+	ChainID  int64     `json:"chain_id"` // 0 for a transaction without EIP-155 chain ID
+	Nonce    uint64    `json:"nonce"`
+	SHA256   string    `json:"sha256"` // Of the unsigned transaction
+	Command  string    `json:"command"`
+	SignedAt time.Time `json:"signed_at"`
+}
+
+type state struct {
+	Entries []Entry `json:"entries"`
+}
+
+// Conflict returns the transaction signed earlier by address on the chain with
+// the same nonce and another payload than digest, or nil when there is none
+func Conflict(address string, chainID int64, nonce uint64, digest string) (*Entry, error) {
+	st := &state{}
+	if err := stateFile.Load(st); err != nil {
+		return nil, err
+	}
+	return st.conflict(address, chainID, nonce, digest), nil
+}
+
+// Claim records a transaction about to be signed, unless another one was
+// recorded with the same nonce: then it records nothing and returns that one.
+// The check and the record are made under one lock, so of two signers racing
+// for a nonce only one gets it.
+func Claim(address string, chainID int64, nonce uint64, digest, command string) (*Entry, error) {
+	st := &state{}
+	var earlier *Entry
+	err := stateFile.Update(st, func() error {
+		if earlier = st.conflict(address, chainID, nonce, digest); earlier == nil {
+			st.add(address, chainID, nonce, digest, command)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return earlier, nil
+}
+
+// Record adds a signed transaction to the record. Signing the same payload
+// again adds nothing.
+func Record(address string, chainID int64, nonce uint64, digest, command string) error {
+	st := &state{}
+	return stateFile.Update(st, func() error {
+		st.add(address, chainID, nonce, digest, command)
+		return nil
+	})
+}
+
+// SignedAddresses returns the addresses, in lower case, that signed a
+// transaction recorded here
+func SignedAddresses() (map[string]bool, error) {
+	st := &state{}
+	if err := stateFile.Load(st); err != nil {
+		return nil, err
+	}
+	signed := make(map[string]bool, len(st.Entries))
+	for _, e := range st.Entries {
+		signed[strings.ToLower(e.Address)] = true
+	}
+	return signed, nil
+}
+
+func (st *state) conflict(address string, chainID int64, nonce uint64, digest string) *Entry {
+	for i := len(st.Entries) - 1; i >= 0; i-- {
+		e := st.Entries[i]
+		if strings.EqualFold(e.Address, address) && e.ChainID == chainID && e.Nonce == nonce && e.SHA256 != digest {
+			return &e
+		}
+	}
+	return nil
+}
+
+func (st *state) add(address string, chainID int64, nonce uint64, digest, command string) {
+	for _, e := range st.Entries {
+		if strings.EqualFold(e.Address, address) && e.ChainID == chainID && e.Nonce == nonce && e.SHA256 == digest {
+			return
+		}
+	}
+	st.Entries = append(st.Entries, Entry{
+		Address:  address,
+		ChainID:  chainID,
+		Nonce:    nonce,
+		SHA256:   digest,
+		Command:  command,
+		SignedAt: time.Now().UTC(),
+	})
+	if len(st.Entries) > maxEntries {
+		sort.SliceStable(st.Entries, func(i, j int) bool { return st.Entries[i].SignedAt.Before(st.Entries[j].SignedAt) })
+		st.Entries = st.Entries[len(st.Entries)-maxEntries:]
+	}
+}
@@ -0,0 +1,86 @@
+// File: internal/ratelimit/ratelimit.go
+package ratelimit
+
+import (
+	"log/slog"
+	"time"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/errors"
+	"vault.module/internal/statefile"
+)
+
+// StateFile records recent secret retrievals. It lives next to config.json and audit.log.
+const StateFile = "ratelimit.json"
+
+var stateFile = statefile.File{Path: StateFile, Name: "rate limit state"}
+
+// Window is the sliding window the limits apply to
+const Window = time.Hour
+
+// Limits are the maximum number of secret retrievals per Window; zero disables a limit.
+type Limits struct {
+	Global int
+	Wallet int
+}
+
+// event is one recorded secret retrieval
+type event struct {
+	Vault  string    `json:"vault"`
+	Prefix string    `json:"prefix"`
+	At     time.Time `json:"at"`
+}
+
+type state struct {
+	Events []event `json:"events"`
+}
+
+// Allow checks the limits for a secret retrieval from vault/prefix and records it
+// if permitted, under the state file's lock: parallel retrievals are counted one
+// after the other. Exceeding a limit returns a RATE_LIMITED error and logs a
+// critical audit event.
+func Allow(vault, prefix string, limits Limits) error {
+	if limits.Global <= 0 && limits.Wallet <= 0 {
+		return nil
+	}
+
+	st := &state{}
+	return stateFile.Update(st, func() error {
+		now := time.Now().UTC()
+		cutoff := now.Add(-Window)
+		recent := st.Events[:0]
+		walletCount := 0
+		for _, e := range st.Events {
+			if e.At.Before(cutoff) {
+				continue
+			}
+			recent = append(recent, e)
+			if e.Vault == vault && e.Prefix == prefix {
+				walletCount++
+			}
+		}
+		st.Events = recent
+
+		if limits.Global > 0 && len(recent) >= limits.Global {
+			return deny("global", vault, prefix, limits.Global)
+		}
+		if limits.Wallet > 0 && walletCount >= limits.Wallet {
+			return deny("wallet", vault, prefix, limits.Wallet)
+		}
+
+		st.Events = append(st.Events, event{Vault: vault, Prefix: prefix, At: now})
+		return nil
+	})
+}
+
+func deny(scope, vault, prefix string, limit int) error {
+	audit.Logger.Error("CRITICAL: secret retrieval rate limit exceeded",
+		slog.String("severity", "CRITICAL"),
+		slog.String("scope", scope),
+		slog.String("vault", vault),
+		slog.String("prefix", prefix),
+		slog.Int("limit", limit))
+	return errors.NewRateLimitError(scope, limit, Window.String()).
+		WithContext("vault", vault).
+		WithContext("prefix", prefix)
+}
@@ -0,0 +1,133 @@
+// File: internal/release/manifest.go
+package release
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"strings"
+)
+
+// ManifestVersion is the version of the release manifest document
+const ManifestVersion = 1
+
+// SignatureSuffix is appended to the name of a manifest to name its signature
+const SignatureSuffix = ".sig"
+
+// Manifest lists the binaries of a release with their SHA-256. It is signed
+// with the release key: the signature file holds the base64 Ed25519 signature
+// of the manifest file's bytes, as written.
+type Manifest struct {
+	Version  int      `json:"version"`
+	Release  string   `json:"release"` // e.g. v1.4.0
+	Commit   string   `json:"commit"`
+	Builder  string   `json:"builder"`
+	BuiltAt  string   `json:"built_at,omitempty"`
+	Binaries []Binary `json:"binaries"`
+}
+
+// Binary is one executable of a release
+type Binary struct {
+	Name     string `json:"name"`
+	Platform string `json:"platform"` // GOOS/GOARCH
+	SHA256   string `json:"sha256"`   // Hex
+}
+
+// ParsePublicKey decodes a base64 Ed25519 public key
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("not a base64 Ed25519 public key")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// KeyFingerprint is the short form of a public key shown to users: the first
+// 16 bytes of its SHA-256, in hex groups
+func KeyFingerprint(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	encoded := hex.EncodeToString(sum[:16])
+	groups := make([]string, 0, len(encoded)/4)
+	for i := 0; i < len(encoded); i += 4 {
+		groups = append(groups, encoded[i:i+4])
+	}
+	return strings.Join(groups, ":")
+}
+
+// VerifyManifest checks the signature of a manifest with the release key and
+// parses it. Nothing in an unsigned manifest is trusted.
+func VerifyManifest(data, signature []byte, key ed25519.PublicKey) (*Manifest, error) {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("the signature is not a base64 Ed25519 signature")
+	}
+	if !ed25519.Verify(key, data, sig) {
+		return nil, fmt.Errorf("the manifest was not signed by the release key, or was changed after signing")
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("not a release manifest: %v", err)
+	}
+	if m.Version != ManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d", m.Version)
+	}
+	return &m, nil
+}
+
+// Find returns the binary of the manifest with the given hash, or nil
+func (m *Manifest) Find(sha256Hex string) *Binary {
+	for i := range m.Binaries {
+		if strings.EqualFold(m.Binaries[i].SHA256, sha256Hex) {
+			return &m.Binaries[i]
+		}
+	}
+	return nil
+}
+
+// HashFile returns the hex SHA-256 of a file
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Toolchain is what the Go toolchain recorded about the running build
+type Toolchain struct {
+	GoVersion string `json:"go_version"`
+	Revision  string `json:"vcs_revision,omitempty"`
+	Time      string `json:"vcs_time,omitempty"`
+	Modified  bool   `json:"vcs_modified,omitempty"` // Built from a tree with uncommitted changes
+}
+
+// ReadToolchain reads the build information embedded in the running binary
+func ReadToolchain() Toolchain {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return Toolchain{}
+	}
+	t := Toolchain{GoVersion: info.GoVersion}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			t.Revision = s.Value
+		case "vcs.time":
+			t.Time = s.Value
+		case "vcs.modified":
+			t.Modified = s.Value == "true"
+		}
+	}
+	return t
+}
@@ -0,0 +1,137 @@
+// File: internal/schema/schema.go
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"vault.module/internal/security"
+)
+
+// Draft is the JSON Schema dialect of the generated schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document or a part of one
+type Schema map[string]interface{}
+
+// Types whose JSON form is not what their Go structure suggests
+var (
+	secureStringType = reflect.TypeOf(security.SecureString{})
+	timeType         = reflect.TypeOf(time.Time{})
+	rawMessageType   = reflect.TypeOf(json.RawMessage{})
+)
+
+// Generate returns the schema of the JSON encoding of v's type, as written by
+// encoding/json: field names from json tags, fields without omitempty
+// required, pointers nullable. Named structs are put under $defs, so
+// recursive types work.
+func Generate(v interface{}, title, description string) Schema {
+	g := generator{defs: map[string]Schema{}}
+	t := reflect.TypeOf(v)
+	var root Schema
+	if t.Kind() == reflect.Struct {
+		// The document itself, rather than a $ref to it
+		root = g.structSchema(t)
+	} else {
+		root = g.schemaOf(t)
+	}
+	doc := Schema{"$schema": Draft, "title": title}
+	if description != "" {
+		doc["description"] = description
+	}
+	for k, val := range root {
+		doc[k] = val
+	}
+	if len(g.defs) > 0 {
+		doc["$defs"] = g.defs
+	}
+	return doc
+}
+
+type generator struct {
+	defs map[string]Schema
+}
+
+func (g *generator) schemaOf(t reflect.Type) Schema {
+	switch t {
+	case secureStringType:
+		return Schema{"type": "string"}
+	case timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return Schema{"anyOf": []Schema{g.schemaOf(t.Elem()), {"type": "null"}}}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "contentEncoding": "base64"}
+		}
+		// A nil slice is written as null
+		return Schema{"type": []string{"array", "null"}, "items": g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := t.Name()
+		if _, ok := g.defs[name]; !ok {
+			g.defs[name] = Schema{} // Placeholder for recursive references
+			g.defs[name] = g.structSchema(t)
+		}
+		return Schema{"$ref": "#/$defs/" + name}
+	}
+	// Interfaces and anything else: any JSON value
+	return Schema{}
+}
+
+// structSchema describes the fields of a struct, with embedded structs inlined
+func (g *generator) structSchema(t reflect.Type) Schema {
+	properties := Schema{}
+	var required []string
+	g.addFields(t, properties, &required)
+	s := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+func (g *generator) addFields(t reflect.Type, properties Schema, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			g.addFields(f.Type, properties, required)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = g.schemaOf(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// File: internal/scrub/scrub.go
+package scrub
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// Redaction replaces every match when redacting
+const Redaction = "[REDACTED]"
+
+// Kinds of secrets the scanner recognizes
+const (
+	KindPrivateKey = "private key"
+	KindMnemonic   = "mnemonic"
+)
+
+// Mnemonic lengths allowed by BIP-39
+var mnemonicLengths = []int{24, 21, 18, 15, 12}
+
+// hexKeyPattern matches 32-byte hex secrets (EVM and Cosmos secp256k1 private keys)
+var hexKeyPattern = regexp.MustCompile(`\b(0x)?[0-9a-fA-F]{64}\b`)
+
+var wordPattern = regexp.MustCompile(`[a-z]+`)
+
+// isBIP39Word reports whether w is in the BIP-39 English word list. The
+// library's own index is used: building a set here slowed every start.
+func isBIP39Word(w string) bool {
+	_, ok := bip39.GetWordIndex(w)
+	return ok
+}
+
+// Finding is one suspected secret in a file. Preview is masked and safe to print.
+type Finding struct {
+	File    string
+	Line    int
+	Kind    string
+	Preview string
+}
+
+// DefaultHistoryFiles returns the shell history files of the current user that exist.
+func DefaultHistoryFiles() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	candidates := []string{
+		filepath.Join(home, ".bash_history"),
+		filepath.Join(home, ".zsh_history"),
+		filepath.Join(home, ".sh_history"),
+		filepath.Join(home, ".history"),
+		filepath.Join(home, ".python_history"),
+		filepath.Join(home, ".node_repl_history"),
+		filepath.Join(home, ".local", "share", "fish", "fish_history"),
+	}
+	if histFile := os.Getenv("HISTFILE"); histFile != "" {
+		candidates = append(candidates, histFile)
+	}
+	if runtime.GOOS == "windows" {
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			candidates = append(candidates, filepath.Join(appData, "Microsoft", "Windows", "PowerShell", "PSReadLine", "ConsoleHost_history.txt"))
+		}
+	}
+
+	seen := make(map[string]bool)
+	var files []string
+	for _, path := range candidates {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// ScanFile reports suspected secrets in path. When redact is true every match is
+// replaced with Redaction and the file is rewritten atomically with its original mode.
+func ScanFile(path string, redact bool) ([]Finding, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		redacted, lineFindings := scanLine(line)
+		for i := range lineFindings {
+			lineFindings[i].File = path
+			lineFindings[i].Line = lineNo
+		}
+		findings = append(findings, lineFindings...)
+		out.WriteString(redacted)
+		out.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if redact && len(findings) > 0 {
+		if err := rewrite(path, out.Bytes(), info.Mode().Perm()); err != nil {
+			return findings, err
+		}
+	}
+	return findings, nil
+}
+
+// scanLine finds secrets in a single line and returns the line with them redacted
+func scanLine(line string) (string, []Finding) {
+	var findings []Finding
+
+	line = hexKeyPattern.ReplaceAllStringFunc(line, func(match string) string {
+		findings = append(findings, Finding{Kind: KindPrivateKey, Preview: mask(match)})
+		return Redaction
+	})
+
+	// Look for runs of consecutive BIP-39 words of a valid mnemonic length
+	locs := wordPattern.FindAllStringIndex(line, -1)
+	var result strings.Builder
+	last := 0
+	for i := 0; i < len(locs); {
+		run := 0
+		for i+run < len(locs) && isBIP39Word(line[locs[i+run][0]:locs[i+run][1]]) &&
+			(run == 0 || onlySpaces(line[locs[i+run-1][1]:locs[i+run][0]])) {
+			run++
+		}
+		length := 0
+		for _, n := range mnemonicLengths {
+			if run >= n {
+				length = n
+				break
+			}
+		}
+		if length == 0 {
+			i += max(run, 1)
+			continue
+		}
+
+		start, end := locs[i][0], locs[i+length-1][1]
+		findings = append(findings, Finding{Kind: KindMnemonic, Preview: mask(line[start:end])})
+		result.WriteString(line[last:start])
+		result.WriteString(Redaction)
+		last = end
+		i += length
+	}
+	result.WriteString(line[last:])
+	return result.String(), findings
+}
+
+// ScanArgs reports suspected secrets in command-line arguments, with the
+// indexes of the arguments holding them. A mnemonic is found as one quoted
+// argument or as one word per argument.
+func ScanArgs(args []string) ([]Finding, []int) {
+	var findings []Finding
+	var indexes []int
+	for i, arg := range args {
+		if _, found := scanLine(arg); len(found) > 0 {
+			findings = append(findings, found...)
+			indexes = append(indexes, i)
+		}
+	}
+
+	for i := 0; i < len(args); {
+		run := 0
+		for i+run < len(args) && isBIP39Word(args[i+run]) {
+			run++
+		}
+		length := 0
+		for _, n := range mnemonicLengths {
+			if run >= n {
+				length = n
+				break
+			}
+		}
+		if length == 0 {
+			i += max(run, 1)
+			continue
+		}
+		findings = append(findings, Finding{Kind: KindMnemonic, Preview: mask(strings.Join(args[i:i+length], " "))})
+		for j := i; j < i+length; j++ {
+			indexes = append(indexes, j)
+		}
+		i += length
+	}
+	return findings, indexes
+}
+
+// ScanText reports suspected secrets in free text, such as wallet notes. A
+// mnemonic may be split across lines.
+func ScanText(text string) []Finding {
+	_, findings := scanLine(text)
+	return findings
+}
+
+func onlySpaces(s string) bool {
+	return s != "" && strings.TrimSpace(s) == ""
+}
+
+// mask keeps only the first and last two characters of a match
+func mask(s string) string {
+	if len(s) <= 8 {
+		return strings.Repeat("*", len(s))
+	}
+	return s[:2] + "…" + s[len(s)-2:]
+}
+
+// rewrite atomically replaces path with data, keeping its permissions
+func rewrite(path string, data []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".scrub-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
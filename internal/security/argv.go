@@ -0,0 +1,36 @@
+//go:build linux || darwin
+// +build linux darwin
+
+// internal/security/argv.go
+package security
+
+import (
+	"os"
+	"strings"
+	"unsafe"
+)
+
+// ScrubArgs overwrites the arguments at indexes of os.Args in the memory the
+// process was started with, which ps and /proc/PID/cmdline show. os.Args is
+// replaced by copies first, so the program still sees the arguments. It
+// reports whether the platform allows it.
+func ScrubArgs(indexes []int) bool {
+	original := os.Args
+	copies := make([]string, len(original))
+	for i, arg := range original {
+		copies[i] = strings.Clone(arg)
+	}
+	os.Args = copies
+
+	// The runtime builds os.Args from the process's argv without copying it
+	for _, i := range indexes {
+		if i <= 0 || i >= len(original) || original[i] == "" {
+			continue
+		}
+		memory := unsafe.Slice(unsafe.StringData(original[i]), len(original[i]))
+		for j := range memory {
+			memory[j] = 'x'
+		}
+	}
+	return true
+}
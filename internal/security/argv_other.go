@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+// internal/security/argv_other.go
+package security
+
+// ScrubArgs cannot change the command line other processes see on this
+// platform: Windows keeps a copy of it in the process environment block.
+func ScrubArgs(indexes []int) bool {
+	return false
+}
@@ -129,8 +129,7 @@ func ClearClipboard() error {
 // CopyToClipboardWithAutoCleanup copies data and registers for shutdown cleanup
 func CopyToClipboardWithAutoCleanup(data string, description string) error {
 	// Register clipboard for cleanup before copying
-	// Note: Import shutdown package when this is used
-	// shutdown.RegisterClipboardGlobal(description)
+	RegisterClipboardGlobal(description)
 
 	return CopyToClipboard(data)
 }
@@ -0,0 +1,196 @@
+// File: internal/security/entropy.go
+package security
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// EntropyProvider is an additional source of randomness mixed into key and secret
+// generation. The operating system's generator is always used; providers can
+// only add to it, so a weak or hostile provider cannot reduce the entropy.
+type EntropyProvider interface {
+	// Name identifies the provider in logs and errors
+	Name() string
+	// Entropy returns n bytes from the source
+	Entropy(n int) ([]byte, error)
+}
+
+// providerSeedBytes is how much each provider contributes to a seed
+const providerSeedBytes = 32
+
+var (
+	entropyProviders   []EntropyProvider
+	entropyProvidersMu sync.Mutex
+)
+
+// SetEntropyProviders replaces the providers mixed into RandomReader
+func SetEntropyProviders(providers ...EntropyProvider) {
+	entropyProvidersMu.Lock()
+	defer entropyProvidersMu.Unlock()
+	entropyProviders = providers
+}
+
+// AddEntropyProvider adds a provider mixed into RandomReader
+func AddEntropyProvider(p EntropyProvider) {
+	entropyProvidersMu.Lock()
+	defer entropyProvidersMu.Unlock()
+	entropyProviders = append(entropyProviders, p)
+}
+
+// RandomReader returns a generator seeded from the operating system and every
+// provider: the seed is HMAC-SHA256 keyed with 32 bytes of OS randomness over the
+// length-prefixed provider outputs, and the stream is HMAC-SHA256 of a counter
+// under that seed. A provider that fails fails the generation.
+func RandomReader() (io.Reader, error) {
+	entropyProvidersMu.Lock()
+	providers := append([]EntropyProvider(nil), entropyProviders...)
+	entropyProvidersMu.Unlock()
+
+	if len(providers) == 0 {
+		return rand.Reader, nil
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("random number generator failed: %w", err)
+	}
+	defer SecureZero(key)
+	mac := hmac.New(sha256.New, key)
+	for _, p := range providers {
+		data, err := p.Entropy(providerSeedBytes)
+		if err != nil {
+			return nil, fmt.Errorf("entropy source %s failed: %w", p.Name(), err)
+		}
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(data)))
+		mac.Write(length[:])
+		mac.Write(data)
+		SecureZero(data)
+	}
+	return &mixedReader{seed: mac.Sum(nil)}, nil
+}
+
+// mixedReader expands a seed into a stream of HMAC-SHA256 blocks
+type mixedReader struct {
+	seed    []byte
+	counter uint64
+	buf     []byte
+}
+
+func (r *mixedReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(r.buf) == 0 {
+			mac := hmac.New(sha256.New, r.seed)
+			var c [8]byte
+			binary.BigEndian.PutUint64(c[:], r.counter)
+			r.counter++
+			mac.Write(c[:])
+			r.buf = mac.Sum(nil)
+		}
+		copied := copy(p[n:], r.buf)
+		SecureZero(r.buf[:copied])
+		r.buf = r.buf[copied:]
+		n += copied
+	}
+	return n, nil
+}
+
+// HWRNGProvider reads a hardware random number generator device
+type HWRNGProvider struct {
+	Path string // default /dev/hwrng
+}
+
+func (p HWRNGProvider) Name() string { return "hwrng" }
+
+func (p HWRNGProvider) Entropy(n int) ([]byte, error) {
+	path := p.Path
+	if path == "" {
+		path = "/dev/hwrng"
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data := make([]byte, n)
+	if _, err := io.ReadFull(f, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// YubiKeyChallengeProvider uses the HMAC-SHA1 challenge-response of a YubiKey OTP
+// slot through ykman. The response to a random challenge depends on the key's
+// secret, which never leaves the YubiKey.
+type YubiKeyChallengeProvider struct {
+	Slot int // OTP slot, 1 or 2 (default 2)
+}
+
+func (p YubiKeyChallengeProvider) Name() string { return "yubikey" }
+
+func (p YubiKeyChallengeProvider) Entropy(n int) ([]byte, error) {
+	slot := p.Slot
+	if slot == 0 {
+		slot = 2
+	}
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+	var out, stderr bytes.Buffer
+	cmd := exec.Command("ykman", "otp", "calculate", fmt.Sprintf("%d", slot), hex.EncodeToString(challenge))
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ykman otp calculate: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	response, err := hex.DecodeString(strings.TrimSpace(out.String()))
+	if err != nil || len(response) == 0 {
+		return nil, fmt.Errorf("unexpected challenge-response output")
+	}
+	// Stretch the 20-byte response to n bytes
+	data := make([]byte, 0, n)
+	for counter := byte(0); len(data) < n; counter++ {
+		sum := sha256.Sum256(append(append([]byte{counter}, challenge...), response...))
+		data = append(data, sum[:]...)
+	}
+	SecureZero(response)
+	return data[:n], nil
+}
+
+// DiceProvider contributes physical six-sided dice rolls
+type DiceProvider struct {
+	Rolls []byte // values 1 to 6
+}
+
+func (p DiceProvider) Name() string { return "dice" }
+
+func (p DiceProvider) Entropy(n int) ([]byte, error) {
+	if len(p.Rolls) == 0 {
+		return nil, fmt.Errorf("no dice rolls")
+	}
+	data := make([]byte, 0, n)
+	for counter := byte(0); len(data) < n; counter++ {
+		sum := sha256.Sum256(append([]byte{counter}, p.Rolls...))
+		data = append(data, sum[:]...)
+	}
+	return data[:n], nil
+}
+
+// DiceRollsFor returns how many six-sided dice rolls carry at least bits of entropy
+func DiceRollsFor(bits int) int {
+	// log2(6) > 2.58
+	return (bits*100 + 257) / 258
+}
@@ -0,0 +1,91 @@
+// File: internal/security/generate.go
+package security
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+// Character classes for generated passwords
+const (
+	lowerChars  = "abcdefghijklmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars  = "0123456789"
+	symbolChars = "!#$%&()*+,-./:;<=>?@[]^_{}~"
+)
+
+// PasswordSymbols are the symbols GeneratePassword draws from
+const PasswordSymbols = symbolChars
+
+// MinPasswordLength is the shortest password GeneratePassword produces
+const MinPasswordLength = 12
+
+// GeneratePassword returns a password of the given length drawn uniformly from
+// letters, digits and, unless disabled, symbols. Every class is represented.
+func GeneratePassword(length int, symbols bool) (string, error) {
+	if length < MinPasswordLength {
+		return "", fmt.Errorf("password length must be at least %d", MinPasswordLength)
+	}
+	classes := []string{lowerChars, upperChars, digitChars}
+	if symbols {
+		classes = append(classes, symbolChars)
+	}
+	alphabet := strings.Join(classes, "")
+	max := big.NewInt(int64(len(alphabet)))
+	random, err := RandomReader()
+	if err != nil {
+		return "", err
+	}
+
+	// Rejection sampling keeps the distribution uniform over all valid passwords
+	for {
+		password := make([]byte, length)
+		for i := range password {
+			n, err := rand.Int(random, max)
+			if err != nil {
+				return "", fmt.Errorf("random number generator failed: %w", err)
+			}
+			password[i] = alphabet[n.Int64()]
+		}
+		complete := true
+		for _, class := range classes {
+			if !strings.ContainsAny(string(password), class) {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			return string(password), nil
+		}
+	}
+}
+
+// GenerateHex returns n random bytes, hex encoded
+func GenerateHex(n int) (string, error) {
+	if n < 1 {
+		return "", fmt.Errorf("byte count must be positive")
+	}
+	b, err := RandomBytes(n)
+	if err != nil {
+		return "", err
+	}
+	defer SecureZero(b)
+	return hex.EncodeToString(b), nil
+}
+
+// RandomBytes returns n bytes from RandomReader. The caller must zero them.
+func RandomBytes(n int) ([]byte, error) {
+	random, err := RandomReader()
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(random, b); err != nil {
+		return nil, fmt.Errorf("random number generator failed: %w", err)
+	}
+	return b, nil
+}
@@ -4,9 +4,12 @@ package security
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
@@ -72,15 +75,140 @@ func (r *ClipboardResource) Description() string {
 	return r.description
 }
 
+// ProcessResource представляет дочерний процесс, получивший секреты через окружение
+type ProcessResource struct {
+	process     *os.Process
+	description string
+}
+
+func (r *ProcessResource) Cleanup() error {
+	if r.process == nil {
+		return nil
+	}
+	// Сначала просим процесс завершиться, затем принудительно
+	if err := r.process.Signal(syscall.SIGTERM); err != nil {
+		// Процесс уже завершился, либо платформа не поддерживает SIGTERM
+		_ = r.process.Kill()
+		return nil
+	}
+	time.Sleep(2 * time.Second)
+	_ = r.process.Kill()
+	return nil
+}
+
+func (r *ProcessResource) Description() string {
+	return r.description
+}
+
+// FuncResource runs a cleanup function, for state that is not one of the
+// resources above, such as a terminal in raw mode or a half-written file
+type FuncResource struct {
+	cleanup     func() error
+	description string
+}
+
+func (r *FuncResource) Cleanup() error {
+	if r.cleanup == nil {
+		return nil
+	}
+	return r.cleanup()
+}
+
+func (r *FuncResource) Description() string {
+	return r.description
+}
+
+// CleanupPriority orders cleanup at shutdown: resources of a higher priority
+// are cleaned first, those of equal priority in parallel
+type CleanupPriority int
+
+const (
+	// PrioritySecureString is last: earlier cleanups may still read secrets
+	PrioritySecureString CleanupPriority = 0
+	PriorityTempFile     CleanupPriority = 100
+	PriorityClipboard    CleanupPriority = 200
+	// PriorityProcess stops children that received secrets before the files
+	// and memory they may use are scrubbed
+	PriorityProcess CleanupPriority = 300
+	// PriorityTerminal restores the terminal first, so the messages of the
+	// other cleanups are readable
+	PriorityTerminal CleanupPriority = 400
+)
+
 // GracefulShutdownManager обрабатывает корректное завершение работы и очистку ресурсов
 type GracefulShutdownManager struct {
 	resources    []CleanupResource
+	priorities   map[CleanupResource]CleanupPriority
 	mu           sync.RWMutex
+	registry     atomic.Pointer[[]registration] // Copy of the registry that fastExit reads without m.mu
 	ctx          context.Context
 	cancel       context.CancelFunc
 	shutdownOnce sync.Once
 	isShutdown   bool
 	signals      chan os.Signal
+	scrubReport  bool        // Print the scrub report at shutdown
+	report       ScrubReport // What became of the registered resources
+}
+
+// registration is a registered resource with its priority
+type registration struct {
+	resource CleanupResource
+	priority CleanupPriority
+}
+
+// Resource kinds of the scrub report, in the order it lists them
+const (
+	ResourceSecureString = "secure strings"
+	ResourceTempFile     = "temp files"
+	ResourceClipboard    = "clipboard registrations"
+	ResourceProcess      = "child processes"
+	ResourceOther        = "other cleanups"
+)
+
+var resourceKinds = []string{ResourceSecureString, ResourceTempFile, ResourceClipboard, ResourceProcess, ResourceOther}
+
+// ScrubCounts counts the resources of one kind over a run
+type ScrubCounts struct {
+	Registered int // Registered with the manager
+	Released   int // Already cleaned or released by the command when it ended
+	Cleaned    int // Cleaned by the manager at shutdown
+	Leaked     int // Still holding sensitive data after shutdown
+}
+
+// ScrubReport tells what became of the sensitive resources registered during
+// a run: a resource is leaked when its cleanup failed or timed out, or when it
+// still holds its data afterwards (a SecureString not cleared, a temp file
+// still on disk).
+type ScrubReport struct {
+	Counts map[string]ScrubCounts // By resource kind
+	Leaks  []string               // Descriptions of the leaked resources, with the reason
+}
+
+// LeakCount returns the number of leaked resources
+func (r ScrubReport) LeakCount() int {
+	leaked := 0
+	for _, counts := range r.Counts {
+		leaked += counts.Leaked
+	}
+	return leaked
+}
+
+// Print writes the report for a person reading the output of a run
+func (r ScrubReport) Print(w io.Writer) {
+	fmt.Fprintln(w, "Scrub report:")
+	for _, kind := range resourceKinds {
+		c := r.Counts[kind]
+		fmt.Fprintf(w, "  %-24s %d registered, %d released by the command, %d cleaned at exit, %d leaked\n",
+			kind+":", c.Registered, c.Released, c.Cleaned, c.Leaked)
+	}
+	for _, leak := range r.Leaks {
+		fmt.Fprintf(w, "  LEAKED: %s\n", leak)
+	}
+	if leaked := r.LeakCount(); leaked > 0 {
+		fmt.Fprintf(w, "Scrub check FAILED: %d sensitive resource(s) survived the run.\n", leaked)
+	} else {
+		fmt.Fprintln(w, "Scrub check passed: no sensitive resource survived the run.")
+	}
 }
 
 var (
@@ -102,10 +230,12 @@ func newManager() *GracefulShutdownManager {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	manager := &GracefulShutdownManager{
-		resources: make([]CleanupResource, 0),
+		resources:  make([]CleanupResource, 0),
+		priorities: make(map[CleanupResource]CleanupPriority),
 		ctx:       ctx,
 		cancel:    cancel,
 		signals:   make(chan os.Signal, 1),
+		report:    ScrubReport{Counts: make(map[string]ScrubCounts)},
 	}
 
 	// Регистрируем обработчики сигналов
@@ -121,16 +251,85 @@ func newManager() *GracefulShutdownManager {
 	return manager
 }
 
-// signalHandler обрабатывает входящие сигналы завершения работы
+// fastExitTimeout bounds the cleanup still done when a second signal forces the exit
+const fastExitTimeout = 3 * time.Second
+
+// signalHandler обрабатывает входящие сигналы завершения работы. The first
+// signal cleans up and exits; a signal while cleanup runs, whoever started
+// it, exits at once after the fast cleanup of fastExit.
 func (m *GracefulShutdownManager) signalHandler() {
+	for {
+		select {
+		case sig := <-m.signals:
+			if m.IsShutdown() {
+				fmt.Fprintf(os.Stderr, "\nReceived signal %v during cleanup, exiting now...\n", sig)
+				m.fastExit()
+				os.Exit(signalExitCode(sig))
+			}
+			fmt.Fprintf(os.Stderr, "\nReceived signal %v, initiating graceful shutdown (send it again to exit at once)...\n", sig)
+			go func() {
+				m.Shutdown()
+				os.Exit(signalExitCode(sig))
+			}()
+		case <-m.ctx.Done():
+			// Контекст отменен, выходим корректно
+			return
+		}
+	}
+}
+
+// fastExit does what cannot be skipped before a forced exit, in priority
+// order and within fastExitTimeout: child processes are killed, the clipboard
+// is cleared and temp files are unlinked without their overwrite passes, then
+// registered functions down to PriorityTempFile (the terminal, half-written
+// files) run. Memory is released with the process. Cleanup that
+// is already running may do the same work again; every step tolerates that.
+func (m *GracefulShutdownManager) fastExit() {
+	// Cleanup in progress may hold the lock: read the copy of the registry
+	var resources []registration
+	if registry := m.registry.Load(); registry != nil {
+		resources = append(resources, *registry...)
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		return resources[i].priority > resources[j].priority
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, entry := range resources {
+			switch r := entry.resource.(type) {
+			case *ProcessResource:
+				if r.process != nil {
+					_ = r.process.Kill()
+				}
+			case *ClipboardResource:
+				_ = r.Cleanup()
+			case *TempFileResource:
+				_ = os.Remove(r.filePath)
+			}
+		}
+		// Registered functions may block, so they come after the built-in steps
+		for _, entry := range resources {
+			if r, ok := entry.resource.(*FuncResource); ok && entry.priority >= PriorityTempFile {
+				_ = r.Cleanup()
+			}
+		}
+	}()
 	select {
-	case sig := <-m.signals:
-		fmt.Fprintf(os.Stderr, "\nReceived signal %v, initiating graceful shutdown...\n", sig)
-		m.Shutdown()
-	case <-m.ctx.Done():
-		// Контекст отменен, выходим корректно
-		return
+	case <-done:
+	case <-time.After(fastExitTimeout):
+		fmt.Fprintln(os.Stderr, "WARNING: forced exit before cleanup finished.")
+	}
+}
+
+// signalExitCode returns the shell convention exit status for a signal, 128
+// plus its number
+func signalExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
 	}
+	return 1
 }
 
 // cleanupResourceWithTimeout выполняет очистку отдельного ресурса с таймаутом
@@ -186,10 +385,12 @@ func (m *GracefulShutdownManager) RegisterSecureString(secureStr interface{}, de
 		return
 	}
 
+	m.countLocked(ResourceSecureString, func(c *ScrubCounts) { c.Registered++ })
 	if m.isShutdown {
 		if clearable, ok := secureStr.(interface{ Clear() }); ok {
 			clearable.Clear()
 		}
+		m.countLocked(ResourceSecureString, func(c *ScrubCounts) { c.Cleaned++ })
 		return
 	}
 
@@ -198,7 +399,7 @@ func (m *GracefulShutdownManager) RegisterSecureString(secureStr interface{}, de
 		description: description,
 	}
 
-	m.resources = append(m.resources, resource)
+	m.addLocked(resource, PrioritySecureString)
 }
 
 // RegisterTempFile регистрирует временный файл для безопасной очистки
@@ -210,8 +411,10 @@ func (m *GracefulShutdownManager) RegisterTempFile(filePath string, description
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.countLocked(ResourceTempFile, func(c *ScrubCounts) { c.Registered++ })
 	if m.isShutdown {
 		SecureFileDelete(filePath)
+		m.recordLocked(&TempFileResource{filePath: filePath, description: description}, false, nil)
 		return
 	}
 
@@ -220,7 +423,7 @@ func (m *GracefulShutdownManager) RegisterTempFile(filePath string, description
 		description: description,
 	}
 
-	m.resources = append(m.resources, resource)
+	m.addLocked(resource, PriorityTempFile)
 }
 
 // RegisterClipboard регистрирует буфер обмена для очистки
@@ -228,8 +431,9 @@ func (m *GracefulShutdownManager) RegisterClipboard(description string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.countLocked(ResourceClipboard, func(c *ScrubCounts) { c.Registered++ })
 	if m.isShutdown {
-		ClearClipboard()
+		m.recordLocked(&ClipboardResource{description: description}, false, ClearClipboard())
 		return
 	}
 
@@ -237,7 +441,111 @@ func (m *GracefulShutdownManager) RegisterClipboard(description string) {
 		description: description,
 	}
 
+	m.addLocked(resource, PriorityClipboard)
+}
+
+// RegisterProcess регистрирует дочерний процесс для завершения при shutdown
+func (m *GracefulShutdownManager) RegisterProcess(process *os.Process, description string) {
+	if process == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.countLocked(ResourceProcess, func(c *ScrubCounts) { c.Registered++ })
+	if m.isShutdown {
+		_ = process.Kill()
+		m.countLocked(ResourceProcess, func(c *ScrubCounts) { c.Cleaned++ })
+		return
+	}
+
+	resource := &ProcessResource{
+		process:     process,
+		description: description,
+	}
+
+	m.addLocked(resource, PriorityProcess)
+}
+
+// Register adds a resource to clean at shutdown, in the order of its priority.
+// Code with its own kind of sensitive state registers it here, or with
+// RegisterFunc; the resource is cleaned at once if shutdown has begun.
+func (m *GracefulShutdownManager) Register(resource CleanupResource, priority CleanupPriority) {
+	if resource == nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.countLocked(resourceKind(resource), func(c *ScrubCounts) { c.Registered++ })
+	if !m.isShutdown {
+		m.addLocked(resource, priority)
+		m.mu.Unlock()
+		return
+	}
+	m.mu.Unlock()
+
+	err := resource.Cleanup()
+	m.record(resource, false, err)
+}
+
+// RegisterFunc registers cleanup as a resource to clean at shutdown and
+// returns it, for Unregister once the code has cleaned up itself
+func (m *GracefulShutdownManager) RegisterFunc(description string, priority CleanupPriority, cleanup func() error) CleanupResource {
+	resource := &FuncResource{cleanup: cleanup, description: description}
+	m.Register(resource, priority)
+	return resource
+}
+
+// Unregister removes a resource the code has cleaned itself from the registry
+func (m *GracefulShutdownManager) Unregister(resource CleanupResource) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, registered := range m.resources {
+		if registered == resource {
+			m.removeLocked(i)
+			m.countLocked(resourceKind(resource), func(c *ScrubCounts) { c.Released++ })
+			return
+		}
+	}
+}
+
+// addLocked adds a resource to the registry, with m.mu held
+func (m *GracefulShutdownManager) addLocked(resource CleanupResource, priority CleanupPriority) {
 	m.resources = append(m.resources, resource)
+	m.priorities[resource] = priority
+	m.publishLocked()
+}
+
+// removeLocked removes the i-th resource from the registry, with m.mu held
+func (m *GracefulShutdownManager) removeLocked(i int) {
+	delete(m.priorities, m.resources[i])
+	m.resources = append(m.resources[:i], m.resources[i+1:]...)
+	m.publishLocked()
+}
+
+// publishLocked replaces the copy of the registry read by fastExit, with m.mu held
+func (m *GracefulShutdownManager) publishLocked() {
+	registry := make([]registration, len(m.resources))
+	for i, resource := range m.resources {
+		registry[i] = registration{resource: resource, priority: m.priorities[resource]}
+	}
+	m.registry.Store(&registry)
+}
+
+// UnregisterProcess удаляет завершившийся дочерний процесс из реестра очистки
+func (m *GracefulShutdownManager) UnregisterProcess(process *os.Process) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, resource := range m.resources {
+		if pResource, ok := resource.(*ProcessResource); ok && pResource.process == process {
+			m.removeLocked(i)
+			m.countLocked(ResourceProcess, func(c *ScrubCounts) { c.Released++ })
+			break
+		}
+	}
 }
 
 // UnregisterSecureString удаляет SecureString из реестра очистки с таймаутом
@@ -266,7 +574,8 @@ func (m *GracefulShutdownManager) UnregisterSecureString(secureStr interface{})
 	for i, resource := range m.resources {
 		if ssResource, ok := resource.(*SecureStringResource); ok {
 			if ssResource.secureStr == secureStr {
-				m.resources = append(m.resources[:i], m.resources[i+1:]...)
+				m.removeLocked(i)
+				m.countLocked(ResourceSecureString, func(c *ScrubCounts) { c.Released++ })
 				break
 			}
 		}
@@ -315,6 +624,9 @@ func (m *GracefulShutdownManager) Shutdown() {
 
 		m.cancel()
 		fmt.Fprintln(os.Stderr, "Graceful shutdown completed.")
+		if m.ScrubReportEnabled() {
+			m.ScrubReport().Print(os.Stderr)
+		}
 	})
 }
 
@@ -323,14 +635,48 @@ func (m *GracefulShutdownManager) cleanupResources() {
 	m.mu.RLock()
 	resources := make([]CleanupResource, len(m.resources))
 	copy(resources, m.resources)
+	priorities := make(map[CleanupResource]CleanupPriority, len(m.priorities))
+	for resource, priority := range m.priorities {
+		priorities[resource] = priority
+	}
 	m.mu.RUnlock()
 
 	// Создаём контекст с таймаутом для общей операции очистки
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Resources the command already cleaned count as released in the report
+	released := make(map[CleanupResource]bool, len(resources))
+	for _, resource := range resources {
+		if done, known := scrubbed(resource); known && done {
+			released[resource] = true
+		}
+	}
+
+	// Higher priorities first, in registration order within a priority
+	sort.SliceStable(resources, func(i, j int) bool {
+		return priorities[resources[i]] > priorities[resources[j]]
+	})
+	for start := 0; start < len(resources); {
+		end := start + 1
+		for end < len(resources) && priorities[resources[end]] == priorities[resources[start]] {
+			end++
+		}
+		if !m.cleanupGroup(ctx, resources[start:end], released) {
+			for _, resource := range resources[end:] {
+				m.record(resource, false, fmt.Errorf("cleanup timed out"))
+			}
+			return
+		}
+		start = end
+	}
+}
+
+// cleanupGroup cleans resources of one priority in parallel and reports
+// whether it finished before ctx expired
+func (m *GracefulShutdownManager) cleanupGroup(ctx context.Context, resources []CleanupResource, released map[CleanupResource]bool) bool {
 	// Используем каналы для параллельной очистки с таймаутом
-	resultsCh := make(chan struct{
+	resultsCh := make(chan struct {
 		resource CleanupResource
 		err      error
 	}, len(resources))
@@ -341,17 +687,120 @@ func (m *GracefulShutdownManager) cleanupResources() {
 	}
 
 	// Собираем результаты
+	pending := make(map[CleanupResource]bool, len(resources))
+	for _, resource := range resources {
+		pending[resource] = true
+	}
 	for i := 0; i < len(resources); i++ {
 		select {
 		case result := <-resultsCh:
 			if result.err != nil {
 				fmt.Fprintf(os.Stderr, "Cleanup error for '%s': %v\n", result.resource.Description(), result.err)
 			}
+			delete(pending, result.resource)
+			m.record(result.resource, released[result.resource], result.err)
 		case <-ctx.Done():
 			fmt.Fprintf(os.Stderr, "Cleanup operation timed out\n")
+			for resource := range pending {
+				m.record(resource, false, fmt.Errorf("cleanup timed out"))
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// record adds the outcome of a resource's cleanup to the scrub report
+func (m *GracefulShutdownManager) record(resource CleanupResource, released bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordLocked(resource, released, err)
+}
+
+// recordLocked is record with m.mu held
+func (m *GracefulShutdownManager) recordLocked(resource CleanupResource, released bool, err error) {
+	kind := resourceKind(resource)
+	if released {
+		m.countLocked(kind, func(c *ScrubCounts) { c.Released++ })
+		return
+	}
+	if err == nil {
+		if done, known := scrubbed(resource); !known || done {
+			m.countLocked(kind, func(c *ScrubCounts) { c.Cleaned++ })
 			return
 		}
+		err = fmt.Errorf("still holds its data after cleanup")
+	}
+	m.countLocked(kind, func(c *ScrubCounts) { c.Leaked++ })
+	m.report.Leaks = append(m.report.Leaks, fmt.Sprintf("%s: %v", resource.Description(), err))
+}
+
+// countLocked updates the counts of a resource kind, with m.mu held
+func (m *GracefulShutdownManager) countLocked(kind string, update func(*ScrubCounts)) {
+	counts := m.report.Counts[kind]
+	update(&counts)
+	m.report.Counts[kind] = counts
+}
+
+// resourceKind returns the scrub report kind of a resource
+func resourceKind(resource CleanupResource) string {
+	switch resource.(type) {
+	case *SecureStringResource:
+		return ResourceSecureString
+	case *TempFileResource:
+		return ResourceTempFile
+	case *ClipboardResource:
+		return ResourceClipboard
+	case *ProcessResource:
+		return ResourceProcess
+	default:
+		return ResourceOther
+	}
+}
+
+// scrubbed reports whether a resource no longer holds sensitive data, and
+// whether that can be checked at all: the clipboard cannot be read back and a
+// process is only known by its cleanup error
+func scrubbed(resource CleanupResource) (done bool, known bool) {
+	switch r := resource.(type) {
+	case *SecureStringResource:
+		if emptiable, ok := r.secureStr.(interface{ IsEmpty() bool }); ok {
+			return emptiable.IsEmpty(), true
+		}
+	case *TempFileResource:
+		_, err := os.Stat(r.filePath)
+		return os.IsNotExist(err), true
 	}
+	return false, false
+}
+
+// EnableScrubReport makes Shutdown print the scrub report
+func (m *GracefulShutdownManager) EnableScrubReport() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scrubReport = true
+}
+
+// ScrubReportEnabled reports whether Shutdown prints the scrub report
+func (m *GracefulShutdownManager) ScrubReportEnabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.scrubReport
+}
+
+// ScrubReport returns what became of the registered resources so far; it is
+// complete once Shutdown has run
+func (m *GracefulShutdownManager) ScrubReport() ScrubReport {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	report := ScrubReport{
+		Counts: make(map[string]ScrubCounts, len(m.report.Counts)),
+		Leaks:  append([]string(nil), m.report.Leaks...),
+	}
+	for kind, counts := range m.report.Counts {
+		report.Counts[kind] = counts
+	}
+	return report
 }
 
 // IsShutdown возвращает true, если было инициировано завершение работы
@@ -380,6 +829,21 @@ func RegisterTempFileGlobal(filePath string, description string) {
 	GetManager().RegisterTempFile(filePath, description)
 }
 
+// RegisterGlobal adds a resource to clean at shutdown; see Register
+func RegisterGlobal(resource CleanupResource, priority CleanupPriority) {
+	GetManager().Register(resource, priority)
+}
+
+// RegisterFuncGlobal registers a cleanup function; see RegisterFunc
+func RegisterFuncGlobal(description string, priority CleanupPriority, cleanup func() error) CleanupResource {
+	return GetManager().RegisterFunc(description, priority, cleanup)
+}
+
+// UnregisterGlobal removes a resource the code has cleaned itself
+func UnregisterGlobal(resource CleanupResource) {
+	GetManager().Unregister(resource)
+}
+
 // RegisterClipboardGlobal регистрирует буфер обмена для очистки
 func RegisterClipboardGlobal(description string) {
 	GetManager().RegisterClipboard(description)
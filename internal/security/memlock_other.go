@@ -0,0 +1,10 @@
+//go:build !darwin && !linux
+// +build !darwin,!linux
+
+// internal/security/memlock_other.go
+package security
+
+// MemlockLimit is not determined on this platform
+func MemlockLimit() (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,24 @@
+//go:build darwin || linux
+// +build darwin linux
+
+// internal/security/memlock_unix.go
+package security
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// MemlockLimit returns how many bytes this process may lock into RAM
+// (RLIMIT_MEMLOCK), and whether there is a limit. Root is not limited.
+func MemlockLimit() (uint64, bool) {
+	if os.Geteuid() == 0 {
+		return 0, false
+	}
+	var rl unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_MEMLOCK, &rl); err != nil || rl.Cur == unix.RLIM_INFINITY {
+		return 0, false
+	}
+	return uint64(rl.Cur), true
+}
@@ -0,0 +1,170 @@
+// File: internal/security/passphrase.go
+package security
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Passphrase strength scores, as in zxcvbn
+const (
+	PassphraseTooGuessable      = 0 // Guessed within about a thousand tries
+	PassphraseVeryGuessable     = 1 // Guessed online, within about a million tries
+	PassphraseSomewhatGuessable = 2
+	PassphraseSafelyUnguessable = 3
+	PassphraseVeryUnguessable   = 4
+)
+
+// PassphraseStrength is an estimate of how hard a passphrase is to guess
+type PassphraseStrength struct {
+	Score        int      `json:"score"`         // 0 to 4, see the Passphrase* scores
+	GuessesLog10 float64  `json:"guesses_log10"` // Estimated guesses needed, as a power of ten
+	Warning      string   `json:"warning,omitempty"`
+	Suggestions  []string `json:"suggestions,omitempty"`
+}
+
+// PassphraseEstimator estimates the strength of passphrases the user chooses.
+// userInputs are words the passphrase should not be built from, such as the
+// names of the vault and wallet.
+type PassphraseEstimator interface {
+	// Name identifies the estimator in logs and errors
+	Name() string
+	// Estimate returns the strength of passphrase
+	Estimate(passphrase string, userInputs []string) (PassphraseStrength, error)
+}
+
+var (
+	passphraseEstimator   PassphraseEstimator = ZxcvbnEstimator{}
+	passphraseEstimatorMu sync.Mutex
+)
+
+// SetPassphraseEstimator replaces the estimator used by EstimatePassphrase
+func SetPassphraseEstimator(e PassphraseEstimator) {
+	passphraseEstimatorMu.Lock()
+	defer passphraseEstimatorMu.Unlock()
+	passphraseEstimator = e
+}
+
+// EstimatePassphrase estimates a passphrase with the configured estimator. It
+// also returns the estimator's name.
+func EstimatePassphrase(passphrase string, userInputs ...string) (PassphraseStrength, string, error) {
+	passphraseEstimatorMu.Lock()
+	e := passphraseEstimator
+	passphraseEstimatorMu.Unlock()
+
+	strength, err := e.Estimate(passphrase, userInputs)
+	if err != nil {
+		return PassphraseStrength{}, e.Name(), fmt.Errorf("passphrase estimator %s failed: %w", e.Name(), err)
+	}
+	if strength.Score < PassphraseTooGuessable || strength.Score > PassphraseVeryUnguessable {
+		return PassphraseStrength{}, e.Name(), fmt.Errorf("passphrase estimator %s returned score %d, not 0 to 4", e.Name(), strength.Score)
+	}
+	return strength, e.Name(), nil
+}
+
+// scoreForGuesses maps estimated guesses to a score with the zxcvbn thresholds
+func scoreForGuesses(log10 float64) int {
+	switch {
+	case log10 < 3:
+		return PassphraseTooGuessable
+	case log10 < 6:
+		return PassphraseVeryGuessable
+	case log10 < 8:
+		return PassphraseSomewhatGuessable
+	case log10 < 10:
+		return PassphraseSafelyUnguessable
+	default:
+		return PassphraseVeryUnguessable
+	}
+}
+
+// LengthEstimator scores a passphrase by its length and character classes
+// only, as if each character were chosen at random. It overrates passphrases
+// built from words or patterns; prefer ZxcvbnEstimator.
+type LengthEstimator struct{}
+
+func (LengthEstimator) Name() string { return "length" }
+
+func (LengthEstimator) Estimate(passphrase string, _ []string) (PassphraseStrength, error) {
+	var lower, upper, digit, other bool
+	for _, r := range passphrase {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+	charset := 0
+	if lower {
+		charset += 26
+	}
+	if upper {
+		charset += 26
+	}
+	if digit {
+		charset += 10
+	}
+	if other {
+		charset += 33
+	}
+	length := len([]rune(passphrase))
+	strength := PassphraseStrength{}
+	if charset > 0 {
+		strength.GuessesLog10 = float64(length) * math.Log10(float64(charset))
+	}
+	strength.Score = scoreForGuesses(strength.GuessesLog10)
+	if strength.Score < PassphraseSafelyUnguessable {
+		strength.Warning = "The passphrase is short"
+		strength.Suggestions = []string{"Use a longer passphrase"}
+	}
+	return strength, nil
+}
+
+// commandEstimatorTimeout bounds one run of an estimator command
+const commandEstimatorTimeout = 10 * time.Second
+
+// CommandEstimator runs an external program, such as a zxcvbn implementation,
+// with the passphrase on the first line of its standard input and the user
+// inputs on the following lines. It prints a PassphraseStrength as JSON.
+type CommandEstimator struct {
+	Path string
+}
+
+func (e CommandEstimator) Name() string { return "command" }
+
+func (e CommandEstimator) Estimate(passphrase string, userInputs []string) (PassphraseStrength, error) {
+	if strings.ContainsAny(passphrase, "\r\n") {
+		return PassphraseStrength{}, fmt.Errorf("the passphrase contains a line break")
+	}
+	input := []byte(passphrase + "\n" + strings.Join(userInputs, "\n") + "\n")
+	defer SecureZero(input)
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandEstimatorTimeout)
+	defer cancel()
+	var out, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.Path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return PassphraseStrength{}, fmt.Errorf("%s: %v: %s", e.Path, err, strings.TrimSpace(stderr.String()))
+	}
+	var strength PassphraseStrength
+	if err := json.Unmarshal(out.Bytes(), &strength); err != nil {
+		return PassphraseStrength{}, fmt.Errorf("%s printed no strength JSON: %v", e.Path, err)
+	}
+	return strength, nil
+}
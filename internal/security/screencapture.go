@@ -0,0 +1,81 @@
+// internal/security/screencapture.go
+package security
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"vault.module/internal/config"
+)
+
+// DefaultScreenCaptureProcesses are screen-sharing and recording tools checked
+// when screen_capture_processes is not configured. Matching is case-insensitive
+// on the executable name.
+var DefaultScreenCaptureProcesses = []string{
+	"zoom", "zoom.us", "teams", "ms-teams", "slack", "discord", "skype", "webex",
+	"obs", "obs64", "obs-studio", "simplescreenrecorder", "kazam", "vokoscreen",
+	"peek", "screenflow", "quicktime player", "loom", "anydesk", "teamviewer",
+	"vncserver", "x11vnc", "rustdesk", "ffmpeg",
+}
+
+// DetectScreenCapture returns the names of running processes that may be sharing or
+// recording the screen. Detection is best effort; an error listing processes yields no matches.
+func DetectScreenCapture() []string {
+	watchlist := config.Cfg.ScreenCaptureProcesses
+	if len(watchlist) == 0 {
+		watchlist = DefaultScreenCaptureProcesses
+	}
+
+	running, err := runningProcessNames()
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var matches []string
+	for _, proc := range running {
+		name := strings.ToLower(proc)
+		for _, watched := range watchlist {
+			if name == strings.ToLower(watched) && !seen[name] {
+				seen[name] = true
+				matches = append(matches, proc)
+			}
+		}
+	}
+	return matches
+}
+
+// runningProcessNames lists the executable names of running processes
+func runningProcessNames() ([]string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("tasklist", "/fo", "csv", "/nh")
+	default:
+		cmd = exec.Command("ps", "-A", "-o", "comm=")
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if runtime.GOOS == "windows" {
+			// "name.exe","pid",...
+			line = strings.Trim(strings.SplitN(line, ",", 2)[0], `"`)
+			line = strings.TrimSuffix(strings.ToLower(line), ".exe")
+		} else {
+			line = filepath.Base(line)
+		}
+		names = append(names, line)
+	}
+	return names, nil
+}
@@ -0,0 +1,31 @@
+// internal/security/secret_output.go
+package security
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// FIFOOpenTimeout is how long WriteSecretToFIFO waits for a reader
+const FIFOOpenTimeout = 60 * time.Second
+
+// WriteSecretToFD writes secret to an inherited file descriptor and closes it.
+// Descriptors 0-2 are rejected so secrets never end up on the terminal by accident.
+func WriteSecretToFD(fd int, secret string) error {
+	if fd < 3 {
+		return fmt.Errorf("file descriptor must be 3 or higher, got %d", fd)
+	}
+	f := os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd))
+	if f == nil {
+		return fmt.Errorf("file descriptor %d is not open", fd)
+	}
+	defer f.Close()
+
+	buf := []byte(secret)
+	defer secureZero(buf)
+	if _, err := f.Write(buf); err != nil {
+		return fmt.Errorf("failed to write to file descriptor %d: %w", fd, err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+//go:build !windows
+// +build !windows
+
+// internal/security/secret_output_unix.go
+package security
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// WriteSecretToFIFO creates a fresh 0600 named pipe at path, writes secret to the
+// first reader and removes the pipe. The path must not exist yet.
+func WriteSecretToFIFO(path string, secret string) error {
+	if err := unix.Mkfifo(path, 0600); err != nil {
+		return fmt.Errorf("failed to create FIFO %s: %w", path, err)
+	}
+	defer os.Remove(path)
+
+	// Opening a FIFO for writing blocks until a reader opens it
+	type openResult struct {
+		f   *os.File
+		err error
+	}
+	opened := make(chan openResult, 1)
+	go func() {
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		opened <- openResult{f, err}
+	}()
+
+	var f *os.File
+	select {
+	case res := <-opened:
+		if res.err != nil {
+			return fmt.Errorf("failed to open FIFO %s: %w", path, res.err)
+		}
+		f = res.f
+	case <-time.After(FIFOOpenTimeout):
+		// Unblock the pending open with a reader of our own, then discard it
+		if r, err := os.OpenFile(path, os.O_RDONLY|unix.O_NONBLOCK, 0); err == nil {
+			if res := <-opened; res.f != nil {
+				res.f.Close()
+			}
+			r.Close()
+		}
+		return fmt.Errorf("no reader opened FIFO %s within %s", path, FIFOOpenTimeout)
+	}
+	defer f.Close()
+
+	buf := []byte(secret)
+	defer secureZero(buf)
+	if _, err := f.Write(buf); err != nil {
+		return fmt.Errorf("failed to write to FIFO %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+//go:build windows
+// +build windows
+
+// internal/security/secret_output_windows.go
+package security
+
+import "fmt"
+
+// WriteSecretToFIFO is not supported on Windows, which has no POSIX named pipes
+func WriteSecretToFIFO(path string, secret string) error {
+	return fmt.Errorf("FIFO output is not supported on Windows")
+}
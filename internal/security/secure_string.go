@@ -382,6 +382,20 @@ func (s *SecureString) unlockMemoryWithRetry() error {
 	return lastErr
 }
 
+// VerifyMlock checks that secret buffers can actually be locked into RAM on this
+// system (platform support and RLIMIT_MEMLOCK). Used by the strict profile, which
+// refuses to run rather than fall back to swappable memory.
+func VerifyMlock() error {
+	probe := &SecureString{data: make([]byte, 64), pad: make([]byte, 64)}
+	if err := probe.lockMemoryWithTimeout(5 * time.Second); err != nil {
+		return err
+	}
+	if !probe.locked {
+		return fmt.Errorf("memory locking is not supported on this platform")
+	}
+	return probe.unlockMemory()
+}
+
 // lockMemoryWithTimeout attempts to lock memory with timeout protection
 func (s *SecureString) lockMemoryWithTimeout(timeout time.Duration) error {
 	lockComplete := make(chan error, 1)
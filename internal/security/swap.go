@@ -0,0 +1,50 @@
+// internal/security/swap.go
+package security
+
+import (
+	"fmt"
+	"strings"
+
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+)
+
+// Memory protection modes for the swap/hibernation check
+const (
+	MemoryProtectionOff    = "off"
+	MemoryProtectionWarn   = "warn"
+	MemoryProtectionStrict = "strict"
+)
+
+// MemoryExposure describes a way secrets may leave RAM despite mlock
+type MemoryExposure struct {
+	Kind   string // "swap" or "hibernation"
+	Detail string
+}
+
+// CheckMemoryExposure detects unencrypted swap and enabled hibernation. mlock keeps
+// secret pages out of swap, but a hibernation image contains all of RAM.
+// In warn mode the findings are returned for display; in strict mode they are an error.
+func CheckMemoryExposure() ([]MemoryExposure, error) {
+	mode := strings.ToLower(config.MemoryProtectionMode())
+	if mode == "" {
+		mode = MemoryProtectionWarn
+	}
+	if mode == MemoryProtectionOff {
+		return nil, nil
+	}
+
+	exposures := detectMemoryExposure()
+	if len(exposures) == 0 || mode != MemoryProtectionStrict {
+		return exposures, nil
+	}
+
+	details := make([]string, 0, len(exposures))
+	for _, e := range exposures {
+		details = append(details, fmt.Sprintf("%s: %s", e.Kind, e.Detail))
+	}
+	return exposures, errors.New(errors.ErrCodePermission, "refusing to run: secrets could be written to disk unencrypted").
+		WithDetails(strings.Join(details, "; ")).
+		WithContext("memory_protection", mode).
+		WithSeverity(errors.SeverityCritical)
+}
@@ -0,0 +1,37 @@
+//go:build darwin
+// +build darwin
+
+// internal/security/swap_darwin.go
+package security
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// detectMemoryExposure checks swap encryption and hibernation on macOS.
+// The hibernation image is only protected when FileVault is on.
+func detectMemoryExposure() []MemoryExposure {
+	var exposures []MemoryExposure
+
+	if out, err := exec.Command("sysctl", "-n", "vm.swapusage").Output(); err == nil {
+		if !strings.Contains(string(out), "encrypted") {
+			exposures = append(exposures, MemoryExposure{Kind: "swap", Detail: "swap is not encrypted"})
+		}
+	}
+
+	out, err := exec.Command("pmset", "-g").Output()
+	if err != nil {
+		return exposures
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "hibernatemode" && fields[1] != "0" {
+			fv, err := exec.Command("fdesetup", "status").Output()
+			if err != nil || !strings.Contains(string(fv), "FileVault is On") {
+				exposures = append(exposures, MemoryExposure{Kind: "hibernation", Detail: "hibernatemode is " + fields[1] + " and FileVault is off"})
+			}
+		}
+	}
+	return exposures
+}
@@ -0,0 +1,83 @@
+//go:build linux
+// +build linux
+
+// internal/security/swap_linux.go
+package security
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// detectMemoryExposure inspects /proc/swaps and /sys/power on Linux
+func detectMemoryExposure() []MemoryExposure {
+	var exposures []MemoryExposure
+
+	if data, err := os.ReadFile("/proc/swaps"); err == nil {
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		for _, line := range lines[1:] { // skip header
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+			name, kind := fields[0], fields[1]
+			if !swapIsEncrypted(name, kind) {
+				exposures = append(exposures, MemoryExposure{Kind: "swap", Detail: fmt.Sprintf("%s is not on an encrypted device", name)})
+			}
+		}
+	}
+
+	// A configured resume device means hibernation can write RAM to disk
+	if resume, err := os.ReadFile("/sys/power/resume"); err == nil {
+		dev := strings.TrimSpace(string(resume))
+		if dev != "" && dev != "0:0" && !blockDeviceIsEncrypted(dev) {
+			exposures = append(exposures, MemoryExposure{Kind: "hibernation", Detail: fmt.Sprintf("hibernation is enabled with unencrypted resume device %s", dev)})
+		}
+	}
+
+	return exposures
+}
+
+// swapIsEncrypted reports whether a swap area lives on dm-crypt or in RAM (zram)
+func swapIsEncrypted(name, kind string) bool {
+	if strings.HasPrefix(filepath.Base(name), "zram") {
+		return true
+	}
+
+	var st unix.Stat_t
+	if err := unix.Stat(name, &st); err != nil {
+		return false
+	}
+	dev := st.Dev // swap file: the filesystem's device
+	if kind == "partition" {
+		dev = st.Rdev
+	}
+	return blockDeviceIsEncrypted(fmt.Sprintf("%d:%d", unix.Major(dev), unix.Minor(dev)))
+}
+
+// blockDeviceIsEncrypted follows a "major:minor" device through device-mapper to dm-crypt
+func blockDeviceIsEncrypted(majorMinor string) bool {
+	uuid, err := os.ReadFile(filepath.Join("/sys/dev/block", majorMinor, "dm", "uuid"))
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(string(uuid), "CRYPT-") {
+		return true
+	}
+	// LVM on top of LUKS: check the devices below this one
+	slaves, err := os.ReadDir(filepath.Join("/sys/dev/block", majorMinor, "slaves"))
+	if err != nil {
+		return false
+	}
+	for _, slave := range slaves {
+		dev, err := os.ReadFile(filepath.Join("/sys/class/block", slave.Name(), "dev"))
+		if err == nil && blockDeviceIsEncrypted(strings.TrimSpace(string(dev))) {
+			return true
+		}
+	}
+	return false
+}
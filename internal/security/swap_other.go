@@ -0,0 +1,10 @@
+//go:build !darwin && !linux
+// +build !darwin,!linux
+
+// internal/security/swap_other.go
+package security
+
+// detectMemoryExposure is not implemented on this platform
+func detectMemoryExposure() []MemoryExposure {
+	return nil
+}
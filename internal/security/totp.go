@@ -0,0 +1,67 @@
+// File: internal/security/totp.go
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// totpPeriod and totpDigits are the RFC 6238 defaults used by authenticator apps
+const (
+	totpPeriod = 30
+	totpDigits = 6
+)
+
+// NormalizeTOTPSeed accepts a base32 seed, with or without spaces and padding, or an
+// otpauth:// URI, and returns the seed in canonical unpadded upper-case base32
+func NormalizeTOTPSeed(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(strings.ToLower(input), "otpauth://") {
+		u, err := url.Parse(input)
+		if err != nil {
+			return "", fmt.Errorf("invalid otpauth URI: %v", err)
+		}
+		input = u.Query().Get("secret")
+		if input == "" {
+			return "", fmt.Errorf("otpauth URI has no secret parameter")
+		}
+	}
+	seed := strings.ToUpper(strings.NewReplacer(" ", "", "-", "", "=", "").Replace(input))
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(seed)
+	if err != nil {
+		return "", fmt.Errorf("TOTP seed is not valid base32")
+	}
+	defer SecureZero(key)
+	if len(key) < 10 {
+		return "", fmt.Errorf("TOTP seed is too short (%d bytes, need at least 10)", len(key))
+	}
+	return seed, nil
+}
+
+// TOTPCode returns the 6-digit RFC 6238 code of a normalized seed at time t, and
+// the number of seconds it remains valid
+func TOTPCode(seed string, t time.Time) (string, int, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(seed)
+	if err != nil {
+		return "", 0, fmt.Errorf("TOTP seed is not valid base32")
+	}
+	defer SecureZero(key)
+
+	counter := uint64(t.Unix()) / totpPeriod
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	code := fmt.Sprintf("%0*d", totpDigits, value%1000000)
+	return code, totpPeriod - int(uint64(t.Unix())%totpPeriod), nil
+}
@@ -0,0 +1,660 @@
+// File: internal/security/zxcvbn.go
+package security
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/tyler-smith/go-bip39/wordlists"
+)
+
+// ZxcvbnEstimator estimates passphrases the way zxcvbn does (Wheeler, "zxcvbn:
+// Low-Budget Password Strength Estimation", USENIX Security 2016): the
+// passphrase is split into the sequence of patterns that an attacker trying
+// likely guesses first would need the fewest guesses for. Patterns are common
+// passwords, dictionary words (also capitalized, reversed or in l33t), the
+// user's own names, keyboard rows, sequences, repeats, years and dates; what no
+// pattern covers is brute-forced.
+type ZxcvbnEstimator struct{}
+
+func (ZxcvbnEstimator) Name() string { return "zxcvbn" }
+
+// zxcvbnMaxLength bounds the characters analyzed; what follows only adds guesses
+const zxcvbnMaxLength = 100
+
+// Constants of the zxcvbn guess estimates
+const (
+	zxcvbnMinGuessesSingleChar  = 10
+	zxcvbnMinGuessesMultiChar   = 50
+	zxcvbnMinYearSpace          = 20
+	zxcvbnBruteforceCardinality = 10
+	zxcvbnSequenceSeparator     = 10000 // The "D" of zxcvbn: cost of every additional pattern
+)
+
+// Pattern names of zxcvbnMatch
+const (
+	zxcvbnDictionary = "dictionary"
+	zxcvbnSpatial    = "spatial"
+	zxcvbnSequence   = "sequence"
+	zxcvbnRepeat     = "repeat"
+	zxcvbnDate       = "date"
+	zxcvbnBruteforce = "bruteforce"
+)
+
+// zxcvbnMatch is a pattern found at runes [i, j) of the passphrase
+type zxcvbnMatch struct {
+	i, j       int
+	pattern    string
+	log10      float64
+	dictionary string // Dictionary of a dictionary match
+	rank       int
+	l33t       bool
+	reversed   bool
+	uppercase  bool
+	base       string // Repeated base of a repeat match
+	year       bool   // A date match that is a year alone
+}
+
+// zxcvbnL33t maps l33t characters to the letters they stand for
+var zxcvbnL33t = map[rune]rune{
+	'4': 'a', '@': 'a', '8': 'b', '(': 'c', '{': 'c', '[': 'c', '<': 'c', '3': 'e',
+	'6': 'g', '9': 'g', '1': 'i', '!': 'i', '|': 'l', '0': 'o', '$': 's', '5': 's',
+	'+': 't', '7': 't', '%': 'x', '2': 'z',
+}
+
+// zxcvbnKeyboardRows are rows of the QWERTY keyboard and the keypad
+var zxcvbnKeyboardRows = []struct {
+	keys    string
+	shifted bool
+}{
+	{"`1234567890-=", false}, {"qwertyuiop[]\\", false}, {"asdfghjkl;'", false}, {"zxcvbnm,./", false},
+	{"~!@#$%^&*()_+", true}, {"QWERTYUIOP{}|", true}, {"ASDFGHJKL:\"", true}, {"ZXCVBNM<>?", true},
+	{"789", false}, {"456", false}, {"123", false}, {"741", false}, {"852", false}, {"963", false},
+}
+
+// zxcvbnPasswords are the most common passwords, most common first
+var zxcvbnPasswords = strings.Fields(`
+123456 password 12345678 qwerty 123456789 12345 1234 111111 1234567 dragon
+123123 baseball abc123 football monkey letmein 696969 shadow master 666666
+qwertyuiop 123321 mustang 1234567890 michael 654321 superman 1qaz2wsx 7777777
+121212 000000 qazwsx 123qwe killer trustno1 jordan jennifer zxcvbnm asdfgh
+hunter buster soccer harley batman andrew tigger sunshine iloveyou 2000
+charlie robert thomas hockey ranger daniel starwars klaster 112233 george
+computer michelle jessica pepper 1111 zxcvbn 555555 11111111 131313 freedom
+777777 pass maggie 159753 aaaaaa ginger princess joshua cheese amanda summer
+love ashley nicole chelsea biteme matthew access yankees 987654321 dallas
+austin thunder taylor matrix admin welcome login passw0rd password1 qwerty123
+secret letmein1 hello whatever nothing changeme default root toor test guest
+passphrase bitcoin ethereum satoshi crypto wallet ledger trezor metamask
+blockchain moon lambo hodl btc eth vault private mnemonic seed
+`)
+
+// zxcvbnEnglish are common English words, most common first
+var zxcvbnEnglish = strings.Fields(`
+the be to of and in that have it for not on with he as you do at this but his
+by from they we say her she or an will my one all would there their what so up
+out if about who get which go me when make can like time no just him know take
+people into year your good some could them see other than then now look only
+come its over think also back after use two how our work first well way even
+new want because any these give day most us is was are been has had were said
+did love life world money home house family friend friends baby girl boy man
+woman god heart happy summer winter spring autumn dog cat horse tiger lion dragon
+angel star sun moon sky blue red green black white secret power master king
+queen prince princess magic music game football soccer hockey baseball golf
+computer internet email phone dream forever always never sweet honey sugar
+cookie chocolate coffee apple orange banana cherry pepper ginger flower rose
+correct battery staple purple yellow silver golden diamond crystal freedom
+password welcome hello monkey shadow hunter ranger killer soldier dance
+`)
+
+var (
+	zxcvbnDictionaries     map[string]map[string]int
+	zxcvbnDictionariesOnce sync.Once
+)
+
+// zxcvbnRanked builds the ranked dictionaries
+func zxcvbnRanked() map[string]map[string]int {
+	zxcvbnDictionariesOnce.Do(func() {
+		rank := func(words []string) map[string]int {
+			ranked := make(map[string]int, len(words))
+			for i, w := range words {
+				if _, ok := ranked[w]; !ok {
+					ranked[w] = i + 1
+				}
+			}
+			return ranked
+		}
+		// BIP-39 words are not ranked by frequency: each counts as one of the list
+		bip39 := make(map[string]int, len(wordlists.English))
+		for _, w := range wordlists.English {
+			bip39[w] = len(wordlists.English)
+		}
+		zxcvbnDictionaries = map[string]map[string]int{
+			"passwords": rank(zxcvbnPasswords),
+			"english":   rank(zxcvbnEnglish),
+			"bip39":     bip39,
+		}
+	})
+	return zxcvbnDictionaries
+}
+
+func (ZxcvbnEstimator) Estimate(passphrase string, userInputs []string) (PassphraseStrength, error) {
+	runes := []rune(passphrase)
+	if len(runes) == 0 {
+		return PassphraseStrength{
+			Score:       PassphraseTooGuessable,
+			Warning:     "The passphrase is empty",
+			Suggestions: []string{"Use a few words, avoid common phrases"},
+		}, nil
+	}
+	extra := 0
+	if len(runes) > zxcvbnMaxLength {
+		extra = len(runes) - zxcvbnMaxLength
+		runes = runes[:zxcvbnMaxLength]
+	}
+
+	inputs := make(map[string]int)
+	for i, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if input != "" {
+			if _, ok := inputs[input]; !ok {
+				inputs[input] = i + 1
+			}
+		}
+	}
+
+	z := zxcvbnState{inputs: inputs, baseGuesses: make(map[string]float64)}
+	log10, sequence := z.mostGuessable(runes)
+	log10 += float64(extra) // Characters past the analyzed length are brute-forced
+	strength := PassphraseStrength{
+		Score:        scoreForGuesses(log10),
+		GuessesLog10: math.Round(log10*100) / 100,
+	}
+	if strength.Score < PassphraseSafelyUnguessable {
+		strength.Warning, strength.Suggestions = zxcvbnFeedback(sequence, len(runes))
+	}
+	return strength, nil
+}
+
+// zxcvbnState holds what one estimate needs across recursive repeat matches
+type zxcvbnState struct {
+	inputs      map[string]int
+	baseGuesses map[string]float64
+}
+
+// mostGuessable returns the guesses, as a power of ten, of the match sequence
+// covering runes that needs the fewest guesses, and that sequence. It minimizes
+// l! * product(guesses of the l matches) + D^(l-1) over all sequences.
+func (z *zxcvbnState) mostGuessable(runes []rune) (float64, []zxcvbnMatch) {
+	n := len(runes)
+	matches := z.matches(runes)
+	ending := make([][]zxcvbnMatch, n+1)
+	for _, m := range matches {
+		ending[m.j] = append(ending[m.j], m)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j <= n; j++ {
+			ending[j] = append(ending[j], zxcvbnMatch{i: i, j: j, pattern: zxcvbnBruteforce, log10: z.bruteforceLog10(j-i, n)})
+		}
+	}
+
+	// best[k][l] is the least log10 product of l matches covering runes[:k]
+	inf := math.Inf(1)
+	best := make([][]float64, n+1)
+	back := make([][]*zxcvbnMatch, n+1)
+	for k := range best {
+		best[k] = make([]float64, n+1)
+		back[k] = make([]*zxcvbnMatch, n+1)
+		for l := range best[k] {
+			best[k][l] = inf
+		}
+	}
+	best[0][0] = 0
+	for k := 1; k <= n; k++ {
+		for idx := range ending[k] {
+			m := &ending[k][idx]
+			for l := 1; l <= k; l++ {
+				if prev := best[m.i][l-1]; prev != inf && prev+m.log10 < best[k][l] {
+					best[k][l] = prev + m.log10
+					back[k][l] = m
+				}
+			}
+		}
+	}
+
+	total, length := inf, 0
+	for l := 1; l <= n; l++ {
+		if best[n][l] == inf {
+			continue
+		}
+		lgamma, _ := math.Lgamma(float64(l + 1))
+		guesses := zxcvbnLog10Sum(lgamma/math.Ln10+best[n][l], float64(l-1)*math.Log10(zxcvbnSequenceSeparator))
+		if guesses < total {
+			total, length = guesses, l
+		}
+	}
+
+	sequence := make([]zxcvbnMatch, length)
+	for k, l := n, length; l > 0; l-- {
+		m := back[k][l]
+		sequence[l-1] = *m
+		k = m.i
+	}
+	return total, sequence
+}
+
+// zxcvbnLog10Sum returns log10(10^a + 10^b)
+func zxcvbnLog10Sum(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	return a + math.Log10(1+math.Pow(10, b-a))
+}
+
+// bruteforceLog10 returns the guesses of length brute-forced characters
+func (z *zxcvbnState) bruteforceLog10(length, total int) float64 {
+	log10 := float64(length) * math.Log10(zxcvbnBruteforceCardinality)
+	if length < total {
+		return math.Max(log10, z.minimumLog10(length))
+	}
+	return log10
+}
+
+// minimumLog10 is the least a pattern of length runes counts for
+func (z *zxcvbnState) minimumLog10(length int) float64 {
+	if length == 1 {
+		return math.Log10(zxcvbnMinGuessesSingleChar)
+	}
+	return math.Log10(zxcvbnMinGuessesMultiChar)
+}
+
+// matches finds every pattern in runes
+func (z *zxcvbnState) matches(runes []rune) []zxcvbnMatch {
+	var matches []zxcvbnMatch
+	matches = append(matches, z.dictionaryMatches(runes)...)
+	matches = append(matches, zxcvbnSpatialMatches(runes)...)
+	matches = append(matches, zxcvbnSequenceMatches(runes)...)
+	matches = append(matches, z.repeatMatches(runes)...)
+	matches = append(matches, zxcvbnDateMatches(runes)...)
+	for i := range matches {
+		if length := matches[i].j - matches[i].i; length < len(runes) {
+			matches[i].log10 = math.Max(matches[i].log10, z.minimumLog10(length))
+		}
+	}
+	return matches
+}
+
+// dictionaryMatches finds dictionary words, plain, reversed and in l33t
+func (z *zxcvbnState) dictionaryMatches(runes []rune) []zxcvbnMatch {
+	dictionaries := zxcvbnRanked()
+	lookup := func(word string) (string, int) {
+		bestDictionary, bestRank := "", 0
+		if rank, ok := z.inputs[word]; ok {
+			bestDictionary, bestRank = "user_inputs", rank
+		}
+		for name, ranked := range dictionaries {
+			if rank, ok := ranked[word]; ok && (bestRank == 0 || rank < bestRank) {
+				bestDictionary, bestRank = name, rank
+			}
+		}
+		return bestDictionary, bestRank
+	}
+
+	var matches []zxcvbnMatch
+	lower := []rune(strings.ToLower(string(runes)))
+	if len(lower) != len(runes) {
+		return nil // Case mapping changed the length: no dictionary holds such words
+	}
+	for i := 0; i < len(runes); i++ {
+		for j := i + 1; j <= len(runes); j++ {
+			token := runes[i:j]
+			word := string(lower[i:j])
+			upper := zxcvbnUppercaseLog10(token)
+			if dictionary, rank := lookup(word); rank > 0 {
+				matches = append(matches, zxcvbnMatch{i: i, j: j, pattern: zxcvbnDictionary, dictionary: dictionary, rank: rank,
+					uppercase: upper > 0, log10: math.Log10(float64(rank)) + upper})
+			}
+			if j-i > 1 {
+				if dictionary, rank := lookup(zxcvbnReverse(word)); rank > 0 {
+					matches = append(matches, zxcvbnMatch{i: i, j: j, pattern: zxcvbnDictionary, dictionary: dictionary, rank: rank,
+						reversed: true, uppercase: upper > 0, log10: math.Log10(float64(rank)) + upper + math.Log10(2)})
+				}
+			}
+			if unl33t, subs := zxcvbnUnl33t(lower[i:j]); subs > 0 && j-i > 1 {
+				if dictionary, rank := lookup(unl33t); rank > 0 {
+					matches = append(matches, zxcvbnMatch{i: i, j: j, pattern: zxcvbnDictionary, dictionary: dictionary, rank: rank,
+						l33t: true, uppercase: upper > 0, log10: math.Log10(float64(rank)) + upper + float64(subs)*math.Log10(2)})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// zxcvbnUppercaseLog10 is the factor, as a power of ten, that the capitals of a
+// word add: none for lowercase, two for a capitalized or all-caps word, and
+// the ways to place the capitals otherwise
+func zxcvbnUppercaseLog10(token []rune) float64 {
+	upper, lower := 0, 0
+	for _, r := range token {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 0
+	}
+	first, last := unicode.IsUpper(token[0]), unicode.IsUpper(token[len(token)-1])
+	if lower == 0 || (upper == 1 && (first || last)) {
+		return math.Log10(2)
+	}
+	variations := 0.0
+	for k := 1; k <= min(upper, lower); k++ {
+		variations += zxcvbnBinomial(upper+lower, k)
+	}
+	return math.Log10(variations)
+}
+
+// zxcvbnBinomial returns n choose k
+func zxcvbnBinomial(n, k int) float64 {
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
+
+// zxcvbnUnl33t replaces l33t characters with the letters they stand for and
+// returns the number of replacements
+func zxcvbnUnl33t(token []rune) (string, int) {
+	out := make([]rune, len(token))
+	subs := 0
+	for i, r := range token {
+		if letter, ok := zxcvbnL33t[r]; ok {
+			out[i] = letter
+			subs++
+		} else {
+			out[i] = r
+		}
+	}
+	return string(out), subs
+}
+
+// zxcvbnReverse reverses a string
+func zxcvbnReverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+// zxcvbnSpatialMatches finds runs of three or more adjacent keys of a row
+func zxcvbnSpatialMatches(runes []rune) []zxcvbnMatch {
+	var matches []zxcvbnMatch
+	for _, row := range zxcvbnKeyboardRows {
+		position := make(map[rune]int, len(row.keys))
+		for i, r := range row.keys {
+			position[r] = i
+		}
+		for i := 0; i < len(runes); {
+			j := i + 1
+			for j < len(runes) {
+				a, okA := position[runes[j-1]]
+				b, okB := position[runes[j]]
+				if !okA || !okB || (a-b != 1 && b-a != 1) {
+					break
+				}
+				j++
+			}
+			if j-i >= 3 {
+				// A starting key, a direction and one turn per run
+				log10 := math.Log10(float64(len(row.keys)) * 2 * float64(j-i))
+				if row.shifted {
+					log10 += math.Log10(2)
+				}
+				matches = append(matches, zxcvbnMatch{i: i, j: j, pattern: zxcvbnSpatial, log10: log10})
+				i = j
+				continue
+			}
+			i++
+		}
+	}
+	return matches
+}
+
+// zxcvbnSequenceMatches finds runs of three or more characters with a constant
+// step of at most five, such as "abc", "9753" or "zyx"
+func zxcvbnSequenceMatches(runes []rune) []zxcvbnMatch {
+	var matches []zxcvbnMatch
+	for i := 0; i+2 < len(runes); {
+		delta := runes[i+1] - runes[i]
+		if delta == 0 || delta > 5 || delta < -5 {
+			i++
+			continue
+		}
+		j := i + 2
+		for j < len(runes) && runes[j]-runes[j-1] == delta {
+			j++
+		}
+		if j-i < 3 {
+			i++
+			continue
+		}
+		first := runes[i]
+		var base float64
+		switch {
+		case strings.ContainsRune("aAzZ019", first):
+			base = 4 // Obvious starts
+		case unicode.IsDigit(first):
+			base = 10
+		default:
+			base = 26
+		}
+		if delta < 0 {
+			base *= 2
+		}
+		matches = append(matches, zxcvbnMatch{i: i, j: j, pattern: zxcvbnSequence, log10: math.Log10(base * float64(j-i))})
+		i = j - 1
+	}
+	return matches
+}
+
+// repeatMatches finds a base repeated at least twice, or a character repeated
+// at least three times. A repeat counts the guesses of its base times the
+// number of repeats.
+func (z *zxcvbnState) repeatMatches(runes []rune) []zxcvbnMatch {
+	var matches []zxcvbnMatch
+	for i := 0; i < len(runes); i++ {
+		for size := 1; i+2*size <= len(runes); size++ {
+			count := 1
+			for i+(count+1)*size <= len(runes) && string(runes[i+count*size:i+(count+1)*size]) == string(runes[i:i+size]) {
+				count++
+			}
+			if count < 2 || (size == 1 && count < 3) {
+				continue
+			}
+			base := string(runes[i : i+size])
+			baseLog10, ok := z.baseGuesses[base]
+			if !ok {
+				baseLog10, _ = z.mostGuessable(runes[i : i+size])
+				z.baseGuesses[base] = baseLog10
+			}
+			matches = append(matches, zxcvbnMatch{i: i, j: i + count*size, pattern: zxcvbnRepeat, base: base,
+				log10: baseLog10 + math.Log10(float64(count))})
+		}
+	}
+	return matches
+}
+
+// zxcvbnReferenceYear is the year dates are compared with
+var zxcvbnReferenceYear = time.Now().Year()
+
+// zxcvbnDateMatches finds years from 1900 to 2099 and dates of 4 to 8 digits,
+// alone or with separators, in day-month-year, month-day-year or
+// year-month-day order
+func zxcvbnDateMatches(runes []rune) []zxcvbnMatch {
+	var matches []zxcvbnMatch
+	yearSpace := func(year int) float64 {
+		return math.Max(math.Abs(float64(year-zxcvbnReferenceYear)), zxcvbnMinYearSpace)
+	}
+	for i := 0; i < len(runes); i++ {
+		for j := i + 4; j <= len(runes) && j-i <= 10; j++ {
+			token := string(runes[i:j])
+			if j-i == 4 && zxcvbnDigits(token) {
+				if year, _ := strconv.Atoi(token); year >= 1900 && year <= 2099 {
+					matches = append(matches, zxcvbnMatch{i: i, j: j, pattern: zxcvbnDate, year: true, log10: math.Log10(yearSpace(year))})
+				}
+			}
+			if year, separator, ok := zxcvbnParseDate(token); ok {
+				log10 := math.Log10(yearSpace(year) * 365)
+				if separator {
+					log10 += math.Log10(4)
+				}
+				matches = append(matches, zxcvbnMatch{i: i, j: j, pattern: zxcvbnDate, log10: log10})
+			}
+		}
+	}
+	return matches
+}
+
+// zxcvbnDigits reports whether s is only ASCII digits
+func zxcvbnDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// zxcvbnParseDate parses a date and returns its year and whether it has separators
+func zxcvbnParseDate(token string) (int, bool, bool) {
+	var parts []string
+	separator := false
+	for _, sep := range []string{"/", "-", ".", "_", " ", "\\"} {
+		if fields := strings.Split(token, sep); len(fields) == 3 {
+			parts, separator = fields, true
+			break
+		}
+	}
+	if !separator {
+		if !zxcvbnDigits(token) || len(token) < 4 || len(token) > 8 {
+			return 0, false, false
+		}
+		// Every split of the digits into three parts of one to four
+		for a := 1; a <= 4 && a < len(token); a++ {
+			for b := 1; b <= 2 && a+b < len(token); b++ {
+				if year, ok := zxcvbnDayMonthYear(token[:a], token[a:a+b], token[a+b:]); ok {
+					return year, false, true
+				}
+			}
+		}
+		return 0, false, false
+	}
+	for _, p := range parts {
+		if !zxcvbnDigits(p) || len(p) > 4 {
+			return 0, false, false
+		}
+	}
+	year, ok := zxcvbnDayMonthYear(parts[0], parts[1], parts[2])
+	return year, true, ok
+}
+
+// zxcvbnDayMonthYear checks three numbers as a date in any common order
+func zxcvbnDayMonthYear(first, second, third string) (int, bool) {
+	a, _ := strconv.Atoi(first)
+	b, _ := strconv.Atoi(second)
+	c, _ := strconv.Atoi(third)
+	year := func(s string, v int) (int, bool) {
+		switch {
+		case len(s) == 4 && v >= 1900 && v <= 2099:
+			return v, true
+		case len(s) == 2 && v > 50:
+			return 1900 + v, true
+		case len(s) == 2:
+			return 2000 + v, true
+		}
+		return 0, false
+	}
+	dayMonth := func(d, m int) bool { return d >= 1 && d <= 31 && m >= 1 && m <= 12 }
+	if y, ok := year(third, c); ok && (dayMonth(a, b) || dayMonth(b, a)) {
+		return y, true
+	}
+	if y, ok := year(first, a); ok && (dayMonth(c, b) || dayMonth(b, c)) {
+		return y, true
+	}
+	return 0, false
+}
+
+// zxcvbnFeedback explains the longest pattern of a weak passphrase
+func zxcvbnFeedback(sequence []zxcvbnMatch, length int) (string, []string) {
+	suggestions := []string{"Add another word or two; uncommon words are better"}
+	var longest *zxcvbnMatch
+	for i := range sequence {
+		m := &sequence[i]
+		if m.pattern != zxcvbnBruteforce && (longest == nil || m.j-m.i > longest.j-longest.i) {
+			longest = m
+		}
+	}
+	if longest == nil {
+		return "", append(suggestions, "Use a longer passphrase")
+	}
+
+	warning := ""
+	switch longest.pattern {
+	case zxcvbnDictionary:
+		whole := len(sequence) == 1 && longest.j-longest.i == length
+		switch {
+		case longest.dictionary == "passwords" && whole && !longest.l33t && !longest.reversed && longest.rank <= 10:
+			warning = "This is a top-10 common password"
+		case longest.dictionary == "passwords" && whole && !longest.l33t && !longest.reversed && longest.rank <= 100:
+			warning = "This is a top-100 common password"
+		case longest.dictionary == "passwords" && whole:
+			warning = "This is similar to a commonly used password"
+		case longest.dictionary == "passwords":
+			warning = "It contains a common password"
+		case longest.dictionary == "user_inputs":
+			warning = "It contains a name of this vault, wallet or operator"
+		case whole:
+			warning = "A word by itself is easy to guess"
+		}
+		if longest.uppercase {
+			suggestions = append(suggestions, "Capitalization doesn't help very much")
+		}
+		if longest.reversed {
+			suggestions = append(suggestions, "Reversed words aren't much harder to guess")
+		}
+		if longest.l33t {
+			suggestions = append(suggestions, "Predictable substitutions like '@' instead of 'a' don't help very much")
+		}
+	case zxcvbnSpatial:
+		warning = "Straight rows of keys are easy to guess"
+		suggestions = append(suggestions, "Use a longer keyboard pattern with more turns")
+	case zxcvbnRepeat:
+		if len([]rune(longest.base)) == 1 {
+			warning = `Repeats like "aaa" are easy to guess`
+		} else {
+			warning = `Repeats like "abcabcabc" are only slightly harder to guess than "abc"`
+		}
+		suggestions = append(suggestions, "Avoid repeated words and characters")
+	case zxcvbnSequence:
+		warning = "Sequences like abc or 6543 are easy to guess"
+		suggestions = append(suggestions, "Avoid sequences")
+	case zxcvbnDate:
+		if longest.year {
+			warning = "Recent years are easy to guess"
+		} else {
+			warning = "Dates are often easy to guess"
+		}
+		suggestions = append(suggestions, "Avoid dates and years that are associated with you")
+	}
+	return warning, suggestions
+}
@@ -0,0 +1,184 @@
+// File: internal/signbatch/manifest.go
+package signbatch
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"vault.module/internal/airgap"
+	"vault.module/internal/errors"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Version is the version of manifests and results manifests
+const Version = 1
+
+// MaxItems bounds the items of a manifest
+const MaxItems = 10000
+
+// maxIDLength bounds an item ID
+const maxIDLength = 64
+
+// Item statuses in a results manifest
+const (
+	StatusSigned   = "signed"
+	StatusDeclined = "declined" // Not approved at review
+	StatusFailed   = "failed"   // Refused by a check or not signable
+)
+
+// Manifest lists the payloads to sign
+type Manifest struct {
+	Version     int    `json:"version"`
+	Description string `json:"description,omitempty"`
+	Items       []Item `json:"items"`
+}
+
+// Item is one payload to sign. The signing address is found by address or,
+// without one, by derivation path; key restricts the search to one wallet.
+type Item struct {
+	ID        string          `json:"id"`
+	Key       string          `json:"key,omitempty"`
+	Address   string          `json:"address,omitempty"`
+	Path      string          `json:"path,omitempty"`
+	Type      string          `json:"type"` // transaction, typed-transaction, personal-message or typed-data
+	ChainID   int64           `json:"chain_id,omitempty"`
+	Payload   string          `json:"payload,omitempty"`    // Hex of an unsigned transaction or a message
+	Message   string          `json:"message,omitempty"`    // Text of a personal message, instead of payload
+	TypedData json.RawMessage `json:"typed_data,omitempty"` // EIP-712 typed data
+	Note      string          `json:"note,omitempty"`
+}
+
+// Result is the outcome of an item
+type Result struct {
+	ID        string `json:"id"`
+	Address   string `json:"address,omitempty"`
+	ChainID   int64  `json:"chain_id,omitempty"`
+	Type      string `json:"type"`
+	SHA256    string `json:"sha256,omitempty"` // Of the signed payload
+	Status    string `json:"status"`
+	Signature string `json:"signature,omitempty"` // Hex r || s || v
+	Error     string `json:"error,omitempty"`
+}
+
+// Results is the results manifest of a batch
+type Results struct {
+	Version        int       `json:"version"`
+	Manifest       string    `json:"manifest"`
+	ManifestSHA256 string    `json:"manifest_sha256"`
+	Vault          string    `json:"vault"`
+	SignedAt       time.Time `json:"signed_at"`
+	Signed         int       `json:"signed"`
+	Declined       int       `json:"declined"`
+	Failed         int       `json:"failed"`
+	Items          []Result  `json:"items"`
+}
+
+// dataTypes maps item types to eth-sign-request data types
+var dataTypes = map[string]int{
+	"transaction":       airgap.EthDataTransaction,
+	"typed-transaction": airgap.EthDataTypedTransaction,
+	"personal-message":  airgap.EthDataPersonalMessage,
+	"typed-data":        airgap.EthDataTypedData,
+}
+
+// Parse reads and checks a manifest. Unknown fields are refused so that a
+// misspelled field does not silently change what is signed.
+func Parse(data []byte, name string) (*Manifest, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var m Manifest
+	if err := decoder.Decode(&m); err != nil {
+		return nil, errors.NewFormatInvalidError(name, fmt.Sprintf("not a signing manifest: %v", err))
+	}
+	if m.Version != Version {
+		return nil, errors.NewFormatInvalidError(name, fmt.Sprintf("unsupported manifest version %d", m.Version))
+	}
+	if len(m.Items) == 0 {
+		return nil, errors.NewInvalidInputError(name, "the manifest has no items")
+	}
+	if len(m.Items) > MaxItems {
+		return nil, errors.NewInvalidInputError(name, fmt.Sprintf("the manifest has %d items; at most %d are signed in one batch", len(m.Items), MaxItems))
+	}
+	seen := make(map[string]bool, len(m.Items))
+	for i, item := range m.Items {
+		if err := item.check(); err != nil {
+			return nil, errors.NewInvalidInputError(fmt.Sprintf("%s: item %d", name, i+1), err.Error())
+		}
+		if seen[item.ID] {
+			return nil, errors.NewInvalidInputError(fmt.Sprintf("%s: item %d", name, i+1), fmt.Sprintf("duplicate item ID %q", item.ID))
+		}
+		seen[item.ID] = true
+	}
+	return &m, nil
+}
+
+// check checks the fields of an item, without decoding its payload
+func (item Item) check() error {
+	if item.ID == "" || len(item.ID) > maxIDLength || strings.ContainsFunc(item.ID, unicode.IsControl) {
+		return fmt.Errorf("id must be 1 to %d printable characters", maxIDLength)
+	}
+	if item.Address == "" && item.Path == "" {
+		return fmt.Errorf("the item names no address or path to sign with")
+	}
+	if item.Address != "" && !common.IsHexAddress(item.Address) {
+		return fmt.Errorf("%q is not an EVM address", item.Address)
+	}
+	if _, ok := dataTypes[item.Type]; !ok {
+		return fmt.Errorf("type must be transaction, typed-transaction, personal-message or typed-data")
+	}
+	if item.Type != "personal-message" && item.ChainID < 1 {
+		return fmt.Errorf("chain_id is required")
+	}
+	if strings.ContainsFunc(item.Note, unicode.IsControl) {
+		return fmt.Errorf("note must be a single line")
+	}
+	return nil
+}
+
+// Request returns an item as the eth-sign-request it stands for
+func (item Item) Request() (*airgap.EthSignRequest, error) {
+	req := &airgap.EthSignRequest{
+		DataType: dataTypes[item.Type],
+		ChainID:  item.ChainID,
+		Path:     item.Path,
+	}
+	if req.ChainID == 0 {
+		req.ChainID = 1
+	}
+	if item.Address != "" {
+		req.Address = common.HexToAddress(item.Address).Bytes()
+	}
+
+	switch {
+	case item.Type == "typed-data":
+		if len(item.TypedData) == 0 || item.Payload != "" || item.Message != "" {
+			return nil, fmt.Errorf("typed data is given as typed_data only")
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, item.TypedData); err != nil {
+			return nil, fmt.Errorf("typed_data is not JSON: %w", err)
+		}
+		req.SignData = compact.Bytes()
+	case item.Message != "":
+		if item.Type != "personal-message" || item.Payload != "" {
+			return nil, fmt.Errorf("message is only for personal messages, instead of payload")
+		}
+		req.SignData = []byte(item.Message)
+	default:
+		if item.Payload == "" || len(item.TypedData) != 0 {
+			return nil, fmt.Errorf("payload is required")
+		}
+		payload, err := hex.DecodeString(strings.TrimPrefix(item.Payload, "0x"))
+		if err != nil || len(payload) == 0 {
+			return nil, fmt.Errorf("payload is not hex")
+		}
+		req.SignData = payload
+	}
+	return req, nil
+}
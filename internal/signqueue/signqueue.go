@@ -0,0 +1,128 @@
+// File: internal/signqueue/signqueue.go
+package signqueue
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"vault.module/internal/errors"
+	"vault.module/internal/statefile"
+)
+
+// StateFile holds the queued signing requests. It lives next to config.json and audit.log.
+// Requests and signatures are public data; no secret is ever written to the queue.
+const StateFile = "signing-queue.json"
+
+var stateFile = statefile.File{Path: StateFile, Name: "signing queue"}
+
+// Request states
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+// Entry is a signing request submitted by a programmatic client
+type Entry struct {
+	ID        string    `json:"id"`
+	Client    string    `json:"client"`
+	Vault     string    `json:"vault"`
+	Key       string    `json:"key,omitempty"` // --key the client restricted the request to
+	Request   string    `json:"request"`       // hex of the eth-sign-request CBOR
+	CreatedAt time.Time `json:"created_at"`
+	Status    string    `json:"status"`
+	DecidedAt time.Time `json:"decided_at,omitempty"`
+	DecidedBy string    `json:"decided_by,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Response  string    `json:"response,omitempty"` // hex of the eth-signature CBOR
+}
+
+type state struct {
+	Entries []Entry `json:"entries"`
+}
+
+// Enqueue adds a pending request and returns its entry
+func Enqueue(client, vault, key string, request []byte) (*Entry, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, errors.Wrap(errors.ErrCodeSystem, "failed to generate request ID", err)
+	}
+	e := Entry{
+		ID:        hex.EncodeToString(id),
+		Client:    client,
+		Vault:     vault,
+		Key:       key,
+		Request:   hex.EncodeToString(request),
+		CreatedAt: time.Now().UTC(),
+		Status:    StatusPending,
+	}
+	st := &state{}
+	err := stateFile.Update(st, func() error {
+		st.Entries = append(st.Entries, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Get returns the entry with the given ID
+func Get(id string) (*Entry, error) {
+	st := &state{}
+	if err := stateFile.Load(st); err != nil {
+		return nil, err
+	}
+	for i := range st.Entries {
+		if st.Entries[i].ID == id {
+			return &st.Entries[i], nil
+		}
+	}
+	return nil, errors.NewInvalidInputError(id, "no signing request with this ID")
+}
+
+// List returns the entries, oldest first
+func List() ([]Entry, error) {
+	st := &state{}
+	if err := stateFile.Load(st); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(st.Entries, func(i, j int) bool { return st.Entries[i].CreatedAt.Before(st.Entries[j].CreatedAt) })
+	return st.Entries, nil
+}
+
+// Decide records the outcome of a pending request. response is the eth-signature
+// of an approved request. The request is checked and updated under the queue's
+// lock: of two operators deciding the same request, the second is refused.
+func Decide(id, status, decidedBy, reason string, response []byte) (*Entry, error) {
+	st := &state{}
+	var decided *Entry
+	err := stateFile.Update(st, func() error {
+		for i := range st.Entries {
+			e := &st.Entries[i]
+			if e.ID != id {
+				continue
+			}
+			if e.Status != StatusPending {
+				return errors.NewInvalidInputError(id, fmt.Sprintf("signing request is already %s", e.Status))
+			}
+			e.Status = status
+			e.DecidedAt = time.Now().UTC()
+			e.DecidedBy = decidedBy
+			e.Reason = reason
+			if response != nil {
+				e.Response = hex.EncodeToString(response)
+			}
+			decided = e
+			return nil
+		}
+		return errors.NewInvalidInputError(id, "no signing request with this ID")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decided, nil
+}
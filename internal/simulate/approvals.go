@@ -0,0 +1,204 @@
+// File: internal/simulate/approvals.go
+package simulate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"vault.module/internal/keys"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Selectors of calls that grant an allowance, on top of approve and setApprovalForAll
+var (
+	selectorIncreaseAllowance = crypto.Keccak256([]byte("increaseAllowance(address,uint256)"))[:4]
+	selectorPermit            = crypto.Keccak256([]byte("permit(address,address,uint256,uint256,uint8,bytes32,bytes32)"))[:4]
+)
+
+// Grant is an allowance a signature hands to a spender: the spender can then
+// move the signer's tokens without asking again
+type Grant struct {
+	Method    string          // approve, increaseAllowance, setApprovalForAll, permit or the EIP-712 primary type
+	Token     *common.Address // nil when the signature does not name it
+	Spender   common.Address
+	Amount    *big.Int // nil with All
+	All       bool     // Every token of the collection, or an unlimited DAI-style permit
+	Signature bool     // An off-chain EIP-712 permit, which anyone holding it can submit
+	Deadline  *big.Int // Expiry of a permit, in Unix seconds; nil when it has none
+}
+
+// Unlimited reports whether the grant is effectively unlimited
+func (g Grant) Unlimited() bool {
+	return g.All || (g.Amount != nil && g.Amount.BitLen() >= 128)
+}
+
+// Grants returns the allowances a payload grants: approve, increaseAllowance,
+// setApprovalForAll and permit calls in a transaction's calldata, and
+// EIP-2612, DAI and Permit2 permits in EIP-712 typed data. Revocations grant
+// nothing and are not returned.
+func Grants(payload []byte, kind keys.EVMPayloadKind) []Grant {
+	switch kind {
+	case keys.EVMLegacyTransaction, keys.EVMTypedTransaction:
+		tx, err := keys.DecodeEVMTransaction(payload, kind)
+		if err != nil || tx.To == nil {
+			return nil
+		}
+		if grant, ok := calldataGrant(*tx.To, tx.Data); ok {
+			return []Grant{grant}
+		}
+	case keys.EVMTypedData:
+		return permitGrants(payload)
+	}
+	return nil
+}
+
+// calldataGrant decodes a call to token that grants an allowance
+func calldataGrant(token common.Address, data []byte) (Grant, bool) {
+	if len(data) < 4 {
+		return Grant{}, false
+	}
+	selector, args := data[:4], data[4:]
+	word := func(i int) []byte {
+		if len(args) < 32*(i+1) {
+			return nil
+		}
+		return args[32*i : 32*(i+1)]
+	}
+	grant := Grant{Token: &token}
+	switch {
+	case bytes.Equal(selector, selectorApprove) && word(1) != nil:
+		grant.Method = "approve"
+		grant.Spender, grant.Amount = common.BytesToAddress(word(0)), new(big.Int).SetBytes(word(1))
+	case bytes.Equal(selector, selectorIncreaseAllowance) && word(1) != nil:
+		grant.Method = "increaseAllowance"
+		grant.Spender, grant.Amount = common.BytesToAddress(word(0)), new(big.Int).SetBytes(word(1))
+	case bytes.Equal(selector, selectorSetApprovalForAll) && word(1) != nil:
+		grant.Method = "setApprovalForAll"
+		grant.Spender, grant.All = common.BytesToAddress(word(0)), new(big.Int).SetBytes(word(1)).Sign() != 0
+		return grant, grant.All
+	case bytes.Equal(selector, selectorPermit) && word(3) != nil:
+		// Submits a permit signed by owner, which may be the signer
+		grant.Method = "permit"
+		grant.Spender, grant.Amount, grant.Deadline = common.BytesToAddress(word(1)), new(big.Int).SetBytes(word(2)), new(big.Int).SetBytes(word(3))
+	default:
+		return Grant{}, false
+	}
+	return grant, grant.Amount.Sign() > 0
+}
+
+// permitGrants decodes the permits of EIP-712 typed data
+func permitGrants(payload []byte) []Grant {
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal(payload, &typedData); err != nil {
+		return nil
+	}
+	message := typedData.Message
+	var token *common.Address
+	if contract := typedData.Domain.VerifyingContract; common.IsHexAddress(contract) {
+		address := common.HexToAddress(contract)
+		token = &address
+	}
+	spender, hasSpender := messageAddress(message, "spender")
+
+	var grants []Grant
+	switch typedData.PrimaryType {
+	case "Permit":
+		if !hasSpender {
+			return nil
+		}
+		grant := Grant{Method: "Permit", Token: token, Spender: spender, Signature: true}
+		if allowed, ok := message["allowed"]; ok {
+			// DAI: allowed grants an unlimited allowance
+			if fmt.Sprint(allowed) != "true" {
+				return nil
+			}
+			grant.All = true
+			grant.Deadline = messageNumber(message, "expiry")
+		} else {
+			grant.Amount = messageNumber(message, "value")
+			grant.Deadline = messageNumber(message, "deadline")
+			if grant.Amount == nil || grant.Amount.Sign() == 0 {
+				return nil
+			}
+		}
+		grants = append(grants, grant)
+	case "PermitSingle", "PermitBatch": // Permit2 allowance transfer
+		if !hasSpender {
+			return nil
+		}
+		details := []interface{}{message["details"]}
+		if list, ok := message["details"].([]interface{}); ok {
+			details = list
+		}
+		for _, d := range details {
+			detail, ok := d.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			grant := Grant{Method: typedData.PrimaryType, Spender: spender, Signature: true, Amount: messageNumber(detail, "amount"), Deadline: messageNumber(detail, "expiration")}
+			if address, ok := messageAddress(detail, "token"); ok {
+				grant.Token = &address
+			}
+			if grant.Amount != nil && grant.Amount.Sign() > 0 {
+				grants = append(grants, grant)
+			}
+		}
+	case "PermitTransferFrom", "PermitBatchTransferFrom", "PermitWitnessTransferFrom", "PermitBatchWitnessTransferFrom": // Permit2 signature transfer
+		if !hasSpender {
+			return nil
+		}
+		permitted := []interface{}{message["permitted"]}
+		if list, ok := message["permitted"].([]interface{}); ok {
+			permitted = list
+		}
+		for _, p := range permitted {
+			entry, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			grant := Grant{Method: typedData.PrimaryType, Spender: spender, Signature: true, Amount: messageNumber(entry, "amount"), Deadline: messageNumber(message, "deadline")}
+			if address, ok := messageAddress(entry, "token"); ok {
+				grant.Token = &address
+			}
+			if grant.Amount != nil && grant.Amount.Sign() > 0 {
+				grants = append(grants, grant)
+			}
+		}
+	}
+	return grants
+}
+
+// messageAddress returns an address field of an EIP-712 message
+func messageAddress(message map[string]interface{}, field string) (common.Address, bool) {
+	value, ok := message[field].(string)
+	if !ok || !common.IsHexAddress(value) {
+		return common.Address{}, false
+	}
+	return common.HexToAddress(value), true
+}
+
+// messageNumber returns a number field of an EIP-712 message, given as a
+// decimal or hex string or a JSON number, or nil
+func messageNumber(message map[string]interface{}, field string) *big.Int {
+	switch value := message[field].(type) {
+	case string:
+		if n, ok := new(big.Int).SetString(strings.TrimSpace(value), 0); ok {
+			return n
+		}
+	case float64:
+		if n, accuracy := big.NewFloat(value).Int(nil); accuracy == big.Exact {
+			return n
+		}
+	case json.Number:
+		if n, ok := new(big.Int).SetString(value.String(), 10); ok {
+			return n
+		}
+	}
+	return nil
+}
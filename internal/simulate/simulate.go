@@ -0,0 +1,519 @@
+// File: internal/simulate/simulate.go
+package simulate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"vault.module/internal/budget"
+	"vault.module/internal/keys"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// requestTimeout bounds each call to the RPC endpoint
+const requestTimeout = 15 * time.Second
+
+// maxSymbolLength bounds a token symbol read from its contract
+const maxSymbolLength = 16
+
+// How a preview was obtained
+const (
+	ModeTrace   = "trace"   // debug_traceCall: every call and event of the execution
+	ModeCall    = "call"    // eth_call: whether it reverts; token movements come from the calldata
+	ModeOffline = "offline" // No RPC: the value and the calldata of the transaction only
+)
+
+// Event signatures of ERC-20 and ERC-721 tokens
+var (
+	transferTopic       = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	approvalTopic       = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+	approvalForAllTopic = crypto.Keccak256Hash([]byte("ApprovalForAll(address,address,bool)"))
+)
+
+// Function selectors decoded from calldata
+var (
+	selectorTransfer          = crypto.Keccak256([]byte("transfer(address,uint256)"))[:4]
+	selectorTransferFrom      = crypto.Keccak256([]byte("transferFrom(address,address,uint256)"))[:4]
+	selectorApprove           = crypto.Keccak256([]byte("approve(address,uint256)"))[:4]
+	selectorSetApprovalForAll = crypto.Keccak256([]byte("setApprovalForAll(address,bool)"))[:4]
+	selectorSymbol            = crypto.Keccak256([]byte("symbol()"))[:4]
+	selectorDecimals          = crypto.Keccak256([]byte("decimals()"))[:4]
+)
+
+// Asset is a coin or token whose balance changes. A nil Token is the chain's
+// native coin.
+type Asset struct {
+	Token    *common.Address
+	Symbol   string // "" when unknown
+	Decimals int    // -1 when unknown
+	NFT      bool   // ERC-721: amounts count tokens
+}
+
+// BalanceChange is the change of the sender's balance of an asset
+type BalanceChange struct {
+	Asset  Asset
+	Amount *big.Int // Negative when the sender loses it
+}
+
+// Approval lets a spender move the sender's tokens
+type Approval struct {
+	Asset   Asset
+	Spender common.Address
+	Amount  *big.Int // Allowance; the token ID for a single NFT
+	All     bool     // setApprovalForAll: every token of the collection
+	Revoked bool     // An allowance of zero, or ApprovalForAll false
+}
+
+// Unlimited reports whether the allowance is so large that it is effectively unlimited
+func (a Approval) Unlimited() bool {
+	return !a.All && !a.Asset.NFT && a.Amount != nil && a.Amount.BitLen() >= 128
+}
+
+// Preview is what a transaction would do to its sender's balances and allowances
+type Preview struct {
+	Mode         string
+	Reverted     bool
+	RevertReason string
+	GasUsed      uint64 // 0 when unknown
+	Changes      []BalanceChange
+	Approvals    []Approval
+	Notes        []string // What the preview could not tell
+}
+
+// Offline previews a transaction from its value and calldata alone. It
+// recognizes token transfers and approvals called directly on a token.
+func Offline(from common.Address, tx *keys.EVMTransaction) *Preview {
+	p := &Preview{Mode: ModeOffline}
+	p.decodeCalldata(from, tx)
+	p.resolveTokens("")
+	return p
+}
+
+// Run previews a transaction by executing it against the latest state of the
+// chain behind rpcURL. It traces the execution when the node supports
+// debug_traceCall and falls back to eth_call otherwise.
+func Run(rpcURL string, from common.Address, tx *keys.EVMTransaction) (*Preview, error) {
+	call := map[string]interface{}{
+		"from":  from,
+		"value": (*hexutil.Big)(tx.Value),
+		"data":  hexutil.Bytes(tx.Data),
+	}
+	if tx.To != nil {
+		call["to"] = tx.To
+	}
+	if tx.Gas > 0 {
+		call["gas"] = hexutil.Uint64(tx.Gas)
+	}
+
+	p := &Preview{Mode: ModeTrace}
+	var frame callFrame
+	tracer := map[string]interface{}{"tracer": "callTracer", "tracerConfig": map[string]bool{"withLog": true}}
+	err := rpcCall(rpcURL, "debug_traceCall", []interface{}{call, "latest", tracer}, &frame)
+	switch {
+	case err == nil:
+		p.applyTrace(from, &frame)
+	case isRPCError(err):
+		// The node does not trace: run the call for its outcome only
+		p.Mode = ModeCall
+		var output hexutil.Bytes
+		if err := rpcCall(rpcURL, "eth_call", []interface{}{call, "latest"}, &output); err != nil {
+			if !isRPCError(err) {
+				return nil, err
+			}
+			p.Reverted = true
+			p.RevertReason = revertReason(err.(*rpcError))
+		}
+		p.decodeCalldata(from, tx)
+		p.Notes = append(p.Notes, "the node does not support debug_traceCall; token movements inside other contracts are not shown")
+	default:
+		return nil, err
+	}
+	p.resolveTokens(rpcURL)
+	return p, nil
+}
+
+// callFrame is a call of the callTracer output
+type callFrame struct {
+	Type         string          `json:"type"`
+	From         common.Address  `json:"from"`
+	To           *common.Address `json:"to"`
+	Value        *hexutil.Big    `json:"value"`
+	GasUsed      hexutil.Uint64  `json:"gasUsed"`
+	Error        string          `json:"error"`
+	RevertReason string          `json:"revertReason"`
+	Calls        []callFrame     `json:"calls"`
+	Logs         []struct {
+		Address common.Address `json:"address"`
+		Topics  []common.Hash  `json:"topics"`
+		Data    hexutil.Bytes  `json:"data"`
+	} `json:"logs"`
+}
+
+// applyTrace collects the balance changes and approvals of the sender from a
+// trace. The calls and events of reverted frames did not happen.
+func (p *Preview) applyTrace(from common.Address, top *callFrame) {
+	p.GasUsed = uint64(top.GasUsed)
+	if top.Error != "" {
+		p.Reverted = true
+		p.RevertReason = top.RevertReason
+		if p.RevertReason == "" {
+			p.RevertReason = top.Error
+		}
+		return
+	}
+
+	var walk func(f *callFrame)
+	walk = func(f *callFrame) {
+		if f.Error != "" {
+			return
+		}
+		if f.Value != nil && f.Type != "DELEGATECALL" && f.Type != "STATICCALL" {
+			value := f.Value.ToInt()
+			if f.From == from {
+				p.addChange(Asset{Decimals: 18}, new(big.Int).Neg(value))
+			}
+			if f.To != nil && *f.To == from {
+				p.addChange(Asset{Decimals: 18}, value)
+			}
+		}
+		for _, log := range f.Logs {
+			if len(log.Topics) < 3 {
+				continue
+			}
+			token := log.Address
+			nft := len(log.Topics) == 4
+			asset := Asset{Token: &token, Decimals: -1, NFT: nft}
+			amount := new(big.Int).SetBytes(log.Data)
+			if nft {
+				amount = log.Topics[3].Big()
+			}
+			source := common.BytesToAddress(log.Topics[1].Bytes())
+			target := common.BytesToAddress(log.Topics[2].Bytes())
+			switch log.Topics[0] {
+			case transferTopic:
+				if nft {
+					amount = big.NewInt(1)
+				}
+				if source == from {
+					p.addChange(asset, new(big.Int).Neg(amount))
+				}
+				if target == from {
+					p.addChange(asset, amount)
+				}
+			case approvalTopic:
+				if source == from && target != (common.Address{}) {
+					p.addApproval(Approval{Asset: asset, Spender: target, Amount: amount, Revoked: !nft && amount.Sign() == 0})
+				}
+			case approvalForAllTopic:
+				if source == from {
+					asset.NFT = true
+					p.addApproval(Approval{Asset: asset, Spender: target, All: true, Revoked: new(big.Int).SetBytes(log.Data).Sign() == 0})
+				}
+			}
+		}
+		for i := range f.Calls {
+			walk(&f.Calls[i])
+		}
+	}
+	walk(top)
+}
+
+// decodeCalldata derives the sender's balance changes and approvals from the
+// value and calldata of the transaction
+func (p *Preview) decodeCalldata(from common.Address, tx *keys.EVMTransaction) {
+	if tx.Value.Sign() > 0 && (tx.To == nil || *tx.To != from) {
+		p.addChange(Asset{Decimals: 18}, new(big.Int).Neg(tx.Value))
+	}
+	if tx.To == nil {
+		p.Notes = append(p.Notes, "the transaction creates a contract")
+		return
+	}
+	if len(tx.Data) == 0 {
+		return
+	}
+	if len(tx.Data) < 4 {
+		p.Notes = append(p.Notes, "the calldata is too short to be a function call")
+		return
+	}
+
+	token := *tx.To
+	asset := Asset{Token: &token, Decimals: -1}
+	selector, args := tx.Data[:4], tx.Data[4:]
+	word := func(i int) []byte {
+		if len(args) < 32*(i+1) {
+			return nil
+		}
+		return args[32*i : 32*(i+1)]
+	}
+	switch {
+	case bytes.Equal(selector, selectorTransfer) && word(1) != nil:
+		if common.BytesToAddress(word(0)) != from {
+			p.addChange(asset, new(big.Int).Neg(new(big.Int).SetBytes(word(1))))
+		}
+	case bytes.Equal(selector, selectorTransferFrom) && word(2) != nil:
+		// The same selector moves an ERC-721 token, whose ID is the last argument
+		source, target := common.BytesToAddress(word(0)), common.BytesToAddress(word(1))
+		amount := new(big.Int).SetBytes(word(2))
+		if source == from && target != from {
+			p.addChange(asset, new(big.Int).Neg(amount))
+		}
+		if target == from && source != from {
+			p.addChange(asset, amount)
+		}
+		p.Notes = append(p.Notes, "transferFrom moves an ERC-20 amount or an ERC-721 token ID; the calldata does not tell which")
+	case bytes.Equal(selector, selectorApprove) && word(1) != nil:
+		amount := new(big.Int).SetBytes(word(1))
+		p.addApproval(Approval{Asset: asset, Spender: common.BytesToAddress(word(0)), Amount: amount, Revoked: amount.Sign() == 0})
+	case bytes.Equal(selector, selectorSetApprovalForAll) && word(1) != nil:
+		asset.NFT = true
+		p.addApproval(Approval{Asset: asset, Spender: common.BytesToAddress(word(0)), All: true, Revoked: new(big.Int).SetBytes(word(1)).Sign() == 0})
+	default:
+		p.Notes = append(p.Notes, fmt.Sprintf("the transaction calls function 0x%x of the contract; what it moves is unknown without a trace", selector))
+	}
+}
+
+// addChange adds amount to the sender's change of asset
+func (p *Preview) addChange(asset Asset, amount *big.Int) {
+	for i := range p.Changes {
+		c := &p.Changes[i]
+		if sameToken(c.Asset.Token, asset.Token) && c.Asset.NFT == asset.NFT {
+			c.Amount = new(big.Int).Add(c.Amount, amount)
+			return
+		}
+	}
+	p.Changes = append(p.Changes, BalanceChange{Asset: asset, Amount: new(big.Int).Set(amount)})
+}
+
+// addApproval records an approval. A later approval of the same spender for
+// the same token replaces an earlier one: a token spent through its allowance
+// emits the allowance left, which is the one that remains.
+func (p *Preview) addApproval(approval Approval) {
+	for i := range p.Approvals {
+		a := &p.Approvals[i]
+		if sameToken(a.Asset.Token, approval.Asset.Token) && a.Spender == approval.Spender && a.All == approval.All && a.Asset.NFT == approval.Asset.NFT {
+			*a = approval
+			return
+		}
+	}
+	p.Approvals = append(p.Approvals, approval)
+}
+
+// resolveTokens drops the changes that cancel out, sorts losses first and
+// names the tokens with their symbol and decimals where the node tells them
+func (p *Preview) resolveTokens(rpcURL string) {
+	changes := p.Changes[:0]
+	for _, c := range p.Changes {
+		if c.Amount.Sign() != 0 {
+			changes = append(changes, c)
+		}
+	}
+	p.Changes = changes
+	sort.SliceStable(p.Changes, func(i, j int) bool { return p.Changes[i].Amount.Sign() < p.Changes[j].Amount.Sign() })
+
+	if rpcURL == "" {
+		return
+	}
+	known := map[common.Address]Asset{}
+	resolve := func(asset *Asset) {
+		if asset.Token == nil {
+			return
+		}
+		if cached, ok := known[*asset.Token]; ok {
+			asset.Symbol, asset.Decimals = cached.Symbol, cached.Decimals
+			return
+		}
+		asset.Symbol = tokenSymbol(rpcURL, *asset.Token)
+		if !asset.NFT {
+			asset.Decimals = tokenDecimals(rpcURL, *asset.Token)
+		}
+		known[*asset.Token] = *asset
+	}
+	for i := range p.Changes {
+		resolve(&p.Changes[i].Asset)
+	}
+	for i := range p.Approvals {
+		resolve(&p.Approvals[i].Asset)
+	}
+}
+
+// tokenSymbol returns the symbol() of a token, or "" when it has none
+func tokenSymbol(rpcURL string, token common.Address) string {
+	var output hexutil.Bytes
+	call := map[string]interface{}{"to": token, "data": hexutil.Bytes(selectorSymbol)}
+	if err := rpcCall(rpcURL, "eth_call", []interface{}{call, "latest"}, &output); err != nil {
+		return ""
+	}
+	var symbol string
+	switch {
+	case len(output) >= 96: // ABI string: offset, length, bytes
+		offset := new(big.Int).SetBytes(output[:32])
+		if !offset.IsUint64() || offset.Uint64()+32 > uint64(len(output)) {
+			return ""
+		}
+		length := new(big.Int).SetBytes(output[offset.Uint64() : offset.Uint64()+32])
+		start := offset.Uint64() + 32
+		if !length.IsUint64() || length.Uint64() > uint64(len(output))-start {
+			return ""
+		}
+		symbol = string(output[start : start+length.Uint64()])
+	case len(output) == 32: // bytes32, as older tokens return
+		symbol = string(bytes.TrimRight(output, "\x00"))
+	}
+	// The symbol comes from the contract and is printed to the terminal
+	symbol = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == unicode.ReplacementChar {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(symbol))
+	if runes := []rune(symbol); len(runes) > maxSymbolLength {
+		symbol = string(runes[:maxSymbolLength])
+	}
+	return symbol
+}
+
+// tokenDecimals returns the decimals() of a token, or -1 when it has none
+func tokenDecimals(rpcURL string, token common.Address) int {
+	var output hexutil.Bytes
+	call := map[string]interface{}{"to": token, "data": hexutil.Bytes(selectorDecimals)}
+	if err := rpcCall(rpcURL, "eth_call", []interface{}{call, "latest"}, &output); err != nil || len(output) != 32 {
+		return -1
+	}
+	decimals := new(big.Int).SetBytes(output)
+	if !decimals.IsInt64() || decimals.Int64() > 36 {
+		return -1
+	}
+	return int(decimals.Int64())
+}
+
+// FormatAmount formats an amount of base units with its decimals, or as base
+// units when they are unknown
+func FormatAmount(amount *big.Int, decimals int) string {
+	sign := ""
+	if amount.Sign() < 0 {
+		sign = "-"
+	}
+	digits := new(big.Int).Abs(amount).String()
+	if decimals <= 0 {
+		return sign + digits
+	}
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if fraction == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction
+}
+
+// NativeSymbol returns the symbol of a chain's native coin
+func NativeSymbol(chainID int64) string {
+	switch chainID {
+	case 56, 97:
+		return "BNB"
+	case 137, 80002:
+		return "POL"
+	case 43114, 43113:
+		return "AVAX"
+	case 100:
+		return "xDAI"
+	case 250:
+		return "FTM"
+	default:
+		return "ETH"
+	}
+}
+
+func sameToken(a, b *common.Address) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// rpcError is an error returned by the node, as opposed to a failure to reach it
+type rpcError struct {
+	Method  string
+	Code    int
+	Message string
+	Data    string
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("%s failed: %s", e.Method, e.Message)
+}
+
+func isRPCError(err error) bool {
+	_, ok := err.(*rpcError)
+	return ok
+}
+
+// revertReason returns the reason of a reverted eth_call: the decoded
+// Error(string) of its data, or the node's message
+func revertReason(err *rpcError) string {
+	if data, decodeErr := hexutil.Decode(err.Data); decodeErr == nil && len(data) >= 68 && bytes.Equal(data[:4], crypto.Keccak256([]byte("Error(string)"))[:4]) {
+		length := new(big.Int).SetBytes(data[36:68])
+		if length.IsUint64() && length.Uint64() <= uint64(len(data)-68) {
+			return string(data[68 : 68+length.Uint64()])
+		}
+	}
+	return err.Message
+}
+
+// rpcCall performs a single JSON-RPC call and decodes its result
+func rpcCall(rpcURL, method string, params []interface{}, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := budget.WithTimeout(requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rpcURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var rpcResp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int             `json:"code"`
+			Message string          `json:"message"`
+			Data    json.RawMessage `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 8<<20)).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("invalid JSON-RPC response: %w", err)
+	}
+	if rpcResp.Error != nil {
+		var data string
+		json.Unmarshal(rpcResp.Error.Data, &data)
+		return &rpcError{Method: method, Code: rpcResp.Error.Code, Message: rpcResp.Error.Message, Data: data}
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
@@ -0,0 +1,121 @@
+// File: internal/statefile/statefile.go
+
+// Package statefile keeps the small JSON state files that live next to
+// config.json and audit.log: rate limits, the signing queue, signed nonces, the
+// address book and usage statistics. Updates hold an exclusive lock on
+// <file>.lock from the read to the write, so concurrent commands never act on
+// the same state or lose each other's writes. Files are replaced atomically
+// with owner-only permissions, so readers need no lock.
+package statefile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"vault.module/internal/errors"
+	"vault.module/internal/filelock"
+)
+
+// lockTimeout bounds how long an update waits for another process's update
+const lockTimeout = 5 * time.Second
+
+// File is a JSON state file
+type File struct {
+	Path string // Relative to the working directory, like config.json
+	Name string // What the file holds, for error messages, e.g. "signing queue"
+}
+
+// Load reads the state into v. A missing file leaves v as it is.
+func (f File) Load(v interface{}) error {
+	data, err := os.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.NewFileSystemError("read", f.Path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		// A corrupt file must not silently reset the state
+		return errors.NewFormatInvalidError(f.Path, fmt.Sprintf("corrupt %s: %v", f.Name, err))
+	}
+	return nil
+}
+
+// Update loads the state into v, calls fn and saves v if fn returns nil, all
+// under the file's lock. fn decides on the state it was given: no other update
+// can run between its check and the save.
+func (f File) Update(v interface{}, fn func() error) error {
+	lockFile, err := f.lock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		filelock.Unlock(lockFile)
+		lockFile.Close()
+	}()
+
+	if err := f.Load(v); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	return f.save(v)
+}
+
+// lock takes an exclusive lock on the file's .lock, waiting up to lockTimeout.
+// The lock file is kept: removing it would let two processes lock different inodes.
+func (f File) lock() (*os.File, error) {
+	lockPath := f.Path + ".lock"
+	lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, errors.NewFileSystemError("open", lockPath, err)
+	}
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err := filelock.TryLock(lockFile)
+		if err == nil {
+			return lockFile, nil
+		}
+		if err != filelock.ErrLocked || time.Now().After(deadline) {
+			lockFile.Close()
+			return nil, errors.NewFileSystemError("lock", lockPath, err).WithDetails(fmt.Sprintf("the %s is locked by another process", f.Name))
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// save writes v atomically with owner-only permissions
+func (f File) save(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.New(errors.ErrCodeInternal, fmt.Sprintf("failed to serialize %s", f.Name)).WithContext("marshal_error", err.Error())
+	}
+
+	base := strings.TrimSuffix(filepath.Base(f.Path), filepath.Ext(f.Path))
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), base+"-*.tmp")
+	if err != nil {
+		return errors.NewFileSystemError("create", f.Path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return errors.NewFileSystemError("chmod", tmp.Name(), err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.NewFileSystemError("write", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.NewFileSystemError("close", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), f.Path); err != nil {
+		return errors.NewFileSystemError("rename", tmp.Name(), err)
+	}
+	return nil
+}
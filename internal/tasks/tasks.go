@@ -0,0 +1,205 @@
+// File: internal/tasks/tasks.go
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// renderInterval is how often the progress line is redrawn
+const renderInterval = 100 * time.Millisecond
+
+// Progress is how far a task has got
+type Progress struct {
+	Done  int
+	Total int    // 0 if not known in advance
+	Item  string // What the task is working on, e.g. a derivation path
+}
+
+// Task is a long operation. Run reports its progress and must return soon
+// after ctx is cancelled, with ctx.Err().
+type Task struct {
+	Name  string
+	Stage string // Name of the task in progress events, e.g. "derive"; Name if empty
+	Run   func(ctx context.Context, report func(Progress)) error
+}
+
+// Event is one line of the progress stream: newline-delimited JSON that
+// wrapping tools read instead of the progress line drawn for people
+type Event struct {
+	Event     string `json:"event"` // "start", "progress" or "end"
+	Stage     string `json:"stage"`
+	Done      int    `json:"done,omitempty"`
+	Total     int    `json:"total,omitempty"`
+	Percent   *int   `json:"percent,omitempty"` // Only when the total is known
+	Item      string `json:"item,omitempty"`
+	Status    string `json:"status,omitempty"` // end: "done", "cancelled" or "failed"
+	ElapsedMs int64  `json:"elapsed_ms"`
+}
+
+// Status of a finished stage
+const (
+	StatusDone      = "done"
+	StatusCancelled = "cancelled"
+	StatusFailed    = "failed"
+)
+
+var (
+	eventsMu  sync.Mutex
+	eventsOut io.Writer
+)
+
+// EnableEvents writes the progress of tasks and stages to out as events, in
+// place of the progress line. Programmatic and JSON modes enable it on stderr.
+func EnableEvents(out io.Writer) {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	eventsOut = out
+}
+
+// EventsEnabled reports whether progress is written as events
+func EventsEnabled() bool {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	return eventsOut != nil
+}
+
+// emit writes one event, if events are enabled
+func emit(ev Event) {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	if eventsOut == nil {
+		return
+	}
+	if ev.Total > 0 {
+		percent := ev.Done * 100 / ev.Total
+		ev.Percent = &percent
+	}
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	eventsOut.Write(append(line, '\n'))
+}
+
+// Stage reports a long step that has no progress of its own, such as
+// decrypting the vault: it emits the stage's start event and returns the
+// function that emits its end with the step's error
+func Stage(stage string) func(err error) {
+	start := time.Now()
+	emit(Event{Event: "start", Stage: stage})
+	return func(err error) {
+		emit(Event{Event: "end", Stage: stage, Status: status(err), ElapsedMs: time.Since(start).Milliseconds()})
+	}
+}
+
+// status returns the end status of a stage that returned err
+func status(err error) string {
+	switch {
+	case err == nil:
+		return StatusDone
+	case errors.Is(err, context.Canceled):
+		return StatusCancelled
+	default:
+		return StatusFailed
+	}
+}
+
+// Result is how a task ended
+type Result struct {
+	Name      string
+	Err       error
+	Cancelled bool // The task stopped because ctx was cancelled
+	Elapsed   time.Duration
+	Progress  Progress // The last progress reported
+}
+
+// Run runs task until it returns, drawing its progress on one line of out
+// while out is a terminal, or writing it as events when they are enabled. Cancelling ctx (Ctrl+C cancels the shutdown
+// manager's context) asks the task to stop; Run still waits for it, so the
+// caller never races a task that holds secrets.
+func Run(ctx context.Context, task Task, out *os.File) Result {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var last Progress
+	report := func(p Progress) {
+		mu.Lock()
+		last = p
+		mu.Unlock()
+	}
+
+	stage := task.Stage
+	if stage == "" {
+		stage = task.Name
+	}
+	events := EventsEnabled()
+
+	start := time.Now()
+	if events {
+		emit(Event{Event: "start", Stage: stage})
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- task.Run(ctx, report)
+	}()
+
+	render := !events && out != nil && term.IsTerminal(int(out.Fd()))
+	var sent Progress
+	ticker := time.NewTicker(renderInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			if render {
+				fmt.Fprint(out, "\r\033[K")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if events {
+				emit(Event{Event: "end", Stage: stage, Done: last.Done, Total: last.Total, Status: status(err), ElapsedMs: time.Since(start).Milliseconds()})
+			}
+			return Result{
+				Name:      task.Name,
+				Err:       err,
+				Cancelled: errors.Is(err, context.Canceled),
+				Elapsed:   time.Since(start),
+				Progress:  last,
+			}
+		case <-ticker.C:
+			mu.Lock()
+			p := last
+			mu.Unlock()
+			if events && p != sent {
+				sent = p
+				emit(Event{Event: "progress", Stage: stage, Done: p.Done, Total: p.Total, Item: p.Item, ElapsedMs: time.Since(start).Milliseconds()})
+			}
+			if !render {
+				continue
+			}
+			fmt.Fprintf(out, "\r\033[K%s", progressLine(task.Name, p, time.Since(start)))
+		}
+	}
+}
+
+// progressLine formats the progress of a task for the status line
+func progressLine(name string, p Progress, elapsed time.Duration) string {
+	count := fmt.Sprintf("%d", p.Done)
+	if p.Total > 0 {
+		count = fmt.Sprintf("%d/%d", p.Done, p.Total)
+	}
+	line := fmt.Sprintf("%s: %s (%s)", name, count, elapsed.Round(time.Second))
+	if p.Item != "" {
+		line += " " + p.Item
+	}
+	return line
+}
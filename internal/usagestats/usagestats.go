@@ -0,0 +1,104 @@
+// File: internal/usagestats/usagestats.go
+package usagestats
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	"vault.module/internal/errors"
+	"vault.module/internal/statefile"
+)
+
+// StateFile holds the usage statistics. It lives next to config.json and
+// audit.log and is only written when usage_stats is enabled. It is never
+// transmitted: it holds command paths and flag names, no argument or value.
+const StateFile = "usage-stats.json"
+
+var stateFile = statefile.File{Path: StateFile, Name: "usage statistics"}
+
+// Count is how often something was used
+type Count struct {
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// Stats are the usage counts since Since
+type Stats struct {
+	Since    time.Time        `json:"since"`
+	Commands map[string]Count `json:"commands"` // By command path, e.g. "vaults add"
+	Features map[string]Count `json:"features"` // Flags by command, e.g. "get --json"
+}
+
+// Usage is a counted command or feature, for listing
+type Usage struct {
+	Name string
+	Count
+}
+
+// Record counts a run of a command and the flags given to it
+func Record(command string, flags []string) error {
+	st := &Stats{}
+	return stateFile.Update(st, func() error {
+		st.init()
+		now := time.Now().UTC().Truncate(time.Second)
+		if st.Since.IsZero() {
+			st.Since = now
+		}
+		count(st.Commands, command, now)
+		for _, flag := range flags {
+			count(st.Features, command+" --"+flag, now)
+		}
+		return nil
+	})
+}
+
+func count(m map[string]Count, key string, now time.Time) {
+	c := m[key]
+	c.Count++
+	c.LastUsed = now
+	m[key] = c
+}
+
+// Sorted lists the counts, most used first
+func Sorted(m map[string]Count) []Usage {
+	list := make([]Usage, 0, len(m))
+	for name, c := range m {
+		list = append(list, Usage{Name: name, Count: c})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count.Count != list[j].Count.Count {
+			return list[i].Count.Count > list[j].Count.Count
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Load reads the statistics; there are none before the first record
+func Load() (*Stats, error) {
+	st := &Stats{}
+	if err := stateFile.Load(st); err != nil {
+		return nil, err
+	}
+	st.init()
+	return st, nil
+}
+
+// init makes the maps of statistics read from a file without them
+func (st *Stats) init() {
+	if st.Commands == nil {
+		st.Commands = map[string]Count{}
+	}
+	if st.Features == nil {
+		st.Features = map[string]Count{}
+	}
+}
+
+// Reset deletes the statistics
+func Reset() error {
+	if err := os.Remove(StateFile); err != nil && !os.IsNotExist(err) {
+		return errors.NewFileSystemError("remove", StateFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// File: internal/vault/access.go
+package vault
+
+import (
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/config"
+)
+
+// AccessEntry records one access to the secrets of a wallet. With access_log
+// set on the vault, the last entries of each wallet are kept in the encrypted
+// header, so the vault carries its recent usage even if audit.log is lost.
+type AccessEntry struct {
+	Time     string `json:"time"` // RFC 3339, UTC
+	Command  string `json:"command"`
+	Field    string `json:"field,omitempty"`
+	Operator string `json:"operator,omitempty"`
+}
+
+var (
+	accessLogs   = map[string]map[string][]AccessEntry{}
+	accessLogsMu sync.Mutex
+)
+
+func rememberAccess(keyFile string, access map[string][]AccessEntry) {
+	accessLogsMu.Lock()
+	defer accessLogsMu.Unlock()
+	accessLogs[keyFile] = access
+}
+
+// accessFor returns the access log to write with v: what was loaded, for the
+// wallets v still holds
+func accessFor(keyFile string, v Vault) map[string][]AccessEntry {
+	accessLogsMu.Lock()
+	defer accessLogsMu.Unlock()
+	var access map[string][]AccessEntry
+	for prefix, entries := range accessLogs[keyFile] {
+		if _, ok := v[prefix]; ok && len(entries) > 0 {
+			if access == nil {
+				access = make(map[string][]AccessEntry)
+			}
+			access[prefix] = entries
+		}
+	}
+	return access
+}
+
+// WalletAccess returns the access log of a wallet recorded in the vault at
+// keyFile, as loaded by the last LoadVault.
+func WalletAccess(keyFile, prefix string) []AccessEntry {
+	accessLogsMu.Lock()
+	defer accessLogsMu.Unlock()
+	return append([]AccessEntry(nil), accessLogs[keyFile][prefix]...)
+}
+
+// RecordAccess adds an access to a wallet's log in the vault and saves the
+// vault, when the vault keeps an access log. v is the vault as loaded.
+func RecordAccess(details config.VaultDetails, v Vault, prefix, field string) error {
+	if details.AccessLog <= 0 {
+		return nil
+	}
+	accessLogsMu.Lock()
+	access := make(map[string][]AccessEntry, len(accessLogs[details.KeyFile])+1)
+	for p, entries := range accessLogs[details.KeyFile] {
+		access[p] = entries
+	}
+	entries := append(append([]AccessEntry(nil), access[prefix]...), AccessEntry{
+		Time:     time.Now().UTC().Format(time.RFC3339),
+		Command:  HistoryCommand,
+		Field:    field,
+		Operator: config.Cfg.Operator,
+	})
+	if len(entries) > details.AccessLog {
+		entries = entries[len(entries)-details.AccessLog:]
+	}
+	access[prefix] = entries
+	accessLogs[details.KeyFile] = access
+	accessLogsMu.Unlock()
+
+	// Only the access log changes: the history gains no entries
+	if err := writeVault(details, v, func(stored Vault) []HistoryEntry {
+		return nextHistory(details.KeyFile, stored)
+	}); err != nil {
+		return err
+	}
+	audit.Logger.Info("Wallet access recorded in vault",
+		slog.String("key_file", filepath.Base(details.KeyFile)),
+		slog.String("prefix", prefix),
+		slog.String("field", field))
+	return nil
+}
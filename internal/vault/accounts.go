@@ -0,0 +1,113 @@
+// File: internal/vault/accounts.go
+package vault
+
+import "sort"
+
+// An HD wallet's addresses are grouped into BIP-44 accounts: the hardened
+// level of the derivation path before the address chain (m/44'/60'/N'/0/i).
+// Account 0 holds the addresses of wallets that predate accounts, whose
+// Address.Account is therefore omitted from the JSON.
+//
+// The path scheme decides where the next address goes. BIP-44 wallets (MetaMask,
+// Trezor) count addresses within account 0; Ledger Live counts accounts and
+// uses address 0 of each; the old Ledger Chrome app and MyEtherWallet count
+// one level higher, under a derivation path without the change level.
+
+// Path schemes
+const (
+	PathSchemeBIP44        = "bip44"         // m/44'/60'/0'/0/i
+	PathSchemeLedgerLive   = "ledger-live"   // m/44'/60'/i'/0/0
+	PathSchemeLedgerLegacy = "ledger-legacy" // m/44'/60'/0'/i
+)
+
+// PathSchemes lists the accepted path schemes
+var PathSchemes = []string{PathSchemeBIP44, PathSchemeLedgerLive, PathSchemeLedgerLegacy}
+
+// AddressAt returns the address with the given account and index, or nil
+func (w Wallet) AddressAt(account, index int) *Address {
+	for i := range w.Addresses {
+		if w.Addresses[i].Account == account && w.Addresses[i].Index == index {
+			return &w.Addresses[i]
+		}
+	}
+	return nil
+}
+
+// NextIndex returns the index following the last address of an account
+func (w Wallet) NextIndex(account int) int {
+	next := 0
+	for _, addr := range w.Addresses {
+		if addr.Account == account && addr.Index >= next {
+			next = addr.Index + 1
+		}
+	}
+	return next
+}
+
+// Accounts returns the accounts the wallet has addresses in, in order
+func (w Wallet) Accounts() []int {
+	seen := map[int]bool{}
+	var accounts []int
+	for _, addr := range w.Addresses {
+		if !seen[addr.Account] {
+			seen[addr.Account] = true
+			accounts = append(accounts, addr.Account)
+		}
+	}
+	sort.Ints(accounts)
+	return accounts
+}
+
+// SortAddresses orders the addresses by account, then index
+func (w *Wallet) SortAddresses() {
+	sort.SliceStable(w.Addresses, func(i, j int) bool {
+		a, b := w.Addresses[i], w.Addresses[j]
+		if a.Account != b.Account {
+			return a.Account < b.Account
+		}
+		return a.Index < b.Index
+	})
+}
+
+// Scheme returns the wallet's path scheme; wallets without one use BIP-44
+func (w Wallet) Scheme() string {
+	if w.PathScheme == "" {
+		return PathSchemeBIP44
+	}
+	return w.PathScheme
+}
+
+// SchemePosition returns the account and index of the n-th address of the
+// wallet's path scheme
+func (w Wallet) SchemePosition(n int) (account, index int) {
+	if w.Scheme() == PathSchemeLedgerLive {
+		return n, 0
+	}
+	return 0, n
+}
+
+// SchemeOrdinal returns where an address falls in the sequence of the
+// wallet's path scheme, or -1 if it lies outside it
+func (w Wallet) SchemeOrdinal(addr Address) int {
+	if w.Scheme() == PathSchemeLedgerLive {
+		if addr.Index != 0 {
+			return -1
+		}
+		return addr.Account
+	}
+	if addr.Account != 0 {
+		return -1
+	}
+	return addr.Index
+}
+
+// SchemeLength returns the ordinal following the wallet's last address in its scheme
+func (w Wallet) SchemeLength() int {
+	next := 0
+	for _, addr := range w.Addresses {
+		if n := w.SchemeOrdinal(addr); n >= next {
+			next = n + 1
+		}
+	}
+	return next
+}
@@ -0,0 +1,41 @@
+// File: internal/vault/addressindex.go
+package vault
+
+import "strings"
+
+// AddressRef locates an address within a vault
+type AddressRef struct {
+	Prefix  string
+	Account int
+	Index   int
+}
+
+// AddressIndex maps the addresses of a decrypted vault to the wallets holding
+// them. It lives only as long as the vault it was built from.
+type AddressIndex map[string][]AddressRef
+
+// IndexAddresses builds the address index of v
+func IndexAddresses(v Vault) AddressIndex {
+	index := AddressIndex{}
+	for prefix, wallet := range v {
+		for _, addr := range wallet.Addresses {
+			key := normalizeAddress(addr.Address)
+			if key == "" {
+				continue
+			}
+			index[key] = append(index[key], AddressRef{Prefix: prefix, Account: addr.Account, Index: addr.Index})
+		}
+	}
+	return index
+}
+
+// Lookup returns where address is held. Several results mean the same key was
+// imported into more than one wallet.
+func (x AddressIndex) Lookup(address string) []AddressRef {
+	return x[normalizeAddress(address)]
+}
+
+// normalizeAddress makes lookups ignore the EIP-55 checksum case and whitespace
+func normalizeAddress(address string) string {
+	return strings.ToLower(strings.TrimSpace(address))
+}
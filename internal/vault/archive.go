@@ -0,0 +1,47 @@
+// File: internal/vault/archive.go
+package vault
+
+// ArchiveRef points a stub to the archive vault holding the wallet it stands for
+type ArchiveRef struct {
+	Vault   string `json:"vault"`   // Name of the archive vault in config.json
+	KeyFile string `json:"keyFile"` // Its file, to find it again if it was renamed
+	Prefix  string `json:"prefix"`  // Of the wallet in the archive vault, kept if the stub is renamed
+	Since   string `json:"since"`   // RFC 3339, UTC
+}
+
+// Archived reports whether the wallet is a stub of an archived wallet
+func (w Wallet) Archived() bool {
+	return w.Archive != nil
+}
+
+// ArchiveStub returns the entry left in the primary vault when w is moved to an
+// archive vault: a watch-only wallet with the public addresses, tags and notes
+// of w, and where w went. It holds no secret.
+func ArchiveStub(w Wallet, ref ArchiveRef) Wallet {
+	stub := Wallet{
+		Kind:           KindWatch,
+		DerivationPath: w.DerivationPath,
+		PathScheme:     w.PathScheme,
+		Addresses:      make([]Address, 0, len(w.Addresses)),
+		Notes:          w.Notes,
+		Frozen:         w.Frozen,
+		Tags:           append([]string(nil), w.Tags...),
+		Archive:        &ref,
+	}
+	for _, addr := range w.Addresses {
+		addr.PrivateKey = nil
+		stub.Addresses = append(stub.Addresses, addr)
+	}
+	return stub
+}
+
+// ArchivedPrefixes returns the prefixes of the archived wallet stubs in v
+func (v Vault) ArchivedPrefixes() []string {
+	var prefixes []string
+	for prefix, w := range v {
+		if w.Archive != nil {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
@@ -0,0 +1,214 @@
+// File: internal/vault/attestation.go
+package vault
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+)
+
+// AttestationSuffix is appended to the name of an export file to name its attestation
+const AttestationSuffix = ".sig"
+
+// attestationVersion is the version of the attestation document
+const attestationVersion = 1
+
+// AttestationKey is the vault-held Ed25519 key that signs the vault's exports,
+// with the UUID identifying the vault in them. Both are created with the first
+// signed export and kept in the encrypted vault, so they survive renames and
+// moves of the vault file.
+type AttestationKey struct {
+	VaultID string                 `json:"vault_id"`
+	Seed    *security.SecureString `json:"seed"`
+}
+
+// attestationKeys carries the key of each vault loaded in this process to
+// SaveVault, like integrityKeys
+var (
+	attestationKeys   = map[string]*AttestationKey{}
+	attestationKeysMu sync.Mutex
+)
+
+func rememberAttestationKey(keyFile string, key *AttestationKey) {
+	attestationKeysMu.Lock()
+	defer attestationKeysMu.Unlock()
+	if key == nil {
+		delete(attestationKeys, keyFile)
+		return
+	}
+	attestationKeys[keyFile] = key
+}
+
+func attestationKeyFor(keyFile string) *AttestationKey {
+	attestationKeysMu.Lock()
+	defer attestationKeysMu.Unlock()
+	return attestationKeys[keyFile]
+}
+
+// Attestation is written next to an export file. It says which vault and which
+// build of the tool produced the file and when, and signs that together with
+// the file's SHA-256.
+type Attestation struct {
+	Version   int    `json:"version"`
+	Tool      string `json:"tool"`
+	VaultID   string `json:"vault_id"`
+	Vault     string `json:"vault"`      // Name of the vault in the exporting installation
+	CreatedAt string `json:"created_at"` // RFC 3339, UTC
+	File      string `json:"file"`       // Base name of the export file
+	SHA256    string `json:"sha256"`
+	PublicKey string `json:"public_key"` // Base64 Ed25519 key of the vault
+	Signature string `json:"signature,omitempty"`
+}
+
+// signedPayload is what the signature covers: the attestation without its signature
+func (a Attestation) signedPayload() ([]byte, error) {
+	a.Signature = ""
+	return json.Marshal(a)
+}
+
+// newVaultID returns a random (version 4) UUID
+func newVaultID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// ensureAttestationKey returns the attestation key of a loaded vault, creating
+// it and saving the vault if the vault has none.
+func ensureAttestationKey(details config.VaultDetails, v Vault) (*AttestationKey, error) {
+	if key := attestationKeyFor(details.KeyFile); key != nil {
+		return key, nil
+	}
+	id, err := newVaultID()
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrCodeSystem, "failed to generate vault ID", err)
+	}
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrCodeSystem, "failed to generate attestation key", err)
+	}
+	key := &AttestationKey{VaultID: id, Seed: security.NewSecureString(hex.EncodeToString(priv.Seed()))}
+	security.SecureZero(priv)
+
+	rememberAttestationKey(details.KeyFile, key)
+	if err := SaveVault(details, v); err != nil {
+		rememberAttestationKey(details.KeyFile, nil)
+		return nil, err
+	}
+	audit.Logger.Info("Vault attestation key created",
+		slog.String("key_file", filepath.Base(details.KeyFile)),
+		slog.String("vault_id", id))
+	return key, nil
+}
+
+// withAttestationKey runs fn with the private key of key
+func withAttestationKey(key *AttestationKey, fn func(priv ed25519.PrivateKey)) error {
+	return key.Seed.WithSecureOperation(func(seedHex []byte) error {
+		seed := make([]byte, ed25519.SeedSize)
+		defer security.SecureZero(seed)
+		if _, err := hex.Decode(seed, seedHex); err != nil {
+			return errors.New(errors.ErrCodeInternal, "invalid attestation key")
+		}
+		priv := ed25519.NewKeyFromSeed(seed)
+		defer security.SecureZero(priv)
+		fn(priv)
+		return nil
+	})
+}
+
+// AttestationIdentity returns the UUID and the base64 public attestation key of
+// a loaded vault, creating them if the vault has none.
+func AttestationIdentity(details config.VaultDetails, v Vault) (string, string, error) {
+	key, err := ensureAttestationKey(details, v)
+	if err != nil {
+		return "", "", err
+	}
+	var publicKey string
+	err = withAttestationKey(key, func(priv ed25519.PrivateKey) {
+		publicKey = base64.StdEncoding.EncodeToString(priv.Public().(ed25519.PublicKey))
+	})
+	return key.VaultID, publicKey, err
+}
+
+// AttestExport signs the export data written to file with the attestation key
+// of a loaded vault, creating the key if the vault has none.
+func AttestExport(details config.VaultDetails, v Vault, data []byte, file string) (Attestation, error) {
+	key, err := ensureAttestationKey(details, v)
+	if err != nil {
+		return Attestation{}, err
+	}
+	sum := sha256.Sum256(data)
+	attestation := Attestation{
+		Version:   attestationVersion,
+		Tool:      constants.Version,
+		VaultID:   key.VaultID,
+		Vault:     config.NameForKeyFile(details.KeyFile),
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		File:      filepath.Base(file),
+		SHA256:    hex.EncodeToString(sum[:]),
+	}
+
+	var signErr error
+	err = withAttestationKey(key, func(priv ed25519.PrivateKey) {
+		attestation.PublicKey = base64.StdEncoding.EncodeToString(priv.Public().(ed25519.PublicKey))
+		payload, err := attestation.signedPayload()
+		if err != nil {
+			signErr = err
+			return
+		}
+		attestation.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, payload))
+	})
+	if err == nil {
+		err = signErr
+	}
+	if err != nil {
+		return Attestation{}, err
+	}
+	return attestation, nil
+}
+
+// VerifyAttestation checks that attestation signs data. It does not say whether
+// the signing vault is trusted; see config.FindTrustedExporter.
+func VerifyAttestation(attestation Attestation, data []byte) error {
+	if attestation.Version != attestationVersion {
+		return fmt.Errorf("unsupported attestation version %d", attestation.Version)
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(attestation.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key")
+	}
+	signature, err := base64.StdEncoding.DecodeString(attestation.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding")
+	}
+	payload, err := attestation.signedPayload()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(ed25519.PublicKey(publicKey), payload, signature) {
+		return fmt.Errorf("the signature does not match the attestation")
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != attestation.SHA256 {
+		return fmt.Errorf("the file was modified after it was exported")
+	}
+	return nil
+}
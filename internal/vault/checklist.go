@@ -0,0 +1,62 @@
+// File: internal/vault/checklist.go
+package vault
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultChecklistItems are the cold-storage steps tracked when the configuration
+// does not define its own
+var DefaultChecklistItems = []string{
+	"Backup made",
+	"Backup verified",
+	"Test transaction done",
+	"Passphrase stored",
+}
+
+// ChecklistItem is one step of a wallet's cold-storage checklist
+type ChecklistItem struct {
+	ID     string     `json:"id"`
+	Title  string     `json:"title"`
+	Done   bool       `json:"done"`
+	DoneAt *time.Time `json:"doneAt,omitempty"`
+}
+
+// NewChecklist creates an open checklist with one item per title
+func NewChecklist(titles []string) []ChecklistItem {
+	items := make([]ChecklistItem, 0, len(titles))
+	for _, title := range titles {
+		items = append(items, ChecklistItem{ID: ChecklistID(title), Title: title})
+	}
+	return items
+}
+
+// ChecklistID derives the identifier of an item from its title: lower case,
+// with runs of other characters replaced by a dash ("Backup made" -> "backup-made")
+func ChecklistID(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// ChecklistProgress returns the number of completed and total checklist items
+func (w *Wallet) ChecklistProgress() (done, total int) {
+	for _, item := range w.Checklist {
+		if item.Done {
+			done++
+		}
+	}
+	return done, len(w.Checklist)
+}
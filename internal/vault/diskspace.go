@@ -0,0 +1,43 @@
+// File: internal/vault/diskspace.go
+package vault
+
+import (
+	stderrors "errors"
+	"strings"
+	"syscall"
+
+	"vault.module/internal/errors"
+)
+
+// checkSaveTarget makes sure a vault serialized to size bytes can be written
+// next to the key file in dir before anything is written: the directory must
+// be writable and hold the encrypted copy while the original still exists.
+// The age armor grows the data by a third; the margin covers headers and
+// file system overhead.
+func checkSaveTarget(dir string, size int) error {
+	if err := dirWritable(dir); err != nil {
+		if stderrors.Is(err, syscall.EROFS) || stderrors.Is(err, syscall.EACCES) || stderrors.Is(err, syscall.EPERM) {
+			return errors.NewReadOnlyFSError(dir, err)
+		}
+		return errors.NewFileSystemError("access", dir, err)
+	}
+	needed := uint64(size) + uint64(size)/3 + 64*1024
+	if available, ok := freeSpace(dir); ok && available < needed {
+		return errors.NewDiskFullError(dir, needed, available, nil)
+	}
+	return nil
+}
+
+// saveWriteError reports a failed write of a save, as DISK_FULL or
+// FILESYSTEM_READ_ONLY when that is the cause. stderr is the output of the
+// tool that wrote, if any.
+func saveWriteError(op, path string, err error, stderr string) *errors.VaultError {
+	switch {
+	case stderrors.Is(err, syscall.ENOSPC), stderrors.Is(err, syscall.EDQUOT),
+		strings.Contains(stderr, "no space left on device"), strings.Contains(stderr, "disk quota exceeded"):
+		return errors.NewDiskFullError(path, 0, 0, err)
+	case stderrors.Is(err, syscall.EROFS), strings.Contains(stderr, "read-only file system"):
+		return errors.NewReadOnlyFSError(path, err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+//go:build !unix
+// +build !unix
+
+// File: internal/vault/diskspace_other.go
+package vault
+
+import (
+	"os"
+)
+
+// freeSpace is not determined on this platform; saves rely on the write errors
+func freeSpace(dir string) (uint64, bool) {
+	return 0, false
+}
+
+// dirWritable reports why files cannot be created in dir, nil if they can
+func dirWritable(dir string) error {
+	probe, err := os.CreateTemp(dir, "vault-probe-*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
@@ -0,0 +1,180 @@
+// File: internal/vault/diskspace_test.go
+package vault
+
+import (
+	"bytes"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/errors"
+)
+
+func TestSaveWriteError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		stderr string
+		want   errors.ErrorCode // "" when the error is not a disk error
+	}{
+		{"ENOSPC", syscall.ENOSPC, "", errors.ErrCodeDiskFull},
+		{"wrapped ENOSPC", fmt.Errorf("write vault.tmp: %w", syscall.ENOSPC), "", errors.ErrCodeDiskFull},
+		{"EDQUOT", &os.PathError{Op: "write", Path: "vault.tmp", Err: syscall.EDQUOT}, "", errors.ErrCodeDiskFull},
+		{"age out of space", stderrors.New("exit status 1"), "age: error: write vault.tmp: no space left on device", errors.ErrCodeDiskFull},
+		{"age over quota", stderrors.New("exit status 1"), "age: error: disk quota exceeded", errors.ErrCodeDiskFull},
+		{"EROFS", syscall.EROFS, "", errors.ErrCodeReadOnlyFS},
+		{"age on read-only fs", stderrors.New("exit status 1"), "age: error: open vault.tmp: read-only file system", errors.ErrCodeReadOnlyFS},
+		{"other error", stderrors.New("exit status 1"), "age: error: no identity matched any of the recipients", ""},
+		{"permission", syscall.EACCES, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := saveWriteError("write", "vault.tmp", tt.err, tt.stderr)
+			if tt.want == "" {
+				if got != nil {
+					t.Fatalf("saveWriteError() = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Code != tt.want {
+				t.Fatalf("saveWriteError() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSaveTarget(t *testing.T) {
+	dir := t.TempDir()
+	readOnly := filepath.Join(t.TempDir(), "readonly")
+	if err := os.Mkdir(readOnly, 0500); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		dir  string
+		size int
+		want errors.ErrorCode // "" when the save may go ahead
+		skip string
+	}{
+		{name: "fits", dir: dir, size: 4096},
+		{name: "larger than the disk", dir: dir, size: math.MaxInt64 / 2, want: errors.ErrCodeDiskFull},
+		{name: "missing directory", dir: filepath.Join(dir, "missing"), size: 4096, want: errors.ErrCodeFileSystem},
+		{name: "read-only directory", dir: readOnly, size: 4096, want: errors.ErrCodeReadOnlyFS, skip: readOnlySkipReason()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.skip != "" {
+				t.Skip(tt.skip)
+			}
+			err := checkSaveTarget(tt.dir, tt.size)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("checkSaveTarget() = %v, want nil", err)
+				}
+				return
+			}
+			var vaultErr *errors.VaultError
+			if !stderrors.As(err, &vaultErr) || vaultErr.Code != tt.want {
+				t.Fatalf("checkSaveTarget() = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+// readOnlySkipReason explains why directory permissions cannot be tested here
+func readOnlySkipReason() string {
+	switch {
+	case runtime.GOOS == "windows":
+		return "directory modes are not enforced on Windows"
+	case os.Geteuid() == 0:
+		return "root writes to read-only directories"
+	}
+	return ""
+}
+
+// TestSaveVaultDiskFullMidWrite runs a save whose age writes part of the
+// ciphertext and then fails with ENOSPC, and checks that the save reports
+// DISK_FULL and leaves the original vault file and directory as they were.
+func TestSaveVaultDiskFullMidWrite(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the failing age is a shell script")
+	}
+	audit.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "bin")
+	if err := os.Mkdir(bin, 0700); err != nil {
+		t.Fatal(err)
+	}
+	// An age that runs out of space halfway through its output file
+	failingAge := `#!/bin/sh
+out=""
+while [ $# -gt 0 ]; do
+	if [ "$1" = "-o" ]; then out="$2"; shift; fi
+	shift
+done
+head -c 100 >"$out"
+echo "age: error: failed to write to output: write $out: no space left on device" >&2
+exit 1
+`
+	if err := os.WriteFile(filepath.Join(bin, "age"), []byte(failingAge), 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	vaultDir := filepath.Join(dir, "vaults")
+	if err := os.Mkdir(vaultDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(vaultDir, "main.key")
+	original := []byte("-----BEGIN AGE ENCRYPTED FILE-----\noriginal vault\n-----END AGE ENCRYPTED FILE-----\n")
+	if err := os.WriteFile(keyFile, original, 0600); err != nil {
+		t.Fatal(err)
+	}
+	recipients := filepath.Join(dir, "recipients.txt")
+	if err := os.WriteFile(recipients, []byte("age1yubikey1example\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	details := config.VaultDetails{
+		KeyFile:        keyFile,
+		RecipientsFile: recipients,
+		Type:           constants.VaultTypeEVM,
+		Encryption:     constants.EncryptionYubiKey,
+	}
+	err := SaveVault(details, Vault{"W1": {Notes: "test wallet"}})
+
+	var vaultErr *errors.VaultError
+	if !stderrors.As(err, &vaultErr) || vaultErr.Code != errors.ErrCodeDiskFull {
+		t.Fatalf("SaveVault() = %v, want %s", err, errors.ErrCodeDiskFull)
+	}
+	got, readErr := os.ReadFile(keyFile)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if !bytes.Equal(got, original) {
+		t.Fatalf("the vault file changed after a failed save:\n%s", got)
+	}
+	entries, readErr := os.ReadDir(vaultDir)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if len(entries) != 1 {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Fatalf("the failed save left files behind: %v", names)
+	}
+}
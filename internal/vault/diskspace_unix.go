@@ -0,0 +1,26 @@
+//go:build unix
+// +build unix
+
+// File: internal/vault/diskspace_unix.go
+package vault
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// freeSpace returns the bytes available to this user on the file system of
+// dir, and whether it could be determined
+func freeSpace(dir string) (uint64, bool) {
+	var st unix.Statfs_t
+	// Pseudo and some network file systems report no blocks at all
+	if err := unix.Statfs(dir, &st); err != nil || st.Blocks == 0 {
+		return 0, false
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), true
+}
+
+// dirWritable reports why files cannot be created in dir, nil if they can.
+// A read-only file system is reported as EROFS.
+func dirWritable(dir string) error {
+	return unix.Access(dir, unix.W_OK)
+}
@@ -0,0 +1,144 @@
+// File: internal/vault/envelope.go
+package vault
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os/exec"
+	"strings"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+)
+
+// A sealed wallet keeps its secrets in an envelope: the mnemonic or seed, generic
+// secret and private keys are encrypted with a passphrase of their own and stored in
+// the vault as an armored age file. Unlocking the vault alone reveals only the
+// public data of the wallet; the envelope is opened in memory for each access
+// and never written back in the clear.
+
+// envelopeSecrets is the plaintext of an envelope
+type envelopeSecrets struct {
+	Mnemonic    *security.SecureString         `json:"mnemonic,omitempty"`
+	Seed        *security.SecureString         `json:"seed,omitempty"`
+	Secret      *security.SecureString         `json:"secret,omitempty"`
+	PrivateKeys map[int]*security.SecureString `json:"privateKeys,omitempty"`
+	// Keys of addresses outside account 0, by derivation path
+	AccountKeys map[string]*security.SecureString `json:"accountKeys,omitempty"`
+}
+
+// Sealed reports whether the wallet's secrets are kept in an envelope
+func (w Wallet) Sealed() bool {
+	return w.Envelope != ""
+}
+
+// withoutSecrets returns a copy of the wallet stripped of the secrets its envelope holds
+func (w Wallet) withoutSecrets() Wallet {
+	stripped := w
+	stripped.Mnemonic = nil
+	stripped.Seed = nil
+	stripped.Secret = nil
+	stripped.Addresses = make([]Address, len(w.Addresses))
+	for i, addr := range w.Addresses {
+		stripped.Addresses[i] = addr
+		stripped.Addresses[i].PrivateKey = nil
+	}
+	return stripped
+}
+
+// SealWallet moves the wallet's secrets into an envelope encrypted with passphrase.
+func SealWallet(w *Wallet, passphrase string) error {
+	if w.Sealed() {
+		return errors.NewInvalidInputError("envelope", "wallet is already sealed")
+	}
+
+	secrets := envelopeSecrets{Mnemonic: w.Mnemonic, Seed: w.Seed, Secret: w.Secret, PrivateKeys: map[int]*security.SecureString{}}
+	for _, addr := range w.Addresses {
+		switch {
+		case addr.PrivateKey == nil:
+		case addr.Account == 0:
+			secrets.PrivateKeys[addr.Index] = addr.PrivateKey
+		default:
+			if secrets.AccountKeys == nil {
+				secrets.AccountKeys = map[string]*security.SecureString{}
+			}
+			secrets.AccountKeys[addr.Path] = addr.PrivateKey
+		}
+	}
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return errors.New(errors.ErrCodeInternal, "failed to serialize wallet secrets").WithContext("marshal_error", err.Error())
+	}
+	defer security.SecureZero(plaintext)
+
+	envelope, err := encryptWithPassphrase(plaintext, passphrase, true)
+	if err != nil {
+		return err
+	}
+
+	sealed := w.withoutSecrets()
+	sealed.Envelope = string(envelope)
+	w.Clear()
+	*w = sealed
+	return nil
+}
+
+// OpenWallet decrypts the wallet's envelope and restores its secrets in memory.
+// The wallet stays sealed: SaveVault writes only the envelope.
+func OpenWallet(w *Wallet) error {
+	if !w.Sealed() {
+		return nil
+	}
+	tty, err := openTTYSafely()
+	if err != nil {
+		return err
+	}
+	tty.Close()
+
+	plaintext := createSecureBuffer("wallet_envelope_buffer")
+	defer plaintext.Clear()
+	var stderr bytes.Buffer
+	cmd := exec.Command("age", "--decrypt")
+	cmd.Stdin = strings.NewReader(w.Envelope)
+	cmd.Stdout = &secureBufferWriter{buffer: plaintext}
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		audit.Logger.Error("Wallet envelope decryption failed", slog.String("stderr", sanitizeLogOutput(stderr.String())))
+		return errors.NewAuthFailedError("wallet envelope rejected (wrong passphrase)")
+	}
+
+	var secrets envelopeSecrets
+	err = plaintext.WithSecureOperation(func(data []byte) error {
+		return json.Unmarshal(data, &secrets)
+	})
+	if err != nil {
+		return errors.NewFormatInvalidError("envelope", "wallet envelope is corrupt")
+	}
+
+	w.Mnemonic = secrets.Mnemonic
+	w.Seed = secrets.Seed
+	w.Secret = secrets.Secret
+	addresses := make([]Address, len(w.Addresses))
+	for i, addr := range w.Addresses {
+		addresses[i] = addr
+		if addr.Account == 0 {
+			addresses[i].PrivateKey = secrets.PrivateKeys[addr.Index]
+		} else {
+			addresses[i].PrivateKey = secrets.AccountKeys[addr.Path]
+		}
+	}
+	w.Addresses = addresses
+	return nil
+}
+
+// UnsealWallet opens the wallet's envelope for good, so its secrets are stored
+// with the rest of the vault again
+func UnsealWallet(w *Wallet) error {
+	if err := OpenWallet(w); err != nil {
+		return err
+	}
+	w.Envelope = ""
+	return nil
+}
@@ -0,0 +1,41 @@
+// File: internal/vault/fido2.go
+package vault
+
+import (
+	"context"
+	"os/exec"
+
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/errors"
+)
+
+// fido2PluginName is the plugin name passed to `age -j` for identity-less decryption
+const fido2PluginName = "fido2-hmac"
+
+// fido2DecryptCommand builds the age invocation that decrypts a vault protected by
+// a FIDO2 hmac-secret credential. age and the plugin prompt for the PIN and user
+// presence on the controlling terminal themselves.
+func fido2DecryptCommand(ctx context.Context, details config.VaultDetails) (*exec.Cmd, error) {
+	if _, err := exec.LookPath(constants.PluginFIDO2); err != nil {
+		return nil, errors.NewDependencyError(constants.PluginFIDO2, "Please install it: https://github.com/olastor/age-plugin-fido2-hmac")
+	}
+	if _, err := exec.LookPath("age"); err != nil {
+		return nil, errors.NewDependencyError("age", "Please install it: https://github.com/FiloSottile/age")
+	}
+
+	args := []string{"--decrypt"}
+	if details.IdentityFile != "" {
+		// Non-discoverable credential: the identity file carries the credential ID
+		if err := config.ValidateFilePath(details.IdentityFile, "identity file"); err != nil {
+			return nil, errors.NewVaultInvalidPathError(details.IdentityFile, err)
+		}
+		args = append(args, "-i", details.IdentityFile)
+	} else {
+		// Credential ID is stored in the recipient stanza, no identity file needed
+		args = append(args, "-j", fido2PluginName)
+	}
+	args = append(args, details.KeyFile)
+
+	return exec.CommandContext(ctx, "age", args...), nil
+}
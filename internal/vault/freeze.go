@@ -0,0 +1,20 @@
+// File: internal/vault/freeze.go
+package vault
+
+// Freeze is a legal hold on a wallet: its keys are retained in the vault, but
+// secret retrieval, signing, export and deletion are refused until it is lifted.
+type Freeze struct {
+	Reason string `json:"reason"`
+	Since  string `json:"since"` // RFC 3339, UTC
+}
+
+// FrozenPrefixes returns the prefixes of the frozen wallets in v
+func (v Vault) FrozenPrefixes() []string {
+	var prefixes []string
+	for prefix, w := range v {
+		if w.Frozen != nil {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
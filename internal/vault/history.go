@@ -0,0 +1,168 @@
+// File: internal/vault/history.go
+package vault
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/security"
+)
+
+// History operations
+const (
+	HistoryCreated = "created"
+	HistoryAdded   = "added"
+	HistoryRemoved = "removed"
+	HistoryChanged = "changed"
+)
+
+// MaxHistoryEntries bounds the history kept in the header; the oldest entries are dropped first
+const MaxHistoryEntries = 500
+
+// HistoryEntry records one mutation of the vault. It is stored in the encrypted
+// header, so the provenance of the vault travels with the file.
+type HistoryEntry struct {
+	Time     string `json:"time"` // RFC 3339, UTC
+	Op       string `json:"op"`
+	Wallet   string `json:"wallet,omitempty"`
+	Command  string `json:"command,omitempty"`
+	Operator string `json:"operator,omitempty"`
+	Tool     string `json:"tool"`
+}
+
+// HistoryCommand names the command that is running, for the history entries of its saves
+var HistoryCommand string
+
+// loadedHistory is what LoadVault saw of a vault: its history and a fingerprint
+// of every wallet, against which SaveVault works out what changed
+type loadedHistory struct {
+	entries      []HistoryEntry
+	fingerprints map[string][32]byte
+	notes        map[string][32]byte // Of each wallet's notes, see changedNotes
+}
+
+var (
+	histories   = map[string]*loadedHistory{}
+	historiesMu sync.Mutex
+)
+
+// fingerprintWallets hashes each wallet's serialized form
+func fingerprintWallets(v Vault) map[string][32]byte {
+	fingerprints := make(map[string][32]byte, len(v))
+	for prefix, w := range v {
+		data, err := json.Marshal(w)
+		if err != nil {
+			continue
+		}
+		fingerprints[prefix] = sha256.Sum256(data)
+		security.SecureZero(data)
+	}
+	return fingerprints
+}
+
+func rememberHistory(keyFile string, entries []HistoryEntry, v Vault) {
+	historiesMu.Lock()
+	defer historiesMu.Unlock()
+	notes := make(map[string][32]byte, len(v))
+	for prefix, w := range v {
+		notes[prefix] = sha256.Sum256([]byte(w.Notes))
+	}
+	histories[keyFile] = &loadedHistory{entries: entries, fingerprints: fingerprintWallets(v), notes: notes}
+}
+
+// forgetHistory drops what was loaded of a vault, so its next save starts a new history
+func forgetHistory(keyFile string) {
+	historiesMu.Lock()
+	defer historiesMu.Unlock()
+	delete(histories, keyFile)
+}
+
+// nextHistory returns the history to write with v: the loaded history followed by
+// an entry for every wallet added, removed or changed since the vault was loaded
+func nextHistory(keyFile string, v Vault) []HistoryEntry {
+	historiesMu.Lock()
+	loaded := histories[keyFile]
+	historiesMu.Unlock()
+
+	entry := func(op, wallet string) HistoryEntry {
+		return HistoryEntry{
+			Time:     time.Now().UTC().Format(time.RFC3339),
+			Op:       op,
+			Wallet:   wallet,
+			Command:  HistoryCommand,
+			Operator: config.Cfg.Operator,
+			Tool:     constants.Version,
+		}
+	}
+
+	var entries []HistoryEntry
+	previous := map[string][32]byte{}
+	if loaded != nil {
+		entries = append(entries, loaded.entries...)
+		previous = loaded.fingerprints
+	} else {
+		entries = append(entries, entry(HistoryCreated, ""))
+	}
+
+	current := fingerprintWallets(v)
+	prefixes := make([]string, 0, len(current)+len(previous))
+	for prefix := range current {
+		prefixes = append(prefixes, prefix)
+	}
+	for prefix := range previous {
+		if _, ok := current[prefix]; !ok {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		before, existed := previous[prefix]
+		after, exists := current[prefix]
+		switch {
+		case !existed:
+			entries = append(entries, entry(HistoryAdded, prefix))
+		case !exists:
+			entries = append(entries, entry(HistoryRemoved, prefix))
+		case before != after:
+			entries = append(entries, entry(HistoryChanged, prefix))
+		}
+	}
+
+	if len(entries) > MaxHistoryEntries {
+		entries = entries[len(entries)-MaxHistoryEntries:]
+	}
+	return entries
+}
+
+// LoadedHistory returns the history of the vault at keyFile, as loaded by the
+// last LoadVault
+func LoadedHistory(keyFile string) []HistoryEntry {
+	historiesMu.Lock()
+	defer historiesMu.Unlock()
+	if loaded := histories[keyFile]; loaded != nil {
+		return append([]HistoryEntry(nil), loaded.entries...)
+	}
+	return nil
+}
+
+// LoadHistory returns the history recorded in the vault
+func LoadHistory(details config.VaultDetails) ([]HistoryEntry, error) {
+	v, err := LoadVault(details)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range v {
+		w.Clear()
+	}
+	historiesMu.Lock()
+	defer historiesMu.Unlock()
+	if loaded := histories[details.KeyFile]; loaded != nil {
+		return loaded.entries, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,323 @@
+// File: internal/vault/integrity.go
+package vault
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+)
+
+// Integrity modes
+const (
+	IntegrityEnforce = "enforce"
+	IntegrityWarn    = "warn"
+)
+
+// IntegrityKey is the vault-held Ed25519 key that signs the vault's configuration.
+// It is stored inside the encrypted vault, so an attacker with write access to
+// config.json or the recipients file cannot produce a valid signature.
+type IntegrityKey struct {
+	Seed       *security.SecureString `json:"seed"`
+	Mode       string                 `json:"mode"`
+	Recipients string                 `json:"recipients,omitempty"` // the recipients file as last signed
+}
+
+// integrityKeys carries the key of each vault loaded in this process to SaveVault,
+// which would otherwise drop it from the rewritten header.
+var (
+	integrityKeys   = map[string]*IntegrityKey{}
+	integrityKeysMu sync.Mutex
+)
+
+func rememberIntegrityKey(keyFile string, key *IntegrityKey) {
+	integrityKeysMu.Lock()
+	defer integrityKeysMu.Unlock()
+	if key == nil {
+		delete(integrityKeys, keyFile)
+		return
+	}
+	integrityKeys[keyFile] = key
+}
+
+func integrityKeyFor(keyFile string) *IntegrityKey {
+	integrityKeysMu.Lock()
+	defer integrityKeysMu.Unlock()
+	return integrityKeys[keyFile]
+}
+
+// signedDetails is the canonical form of everything that decides where and how
+// the vault is encrypted
+type signedDetails struct {
+	KeyFile          string `json:"keyfile"`
+	RecipientsFile   string `json:"recipientsfile"`
+	Recipients       string `json:"recipients"`
+	Type             string `json:"type"`
+	Encryption       string `json:"encryption"`
+	YubikeySerial    string `json:"yubikey_serial"`
+	YubikeySlot      string `json:"yubikey_slot"`
+	IdentityFile     string `json:"identityfile"`
+	TPMSealDir       string `json:"tpm_seal_dir"`
+	TPMPCRs          string `json:"tpm_pcrs"`
+	SecondFactor     string `json:"second_factor"`
+	SecondFactorFile string `json:"second_factor_file"`
+	ApprovalURL      string `json:"approval_url"`
+	Operators        string `json:"operators,omitempty"` // name=recipient of each granted operator
+}
+
+// IntegrityModeFor returns the integrity mode of a vault loaded in this process,
+// or "" if the vault has no integrity key
+func IntegrityModeFor(keyFile string) string {
+	if key := integrityKeyFor(keyFile); key != nil {
+		return key.Mode
+	}
+	return ""
+}
+
+// integrityDigest hashes the vault's configuration together with its recipients file
+func integrityDigest(details config.VaultDetails) ([]byte, error) {
+	var recipientsHash string
+	if details.RecipientsFile != "" {
+		data, err := os.ReadFile(details.RecipientsFile)
+		if err != nil {
+			return nil, errors.FromOSError(err, details.RecipientsFile)
+		}
+		sum := sha256.Sum256(data)
+		recipientsHash = hex.EncodeToString(sum[:])
+	}
+
+	var operators []string
+	for _, name := range details.Operators {
+		op, _ := config.FindOperator(name)
+		operators = append(operators, name+"="+op.Recipient)
+	}
+
+	canonical, err := json.Marshal(signedDetails{
+		KeyFile:          details.KeyFile,
+		RecipientsFile:   details.RecipientsFile,
+		Recipients:       recipientsHash,
+		Type:             details.Type,
+		Encryption:       details.Encryption,
+		YubikeySerial:    details.YubikeySerial,
+		YubikeySlot:      details.YubikeySlot,
+		IdentityFile:     details.IdentityFile,
+		TPMSealDir:       details.TPMSealDir,
+		TPMPCRs:          details.TPMPCRs,
+		SecondFactor:     details.SecondFactor,
+		SecondFactorFile: details.SecondFactorFile,
+		ApprovalURL:      details.ApprovalURL,
+		Operators:        strings.Join(operators, ","),
+	})
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeInternal, "failed to serialize vault details").WithContext("marshal_error", err.Error())
+	}
+	sum := sha256.Sum256(canonical)
+	return sum[:], nil
+}
+
+// SignVaultConfig signs the vault's configuration and recipients file with its
+// integrity key, creating the key if the vault has none. The vault must have been
+// loaded in this process. Returns the signature to store in integrity_signature.
+func SignVaultConfig(details config.VaultDetails, v Vault, mode string) (string, error) {
+	if mode != IntegrityEnforce && mode != IntegrityWarn {
+		return "", errors.NewInvalidInputError(mode, "integrity mode must be 'enforce' or 'warn'")
+	}
+
+	key := integrityKeyFor(details.KeyFile)
+	newKey := key == nil
+	if newKey {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return "", errors.Wrap(errors.ErrCodeSystem, "failed to generate integrity key", err)
+		}
+		key = &IntegrityKey{Seed: security.NewSecureString(hex.EncodeToString(priv.Seed()))}
+		security.SecureZero(priv)
+	}
+	key.Mode = mode
+
+	digest, err := integrityDigest(details)
+	if err != nil {
+		return "", err
+	}
+	signature, err := signDigest(key, digest)
+	if err != nil {
+		return "", err
+	}
+	signedRecipients, err := readRecipients(details)
+	if err != nil {
+		return "", err
+	}
+	key.Recipients = signedRecipients
+
+	// Persist the (new) key and mode inside the vault
+	rememberIntegrityKey(details.KeyFile, key)
+	if err := SaveVault(details, v); err != nil {
+		if newKey {
+			rememberIntegrityKey(details.KeyFile, nil)
+		}
+		return "", err
+	}
+
+	audit.Logger.Info("Vault configuration signed",
+		slog.String("key_file", filepath.Base(details.KeyFile)),
+		slog.String("mode", mode))
+	return signature, nil
+}
+
+// verifyIntegrity checks integrity_signature against the key found in the vault header
+func verifyIntegrity(details config.VaultDetails, key *IntegrityKey) error {
+	digest, err := integrityDigest(details)
+	if err != nil {
+		return err
+	}
+
+	valid, err := verifyDigest(key, digest, details.IntegritySignature)
+	if err != nil {
+		return err
+	}
+	if valid {
+		return nil
+	}
+
+	name := config.NameForKeyFile(details.KeyFile)
+	audit.Logger.Error("CRITICAL: vault configuration signature mismatch",
+		slog.String("severity", "CRITICAL"),
+		slog.String("vault", name),
+		slog.String("mode", key.Mode))
+	if key.Mode == IntegrityWarn {
+		Warn(fmt.Sprintf("config.json or the recipients file of vault '%s' does not match its signature.", name))
+		return nil
+	}
+	return errors.NewVaultTamperedError(name).
+		WithDetails(fmt.Sprintf("config.json or the recipients file does not match the signature held in the vault; review them and run 'vault.module vaults sign %s'", name))
+}
+
+// verifyDigest reports whether signature is the integrity key's signature of digest
+func verifyDigest(key *IntegrityKey, digest []byte, signature string) (bool, error) {
+	var valid bool
+	err := key.Seed.WithSecureOperation(func(seedHex []byte) error {
+		seed := make([]byte, ed25519.SeedSize)
+		defer security.SecureZero(seed)
+		if _, err := hex.Decode(seed, seedHex); err != nil {
+			return errors.New(errors.ErrCodeVaultCorrupt, "invalid integrity key")
+		}
+		priv := ed25519.NewKeyFromSeed(seed)
+		defer security.SecureZero(priv)
+
+		raw, err := base64.StdEncoding.DecodeString(signature)
+		valid = err == nil && ed25519.Verify(priv.Public().(ed25519.PublicKey), digest, raw)
+		return nil
+	})
+	return valid, err
+}
+
+// readRecipients returns the vault's recipients file, "" if it has none
+func readRecipients(details config.VaultDetails) (string, error) {
+	if details.RecipientsFile == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(details.RecipientsFile)
+	if err != nil {
+		return "", errors.FromOSError(err, details.RecipientsFile)
+	}
+	return string(data), nil
+}
+
+// RecipientsReview compares a vault's recipients file with the one it was last signed with
+type RecipientsReview struct {
+	Current    string   // the recipients file as it is now
+	Signed     bool     // the vault has an integrity key
+	Matches    bool     // config.json and the recipients file match integrity_signature
+	SignedCopy bool     // the vault holds the recipients file it was signed with
+	Added      []string // recipients not in the signed copy
+	Removed    []string // recipients of the signed copy no longer in the file
+}
+
+// ReviewRecipients compares the recipients file with the signature and the
+// signed copy held in the vault. The vault must have been loaded in this process.
+func ReviewRecipients(details config.VaultDetails) (RecipientsReview, error) {
+	current, err := readRecipients(details)
+	if err != nil {
+		return RecipientsReview{}, err
+	}
+	review := RecipientsReview{Current: current}
+	key := integrityKeyFor(details.KeyFile)
+	if key == nil {
+		return review, nil
+	}
+	review.Signed = true
+
+	digest, err := integrityDigest(details)
+	if err != nil {
+		return review, err
+	}
+	if review.Matches, err = verifyDigest(key, digest, details.IntegritySignature); err != nil {
+		return review, err
+	}
+	if key.Recipients == "" {
+		// Signed before the vault kept a copy of the recipients
+		return review, nil
+	}
+	review.SignedCopy = true
+	signed := recipientLines(key.Recipients)
+	now := recipientLines(current)
+	for line := range now {
+		if !signed[line] {
+			review.Added = append(review.Added, line)
+		}
+	}
+	for line := range signed {
+		if !now[line] {
+			review.Removed = append(review.Removed, line)
+		}
+	}
+	sort.Strings(review.Added)
+	sort.Strings(review.Removed)
+	return review, nil
+}
+
+// recipientLines returns the recipients of a recipients file, without comments
+func recipientLines(data string) map[string]bool {
+	lines := map[string]bool{}
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines[line] = true
+		}
+	}
+	return lines
+}
+
+// signDigest signs digest with the integrity key
+func signDigest(key *IntegrityKey, digest []byte) (string, error) {
+	var signature []byte
+	err := key.Seed.WithSecureOperation(func(seedHex []byte) error {
+		seed := make([]byte, ed25519.SeedSize)
+		defer security.SecureZero(seed)
+		if _, err := hex.Decode(seed, seedHex); err != nil {
+			return errors.New(errors.ErrCodeInternal, "invalid integrity key")
+		}
+		priv := ed25519.NewKeyFromSeed(seed)
+		defer security.SecureZero(priv)
+		signature = ed25519.Sign(priv, digest)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,100 @@
+// File: internal/vault/memory.go
+package vault
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+)
+
+// Decrypting a vault holds, at its peak, the plaintext in a locked buffer, the
+// JSON being decoded and a locked copy of every secret with its pad. The
+// factors are per byte of plaintext and err on the high side.
+const (
+	peakMemoryFactor   = 6
+	lockedMemoryFactor = 3
+)
+
+// memoryWarnPercent is the share of the memory budget above which loading warns
+const memoryWarnPercent = 80
+
+// loadMemoryEstimate estimates, from the size of the vault's ciphertext, the
+// peak memory decrypting it takes and how much of it is locked into RAM
+func loadMemoryEstimate(details config.VaultDetails) (peak, locked uint64, err error) {
+	f, err := os.Open(details.KeyFile)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+	head := make([]byte, len(ageArmorHeader))
+	n, _ := io.ReadFull(f, head)
+
+	plaintext := uint64(info.Size())
+	if string(head[:n]) == ageArmorHeader {
+		plaintext = plaintext * 3 / 4 // Base64
+	}
+	peak = plaintext * peakMemoryFactor
+	locked = plaintext * lockedMemoryFactor
+	if details.SecondFactor != "" {
+		// The inner ciphertext is held while its layer is decrypted
+		peak += plaintext
+		locked += plaintext
+	}
+	return peak, locked, nil
+}
+
+// checkLoadMemory refuses to decrypt a vault whose estimated memory is over
+// memory_budget_mb, rather than have the process killed midway, and warns
+// when it comes close or its secrets will not all fit in locked memory. The
+// strict profile refuses the latter too.
+func checkLoadMemory(details config.VaultDetails) error {
+	peak, locked, err := loadMemoryEstimate(details)
+	if err != nil {
+		return nil // Decryption reports an unreadable file
+	}
+	name := filepath.Base(details.KeyFile)
+
+	if config.Cfg.MemoryBudgetMB > 0 {
+		budget := uint64(config.Cfg.MemoryBudgetMB) << 20
+		switch {
+		case peak > budget:
+			audit.Logger.Error("Vault over memory budget",
+				slog.String("key_file", name),
+				slog.Uint64("estimated_kb", peak>>10),
+				slog.Int("budget_mb", config.Cfg.MemoryBudgetMB))
+			return errors.NewMemoryBudgetError(details.KeyFile, peak, budget)
+		case peak > budget/100*memoryWarnPercent:
+			audit.Logger.Warn("Vault close to memory budget",
+				slog.String("key_file", name),
+				slog.Uint64("estimated_kb", peak>>10),
+				slog.Int("budget_mb", config.Cfg.MemoryBudgetMB))
+			Warn(fmt.Sprintf("decrypting '%s' takes about %.1f MB of the %d MB memory budget. Move rarely used wallets to an archive vault with 'archive'.", name, float64(peak)/(1<<20), config.Cfg.MemoryBudgetMB))
+		}
+	}
+
+	if limit, ok := security.MemlockLimit(); ok && locked > limit {
+		audit.Logger.Warn("Vault secrets over locked memory limit",
+			slog.String("key_file", name),
+			slog.Uint64("estimated_kb", locked>>10),
+			slog.Uint64("memlock_kb", limit>>10),
+			slog.Bool("strict", config.Cfg.Strict))
+		if config.Cfg.Strict {
+			return errors.New(errors.ErrCodePermission, "the vault's secrets would not fit in locked memory").
+				WithDetails(fmt.Sprintf("about %d KB are needed and RLIMIT_MEMLOCK allows %d KB; raise it (ulimit -l) or move wallets to an archive vault with 'archive'", locked>>10, limit>>10)).
+				WithSeverity(errors.SeverityCritical)
+		}
+		Warn(fmt.Sprintf("the secrets of '%s' take about %d KB and RLIMIT_MEMLOCK allows %d KB: some may be swapped to disk. Raise it with ulimit -l.", name, locked>>10, limit>>10))
+	}
+	return nil
+}
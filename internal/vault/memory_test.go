@@ -0,0 +1,54 @@
+// File: internal/vault/memory_test.go
+package vault
+
+import (
+	stderrors "errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+)
+
+// TestCheckLoadMemory checks that a vault over the memory budget fails to load
+// with the reason shown to the user, and that one close to it warns through Warn
+func TestCheckLoadMemory(t *testing.T) {
+	audit.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	origBudget, origStrict, origWarn := config.Cfg.MemoryBudgetMB, config.Cfg.Strict, Warn
+	defer func() {
+		config.Cfg.MemoryBudgetMB, config.Cfg.Strict, Warn = origBudget, origStrict, origWarn
+	}()
+	config.Cfg.Strict = false
+	var warnings []string
+	Warn = func(message string) { warnings = append(warnings, message) }
+
+	// Decrypting 1 MB takes about 6 MB
+	keyFile := filepath.Join(t.TempDir(), "vault.key")
+	if err := os.WriteFile(keyFile, make([]byte, 1<<20), 0600); err != nil {
+		t.Fatal(err)
+	}
+	details := config.VaultDetails{KeyFile: keyFile}
+
+	config.Cfg.MemoryBudgetMB = 5
+	err := checkLoadMemory(details)
+	var vErr *errors.VaultError
+	if !stderrors.As(err, &vErr) || vErr.Code != errors.ErrCodeMemoryBudget {
+		t.Fatalf("checkLoadMemory() over budget = %v, want %s", err, errors.ErrCodeMemoryBudget)
+	}
+	if loadErr := errors.NewVaultLoadError(keyFile, err); !strings.Contains(loadErr.Details, "over the memory budget") {
+		t.Fatalf("the load error hides its cause: %v", loadErr)
+	}
+
+	config.Cfg.MemoryBudgetMB = 7
+	if err := checkLoadMemory(details); err != nil {
+		t.Fatalf("checkLoadMemory() close to budget = %v", err)
+	}
+	if len(warnings) == 0 || !strings.Contains(warnings[0], "memory budget") {
+		t.Fatalf("close to budget warned %q, want a memory budget warning", warnings)
+	}
+}
@@ -0,0 +1,70 @@
+// File: internal/vault/notes.go
+package vault
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/scrub"
+)
+
+// changedNotes returns the prefixes of the wallets whose notes were written
+// since the vault was loaded, new wallets included
+func changedNotes(keyFile string, v Vault) []string {
+	historiesMu.Lock()
+	loaded := histories[keyFile]
+	historiesMu.Unlock()
+
+	var prefixes []string
+	for prefix, w := range v {
+		if w.Notes == "" {
+			continue
+		}
+		if loaded != nil {
+			if before, ok := loaded.notes[prefix]; ok && before == sha256.Sum256([]byte(w.Notes)) {
+				continue
+			}
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+// checkNotesForSecrets looks for private keys and mnemonics pasted into notes
+// written since the vault was loaded. Notes are free text: they are shown by
+// 'list' and copied into public twins, without the protection of the secret
+// fields. The strict profile refuses the save; otherwise a warning
+// is printed. Notes written earlier are left to 'lint'.
+func checkNotesForSecrets(details config.VaultDetails, v Vault) error {
+	var leaks []string
+	for _, prefix := range changedNotes(details.KeyFile, v) {
+		findings := scrub.ScanText(v[prefix].Notes)
+		if len(findings) == 0 {
+			continue
+		}
+		kinds := make([]string, 0, len(findings))
+		for _, f := range findings {
+			kinds = append(kinds, fmt.Sprintf("%s (%s)", f.Kind, f.Preview))
+		}
+		audit.Logger.Warn("Secret in wallet notes",
+			slog.String("vault", config.NameForKeyFile(details.KeyFile)),
+			slog.String("prefix", prefix),
+			slog.Int("count", len(findings)),
+			slog.Bool("strict", config.Cfg.Strict))
+		leak := fmt.Sprintf("the notes of '%s' hold what looks like a %s", prefix, strings.Join(kinds, ", "))
+		Warn(leak + ". Notes are not protected like keys and 'list' shows them; keep the value in a secret ('secret add').")
+		leaks = append(leaks, leak)
+	}
+	if len(leaks) > 0 && config.Cfg.Strict {
+		return errors.New(errors.ErrCodePermission, "the strict profile refuses to save what looks like a secret in wallet notes").
+			WithDetails(strings.Join(leaks, "; "))
+	}
+	return nil
+}
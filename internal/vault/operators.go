@@ -0,0 +1,63 @@
+// File: internal/vault/operators.go
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+)
+
+// operatorBackend selects decryption with the current operator's own identity
+// instead of the vault's configured encryption backend
+const operatorBackend = "operator"
+
+// decryptingOperator returns the operator whose identity decrypts the vault, or nil
+// when the vault is not in organization mode or this machine has no operator set.
+// An operator who has not been granted access is refused before age is run.
+func decryptingOperator(details config.VaultDetails) (*config.Operator, error) {
+	if len(details.Operators) == 0 || config.Cfg.Operator == "" {
+		return nil, nil
+	}
+	op, ok := config.CurrentOperator()
+	if !ok {
+		return nil, errors.NewConfigValidationError("operator", config.Cfg.Operator, "operator is not defined in config.json")
+	}
+	if !details.HasOperator(op.Name) {
+		return nil, errors.Newf(errors.ErrCodePermission, "operator '%s' is not granted access to vault '%s'", op.Name, config.NameForKeyFile(details.KeyFile)).
+			WithDetails("ask an operator with access to run 'vault.module operators grant " + op.Name + "'")
+	}
+	if op.IdentityFile == "" {
+		return nil, errors.NewConfigMissingError("operators." + op.Name + ".identityfile").
+			WithDetails("set the identity file with 'vault.module operators add " + op.Name + " --identity-file <path>'")
+	}
+	return &op, nil
+}
+
+// operatorDecryptCommand builds the age invocation that decrypts the vault with the
+// operator's identity file. Plugin identities (YubiKey, FIDO2) are handled by age.
+func operatorDecryptCommand(ctx context.Context, details config.VaultDetails, op config.Operator) (*exec.Cmd, error) {
+	if _, err := exec.LookPath("age"); err != nil {
+		return nil, errors.NewDependencyError("age", "Please install it: https://github.com/FiloSottile/age")
+	}
+	if err := config.ValidateFilePath(op.IdentityFile, "identity file"); err != nil {
+		return nil, errors.NewVaultInvalidPathError(op.IdentityFile, err)
+	}
+	return exec.CommandContext(ctx, "age", "--decrypt", "-i", op.IdentityFile, details.KeyFile), nil
+}
+
+// operatorRecipientArgs returns the age arguments that add the recipients of the
+// operators granted access to the vault
+func operatorRecipientArgs(details config.VaultDetails) ([]string, error) {
+	var args []string
+	for _, name := range details.Operators {
+		op, ok := config.FindOperator(name)
+		if !ok {
+			return nil, errors.NewConfigValidationError("operators", name, fmt.Sprintf("vault grants access to operator '%s', who is not defined in config.json", name))
+		}
+		args = append(args, "-r", op.Recipient)
+	}
+	return args, nil
+}
@@ -0,0 +1,74 @@
+// File: internal/vault/passphrase.go
+package vault
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"vault.module/internal/errors"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// Passphrase layers (wallet envelopes and the passphrase second factor) are
+// encrypted in-process with age's scrypt recipient, so that the passphrase used
+// is the one whose strength was checked. The result is a standard age file that
+// the age CLI decrypts, prompting for the passphrase itself. The second factor
+// layer is decrypted in-process too, so that the passphrase that opened a vault
+// is the one its next save encrypts with.
+
+// encryptWithPassphrase encrypts plaintext to an age scrypt recipient,
+// ASCII-armored if armored is set
+func encryptWithPassphrase(plaintext []byte, passphrase string, armored bool) ([]byte, error) {
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrCodeInvalidInput, "the passphrase cannot be used", err)
+	}
+
+	var out bytes.Buffer
+	var dst io.Writer = &out
+	var armorWriter io.WriteCloser
+	if armored {
+		armorWriter = armor.NewWriter(&out)
+		dst = armorWriter
+	}
+	w, err := age.Encrypt(dst, recipient)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrCodeSystem, "passphrase encryption failed", err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, errors.Wrap(errors.ErrCodeSystem, "passphrase encryption failed", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(errors.ErrCodeSystem, "passphrase encryption failed", err)
+	}
+	if armorWriter != nil {
+		if err := armorWriter.Close(); err != nil {
+			return nil, errors.Wrap(errors.ErrCodeSystem, "passphrase encryption failed", err)
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// decryptWithPassphrase decrypts an age file encrypted to a scrypt recipient,
+// armored or not, and writes the plaintext to out
+func decryptWithPassphrase(ciphertext []byte, passphrase string, out io.Writer) error {
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return errors.Wrap(errors.ErrCodeInvalidInput, "the passphrase cannot be used", err)
+	}
+
+	src := bufio.NewReader(bytes.NewReader(ciphertext))
+	var in io.Reader = src
+	if start, _ := src.Peek(len(armor.Header)); string(start) == armor.Header {
+		in = armor.NewReader(src)
+	}
+	r, err := age.Decrypt(in, identity)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, r)
+	return err
+}
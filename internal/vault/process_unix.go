@@ -0,0 +1,40 @@
+//go:build unix
+// +build unix
+
+// File: internal/vault/process_unix.go
+package vault
+
+import (
+	"os"
+	"syscall"
+)
+
+// ttyDevice is the controlling terminal, for the prompts of age plugins
+const ttyDevice = "/dev/tty"
+
+// isProcessRunning checks if a process with given PID is still running
+// Uses more robust process existence checking with proper error handling
+func isProcessRunning(pid int) bool {
+	if pid <= 0 {
+		return false // Invalid PID
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	// On Unix systems, signal 0 can be used to check if process exists
+	// This is the standard way to check process existence without affecting it
+	err = process.Signal(syscall.Signal(0))
+	if err != nil {
+		// Check specific error types to distinguish between permission and non-existence
+		if errno, ok := err.(syscall.Errno); ok {
+			// ESRCH means no such process
+			// EPERM means process exists but we don't have permission to signal it
+			return errno != syscall.ESRCH
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,27 @@
+//go:build windows
+// +build windows
+
+// File: internal/vault/process_windows.go
+package vault
+
+import (
+	"os"
+)
+
+// ttyDevice is the console input, for the prompts of age plugins
+const ttyDevice = "CONIN$"
+
+// isProcessRunning checks if a process with given PID is still running.
+// Windows has no signal 0: finding the process opens a handle to it, which
+// fails once it has exited.
+func isProcessRunning(pid int) bool {
+	if pid <= 0 {
+		return false // Invalid PID
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
@@ -0,0 +1,84 @@
+//go:build darwin
+// +build darwin
+
+// File: internal/vault/secureenclave_darwin.go
+package vault
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/budget"
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/errors"
+)
+
+// secureEnclaveAccessControl requires Touch ID (or an enrolled Apple Watch) for every decrypt
+const secureEnclaveAccessControl = "any-biometry"
+
+// SecureEnclaveSupported reports whether the Secure Enclave backend is available on this platform
+func SecureEnclaveSupported() bool {
+	return true
+}
+
+// CreateSecureEnclaveIdentity generates a new P-256 key inside the Secure Enclave
+// and writes its age identity (an opaque reference to the enclave key, not the key
+// itself) to identityFile. The matching age recipient is returned.
+func CreateSecureEnclaveIdentity(identityFile string) (string, error) {
+	if _, err := exec.LookPath(constants.PluginSE); err != nil {
+		return "", errors.NewDependencyError(constants.PluginSE, "Please install it: https://github.com/remko/age-plugin-se")
+	}
+	if _, err := os.Stat(identityFile); err == nil {
+		return "", errors.NewInvalidInputError(identityFile, "identity file already exists")
+	}
+
+	ctx, cancel := budget.WithTimeout(yubikeyDecryptTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, constants.PluginSE, "keygen",
+		"--access-control="+secureEnclaveAccessControl, "-o", identityFile)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", errors.Wrap(errors.ErrCodeSystem, "failed to create Secure Enclave key", err).WithDetails(sanitizeLogOutput(stderr.String()))
+	}
+	if err := os.Chmod(identityFile, 0600); err != nil {
+		return "", errors.FromOSError(err, identityFile)
+	}
+
+	data, err := os.ReadFile(identityFile)
+	if err != nil {
+		return "", errors.FromOSError(err, identityFile)
+	}
+	recipient := parseAgeKeygenRecipient(data)
+	if recipient == "" {
+		return "", errors.New(errors.ErrCodeSystem, "Secure Enclave identity did not contain a public key")
+	}
+
+	audit.Logger.Info("Created Secure Enclave identity",
+		slog.String("identity_file", filepath.Base(identityFile)),
+		slog.String("access_control", secureEnclaveAccessControl))
+	return recipient, nil
+}
+
+// secureEnclaveDecryptCommand builds the age invocation for a Secure Enclave vault.
+// The plugin shows the Touch ID prompt itself.
+func secureEnclaveDecryptCommand(ctx context.Context, details config.VaultDetails) (*exec.Cmd, error) {
+	if _, err := exec.LookPath(constants.PluginSE); err != nil {
+		return nil, errors.NewDependencyError(constants.PluginSE, "Please install it: https://github.com/remko/age-plugin-se")
+	}
+	if _, err := exec.LookPath("age"); err != nil {
+		return nil, errors.NewDependencyError("age", "Please install it: https://github.com/FiloSottile/age")
+	}
+	if err := config.ValidateFilePath(details.IdentityFile, "identity file"); err != nil {
+		return nil, errors.NewVaultInvalidPathError(details.IdentityFile, err)
+	}
+
+	return exec.CommandContext(ctx, "age", "--decrypt", "-i", details.IdentityFile, details.KeyFile), nil
+}
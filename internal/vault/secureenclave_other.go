@@ -0,0 +1,33 @@
+//go:build !darwin
+// +build !darwin
+
+// File: internal/vault/secureenclave_other.go
+package vault
+
+import (
+	"context"
+	"os/exec"
+
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/errors"
+)
+
+// SecureEnclaveSupported reports whether the Secure Enclave backend is available on this platform
+func SecureEnclaveSupported() bool {
+	return false
+}
+
+// CreateSecureEnclaveIdentity is only available on macOS
+func CreateSecureEnclaveIdentity(identityFile string) (string, error) {
+	return "", errSecureEnclaveUnsupported()
+}
+
+// secureEnclaveDecryptCommand is only available on macOS
+func secureEnclaveDecryptCommand(ctx context.Context, details config.VaultDetails) (*exec.Cmd, error) {
+	return nil, errSecureEnclaveUnsupported()
+}
+
+func errSecureEnclaveUnsupported() error {
+	return errors.NewFormatInvalidError(constants.EncryptionSecureEnclave, "the Secure Enclave backend is only available on macOS")
+}
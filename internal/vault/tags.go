@@ -0,0 +1,26 @@
+// File: internal/vault/tags.go
+package vault
+
+import "sort"
+
+// HasTag reports whether the wallet carries tag
+func (w Wallet) HasTag(tag string) bool {
+	for _, t := range w.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// TaggedPrefixes returns the prefixes of the wallets in v carrying tag, sorted
+func (v Vault) TaggedPrefixes(tag string) []string {
+	var prefixes []string
+	for prefix, w := range v {
+		if w.HasTag(tag) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
@@ -0,0 +1,330 @@
+// File: internal/vault/tamper.go
+package vault
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"vault.module/internal/audit"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+)
+
+// ageArmorHeader starts every vault written by SaveVault
+const ageArmorHeader = "-----BEGIN AGE ENCRYPTED FILE-----"
+
+// TamperEvent describes an external modification of a vault's files
+type TamperEvent struct {
+	Path      string
+	Op        string
+	Integrity error // result of re-verifying the files after the change, nil if they still look sane
+}
+
+// TamperMarkerPath is the file that flags a vault as tampered until it is re-verified
+func TamperMarkerPath(details config.VaultDetails) string {
+	return details.KeyFile + ".tampered"
+}
+
+// IsMarkedTampered reports whether a tamper event is pending re-authentication
+func IsMarkedTampered(details config.VaultDetails) bool {
+	_, err := os.Stat(TamperMarkerPath(details))
+	return err == nil
+}
+
+// ClearTamperMarker removes the tamper flag after the vault was re-verified
+func ClearTamperMarker(details config.VaultDetails) error {
+	if err := os.Remove(TamperMarkerPath(details)); err != nil && !os.IsNotExist(err) {
+		return errors.FromOSError(err, TamperMarkerPath(details))
+	}
+	return nil
+}
+
+// VerifyFileIntegrity performs the structural checks that do not need the hardware key:
+// the keyfile is a regular owner-only age file and the recipients file only contains age recipients.
+func VerifyFileIntegrity(details config.VaultDetails) error {
+	info, err := os.Lstat(details.KeyFile)
+	if err != nil {
+		return errors.FromOSError(err, details.KeyFile)
+	}
+	if !info.Mode().IsRegular() {
+		return errors.NewVaultCorruptError(details.KeyFile, fmt.Errorf("keyfile is not a regular file"))
+	}
+	// Windows reports no owner-only modes: access is controlled by ACLs
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return errors.NewVaultCorruptError(details.KeyFile, fmt.Errorf("keyfile permissions are %o, expected 0600", info.Mode().Perm()))
+	}
+
+	f, err := os.Open(details.KeyFile)
+	if err != nil {
+		return errors.FromOSError(err, details.KeyFile)
+	}
+	defer f.Close()
+	header, _ := bufio.NewReader(f).ReadString('\n')
+	if strings.TrimSpace(header) != ageArmorHeader {
+		return errors.NewVaultCorruptError(details.KeyFile, fmt.Errorf("keyfile is not an armored age file"))
+	}
+
+	if details.RecipientsFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(details.RecipientsFile)
+	if err != nil {
+		return errors.FromOSError(err, details.RecipientsFile)
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, "age1") {
+			return errors.NewVaultCorruptError(details.RecipientsFile, fmt.Errorf("line %d is not an age recipient", i+1))
+		}
+	}
+	return nil
+}
+
+// SaveRecordPath is the file in which SaveVault records the keyfile it wrote
+func SaveRecordPath(details config.VaultDetails) string {
+	return details.KeyFile + ".saved"
+}
+
+// newSaveNonce returns the random nonce that ties a save's lock file to its save record
+func newSaveNonce() (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.Wrap(errors.ErrCodeSystem, "failed to generate save nonce", err)
+	}
+	return hex.EncodeToString(nonce), nil
+}
+
+// saveDigest is what the integrity key signs in a save record
+func saveDigest(nonce, keyFileHash string) []byte {
+	sum := sha256.Sum256([]byte("vault.module save " + nonce + " " + keyFileHash))
+	return sum[:]
+}
+
+// fileHash returns the hex SHA-256 of a file
+func fileHash(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.FromOSError(err, path)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// writeSaveRecord records the keyfile just written by the save holding nonce,
+// signed with the vault's integrity key. Vaults without one get no record, and
+// 'vaults watch' reports every change of their keyfile.
+func writeSaveRecord(details config.VaultDetails, nonce string) {
+	key := integrityKeyFor(details.KeyFile)
+	if key == nil {
+		if err := os.Remove(SaveRecordPath(details)); err != nil && !os.IsNotExist(err) {
+			audit.Logger.Warn("Failed to remove save record", slog.String("error", err.Error()))
+		}
+		return
+	}
+	hash, err := fileHash(details.KeyFile)
+	if err == nil {
+		var signature string
+		if signature, err = signDigest(key, saveDigest(nonce, hash)); err == nil {
+			err = os.WriteFile(SaveRecordPath(details), []byte(nonce+" "+hash+" "+signature+"\n"), 0600)
+		}
+	}
+	if err != nil {
+		audit.Logger.Warn("Failed to write save record",
+			slog.String("key_file", filepath.Base(details.KeyFile)),
+			slog.String("error", err.Error()))
+	}
+}
+
+// readSaveLock returns the nonce of the save holding lockPath, if the lock
+// names a running process
+func readSaveLock(lockPath string) (string, bool) {
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return "", false
+	}
+	pidStr, nonce, found := strings.Cut(strings.TrimSpace(string(data)), " ")
+	pid, err := strconv.Atoi(pidStr)
+	if !found || err != nil || nonce == "" || !isProcessRunning(pid) {
+		return "", false
+	}
+	return nonce, true
+}
+
+// checkSaveRecord verifies that the keyfile is the one the save holding nonce
+// wrote and signed with the integrity key
+func checkSaveRecord(details config.VaultDetails, key *IntegrityKey, nonce string) error {
+	data, err := os.ReadFile(SaveRecordPath(details))
+	if err != nil {
+		return errors.NewVaultCorruptError(details.KeyFile, fmt.Errorf("the keyfile changed while locked, and no save recorded it"))
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 3 || fields[0] != nonce {
+		return errors.NewVaultCorruptError(details.KeyFile, fmt.Errorf("the keyfile changed while locked, and the save record is for another save"))
+	}
+	hash, err := fileHash(details.KeyFile)
+	if err != nil {
+		return err
+	}
+	if hash != fields[1] {
+		return errors.NewVaultCorruptError(details.KeyFile, fmt.Errorf("the keyfile is not the one its last save wrote"))
+	}
+	valid, err := verifyDigest(key, saveDigest(nonce, hash), fields[2])
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return errors.NewVaultCorruptError(details.KeyFile, fmt.Errorf("the save record is not signed by the vault's integrity key"))
+	}
+	return nil
+}
+
+// saveTimeout bounds how long a keyfile change waits for the lock of its save
+// to be released before it is reported
+const saveTimeout = time.Minute
+
+// WatchVaultFiles watches the keyfile and recipients file of a vault until ctx is
+// cancelled and calls onTamper for every modification not made by SaveVault. Each
+// event re-verifies the files and leaves a tamper marker that blocks further
+// operations until the vault is re-authenticated.
+//
+// Changes of the keyfile while a save holds its lock are checked once the lock is
+// released, against the save record signed with the vault's integrity key, so the
+// vault must have been loaded in this process. Without an integrity key every
+// change is reported.
+func WatchVaultFiles(ctx context.Context, details config.VaultDetails, onTamper func(TamperEvent)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(errors.ErrCodeSystem, "failed to start file watcher", err)
+	}
+	defer watcher.Close()
+
+	// Watch directories so atomic replacements (rename over the file) are seen
+	watched := map[string]bool{}
+	for _, path := range []string{details.KeyFile, details.RecipientsFile} {
+		if path == "" {
+			continue
+		}
+		dir := filepath.Dir(path)
+		if watched[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			return errors.FromOSError(err, dir)
+		}
+		watched[dir] = true
+	}
+
+	report := func(tamper TamperEvent) {
+		marker := fmt.Sprintf("%s %s %s\n", time.Now().UTC().Format(time.RFC3339), tamper.Op, filepath.Base(tamper.Path))
+		if err := os.WriteFile(TamperMarkerPath(details), []byte(marker), 0600); err != nil {
+			audit.Logger.Error("Failed to write tamper marker", slog.String("error", err.Error()))
+		}
+
+		attrs := []any{
+			slog.String("severity", "CRITICAL"),
+			slog.String("file", filepath.Base(tamper.Path)),
+			slog.String("op", tamper.Op),
+		}
+		if tamper.Integrity != nil {
+			attrs = append(attrs, slog.String("integrity_error", tamper.Integrity.Error()))
+		}
+		audit.Logger.Error("CRITICAL: external modification of vault file detected", attrs...)
+
+		onTamper(tamper)
+	}
+
+	key := integrityKeyFor(details.KeyFile)
+	lockPath := details.KeyFile + ".lock"
+	saveNonce := ""         // nonce of the save holding the lock
+	keyFileChanged := false // the keyfile changed while the lock was held
+	var overdue <-chan time.Time
+	seenNonces := map[string]bool{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			audit.Logger.Warn("File watcher error", slog.String("error", err.Error()))
+		case <-overdue:
+			overdue = nil
+			if keyFileChanged {
+				keyFileChanged = false
+				report(TamperEvent{Path: details.KeyFile, Op: "WRITE", Integrity: errors.NewVaultCorruptError(details.KeyFile,
+					fmt.Errorf("the keyfile changed while its lock was held for over %s", saveTimeout))})
+			}
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			// SaveVault holds the lock file, naming its process and the save's nonce,
+			// while replacing the keyfile. Anyone can create a lock file, so changes
+			// made meanwhile are only accepted if the save record signed with the
+			// integrity key matches the keyfile once the lock is released.
+			if event.Name == lockPath {
+				switch {
+				case event.Has(fsnotify.Create), event.Has(fsnotify.Write):
+					if key == nil || saveNonce != "" {
+						continue
+					}
+					if nonce, ok := readSaveLock(lockPath); ok && !seenNonces[nonce] {
+						seenNonces[nonce] = true
+						saveNonce = nonce
+					}
+				case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+					nonce := saveNonce
+					saveNonce = ""
+					if nonce == "" || !keyFileChanged {
+						continue
+					}
+					keyFileChanged = false
+					overdue = nil
+					if err := checkSaveRecord(details, key, nonce); err != nil {
+						report(TamperEvent{Path: details.KeyFile, Op: "WRITE", Integrity: err})
+					}
+				}
+				continue
+			}
+
+			if event.Name != details.KeyFile && event.Name != details.RecipientsFile {
+				continue
+			}
+			if event.Name == details.KeyFile && saveNonce != "" {
+				if !keyFileChanged {
+					keyFileChanged = true
+					overdue = time.After(saveTimeout)
+				}
+				continue
+			}
+			if event.Op == fsnotify.Chmod && event.Name == details.KeyFile {
+				// Only the permissions changed; still verify below if they are wrong
+				if VerifyFileIntegrity(details) == nil {
+					continue
+				}
+			}
+
+			// Give a writer a moment to finish before re-verifying
+			time.Sleep(100 * time.Millisecond)
+			report(TamperEvent{Path: event.Name, Op: event.Op.String(), Integrity: VerifyFileIntegrity(details)})
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// File: internal/vault/tamper_test.go
+package vault
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"testing"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/config"
+	"vault.module/internal/security"
+)
+
+// TestSaveRecord checks that only a keyfile written by the save holding the
+// lock's nonce, and signed with the integrity key, passes for a save
+func TestSaveRecord(t *testing.T) {
+	audit.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	details := config.VaultDetails{KeyFile: t.TempDir() + "/vault.key"}
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &IntegrityKey{Seed: security.NewSecureString(hex.EncodeToString(priv.Seed())), Mode: IntegrityEnforce}
+	rememberIntegrityKey(details.KeyFile, key)
+	defer rememberIntegrityKey(details.KeyFile, nil)
+
+	if err := os.WriteFile(details.KeyFile, []byte(ageArmorHeader+"\nsaved\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	nonce, err := newSaveNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeSaveRecord(details, nonce)
+	if err := checkSaveRecord(details, key, nonce); err != nil {
+		t.Fatalf("checkSaveRecord() for the save = %v", err)
+	}
+
+	// A lock file made by someone else carries another nonce
+	if err := checkSaveRecord(details, key, "forged"); err == nil {
+		t.Fatal("checkSaveRecord() accepted a record of another save")
+	}
+
+	// The keyfile replaced after the save
+	if err := os.WriteFile(details.KeyFile, []byte(ageArmorHeader+"\nreplaced\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkSaveRecord(details, key, nonce); err == nil {
+		t.Fatal("checkSaveRecord() accepted a keyfile the save did not write")
+	}
+
+	// A record written without the integrity key
+	hash, err := fileHash(details.KeyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := nonce + " " + hash + " " + "c2lnbmF0dXJl\n"
+	if err := os.WriteFile(SaveRecordPath(details), []byte(record), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkSaveRecord(details, key, nonce); err == nil {
+		t.Fatal("checkSaveRecord() accepted a record not signed by the integrity key")
+	}
+}
+
+// TestReadSaveLock checks that a lock is only taken for a save of a running process
+func TestReadSaveLock(t *testing.T) {
+	lockPath := t.TempDir() + "/vault.key.lock"
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{name: "running process", content: strconv.Itoa(os.Getpid()) + " 00ff", want: true},
+		{name: "no nonce", content: strconv.Itoa(os.Getpid()), want: false},
+		{name: "not a PID", content: "x 00ff", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(lockPath, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if _, got := readSaveLock(lockPath); got != tt.want {
+				t.Fatalf("readSaveLock(%q) = %v, want %v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// File: internal/vault/touch.go
+package vault
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Touch policies reported by age-plugin-yubikey
+const (
+	TouchPolicyNever  = "never"
+	TouchPolicyAlways = "always"
+	TouchPolicyCached = "cached"
+)
+
+// RequiresTouch reports whether a slot with the given touch policy may block
+// waiting for a physical touch during decryption.
+func RequiresTouch(policy string) bool {
+	switch strings.ToLower(strings.TrimSpace(policy)) {
+	case TouchPolicyAlways, TouchPolicyCached:
+		return true
+	default:
+		return false
+	}
+}
+
+// touchIdentityPolicy extracts the touch policy from the identity emitted by
+// `age-plugin-yubikey --identity`. Returns an empty string if it is not annotated.
+func touchIdentityPolicy(identity []byte) string {
+	ids := parseYubiKeyList(string(identity))
+	if len(ids) == 0 {
+		return ""
+	}
+	return ids[0].TouchPolicy
+}
+
+// startTouchPrompt renders a "Touch your YubiKey now" spinner with a countdown on w
+// until the returned stop function is called or the deadline passes.
+func startTouchPrompt(w io.Writer, deadline time.Time) func() {
+	frames := []string{"|", "/", "-", "\\"}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(150 * time.Millisecond)
+		defer ticker.Stop()
+
+		for frame := 0; ; frame++ {
+			remaining := time.Until(deadline).Round(time.Second)
+			if remaining < 0 {
+				remaining = 0
+			}
+			fmt.Fprintf(w, "\r%s Touch your YubiKey now (%s remaining) ", frames[frame%len(frames)], remaining)
+
+			select {
+			case <-done:
+				// Clear the prompt line
+				fmt.Fprintf(w, "\r%s\r", strings.Repeat(" ", 48))
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
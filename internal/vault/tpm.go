@@ -0,0 +1,241 @@
+// File: internal/vault/tpm.go
+package vault
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/budget"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+)
+
+const (
+	// Names of the sealed object halves inside a vault's TPM seal directory
+	tpmSealedPublicFile  = "identity.pub"
+	tpmSealedPrivateFile = "identity.priv"
+
+	tpmCommandTimeout = 30 * time.Second
+)
+
+var pcrSelectionPattern = regexp.MustCompile(`^(sha1|sha256|sha384|sha512):[0-9]+(,[0-9]+)*$`)
+
+// ValidatePCRSelection checks a PCR selection such as "sha256:0,7". Empty disables the PCR policy.
+func ValidatePCRSelection(pcrs string) error {
+	if pcrs == "" {
+		return nil
+	}
+	if !pcrSelectionPattern.MatchString(pcrs) {
+		return fmt.Errorf("PCR selection must look like 'sha256:0,7'")
+	}
+	return nil
+}
+
+// DefaultTPMSealDir returns where the sealed identity of a vault is stored by default.
+func DefaultTPMSealDir(keyFile string) string {
+	return keyFile + ".tpm"
+}
+
+// SealTPMIdentity generates a fresh X25519 age identity, seals it to the TPM
+// (bound to the given PCR selection if non-empty) and writes the sealed blobs
+// into sealDir. The identity never touches disk in plaintext; the matching
+// age recipient is returned so it can be added to the recipients file.
+func SealTPMIdentity(sealDir, pcrs string) (string, error) {
+	for _, tool := range []string{"age-keygen", "tpm2_createprimary", "tpm2_create", "tpm2_createpolicy"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			return "", errors.NewDependencyError(tool, "Please install age and tpm2-tools")
+		}
+	}
+	if err := ValidatePCRSelection(pcrs); err != nil {
+		return "", errors.NewInvalidInputError(pcrs, err.Error())
+	}
+	if err := os.MkdirAll(sealDir, 0700); err != nil {
+		return "", errors.FromOSError(err, sealDir)
+	}
+
+	ctx, cancel := budget.WithTimeout(tpmCommandTimeout)
+	defer cancel()
+
+	// Generate the identity straight into a secure buffer
+	identity := createSecureBuffer("tpm_identity_keygen")
+	defer identity.Clear()
+
+	var stderr bytes.Buffer
+	keygen := exec.CommandContext(ctx, "age-keygen")
+	keygen.Stdout = &secureBufferWriter{buffer: identity}
+	keygen.Stderr = &stderr
+	if err := keygen.Run(); err != nil {
+		return "", errors.Wrap(errors.ErrCodeSystem, "failed to generate age identity", err).WithDetails(sanitizeLogOutput(stderr.String()))
+	}
+
+	var recipient string
+	err := identity.WithSecureOperation(func(data []byte) error {
+		recipient = parseAgeKeygenRecipient(data)
+		if recipient == "" {
+			return errors.New(errors.ErrCodeSystem, "age-keygen output did not contain a public key")
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	workDir, err := os.MkdirTemp("", "vault-tpm-*")
+	if err != nil {
+		return "", errors.NewFileSystemError("create", os.TempDir(), err)
+	}
+	defer os.RemoveAll(workDir)
+
+	primaryCtx := filepath.Join(workDir, "primary.ctx")
+	if err := runTPMTool(ctx, nil, "tpm2_createprimary", "-Q", "-C", "o", "-g", "sha256", "-G", "ecc", "-c", primaryCtx); err != nil {
+		return "", err
+	}
+
+	createArgs := []string{"-Q", "-C", primaryCtx, "-i", "-",
+		"-u", filepath.Join(sealDir, tpmSealedPublicFile),
+		"-r", filepath.Join(sealDir, tpmSealedPrivateFile)}
+	if pcrs != "" {
+		policyFile := filepath.Join(workDir, "pcr.policy")
+		if err := runTPMTool(ctx, nil, "tpm2_createpolicy", "-Q", "--policy-pcr", "-l", pcrs, "-L", policyFile); err != nil {
+			return "", err
+		}
+		// Without userwithauth the object can only be unsealed by satisfying the PCR policy
+		createArgs = append(createArgs, "-L", policyFile, "-a", "fixedtpm|fixedparent")
+	}
+
+	err = identity.WithSecureOperation(func(data []byte) error {
+		return runTPMTool(ctx, bytes.NewReader(data), "tpm2_create", createArgs...)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	audit.Logger.Info("Sealed age identity to TPM",
+		slog.String("seal_dir", filepath.Base(sealDir)),
+		slog.String("pcrs", pcrs))
+	return recipient, nil
+}
+
+// tpmUnsealIdentity unseals the vault's identity into a secure buffer.
+// The caller must Clear the returned buffer.
+func tpmUnsealIdentity(ctx context.Context, details config.VaultDetails) (*security.SecureString, error) {
+	for _, tool := range []string{"tpm2_createprimary", "tpm2_load", "tpm2_unseal"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			return nil, errors.NewDependencyError(tool, "Please install tpm2-tools")
+		}
+	}
+
+	sealDir := details.TPMSealDir
+	if sealDir == "" {
+		sealDir = DefaultTPMSealDir(details.KeyFile)
+	}
+	for _, name := range []string{tpmSealedPublicFile, tpmSealedPrivateFile} {
+		if _, err := os.Stat(filepath.Join(sealDir, name)); err != nil {
+			return nil, errors.FromOSError(err, filepath.Join(sealDir, name)).WithDetails("sealed TPM identity not found")
+		}
+	}
+
+	workDir, err := os.MkdirTemp("", "vault-tpm-*")
+	if err != nil {
+		return nil, errors.NewFileSystemError("create", os.TempDir(), err)
+	}
+	defer os.RemoveAll(workDir)
+
+	primaryCtx := filepath.Join(workDir, "primary.ctx")
+	sealCtx := filepath.Join(workDir, "seal.ctx")
+	if err := runTPMTool(ctx, nil, "tpm2_createprimary", "-Q", "-C", "o", "-g", "sha256", "-G", "ecc", "-c", primaryCtx); err != nil {
+		return nil, err
+	}
+	if err := runTPMTool(ctx, nil, "tpm2_load", "-Q", "-C", primaryCtx,
+		"-u", filepath.Join(sealDir, tpmSealedPublicFile),
+		"-r", filepath.Join(sealDir, tpmSealedPrivateFile),
+		"-c", sealCtx); err != nil {
+		return nil, err
+	}
+
+	unsealArgs := []string{"-c", sealCtx}
+	if details.TPMPCRs != "" {
+		unsealArgs = append(unsealArgs, "-p", "pcr:"+details.TPMPCRs)
+	}
+
+	identity := createSecureBuffer("tpm_unsealed_identity")
+	var stderr bytes.Buffer
+	unseal := exec.CommandContext(ctx, "tpm2_unseal", unsealArgs...)
+	unseal.Stdout = &secureBufferWriter{buffer: identity}
+	unseal.Stderr = &stderr
+	if err := unseal.Run(); err != nil {
+		identity.Clear()
+		audit.Logger.Error("Failed to unseal TPM identity",
+			slog.String("key_file", filepath.Base(details.KeyFile)),
+			slog.String("stderr", sanitizeLogOutput(stderr.String())))
+		return nil, errors.NewAuthFailedError("TPM refused to unseal the vault identity (PCR policy mismatch or different machine)")
+	}
+	return identity, nil
+}
+
+// runTPMTool runs a tpm2-tools command and converts failures into VaultErrors
+func runTPMTool(ctx context.Context, stdin *bytes.Reader, tool string, args ...string) error {
+	cmd := exec.CommandContext(ctx, tool, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(errors.ErrCodeSystem, fmt.Sprintf("%s failed", tool), err).
+			WithDetails(sanitizeLogOutput(stderr.String())).
+			WithContext("tool", tool)
+	}
+	return nil
+}
+
+// parseAgeKeygenRecipient extracts the "# public key: age1..." line from age-keygen output
+func parseAgeKeygenRecipient(output []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "# public key:"); ok {
+			return strings.TrimSpace(rest)
+		}
+	}
+	return ""
+}
+
+// tpmDecryptCommand builds the age invocation for a TPM vault. The identity is
+// unsealed into a secure buffer and fed to age over stdin; the returned cleanup
+// function clears it and must be called once age has finished.
+func tpmDecryptCommand(ctx context.Context, details config.VaultDetails) (*exec.Cmd, func(), error) {
+	if _, err := exec.LookPath("age"); err != nil {
+		return nil, nil, errors.NewDependencyError("age", "Please install it: https://github.com/FiloSottile/age")
+	}
+
+	identity, err := tpmUnsealIdentity(ctx, details)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var stdin []byte
+	_ = identity.WithSecureOperation(func(data []byte) error {
+		stdin = append([]byte(nil), data...)
+		return nil
+	})
+	cleanup := func() {
+		security.SecureZero(stdin)
+		identity.Clear()
+	}
+
+	ageCmd := exec.CommandContext(ctx, "age", "--decrypt", "-i", "-", details.KeyFile)
+	ageCmd.Stdin = bytes.NewReader(stdin)
+	return ageCmd, cleanup, nil
+}
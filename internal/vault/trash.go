@@ -0,0 +1,175 @@
+// File: internal/vault/trash.go
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"vault.module/internal/config"
+)
+
+// TrashDir holds deleted vaults until they are restored or purged. Each one is
+// a directory named <vault>-<UTC time> with its files, still encrypted as they
+// were, and trash.json describing it.
+const TrashDir = "vault-trash"
+
+// trashSidecars are the files next to a key file that belong to the vault
+var trashSidecars = []string{".se-identity", ".policy.txt", ".tampered"}
+
+// TrashedFile is a file of a deleted vault and where it came from.
+type TrashedFile struct {
+	Original string `json:"original"` // Path the file is restored to
+	Stored   string `json:"stored"`   // Base name inside the trash entry
+}
+
+// TrashEntry is a deleted vault: its config.json entry and its files.
+type TrashEntry struct {
+	Name      string              `json:"name"`
+	DeletedAt string              `json:"deleted_at"` // RFC 3339, UTC
+	Details   config.VaultDetails `json:"details"`
+	Files     []TrashedFile       `json:"files"`
+	Dir       string              `json:"-"`
+}
+
+// MoveToTrash moves the key file of a vault, and the files kept next to it,
+// into a new trash entry. Files that do not exist are skipped.
+func MoveToTrash(name string, details config.VaultDetails) (TrashEntry, error) {
+	now := time.Now().UTC()
+	entry := TrashEntry{
+		Name:      name,
+		DeletedAt: now.Format(time.RFC3339),
+		Details:   details,
+		Dir:       filepath.Join(TrashDir, name+"-"+now.Format("20060102T150405Z")),
+	}
+	if err := os.MkdirAll(entry.Dir, 0700); err != nil {
+		return entry, err
+	}
+
+	paths := []string{details.KeyFile}
+	for _, suffix := range trashSidecars {
+		paths = append(paths, details.KeyFile+suffix)
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return entry, err
+		}
+		stored := filepath.Base(path)
+		if err := moveFile(path, filepath.Join(entry.Dir, stored)); err != nil {
+			return entry, err
+		}
+		entry.Files = append(entry.Files, TrashedFile{Original: abs, Stored: stored})
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return entry, err
+	}
+	return entry, os.WriteFile(filepath.Join(entry.Dir, "trash.json"), data, 0600)
+}
+
+// ListTrash returns the deleted vaults, oldest first.
+func ListTrash() ([]TrashEntry, error) {
+	dirs, err := os.ReadDir(TrashDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []TrashEntry
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		dir := filepath.Join(TrashDir, d.Name())
+		data, err := os.ReadFile(filepath.Join(dir, "trash.json"))
+		if err != nil {
+			continue
+		}
+		var entry TrashEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
+		}
+		entry.Dir = dir
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].DeletedAt < entries[j].DeletedAt })
+	return entries, nil
+}
+
+// FindTrash returns the most recently deleted vault with the given name.
+func FindTrash(name string) (TrashEntry, bool, error) {
+	entries, err := ListTrash()
+	if err != nil {
+		return TrashEntry{}, false, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Name == name {
+			return entries[i], true, nil
+		}
+	}
+	return TrashEntry{}, false, nil
+}
+
+// RestoreFromTrash moves the files of a deleted vault back where they were and
+// removes the trash entry. It refuses to overwrite files that exist again.
+func RestoreFromTrash(entry TrashEntry) error {
+	for _, f := range entry.Files {
+		if _, err := os.Stat(f.Original); err == nil {
+			return fmt.Errorf("%s exists; move it away before restoring", f.Original)
+		}
+	}
+	for _, f := range entry.Files {
+		if err := os.MkdirAll(filepath.Dir(f.Original), 0700); err != nil {
+			return err
+		}
+		if err := moveFile(filepath.Join(entry.Dir, f.Stored), f.Original); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(entry.Dir)
+}
+
+// PurgeTrash removes a trash entry and its files for good.
+func PurgeTrash(entry TrashEntry) error {
+	return os.RemoveAll(entry.Dir)
+}
+
+// moveFile renames src to dst, copying across file systems
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
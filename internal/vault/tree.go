@@ -0,0 +1,237 @@
+// File: internal/vault/tree.go
+package vault
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Key status of an address in a derivation tree
+const (
+	KeyHeld      = "key"        // The private key is in the vault
+	KeySealed    = "sealed"     // The private key is in the wallet's envelope
+	KeyWatchOnly = "watch-only" // No private key: the address can only be watched
+)
+
+// coinTypeNames names the SLIP-44 coin types of the chains vault.module derives or scans
+var coinTypeNames = map[int]string{
+	0:   "Bitcoin",
+	1:   "testnets",
+	60:  "Ethereum",
+	61:  "Ethereum Classic",
+	118: "Cosmos",
+	330: "Terra",
+	394: "Crypto.org",
+	459: "Kava",
+	529: "Secret Network",
+}
+
+// DerivationNode is a level of a wallet's derivation tree. Leaves are addresses.
+type DerivationNode struct {
+	Segment   string            `json:"segment"` // e.g. "60'"
+	Path      string            `json:"path"`    // e.g. "m/44'/60'"
+	Label     string            `json:"label"`   // What the level is, e.g. "coin type: Ethereum"
+	Address   string            `json:"address,omitempty"`
+	Account   int               `json:"account,omitempty"`
+	Index     int               `json:"index,omitempty"`
+	KeyStatus string            `json:"key_status,omitempty"` // Of addresses, see the Key* statuses
+	Children  []*DerivationNode `json:"children,omitempty"`
+}
+
+// IsAddress reports whether the node is an address of the wallet
+func (n *DerivationNode) IsAddress() bool {
+	return n.Address != ""
+}
+
+// AddressCount returns the number of addresses at and below the node
+func (n *DerivationNode) AddressCount() int {
+	count := 0
+	if n.IsAddress() {
+		count++
+	}
+	for _, child := range n.Children {
+		count += child.AddressCount()
+	}
+	return count
+}
+
+// KeyStatus tells whether the vault holds the key of an address of the wallet
+func (w Wallet) KeyStatus(addr Address) string {
+	switch {
+	case w.Kind == KindWatch:
+		return KeyWatchOnly
+	case addr.PrivateKey != nil && !addr.PrivateKey.IsEmpty():
+		return KeyHeld
+	case w.IsHD():
+		return KeyHeld // Derived from the seed when needed
+	case w.Sealed():
+		return KeySealed
+	}
+	return KeyWatchOnly
+}
+
+// DerivationTree arranges the wallet's addresses by the levels of their
+// derivation paths, from m. Addresses without a path, such as imported
+// private keys, are gathered under a node of their own.
+func (w Wallet) DerivationTree() *DerivationNode {
+	root := &DerivationNode{Segment: "m", Path: "m", Label: "master key"}
+	var imported *DerivationNode
+	for _, addr := range w.Addresses {
+		segments := strings.Split(strings.TrimPrefix(addr.Path, "m/"), "/")
+		if addr.Path == "" || !strings.HasPrefix(addr.Path, "m/") {
+			if imported == nil {
+				imported = &DerivationNode{Label: "keys without derivation path"}
+			}
+			imported.Children = append(imported.Children, w.addressNode(addr, "", addr.Path))
+			continue
+		}
+		node := root
+		for depth, segment := range segments[:len(segments)-1] {
+			node = node.child(segment, depth+1)
+		}
+		node.Children = append(node.Children, w.addressNode(addr, segments[len(segments)-1], addr.Path))
+	}
+	root.sortChildren()
+	root.labelChains()
+	if len(root.Children) == 0 && imported != nil {
+		return imported
+	}
+	if imported != nil {
+		root.Children = append(root.Children, imported)
+	}
+	return root
+}
+
+// addressNode is the leaf of an address
+func (w Wallet) addressNode(addr Address, segment, path string) *DerivationNode {
+	return &DerivationNode{
+		Segment:   segment,
+		Path:      path,
+		Label:     fmt.Sprintf("address %d", addr.Index),
+		Address:   addr.Address,
+		Account:   addr.Account,
+		Index:     addr.Index,
+		KeyStatus: w.KeyStatus(addr),
+	}
+}
+
+// child returns the level below n with the given segment, creating it
+func (n *DerivationNode) child(segment string, depth int) *DerivationNode {
+	for _, c := range n.Children {
+		if c.Segment == segment && !c.IsAddress() {
+			return c
+		}
+	}
+	c := &DerivationNode{Segment: segment, Path: n.Path + "/" + segment, Label: levelLabel(segment, depth)}
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// levelLabel names a level above the addresses by its BIP-44 role
+func levelLabel(segment string, depth int) string {
+	number, hardened := parseSegment(segment)
+	switch {
+	case depth == 1 && hardened:
+		switch number {
+		case 44, 49, 84, 86:
+			return fmt.Sprintf("purpose: BIP-%d", number)
+		}
+		return fmt.Sprintf("purpose %d", number)
+	case depth == 2 && hardened:
+		if name, ok := coinTypeNames[number]; ok {
+			return "coin type: " + name
+		}
+		return fmt.Sprintf("coin type %d", number)
+	case depth == 3 && hardened:
+		return fmt.Sprintf("account %d", number)
+	}
+	return "level " + segment
+}
+
+// labelChains names the BIP-44 change levels: the non-hardened level just
+// above addresses, below an account
+func (n *DerivationNode) labelChains() {
+	for _, c := range n.Children {
+		if c.IsAddress() {
+			continue
+		}
+		if strings.Count(c.Path, "/") == 4 && c.hasOnlyAddresses() {
+			switch number, hardened := parseSegment(c.Segment); {
+			case !hardened && number == 0:
+				c.Label = "external chain"
+			case !hardened && number == 1:
+				c.Label = "change chain"
+			}
+		}
+		c.labelChains()
+	}
+}
+
+func (n *DerivationNode) hasOnlyAddresses() bool {
+	for _, c := range n.Children {
+		if !c.IsAddress() {
+			return false
+		}
+	}
+	return len(n.Children) > 0
+}
+
+// sortChildren orders every level by number, levels before addresses
+func (n *DerivationNode) sortChildren() {
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		if a.IsAddress() != b.IsAddress() {
+			return !a.IsAddress()
+		}
+		na, _ := parseSegment(a.Segment)
+		nb, _ := parseSegment(b.Segment)
+		return na < nb
+	})
+	for _, c := range n.Children {
+		c.sortChildren()
+	}
+}
+
+// IndexRanges summarizes the indices of the addresses right below n, e.g.
+// "0-4, 7": gaps show addresses that were skipped or deleted
+func (n *DerivationNode) IndexRanges() string {
+	var numbers []int
+	for _, c := range n.Children {
+		if c.IsAddress() && c.Segment != "" {
+			number, _ := parseSegment(c.Segment)
+			numbers = append(numbers, number)
+		}
+	}
+	if len(numbers) == 0 {
+		return ""
+	}
+	sort.Ints(numbers)
+	var ranges []string
+	start := numbers[0]
+	for i := 1; i <= len(numbers); i++ {
+		if i < len(numbers) && numbers[i] <= numbers[i-1]+1 {
+			continue
+		}
+		if end := numbers[i-1]; end == start {
+			ranges = append(ranges, strconv.Itoa(start))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", start, end))
+		}
+		if i < len(numbers) {
+			start = numbers[i]
+		}
+	}
+	return strings.Join(ranges, ", ")
+}
+
+// parseSegment returns the number of a path segment and whether it is hardened
+func parseSegment(segment string) (int, bool) {
+	hardened := strings.HasSuffix(segment, "'") || strings.HasSuffix(segment, "h")
+	number, err := strconv.Atoi(strings.TrimRight(segment, "'h"))
+	if err != nil {
+		return -1, hardened
+	}
+	return number, hardened
+}
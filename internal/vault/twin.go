@@ -0,0 +1,88 @@
+// File: internal/vault/twin.go
+package vault
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+)
+
+// A cold vault can keep a public twin: a second configured vault holding only
+// the public data of its wallets (addresses, xpubs, notes) so that day-to-day
+// lookups never need the cold vault's key. The twin is regenerated on every
+// save of the cold vault and carries the cold vault's history.
+
+// XPubFor returns the account-level xpub published for an HD wallet. It is set
+// by the keys package, which depends on this one.
+var XPubFor func(w Wallet) (string, error)
+
+// PublicWallet returns the public data of the wallet: no mnemonic, secret,
+// private keys or envelope, and the account xpub of HD wallets
+func PublicWallet(w Wallet) (Wallet, error) {
+	public := w.withoutSecrets()
+	public.Envelope = ""
+	if XPubFor != nil && !w.Sealed() {
+		xpub, err := XPubFor(w)
+		if err != nil {
+			return Wallet{}, err
+		}
+		public.XPub = xpub
+	}
+	return public, nil
+}
+
+// SyncPublicTwin rewrites the public twin of the vault from v
+func SyncPublicTwin(details config.VaultDetails, v Vault) error {
+	twinName := details.PublicTwin
+	twin, ok := config.Cfg.Vaults[twinName]
+	if !ok {
+		return errors.NewVaultNotFoundError(twinName)
+	}
+	if twin.KeyFile == details.KeyFile || twin.PublicTwin != "" {
+		return errors.NewConfigValidationError("public_twin", twinName, "a public twin must be a separate vault without a twin of its own")
+	}
+
+	public := make(Vault, len(v))
+	for prefix, w := range v {
+		pw, err := PublicWallet(w)
+		if err != nil {
+			return errors.NewWalletInvalidError(prefix, fmt.Sprintf("cannot derive the public key: %v", err))
+		}
+		public[prefix] = pw
+	}
+
+	var history []HistoryEntry
+	historiesMu.Lock()
+	if loaded := histories[details.KeyFile]; loaded != nil {
+		history = loaded.entries
+	}
+	historiesMu.Unlock()
+
+	if err := writeVault(twin, public, func(Vault) []HistoryEntry { return history }); err != nil {
+		return err
+	}
+	audit.Logger.Info("Public twin updated",
+		slog.String("key_file", filepath.Base(details.KeyFile)),
+		slog.String("twin", twinName),
+		slog.Int("wallet_count", len(public)))
+	return nil
+}
+
+// syncPublicTwinAfterSave keeps the twin in step with a save of the cold vault. The
+// cold vault is already saved, so a failure is reported without failing the save.
+func syncPublicTwinAfterSave(details config.VaultDetails, v Vault) {
+	if details.PublicTwin == "" {
+		return
+	}
+	if err := SyncPublicTwin(details, v); err != nil {
+		audit.Logger.Error("Failed to update public twin",
+			slog.String("key_file", filepath.Base(details.KeyFile)),
+			slog.String("twin", details.PublicTwin),
+			slog.String("error", err.Error()))
+		Warn(fmt.Sprintf("public twin '%s' was not updated: %v", details.PublicTwin, err))
+	}
+}
@@ -0,0 +1,190 @@
+// File: internal/vault/twofactor.go
+package vault
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/errors"
+	"vault.module/internal/security"
+)
+
+// Vaults with a second factor are encrypted twice: the serialized vault is first
+// encrypted with the second factor (a local age keyfile or a passphrase), and the
+// result is then encrypted with the vault's primary backend. Both factors are
+// needed to recover the plaintext.
+
+// GenerateSecondFactorKeyfile creates a new age identity file for the keyfile second factor
+func GenerateSecondFactorKeyfile(path string) error {
+	if _, err := exec.LookPath("age-keygen"); err != nil {
+		return errors.NewDependencyError("age-keygen", "Please install it: https://github.com/FiloSottile/age")
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("age-keygen", "-o", path)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(errors.ErrCodeSystem, "failed to generate second factor keyfile", err).WithDetails(sanitizeLogOutput(stderr.String()))
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		return errors.FromOSError(err, path)
+	}
+
+	audit.Logger.Info("Generated second factor keyfile", slog.String("file", filepath.Base(path)))
+	return nil
+}
+
+// OpenSecondFactorPassphrase asks for the passphrase that opens a vault's
+// passphrase second factor. It is set by the cmd package.
+var OpenSecondFactorPassphrase func(details config.VaultDetails) (string, error)
+
+// secondFactorPassphrases carries the passphrase that opened each vault loaded
+// in this process to SaveVault, which encrypts the vault with it again instead
+// of asking for it on every save. New passphrases are set only by 'vaults add'
+// and 'vaults passphrase'.
+var (
+	secondFactorPassphrases   = map[string]*security.SecureString{}
+	secondFactorPassphrasesMu sync.Mutex
+)
+
+func rememberSecondFactorPassphrase(keyFile, passphrase string) {
+	secondFactorPassphrasesMu.Lock()
+	defer secondFactorPassphrasesMu.Unlock()
+	if old := secondFactorPassphrases[keyFile]; old != nil {
+		old.Clear()
+	}
+	secondFactorPassphrases[keyFile] = security.NewSecureStringWithRegistration(passphrase, "second factor passphrase of "+filepath.Base(keyFile))
+}
+
+// SetSecondFactorPassphrase sets the passphrase the next save of keyFile
+// encrypts its passphrase second factor with. The caller checks its strength.
+func SetSecondFactorPassphrase(keyFile, passphrase string) {
+	rememberSecondFactorPassphrase(keyFile, passphrase)
+}
+
+// ShareSecondFactorPassphrase lets a new vault file made from a vault opened in
+// this process, such as a clone or an archive, be saved with its passphrase
+func ShareSecondFactorPassphrase(fromKeyFile, toKeyFile string) {
+	secondFactorPassphrasesMu.Lock()
+	known := secondFactorPassphrases[fromKeyFile]
+	secondFactorPassphrasesMu.Unlock()
+	if known == nil {
+		return
+	}
+	rememberSecondFactorPassphrase(toKeyFile, known.String())
+}
+
+func secondFactorPassphraseFor(keyFile string) *security.SecureString {
+	secondFactorPassphrasesMu.Lock()
+	defer secondFactorPassphrasesMu.Unlock()
+	return secondFactorPassphrases[keyFile]
+}
+
+// secondFactorArgs returns the age arguments selecting the second factor
+func secondFactorArgs(details config.VaultDetails) ([]string, error) {
+	switch details.SecondFactor {
+	case constants.SecondFactorKeyfile:
+		if err := config.ValidateFilePath(details.SecondFactorFile, "second factor file"); err != nil {
+			return nil, errors.NewVaultInvalidPathError(details.SecondFactorFile, err)
+		}
+		return []string{"-i", details.SecondFactorFile}, nil
+	case constants.SecondFactorPassphrase:
+		// The passphrase layer is encrypted and decrypted in-process
+		return nil, nil
+	default:
+		return nil, errors.NewFormatInvalidError(details.SecondFactor, "unknown second factor")
+	}
+}
+
+// encryptSecondFactor applies the inner encryption layer to the serialized vault
+func encryptSecondFactor(ctx context.Context, details config.VaultDetails, plaintext []byte) ([]byte, error) {
+	factorArgs, err := secondFactorArgs(details)
+	if err != nil {
+		return nil, err
+	}
+	if details.SecondFactor == constants.SecondFactorPassphrase {
+		// The passphrase that opened the vault encrypts it again
+		known := secondFactorPassphraseFor(details.KeyFile)
+		if known == nil {
+			return nil, errors.NewVaultSaveError(details.KeyFile, nil).
+				WithDetails("the second factor passphrase is not known: open the vault before saving it")
+		}
+		var encrypted []byte
+		err := known.WithSecureOperation(func(passphrase []byte) error {
+			var encErr error
+			encrypted, encErr = encryptWithPassphrase(plaintext, string(passphrase), false)
+			return encErr
+		})
+		return encrypted, err
+	}
+
+	var out, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "age", append([]string{"--encrypt"}, factorArgs...)...)
+	cmd.Stdin = bytes.NewReader(plaintext)
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.NewVaultSaveError(details.KeyFile, err).
+			WithDetails("second factor encryption failed: " + sanitizeLogOutput(stderr.String()))
+	}
+	return out.Bytes(), nil
+}
+
+// decryptSecondFactor removes the inner encryption layer. The caller must Clear the returned buffer.
+func decryptSecondFactor(ctx context.Context, details config.VaultDetails, ciphertext []byte) (*security.SecureString, error) {
+	factorArgs, err := secondFactorArgs(details)
+	if err != nil {
+		return nil, err
+	}
+	if details.SecondFactor == constants.SecondFactorPassphrase {
+		return decryptPassphraseSecondFactor(details, ciphertext)
+	}
+
+	plaintext := createSecureBuffer("vault_second_factor_buffer")
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "age", append([]string{"--decrypt"}, factorArgs...)...)
+	cmd.Stdin = bytes.NewReader(ciphertext)
+	cmd.Stdout = &secureBufferWriter{buffer: plaintext}
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		plaintext.Clear()
+		audit.Logger.Error("Second factor decryption failed",
+			slog.String("key_file", filepath.Base(details.KeyFile)),
+			slog.String("second_factor", details.SecondFactor),
+			slog.String("stderr", sanitizeLogOutput(stderr.String())))
+		return nil, errors.NewAuthFailedError("second factor rejected (wrong keyfile or passphrase)")
+	}
+	return plaintext, nil
+}
+
+// decryptPassphraseSecondFactor asks for the passphrase and removes the inner
+// layer with it, remembering the passphrase for the next save
+func decryptPassphraseSecondFactor(details config.VaultDetails, ciphertext []byte) (*security.SecureString, error) {
+	if OpenSecondFactorPassphrase == nil {
+		return nil, errors.New(errors.ErrCodeInternal, "no prompt for the second factor passphrase")
+	}
+	passphrase, err := OpenSecondFactorPassphrase(details)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := createSecureBuffer("vault_second_factor_buffer")
+	if err := decryptWithPassphrase(ciphertext, passphrase, &secureBufferWriter{buffer: plaintext}); err != nil {
+		plaintext.Clear()
+		audit.Logger.Error("Second factor decryption failed",
+			slog.String("key_file", filepath.Base(details.KeyFile)),
+			slog.String("second_factor", details.SecondFactor),
+			slog.String("error", err.Error()))
+		return nil, errors.NewAuthFailedError("second factor rejected (wrong keyfile or passphrase)")
+	}
+	rememberSecondFactorPassphrase(details.KeyFile, passphrase)
+	return plaintext, nil
+}
@@ -0,0 +1,100 @@
+// File: internal/vault/twofactor_test.go
+package vault
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/config"
+	"vault.module/internal/constants"
+	"vault.module/internal/errors"
+)
+
+// forgetSecondFactorPassphrase drops what a vault loaded earlier left behind,
+// as a new process starts without it
+func forgetSecondFactorPassphrase(keyFile string) {
+	secondFactorPassphrasesMu.Lock()
+	defer secondFactorPassphrasesMu.Unlock()
+	delete(secondFactorPassphrases, keyFile)
+}
+
+// TestPassphraseSecondFactorReuse checks that a vault opened with its second
+// factor passphrase is saved with that passphrase, and that a vault whose
+// passphrase is not known is not saved at all
+func TestPassphraseSecondFactorReuse(t *testing.T) {
+	audit.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	const passphrase = "trombone gravel lantern oyster"
+	details := config.VaultDetails{KeyFile: t.TempDir() + "/vault.key", SecondFactor: constants.SecondFactorPassphrase}
+	plaintext := []byte(`{"version":1,"data":{}}`)
+
+	origOpen := OpenSecondFactorPassphrase
+	defer func() {
+		OpenSecondFactorPassphrase = origOpen
+		forgetSecondFactorPassphrase(details.KeyFile)
+	}()
+
+	// Without a passphrase set by 'vaults add' or opening the vault, nothing is saved
+	_, err := encryptSecondFactor(context.Background(), details, plaintext)
+	var vaultErr *errors.VaultError
+	if !stderrors.As(err, &vaultErr) || vaultErr.Code != errors.ErrCodeVaultSave {
+		t.Fatalf("encryptSecondFactor() without a passphrase = %v, want %s", err, errors.ErrCodeVaultSave)
+	}
+
+	SetSecondFactorPassphrase(details.KeyFile, passphrase)
+	ciphertext, err := encryptSecondFactor(context.Background(), details, plaintext)
+	if err != nil {
+		t.Fatalf("encryptSecondFactor() error = %v", err)
+	}
+
+	// Another run opens it, and saves it with the same passphrase
+	forgetSecondFactorPassphrase(details.KeyFile)
+	OpenSecondFactorPassphrase = func(config.VaultDetails) (string, error) { return passphrase, nil }
+	opened, err := decryptSecondFactor(context.Background(), details, ciphertext)
+	if err != nil {
+		t.Fatalf("decryptSecondFactor() error = %v", err)
+	}
+	if got := opened.String(); got != string(plaintext) {
+		t.Fatalf("decryptSecondFactor() = %q, want %q", got, plaintext)
+	}
+	opened.Clear()
+
+	resaved, err := encryptSecondFactor(context.Background(), details, plaintext)
+	if err != nil {
+		t.Fatalf("encryptSecondFactor() error = %v", err)
+	}
+	var out bytes.Buffer
+	if err := decryptWithPassphrase(resaved, passphrase, &out); err != nil {
+		t.Fatalf("the re-saved vault does not open with its passphrase: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), plaintext) {
+		t.Fatalf("the re-saved vault holds %q, want %q", out.Bytes(), plaintext)
+	}
+
+	// A wrong passphrase is refused
+	OpenSecondFactorPassphrase = func(config.VaultDetails) (string, error) { return "not the passphrase", nil }
+	_, err = decryptSecondFactor(context.Background(), details, resaved)
+	if !stderrors.As(err, &vaultErr) || vaultErr.Code != errors.ErrCodeAuthFailed {
+		t.Fatalf("decryptSecondFactor() with a wrong passphrase = %v, want %s", err, errors.ErrCodeAuthFailed)
+	}
+}
+
+// TestDecryptWithPassphraseArmored checks that armored passphrase files, as
+// the age CLI writes with -a, decrypt too
+func TestDecryptWithPassphraseArmored(t *testing.T) {
+	ciphertext, err := encryptWithPassphrase([]byte("secret"), "trombone gravel lantern oyster", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := decryptWithPassphrase(ciphertext, "trombone gravel lantern oyster", &out); err != nil {
+		t.Fatalf("decryptWithPassphrase() error = %v", err)
+	}
+	if out.String() != "secret" {
+		t.Fatalf("decryptWithPassphrase() = %q, want %q", out.String(), "secret")
+	}
+}
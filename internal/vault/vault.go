@@ -2,31 +2,42 @@ package vault
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
-	"golang.org/x/sys/unix"
 	"golang.org/x/term"
+	"vault.module/internal/approval"
 	"vault.module/internal/audit"
+	"vault.module/internal/budget"
 	"vault.module/internal/config"
 	"vault.module/internal/constants"
 	"vault.module/internal/errors"
+	"vault.module/internal/filelock"
+	"vault.module/internal/hooks"
 	"vault.module/internal/security"
+	"vault.module/internal/tasks"
 )
 
 const (
 	CurrentVaultVersion = 1
+
+	// yubikeyDecryptTimeout bounds the plugin and age invocations during decryption,
+	// including the time the user has to touch the key
+	yubikeyDecryptTimeout = 30 * time.Second
 )
 
+// Warn shows the user a warning that does not stop the operation. It is set by
+// the cmd package; the warnings are also in the audit log.
+var Warn = func(message string) {}
+
 // secureBufferWriter is a custom writer that accumulates data into a SecureString
 // for secure handling of decrypted vault data
 type secureBufferWriter struct {
@@ -46,24 +57,71 @@ func (w *secureBufferWriter) Write(p []byte) (n int, err error) {
 
 // VaultHeader with version support for future migrations
 type VaultHeader struct {
-	Version int   `json:"version"`
-	Data    Vault `json:"data"`
+	Version     int                      `json:"version"`
+	Data        Vault                    `json:"data"`
+	Integrity   *IntegrityKey            `json:"integrity,omitempty"`
+	Attestation *AttestationKey          `json:"attestation,omitempty"` // Signs exports, see attestation.go
+	History     []HistoryEntry           `json:"history,omitempty"`
+	Access      map[string][]AccessEntry `json:"access,omitempty"` // Recent accesses per wallet, see access.go
 }
 
 // Address defines the structure for a single address.
 type Address struct {
 	Index      int                    `json:"index"`
+	Account    int                    `json:"account,omitempty"` // BIP-44 account, see accounts.go
 	Path       string                 `json:"path"`
 	Address    string                 `json:"address"`
 	PrivateKey *security.SecureString `json:"privateKey"`
 }
 
-// Wallet defines the structure for a wallet, which can be HD or a single key.
+// KindSecret marks a wallet holding a generic secret, such as a password or API key,
+// instead of blockchain keys
+const KindSecret = "secret"
+
+// KindWatch marks a watch-only wallet: addresses without keys
+const KindWatch = "watch"
+
+// KindConsensus marks a validator's Tendermint consensus key: one ed25519 key
+// whose address is the validator address. It signs blocks, not transactions.
+const KindConsensus = "consensus"
+
+// Sources of wallets imported from raw key material rather than a mnemonic
+// or private key, kept as the wallet's provenance
+const (
+	SourceEntropy = "entropy"    // BIP-39 entropy; the mnemonic was reconstructed from it
+	SourceSeed    = "bip32-seed" // BIP-32 seed; there is no mnemonic to reconstruct
+)
+
+// Types of generic secret entries
+const (
+	SecretTypePassword = "password"
+	SecretTypeAPIKey   = "api-key"
+	SecretTypeTOTP     = "totp"
+	SecretTypeSeed     = "seed"
+	SecretTypeGeneric  = "generic"
+)
+
+// SecretTypes lists the accepted secret entry types
+var SecretTypes = []string{SecretTypePassword, SecretTypeAPIKey, SecretTypeTOTP, SecretTypeSeed, SecretTypeGeneric}
+
+// Wallet defines the structure for a wallet, which can be HD, a single key or a generic secret.
 type Wallet struct {
+	Kind           string                 `json:"kind,omitempty"`
+	SecretType     string                 `json:"secretType,omitempty"`
+	Secret         *security.SecureString `json:"secret,omitempty"`
 	Mnemonic       *security.SecureString `json:"mnemonic,omitempty"`
+	Seed           *security.SecureString `json:"seed,omitempty"`   // Hex BIP-32 seed of HD wallets without a mnemonic
+	Source         string                 `json:"source,omitempty"` // Provenance, see SourceEntropy
 	DerivationPath string                 `json:"derivationPath,omitempty"`
+	PathScheme     string                 `json:"pathScheme,omitempty"` // How addresses follow each other, see accounts.go
 	Addresses      []Address              `json:"addresses"`
 	Notes          string                 `json:"notes"`
+	Checklist      []ChecklistItem        `json:"checklist,omitempty"`
+	Frozen         *Freeze                `json:"frozen,omitempty"`
+	Tags           []string               `json:"tags,omitempty"`
+	Envelope       string                 `json:"envelope,omitempty"`
+	XPub           string                 `json:"xpub,omitempty"` // Account xpub, only kept in public twins
+	Archive        *ArchiveRef            `json:"archive,omitempty"` // Set on stubs of wallets moved to an archive vault
 }
 
 // Vault is the root structure of our vault (the JSON file).
@@ -75,6 +133,12 @@ func (w Wallet) Sanitize() Wallet {
 	if sanitizedWallet.Mnemonic != nil && sanitizedWallet.Mnemonic.String() != "" {
 		sanitizedWallet.Mnemonic = security.NewSecureString("[REDACTED]")
 	}
+	if sanitizedWallet.Secret != nil && sanitizedWallet.Secret.String() != "" {
+		sanitizedWallet.Secret = security.NewSecureString("[REDACTED]")
+	}
+	if sanitizedWallet.Seed != nil && sanitizedWallet.Seed.String() != "" {
+		sanitizedWallet.Seed = security.NewSecureString("[REDACTED]")
+	}
 
 	sanitizedAddresses := make([]Address, len(w.Addresses))
 	for i, addr := range w.Addresses {
@@ -91,6 +155,14 @@ func (w *Wallet) Clear() {
 		w.Mnemonic.Clear()
 		w.Mnemonic = nil
 	}
+	if w.Secret != nil {
+		w.Secret.Clear()
+		w.Secret = nil
+	}
+	if w.Seed != nil {
+		w.Seed.Clear()
+		w.Seed = nil
+	}
 	for i := range w.Addresses {
 		if w.Addresses[i].PrivateKey != nil {
 			w.Addresses[i].PrivateKey.Clear()
@@ -99,6 +171,12 @@ func (w *Wallet) Clear() {
 	}
 }
 
+// IsHD reports whether the wallet derives its addresses from a seed: it holds
+// a mnemonic or, when imported from one, a BIP-32 seed
+func (w Wallet) IsHD() bool {
+	return (w.Mnemonic != nil && !w.Mnemonic.IsEmpty()) || (w.Seed != nil && !w.Seed.IsEmpty())
+}
+
 // GetMnemonicHint returns a safe hint of the mnemonic (first and last word)
 func (w *Wallet) GetMnemonicHint() string {
 	if w.Mnemonic == nil {
@@ -158,33 +236,6 @@ func validateVaultVersion(version int) error {
 	return nil
 }
 
-// isProcessRunning checks if a process with given PID is still running
-// Uses more robust process existence checking with proper error handling
-func isProcessRunning(pid int) bool {
-	if pid <= 0 {
-		return false // Invalid PID
-	}
-
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		return false
-	}
-
-	// On Unix systems, signal 0 can be used to check if process exists
-	// This is the standard way to check process existence without affecting it
-	err = process.Signal(syscall.Signal(0))
-	if err != nil {
-		// Check specific error types to distinguish between permission and non-existence
-		if errno, ok := err.(syscall.Errno); ok {
-			// ESRCH means no such process
-			// EPERM means process exists but we don't have permission to signal it
-			return errno != syscall.ESRCH
-		}
-		return false
-	}
-	return true
-}
-
 // cleanupStaleLock removes lock file if the process that created it is no longer running
 // Enhanced with better validation and atomic operations
 func cleanupStaleLock(lockFileName string) error {
@@ -199,13 +250,13 @@ func cleanupStaleLock(lockFileName string) error {
 	defer lockFile.Close()
 
 	// Try to acquire a shared lock to ensure we're not interfering with active lock holder
-	if err := unix.Flock(int(lockFile.Fd()), unix.LOCK_SH|unix.LOCK_NB); err != nil {
+	if err := filelock.TryRLock(lockFile); err != nil {
 		// Lock is actively held by another process, don't clean it up
 		audit.Logger.Debug("Lock file is actively held, not cleaning up",
 			slog.String("lock_file", filepath.Base(lockFileName)))
 		return nil
 	}
-	defer unix.Flock(int(lockFile.Fd()), unix.LOCK_UN)
+	defer filelock.Unlock(lockFile)
 
 	// Read PID from the lock file
 	data, err := os.ReadFile(lockFileName)
@@ -213,7 +264,8 @@ func cleanupStaleLock(lockFileName string) error {
 		return err
 	}
 
-	pidStr := strings.TrimSpace(string(data))
+	// The lock holds "PID nonce"; locks of older versions only the PID
+	pidStr, _, _ := strings.Cut(strings.TrimSpace(string(data)), " ")
 	pid, err := strconv.Atoi(pidStr)
 	if err != nil {
 		// Invalid PID format, assume stale and remove
@@ -242,9 +294,9 @@ func cleanupStaleLock(lockFileName string) error {
 	return nil // Lock is still valid
 }
 
-// createLockFile creates a lock file with current PID using atomic operations
+// createLockFile creates a lock file with current PID and the save's nonce using atomic operations
 // Enhanced to prevent race conditions and ensure atomic lock creation
-func createLockFile(lockFileName string) (*os.File, error) {
+func createLockFile(lockFileName, nonce string) (*os.File, error) {
 	currentPID := os.Getpid()
 	pidStr := strconv.Itoa(currentPID)
 	
@@ -280,8 +332,8 @@ func createLockFile(lockFileName string) (*os.File, error) {
 			return nil, fmt.Errorf("failed to create temporary lock file: %v", err)
 		}
 
-		// Write PID to temporary file
-		if _, err := tmpFile.WriteString(pidStr); err != nil {
+		// Write PID and nonce to temporary file
+		if _, err := tmpFile.WriteString(pidStr + " " + nonce); err != nil {
 			tmpFile.Close()
 			os.Remove(tmpLockFile)
 			return nil, fmt.Errorf("failed to write PID to temporary lock file: %v", err)
@@ -319,10 +371,10 @@ func createLockFile(lockFileName string) (*os.File, error) {
 		}
 
 		// Apply exclusive file lock immediately
-		if err := unix.Flock(int(lockFile.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+		if err := filelock.TryLock(lockFile); err != nil {
 			lockFile.Close()
 			os.Remove(lockFileName)
-			if err == syscall.EWOULDBLOCK || err == syscall.EAGAIN {
+			if err == filelock.ErrLocked {
 				if retry < maxRetries-1 {
 					audit.Logger.Debug("Lock file is locked by another process, retrying",
 					slog.String("lock_file", filepath.Base(lockFileName)),
@@ -349,14 +401,14 @@ func createLockFile(lockFileName string) (*os.File, error) {
 // Enhanced with non-blocking option and proper error handling
 func lockFile(file *os.File) error {
 	// First try non-blocking lock to get immediate feedback
-	if err := unix.Flock(int(file.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
-		if err == syscall.EWOULDBLOCK || err == syscall.EAGAIN {
+	if err := filelock.TryLock(file); err != nil {
+		if err == filelock.ErrLocked {
 			// File is locked, try with timeout using blocking call
 			audit.Logger.Debug("File is locked, waiting for lock",
 				slog.String("file", file.Name()))
 			
 			// Use blocking lock as fallback
-			return unix.Flock(int(file.Fd()), unix.LOCK_EX)
+			return filelock.Lock(file)
 		}
 		return err
 	}
@@ -365,7 +417,7 @@ func lockFile(file *os.File) error {
 
 // unlockFile removes the lock from the file
 func unlockFile(file *os.File) error {
-	return unix.Flock(int(file.Fd()), unix.LOCK_UN)
+	return filelock.Unlock(file)
 }
 
 // openTTYSafely safely opens TTY with availability checks
@@ -376,9 +428,9 @@ func openTTYSafely() (*os.File, error) {
 	}
 
 	// Attempt to open TTY
-	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	tty, err := os.OpenFile(ttyDevice, os.O_RDWR, 0)
 	if err != nil {
-		return nil, errors.NewFileSystemError("open", "/dev/tty", err).WithDetails("TTY not accessible")
+		return nil, errors.NewFileSystemError("open", ttyDevice, err).WithDetails("TTY not accessible")
 	}
 
 	return tty, nil
@@ -413,7 +465,7 @@ func CheckYubiKeyWithRetry(maxRetries int) error {
 	for attempt := 1; attempt <= maxRetries; attempt++ {
 		audit.Logger.Debug("YubiKey check attempt", slog.Int("attempt", attempt), slog.Int("max_retries", maxRetries))
 
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		ctx, cancel := budget.WithTimeout(timeout)
 		cmd := exec.CommandContext(ctx, "age-plugin-yubikey", "--list")
 		output, err := cmd.CombinedOutput()
 		cancel()
@@ -543,6 +595,26 @@ func isBase64Like(s string) bool {
 
 // LoadVault decrypts and loads the vault from a file, using the specified method.
 func LoadVault(details config.VaultDetails) (Vault, error) {
+	return loadVault(details, true)
+}
+
+// LoadVaultUnverified loads the vault without checking the configuration signature.
+// Only used to re-sign a configuration the user has reviewed.
+func LoadVaultUnverified(details config.VaultDetails) (Vault, error) {
+	return loadVault(details, false)
+}
+
+// loadVault decrypts and loads the vault, as the "vault.decrypt" stage of the
+// progress events
+func loadVault(details config.VaultDetails, verifyConfig bool) (Vault, error) {
+	end := tasks.Stage("vault.decrypt")
+	v, err := decryptVault(details, verifyConfig)
+	end(err)
+	return v, err
+}
+
+// decryptVault is loadVault without the stage events
+func decryptVault(details config.VaultDetails, verifyConfig bool) (Vault, error) {
 	// Validate the file path
 	if err := config.ValidateFilePath(details.KeyFile, "keyfile"); err != nil {
 		audit.Logger.Error("Failed to validate key file path",
@@ -555,6 +627,7 @@ func LoadVault(details config.VaultDetails) (Vault, error) {
 		// If the vault file doesn't exist, return a new, empty vault.
 		audit.Logger.Info("Vault file does not exist, creating new vault",
 			slog.String("key_file", filepath.Base(details.KeyFile)))
+		forgetHistory(details.KeyFile)
 		return make(Vault), nil
 	}
 
@@ -562,6 +635,19 @@ func LoadVault(details config.VaultDetails) (Vault, error) {
 		slog.String("key_file", filepath.Base(details.KeyFile)),
 		slog.String("encryption", details.Encryption))
 
+	// A vault too large for memory fails here, not midway through decryption
+	if err := checkLoadMemory(details); err != nil {
+		return nil, err
+	}
+
+	// Team-managed vaults require a remote approval before every decryption
+	if details.ApprovalURL != "" {
+		timeout := time.Duration(details.ApprovalTimeout) * time.Second
+		if err := approval.Await(details.ApprovalURL, config.NameForKeyFile(details.KeyFile), timeout); err != nil {
+			return nil, err
+		}
+	}
+
 	// Lock the file to prevent concurrent access during loading
 	file, err := os.OpenFile(details.KeyFile, os.O_RDONLY, 0600)
 	if err != nil {
@@ -580,21 +666,40 @@ func LoadVault(details config.VaultDetails) (Vault, error) {
 	}
 
 	var ageCmd *exec.Cmd
+	// Where to render the touch prompt, if the identity requires a physical touch
+	var touchPromptOut *os.File
+	var decryptDeadline time.Time
+
+	// In organization mode each operator decrypts with their own identity
+	operator, err := decryptingOperator(details)
+	if err != nil {
+		return nil, err
+	}
+	backend := details.Encryption
+	if operator != nil {
+		backend = operatorBackend
+	}
+
+	switch backend {
+	case operatorBackend:
+		ctx, cancel := budget.WithTimeout(yubikeyDecryptTimeout)
+		defer cancel()
+
+		ageCmd, err = operatorDecryptCommand(ctx, details, *operator)
+		if err != nil {
+			return nil, err
+		}
 
-	switch details.Encryption {
 	case constants.EncryptionYubiKey:
 		// Check for age-plugin-yubikey availability
 		if _, err := exec.LookPath("age-plugin-yubikey"); err != nil {
 			return nil, errors.NewDependencyError("age-plugin-yubikey", "Please install it: https://github.com/str4d/age-plugin-yubikey")
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx, cancel := budget.WithTimeout(yubikeyDecryptTimeout)
 		defer cancel()
 
-		pluginArgs := []string{"-i"}
-		if config.Cfg.YubikeySlot != "" {
-			pluginArgs = append(pluginArgs, "--slot", config.Cfg.YubikeySlot)
-		}
+		pluginArgs := yubikeyPluginArgs(details)
 		pluginCmd := exec.CommandContext(ctx, "age-plugin-yubikey", pluginArgs...)
 
 		tty, err := openTTYSafely()
@@ -619,6 +724,41 @@ func LoadVault(details config.VaultDetails) (Vault, error) {
 		ageCmd = exec.CommandContext(ctx, "age", "--decrypt", "-i", "-", details.KeyFile)
 		ageCmd.Stdin = bytes.NewReader(identity)
 
+		if policy := touchIdentityPolicy(identity); RequiresTouch(policy) {
+			audit.Logger.Debug("YubiKey identity requires touch", slog.String("touch_policy", policy))
+			touchPromptOut = tty
+			decryptDeadline, _ = ctx.Deadline()
+		}
+
+	case constants.EncryptionFIDO2:
+		ctx, cancel := budget.WithTimeout(yubikeyDecryptTimeout)
+		defer cancel()
+
+		ageCmd, err = fido2DecryptCommand(ctx, details)
+		if err != nil {
+			return nil, err
+		}
+
+	case constants.EncryptionSecureEnclave:
+		ctx, cancel := budget.WithTimeout(yubikeyDecryptTimeout)
+		defer cancel()
+
+		ageCmd, err = secureEnclaveDecryptCommand(ctx, details)
+		if err != nil {
+			return nil, err
+		}
+
+	case constants.EncryptionTPM:
+		ctx, cancel := budget.WithTimeout(tpmCommandTimeout)
+		defer cancel()
+
+		var cleanupIdentity func()
+		ageCmd, cleanupIdentity, err = tpmDecryptCommand(ctx, details)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanupIdentity()
+
 	default:
 		return nil, errors.NewFormatInvalidError(details.Encryption, "unknown encryption method")
 	}
@@ -635,9 +775,21 @@ func LoadVault(details config.VaultDetails) (Vault, error) {
 		ageCmd.Stderr = &stderr
 	}
 
-	if err := ageCmd.Run(); err != nil {
+	stopTouchPrompt := func() {}
+	if touchPromptOut != nil {
+		stopTouchPrompt = startTouchPrompt(touchPromptOut, decryptDeadline)
+	}
+	runErr := ageCmd.Run()
+	stopTouchPrompt()
+
+	if err := runErr; err != nil {
 		// SecureBuffer will be cleared by defer, no additional cleanup needed
 
+		if touchPromptOut != nil && time.Now().After(decryptDeadline) {
+			return nil, errors.NewTimeoutError("yubikey touch", yubikeyDecryptTimeout.String()).
+				WithDetails("no touch was detected before the timeout; touch the YubiKey when prompted")
+		}
+
 		// Get stderr content - handle case where stderr might be set elsewhere
 		var stderrContent string
 		if ageCmd.Stderr == &stderr {
@@ -648,7 +800,7 @@ func LoadVault(details config.VaultDetails) (Vault, error) {
 		}
 
 		// For YubiKey encryption, use ParseYubiKeyError for all errors with sanitized content
-		if details.Encryption == constants.EncryptionYubiKey {
+		if backend == constants.EncryptionYubiKey {
 			return nil, errors.ParseYubiKeyError(err, sanitizeLogOutput(stderrContent))
 		}
 
@@ -659,10 +811,30 @@ func LoadVault(details config.VaultDetails) (Vault, error) {
 		return nil, errors.NewVaultLoadError(details.KeyFile, err).WithDetails(stderrContent)
 	}
 
+	// Peel off the inner layer for two-factor vaults
+	if details.SecondFactor != "" {
+		ctx, cancel := budget.WithTimeout(yubikeyDecryptTimeout)
+		defer cancel()
+
+		var innerPlaintext *security.SecureString
+		err = secureBuffer.WithSecureOperation(func(inner []byte) error {
+			var decErr error
+			innerPlaintext, decErr = decryptSecondFactor(ctx, details, inner)
+			return decErr
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer innerPlaintext.Clear()
+		secureBuffer = innerPlaintext
+	}
+
 	// Data is now securely stored in secureBuffer, ready for processing
 	var finalVault Vault
 
 	// Use secure operation to process vault data
+	var history []HistoryEntry
+	var access map[string][]AccessEntry
 	err = secureBuffer.WithSecureOperation(func(vaultData []byte) error {
 		// Detect vault format and handle accordingly
 		isVersioned, err := detectVaultFormat(vaultData)
@@ -696,6 +868,19 @@ func LoadVault(details config.VaultDetails) (Vault, error) {
 				slog.String("key_file", filepath.Base(details.KeyFile)),
 				slog.Int("version", header.Version))
 
+			rememberIntegrityKey(details.KeyFile, header.Integrity)
+			rememberAttestationKey(details.KeyFile, header.Attestation)
+			history = header.History
+			access = header.Access
+			if header.Integrity != nil && verifyConfig {
+				if err := verifyIntegrity(details, header.Integrity); err != nil {
+					for _, wallet := range header.Data {
+						wallet.Clear()
+					}
+					return err
+				}
+			}
+
 			finalVault = header.Data
 		} else {
 			// Handle legacy format
@@ -716,13 +901,31 @@ func LoadVault(details config.VaultDetails) (Vault, error) {
 	if err != nil {
 		return nil, err
 	}
+	rememberHistory(details.KeyFile, history, finalVault)
+	rememberAccess(details.KeyFile, access)
 
 	audit.Logger.Info("Vault loaded successfully",
 	slog.String("key_file", filepath.Base(details.KeyFile)),
 	slog.Int("wallet_count", len(finalVault)))
+	if operator != nil {
+		audit.Logger.Info("Vault decrypted by operator",
+			slog.String("vault", config.NameForKeyFile(details.KeyFile)),
+			slog.String("operator", operator.Name),
+			slog.String("recipient", operator.Recipient))
+	}
 	return finalVault, nil
 }
 
+// syncDir flushes a directory so a rename inside it survives a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
 // createSecureTempFile creates a temporary file with secure permissions (0600)
 func createSecureTempFile(dir string) (*os.File, error) {
 	tmpfile, err := os.CreateTemp(dir, "vault-tmp-*")
@@ -739,8 +942,57 @@ func createSecureTempFile(dir string) (*os.File, error) {
 	return tmpfile, nil
 }
 
-// SaveVault encrypts and saves the vault to a file atomically.
+// SaveVault encrypts and saves the vault to a file atomically, then updates its public twin.
+// Notes that look like they hold a secret are warned about, or refused by the strict profile.
 func SaveVault(details config.VaultDetails, v Vault) error {
+	if err := checkNotesForSecrets(details, v); err != nil {
+		return err
+	}
+	err := writeVault(details, v, func(stored Vault) []HistoryEntry {
+		return nextHistory(details.KeyFile, stored)
+	})
+	if err != nil {
+		return err
+	}
+	syncPublicTwinAfterSave(details, v)
+	return nil
+}
+
+// runPreSaveHooks runs the pre-save hook scripts of the vault; any failing
+// script vetoes the save
+func runPreSaveHooks(details config.VaultDetails, v Vault) error {
+	vaultName := config.NameForKeyFile(details.KeyFile)
+	if !hooks.Configured(hooks.PreSave, vaultName) {
+		return nil
+	}
+	prefixes := make([]string, 0, len(v))
+	for prefix := range v {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	errs := hooks.Run(hooks.Event{Hook: hooks.PreSave, Vault: vaultName, Prefixes: prefixes})
+	if len(errs) == 0 {
+		return nil
+	}
+	reasons := make([]string, 0, len(errs))
+	for _, err := range errs {
+		reasons = append(reasons, err.Error())
+	}
+	return errors.New(errors.ErrCodeVaultSave, "a pre-save hook rejected the save").
+		WithDetails(strings.Join(reasons, "; "))
+}
+
+// writeVault encrypts and saves the vault with the history returned by
+// history, as the "vault.save" stage of the progress events
+func writeVault(details config.VaultDetails, v Vault, history func(stored Vault) []HistoryEntry) error {
+	end := tasks.Stage("vault.save")
+	err := encryptVault(details, v, history)
+	end(err)
+	return err
+}
+
+// encryptVault is writeVault without the stage events
+func encryptVault(details config.VaultDetails, v Vault, history func(stored Vault) []HistoryEntry) error {
 	audit.Logger.Info("Saving vault",
 		slog.String("key_file", filepath.Base(details.KeyFile)),
 		slog.String("encryption", details.Encryption),
@@ -765,13 +1017,25 @@ func SaveVault(details config.VaultDetails, v Vault) error {
 		recipientsFile = details.RecipientsFile
 	}
 
-	// Create lock file with PID to prevent concurrent saves and handle stale locks
+	if err := runPreSaveHooks(details, v); err != nil {
+		return err
+	}
+
+	// Create lock file with PID to prevent concurrent saves and handle stale locks;
+	// its nonce ties the save record written below to this save
+	nonce, err := newSaveNonce()
+	if err != nil {
+		return err
+	}
 	lockFileName := details.KeyFile + ".lock"
-	lockFile, err := createLockFile(lockFileName)
+	lockFile, err := createLockFile(lockFileName, nonce)
 	if err != nil {
 		if os.IsExist(err) {
 			return errors.NewVaultLockedError(details.KeyFile)
 		}
+		if writeErr := saveWriteError("create", lockFileName, err, ""); writeErr != nil {
+			return writeErr
+		}
 		return errors.NewFileSystemError("create", lockFileName, err)
 	}
 	defer func() {
@@ -787,10 +1051,24 @@ func SaveVault(details config.VaultDetails, v Vault) error {
 
 	audit.Logger.Debug("Lock file created for save operation", slog.String("lock_file", filepath.Base(lockFileName)))
 
+	// Sealed wallets are written without the secrets their envelopes hold,
+	// even if a command opened them
+	stored := make(Vault, len(v))
+	for prefix, wallet := range v {
+		if wallet.Sealed() {
+			wallet = wallet.withoutSecrets()
+		}
+		stored[prefix] = wallet
+	}
+
 	// Create versioned vault header
 	vaultHeader := VaultHeader{
-		Version: CurrentVaultVersion,
-		Data:    v,
+		Version:     CurrentVaultVersion,
+		Data:        stored,
+		Integrity:   integrityKeyFor(details.KeyFile),
+		Attestation: attestationKeyFor(details.KeyFile),
+		History:     history(stored),
+		Access:      accessFor(details.KeyFile, stored),
 	}
 
 	// Serialize versioned data securely after acquiring lock
@@ -810,32 +1088,67 @@ func SaveVault(details config.VaultDetails, v Vault) error {
 		dir = "."
 	}
 
+	// Fail before writing anything if the vault cannot fit: the original file
+	// stays untouched
+	if err := checkSaveTarget(dir, len(data)); err != nil {
+		audit.Logger.Error("Vault cannot be saved here",
+			slog.String("key_file", filepath.Base(details.KeyFile)),
+			slog.String("error", err.Error()))
+		return err
+	}
+
 	tmpfile, err := createSecureTempFile(dir)
 	if err != nil {
+		if writeErr := saveWriteError("create", dir, err, ""); writeErr != nil {
+			return writeErr
+		}
 		return errors.NewFileSystemError("create", dir, err).WithDetails("could not create temp file")
 	}
 	defer os.Remove(tmpfile.Name()) // clean up
+	// An interrupted save leaves no temp file next to the vault
+	tmpName := tmpfile.Name()
+	removeTmp := security.RegisterFuncGlobal("vault temp file "+filepath.Base(tmpName), security.PriorityTempFile, func() error {
+		if err := os.Remove(tmpName); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	})
+	defer security.UnregisterGlobal(removeTmp)
 
 	var cmd *exec.Cmd
 
 	switch details.Encryption {
-	case constants.EncryptionYubiKey:
+	case constants.EncryptionYubiKey, constants.EncryptionFIDO2, constants.EncryptionTPM, constants.EncryptionSecureEnclave:
 		// Check for age availability
 		if _, err := exec.LookPath("age"); err != nil {
 			return errors.NewDependencyError("age", "Please install it: https://github.com/FiloSottile/age")
 		}
 
 		if recipientsFile == "" {
-			return errors.NewConfigMissingError("recipients_file").WithDetails("recipients file is required for yubikey encryption")
+			return errors.NewConfigMissingError("recipients_file").WithDetails(fmt.Sprintf("recipients file is required for %s encryption", details.Encryption))
 		}
 		if _, err := os.Stat(recipientsFile); os.IsNotExist(err) {
 			return errors.NewFileSystemError("access", recipientsFile, err).WithDetails("recipients file not found")
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx, cancel := budget.WithTimeout(30*time.Second)
 		defer cancel()
 
+		if details.SecondFactor != "" {
+			inner, err := encryptSecondFactor(ctx, details, data)
+			if err != nil {
+				return err
+			}
+			security.SecureZero(data)
+			data = inner
+		}
+
 		args := []string{"-a", "-R", recipientsFile, "-o", tmpfile.Name()}
+		operatorArgs, err := operatorRecipientArgs(details)
+		if err != nil {
+			return err
+		}
+		args = append(args, operatorArgs...)
 		cmd = exec.CommandContext(ctx, "age", args...)
 		// Use secure reader for sensitive data
 		cmd.Stdin = bytes.NewReader(data)
@@ -858,9 +1171,23 @@ func SaveVault(details config.VaultDetails, v Vault) error {
 			slog.String("key_file", filepath.Base(details.KeyFile)),
 			slog.String("error", runErr.Error()),
 			slog.String("stderr", sanitizedStderr))
+		// A full disk mid-write leaves a partial temp file, removed above
+		if writeErr := saveWriteError("write", tmpfile.Name(), runErr, strings.ToLower(stderrContent)); writeErr != nil {
+			return writeErr
+		}
 		return errors.NewVaultSaveError(details.KeyFile, runErr).WithDetails(sanitizedStderr)
 	}
 
+	// Strict profile: make sure the ciphertext is on disk before it replaces the vault
+	if config.Cfg.Strict {
+		if err := tmpfile.Sync(); err != nil {
+			if writeErr := saveWriteError("fsync", tmpfile.Name(), err, ""); writeErr != nil {
+				return writeErr
+			}
+			return errors.NewFileSystemError("fsync", tmpfile.Name(), err)
+		}
+	}
+
 	// Atomically replace the target file with our encrypted temporary file
 	encryptedFile := tmpfile.Name()
 	tmpfile.Close() // Close handle to allow rename
@@ -874,6 +1201,12 @@ func SaveVault(details config.VaultDetails, v Vault) error {
 		return errors.NewFileSystemError("rename", encryptedFile, err).WithDetails("failed to atomically move encrypted file")
 	}
 
+	if config.Cfg.Strict {
+		if err := syncDir(filepath.Dir(details.KeyFile)); err != nil {
+			return errors.NewFileSystemError("fsync", filepath.Dir(details.KeyFile), err)
+		}
+	}
+
 	// Set secure permissions for the final file
 	if err := os.Chmod(details.KeyFile, 0600); err != nil {
 		audit.Logger.Error("Failed to set secure permissions on final file",
@@ -882,6 +1215,11 @@ func SaveVault(details config.VaultDetails, v Vault) error {
 		// Don't return error as file is already saved
 	}
 
+	// Lets 'vaults watch' tell this save from an external modification
+	writeSaveRecord(details, nonce)
+
+	rememberHistory(details.KeyFile, vaultHeader.History, stored)
+
 	audit.Logger.Info("Vault saved successfully",
 	slog.String("key_file", filepath.Base(details.KeyFile)),
 	slog.Int("wallet_count", len(v)))
@@ -0,0 +1,133 @@
+// File: internal/vault/yubikey.go
+package vault
+
+import (
+	"bufio"
+	"log/slog"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"vault.module/internal/audit"
+	"vault.module/internal/budget"
+	"vault.module/internal/config"
+	"vault.module/internal/errors"
+)
+
+// YubiKeyIdentity describes a single age identity stored on a connected YubiKey.
+type YubiKeyIdentity struct {
+	Serial      string `json:"serial"`
+	Slot        string `json:"slot"`
+	Name        string `json:"name,omitempty"`
+	PINPolicy   string `json:"pinPolicy,omitempty"`
+	TouchPolicy string `json:"touchPolicy,omitempty"`
+	Recipient   string `json:"recipient,omitempty"`
+}
+
+var serialSlotPattern = regexp.MustCompile(`Serial:\s*(\d+),\s*Slot:\s*(\d+)`)
+
+// ListYubiKeys enumerates age identities on all connected YubiKeys.
+func ListYubiKeys() ([]YubiKeyIdentity, error) {
+	if _, err := exec.LookPath("age-plugin-yubikey"); err != nil {
+		return nil, errors.NewDependencyError("age-plugin-yubikey", "Please install it: https://github.com/str4d/age-plugin-yubikey")
+	}
+
+	ctx, cancel := budget.WithTimeout(getYubiKeyTimeout())
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "age-plugin-yubikey", "--list").Output()
+	if err != nil {
+		audit.Logger.Warn("Failed to enumerate YubiKeys", slog.String("error", err.Error()))
+		return nil, errors.ParseYubiKeyError(err, "")
+	}
+
+	return parseYubiKeyList(string(output)), nil
+}
+
+// parseYubiKeyList parses the comment-annotated output of `age-plugin-yubikey --list`
+// (or `--identity`, which uses the same header). Each identity block starts with a
+// "Serial: N, Slot: M" comment and ends with the recipient line.
+func parseYubiKeyList(output string) []YubiKeyIdentity {
+	var identities []YubiKeyIdentity
+	var current *YubiKeyIdentity
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if m := serialSlotPattern.FindStringSubmatch(line); m != nil {
+			if current != nil {
+				identities = append(identities, *current)
+			}
+			current = &YubiKeyIdentity{Serial: m[1], Slot: m[2]}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			key, value, ok := strings.Cut(strings.TrimSpace(strings.TrimPrefix(line, "#")), ":")
+			if !ok {
+				continue
+			}
+			value = strings.TrimSpace(value)
+			// Drop the human explanation in parentheses, e.g. "Always (A physical touch ...)"
+			if idx := strings.Index(value, "("); idx > 0 {
+				value = strings.TrimSpace(value[:idx])
+			}
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "name":
+				current.Name = value
+			case "pin policy":
+				current.PINPolicy = value
+			case "touch policy":
+				current.TouchPolicy = value
+			case "recipient":
+				current.Recipient = value
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "age1yubikey") {
+			current.Recipient = line
+			identities = append(identities, *current)
+			current = nil
+		}
+	}
+	if current != nil {
+		identities = append(identities, *current)
+	}
+	return identities
+}
+
+// FindYubiKeyIdentity returns the connected identity matching the vault's serial and slot.
+// Empty serial or slot values act as wildcards.
+func FindYubiKeyIdentity(identities []YubiKeyIdentity, details config.VaultDetails) (YubiKeyIdentity, bool) {
+	slot := details.ResolveYubikeySlot()
+	for _, id := range identities {
+		if details.YubikeySerial != "" && id.Serial != details.YubikeySerial {
+			continue
+		}
+		if slot != "" && id.Slot != slot {
+			continue
+		}
+		return id, true
+	}
+	return YubiKeyIdentity{}, false
+}
+
+// yubikeyPluginArgs builds the identity arguments for age-plugin-yubikey for a vault.
+func yubikeyPluginArgs(details config.VaultDetails) []string {
+	args := []string{"-i"}
+	if details.YubikeySerial != "" {
+		args = append(args, "--serial", details.YubikeySerial)
+	}
+	if slot := details.ResolveYubikeySlot(); slot != "" {
+		args = append(args, "--slot", slot)
+	}
+	return args
+}
@@ -0,0 +1,138 @@
+// File: internal/vaulttemplate/vaulttemplate.go
+package vaulttemplate
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"vault.module/internal/config"
+	"vault.module/internal/vault"
+)
+
+// Built-in templates, one JSON file per template
+//
+//go:embed templates/*.json
+var builtin embed.FS
+
+// Template describes how a new vault starts out: its tag taxonomy, wallet
+// naming convention, command defaults, written policy and placeholder
+// wallets. Templates are plain JSON so teams can share their own.
+type Template struct {
+	Name          string                `json:"name"`
+	Description   string                `json:"description,omitempty"`
+	Type          string                `json:"type,omitempty"`           // Vault type used when --type is not given
+	Tags          []string              `json:"tags,omitempty"`           // The only tags wallets of the vault may carry
+	PrefixPattern string                `json:"prefix_pattern,omitempty"` // Regular expression every wallet prefix must match
+	Defaults      *config.VaultDefaults `json:"defaults,omitempty"`
+	Policy        string                `json:"policy,omitempty"` // Text of the vault's policy, written next to the vault file
+	Wallets       []Wallet              `json:"wallets,omitempty"`
+}
+
+// Wallet is a placeholder watch-only wallet of a template.
+type Wallet struct {
+	Prefix    string   `json:"prefix"`
+	Addresses []string `json:"addresses,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	Notes     string   `json:"notes,omitempty"`
+}
+
+// Builtin returns the names of the built-in templates, sorted.
+func Builtin() []string {
+	entries, _ := builtin.ReadDir("templates")
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load returns the built-in template of that name, or reads the template file
+// at that path.
+func Load(nameOrPath string) (*Template, []byte, error) {
+	data, err := builtin.ReadFile(path.Join("templates", nameOrPath+".json"))
+	if err != nil {
+		data, err = os.ReadFile(nameOrPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("no built-in template %q (available: %s) and no such file", nameOrPath, strings.Join(Builtin(), ", "))
+		}
+	}
+
+	var t Template
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, nil, fmt.Errorf("template %s: %w", nameOrPath, err)
+	}
+	return &t, data, nil
+}
+
+// Validate checks the template against itself: placeholder wallets must follow
+// its naming convention and tag taxonomy. validPrefix applies the tool's own
+// prefix rules and validTag its tag syntax.
+func (t *Template) Validate(validPrefix func(string) error, validTag func(string) bool) error {
+	if t.Name == "" {
+		return fmt.Errorf("template has no name")
+	}
+	var pattern *regexp.Regexp
+	if t.PrefixPattern != "" {
+		var err error
+		if pattern, err = regexp.Compile(t.PrefixPattern); err != nil {
+			return fmt.Errorf("prefix_pattern: %w", err)
+		}
+	}
+	allowed := map[string]bool{}
+	for _, tag := range t.Tags {
+		if !validTag(tag) {
+			return fmt.Errorf("invalid tag %q in the taxonomy", tag)
+		}
+		allowed[tag] = true
+	}
+
+	seen := map[string]bool{}
+	for _, w := range t.Wallets {
+		if err := validPrefix(w.Prefix); err != nil {
+			return fmt.Errorf("wallet %q: %w", w.Prefix, err)
+		}
+		if pattern != nil && !pattern.MatchString(w.Prefix) {
+			return fmt.Errorf("wallet %q does not match the prefix pattern %s", w.Prefix, t.PrefixPattern)
+		}
+		if seen[w.Prefix] {
+			return fmt.Errorf("wallet %q is listed twice", w.Prefix)
+		}
+		seen[w.Prefix] = true
+		for _, tag := range w.Tags {
+			if len(t.Tags) > 0 && !allowed[tag] {
+				return fmt.Errorf("wallet %q: tag %q is not in the taxonomy", w.Prefix, tag)
+			}
+			if !validTag(tag) {
+				return fmt.Errorf("wallet %q: invalid tag %q", w.Prefix, tag)
+			}
+		}
+	}
+	return nil
+}
+
+// Vault returns the placeholder wallets of the template as watch-only wallets.
+func (t *Template) Vault() vault.Vault {
+	v := make(vault.Vault, len(t.Wallets))
+	for _, w := range t.Wallets {
+		addresses := make([]vault.Address, 0, len(w.Addresses))
+		for i, a := range w.Addresses {
+			addresses = append(addresses, vault.Address{Index: i, Path: "watch", Address: a})
+		}
+		tags := append([]string(nil), w.Tags...)
+		sort.Strings(tags)
+		v[w.Prefix] = vault.Wallet{
+			Kind:      vault.KindWatch,
+			Addresses: addresses,
+			Notes:     w.Notes,
+			Tags:      tags,
+		}
+	}
+	return v
+}
@@ -0,0 +1,493 @@
+// File: internal/web3signer/web3signer.go
+package web3signer
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"vault.module/internal/addressbook"
+	"vault.module/internal/keys"
+)
+
+// maxBodySize bounds a request body; typed data is the largest payload
+const maxBodySize = 1 << 20
+
+// ErrRefused is returned by a Signer that will not sign a request; the
+// client gets 403 or a JSON-RPC error instead of a server error
+var ErrRefused = errors.New("signing refused")
+
+// Key is an address the signer holds the key of
+type Key struct {
+	Address   common.Address
+	PublicKey []byte // Uncompressed secp256k1 public key, without the 0x04 prefix
+}
+
+// Request is a payload to sign, hashed as Kind says
+type Request struct {
+	Method  string // API endpoint or JSON-RPC method asking for the signature
+	Kind    keys.EVMPayloadKind
+	Payload []byte
+	ChainID int64 // 0 when the payload does not name a chain
+	Summary string
+	Client  string // Client authenticated by its client token, "" for the signer's token
+}
+
+// Signer signs requests with the key of an address. It returns r || s || v as
+// keys.SignEVMPayload does.
+type Signer interface {
+	Sign(address common.Address, req Request) ([]byte, error)
+}
+
+// Server serves a subset of the Web3Signer REST API and its eth1 JSON-RPC
+// methods on a loopback address
+type Server struct {
+	Keys    []Key
+	Signer  Signer
+	ChainID int64  // Chain of eth_chainId and of transactions that name none
+	RawSign bool   // Serve /api/v1/eth1/sign, which signs arbitrary hashes
+	Token   string // Bearer token required from clients
+	// Clients authenticates the client tokens also accepted as bearer tokens,
+	// returning the client a token was issued to. Optional.
+	Clients func(token string) (string, bool)
+}
+
+// clientKey is the request context key of the authenticated client
+type clientKey struct{}
+
+// clientOf returns the client that authenticated r
+func clientOf(r *http.Request) string {
+	client, _ := r.Context().Value(clientKey{}).(string)
+	return client
+}
+
+// ListenAndServe serves on addr, which must be a loopback address, until ctx is done.
+// Any local user can reach a loopback address, so a token is required.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	if s.Token == "" {
+		return errors.New("the signer requires a bearer token")
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return errors.New("the signer may only listen on a loopback address")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /upcheck", s.guard(s.handleUpcheck))
+	mux.HandleFunc("GET /healthcheck", s.guard(s.handleHealthcheck))
+	mux.HandleFunc("GET /api/v1/eth1/publicKeys", s.guard(s.handlePublicKeys))
+	mux.HandleFunc("POST /api/v1/eth1/sign/{identifier}", s.guard(s.handleSign))
+	mux.HandleFunc("GET /api/v1/eth2/publicKeys", s.guard(s.handleEth2PublicKeys))
+	mux.HandleFunc("POST /{$}", s.guard(s.handleRPC))
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// guard rejects requests with a foreign Host header (DNS rebinding), requests
+// made by browsers (they send Origin) and requests without the token or a
+// client token, and records the client of a client token in the request context
+func (s *Server) guard(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if host != "localhost" && !isLoopbackIP(host) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("Origin") != "" {
+			http.Error(w, "browser requests are not served", http.StatusForbidden)
+			return
+		}
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		client, ok := s.authenticate(token)
+		if !ok {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+		next(w, r.WithContext(context.WithValue(r.Context(), clientKey{}, client)))
+	}
+}
+
+// authenticate checks a bearer token against the signer's token and the client
+// tokens, and returns the client of a client token
+func (s *Server) authenticate(token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+	if s.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1 {
+		return "", true
+	}
+	if s.Clients != nil {
+		return s.Clients(token)
+	}
+	return "", false
+}
+
+func isLoopbackIP(host string) bool {
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (s *Server) handleUpcheck(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, "OK")
+}
+
+func (s *Server) handleHealthcheck(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"status": "UP", "checks": []any{}, "outcome": "UP"})
+}
+
+func (s *Server) handlePublicKeys(w http.ResponseWriter, r *http.Request) {
+	publicKeys := make([]string, 0, len(s.Keys))
+	for _, key := range s.Keys {
+		publicKeys = append(publicKeys, hexutil.Encode(key.PublicKey))
+	}
+	writeJSON(w, http.StatusOK, publicKeys)
+}
+
+// handleEth2PublicKeys lists no keys: the vault holds no BLS validator keys,
+// and consensus clients asking for them should find none rather than fail
+func (s *Server) handleEth2PublicKeys(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, []string{})
+}
+
+// handleSign signs the Keccak-256 hash of data with the key named by its
+// public key (or address), as Web3Signer's eth1 sign does
+func (s *Server) handleSign(w http.ResponseWriter, r *http.Request) {
+	if !s.RawSign {
+		http.Error(w, "raw signing is disabled; start the signer with --raw-sign", http.StatusForbidden)
+		return
+	}
+	key, ok := s.findKey(r.PathValue("identifier"))
+	if !ok {
+		http.Error(w, "public key not found", http.StatusNotFound)
+		return
+	}
+	var body struct {
+		Data hexutil.Bytes `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Data) == 0 {
+		http.Error(w, "body must be {\"data\": \"0x...\"}", http.StatusBadRequest)
+		return
+	}
+	signature, err := s.Signer.Sign(key.Address, Request{
+		Method:  "eth1/sign",
+		Kind:    keys.EVMRawData,
+		Payload: body.Data,
+		Summary: fmt.Sprintf("%d bytes of raw data", len(body.Data)),
+		Client:  clientOf(r),
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrRefused) {
+			status = http.StatusForbidden
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, hexutil.Encode(signature))
+}
+
+// findKey finds a key by its public key, with or without the 0x04 prefix, or
+// by its address
+func (s *Server) findKey(identifier string) (Key, bool) {
+	raw, err := hexutil.Decode(identifier)
+	if err != nil {
+		return Key{}, false
+	}
+	if len(raw) == 65 && raw[0] == 0x04 {
+		raw = raw[1:]
+	}
+	for _, key := range s.Keys {
+		if string(raw) == string(key.PublicKey) || (len(raw) == common.AddressLength && common.BytesToAddress(raw) == key.Address) {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+// rpcRequest is a JSON-RPC 2.0 request
+type rpcRequest struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      json.RawMessage   `json:"id"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+	client  string            // set from the request context, not the body
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// JSON-RPC error codes
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcServerError    = -32000
+)
+
+func (s *Server) handleRPC(w http.ResponseWriter, r *http.Request) {
+	var req rpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusOK, rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{rpcParseError, "parse error"}})
+		return
+	}
+	if req.ID == nil {
+		req.ID = json.RawMessage("null")
+	}
+	req.client = clientOf(r)
+	result, rpcErr := s.call(req)
+	writeJSON(w, http.StatusOK, rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr})
+}
+
+// call runs a JSON-RPC method
+func (s *Server) call(req rpcRequest) (any, *rpcError) {
+	if req.JSONRPC != "2.0" {
+		return nil, &rpcError{rpcInvalidRequest, "jsonrpc must be 2.0"}
+	}
+	switch req.Method {
+	case "eth_accounts":
+		accounts := make([]string, 0, len(s.Keys))
+		for _, key := range s.Keys {
+			accounts = append(accounts, key.Address.Hex())
+		}
+		return accounts, nil
+	case "eth_chainId":
+		return hexutil.EncodeBig(big.NewInt(s.ChainID)), nil
+	case "eth_sign":
+		var address common.Address
+		var data hexutil.Bytes
+		if err := params(req, &address, &data); err != nil {
+			return nil, err
+		}
+		return s.sign(address, Request{Method: req.Method, Kind: keys.EVMPersonalMessage, Payload: data, Summary: fmt.Sprintf("%d-byte message", len(data)), Client: req.client})
+	case "eth_signTypedData", "eth_signTypedData_v4":
+		var address common.Address
+		var typedData json.RawMessage
+		if err := params(req, &address, &typedData); err != nil {
+			return nil, err
+		}
+		// Clients send the typed data as an object or as a JSON string
+		var asString string
+		if json.Unmarshal(typedData, &asString) == nil {
+			typedData = json.RawMessage(asString)
+		}
+		// A signature for another chain's domain could be replayed there
+		chainID := typedDataChainID(typedData)
+		if chainID != 0 && chainID != s.ChainID {
+			return nil, &rpcError{rpcInvalidParams, fmt.Sprintf("the typed data is for chain %d, not the signer's chain %d", chainID, s.ChainID)}
+		}
+		return s.sign(address, Request{Method: req.Method, Kind: keys.EVMTypedData, Payload: typedData, ChainID: chainID, Summary: "EIP-712 typed data", Client: req.client})
+	case "eth_signTransaction":
+		var args TransactionArgs
+		if err := params(req, &args); err != nil {
+			return nil, err
+		}
+		return s.signTransaction(req, args)
+	default:
+		return nil, &rpcError{rpcMethodNotFound, fmt.Sprintf("method %s is not supported", req.Method)}
+	}
+}
+
+// params decodes the positional parameters of req into targets
+func params(req rpcRequest, targets ...any) *rpcError {
+	if len(req.Params) != len(targets) {
+		return &rpcError{rpcInvalidParams, fmt.Sprintf("%s takes %d parameters", req.Method, len(targets))}
+	}
+	for i, target := range targets {
+		if err := json.Unmarshal(req.Params[i], target); err != nil {
+			return &rpcError{rpcInvalidParams, fmt.Sprintf("parameter %d: %v", i+1, err)}
+		}
+	}
+	return nil
+}
+
+// sign signs req with the key of address and returns the hex signature
+func (s *Server) sign(address common.Address, req Request) (any, *rpcError) {
+	if _, ok := s.findKey(address.Hex()); !ok {
+		return nil, &rpcError{rpcServerError, fmt.Sprintf("no key for account %s", address.Hex())}
+	}
+	signature, err := s.Signer.Sign(address, req)
+	if err != nil {
+		return nil, &rpcError{rpcServerError, err.Error()}
+	}
+	return hexutil.Encode(signature), nil
+}
+
+// typedDataChainID returns the chain ID in the domain of typed data, 0 if it names none
+func typedDataChainID(typedData []byte) int64 {
+	var data struct {
+		Domain struct {
+			ChainID *json.Number `json:"chainId"`
+		} `json:"domain"`
+	}
+	if json.Unmarshal(typedData, &data) != nil || data.Domain.ChainID == nil {
+		return 0
+	}
+	id, ok := new(big.Int).SetString(data.Domain.ChainID.String(), 0)
+	if !ok || !id.IsInt64() {
+		return 0
+	}
+	return id.Int64()
+}
+
+// TransactionArgs are the fields of eth_signTransaction. The signer has no
+// node to ask, so nonce and gas must be given.
+type TransactionArgs struct {
+	From                 common.Address    `json:"from"`
+	To                   *common.Address   `json:"to"`
+	Gas                  *hexutil.Uint64   `json:"gas"`
+	GasPrice             *hexutil.Big      `json:"gasPrice"`
+	MaxFeePerGas         *hexutil.Big      `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big      `json:"maxPriorityFeePerGas"`
+	Value                *hexutil.Big      `json:"value"`
+	Nonce                *hexutil.Uint64   `json:"nonce"`
+	Data                 *hexutil.Bytes    `json:"data"`
+	Input                *hexutil.Bytes    `json:"input"`
+	ChainID              *hexutil.Big      `json:"chainId"`
+	AccessList           *types.AccessList `json:"accessList"`
+}
+
+// signTransaction signs a transaction and returns it RLP-encoded, ready for
+// eth_sendRawTransaction
+func (s *Server) signTransaction(req rpcRequest, args TransactionArgs) (any, *rpcError) {
+	if args.Nonce == nil || args.Gas == nil {
+		return nil, &rpcError{rpcInvalidParams, "nonce and gas are required"}
+	}
+	chainID := big.NewInt(s.ChainID)
+	if args.ChainID != nil && args.ChainID.ToInt().Cmp(chainID) != 0 {
+		return nil, &rpcError{rpcInvalidParams, fmt.Sprintf("chainId %s does not match the signer's chain %d", args.ChainID.ToInt(), s.ChainID)}
+	}
+	data := []byte{}
+	if args.Input != nil {
+		data = *args.Input
+	} else if args.Data != nil {
+		data = *args.Data
+	}
+	value := new(big.Int)
+	if args.Value != nil {
+		value = args.Value.ToInt()
+	}
+
+	var tx *types.Transaction
+	var kind keys.EVMPayloadKind
+	var fields []any
+	switch {
+	case args.MaxFeePerGas != nil:
+		if args.GasPrice != nil {
+			return nil, &rpcError{rpcInvalidParams, "give either gasPrice or maxFeePerGas"}
+		}
+		tip := new(big.Int)
+		if args.MaxPriorityFeePerGas != nil {
+			tip = args.MaxPriorityFeePerGas.ToInt()
+		}
+		accessList := types.AccessList{}
+		if args.AccessList != nil {
+			accessList = *args.AccessList
+		}
+		tx = types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: uint64(*args.Nonce), GasTipCap: tip, GasFeeCap: args.MaxFeePerGas.ToInt(), Gas: uint64(*args.Gas), To: args.To, Value: value, Data: data, AccessList: accessList})
+		kind, fields = keys.EVMTypedTransaction, []any{chainID, tx.Nonce(), tx.GasTipCap(), tx.GasFeeCap(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), tx.AccessList()}
+	case args.GasPrice != nil && args.AccessList != nil:
+		tx = types.NewTx(&types.AccessListTx{ChainID: chainID, Nonce: uint64(*args.Nonce), GasPrice: args.GasPrice.ToInt(), Gas: uint64(*args.Gas), To: args.To, Value: value, Data: data, AccessList: *args.AccessList})
+		kind, fields = keys.EVMTypedTransaction, []any{chainID, tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), tx.AccessList()}
+	case args.GasPrice != nil:
+		tx = types.NewTx(&types.LegacyTx{Nonce: uint64(*args.Nonce), GasPrice: args.GasPrice.ToInt(), Gas: uint64(*args.Gas), To: args.To, Value: value, Data: data})
+		kind, fields = keys.EVMLegacyTransaction, []any{tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), chainID, uint(0), uint(0)}
+	default:
+		return nil, &rpcError{rpcInvalidParams, "gasPrice or maxFeePerGas is required"}
+	}
+	payload, err := rlp.EncodeToBytes(fields)
+	if err != nil {
+		return nil, &rpcError{rpcInvalidParams, err.Error()}
+	}
+	if kind == keys.EVMTypedTransaction {
+		payload = append([]byte{tx.Type()}, payload...)
+	}
+
+	to := "contract creation"
+	if args.To != nil {
+		to = "to " + addressbook.Describe(*args.To)
+	}
+	result, rpcErr := s.sign(args.From, Request{
+		Method:  req.Method,
+		Kind:    kind,
+		Payload: payload,
+		ChainID: s.ChainID,
+		Summary: fmt.Sprintf("transaction %s, value %s wei, nonce %d", to, value, tx.Nonce()),
+		Client:  req.client,
+	})
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	signature := hexutil.MustDecode(result.(string))
+
+	// r || s || recovery id, as WithSignature expects
+	recoveryID := new(big.Int).SetBytes(signature[64:])
+	if kind == keys.EVMLegacyTransaction {
+		recoveryID.Sub(recoveryID, new(big.Int).Add(big.NewInt(35), new(big.Int).Mul(chainID, big.NewInt(2))))
+	}
+	signer := types.LatestSignerForChainID(chainID)
+	signed, err := tx.WithSignature(signer, append(signature[:64:64], byte(recoveryID.Uint64())))
+	if err != nil {
+		return nil, &rpcError{rpcServerError, fmt.Sprintf("failed to assemble the transaction: %v", err)}
+	}
+	if sender, err := types.Sender(signer, signed); err != nil || sender != args.From {
+		return nil, &rpcError{rpcServerError, "the signed transaction does not recover to the sending account"}
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, &rpcError{rpcServerError, err.Error()}
+	}
+	return hexutil.Encode(raw), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,61 @@
+// File: internal/web3signer/web3signer_test.go
+package web3signer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGuard(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string // the server's
+		auth   string
+		origin string
+		want   int
+		client string // the client the request is attributed to
+	}{
+		{name: "token", token: "secret", auth: "Bearer secret", want: http.StatusOK},
+		{name: "no token sent", token: "secret", want: http.StatusUnauthorized},
+		{name: "wrong token", token: "secret", auth: "Bearer other", want: http.StatusUnauthorized},
+		{name: "server without a token", auth: "Bearer ", want: http.StatusUnauthorized},
+		{name: "browser", token: "secret", auth: "Bearer secret", origin: "https://example.com", want: http.StatusForbidden},
+		{name: "client token", token: "secret", auth: "Bearer ci-token", want: http.StatusOK, client: "ci-bot"},
+		{name: "unknown client token", token: "secret", auth: "Bearer other-token", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{Token: tt.token, Clients: func(token string) (string, bool) {
+				return "ci-bot", token == "ci-token"
+			}}
+			req := httptest.NewRequest(http.MethodGet, "http://127.0.0.1:9000/upcheck", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+			var client string
+			s.guard(func(w http.ResponseWriter, r *http.Request) {
+				client = clientOf(r)
+				s.handleUpcheck(w, r)
+			})(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if client != tt.client {
+				t.Fatalf("client = %q, want %q", client, tt.client)
+			}
+		})
+	}
+}
+
+func TestListenAndServeRequiresToken(t *testing.T) {
+	s := &Server{}
+	if err := s.ListenAndServe(context.Background(), "127.0.0.1:0"); err == nil {
+		t.Fatal("ListenAndServe() without a token succeeded")
+	}
+}
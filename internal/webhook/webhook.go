@@ -0,0 +1,143 @@
+// File: internal/webhook/webhook.go
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"vault.module/internal/budget"
+	"vault.module/internal/config"
+)
+
+// Mutation events delivered to webhooks
+const (
+	EventWalletAdded    = "wallet.added"
+	EventWalletDeleted  = "wallet.deleted"
+	EventWalletImported = "wallet.imported"
+	EventWalletRenamed  = "wallet.renamed"
+)
+
+// SignatureHeader carries "sha256=<hex HMAC of the body>" when the webhook has a secret
+const SignatureHeader = "X-Vault-Signature"
+
+const (
+	requestTimeout = 10 * time.Second
+	maxAttempts    = 3
+	initialBackoff = time.Second
+)
+
+// Event is the payload POSTed to webhooks. Text makes it directly usable with
+// Slack and Matrix incoming-webhook bridges. It never contains secrets.
+type Event struct {
+	Event     string    `json:"event"`
+	Vault     string    `json:"vault"`
+	Prefix    string    `json:"prefix,omitempty"`
+	Details   string    `json:"details,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Text      string    `json:"text"`
+}
+
+// Notify delivers ev to every configured webhook subscribed to it. Each webhook is
+// retried with exponential backoff; the returned errors are one per failed webhook.
+func Notify(ev Event) []error {
+	if ev.Timestamp.IsZero() {
+		ev.Timestamp = time.Now().UTC()
+	}
+	if ev.Text == "" {
+		ev.Text = fmt.Sprintf("vault.module: %s in vault '%s'", ev.Event, ev.Vault)
+		if ev.Prefix != "" {
+			ev.Text += fmt.Sprintf(" (wallet '%s')", ev.Prefix)
+		}
+		if ev.Details != "" {
+			ev.Text += ": " + ev.Details
+		}
+	}
+
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, hook := range config.Cfg.Webhooks {
+		if !subscribed(hook, ev.Event) {
+			continue
+		}
+		if err := deliver(hook, ev.Event, body); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", hook.URL, err))
+		}
+	}
+	return errs
+}
+
+// subscribed reports whether hook receives event; an empty event list means all events
+func subscribed(hook config.Webhook, event string) bool {
+	if len(hook.Events) == 0 {
+		return true
+	}
+	for _, e := range hook.Events {
+		if e == event || e == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the signature header value for body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliver POSTs body to the webhook, retrying on network errors and 5xx/429 responses
+func deliver(hook config.Webhook, event string, body []byte) error {
+	backoff := initialBackoff
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		retry, err := post(hook, event, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || attempt == maxAttempts {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	return lastErr
+}
+
+// post performs a single delivery attempt and reports whether a failure is worth retrying
+func post(hook config.Webhook, event string, body []byte) (bool, error) {
+	ctx, cancel := budget.WithTimeout(requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Event", event)
+	if hook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(hook.Secret, body))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook responded with %s", resp.Status)
+}
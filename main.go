@@ -1,4 +1,19 @@
 // File: main.go
+
+// vault.module is a secure CLI manager for crypto keys with YubiKey support.
+//
+// It needs no CGO: secp256k1 falls back to its pure-Go implementation, and the
+// security features that depend on the OS (memory locking, file locks, swap
+// and screen-capture checks) have an implementation for each target. Static
+// release binaries are cross-compiled with
+//
+//	CGO_ENABLED=0 GOOS=linux GOARCH=arm64 go build -trimpath .
+//	CGO_ENABLED=0 GOOS=darwin GOARCH=arm64 go build -trimpath .
+//	CGO_ENABLED=0 GOOS=windows GOARCH=amd64 go build -trimpath .
+//
+// On Windows, memory locking uses VirtualLock, files are locked with
+// LockFileEx, and the owner-only permission check on vault files is left to
+// ACLs.
 package main
 
 import (
@@ -19,17 +34,26 @@ func main() {
 		if !shutdownManager.IsShutdown() {
 			shutdownManager.Shutdown()
 		}
+		// With --scrub-report, a secret that survives the run fails it
+		if shutdownManager.ScrubReportEnabled() && shutdownManager.ScrubReport().LeakCount() > 0 {
+			os.Exit(1)
+		}
 	}()
 
 	// Execute the root command and check for errors.
 	if err := cmd.Execute(); err != nil {
-		// Use centralized error handling
-		if errors.DefaultHandler != nil {
-			errorMsg := errors.FormatForUser(err)
-			fmt.Fprintln(os.Stderr, "Error:", errorMsg)
-		} else {
-			// Fallback if error handler not initialized
-			fmt.Fprintln(os.Stderr, "Error:", err)
+		// A child run by 'exec' already reported its failure: exit with its status
+		code, childExited := errors.ExitCode(err)
+		if !childExited {
+			code = 1
+			// Use centralized error handling
+			if errors.DefaultHandler != nil {
+				errorMsg := errors.FormatForUser(err)
+				fmt.Fprintln(os.Stderr, "Error:", errorMsg)
+			} else {
+				// Fallback if error handler not initialized
+				fmt.Fprintln(os.Stderr, "Error:", err)
+			}
 		}
 
 		// Ensure cleanup happens before exit
@@ -37,6 +61,6 @@ func main() {
 			shutdownManager.Shutdown()
 		}
 
-		os.Exit(1)
+		os.Exit(code)
 	}
 }
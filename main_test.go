@@ -0,0 +1,234 @@
+// File: main_test.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"vault.module/internal/actions"
+	"vault.module/internal/constants"
+	"vault.module/internal/vault"
+)
+
+// runMainEnv makes the test binary run vault.module itself with its arguments,
+// so that a test can run commands end to end, shutdown and exit status included
+const runMainEnv = "VAULT_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// TestScrubReport runs commands that handle secrets with VAULT_SCRUB_REPORT=1
+// and fails if any of them leaves a secret, temp file, clipboard registration
+// or child process behind.
+func TestScrubReport(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake age is a shell script")
+	}
+	dir := scrubTestVault(t)
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"list", []string{"list"}},
+		{"get mnemonic", []string{"get", "H1", "mnemonic", "--out-fd", "3"}},
+		{"get private key", []string{"get", "W1", "privatekey", "--out-fd", "3"}},
+		{"derive", []string{"derive", "H1"}},
+		{"exec", []string{"exec", "H1", "--", "true"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := os.Create(filepath.Join(t.TempDir(), "out"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer out.Close()
+
+			cmd := exec.Command(os.Args[0], tt.args...)
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(),
+				runMainEnv+"=1",
+				"VAULT_SCRUB_REPORT=1",
+				"PATH="+filepath.Join(dir, "bin")+string(os.PathListSeparator)+os.Getenv("PATH"),
+			)
+			cmd.ExtraFiles = []*os.File{out} // --out-fd 3
+			var output bytes.Buffer
+			cmd.Stdout = &output
+			cmd.Stderr = &output
+
+			runErr := cmd.Run()
+			report := output.String()
+			if !strings.Contains(report, "Scrub report:") {
+				t.Fatalf("%v printed no scrub report (%v):\n%s", tt.args, runErr, report)
+			}
+			if strings.Contains(report, "LEAKED") || !strings.Contains(report, "Scrub check passed") {
+				t.Fatalf("%v leaked sensitive resources:\n%s", tt.args, report)
+			}
+			if runErr != nil {
+				t.Fatalf("%v failed: %v\n%s", tt.args, runErr, report)
+			}
+		})
+	}
+}
+
+// TestExecExitStatus checks that exec exits with the status of its child,
+// without an error message of its own
+func TestExecExitStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake age is a shell script")
+	}
+	dir := scrubTestVault(t)
+
+	cmd := exec.Command(os.Args[0], "exec", "H1", "--", "sh", "-c", "exit 7")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		runMainEnv+"=1",
+		"PATH="+filepath.Join(dir, "bin")+string(os.PathListSeparator)+os.Getenv("PATH"),
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.ExitCode() != 7 {
+		t.Fatalf("exec of a child exiting with 7 = %v, want exit status 7\n%s", err, stderr.String())
+	}
+	if strings.Contains(stderr.String(), "Error:") {
+		t.Fatalf("exec reported the child's exit status as its own error:\n%s", stderr.String())
+	}
+}
+
+// scrubTestVault sets up a directory with a FIDO2 vault holding an HD wallet
+// H1 and an imported wallet W1, and an age that stores the vault in plaintext.
+// FIDO2 vaults with an identity file decrypt without a terminal.
+func scrubTestVault(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "bin")
+	if err := os.Mkdir(bin, 0700); err != nil {
+		t.Fatal(err)
+	}
+	fakeAge := `#!/bin/sh
+out=""; in=""
+while [ $# -gt 0 ]; do
+	case "$1" in
+	-o) out="$2"; shift 2 ;;
+	-i|-r|-R|-j) shift 2 ;;
+	-*) shift ;;
+	*) in="$1"; shift ;;
+	esac
+done
+[ -n "$in" ] || in=/dev/stdin
+if [ -n "$out" ]; then cat "$in" >"$out"; else cat "$in"; fi
+`
+	files := map[string]string{
+		filepath.Join(bin, "age"):                 fakeAge,
+		filepath.Join(bin, constants.PluginFIDO2): "#!/bin/sh\nexit 0\n",
+		filepath.Join(dir, "identity.txt"):        "AGE-PLUGIN-FIDO2-HMAC-1TEST\n",
+		filepath.Join(dir, "recipients.txt"):      "age1fido2-hmac1test\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hd, _, err := actions.CreateWalletFromMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", constants.VaultTypeEVM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, _, err := actions.CreateWalletFromPrivateKey("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318", constants.VaultTypeEVM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, "vault.key")
+	data, err := json.Marshal(vault.VaultHeader{Version: vault.CurrentVaultVersion, Data: vault.Vault{"H1": hd, "W1": imported}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := fmt.Sprintf(`{"active_vault": "main", "vaults": {"main": {"KeyFile": %q, "RecipientsFile": %q, "IdentityFile": %q, "Type": %q, "Encryption": %q}}}`,
+		keyFile, filepath.Join(dir, "recipients.txt"), filepath.Join(dir, "identity.txt"), constants.VaultTypeEVM, constants.EncryptionFIDO2)
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// startupArgs are the commands whose cold start is timed
+var startupArgs = [][]string{{"--help"}, {"vaults", "list"}}
+
+// maxStartup bounds the fastest of a few cold starts. The target is 50ms and a
+// cold start takes about 25ms; the bound leaves room for slow and busy machines.
+const maxStartup = 250 * time.Millisecond
+
+// TestStartupTime fails when the fastest of a few cold starts of --help or
+// 'vaults list' takes longer than maxStartup
+func TestStartupTime(t *testing.T) {
+	if testing.Short() {
+		t.Skip("times process starts")
+	}
+	dir := startupTestDir(t)
+	for _, args := range startupArgs {
+		fastest := time.Duration(1<<63 - 1)
+		for i := 0; i < 5; i++ {
+			start := time.Now()
+			runStartup(t, dir, args)
+			fastest = min(fastest, time.Since(start))
+		}
+		t.Logf("%v started in %v", args, fastest)
+		if fastest > maxStartup {
+			t.Errorf("%v took %v to start, want at most %v", args, fastest, maxStartup)
+		}
+	}
+}
+
+// BenchmarkStartup times a cold start of --help and 'vaults list', process
+// start included. TestStartupDispatch in cmd checks that neither reads
+// config.json before dispatch nor looks up age and its plugins.
+func BenchmarkStartup(b *testing.B) {
+	dir := startupTestDir(b)
+	for _, args := range startupArgs {
+		b.Run(strings.Join(args, " "), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				runStartup(b, dir, args)
+			}
+		})
+	}
+}
+
+func startupTestDir(tb testing.TB) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	config := `{"active_vault": "main", "tour_seen": true, "vaults": {"main": {"KeyFile": "main.key", "Type": "evm", "Encryption": "yubikey"}}}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0600); err != nil {
+		tb.Fatal(err)
+	}
+	return dir
+}
+
+// runStartup runs vault.module with args in dir, in a new process
+func runStartup(tb testing.TB, dir string, args []string) {
+	tb.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), runMainEnv+"=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		tb.Fatalf("%v failed: %v\n%s", args, err, out)
+	}
+}